@@ -3,15 +3,24 @@ package application
 import (
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"math"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/valerii-smirnov/petli-test-task/internal/adapters"
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
 	"github.com/valerii-smirnov/petli-test-task/internal/presenters"
 	"github.com/valerii-smirnov/petli-test-task/internal/usecases"
+	"github.com/valerii-smirnov/petli-test-task/pkg/blob"
 	"github.com/valerii-smirnov/petli-test-task/pkg/db/sqlx"
 	"github.com/valerii-smirnov/petli-test-task/pkg/hasher"
+	"github.com/valerii-smirnov/petli-test-task/pkg/mailer"
+	"github.com/valerii-smirnov/petli-test-task/pkg/oidc"
 	"github.com/valerii-smirnov/petli-test-task/pkg/token"
+	"github.com/valerii-smirnov/petli-test-task/pkg/totp"
 	"github.com/valerii-smirnov/petli-test-task/pkg/utils/user"
+	"github.com/valerii-smirnov/petli-test-task/pkg/worker"
 
 	"github.com/urfave/cli/v2"
 )
@@ -24,10 +33,74 @@ type applicationConfig struct {
 	DBPass                 string
 	DBName                 string
 	JWTTokenSecret         string
+	JWTSigningKeys         cli.StringSlice
+	JWTActiveKeyID         string
+	JWTRetiredKeyIDs       cli.StringSlice
 	JWTTokenExpirationTime time.Duration
+	RefreshTokenExpiration time.Duration
+	RevokedTokensPruning   time.Duration
 	PasswordSalt           string
+	PasswordHashMemory     uint
+	PasswordHashIterations uint
+	PasswordHashThreads    uint
+	SignInEmailMaxFailures uint
+	SignInIPMaxFailures    uint
+	SignInLockoutBaseDelay time.Duration
+	SignInLockoutMaxDelay  time.Duration
+	SignInFailuresReset    time.Duration
+	SignInAttemptsPruning  time.Duration
+	TrustedProxies         cli.StringSlice
+	TOTPIssuer             string
+	MFATokenExpiration     time.Duration
+	OIDCIssuer             string
+	OIDCClientID           string
+	OIDCClientSecret       string
+	OIDCRedirectURL        string
+	OIDCLoginTimeout       time.Duration
+	PublicURL              string
+	LinkSigningSecret      string
+	EmailVerificationTTL   time.Duration
+	PasswordResetURL       string
+	PasswordResetTTL       time.Duration
+	AccountDeletionGrace   time.Duration
+	DeletedAccountsPurging time.Duration
+	DataExportSyncMaxDogs  uint
+	DataExportTTL          time.Duration
+	DataExportJobsPolling  time.Duration
+	DataExportsPruning     time.Duration
+	Mailer                 string
+	MailFrom               string
+	MailLogFile            string
+	SMTPHost               string
+	SMTPPort               uint
+	SMTPUser               string
+	SMTPPass               string
+	DogImageMaxSize        uint
+	DogMaxPhotos           uint
+	PhotoDuplicateDistance uint
+	RecommendationWeights  domain.RecommendationWeights
+	RecommendationPoolSize uint
+	BlobStore              string
+	BlobLocalDir           string
+	S3Endpoint             string
+	S3Region               string
+	S3Bucket               string
+	S3AccessKey            string
+	S3SecretKey            string
+	S3PublicURL            string
 }
 
+const (
+	mailerSMTP = "smtp"
+	mailerLog  = "log"
+
+	blobStoreLocal = "local"
+	blobStoreS3    = "s3"
+
+	// localBlobsPath path the app serves files of local blob store at.
+	localBlobsPath = "/media"
+)
+
 type App struct {
 	appConfig applicationConfig
 }
@@ -95,22 +168,460 @@ func (a *App) Init() *cli.App {
 					EnvVars:     []string{"JWT_TOKEN_SECRET"},
 					DefaultText: "super-secret-secret",
 				},
+				&cli.StringSliceFlag{
+					Name:        "jwt-signing-keys",
+					Usage:       "paths to PEM encoded RSA or Ed25519 private keys, file name is used as key id. jwt-token-secret is used if empty {string}",
+					Destination: &a.appConfig.JWTSigningKeys,
+					Required:    false,
+					EnvVars:     []string{"JWT_SIGNING_KEYS"},
+				},
+				&cli.StringFlag{
+					Name:        "jwt-active-key-id",
+					Usage:       "id of the key new tokens are signed with, may be omitted if only one key is provided {string}",
+					Destination: &a.appConfig.JWTActiveKeyID,
+					Required:    false,
+					EnvVars:     []string{"JWT_ACTIVE_KEY_ID"},
+				},
+				&cli.StringSliceFlag{
+					Name:        "jwt-retired-key-ids",
+					Usage:       "ids of keys which are not accepted anymore {string}",
+					Destination: &a.appConfig.JWTRetiredKeyIDs,
+					Required:    false,
+					EnvVars:     []string{"JWT_RETIRED_KEY_IDS"},
+				},
 				&cli.DurationFlag{
 					Name:        "jwt-token-expiration-time",
 					Usage:       "jwt token expiration time {string}",
 					Destination: &a.appConfig.JWTTokenExpirationTime,
 					Required:    false,
 					EnvVars:     []string{"JWT_TOKEN_EXPIRATION_TIME"},
-					DefaultText: "24h",
+					Value:       15 * time.Minute,
+				},
+				&cli.DurationFlag{
+					Name:        "refresh-token-expiration-time",
+					Usage:       "refresh token expiration time {string}",
+					Destination: &a.appConfig.RefreshTokenExpiration,
+					Required:    false,
+					EnvVars:     []string{"REFRESH_TOKEN_EXPIRATION_TIME"},
+					Value:       30 * 24 * time.Hour,
+				},
+				&cli.DurationFlag{
+					Name:        "revoked-tokens-prune-interval",
+					Usage:       "interval of removing expired tokens from revocation list {string}",
+					Destination: &a.appConfig.RevokedTokensPruning,
+					Required:    false,
+					EnvVars:     []string{"REVOKED_TOKENS_PRUNE_INTERVAL"},
+					Value:       time.Hour,
 				},
 				&cli.StringFlag{
 					Name:        "user-password-salt",
-					Usage:       "user password salt {string}",
+					Usage:       "legacy md5 user password salt, used only to verify not yet upgraded hashes {string}",
 					Destination: &a.appConfig.PasswordSalt,
 					Required:    false,
 					EnvVars:     []string{"USER_PASSWORD_SALT"},
 					DefaultText: "super-secret-user-password-salt",
 				},
+				&cli.UintFlag{
+					Name:        "password-hash-memory",
+					Usage:       "argon2id password hashing memory cost in KiB {uint}",
+					Destination: &a.appConfig.PasswordHashMemory,
+					Required:    false,
+					EnvVars:     []string{"PASSWORD_HASH_MEMORY"},
+					Value:       uint(hasher.DefaultArgon2IDParams.Memory),
+				},
+				&cli.UintFlag{
+					Name:        "password-hash-iterations",
+					Usage:       "argon2id password hashing iterations {uint}",
+					Destination: &a.appConfig.PasswordHashIterations,
+					Required:    false,
+					EnvVars:     []string{"PASSWORD_HASH_ITERATIONS"},
+					Value:       uint(hasher.DefaultArgon2IDParams.Iterations),
+				},
+				&cli.UintFlag{
+					Name:        "password-hash-threads",
+					Usage:       "argon2id password hashing parallelism, 1-255 {uint}",
+					Destination: &a.appConfig.PasswordHashThreads,
+					Required:    false,
+					EnvVars:     []string{"PASSWORD_HASH_THREADS"},
+					Value:       uint(hasher.DefaultArgon2IDParams.Parallelism),
+				},
+				&cli.UintFlag{
+					Name:        "sign-in-email-max-failures",
+					Usage:       "failed sign-in attempts allowed for an email before it gets locked {uint}",
+					Destination: &a.appConfig.SignInEmailMaxFailures,
+					Required:    false,
+					EnvVars:     []string{"SIGN_IN_EMAIL_MAX_FAILURES"},
+					Value:       5,
+				},
+				&cli.UintFlag{
+					Name:        "sign-in-ip-max-failures",
+					Usage:       "failed sign-in attempts allowed from a client ip before it gets locked {uint}",
+					Destination: &a.appConfig.SignInIPMaxFailures,
+					Required:    false,
+					EnvVars:     []string{"SIGN_IN_IP_MAX_FAILURES"},
+					Value:       50,
+				},
+				&cli.DurationFlag{
+					Name:        "sign-in-lockout-base-delay",
+					Usage:       "lockout after the first failure over the limit, doubled by every next failure {string}",
+					Destination: &a.appConfig.SignInLockoutBaseDelay,
+					Required:    false,
+					EnvVars:     []string{"SIGN_IN_LOCKOUT_BASE_DELAY"},
+					Value:       30 * time.Second,
+				},
+				&cli.DurationFlag{
+					Name:        "sign-in-lockout-max-delay",
+					Usage:       "maximal sign-in lockout {string}",
+					Destination: &a.appConfig.SignInLockoutMaxDelay,
+					Required:    false,
+					EnvVars:     []string{"SIGN_IN_LOCKOUT_MAX_DELAY"},
+					Value:       time.Hour,
+				},
+				&cli.DurationFlag{
+					Name:        "sign-in-failures-reset-after",
+					Usage:       "period without failed sign-in attempts after which counter starts over {string}",
+					Destination: &a.appConfig.SignInFailuresReset,
+					Required:    false,
+					EnvVars:     []string{"SIGN_IN_FAILURES_RESET_AFTER"},
+					Value:       24 * time.Hour,
+				},
+				&cli.DurationFlag{
+					Name:        "sign-in-attempts-prune-interval",
+					Usage:       "interval of removing stale failed sign-in attempts counters {string}",
+					Destination: &a.appConfig.SignInAttemptsPruning,
+					Required:    false,
+					EnvVars:     []string{"SIGN_IN_ATTEMPTS_PRUNE_INTERVAL"},
+					Value:       time.Hour,
+				},
+				&cli.StringSliceFlag{
+					Name:        "trusted-proxies",
+					Usage:       "ips or cidrs of proxies allowed to set client ip with X-Forwarded-For header, none if empty {string}",
+					Destination: &a.appConfig.TrustedProxies,
+					Required:    false,
+					EnvVars:     []string{"TRUSTED_PROXIES"},
+				},
+				&cli.StringFlag{
+					Name:        "totp-issuer",
+					Usage:       "issuer name shown by authenticator apps {string}",
+					Destination: &a.appConfig.TOTPIssuer,
+					Required:    false,
+					EnvVars:     []string{"TOTP_ISSUER"},
+					Value:       "Petly",
+				},
+				&cli.DurationFlag{
+					Name:        "mfa-token-expiration-time",
+					Usage:       "time given to pass the second sign-in step {string}",
+					Destination: &a.appConfig.MFATokenExpiration,
+					Required:    false,
+					EnvVars:     []string{"MFA_TOKEN_EXPIRATION_TIME"},
+					Value:       5 * time.Minute,
+				},
+				&cli.StringFlag{
+					Name:        "oidc-issuer",
+					Usage:       "issuer url of OpenID Connect provider, sign-in with the provider is disabled if empty {string}",
+					Destination: &a.appConfig.OIDCIssuer,
+					Required:    false,
+					EnvVars:     []string{"OIDC_ISSUER"},
+				},
+				&cli.StringFlag{
+					Name:        "oidc-client-id",
+					Usage:       "client id registered at OpenID Connect provider {string}",
+					Destination: &a.appConfig.OIDCClientID,
+					Required:    false,
+					EnvVars:     []string{"OIDC_CLIENT_ID"},
+				},
+				&cli.StringFlag{
+					Name:        "oidc-client-secret",
+					Usage:       "client secret registered at OpenID Connect provider {string}",
+					Destination: &a.appConfig.OIDCClientSecret,
+					Required:    false,
+					EnvVars:     []string{"OIDC_CLIENT_SECRET"},
+				},
+				&cli.StringFlag{
+					Name:        "oidc-redirect-url",
+					Usage:       "redirect url registered at OpenID Connect provider, public-url + /api/auth/oidc/callback if empty {string}",
+					Destination: &a.appConfig.OIDCRedirectURL,
+					Required:    false,
+					EnvVars:     []string{"OIDC_REDIRECT_URL"},
+				},
+				&cli.DurationFlag{
+					Name:        "oidc-login-timeout",
+					Usage:       "time given to log in at OpenID Connect provider {string}",
+					Destination: &a.appConfig.OIDCLoginTimeout,
+					Required:    false,
+					EnvVars:     []string{"OIDC_LOGIN_TIMEOUT"},
+					Value:       10 * time.Minute,
+				},
+				&cli.StringFlag{
+					Name:        "public-url",
+					Usage:       "public url of the server, used to build links sent to users {string}",
+					Destination: &a.appConfig.PublicURL,
+					Required:    false,
+					EnvVars:     []string{"PUBLIC_URL"},
+					Value:       "http://localhost:8080",
+				},
+				&cli.StringFlag{
+					Name:        "link-signing-secret",
+					Usage:       "secret links sent to users are signed with {string}",
+					Destination: &a.appConfig.LinkSigningSecret,
+					Required:    true,
+					EnvVars:     []string{"LINK_SIGNING_SECRET"},
+				},
+				&cli.DurationFlag{
+					Name:        "email-verification-link-ttl",
+					Usage:       "email verification and email change confirmation link expiration time {string}",
+					Destination: &a.appConfig.EmailVerificationTTL,
+					Required:    false,
+					EnvVars:     []string{"EMAIL_VERIFICATION_LINK_TTL"},
+					Value:       48 * time.Hour,
+				},
+				&cli.StringFlag{
+					Name:        "password-reset-url",
+					Usage:       "page of the client app password reset link points to, token is passed as token query parameter {string}",
+					Destination: &a.appConfig.PasswordResetURL,
+					Required:    false,
+					EnvVars:     []string{"PASSWORD_RESET_URL"},
+					Value:       "http://localhost:8080/password-reset",
+				},
+				&cli.DurationFlag{
+					Name:        "password-reset-token-ttl",
+					Usage:       "password reset token expiration time {string}",
+					Destination: &a.appConfig.PasswordResetTTL,
+					Required:    false,
+					EnvVars:     []string{"PASSWORD_RESET_TOKEN_TTL"},
+					Value:       time.Hour,
+				},
+				&cli.DurationFlag{
+					Name:        "account-deletion-grace-period",
+					Usage:       "time after account deletion request during which the deletion can be canceled {string}",
+					Destination: &a.appConfig.AccountDeletionGrace,
+					Required:    false,
+					EnvVars:     []string{"ACCOUNT_DELETION_GRACE_PERIOD"},
+					Value:       30 * 24 * time.Hour,
+				},
+				&cli.DurationFlag{
+					Name:        "deleted-accounts-purge-interval",
+					Usage:       "interval of purging accounts which deletion grace period is over {string}",
+					Destination: &a.appConfig.DeletedAccountsPurging,
+					Required:    false,
+					EnvVars:     []string{"DELETED_ACCOUNTS_PURGE_INTERVAL"},
+					Value:       time.Hour,
+				},
+				&cli.UintFlag{
+					Name:        "data-export-sync-max-dogs",
+					Usage:       "data of users with more dogs is exported by background job {uint}",
+					Destination: &a.appConfig.DataExportSyncMaxDogs,
+					Required:    false,
+					EnvVars:     []string{"DATA_EXPORT_SYNC_MAX_DOGS"},
+					Value:       10,
+				},
+				&cli.DurationFlag{
+					Name:        "data-export-ttl",
+					Usage:       "time archive built by data export job can be downloaded {string}",
+					Destination: &a.appConfig.DataExportTTL,
+					Required:    false,
+					EnvVars:     []string{"DATA_EXPORT_TTL"},
+					Value:       24 * time.Hour,
+				},
+				&cli.DurationFlag{
+					Name:        "data-export-jobs-poll-interval",
+					Usage:       "interval of checking for pending data export jobs {string}",
+					Destination: &a.appConfig.DataExportJobsPolling,
+					Required:    false,
+					EnvVars:     []string{"DATA_EXPORT_JOBS_POLL_INTERVAL"},
+					Value:       10 * time.Second,
+				},
+				&cli.DurationFlag{
+					Name:        "data-exports-prune-interval",
+					Usage:       "interval of removing expired data export archives {string}",
+					Destination: &a.appConfig.DataExportsPruning,
+					Required:    false,
+					EnvVars:     []string{"DATA_EXPORTS_PRUNE_INTERVAL"},
+					Value:       time.Hour,
+				},
+				&cli.StringFlag{
+					Name:        "mailer",
+					Usage:       "mail sender: smtp or log, log writes messages to mail-log-file for local development {string}",
+					Destination: &a.appConfig.Mailer,
+					Required:    false,
+					EnvVars:     []string{"MAILER"},
+					Value:       mailerLog,
+				},
+				&cli.StringFlag{
+					Name:        "mail-from",
+					Usage:       "sender address of emails {string}",
+					Destination: &a.appConfig.MailFrom,
+					Required:    false,
+					EnvVars:     []string{"MAIL_FROM"},
+					Value:       "no-reply@petly.local",
+				},
+				&cli.StringFlag{
+					Name:        "mail-log-file",
+					Usage:       "file log mailer appends messages to, stdout if empty {string}",
+					Destination: &a.appConfig.MailLogFile,
+					Required:    false,
+					EnvVars:     []string{"MAIL_LOG_FILE"},
+				},
+				&cli.StringFlag{
+					Name:        "smtp-host",
+					Usage:       "smtp server host {string}",
+					Destination: &a.appConfig.SMTPHost,
+					Required:    false,
+					EnvVars:     []string{"SMTP_HOST"},
+				},
+				&cli.UintFlag{
+					Name:        "smtp-port",
+					Usage:       "smtp server port {uint}",
+					Destination: &a.appConfig.SMTPPort,
+					Required:    false,
+					EnvVars:     []string{"SMTP_PORT"},
+					Value:       587,
+				},
+				&cli.StringFlag{
+					Name:        "smtp-user",
+					Usage:       "smtp user, authentication is skipped if empty {string}",
+					Destination: &a.appConfig.SMTPUser,
+					Required:    false,
+					EnvVars:     []string{"SMTP_USER"},
+				},
+				&cli.StringFlag{
+					Name:        "smtp-pass",
+					Usage:       "smtp password {string}",
+					Destination: &a.appConfig.SMTPPass,
+					Required:    false,
+					EnvVars:     []string{"SMTP_PASS"},
+				},
+				&cli.UintFlag{
+					Name:        "dog-image-max-size",
+					Usage:       "max size of uploaded dog image in bytes {uint}",
+					Destination: &a.appConfig.DogImageMaxSize,
+					Required:    false,
+					EnvVars:     []string{"DOG_IMAGE_MAX_SIZE"},
+					Value:       5 << 20,
+				},
+				&cli.UintFlag{
+					Name:        "dog-max-photos",
+					Usage:       "max number of photos per dog {uint}",
+					Destination: &a.appConfig.DogMaxPhotos,
+					Required:    false,
+					EnvVars:     []string{"DOG_MAX_PHOTOS"},
+					Value:       6,
+				},
+				&cli.UintFlag{
+					Name:        "photo-duplicate-distance",
+					Usage:       "max number of differing bits of 64 bit photo hashes the photos are considered duplicates at {uint}",
+					Destination: &a.appConfig.PhotoDuplicateDistance,
+					Required:    false,
+					EnvVars:     []string{"PHOTO_DUPLICATE_DISTANCE"},
+					Value:       8,
+				},
+				&cli.Float64Flag{
+					Name:        "recommendation-weight-preference",
+					Usage:       "weight of preference fit of the dogs to each other in the recommendation score {float}",
+					Destination: &a.appConfig.RecommendationWeights.Preference,
+					Required:    false,
+					EnvVars:     []string{"RECOMMENDATION_WEIGHT_PREFERENCE"},
+					Value:       1,
+				},
+				&cli.Float64Flag{
+					Name:        "recommendation-weight-distance",
+					Usage:       "weight of closeness of the dogs in the recommendation score {float}",
+					Destination: &a.appConfig.RecommendationWeights.Distance,
+					Required:    false,
+					EnvVars:     []string{"RECOMMENDATION_WEIGHT_DISTANCE"},
+					Value:       1,
+				},
+				&cli.Float64Flag{
+					Name:        "recommendation-weight-recency",
+					Usage:       "weight of how new the recommended dog is in the recommendation score {float}",
+					Destination: &a.appConfig.RecommendationWeights.Recency,
+					Required:    false,
+					EnvVars:     []string{"RECOMMENDATION_WEIGHT_RECENCY"},
+					Value:       0.5,
+				},
+				&cli.Float64Flag{
+					Name:        "recommendation-weight-like-back",
+					Usage:       "weight of likelihood the recommended dog likes back in the recommendation score {float}",
+					Destination: &a.appConfig.RecommendationWeights.LikeBack,
+					Required:    false,
+					EnvVars:     []string{"RECOMMENDATION_WEIGHT_LIKE_BACK"},
+					Value:       1.5,
+				},
+				&cli.Float64Flag{
+					Name:        "recommendation-weight-diversity",
+					Usage:       "weight of diversity of breeds and owners among recommended dogs in the recommendation score {float}",
+					Destination: &a.appConfig.RecommendationWeights.Diversity,
+					Required:    false,
+					EnvVars:     []string{"RECOMMENDATION_WEIGHT_DIVERSITY"},
+					Value:       0.5,
+				},
+				&cli.UintFlag{
+					Name:        "recommendation-pool-size",
+					Usage:       "max number of newest feed dogs ranked for recommendations {uint}",
+					Destination: &a.appConfig.RecommendationPoolSize,
+					Required:    false,
+					EnvVars:     []string{"RECOMMENDATION_POOL_SIZE"},
+					Value:       200,
+				},
+				&cli.StringFlag{
+					Name:        "blob-store",
+					Usage:       "storage of uploaded files: local or s3, local files are served by the app at /media {string}",
+					Destination: &a.appConfig.BlobStore,
+					Required:    false,
+					EnvVars:     []string{"BLOB_STORE"},
+					Value:       blobStoreLocal,
+				},
+				&cli.StringFlag{
+					Name:        "blob-local-dir",
+					Usage:       "directory local blob store keeps files in {string}",
+					Destination: &a.appConfig.BlobLocalDir,
+					Required:    false,
+					EnvVars:     []string{"BLOB_LOCAL_DIR"},
+					Value:       "media",
+				},
+				&cli.StringFlag{
+					Name:        "s3-endpoint",
+					Usage:       "S3 compatible storage endpoint, e.g. http://localhost:9000 for MinIO {string}",
+					Destination: &a.appConfig.S3Endpoint,
+					Required:    false,
+					EnvVars:     []string{"S3_ENDPOINT"},
+				},
+				&cli.StringFlag{
+					Name:        "s3-region",
+					Usage:       "S3 region {string}",
+					Destination: &a.appConfig.S3Region,
+					Required:    false,
+					EnvVars:     []string{"S3_REGION"},
+					Value:       "us-east-1",
+				},
+				&cli.StringFlag{
+					Name:        "s3-bucket",
+					Usage:       "S3 bucket uploaded files are stored in {string}",
+					Destination: &a.appConfig.S3Bucket,
+					Required:    false,
+					EnvVars:     []string{"S3_BUCKET"},
+				},
+				&cli.StringFlag{
+					Name:        "s3-access-key",
+					Usage:       "S3 access key {string}",
+					Destination: &a.appConfig.S3AccessKey,
+					Required:    false,
+					EnvVars:     []string{"S3_ACCESS_KEY"},
+				},
+				&cli.StringFlag{
+					Name:        "s3-secret-key",
+					Usage:       "S3 secret key {string}",
+					Destination: &a.appConfig.S3SecretKey,
+					Required:    false,
+					EnvVars:     []string{"S3_SECRET_KEY"},
+				},
+				&cli.StringFlag{
+					Name:        "s3-public-url",
+					Usage:       "URL files of the bucket are served at, e.g. CDN, s3-endpoint/s3-bucket if empty {string}",
+					Destination: &a.appConfig.S3PublicURL,
+					Required:    false,
+					EnvVars:     []string{"S3_PUBLIC_URL"},
+				},
 			},
 		},
 	}
@@ -118,7 +629,11 @@ func (a *App) Init() *cli.App {
 	return app
 }
 
-func (a *App) serveAction(_ *cli.Context) error {
+func (a *App) serveAction(c *cli.Context) error {
+	// argon2 panics on zero parallelism, wider values don't fit uint8.
+	if a.appConfig.PasswordHashThreads < 1 || a.appConfig.PasswordHashThreads > math.MaxUint8 {
+		return fmt.Errorf("password-hash-threads must be in range 1-%d, got %d", math.MaxUint8, a.appConfig.PasswordHashThreads)
+	}
 
 	db, err := sqlx.NewConnection(
 		a.appConfig.DBHost,
@@ -133,26 +648,342 @@ func (a *App) serveAction(_ *cli.Context) error {
 		return err
 	}
 
-	passwordHasher := hasher.NewMD5(a.appConfig.PasswordSalt)
-	tokenProcessor := token.NewJWT(a.appConfig.JWTTokenSecret, a.appConfig.JWTTokenExpirationTime)
+	passwordHasher := hasher.NewPassword(
+		hasher.NewArgon2ID(hasher.Argon2IDParams{
+			Memory:      uint32(a.appConfig.PasswordHashMemory),
+			Iterations:  uint32(a.appConfig.PasswordHashIterations),
+			Parallelism: uint8(a.appConfig.PasswordHashThreads),
+			SaltLength:  hasher.DefaultArgon2IDParams.SaltLength,
+			KeyLength:   hasher.DefaultArgon2IDParams.KeyLength,
+		}),
+		hasher.NewMD5(a.appConfig.PasswordSalt),
+	)
+	keys, err := a.signingKeys()
+	if err != nil {
+		return err
+	}
+
+	tokenProcessor := token.NewJWT(keys, a.appConfig.JWTTokenExpirationTime)
 
 	userAdapter := adapters.NewUser(db)
 	dogAdapter := adapters.NewDog(db)
+	refreshTokenAdapter := adapters.NewRefreshToken(db)
+	tokenRevocationAdapter := adapters.NewTokenRevocation(db)
+	passwordResetTokenAdapter := adapters.NewPasswordResetToken(db)
+	signInAttemptsAdapter := adapters.NewSignInAttempts(db)
+	recoveryCodeAdapter := adapters.NewRecoveryCode(db)
+	userIdentityAdapter := adapters.NewUserIdentity(db)
+	apiKeyAdapter := adapters.NewAPIKey(db)
+	dataExportAdapter := adapters.NewDataExport(db)
+	auditEventAdapter := adapters.NewAuditEvent(db)
+	flaggedPhotoAdapter := adapters.NewFlaggedPhoto(db)
+
+	mailSender, err := a.mailer()
+	if err != nil {
+		return err
+	}
+
+	signer := token.NewSigned(a.appConfig.LinkSigningSecret)
+
+	emailVerificationUsecase := usecases.NewEmailVerification(
+		userAdapter,
+		signer,
+		mailSender,
+		a.appConfig.PublicURL+"/api/auth/verify-email",
+		a.appConfig.EmailVerificationTTL,
+	)
+	lockoutUsecase := usecases.NewLockout(
+		signInAttemptsAdapter,
+		a.lockoutPolicy(a.appConfig.SignInEmailMaxFailures),
+		a.lockoutPolicy(a.appConfig.SignInIPMaxFailures),
+	)
+	twoFactorUsecase := usecases.NewTwoFactor(
+		userAdapter,
+		recoveryCodeAdapter,
+		totp.New(a.appConfig.TOTPIssuer),
+		token.NewOpaque(),
+		signer,
+		lockoutUsecase,
+		a.appConfig.MFATokenExpiration,
+	)
+	authUsecase := usecases.NewAuth(
+		passwordHasher,
+		tokenProcessor,
+		token.NewOpaque(),
+		userAdapter,
+		refreshTokenAdapter,
+		tokenRevocationAdapter,
+		emailVerificationUsecase,
+		lockoutUsecase,
+		twoFactorUsecase,
+		auditEventAdapter,
+		a.appConfig.RefreshTokenExpiration,
+	)
+	passwordResetUsecase := usecases.NewPasswordReset(
+		userAdapter,
+		passwordResetTokenAdapter,
+		token.NewOpaque(),
+		passwordHasher,
+		mailSender,
+		authUsecase,
+		auditEventAdapter,
+		a.appConfig.PasswordResetURL,
+		a.appConfig.PasswordResetTTL,
+	)
+	blobStore, err := a.blobStore()
+	if err != nil {
+		return err
+	}
 
-	authUsecase := usecases.NewAuth(passwordHasher, tokenProcessor, userAdapter)
-	dogUsecase := usecases.NewDog(dogAdapter)
+	userUsecase := usecases.NewUser(
+		userAdapter,
+		apiKeyAdapter,
+		auditEventAdapter,
+		passwordHasher,
+		signer,
+		mailSender,
+		authUsecase,
+		auditEventAdapter,
+		blobStore,
+		a.appConfig.PublicURL+"/api/me/email/confirm",
+		a.appConfig.EmailVerificationTTL,
+		a.appConfig.AccountDeletionGrace,
+	)
 
-	authMiddleware := presenters.NewAuthMiddleware(tokenProcessor)
+	geocoder, err := adapters.NewCityGeocoder()
+	if err != nil {
+		return err
+	}
 
-	authPresenter := presenters.NewAuth(authUsecase)
+	dogUsecase := usecases.NewDog(
+		dogAdapter,
+		userAdapter,
+		flaggedPhotoAdapter,
+		blobStore,
+		adapters.NewImageProcessor(),
+		geocoder,
+		int64(a.appConfig.DogImageMaxSize),
+		int(a.appConfig.DogMaxPhotos),
+		int(a.appConfig.PhotoDuplicateDistance),
+	)
+	recommendationUsecase := usecases.NewRecommendation(
+		dogAdapter,
+		a.appConfig.RecommendationWeights,
+		int(a.appConfig.RecommendationPoolSize),
+	)
+	dataExportUsecase := usecases.NewDataExport(
+		dataExportAdapter,
+		userAdapter,
+		dogAdapter,
+		adapters.NewExportArchive(),
+		int(a.appConfig.DataExportSyncMaxDogs),
+		a.appConfig.DataExportTTL,
+	)
+	adminUsecase := usecases.NewAdmin(userAdapter, auditEventAdapter)
+	moderationUsecase := usecases.NewModeration(userAdapter, flaggedPhotoAdapter, int(a.appConfig.DogMaxPhotos))
+	apiKeyUsecase := usecases.NewAPIKey(apiKeyAdapter, token.NewOpaque())
+
+	authMiddleware := presenters.NewAuthMiddleware(tokenProcessor, authUsecase, apiKeyUsecase)
+
+	authPresenter := presenters.NewAuth(authUsecase, authMiddleware.Auth)
+	emailVerificationPresenter := presenters.NewEmailVerification(emailVerificationUsecase)
+	passwordResetPresenter := presenters.NewPasswordReset(passwordResetUsecase)
+	twoFactorPresenter := presenters.NewTwoFactor(twoFactorUsecase, user.NewIdentityExtractor(), authMiddleware.Auth)
 	dogPresenter := presenters.NewDog(
 		dogUsecase,
 		user.NewIdentityExtractor(),
 		presenters.NewUrlPagination(),
+		presenters.NewUrlDogFilter(),
+		int64(a.appConfig.DogImageMaxSize),
+		authMiddleware.Auth,
+	)
+	recommendationPresenter := presenters.NewRecommendation(
+		recommendationUsecase,
+		user.NewIdentityExtractor(),
+		presenters.NewUrlPagination(),
+		authMiddleware.Auth,
+	)
+	userPresenter := presenters.NewUser(
+		userUsecase,
+		user.NewIdentityExtractor(),
+		presenters.NewUrlPagination(),
+		authMiddleware.Auth,
+		presenters.RequireAccessToken,
+	)
+	dataExportPresenter := presenters.NewDataExport(
+		dataExportUsecase,
+		user.NewIdentityExtractor(),
+		authMiddleware.Auth,
+		presenters.RequireAccessToken,
+	)
+	apiKeyPresenter := presenters.NewAPIKey(
+		apiKeyUsecase,
+		user.NewIdentityExtractor(),
+		authMiddleware.Auth,
+		presenters.RequireAccessToken,
+	)
+	adminPresenter := presenters.NewAdmin(
+		adminUsecase,
+		user.NewIdentityExtractor(),
+		presenters.NewUrlPagination(),
+		authMiddleware.Auth,
+		presenters.RequirePermission(domain.PermissionManageUsers, domain.PermissionViewAuditLog),
+	)
+	moderationPresenter := presenters.NewModeration(
+		moderationUsecase,
+		user.NewIdentityExtractor(),
+		presenters.NewUrlPagination(),
 		authMiddleware.Auth,
+		presenters.RequirePermission(domain.PermissionModeratePhotos),
 	)
 
+	injectors := []presenters.RoutesInjector{
+		authPresenter,
+		emailVerificationPresenter,
+		passwordResetPresenter,
+		twoFactorPresenter,
+		userPresenter,
+		dataExportPresenter,
+		dogPresenter,
+		recommendationPresenter,
+		apiKeyPresenter,
+		adminPresenter,
+		moderationPresenter,
+	}
+
+	if a.appConfig.OIDCIssuer != "" {
+		oidcPresenter, err := a.oidcPresenter(c, userAdapter, userIdentityAdapter, signer, authUsecase)
+		if err != nil {
+			return err
+		}
+
+		injectors = append(injectors, oidcPresenter)
+	}
+
+	go worker.NewPeriodic("revoked tokens pruning", a.appConfig.RevokedTokensPruning, authUsecase.PruneRevokedTokens).Run(c.Context)
+	go worker.NewPeriodic("sign-in attempts pruning", a.appConfig.SignInAttemptsPruning, lockoutUsecase.PruneStale).Run(c.Context)
+	go worker.NewPeriodic("deleted accounts purging", a.appConfig.DeletedAccountsPurging, userUsecase.PurgeDeleted).Run(c.Context)
+	go worker.NewPeriodic("data export jobs", a.appConfig.DataExportJobsPolling, dataExportUsecase.ProcessPending).Run(c.Context)
+	go worker.NewPeriodic("data exports pruning", a.appConfig.DataExportsPruning, dataExportUsecase.PruneExpired).Run(c.Context)
+
 	engine := gin.New()
-	presenters.InitRoutes(engine, authPresenter, dogPresenter)
+	if err := engine.SetTrustedProxies(a.appConfig.TrustedProxies.Value()); err != nil {
+		return err
+	}
+
+	presenters.InitRoutes(engine, injectors...)
+	if a.appConfig.BlobStore == blobStoreLocal {
+		engine.Static(localBlobsPath, a.appConfig.BlobLocalDir)
+	}
+	presenters.NewKeys(tokenProcessor).Inject(engine)
+
 	return engine.Run(fmt.Sprintf(":%d", a.appConfig.Port))
 }
+
+// oidcPresenter discovers configured OpenID Connect provider and builds sign-in with it.
+func (a *App) oidcPresenter(
+	c *cli.Context,
+	userAdapter usecases.UserAdapter,
+	identityAdapter usecases.UserIdentityAdapter,
+	flowSigner usecases.LinkSigner,
+	sessionIssuer usecases.SessionIssuer,
+) (*presenters.OIDC, error) {
+	redirectURL := a.appConfig.OIDCRedirectURL
+	if redirectURL == "" {
+		redirectURL = a.appConfig.PublicURL + "/api/auth/oidc/callback"
+	}
+
+	provider, err := oidc.Discover(c.Context, nil, oidc.Config{
+		Issuer:       a.appConfig.OIDCIssuer,
+		ClientID:     a.appConfig.OIDCClientID,
+		ClientSecret: a.appConfig.OIDCClientSecret,
+		RedirectURL:  redirectURL,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	oidcUsecase := usecases.NewOIDC(
+		adapters.NewOIDCProvider(provider),
+		userAdapter,
+		identityAdapter,
+		token.NewOpaque(),
+		flowSigner,
+		sessionIssuer,
+		a.appConfig.OIDCLoginTimeout,
+	)
+
+	return presenters.NewOIDC(oidcUsecase, strings.HasPrefix(a.appConfig.PublicURL, "https://")), nil
+}
+
+// signingKeys loads asymmetric signing keys if configured, otherwise falls back to HMAC shared secret.
+func (a *App) signingKeys() (*token.KeySet, error) {
+	if len(a.appConfig.JWTSigningKeys.Value()) == 0 {
+		return token.NewHMACKeySet(a.appConfig.JWTTokenSecret), nil
+	}
+
+	return token.LoadKeySet(
+		a.appConfig.JWTSigningKeys.Value(),
+		a.appConfig.JWTActiveKeyID,
+		a.appConfig.JWTRetiredKeyIDs.Value(),
+	)
+}
+
+func (a *App) lockoutPolicy(maxFailures uint) usecases.LockoutPolicy {
+	return usecases.LockoutPolicy{
+		MaxFailures: int(maxFailures),
+		BaseDelay:   a.appConfig.SignInLockoutBaseDelay,
+		MaxDelay:    a.appConfig.SignInLockoutMaxDelay,
+		ResetAfter:  a.appConfig.SignInFailuresReset,
+	}
+}
+
+// blobStore returns configured storage of uploaded files.
+func (a *App) blobStore() (usecases.BlobStore, error) {
+	switch a.appConfig.BlobStore {
+	case blobStoreLocal:
+		return blob.NewLocal(a.appConfig.BlobLocalDir, a.appConfig.PublicURL+localBlobsPath), nil
+	case blobStoreS3:
+		if a.appConfig.S3Endpoint == "" || a.appConfig.S3Bucket == "" {
+			return nil, fmt.Errorf("s3 blob store requires s3-endpoint and s3-bucket")
+		}
+
+		return blob.NewS3(blob.S3Config{
+			Endpoint:  a.appConfig.S3Endpoint,
+			Region:    a.appConfig.S3Region,
+			Bucket:    a.appConfig.S3Bucket,
+			AccessKey: a.appConfig.S3AccessKey,
+			SecretKey: a.appConfig.S3SecretKey,
+			PublicURL: a.appConfig.S3PublicURL,
+		}), nil
+	default:
+		return nil, fmt.Errorf("unknown blob store %q", a.appConfig.BlobStore)
+	}
+}
+
+// mailer returns configured mail sender.
+func (a *App) mailer() (usecases.Mailer, error) {
+	switch a.appConfig.Mailer {
+	case mailerSMTP:
+		return mailer.NewSMTP(
+			a.appConfig.SMTPHost,
+			a.appConfig.SMTPPort,
+			a.appConfig.SMTPUser,
+			a.appConfig.SMTPPass,
+			a.appConfig.MailFrom,
+		), nil
+	case mailerLog:
+		if a.appConfig.MailLogFile == "" {
+			return mailer.NewWriter(os.Stdout, a.appConfig.MailFrom), nil
+		}
+
+		f, err := os.OpenFile(a.appConfig.MailLogFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("opening mail log file error: %w", err)
+		}
+
+		return mailer.NewWriter(f, a.appConfig.MailFrom), nil
+	default:
+		return nil, fmt.Errorf("unknown mailer %q", a.appConfig.Mailer)
+	}
+}
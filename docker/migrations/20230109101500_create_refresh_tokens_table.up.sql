@@ -0,0 +1,13 @@
+CREATE TABLE refresh_tokens
+(
+    id         uuid primary key     default uuid_generate_v4(),
+    family_id  uuid        not null,
+    user_id    uuid        not null references users (id) on delete cascade,
+    token_hash varchar(64) not null unique,
+    expires_at timestamp   not null,
+    used_at    timestamp,
+    revoked_at timestamp,
+    created_at timestamp   not null default now()
+);
+
+CREATE INDEX refresh_tokens_family_id_idx ON refresh_tokens (family_id);
\ No newline at end of file
@@ -0,0 +1,3 @@
+DROP TABLE revoked_tokens;
+
+ALTER TABLE users DROP COLUMN tokens_valid_after;
\ No newline at end of file
@@ -0,0 +1,11 @@
+ALTER TABLE users ADD COLUMN tokens_valid_after timestamp;
+
+CREATE TABLE revoked_tokens
+(
+    id         uuid primary key,
+    user_id    uuid      not null references users (id) on delete cascade,
+    expires_at timestamp not null,
+    revoked_at timestamp not null default now()
+);
+
+CREATE INDEX revoked_tokens_expires_at_idx ON revoked_tokens (expires_at);
\ No newline at end of file
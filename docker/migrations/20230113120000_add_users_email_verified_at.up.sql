@@ -0,0 +1,3 @@
+ALTER TABLE users ADD COLUMN email_verified_at timestamp;
+
+UPDATE users SET email_verified_at = registered_at;
@@ -0,0 +1,11 @@
+CREATE TABLE password_reset_tokens
+(
+    id         uuid primary key     default uuid_generate_v4(),
+    user_id    uuid        not null references users (id) on delete cascade,
+    token_hash varchar(64) not null unique,
+    expires_at timestamp   not null,
+    used_at    timestamp,
+    created_at timestamp   not null default now()
+);
+
+CREATE INDEX password_reset_tokens_user_id_idx ON password_reset_tokens (user_id);
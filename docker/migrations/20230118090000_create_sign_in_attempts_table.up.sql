@@ -0,0 +1,8 @@
+CREATE TABLE sign_in_attempts
+(
+    key            varchar(512) primary key,
+    failures       integer   not null,
+    last_failed_at timestamp not null
+);
+
+CREATE INDEX sign_in_attempts_last_failed_at_idx ON sign_in_attempts (last_failed_at);
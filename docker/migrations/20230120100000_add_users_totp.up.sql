@@ -0,0 +1,14 @@
+ALTER TABLE users ADD COLUMN totp_secret varchar(64);
+ALTER TABLE users ADD COLUMN totp_enabled_at timestamp;
+ALTER TABLE users ADD COLUMN totp_last_step bigint not null default 0;
+
+CREATE TABLE recovery_codes
+(
+    id         uuid primary key     default uuid_generate_v4(),
+    user_id    uuid        not null references users (id) on delete cascade,
+    code_hash  varchar(64) not null,
+    used_at    timestamp,
+    created_at timestamp   not null default now()
+);
+
+CREATE UNIQUE INDEX recovery_codes_user_id_code_hash_idx ON recovery_codes (user_id, code_hash);
@@ -0,0 +1,11 @@
+CREATE TABLE user_identities
+(
+    id         uuid primary key      default uuid_generate_v4(),
+    user_id    uuid         not null references users (id) on delete cascade,
+    issuer     varchar(255) not null,
+    subject    varchar(255) not null,
+    created_at timestamp    not null default now()
+);
+
+CREATE UNIQUE INDEX user_identities_issuer_subject_idx ON user_identities (issuer, subject);
+CREATE INDEX user_identities_user_id_idx ON user_identities (user_id);
@@ -0,0 +1 @@
+ALTER TABLE users ADD COLUMN role varchar(32) not null default 'user' check (role in ('user', 'moderator', 'admin'));
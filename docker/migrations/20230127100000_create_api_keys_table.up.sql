@@ -0,0 +1,14 @@
+CREATE TABLE api_keys
+(
+    id           uuid primary key       default uuid_generate_v4(),
+    user_id      uuid          not null references users (id) on delete cascade,
+    name         varchar(64)   not null,
+    prefix       varchar(16)   not null,
+    key_hash     varchar(64)   not null unique,
+    scopes       varchar(16)[] not null,
+    last_used_at timestamp,
+    revoked_at   timestamp,
+    created_at   timestamp     not null default now()
+);
+
+CREATE INDEX api_keys_user_id_idx ON api_keys (user_id);
@@ -0,0 +1,4 @@
+ALTER TABLE users ADD COLUMN display_name varchar(64) not null default '';
+ALTER TABLE users ADD COLUMN city varchar(64) not null default '';
+ALTER TABLE users ADD COLUMN bio varchar(500) not null default '';
+ALTER TABLE users ADD COLUMN avatar_url varchar(1024) not null default '';
@@ -0,0 +1,14 @@
+CREATE TABLE data_exports
+(
+    id           uuid primary key     default uuid_generate_v4(),
+    user_id      uuid        not null references users (id) on delete cascade,
+    status       varchar(16) not null default 'pending',
+    archive      bytea,
+    created_at   timestamp   not null default now(),
+    started_at   timestamp,
+    completed_at timestamp,
+    expires_at   timestamp
+);
+
+CREATE INDEX data_exports_user_id_idx ON data_exports (user_id);
+CREATE INDEX data_exports_status_idx ON data_exports (status, created_at);
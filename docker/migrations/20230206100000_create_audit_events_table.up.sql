@@ -0,0 +1,15 @@
+CREATE TABLE audit_events
+(
+    id         uuid primary key      default uuid_generate_v4(),
+    user_id    uuid references users (id) on delete cascade,
+    email      varchar(255) not null default '',
+    event_type varchar(32)  not null,
+    outcome    varchar(16)  not null,
+    reason     varchar(255) not null default '',
+    client_ip  varchar(45)  not null default '',
+    user_agent varchar(512) not null default '',
+    created_at timestamp    not null default now()
+);
+
+CREATE INDEX audit_events_user_id_idx ON audit_events (user_id, created_at);
+CREATE INDEX audit_events_created_at_idx ON audit_events (created_at);
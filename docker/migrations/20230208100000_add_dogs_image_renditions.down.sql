@@ -0,0 +1,2 @@
+ALTER TABLE dogs DROP COLUMN image_card;
+ALTER TABLE dogs DROP COLUMN image_thumb;
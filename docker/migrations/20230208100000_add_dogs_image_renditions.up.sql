@@ -0,0 +1,4 @@
+ALTER TABLE dogs ADD COLUMN image_thumb varchar(1024) not null default '';
+ALTER TABLE dogs ADD COLUMN image_card varchar(1024) not null default '';
+
+UPDATE dogs SET image_thumb = image, image_card = image;
@@ -0,0 +1,8 @@
+ALTER TABLE dogs ADD COLUMN image varchar(1024) not null default '';
+ALTER TABLE dogs ADD COLUMN image_thumb varchar(1024) not null default '';
+ALTER TABLE dogs ADD COLUMN image_card varchar(1024) not null default '';
+
+UPDATE dogs d SET image = p.image, image_thumb = p.image_thumb, image_card = p.image_card
+FROM dog_photos p WHERE p.dog_id = d.id AND p.is_primary;
+
+DROP TABLE dog_photos;
@@ -0,0 +1,22 @@
+CREATE TABLE dog_photos
+(
+    id          uuid primary key       default uuid_generate_v4(),
+    dog_id      uuid          not null references dogs (id) on delete cascade,
+    position    int           not null,
+    image       varchar(1024) not null,
+    image_thumb varchar(1024) not null,
+    image_card  varchar(1024) not null,
+    is_primary  boolean       not null default false,
+    created_at  timestamp     not null default now(),
+    -- photos are reordered by updating every position, uniqueness is checked once the transaction commits.
+    constraint dog_photos_dog_id_position_key unique (dog_id, position) deferrable initially deferred
+);
+
+CREATE UNIQUE INDEX dog_photos_dog_id_primary_idx ON dog_photos (dog_id) WHERE is_primary;
+
+INSERT INTO dog_photos (dog_id, position, image, image_thumb, image_card, is_primary)
+SELECT id, 0, image, image_thumb, image_card, true FROM dogs WHERE image != '';
+
+ALTER TABLE dogs DROP COLUMN image;
+ALTER TABLE dogs DROP COLUMN image_thumb;
+ALTER TABLE dogs DROP COLUMN image_card;
@@ -0,0 +1 @@
+ALTER TABLE dog_photos DROP COLUMN phash;
@@ -0,0 +1,2 @@
+-- perceptual hash of uploaded photos, photos given by URL aren't hashed.
+ALTER TABLE dog_photos ADD COLUMN phash bigint;
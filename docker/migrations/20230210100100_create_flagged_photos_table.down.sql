@@ -0,0 +1 @@
+DROP TABLE flagged_photos;
@@ -0,0 +1,18 @@
+CREATE TABLE flagged_photos
+(
+    id               uuid primary key       default uuid_generate_v4(),
+    dog_id           uuid          not null references dogs (id) on delete cascade,
+    image            varchar(1024) not null,
+    image_thumb      varchar(1024) not null,
+    image_card       varchar(1024) not null,
+    phash            bigint        not null,
+    matched_photo_id uuid references dog_photos (id) on delete set null,
+    matched_dog_id   uuid references dogs (id) on delete set null,
+    distance         int           not null,
+    status           varchar(16)   not null default 'pending',
+    reviewed_by      uuid references users (id) on delete set null,
+    reviewed_at      timestamp,
+    created_at       timestamp     not null default now()
+);
+
+CREATE INDEX flagged_photos_status_created_at_idx ON flagged_photos (status, created_at);
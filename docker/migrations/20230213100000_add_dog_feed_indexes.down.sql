@@ -0,0 +1,3 @@
+DROP INDEX reactions_liked_id_idx;
+DROP INDEX dogs_created_at_id_idx;
+DROP INDEX dogs_user_id_idx;
@@ -0,0 +1,5 @@
+-- the feed leaves out dogs of the user and dogs the acting dog already reacted to,
+-- (liker_id, liked_id) lookups are served by unique_liker_dog constraint of reactions.
+CREATE INDEX dogs_user_id_idx ON dogs (user_id);
+CREATE INDEX dogs_created_at_id_idx ON dogs (created_at DESC, id DESC);
+CREATE INDEX reactions_liked_id_idx ON reactions (liked_id);
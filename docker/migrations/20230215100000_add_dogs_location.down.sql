@@ -0,0 +1,7 @@
+DROP INDEX dogs_location_idx;
+
+ALTER TABLE dogs
+    DROP CONSTRAINT dogs_location_check,
+    DROP COLUMN longitude,
+    DROP COLUMN latitude,
+    DROP COLUMN city;
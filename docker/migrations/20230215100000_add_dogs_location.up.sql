@@ -0,0 +1,8 @@
+ALTER TABLE dogs
+    ADD COLUMN city      varchar(100)     not null default '',
+    ADD COLUMN latitude  double precision check (latitude between -90 and 90),
+    ADD COLUMN longitude double precision check (longitude between -180 and 180),
+    ADD CONSTRAINT dogs_location_check check ((latitude is null) = (longitude is null));
+
+-- dogs near a point are looked up within a latitude range first, then by haversine distance.
+CREATE INDEX dogs_location_idx ON dogs (latitude, longitude) WHERE latitude is not null;
@@ -0,0 +1 @@
+DROP TABLE dog_preferences;
@@ -0,0 +1,11 @@
+-- what the dog is looking for, null columns and empty breeds match any dog.
+CREATE TABLE dog_preferences
+(
+    dog_id          uuid primary key references dogs (id) on delete cascade,
+    sex             dog_sex,
+    min_age         integer,
+    max_age         integer,
+    breeds          text[]    not null default '{}',
+    max_distance_km integer,
+    updated_at      timestamp not null default now()
+);
@@ -0,0 +1,2 @@
+DROP INDEX reactions_liked_id_created_at_idx;
+CREATE INDEX reactions_liked_id_idx ON reactions (liked_id);
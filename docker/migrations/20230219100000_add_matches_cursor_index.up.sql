@@ -0,0 +1,3 @@
+-- matches of the dog are paged through by (created_at, liker_id) of reactions to it, latest first.
+DROP INDEX reactions_liked_id_idx;
+CREATE INDEX reactions_liked_id_created_at_idx ON reactions (liked_id, created_at DESC, liker_id DESC);
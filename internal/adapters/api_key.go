@@ -0,0 +1,128 @@
+package adapters
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/valerii-smirnov/petli-test-task/internal/adapters/models"
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+type APIKey struct {
+	db *sqlx.DB
+}
+
+func NewAPIKey(db *sqlx.DB) *APIKey {
+	return &APIKey{db: db}
+}
+
+func (a APIKey) Create(ctx context.Context, key domain.APIKey) (domain.APIKey, error) {
+	query := "insert into api_keys (user_id, name, prefix, key_hash, scopes) values ($1, $2, $3, $4, $5) returning *"
+
+	scopes := make(pq.StringArray, 0, len(key.Scopes))
+	for _, scope := range key.Scopes {
+		scopes = append(scopes, scope.String())
+	}
+
+	var mKey models.APIKey
+	if err := a.db.GetContext(ctx, &mKey, query, key.UserID, key.Name, key.Prefix, key.KeyHash, scopes); err != nil {
+		return domain.APIKey{}, ierr.WrapCode(ierr.Internal, err, "execution insert query error")
+	}
+
+	return a.apiKeyToDomain(mKey), nil
+}
+
+// List returns not revoked keys of the user, the newest first.
+func (a APIKey) List(ctx context.Context, userID uuid.UUID) ([]domain.APIKey, error) {
+	query := "select * from api_keys where user_id=$1 and revoked_at is null order by created_at desc"
+
+	var mKeys []models.APIKey
+	if err := a.db.SelectContext(ctx, &mKeys, query, userID); err != nil {
+		return nil, ierr.WrapCode(ierr.Internal, err, "execution select query error")
+	}
+
+	keys := make([]domain.APIKey, 0, len(mKeys))
+	for _, mKey := range mKeys {
+		keys = append(keys, a.apiKeyToDomain(mKey))
+	}
+
+	return keys, nil
+}
+
+// Use finds not revoked key by its hash and records the time it was last used.
+func (a APIKey) Use(ctx context.Context, keyHash string) (domain.APIKey, error) {
+	query := "update api_keys set last_used_at=now() where key_hash=$1 and revoked_at is null returning *"
+
+	var mKey models.APIKey
+	if err := a.db.GetContext(ctx, &mKey, query, keyHash); err != nil {
+		if err == sql.ErrNoRows {
+			return domain.APIKey{}, ierr.WrapCode(ierr.NotFound, err, "api key not found")
+		}
+
+		return domain.APIKey{}, ierr.WrapCode(ierr.Internal, err, "execution update query error")
+	}
+
+	return a.apiKeyToDomain(mKey), nil
+}
+
+func (a APIKey) Revoke(ctx context.Context, userID, keyID uuid.UUID) error {
+	query := "update api_keys set revoked_at=now() where id=$1 and user_id=$2 and revoked_at is null"
+
+	res, err := a.db.ExecContext(ctx, query, keyID, userID)
+	if err != nil {
+		return ierr.WrapCode(ierr.Internal, err, "execution update query error")
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return ierr.WrapCode(ierr.Internal, err, "getting affected rows error")
+	}
+
+	if affected == 0 {
+		return ierr.New(ierr.NotFound, "api key not found")
+	}
+
+	return nil
+}
+
+// RevokeAll revokes every active key of the user.
+func (a APIKey) RevokeAll(ctx context.Context, userID uuid.UUID) error {
+	query := "update api_keys set revoked_at=now() where user_id=$1 and revoked_at is null"
+
+	if _, err := a.db.ExecContext(ctx, query, userID); err != nil {
+		return ierr.WrapCode(ierr.Internal, err, "execution update query error")
+	}
+
+	return nil
+}
+
+func (a APIKey) apiKeyToDomain(key models.APIKey) domain.APIKey {
+	dKey := domain.APIKey{
+		ID:        key.ID,
+		UserID:    key.UserID,
+		Name:      key.Name,
+		Prefix:    key.Prefix,
+		KeyHash:   key.KeyHash,
+		Scopes:    make([]domain.APIKeyScope, 0, len(key.Scopes)),
+		CreatedAt: key.CreatedAt,
+	}
+
+	for _, scope := range key.Scopes {
+		dKey.Scopes = append(dKey.Scopes, domain.APIKeyScope(scope))
+	}
+
+	if key.LastUsedAt.Valid {
+		dKey.LastUsedAt = &key.LastUsedAt.Time
+	}
+
+	if key.RevokedAt.Valid {
+		dKey.RevokedAt = &key.RevokedAt.Time
+	}
+
+	return dKey
+}
@@ -0,0 +1,148 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+)
+
+func TestAPIKey_Use(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	testingError := errors.New("testing-error")
+	keyID := uuid.New()
+	userID := uuid.New()
+	keyHash := "keyhash"
+	now := time.Now()
+
+	columns := []string{"id", "user_id", "name", "prefix", "key_hash", "scopes", "last_used_at", "revoked_at", "created_at"}
+
+	type fields struct {
+		db *sqlx.DB
+	}
+	tests := []struct {
+		name      string
+		fields    fields
+		mocksInit func()
+		want      domain.APIKey
+		wantCode  ierr.Code
+		wantErr   bool
+	}{
+		{
+			name: "update query error",
+			fields: fields{
+				db: sqlx.NewDb(db, "postgres"),
+			},
+			mocksInit: func() {
+				mock.ExpectQuery("update api_keys set last_used_at").WithArgs(keyHash).WillReturnError(testingError)
+			},
+			want:     domain.APIKey{},
+			wantCode: ierr.Internal,
+			wantErr:  true,
+		},
+		{
+			name: "not found or revoked",
+			fields: fields{
+				db: sqlx.NewDb(db, "postgres"),
+			},
+			mocksInit: func() {
+				mock.ExpectQuery("update api_keys set last_used_at").WithArgs(keyHash).WillReturnRows(sqlmock.NewRows(columns))
+			},
+			want:     domain.APIKey{},
+			wantCode: ierr.NotFound,
+			wantErr:  true,
+		},
+		{
+			name: "success",
+			fields: fields{
+				db: sqlx.NewDb(db, "postgres"),
+			},
+			mocksInit: func() {
+				rows := sqlmock.NewRows(columns).
+					AddRow(keyID, userID, "ci", "petly_abcdef", keyHash, "{read,write}", now, nil, now)
+				mock.ExpectQuery("update api_keys set last_used_at").WithArgs(keyHash).WillReturnRows(rows)
+			},
+			want: domain.APIKey{
+				ID:         keyID,
+				UserID:     userID,
+				Name:       "ci",
+				Prefix:     "petly_abcdef",
+				KeyHash:    keyHash,
+				Scopes:     []domain.APIKeyScope{domain.APIKeyScopeRead, domain.APIKeyScopeWrite},
+				LastUsedAt: &now,
+				CreatedAt:  now,
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInit()
+
+			a := NewAPIKey(tt.fields.db)
+			got, err := a.Use(context.TODO(), keyHash)
+			assert.Equal(t, tt.wantErr, err != nil)
+			if tt.wantErr {
+				assert.Equal(t, tt.wantCode, ierr.GetCode(err))
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestAPIKey_Revoke(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	keyID := uuid.New()
+	userID := uuid.New()
+
+	tests := []struct {
+		name      string
+		mocksInit func()
+		wantCode  ierr.Code
+		wantErr   bool
+	}{
+		{
+			name: "key of another user or already revoked",
+			mocksInit: func() {
+				mock.ExpectExec("update api_keys set revoked_at").WithArgs(keyID, userID).WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantCode: ierr.NotFound,
+			wantErr:  true,
+		},
+		{
+			name: "success",
+			mocksInit: func() {
+				mock.ExpectExec("update api_keys set revoked_at").WithArgs(keyID, userID).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInit()
+
+			err := NewAPIKey(sqlx.NewDb(db, "postgres")).Revoke(context.TODO(), userID, keyID)
+			assert.Equal(t, tt.wantErr, err != nil)
+			if tt.wantErr {
+				assert.Equal(t, tt.wantCode, ierr.GetCode(err))
+			}
+		})
+	}
+}
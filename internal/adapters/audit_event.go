@@ -0,0 +1,128 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/valerii-smirnov/petli-test-task/internal/adapters/models"
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type AuditEvent struct {
+	db *sqlx.DB
+}
+
+func NewAuditEvent(db *sqlx.DB) *AuditEvent {
+	return &AuditEvent{db: db}
+}
+
+func (a AuditEvent) Record(ctx context.Context, event domain.AuditEvent) error {
+	query := `insert into audit_events
+				(user_id, email, event_type, outcome, reason, client_ip, user_agent) values
+				($1, $2, $3, $4, $5, $6, $7)`
+
+	var userID uuid.NullUUID
+	if event.UserID != nil {
+		userID = uuid.NullUUID{UUID: *event.UserID, Valid: true}
+	}
+
+	_, err := a.db.ExecContext(
+		ctx,
+		query,
+		userID,
+		event.Email,
+		event.Type.String(),
+		event.Outcome.String(),
+		event.Reason,
+		event.ClientIP,
+		event.UserAgent,
+	)
+	if err != nil {
+		return ierr.WrapCode(ierr.Internal, err, "execution insert query error")
+	}
+
+	return nil
+}
+
+// List returns events matching the filter, the newest first.
+func (a AuditEvent) List(ctx context.Context, filter domain.AuditEventFilter) ([]domain.AuditEvent, error) {
+	conditions := make([]string, 0)
+	args := make([]interface{}, 0)
+
+	where := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.UserID != nil {
+		where("user_id = $%d", *filter.UserID)
+	}
+
+	if filter.Email != "" {
+		where("email = $%d", filter.Email)
+	}
+
+	if filter.Type != "" {
+		where("event_type = $%d", filter.Type.String())
+	}
+
+	if filter.Outcome != "" {
+		where("outcome = $%d", filter.Outcome.String())
+	}
+
+	if filter.ClientIP != "" {
+		where("client_ip = $%d", filter.ClientIP)
+	}
+
+	if !filter.From.IsZero() {
+		where("created_at >= $%d", filter.From)
+	}
+
+	if !filter.To.IsZero() {
+		where("created_at < $%d", filter.To)
+	}
+
+	query := "select * from audit_events"
+	if len(conditions) > 0 {
+		query += " where " + strings.Join(conditions, " and ")
+	}
+
+	args = append(args, filter.Pagination.PerPage, filter.Pagination.PerPage*(filter.Pagination.Page-1))
+	query += fmt.Sprintf(" order by created_at desc limit $%d offset $%d", len(args)-1, len(args))
+
+	var mEvents []models.AuditEvent
+	if err := a.db.SelectContext(ctx, &mEvents, query, args...); err != nil {
+		return nil, ierr.WrapCode(ierr.Internal, err, "execution select query error")
+	}
+
+	events := make([]domain.AuditEvent, 0, len(mEvents))
+	for _, mEvent := range mEvents {
+		events = append(events, a.auditEventToDomain(mEvent))
+	}
+
+	return events, nil
+}
+
+func (a AuditEvent) auditEventToDomain(event models.AuditEvent) domain.AuditEvent {
+	dEvent := domain.AuditEvent{
+		ID:        event.ID,
+		Email:     event.Email,
+		Type:      domain.AuditEventType(event.EventType),
+		Outcome:   domain.AuditOutcome(event.Outcome),
+		Reason:    event.Reason,
+		ClientIP:  event.ClientIP,
+		UserAgent: event.UserAgent,
+		CreatedAt: event.CreatedAt,
+	}
+
+	if event.UserID.Valid {
+		dEvent.UserID = &event.UserID.UUID
+	}
+
+	return dEvent
+}
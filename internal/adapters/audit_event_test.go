@@ -0,0 +1,106 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditEvent_List(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	eventID := uuid.New()
+	userID := uuid.New()
+	createdAt := time.Now()
+	from := createdAt.Add(-time.Hour)
+	columns := []string{"id", "user_id", "email", "event_type", "outcome", "reason", "client_ip", "user_agent", "created_at"}
+
+	tests := []struct {
+		name      string
+		filter    domain.AuditEventFilter
+		mocksInit func()
+		want      []domain.AuditEvent
+		wantCode  ierr.Code
+		wantErr   bool
+	}{
+		{
+			name:   "select error",
+			filter: domain.AuditEventFilter{Pagination: domain.Pagination{Page: 1, PerPage: 10}},
+			mocksInit: func() {
+				mock.ExpectQuery(`select \* from audit_events order by created_at desc limit \$1 offset \$2`).
+					WithArgs(10, 0).
+					WillReturnError(errors.New("testing-error"))
+			},
+			wantCode: ierr.Internal,
+			wantErr:  true,
+		},
+		{
+			name: "filtered",
+			filter: domain.AuditEventFilter{
+				UserID:     &userID,
+				Type:       domain.AuditSignIn,
+				Outcome:    domain.AuditFailure,
+				From:       from,
+				Pagination: domain.Pagination{Page: 2, PerPage: 10},
+			},
+			mocksInit: func() {
+				rows := sqlmock.NewRows(columns).
+					AddRow(eventID, userID, "test@test.com", "sign_in", "failure", "user not found", "10.0.0.1", "curl", createdAt).
+					AddRow(eventID, nil, "", "sign_in", "failure", "user not found", "10.0.0.1", "curl", createdAt)
+				mock.ExpectQuery(`select \* from audit_events where user_id = \$1 and event_type = \$2 and outcome = \$3 `+
+					`and created_at >= \$4 order by created_at desc limit \$5 offset \$6`).
+					WithArgs(userID, "sign_in", "failure", from, 10, 10).
+					WillReturnRows(rows)
+			},
+			want: []domain.AuditEvent{
+				{
+					ID:        eventID,
+					UserID:    &userID,
+					Email:     "test@test.com",
+					Type:      domain.AuditSignIn,
+					Outcome:   domain.AuditFailure,
+					Reason:    "user not found",
+					ClientIP:  "10.0.0.1",
+					UserAgent: "curl",
+					CreatedAt: createdAt,
+				},
+				{
+					ID:        eventID,
+					Type:      domain.AuditSignIn,
+					Outcome:   domain.AuditFailure,
+					Reason:    "user not found",
+					ClientIP:  "10.0.0.1",
+					UserAgent: "curl",
+					CreatedAt: createdAt,
+				},
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInit()
+
+			got, err := NewAuditEvent(sqlx.NewDb(db, "postgres")).List(context.TODO(), tt.filter)
+			assert.Equal(t, tt.wantErr, err != nil)
+			if tt.wantErr {
+				assert.Equal(t, tt.wantCode, ierr.GetCode(err))
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package adapters
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/valerii-smirnov/petli-test-task/internal/adapters/models"
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// dataExportColumns every column except the archive, which is loaded only to be downloaded.
+const dataExportColumns = "id, user_id, status, created_at, started_at, completed_at, expires_at"
+
+type DataExport struct {
+	db *sqlx.DB
+}
+
+func NewDataExport(db *sqlx.DB) *DataExport {
+	return &DataExport{db: db}
+}
+
+func (d DataExport) Create(ctx context.Context, userID uuid.UUID) (domain.DataExport, error) {
+	query := "insert into data_exports (user_id, status) values ($1, $2) returning " + dataExportColumns
+
+	var mExport models.DataExport
+	if err := d.db.GetContext(ctx, &mExport, query, userID, domain.DataExportPending.String()); err != nil {
+		return domain.DataExport{}, ierr.WrapCode(ierr.Internal, err, "execution insert query error")
+	}
+
+	return d.dataExportToDomain(mExport), nil
+}
+
+func (d DataExport) Get(ctx context.Context, userID, exportID uuid.UUID) (domain.DataExport, error) {
+	query := "select " + dataExportColumns + " from data_exports where id=$1 and user_id=$2"
+
+	return d.getOne(ctx, query, exportID, userID)
+}
+
+// GetUnfinished returns pending or processing export of the user.
+func (d DataExport) GetUnfinished(ctx context.Context, userID uuid.UUID) (domain.DataExport, error) {
+	query := "select " + dataExportColumns + " from data_exports where user_id=$1 and status in ($2, $3) " +
+		"order by created_at desc limit 1"
+
+	return d.getOne(ctx, query, userID, domain.DataExportPending.String(), domain.DataExportProcessing.String())
+}
+
+// GetArchive returns archive of ready and not yet expired export.
+func (d DataExport) GetArchive(ctx context.Context, userID, exportID uuid.UUID) ([]byte, error) {
+	query := "select archive from data_exports where id=$1 and user_id=$2 and status=$3 and expires_at > now()"
+
+	var archive []byte
+	if err := d.db.GetContext(ctx, &archive, query, exportID, userID, domain.DataExportReady.String()); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ierr.WrapCode(ierr.NotFound, err, "data export archive not found")
+		}
+
+		return nil, ierr.WrapCode(ierr.Internal, err, "execution select query error")
+	}
+
+	return archive, nil
+}
+
+// Claim takes the oldest pending export for processing. Exports processed since before staleBefore
+// are considered abandoned, e.g. by stopped instance, and are taken again.
+// Concurrent workers never get the same export.
+func (d DataExport) Claim(ctx context.Context, staleBefore time.Time) (domain.DataExport, error) {
+	query := `
+			update data_exports set status=$1, started_at=now()
+			where id = (
+				select id from data_exports
+				where status = $2 or (status = $1 and started_at < $3)
+				order by created_at
+				limit 1
+				for update skip locked
+			)
+			returning ` + dataExportColumns
+
+	return d.getOne(ctx, query, domain.DataExportProcessing.String(), domain.DataExportPending.String(), staleBefore)
+}
+
+func (d DataExport) Complete(ctx context.Context, exportID uuid.UUID, archive []byte, expiresAt time.Time) error {
+	query := "update data_exports set status=$1, archive=$2, completed_at=now(), expires_at=$3 where id=$4"
+
+	if _, err := d.db.ExecContext(ctx, query, domain.DataExportReady.String(), archive, expiresAt, exportID); err != nil {
+		return ierr.WrapCode(ierr.Internal, err, "execution update query error")
+	}
+
+	return nil
+}
+
+func (d DataExport) Fail(ctx context.Context, exportID uuid.UUID) error {
+	query := "update data_exports set status=$1, completed_at=now() where id=$2"
+
+	if _, err := d.db.ExecContext(ctx, query, domain.DataExportFailed.String(), exportID); err != nil {
+		return ierr.WrapCode(ierr.Internal, err, "execution update query error")
+	}
+
+	return nil
+}
+
+// DeleteExpired removes exports which archives are expired, together with failed ones.
+func (d DataExport) DeleteExpired(ctx context.Context) (int64, error) {
+	query := "delete from data_exports where expires_at < now() or (status=$1 and completed_at < now() - interval '1 day')"
+
+	res, err := d.db.ExecContext(ctx, query, domain.DataExportFailed.String())
+	if err != nil {
+		return 0, ierr.WrapCode(ierr.Internal, err, "execution delete query error")
+	}
+
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return 0, ierr.WrapCode(ierr.Internal, err, "getting affected rows error")
+	}
+
+	return deleted, nil
+}
+
+func (d DataExport) getOne(ctx context.Context, query string, args ...interface{}) (domain.DataExport, error) {
+	var mExport models.DataExport
+
+	if err := d.db.GetContext(ctx, &mExport, query, args...); err != nil {
+		if err == sql.ErrNoRows {
+			return domain.DataExport{}, ierr.WrapCode(ierr.NotFound, err, "data export not found")
+		}
+
+		return domain.DataExport{}, ierr.WrapCode(ierr.Internal, err, "execution select query error")
+	}
+
+	return d.dataExportToDomain(mExport), nil
+}
+
+func (d DataExport) dataExportToDomain(export models.DataExport) domain.DataExport {
+	dExport := domain.DataExport{
+		ID:        export.ID,
+		UserID:    export.UserID,
+		Status:    domain.DataExportStatus(export.Status),
+		CreatedAt: export.CreatedAt,
+	}
+
+	if export.CompletedAt.Valid {
+		dExport.CompletedAt = &export.CompletedAt.Time
+	}
+
+	if export.ExpiresAt.Valid {
+		dExport.ExpiresAt = &export.ExpiresAt.Time
+	}
+
+	return dExport
+}
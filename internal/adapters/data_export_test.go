@@ -0,0 +1,131 @@
+package adapters
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDataExport_Claim(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	exportID := uuid.New()
+	userID := uuid.New()
+	createdAt := time.Now()
+	staleBefore := time.Now().Add(-time.Minute)
+
+	tests := []struct {
+		name      string
+		mocksInit func()
+		want      domain.DataExport
+		wantCode  ierr.Code
+		wantErr   bool
+	}{
+		{
+			name: "nothing to process",
+			mocksInit: func() {
+				mock.ExpectQuery("update data_exports set status").
+					WithArgs(domain.DataExportProcessing.String(), domain.DataExportPending.String(), staleBefore).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			},
+			want:     domain.DataExport{},
+			wantCode: ierr.NotFound,
+			wantErr:  true,
+		},
+		{
+			name: "success",
+			mocksInit: func() {
+				rows := sqlmock.NewRows([]string{"id", "user_id", "status", "created_at", "started_at", "completed_at", "expires_at"}).
+					AddRow(exportID, userID, domain.DataExportProcessing.String(), createdAt, time.Now(), nil, nil)
+				mock.ExpectQuery("update data_exports set status").
+					WithArgs(domain.DataExportProcessing.String(), domain.DataExportPending.String(), staleBefore).
+					WillReturnRows(rows)
+			},
+			want: domain.DataExport{
+				ID:        exportID,
+				UserID:    userID,
+				Status:    domain.DataExportProcessing,
+				CreatedAt: createdAt,
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInit()
+
+			got, err := NewDataExport(sqlx.NewDb(db, "postgres")).Claim(context.TODO(), staleBefore)
+			assert.Equal(t, tt.wantErr, err != nil)
+			if tt.wantErr {
+				assert.Equal(t, tt.wantCode, ierr.GetCode(err))
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDataExport_GetArchive(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	exportID := uuid.New()
+	userID := uuid.New()
+
+	tests := []struct {
+		name      string
+		mocksInit func()
+		want      []byte
+		wantCode  ierr.Code
+		wantErr   bool
+	}{
+		{
+			name: "expired",
+			mocksInit: func() {
+				mock.ExpectQuery("select archive from data_exports").
+					WithArgs(exportID, userID, domain.DataExportReady.String()).
+					WillReturnRows(sqlmock.NewRows([]string{"archive"}))
+			},
+			wantCode: ierr.NotFound,
+			wantErr:  true,
+		},
+		{
+			name: "success",
+			mocksInit: func() {
+				mock.ExpectQuery("select archive from data_exports").
+					WithArgs(exportID, userID, domain.DataExportReady.String()).
+					WillReturnRows(sqlmock.NewRows([]string{"archive"}).AddRow([]byte("archive")))
+			},
+			want:    []byte("archive"),
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInit()
+
+			got, err := NewDataExport(sqlx.NewDb(db, "postgres")).GetArchive(context.TODO(), userID, exportID)
+			assert.Equal(t, tt.wantErr, err != nil)
+			if tt.wantErr {
+				assert.Equal(t, tt.wantCode, ierr.GetCode(err))
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -3,6 +3,9 @@ package adapters
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"math"
+	"strings"
 
 	"github.com/valerii-smirnov/petli-test-task/internal/adapters/models"
 	"github.com/valerii-smirnov/petli-test-task/internal/domain"
@@ -10,6 +13,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 type Dog struct {
@@ -22,24 +26,198 @@ func NewDog(db *sqlx.DB) *Dog {
 	}
 }
 
-func (d Dog) List(ctx context.Context, userID uuid.UUID, pagination domain.Pagination) (domain.DogList, error) {
-	query := "select * from dogs WHERE user_id != $1 order by created_at desc limit $2 offset $3"
-	rows, err := d.db.QueryxContext(ctx, query, userID, pagination.PerPage, pagination.PerPage*(pagination.Page-1))
+// dogSortOrders order by clauses of the sort orders, id makes the order stable between pages.
+var dogSortOrders = map[domain.DogSort]string{
+	domain.SortCreatedDesc: "d.created_at desc, d.id desc",
+	domain.SortCreatedAsc:  "d.created_at asc, d.id asc",
+	domain.SortAgeAsc:      "d.age asc, d.id asc",
+	domain.SortAgeDesc:     "d.age desc, d.id desc",
+	domain.SortNameAsc:     "d.name asc, d.id asc",
+	domain.SortNameDesc:    "d.name desc, d.id desc",
+	// dogs without location have no distance, they are the last.
+	domain.SortDistanceAsc: "distance asc, d.id asc",
+}
+
+const earthRadiusKm = 6371.0
+
+// locationCellDeg size in degrees of the grid cells dog locations are snapped to, about 2 km. Every distance
+// to a dog is computed from the center of its cell, so filtering and sorting by distance tell no more than the cell.
+const locationCellDeg = 0.02
+
+// haversineDistance great-circle distance km between the points given by latitude and longitude expressions.
+const haversineDistance = `%[5]g * 2 * asin(least(1, sqrt(
+		power(sin(radians(%[3]s - %[1]s) / 2), 2) +
+		cos(radians(%[1]s)) * cos(radians(%[3]s)) * power(sin(radians(%[4]s - %[2]s) / 2), 2)
+	)))`
+
+// snappedDistance distance km from the dog aliased as dog, snapped to its cell, to the point.
+func snappedDistance(dog, lat, lng string) string {
+	return fmt.Sprintf(haversineDistance, lat, lng, snapped(dog+".latitude"), snapped(dog+".longitude"), earthRadiusKm)
+}
+
+// snapped coordinate of the column snapped to the center of its grid cell.
+func snapped(column string) string {
+	return fmt.Sprintf("round(%s / %[2]g) * %[2]g", column, locationCellDeg)
+}
+
+// List returns dogs of other users matching the filter, the newest first unless the filter sorts them otherwise.
+func (d Dog) List(
+	ctx context.Context,
+	userID uuid.UUID,
+	filter domain.DogFilter,
+	pagination domain.Pagination,
+) (domain.DogPage, error) {
+	return d.candidates(ctx, userID, uuid.Nil, filter, pagination)
+}
+
+// Feed returns dogs of other users matching the filter the dog hasn't reacted to yet.
+func (d Dog) Feed(
+	ctx context.Context,
+	dog domain.Dog,
+	filter domain.DogFilter,
+	pagination domain.Pagination,
+) (domain.DogPage, error) {
+	return d.candidates(ctx, dog.UserID, dog.ID, filter, pagination)
+}
+
+// candidates returns dogs of other users than userID matching the filter,
+// dogs reactorID reacted to are left out unless it's uuid.Nil.
+// Lists sorted by creation time are paged through by cursor as well as by page number.
+func (d Dog) candidates(
+	ctx context.Context,
+	userID uuid.UUID,
+	reactorID uuid.UUID,
+	filter domain.DogFilter,
+	pagination domain.Pagination,
+) (domain.DogPage, error) {
+	// dogs of accounts pending deletion are hidden from other users.
+	conditions := []string{"d.user_id != $1", "u.deletion_scheduled_at is null"}
+	args := []interface{}{userID}
+
+	param := func(arg interface{}) string {
+		args = append(args, arg)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	where := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	joins := ""
+	if reactorID != uuid.Nil {
+		where("not exists (select 1 from reactions r where r.liker_id = $%d and r.liked_id = d.id)", reactorID)
+
+		// the dog must fit preferences of the reactor and the reactor must fit preferences of the dog.
+		joins = fmt.Sprintf(`
+			inner join dogs a on a.id = $%d
+			left join dog_preferences ap on ap.dog_id = a.id
+			left join dog_preferences dp on dp.dog_id = d.id`, len(args))
+		distance := snappedDistance("d", snapped("a.latitude"), snapped("a.longitude"))
+		conditions = append(conditions, fitsPreferences("d", "ap", distance), fitsPreferences("a", "dp", distance))
+	}
+
+	if filter.Sex != "" {
+		where("d.sex = $%d", filter.Sex.String())
+	}
+
+	if filter.MinAge != nil {
+		where("d.age >= $%d", *filter.MinAge)
+	}
+
+	if filter.MaxAge != nil {
+		where("d.age <= $%d", *filter.MaxAge)
+	}
+
+	if len(filter.Breeds) > 0 {
+		breeds := make(pq.StringArray, 0, len(filter.Breeds))
+		for _, breed := range filter.Breeds {
+			breeds = append(breeds, strings.ToLower(breed))
+		}
+
+		where("lower(d.breed) = any($%d)", breeds)
+	}
+
+	if !filter.CreatedAfter.IsZero() {
+		where("d.created_at > $%d", filter.CreatedAfter)
+	}
+
+	columns := "d.*"
+	if filter.Near != nil {
+		distance := snappedDistance("d", param(filter.Near.Latitude), param(filter.Near.Longitude))
+		columns += ", " + distance + " as distance"
+
+		if filter.RadiusKm > 0 {
+			// the box lets the location index skip far away dogs before the distance is computed,
+			// it's wider by half a cell since the dog within the radius may be snapped from outside of it.
+			box := boundingBox(*filter.Near, filter.RadiusKm).grow(locationCellDeg / 2)
+			conditions = append(conditions, fmt.Sprintf("d.latitude between %s and %s", param(box.minLat), param(box.maxLat)))
+			if !box.anyLng {
+				conditions = append(conditions, fmt.Sprintf("d.longitude between %s and %s", param(box.minLng), param(box.maxLng)))
+			}
+
+			where(distance+" <= $%d", filter.RadiusKm)
+		}
+	}
+
+	sort := filter.Sort
+	if _, ok := dogSortOrders[sort]; !ok || (sort == domain.SortDistanceAsc && filter.Near == nil) {
+		sort = domain.SortCreatedDesc
+	}
+
+	offset := pagination.PerPage * (pagination.Page - 1)
+	if pagination.After != nil && sort.ByCreation() {
+		comparison := "<"
+		if sort == domain.SortCreatedAsc {
+			comparison = ">"
+		}
+
+		conditions = append(conditions, fmt.Sprintf("(d.created_at, d.id) %s (%s, %s)",
+			comparison, param(pagination.After.CreatedAt), param(pagination.After.ID)))
+		offset = 0
+	}
+
+	// one dog more than the page tells if there is the next one.
+	query := fmt.Sprintf(`
+			select %s from dogs d
+			inner join users u on u.id = d.user_id%s
+			where %s
+			order by %s
+			limit %s offset %s
+		`, columns, joins, strings.Join(conditions, " and "), dogSortOrders[sort],
+		param(pagination.PerPage+1), param(offset))
+
+	rows, err := d.db.QueryxContext(ctx, query, args...)
 	if err != nil {
-		return nil, ierr.WrapCode(ierr.Internal, err, "execution select query error")
+		return domain.DogPage{}, ierr.WrapCode(ierr.Internal, err, "execution select query error")
 	}
 
 	list := make([]models.Dog, 0, 1)
 	for rows.Next() {
 		var dog models.Dog
 		if err := rows.StructScan(&dog); err != nil {
-			return nil, ierr.WrapCode(ierr.Internal, err, "struct scanning error")
+			return domain.DogPage{}, ierr.WrapCode(ierr.Internal, err, "struct scanning error")
 		}
 
 		list = append(list, dog)
 	}
 
-	return d.dogListToDomainDogList(list)
+	// the next page starts after the last dog of this one.
+	var next *domain.Cursor
+	if pagination.PerPage > 0 && len(list) > pagination.PerPage {
+		list = list[:pagination.PerPage]
+		if sort.ByCreation() {
+			last := list[len(list)-1]
+			next = &domain.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}
+		}
+	}
+
+	dogs, err := d.withPhotos(ctx, d.db, list)
+	if err != nil {
+		return domain.DogPage{}, err
+	}
+
+	return domain.DogPage{Dogs: dogs, NextCursor: next}, nil
 }
 
 func (d Dog) Get(ctx context.Context, uid uuid.UUID) (domain.Dog, error) {
@@ -53,68 +231,414 @@ func (d Dog) Get(ctx context.Context, uid uuid.UUID) (domain.Dog, error) {
 		return domain.Dog{}, ierr.WrapCode(ierr.Internal, err, "getting dog error")
 	}
 
-	return d.dogToDomainDog(dog), nil
+	return d.withPhotosOne(ctx, d.db, dog)
 }
 
-func (d Dog) Matches(ctx context.Context, dogID uuid.UUID, pagination domain.Pagination) (domain.DogList, error) {
-	query := `
-			select d.* from reactions r0
+// Matches returns dogs which liked the dog back, the latest matches first.
+func (d Dog) Matches(ctx context.Context, dogID uuid.UUID, pagination domain.Pagination) (domain.DogPage, error) {
+	args := []interface{}{dogID, domain.Like, pagination.PerPage + 1}
+
+	after := ""
+	offset := pagination.PerPage * (pagination.Page - 1)
+	if pagination.After != nil {
+		args = append(args, pagination.After.CreatedAt, pagination.After.ID)
+		after = "and (r1.created_at, r1.liker_id) < ($4, $5)"
+		offset = 0
+	}
+
+	args = append(args, offset)
+
+	// one dog more than the page tells if there is the next one.
+	query := fmt.Sprintf(`
+			select d.*, r1.created_at as matched_at from reactions r0
 			inner join reactions r1 on r0.liker_id = r1.liked_id and r1.liker_id = r0.liked_id
 			inner join dogs d on d.id = r1.liker_id
-			where r0.liker_id = $1 AND r0.action = $2
-			order by r1.created_at DESC
-			limit $3 offset $4
-		`
+			inner join users u on u.id = d.user_id
+			where r0.liker_id = $1 AND r0.action = $2 AND u.deletion_scheduled_at is null %s
+			order by r1.created_at DESC, r1.liker_id DESC
+			limit $3 offset $%d
+		`, after, len(args))
 
-	rows, err := d.db.QueryxContext(ctx, query, dogID, domain.Like, pagination.PerPage, pagination.PerPage*(pagination.Page-1))
+	rows, err := d.db.QueryxContext(ctx, query, args...)
 	if err != nil {
-		return nil, ierr.WrapCode(ierr.Internal, err, "getting matches error")
+		return domain.DogPage{}, ierr.WrapCode(ierr.Internal, err, "getting matches error")
 	}
 
-	list := make([]models.Dog, 0, 1)
+	list := make([]models.MatchedDog, 0, 1)
 	for rows.Next() {
-		var dog models.Dog
+		var dog models.MatchedDog
 		if err := rows.StructScan(&dog); err != nil {
-			return nil, ierr.WrapCode(ierr.Internal, err, "struct scanning error")
+			return domain.DogPage{}, ierr.WrapCode(ierr.Internal, err, "struct scanning error")
 		}
 
 		list = append(list, dog)
 	}
 
-	return d.dogListToDomainDogList(list)
+	var next *domain.Cursor
+	if pagination.PerPage > 0 && len(list) > pagination.PerPage {
+		list = list[:pagination.PerPage]
+		last := list[len(list)-1]
+		next = &domain.Cursor{CreatedAt: last.MatchedAt, ID: last.ID}
+	}
+
+	matched := make([]models.Dog, 0, len(list))
+	for _, dog := range list {
+		matched = append(matched, dog.Dog)
+	}
+
+	dogs, err := d.withPhotos(ctx, d.db, matched)
+	if err != nil {
+		return domain.DogPage{}, err
+	}
+
+	return domain.DogPage{Dogs: dogs, NextCursor: next}, nil
 }
 
+// Create inserts the dog together with its photos, the first photo is at the first position.
 func (d Dog) Create(ctx context.Context, dog domain.Dog) (domain.Dog, error) {
+	tx, err := d.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return domain.Dog{}, ierr.WrapCode(ierr.Internal, err, "beginning transaction error")
+	}
+	defer tx.Rollback()
+
 	query := `insert into dogs 
-    			(user_id, name, sex, age, breed, image) VALUES 
-				($1, $2, $3, $4, $5, $6) RETURNING *`
+    			(user_id, name, sex, age, breed, city, latitude, longitude) VALUES 
+				($1, $2, $3, $4, $5, $6, $7, $8) RETURNING *`
 
+	lat, lng := pointToNull(dog.Location)
 	var mDog models.Dog
-	if err := d.db.GetContext(ctx, &mDog, query, dog.UserID, dog.Name, dog.Sex.String(), dog.Age, dog.Breed, dog.Image); err != nil {
+	if err := tx.GetContext(ctx, &mDog, query, dog.UserID, dog.Name, dog.Sex.String(), dog.Age, dog.Breed, dog.City, lat, lng); err != nil {
 		return domain.Dog{}, ierr.WrapCode(ierr.Internal, err, "creating dog error")
 	}
 
-	return d.dogToDomainDog(mDog), nil
+	for position, photo := range dog.Photos {
+		if err := d.insertPhoto(ctx, tx, mDog.ID, position, photo); err != nil {
+			return domain.Dog{}, err
+		}
+	}
+
+	return d.commitWithPhotos(ctx, tx, mDog)
 }
 
+// Update updates the dog, photos are managed separately and kept as they are.
 func (d Dog) Update(ctx context.Context, uid uuid.UUID, dog domain.Dog) (domain.Dog, error) {
-	query := "update dogs set name=$1, sex=$2, age=$3, breed=$4, image=$5, updated_at=now() WHERE id=$6 returning *"
+	tx, err := d.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return domain.Dog{}, ierr.WrapCode(ierr.Internal, err, "beginning transaction error")
+	}
+	defer tx.Rollback()
+
+	if _, err := d.lock(ctx, tx, uid); err != nil {
+		return domain.Dog{}, err
+	}
+
+	query := `update dogs set name=$1, sex=$2, age=$3, breed=$4, city=$5, latitude=$6, longitude=$7, updated_at=now()
+				WHERE id=$8 returning *`
 
+	lat, lng := pointToNull(dog.Location)
 	var mDog models.Dog
-	if err := d.db.GetContext(ctx, &mDog, query, dog.Name, dog.Sex.String(), dog.Age, dog.Breed, dog.Image, uid); err != nil {
+	if err := tx.GetContext(ctx, &mDog, query, dog.Name, dog.Sex.String(), dog.Age, dog.Breed, dog.City, lat, lng, uid); err != nil {
 		return domain.Dog{}, ierr.WrapCode(ierr.Internal, err, "updating dog error")
 	}
 
-	return d.dogToDomainDog(mDog), nil
+	return d.commitWithPhotos(ctx, tx, mDog)
 }
 
-func (d Dog) Delete(ctx context.Context, uid uuid.UUID) error {
-	query := "delete from dogs where id=$1"
-	if _, err := d.db.ExecContext(ctx, query, uid); err != nil {
-		return ierr.WrapCode(ierr.Internal, err, "execution delete query error")
+// AddPhoto appends the photo to the photos of the dog, the first photo becomes the primary one.
+func (d Dog) AddPhoto(ctx context.Context, dogID uuid.UUID, photo domain.DogPhoto, maxPhotos int) (domain.Dog, error) {
+	tx, err := d.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return domain.Dog{}, ierr.WrapCode(ierr.Internal, err, "beginning transaction error")
 	}
+	defer tx.Rollback()
 
-	return nil
+	mDog, err := d.addPhoto(ctx, tx, dogID, photo, maxPhotos)
+	if err != nil {
+		return domain.Dog{}, err
+	}
+
+	return d.commitWithPhotos(ctx, tx, mDog)
+}
+
+// DeletePhoto removes the photo, photos after it move up. If the photo was the primary one,
+// the first remaining photo becomes primary. Images of the removed photo are returned.
+func (d Dog) DeletePhoto(ctx context.Context, dogID, photoID uuid.UUID) (domain.Dog, domain.DogImages, error) {
+	tx, err := d.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return domain.Dog{}, domain.DogImages{}, ierr.WrapCode(ierr.Internal, err, "beginning transaction error")
+	}
+	defer tx.Rollback()
+
+	mDog, err := d.lock(ctx, tx, dogID)
+	if err != nil {
+		return domain.Dog{}, domain.DogImages{}, err
+	}
+
+	var photo models.DogPhoto
+	if err := tx.GetContext(ctx, &photo, "delete from dog_photos where id=$1 and dog_id=$2 returning *", photoID, dogID); err != nil {
+		if err == sql.ErrNoRows {
+			return domain.Dog{}, domain.DogImages{}, ierr.WrapCode(ierr.NotFound, err, "photo not found")
+		}
+
+		return domain.Dog{}, domain.DogImages{}, ierr.WrapCode(ierr.Internal, err, "deleting photo error")
+	}
+
+	query := "update dog_photos set position=position-1 where dog_id=$1 and position>$2"
+	if _, err := tx.ExecContext(ctx, query, dogID, photo.Position); err != nil {
+		return domain.Dog{}, domain.DogImages{}, ierr.WrapCode(ierr.Internal, err, "moving photos error")
+	}
+
+	if photo.IsPrimary {
+		query := "update dog_photos set is_primary=true where dog_id=$1 and position=0"
+		if _, err := tx.ExecContext(ctx, query, dogID); err != nil {
+			return domain.Dog{}, domain.DogImages{}, ierr.WrapCode(ierr.Internal, err, "setting primary photo error")
+		}
+	}
+
+	dog, err := d.commitWithPhotos(ctx, tx, mDog)
+	if err != nil {
+		return domain.Dog{}, domain.DogImages{}, err
+	}
+
+	return dog, domain.DogImages{Thumb: photo.ImageThumb, Card: photo.ImageCard, Full: photo.Image}, nil
+}
+
+// ReorderPhotos puts photos of the dog in the given order, every photo of the dog must be listed exactly once.
+func (d Dog) ReorderPhotos(ctx context.Context, dogID uuid.UUID, photoIDs []uuid.UUID) (domain.Dog, error) {
+	tx, err := d.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return domain.Dog{}, ierr.WrapCode(ierr.Internal, err, "beginning transaction error")
+	}
+	defer tx.Rollback()
+
+	mDog, err := d.lock(ctx, tx, dogID)
+	if err != nil {
+		return domain.Dog{}, err
+	}
+
+	var current []uuid.UUID
+	if err := tx.SelectContext(ctx, &current, "select id from dog_photos where dog_id=$1", dogID); err != nil {
+		return domain.Dog{}, ierr.WrapCode(ierr.Internal, err, "getting photos error")
+	}
+
+	if !samePhotos(current, photoIDs) {
+		return domain.Dog{}, ierr.New(ierr.InvalidArgument, "every photo of the dog must be listed exactly once")
+	}
+
+	for position, photoID := range photoIDs {
+		query := "update dog_photos set position=$1 where id=$2"
+		if _, err := tx.ExecContext(ctx, query, position, photoID); err != nil {
+			return domain.Dog{}, ierr.WrapCode(ierr.Internal, err, "moving photo error")
+		}
+	}
+
+	return d.commitWithPhotos(ctx, tx, mDog)
+}
+
+// SetPrimaryPhoto makes the photo the primary one, the photo keeps its position.
+func (d Dog) SetPrimaryPhoto(ctx context.Context, dogID, photoID uuid.UUID) (domain.Dog, error) {
+	tx, err := d.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return domain.Dog{}, ierr.WrapCode(ierr.Internal, err, "beginning transaction error")
+	}
+	defer tx.Rollback()
+
+	mDog, err := d.lock(ctx, tx, dogID)
+	if err != nil {
+		return domain.Dog{}, err
+	}
+
+	// primary photo uniqueness is checked per statement, the old one is unset first.
+	if _, err := tx.ExecContext(ctx, "update dog_photos set is_primary=false where dog_id=$1 and is_primary", dogID); err != nil {
+		return domain.Dog{}, ierr.WrapCode(ierr.Internal, err, "unsetting primary photo error")
+	}
+
+	res, err := tx.ExecContext(ctx, "update dog_photos set is_primary=true where id=$1 and dog_id=$2", photoID, dogID)
+	if err != nil {
+		return domain.Dog{}, ierr.WrapCode(ierr.Internal, err, "setting primary photo error")
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return domain.Dog{}, ierr.WrapCode(ierr.Internal, err, "getting affected rows error")
+	}
+
+	if affected == 0 {
+		return domain.Dog{}, ierr.New(ierr.NotFound, "photo not found")
+	}
+
+	return d.commitWithPhotos(ctx, tx, mDog)
+}
+
+// SimilarPhoto returns uploaded photo of another user's dog with hash the closest to the given one,
+// ierr.NotFound if hashes of all photos are further than maxDistance.
+func (d Dog) SimilarPhoto(
+	ctx context.Context,
+	hash domain.ImageHash,
+	exceptUserID uuid.UUID,
+	maxDistance int,
+) (domain.SimilarPhoto, error) {
+	// hamming distance can't use an index, every hash is compared, which is fine for the number of photos we have.
+	query := `
+			select p.id as photo_id, p.dog_id, d.user_id, bit_count((p.phash # $1)::bit(64)) as distance
+			from dog_photos p
+			inner join dogs d on d.id = p.dog_id
+			where p.phash is not null and d.user_id != $2 and bit_count((p.phash # $1)::bit(64)) <= $3
+			order by distance
+			limit 1
+		`
+
+	var photo models.SimilarPhoto
+	if err := d.db.GetContext(ctx, &photo, query, int64(hash), exceptUserID, maxDistance); err != nil {
+		if err == sql.ErrNoRows {
+			return domain.SimilarPhoto{}, ierr.WrapCode(ierr.NotFound, err, "similar photo not found")
+		}
+
+		return domain.SimilarPhoto{}, ierr.WrapCode(ierr.Internal, err, "searching similar photo error")
+	}
+
+	return domain.SimilarPhoto{
+		PhotoID:  photo.PhotoID,
+		DogID:    photo.DogID,
+		UserID:   photo.UserID,
+		Distance: photo.Distance,
+	}, nil
+}
+
+// Preferences returns preferences of the dog, zero ones if the dog has never set them.
+func (d Dog) Preferences(ctx context.Context, dogID uuid.UUID) (domain.DogPreferences, error) {
+	var prefs models.DogPreferences
+	if err := d.db.GetContext(ctx, &prefs, "select * from dog_preferences where dog_id=$1", dogID); err != nil {
+		if err == sql.ErrNoRows {
+			return domain.DogPreferences{}, nil
+		}
+
+		return domain.DogPreferences{}, ierr.WrapCode(ierr.Internal, err, "getting dog preferences error")
+	}
+
+	return preferencesToDomain(prefs), nil
+}
+
+// SetPreferences replaces preferences of the dog, breeds are stored lowercased.
+func (d Dog) SetPreferences(ctx context.Context, dogID uuid.UUID, prefs domain.DogPreferences) (domain.DogPreferences, error) {
+	query := `insert into dog_preferences (dog_id, sex, min_age, max_age, breeds, max_distance_km, updated_at)
+				values ($1, $2, $3, $4, $5, $6, now())
+				on conflict (dog_id) do update set sex=$2, min_age=$3, max_age=$4, breeds=$5, max_distance_km=$6, updated_at=now()
+				returning *`
+
+	breeds := make(pq.StringArray, 0, len(prefs.Breeds))
+	for _, breed := range prefs.Breeds {
+		breeds = append(breeds, strings.ToLower(breed))
+	}
+
+	var mPrefs models.DogPreferences
+	if err := d.db.GetContext(
+		ctx,
+		&mPrefs,
+		query,
+		dogID,
+		sql.NullString{String: prefs.Sex.String(), Valid: prefs.Sex != ""},
+		uintToNull(prefs.MinAge),
+		uintToNull(prefs.MaxAge),
+		breeds,
+		uintToNull(prefs.MaxDistanceKm),
+	); err != nil {
+		return domain.DogPreferences{}, ierr.WrapCode(ierr.Internal, err, "setting dog preferences error")
+	}
+
+	return preferencesToDomain(mPrefs), nil
+}
+
+// PreferencesOf returns preferences of the dogs which have set them.
+func (d Dog) PreferencesOf(ctx context.Context, dogIDs []uuid.UUID) (map[uuid.UUID]domain.DogPreferences, error) {
+	ids := make(pq.StringArray, 0, len(dogIDs))
+	for _, id := range dogIDs {
+		ids = append(ids, id.String())
+	}
+
+	var list []models.DogPreferences
+	if err := d.db.SelectContext(ctx, &list, "select * from dog_preferences where dog_id = any($1::uuid[])", ids); err != nil {
+		return nil, ierr.WrapCode(ierr.Internal, err, "getting dogs preferences error")
+	}
+
+	prefs := make(map[uuid.UUID]domain.DogPreferences, len(list))
+	for _, p := range list {
+		prefs[p.DogID] = preferencesToDomain(p)
+	}
+
+	return prefs, nil
+}
+
+// ReactionStats returns reactions the reactors gave to other dogs than the dog, reactors without reactions are missing.
+// Reactions to the dog itself are left out, so the stats never tell if a reactor liked it before they match.
+func (d Dog) ReactionStats(
+	ctx context.Context,
+	dogID uuid.UUID,
+	reactorIDs []uuid.UUID,
+) (map[uuid.UUID]domain.ReactionStats, error) {
+	ids := make(pq.StringArray, 0, len(reactorIDs))
+	for _, id := range reactorIDs {
+		ids = append(ids, id.String())
+	}
+
+	query := `
+			select r.liker_id,
+				count(*) filter (where r.action = $3) as likes,
+				count(*) as reactions
+			from reactions r
+			where r.liker_id = any($2::uuid[]) and r.liked_id != $1
+			group by r.liker_id
+		`
+
+	var list []models.ReactionStats
+	if err := d.db.SelectContext(ctx, &list, query, dogID, ids, domain.Like); err != nil {
+		return nil, ierr.WrapCode(ierr.Internal, err, "getting reaction stats error")
+	}
+
+	stats := make(map[uuid.UUID]domain.ReactionStats, len(list))
+	for _, s := range list {
+		stats[s.LikerID] = domain.ReactionStats{Likes: s.Likes, Reactions: s.Reactions}
+	}
+
+	return stats, nil
+}
+
+// Delete removes the dog, its photos are removed by cascade. Images of the photos, including the ones held
+// for moderation, are returned. The dog is locked, so photos can't be added while the images are read.
+func (d Dog) Delete(ctx context.Context, uid uuid.UUID) ([]domain.DogImages, error) {
+	tx, err := d.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, ierr.WrapCode(ierr.Internal, err, "beginning transaction error")
+	}
+	defer tx.Rollback()
+
+	if _, err := d.lock(ctx, tx, uid); err != nil {
+		return nil, err
+	}
+
+	var images []models.DogImages
+	query := `select image, image_thumb, image_card from dog_photos where dog_id=$1
+			union
+			select image, image_thumb, image_card from flagged_photos where dog_id=$1`
+	if err := tx.SelectContext(ctx, &images, query, uid); err != nil {
+		return nil, ierr.WrapCode(ierr.Internal, err, "getting dog images error")
+	}
+
+	if _, err := tx.ExecContext(ctx, "delete from dogs where id=$1", uid); err != nil {
+		return nil, ierr.WrapCode(ierr.Internal, err, "execution delete query error")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, ierr.WrapCode(ierr.Internal, err, "committing transaction error")
+	}
+
+	deleted := make([]domain.DogImages, 0, len(images))
+	for _, image := range images {
+		deleted = append(deleted, domain.DogImages{Thumb: image.ImageThumb, Card: image.ImageCard, Full: image.Image})
+	}
+
+	return deleted, nil
 }
 
 func (d Dog) AddReaction(ctx context.Context, reaction domain.Reaction) error {
@@ -129,25 +653,331 @@ func (d Dog) AddReaction(ctx context.Context, reaction domain.Reaction) error {
 	return nil
 }
 
-func (d Dog) dogToDomainDog(dog models.Dog) domain.Dog {
-	return domain.Dog{
-		ID:        dog.ID,
-		UserID:    dog.UserID,
-		Name:      dog.Name,
-		Sex:       domain.DogSex(dog.Sex),
-		Age:       dog.Age,
-		Breed:     dog.Breed,
-		Image:     dog.Image,
-		CreatedAt: dog.CreatedAt,
-		UpdatedAt: dog.UpdatedAt,
+// ListByUser returns every dog of the user, the oldest first.
+func (d Dog) ListByUser(ctx context.Context, userID uuid.UUID) (domain.DogList, error) {
+	var list []models.Dog
+	if err := d.db.SelectContext(ctx, &list, "select * from dogs where user_id=$1 order by created_at", userID); err != nil {
+		return nil, ierr.WrapCode(ierr.Internal, err, "execution select query error")
+	}
+
+	return d.withPhotos(ctx, d.db, list)
+}
+
+// UserReactions returns reactions the user's dogs gave or received.
+func (d Dog) UserReactions(ctx context.Context, userID uuid.UUID) ([]domain.Reaction, error) {
+	query := `
+			select r.* from reactions r
+			where r.liker_id in (select id from dogs where user_id = $1)
+			   or r.liked_id in (select id from dogs where user_id = $1)
+			order by r.created_at
+		`
+
+	var list []models.Reaction
+	if err := d.db.SelectContext(ctx, &list, query, userID); err != nil {
+		return nil, ierr.WrapCode(ierr.Internal, err, "execution select query error")
+	}
+
+	reactions := make([]domain.Reaction, 0, len(list))
+	for _, reaction := range list {
+		reactions = append(reactions, domain.Reaction{
+			Liker:     reaction.LikerID,
+			Liked:     reaction.LikedID,
+			Action:    domain.Action(reaction.Action),
+			CreatedAt: reaction.CreatedAt,
+		})
+	}
+
+	return reactions, nil
+}
+
+// lock locks the dog row until the transaction ends, photo changes of the dog are serialized by it.
+func (d Dog) lock(ctx context.Context, tx *sqlx.Tx, dogID uuid.UUID) (models.Dog, error) {
+	var dog models.Dog
+	if err := tx.GetContext(ctx, &dog, "select * from dogs where id=$1 for update", dogID); err != nil {
+		if err == sql.ErrNoRows {
+			return models.Dog{}, ierr.WrapCode(ierr.NotFound, err, "dog not found")
+		}
+
+		return models.Dog{}, ierr.WrapCode(ierr.Internal, err, "getting dog error")
 	}
+
+	return dog, nil
 }
 
-func (d Dog) dogListToDomainDogList(dogs []models.Dog) (domain.DogList, error) {
+// addPhoto appends the photo to the photos of the dog in the transaction, the first photo becomes the primary one.
+// The dog is locked while photos are counted, so concurrent uploads can't exceed maxPhotos.
+func (d Dog) addPhoto(
+	ctx context.Context,
+	tx *sqlx.Tx,
+	dogID uuid.UUID,
+	photo domain.DogPhoto,
+	maxPhotos int,
+) (models.Dog, error) {
+	mDog, err := d.lock(ctx, tx, dogID)
+	if err != nil {
+		return models.Dog{}, err
+	}
+
+	var count int
+	if err := tx.GetContext(ctx, &count, "select count(*) from dog_photos where dog_id=$1", dogID); err != nil {
+		return models.Dog{}, ierr.WrapCode(ierr.Internal, err, "counting photos error")
+	}
+
+	if count >= maxPhotos {
+		return models.Dog{}, ierr.New(ierr.InvalidArgument, fmt.Sprintf("dog can't have more than %d photos", maxPhotos))
+	}
+
+	photo.Primary = count == 0
+	if err := d.insertPhoto(ctx, tx, dogID, count, photo); err != nil {
+		return models.Dog{}, err
+	}
+
+	return mDog, nil
+}
+
+// insertPhoto inserts the photo of the dog at the position within the transaction.
+func (d Dog) insertPhoto(ctx context.Context, tx *sqlx.Tx, dogID uuid.UUID, position int, photo domain.DogPhoto) error {
+	query := `insert into dog_photos (dog_id, position, image, image_thumb, image_card, phash, is_primary) 
+				values ($1, $2, $3, $4, $5, $6, $7)`
+
+	images := photo.Images
+	_, err := tx.ExecContext(
+		ctx,
+		query,
+		dogID,
+		position,
+		images.Full,
+		images.Thumb,
+		images.Card,
+		hashToNull(photo.Hash),
+		photo.Primary,
+	)
+	if err != nil {
+		return ierr.WrapCode(ierr.Internal, err, "adding photo error")
+	}
+
+	return nil
+}
+
+// commitWithPhotos reads photos of the dog changed in the transaction and commits it.
+func (d Dog) commitWithPhotos(ctx context.Context, tx *sqlx.Tx, dog models.Dog) (domain.Dog, error) {
+	dDog, err := d.withPhotosOne(ctx, tx, dog)
+	if err != nil {
+		return domain.Dog{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return domain.Dog{}, ierr.WrapCode(ierr.Internal, err, "committing transaction error")
+	}
+
+	return dDog, nil
+}
+
+func (d Dog) withPhotosOne(ctx context.Context, q sqlx.QueryerContext, dog models.Dog) (domain.Dog, error) {
+	dogs, err := d.withPhotos(ctx, q, []models.Dog{dog})
+	if err != nil {
+		return domain.Dog{}, err
+	}
+
+	return dogs[0], nil
+}
+
+// withPhotos reads photos of the dogs with a single query and converts the dogs to domain ones.
+func (d Dog) withPhotos(ctx context.Context, q sqlx.QueryerContext, dogs []models.Dog) (domain.DogList, error) {
 	dDogs := make(domain.DogList, 0, len(dogs))
+	if len(dogs) == 0 {
+		return dDogs, nil
+	}
+
+	ids := make(pq.StringArray, 0, len(dogs))
+	for _, dog := range dogs {
+		ids = append(ids, dog.ID.String())
+	}
+
+	var photos []models.DogPhoto
+	query := "select * from dog_photos where dog_id = any($1::uuid[]) order by position"
+	if err := sqlx.SelectContext(ctx, q, &photos, query, ids); err != nil {
+		return nil, ierr.WrapCode(ierr.Internal, err, "getting photos error")
+	}
+
+	byDog := make(map[uuid.UUID][]domain.DogPhoto, len(dogs))
+	for _, photo := range photos {
+		byDog[photo.DogID] = append(byDog[photo.DogID], domain.DogPhoto{
+			ID: photo.ID,
+			Images: domain.DogImages{
+				Thumb: photo.ImageThumb,
+				Card:  photo.ImageCard,
+				Full:  photo.Image,
+			},
+			Hash:      nullToHash(photo.PHash),
+			Primary:   photo.IsPrimary,
+			CreatedAt: photo.CreatedAt,
+		})
+	}
+
 	for _, dog := range dogs {
-		dDogs = append(dDogs, d.dogToDomainDog(dog))
+		dDogs = append(dDogs, domain.Dog{
+			ID:        dog.ID,
+			UserID:    dog.UserID,
+			Name:      dog.Name,
+			Sex:       domain.DogSex(dog.Sex),
+			Age:       dog.Age,
+			Breed:     dog.Breed,
+			Photos:    byDog[dog.ID],
+			City:      dog.City,
+			Location:  nullToPoint(dog.Latitude, dog.Longitude),
+			Distance:  nullToDistance(dog.Distance),
+			CreatedAt: dog.CreatedAt,
+			UpdatedAt: dog.UpdatedAt,
+		})
 	}
 
 	return dDogs, nil
 }
+
+// samePhotos reports if the ids are the same photos, each listed once.
+func samePhotos(current, ids []uuid.UUID) bool {
+	if len(current) != len(ids) {
+		return false
+	}
+
+	listed := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		listed[id] = true
+	}
+
+	if len(listed) != len(ids) {
+		return false
+	}
+
+	for _, id := range current {
+		if !listed[id] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// hashToNull stores the hash as bigint of the same bits, postgres has no unsigned integers.
+func hashToNull(hash *domain.ImageHash) sql.NullInt64 {
+	if hash == nil {
+		return sql.NullInt64{}
+	}
+
+	return sql.NullInt64{Int64: int64(*hash), Valid: true}
+}
+
+func nullToHash(hash sql.NullInt64) *domain.ImageHash {
+	if !hash.Valid {
+		return nil
+	}
+
+	dHash := domain.ImageHash(hash.Int64)
+	return &dHash
+}
+
+// geoBox latitude and longitude ranges containing the circle, any longitude if the circle contains a pole
+// or crosses the antimeridian.
+type geoBox struct {
+	minLat, maxLat float64
+	minLng, maxLng float64
+	anyLng         bool
+}
+
+// boundingBox returns the box containing the circle around the center.
+func boundingBox(center domain.GeoPoint, radiusKm float64) geoBox {
+	angular := radiusKm / earthRadiusKm
+	latDelta := angular * 180 / math.Pi
+
+	box := geoBox{
+		minLat: center.Latitude - latDelta,
+		maxLat: center.Latitude + latDelta,
+		anyLng: true,
+	}
+
+	if box.minLat <= -90 || box.maxLat >= 90 || angular >= math.Pi/2 {
+		box.minLat, box.maxLat = math.Max(box.minLat, -90), math.Min(box.maxLat, 90)
+		return box
+	}
+
+	lngDelta := math.Asin(math.Sin(angular)/math.Cos(center.Latitude*math.Pi/180)) * 180 / math.Pi
+	box.minLng, box.maxLng = center.Longitude-lngDelta, center.Longitude+lngDelta
+	box.anyLng = box.minLng < -180 || box.maxLng > 180
+
+	return box
+}
+
+// grow widens the box by deg on every side.
+func (b geoBox) grow(deg float64) geoBox {
+	b.minLat, b.maxLat = b.minLat-deg, b.maxLat+deg
+	b.minLng, b.maxLng = b.minLng-deg, b.maxLng+deg
+	b.anyLng = b.anyLng || b.minLng < -180 || b.maxLng > 180
+
+	return b
+}
+
+func pointToNull(point *domain.GeoPoint) (sql.NullFloat64, sql.NullFloat64) {
+	if point == nil {
+		return sql.NullFloat64{}, sql.NullFloat64{}
+	}
+
+	return sql.NullFloat64{Float64: point.Latitude, Valid: true}, sql.NullFloat64{Float64: point.Longitude, Valid: true}
+}
+
+func nullToPoint(lat, lng sql.NullFloat64) *domain.GeoPoint {
+	if !lat.Valid || !lng.Valid {
+		return nil
+	}
+
+	return &domain.GeoPoint{Latitude: lat.Float64, Longitude: lng.Float64}
+}
+
+// nullToDistance rounds the distance, exact one never leaves the adapter.
+func nullToDistance(km sql.NullFloat64) *uint {
+	if !km.Valid {
+		return nil
+	}
+
+	distance := domain.RoundDistance(km.Float64)
+	return &distance
+}
+
+// fitsPreferences condition of the dog aliased as dog fitting preferences aliased as prefs,
+// missing preferences match any dog.
+func fitsPreferences(dog, prefs, distance string) string {
+	return strings.NewReplacer("{dog}", dog, "{prefs}", prefs, "{distance}", distance).Replace(`(
+				({prefs}.sex is null or {dog}.sex = {prefs}.sex)
+				and ({prefs}.min_age is null or {dog}.age >= {prefs}.min_age)
+				and ({prefs}.max_age is null or {dog}.age <= {prefs}.max_age)
+				and ({prefs}.breeds is null or cardinality({prefs}.breeds) = 0 or lower({dog}.breed) = any({prefs}.breeds))
+				and ({prefs}.max_distance_km is null or {distance} <= {prefs}.max_distance_km)
+			)`)
+}
+
+func preferencesToDomain(prefs models.DogPreferences) domain.DogPreferences {
+	return domain.DogPreferences{
+		Sex:           domain.DogSex(prefs.Sex.String),
+		MinAge:        nullToUint(prefs.MinAge),
+		MaxAge:        nullToUint(prefs.MaxAge),
+		Breeds:        prefs.Breeds,
+		MaxDistanceKm: nullToUint(prefs.MaxDistanceKm),
+	}
+}
+
+func uintToNull(value *uint) sql.NullInt64 {
+	if value == nil {
+		return sql.NullInt64{}
+	}
+
+	return sql.NullInt64{Int64: int64(*value), Valid: true}
+}
+
+func nullToUint(value sql.NullInt64) *uint {
+	if !value.Valid {
+		return nil
+	}
+
+	u := uint(value.Int64)
+	return &u
+}
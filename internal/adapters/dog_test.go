@@ -8,13 +8,19 @@ import (
 	"time"
 
 	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 )
 
+const dogImageURL = "http://dog-images.com/test.jpg"
+
+var dogPhotoColumns = []string{"id", "dog_id", "position", "image", "image_thumb", "image_card", "phash", "is_primary", "created_at"}
+
 func TestDog_List(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -32,6 +38,12 @@ func TestDog_List(t *testing.T) {
 	dogsTime := time.Now()
 	dog1ID := uuid.New()
 	dog2ID := uuid.New()
+	photo := domain.DogPhoto{
+		ID:        uuid.New(),
+		Images:    domain.ExternalDogImages(dogImageURL),
+		Primary:   true,
+		CreatedAt: dogsTime,
+	}
 	expectedList := domain.DogList{
 		{
 			ID:        dog1ID,
@@ -40,7 +52,7 @@ func TestDog_List(t *testing.T) {
 			Sex:       "male",
 			Age:       2,
 			Breed:     "test_breed_1",
-			Image:     "http://dog-images.com/test.jpg",
+			Photos:    []domain.DogPhoto{photo},
 			CreatedAt: dogsTime,
 			UpdatedAt: dogsTime,
 		},
@@ -51,7 +63,6 @@ func TestDog_List(t *testing.T) {
 			Sex:       "female",
 			Age:       3,
 			Breed:     "test_breed_1",
-			Image:     "http://dog-images.com/test.jpg",
 			CreatedAt: dogsTime,
 			UpdatedAt: dogsTime,
 		},
@@ -60,9 +71,23 @@ func TestDog_List(t *testing.T) {
 	type fields struct {
 		db *sqlx.DB
 	}
+	minAge, maxAge := uint(1), uint(5)
+	createdAfter := dogsTime.Add(-time.Hour)
+	nearDistance := uint(3)
+	after := domain.Cursor{CreatedAt: dogsTime.Add(time.Minute), ID: uuid.New()}
+	filter := domain.DogFilter{
+		Sex:          domain.Female,
+		MinAge:       &minAge,
+		MaxAge:       &maxAge,
+		Breeds:       []string{"Test_Breed_1", "poodle"},
+		CreatedAfter: createdAfter,
+		Sort:         domain.SortAgeAsc,
+	}
+
 	type args struct {
 		ctx        context.Context
 		userID     uuid.UUID
+		filter     domain.DogFilter
 		pagination domain.Pagination
 	}
 	tests := []struct {
@@ -70,7 +95,7 @@ func TestDog_List(t *testing.T) {
 		fields    fields
 		args      args
 		mocksInit func()
-		want      domain.DogList
+		want      domain.DogPage
 		wantErr   bool
 	}{
 		{
@@ -85,10 +110,10 @@ func TestDog_List(t *testing.T) {
 			},
 			mocksInit: func() {
 				mock.ExpectQuery("select").
-					WithArgs(userID, pag.PerPage, pag.PerPage*(pag.Page-1)).
+					WithArgs(userID, pag.PerPage+1, pag.PerPage*(pag.Page-1)).
 					WillReturnError(testingError)
 			},
-			want:    nil,
+			want:    domain.DogPage{},
 			wantErr: true,
 		},
 		{
@@ -102,15 +127,15 @@ func TestDog_List(t *testing.T) {
 				pagination: pag,
 			},
 			mocksInit: func() {
-				rows := sqlmock.NewRows([]string{"id", "user_id", "name", "sex", "age", "breed", "image", "created_at", "updated_at"}).
-					AddRow(dog1ID, userID, "dog1", "male", 2, "test_breed_1", "http://dog-images.com/test.jpg", dogsTime, dogsTime).
-					AddRow(dog2ID, userID, "dog2", "male", "wrong-age-type", "test_breed_1", "http://dog-images.com/test.jpg", dogsTime, dogsTime)
+				rows := sqlmock.NewRows([]string{"id", "user_id", "name", "sex", "age", "breed", "created_at", "updated_at"}).
+					AddRow(dog1ID, userID, "dog1", "male", 2, "test_breed_1", dogsTime, dogsTime).
+					AddRow(dog2ID, userID, "dog2", "male", "wrong-age-type", "test_breed_1", dogsTime, dogsTime)
 
 				mock.ExpectQuery("select").
-					WithArgs(userID, pag.PerPage, pag.PerPage*(pag.Page-1)).
+					WithArgs(userID, pag.PerPage+1, pag.PerPage*(pag.Page-1)).
 					WillReturnRows(rows)
 			},
-			want:    nil,
+			want:    domain.DogPage{},
 			wantErr: true,
 		},
 		{
@@ -124,15 +149,149 @@ func TestDog_List(t *testing.T) {
 				pagination: pag,
 			},
 			mocksInit: func() {
-				rows := sqlmock.NewRows([]string{"id", "user_id", "name", "sex", "age", "breed", "image", "created_at", "updated_at"}).
-					AddRow(dog1ID, userID, "dog1", "male", 2, "test_breed_1", "http://dog-images.com/test.jpg", dogsTime, dogsTime).
-					AddRow(dog2ID, userID, "dog2", "female", 3, "test_breed_1", "http://dog-images.com/test.jpg", dogsTime, dogsTime)
+				rows := sqlmock.NewRows([]string{"id", "user_id", "name", "sex", "age", "breed", "created_at", "updated_at"}).
+					AddRow(dog1ID, userID, "dog1", "male", 2, "test_breed_1", dogsTime, dogsTime).
+					AddRow(dog2ID, userID, "dog2", "female", 3, "test_breed_1", dogsTime, dogsTime)
 
 				mock.ExpectQuery("select").
-					WithArgs(userID, pag.PerPage, pag.PerPage*(pag.Page-1)).
+					WithArgs(userID, pag.PerPage+1, pag.PerPage*(pag.Page-1)).
+					WillReturnRows(rows)
+				mock.ExpectQuery(`select \* from dog_photos where dog_id = any\(\$1::uuid\[\]\) order by position`).
+					WithArgs(pq.StringArray{dog1ID.String(), dog2ID.String()}).
+					WillReturnRows(sqlmock.NewRows(dogPhotoColumns).
+						AddRow(photo.ID, dog1ID, 0, dogImageURL, dogImageURL, dogImageURL, nil, true, dogsTime))
+			},
+			want:    domain.DogPage{Dogs: expectedList},
+			wantErr: false,
+		},
+		{
+			name: "filtered and sorted",
+			fields: fields{
+				db: sqlx.NewDb(db, "postgres"),
+			},
+			args: args{
+				ctx:        context.TODO(),
+				userID:     userID,
+				filter:     filter,
+				pagination: pag,
+			},
+			mocksInit: func() {
+				rows := sqlmock.NewRows([]string{"id", "user_id", "name", "sex", "age", "breed", "created_at", "updated_at"}).
+					AddRow(dog2ID, userID, "dog2", "female", 3, "test_breed_1", dogsTime, dogsTime)
+
+				query := `where d.user_id != \$1 and u.deletion_scheduled_at is null and d.sex = \$2 and d.age >= \$3 ` +
+					`and d.age <= \$4 and lower\(d.breed\) = any\(\$5\) and d.created_at > \$6\s+` +
+					`order by d.age asc, d.id asc\s+limit \$7 offset \$8`
+				mock.ExpectQuery(query).
+					WithArgs(userID, "female", minAge, maxAge, pq.StringArray{"test_breed_1", "poodle"}, createdAfter, pag.PerPage+1, 0).
+					WillReturnRows(rows)
+				mock.ExpectQuery("select \\* from dog_photos").
+					WithArgs(pq.StringArray{dog2ID.String()}).
+					WillReturnRows(sqlmock.NewRows(dogPhotoColumns))
+			},
+			want:    domain.DogPage{Dogs: expectedList[1:]},
+			wantErr: false,
+		},
+		{
+			name: "near point within radius sorted by distance",
+			fields: fields{
+				db: sqlx.NewDb(db, "postgres"),
+			},
+			args: args{
+				ctx:    context.TODO(),
+				userID: userID,
+				filter: domain.DogFilter{
+					Near:     &domain.GeoPoint{Latitude: 49.8397, Longitude: 24.0297},
+					RadiusKm: 10,
+					Sort:     domain.SortDistanceAsc,
+				},
+				pagination: pag,
+			},
+			mocksInit: func() {
+				rows := sqlmock.NewRows([]string{"id", "user_id", "name", "sex", "age", "breed", "city", "latitude", "longitude", "distance", "created_at", "updated_at"}).
+					AddRow(dog2ID, userID, "dog2", "female", 3, "test_breed_1", "Lviv", 49.8, 24.0, 2.3, dogsTime, dogsTime)
+
+				// the distance is computed from the dog location snapped to its cell.
+				query := `select d.\*, 6371 \* 2 \* asin\(.+round\(d.latitude / 0.02\) \* 0.02.+round\(d.longitude / 0.02\) \* 0.02.+\) as distance from dogs d.+` +
+					`and d.latitude between \$4 and \$5 and d.longitude between \$6 and \$7 and 6371 \* 2 \* asin\(.+\) <= \$8\s+` +
+					`order by distance asc, d.id asc\s+limit \$9 offset \$10`
+				mock.ExpectQuery(query).
+					WithArgs(userID, 49.8397, 24.0297, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), 10.0, pag.PerPage+1, 0).
 					WillReturnRows(rows)
+				mock.ExpectQuery("select \\* from dog_photos").
+					WithArgs(pq.StringArray{dog2ID.String()}).
+					WillReturnRows(sqlmock.NewRows(dogPhotoColumns))
 			},
-			want:    expectedList,
+			want: domain.DogPage{Dogs: domain.DogList{{
+				ID:        dog2ID,
+				UserID:    userID,
+				Name:      "dog2",
+				Sex:       "female",
+				Age:       3,
+				Breed:     "test_breed_1",
+				City:      "Lviv",
+				Location:  &domain.GeoPoint{Latitude: 49.8, Longitude: 24.0},
+				Distance:  &nearDistance,
+				CreatedAt: dogsTime,
+				UpdatedAt: dogsTime,
+			}}},
+			wantErr: false,
+		},
+		{
+			name: "page after cursor",
+			fields: fields{
+				db: sqlx.NewDb(db, "postgres"),
+			},
+			args: args{
+				ctx:        context.TODO(),
+				userID:     userID,
+				filter:     domain.DogFilter{Sort: domain.SortCreatedDesc},
+				pagination: domain.Pagination{Page: 3, PerPage: 1, After: &after},
+			},
+			mocksInit: func() {
+				rows := sqlmock.NewRows([]string{"id", "user_id", "name", "sex", "age", "breed", "created_at", "updated_at"}).
+					AddRow(dog2ID, userID, "dog2", "female", 3, "test_breed_1", dogsTime, dogsTime).
+					AddRow(dog1ID, userID, "dog1", "male", 2, "test_breed_1", dogsTime, dogsTime)
+
+				query := `where d.user_id != \$1 and u.deletion_scheduled_at is null and \(d.created_at, d.id\) < \(\$2, \$3\)\s+` +
+					`order by d.created_at desc, d.id desc\s+limit \$4 offset \$5`
+				mock.ExpectQuery(query).
+					WithArgs(userID, after.CreatedAt, after.ID, 2, 0).
+					WillReturnRows(rows)
+				mock.ExpectQuery("select \\* from dog_photos").
+					WithArgs(pq.StringArray{dog2ID.String()}).
+					WillReturnRows(sqlmock.NewRows(dogPhotoColumns))
+			},
+			want: domain.DogPage{
+				Dogs:       expectedList[1:],
+				NextCursor: &domain.Cursor{CreatedAt: dogsTime, ID: dog2ID},
+			},
+			wantErr: false,
+		},
+		{
+			name: "no cursor for not creation time order",
+			fields: fields{
+				db: sqlx.NewDb(db, "postgres"),
+			},
+			args: args{
+				ctx:        context.TODO(),
+				userID:     userID,
+				filter:     domain.DogFilter{Sort: domain.SortNameAsc},
+				pagination: domain.Pagination{Page: 1, PerPage: 1},
+			},
+			mocksInit: func() {
+				rows := sqlmock.NewRows([]string{"id", "user_id", "name", "sex", "age", "breed", "created_at", "updated_at"}).
+					AddRow(dog2ID, userID, "dog2", "female", 3, "test_breed_1", dogsTime, dogsTime).
+					AddRow(dog1ID, userID, "dog1", "male", 2, "test_breed_1", dogsTime, dogsTime)
+
+				mock.ExpectQuery(`order by d.name asc, d.id asc\s+limit \$2 offset \$3`).
+					WithArgs(userID, 2, 0).
+					WillReturnRows(rows)
+				mock.ExpectQuery("select \\* from dog_photos").
+					WithArgs(pq.StringArray{dog2ID.String()}).
+					WillReturnRows(sqlmock.NewRows(dogPhotoColumns))
+			},
+			want:    domain.DogPage{Dogs: expectedList[1:]},
 			wantErr: false,
 		},
 	}
@@ -141,9 +300,10 @@ func TestDog_List(t *testing.T) {
 			tt.mocksInit()
 
 			d := NewDog(tt.fields.db)
-			got, err := d.List(tt.args.ctx, tt.args.userID, tt.args.pagination)
+			got, err := d.List(tt.args.ctx, tt.args.userID, tt.args.filter, tt.args.pagination)
 			assert.Equal(t, tt.wantErr, err != nil)
 			assert.Equal(t, tt.want, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
 		})
 	}
 }
@@ -159,6 +319,12 @@ func TestDog_Get(t *testing.T) {
 	dogID := uuid.New()
 	userID := uuid.New()
 	dogTime := time.Now()
+	photo := domain.DogPhoto{
+		ID:        uuid.New(),
+		Images:    domain.DogImages{Thumb: "http://dog-images.com/thumb.jpg", Card: "http://dog-images.com/card.jpg", Full: dogImageURL},
+		Primary:   true,
+		CreatedAt: dogTime,
+	}
 
 	expectedDog := domain.Dog{
 		ID:        dogID,
@@ -167,7 +333,7 @@ func TestDog_Get(t *testing.T) {
 		Sex:       "male",
 		Age:       2,
 		Breed:     "test_breed_1",
-		Image:     "http://dog-images.com/test.jpg",
+		Photos:    []domain.DogPhoto{photo},
 		CreatedAt: dogTime,
 		UpdatedAt: dogTime,
 	}
@@ -231,12 +397,16 @@ func TestDog_Get(t *testing.T) {
 				uid: dogID,
 			},
 			mocksInit: func() {
-				rows := sqlmock.NewRows([]string{"id", "user_id", "name", "sex", "age", "breed", "image", "created_at", "updated_at"}).
-					AddRow(dogID, userID, "dog1", "male", 2, "test_breed_1", "http://dog-images.com/test.jpg", dogTime, dogTime)
+				rows := sqlmock.NewRows([]string{"id", "user_id", "name", "sex", "age", "breed", "created_at", "updated_at"}).
+					AddRow(dogID, userID, "dog1", "male", 2, "test_breed_1", dogTime, dogTime)
 
 				mock.ExpectQuery("select").
 					WithArgs(dogID).
 					WillReturnRows(rows)
+				mock.ExpectQuery("select \\* from dog_photos").
+					WithArgs(pq.StringArray{dogID.String()}).
+					WillReturnRows(sqlmock.NewRows(dogPhotoColumns).
+						AddRow(photo.ID, dogID, 0, photo.Images.Full, photo.Images.Thumb, photo.Images.Card, nil, true, dogTime))
 			},
 			want:    expectedDog,
 			wantErr: false,
@@ -254,6 +424,140 @@ func TestDog_Get(t *testing.T) {
 	}
 }
 
+func TestBoundingBox(t *testing.T) {
+	tests := []struct {
+		name     string
+		center   domain.GeoPoint
+		radiusKm float64
+		want     geoBox
+	}{
+		{
+			name:     "equator",
+			center:   domain.GeoPoint{},
+			radiusKm: 111.19,
+			want:     geoBox{minLat: -1, maxLat: 1, minLng: -1, maxLng: 1},
+		},
+		{
+			name:     "longitude range widens towards poles",
+			center:   domain.GeoPoint{Latitude: 60, Longitude: 30},
+			radiusKm: 111.19,
+			want:     geoBox{minLat: 59, maxLat: 61, minLng: 28, maxLng: 32},
+		},
+		{
+			name:     "pole within radius",
+			center:   domain.GeoPoint{Latitude: 89.5, Longitude: 30},
+			radiusKm: 111.19,
+			want:     geoBox{minLat: 88.5, maxLat: 90, anyLng: true},
+		},
+		{
+			name:     "antimeridian within radius",
+			center:   domain.GeoPoint{Latitude: 0, Longitude: 179.5},
+			radiusKm: 111.19,
+			want:     geoBox{minLat: -1, maxLat: 1, minLng: 178.5, maxLng: 180.5, anyLng: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := boundingBox(tt.center, tt.radiusKm)
+			assert.Equal(t, tt.want.anyLng, got.anyLng)
+			assert.InDelta(t, tt.want.minLat, got.minLat, 0.01)
+			assert.InDelta(t, tt.want.maxLat, got.maxLat, 0.01)
+			// longitude range isn't computed when the box contains a pole.
+			if tt.want.minLng != 0 || tt.want.maxLng != 0 {
+				assert.InDelta(t, tt.want.minLng, got.minLng, 0.01)
+				assert.InDelta(t, tt.want.maxLng, got.maxLng, 0.01)
+			}
+		})
+	}
+}
+
+func TestGeoBox_Grow(t *testing.T) {
+	got := geoBox{minLat: 49, maxLat: 51, minLng: 23, maxLng: 25}.grow(0.01)
+	assert.Equal(t, geoBox{minLat: 48.99, maxLat: 51.01, minLng: 22.99, maxLng: 25.01}, got)
+
+	got = geoBox{minLat: -1, maxLat: 1, minLng: 178, maxLng: 179.995}.grow(0.01)
+	assert.True(t, got.anyLng, "box crossing the antimeridian must match any longitude")
+}
+
+func TestDog_Feed(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	dogsTime := time.Now()
+	dog := domain.Dog{ID: uuid.New(), UserID: uuid.New()}
+	candidateID := uuid.New()
+	candidateUserID := uuid.New()
+	pag := domain.Pagination{Page: 2, PerPage: 10}
+	filter := domain.DogFilter{Sex: domain.Male, Sort: domain.SortNameAsc}
+
+	query := `inner join dogs a on a.id = \$2\s+left join dog_preferences ap on ap.dog_id = a.id\s+` +
+		`left join dog_preferences dp on dp.dog_id = d.id\s+` +
+		`where d.user_id != \$1 and u.deletion_scheduled_at is null ` +
+		`and not exists \(select 1 from reactions r where r.liker_id = \$2 and r.liked_id = d.id\) ` +
+		`and \(\s+\(ap.sex is null or d.sex = ap.sex\).+round\(a.latitude / 0.02\) \* 0.02.+round\(d.latitude / 0.02\) \* 0.02.+<= ap.max_distance_km\)\s+\) ` +
+		`and \(\s+\(dp.sex is null or a.sex = dp.sex\).+<= dp.max_distance_km\)\s+\) ` +
+		`and d.sex = \$3\s+order by d.name asc, d.id asc\s+limit \$4 offset \$5`
+
+	t.Run("query error", func(t *testing.T) {
+		mock.ExpectQuery(query).
+			WithArgs(dog.UserID, dog.ID, "male", pag.PerPage+1, pag.PerPage).
+			WillReturnError(errors.New("testing-error"))
+
+		_, err := NewDog(sqlx.NewDb(db, "postgres")).Feed(context.TODO(), dog, filter, pag)
+		assert.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("success", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"id", "user_id", "name", "sex", "age", "breed", "created_at", "updated_at"}).
+			AddRow(candidateID, candidateUserID, "dog", "male", 2, "test_breed_1", dogsTime, dogsTime)
+
+		mock.ExpectQuery(query).
+			WithArgs(dog.UserID, dog.ID, "male", pag.PerPage+1, pag.PerPage).
+			WillReturnRows(rows)
+		mock.ExpectQuery("select \\* from dog_photos").
+			WithArgs(pq.StringArray{candidateID.String()}).
+			WillReturnRows(sqlmock.NewRows(dogPhotoColumns))
+
+		got, err := NewDog(sqlx.NewDb(db, "postgres")).Feed(context.TODO(), dog, filter, pag)
+		assert.NoError(t, err)
+		assert.Equal(t, domain.DogPage{Dogs: domain.DogList{{
+			ID:        candidateID,
+			UserID:    candidateUserID,
+			Name:      "dog",
+			Sex:       "male",
+			Age:       2,
+			Breed:     "test_breed_1",
+			CreatedAt: dogsTime,
+			UpdatedAt: dogsTime,
+		}}}, got)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("page after cursor", func(t *testing.T) {
+		after := domain.Cursor{CreatedAt: dogsTime.Add(time.Minute), ID: uuid.New()}
+		rows := sqlmock.NewRows([]string{"id", "user_id", "name", "sex", "age", "breed", "created_at", "updated_at"}).
+			AddRow(candidateID, candidateUserID, "dog", "male", 2, "test_breed_1", dogsTime, dogsTime)
+
+		mock.ExpectQuery(`and d.sex = \$3 and \(d.created_at, d.id\) < \(\$4, \$5\)\s+order by d.created_at desc, d.id desc\s+limit \$6 offset \$7`).
+			WithArgs(dog.UserID, dog.ID, "male", after.CreatedAt, after.ID, pag.PerPage+1, 0).
+			WillReturnRows(rows)
+		mock.ExpectQuery("select \\* from dog_photos").
+			WithArgs(pq.StringArray{candidateID.String()}).
+			WillReturnRows(sqlmock.NewRows(dogPhotoColumns))
+
+		createdDesc := domain.DogFilter{Sex: domain.Male, Sort: domain.SortCreatedDesc}
+		got, err := NewDog(sqlx.NewDb(db, "postgres")).Feed(context.TODO(), dog, createdDesc, domain.Pagination{Page: 2, PerPage: 10, After: &after})
+		assert.NoError(t, err)
+		assert.Len(t, got.Dogs, 1)
+		assert.Nil(t, got.NextCursor)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
 func TestDog_Matches(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -271,6 +575,8 @@ func TestDog_Matches(t *testing.T) {
 	}
 
 	dogsTime := time.Now()
+	matchedTime := dogsTime.Add(time.Hour)
+	after := domain.Cursor{CreatedAt: matchedTime.Add(time.Minute), ID: uuid.New()}
 	dog1ID := uuid.New()
 	dog2ID := uuid.New()
 	expectedList := domain.DogList{
@@ -281,7 +587,6 @@ func TestDog_Matches(t *testing.T) {
 			Sex:       "male",
 			Age:       2,
 			Breed:     "test_breed_1",
-			Image:     "http://dog-images.com/test.jpg",
 			CreatedAt: dogsTime,
 			UpdatedAt: dogsTime,
 		},
@@ -292,7 +597,6 @@ func TestDog_Matches(t *testing.T) {
 			Sex:       "female",
 			Age:       3,
 			Breed:     "test_breed_1",
-			Image:     "http://dog-images.com/test.jpg",
 			CreatedAt: dogsTime,
 			UpdatedAt: dogsTime,
 		},
@@ -311,7 +615,7 @@ func TestDog_Matches(t *testing.T) {
 		fields    fields
 		args      args
 		mocksInit func()
-		want      domain.DogList
+		want      domain.DogPage
 		wantErr   bool
 	}{
 		{
@@ -326,10 +630,10 @@ func TestDog_Matches(t *testing.T) {
 			},
 			mocksInit: func() {
 				mock.ExpectQuery("select").
-					WithArgs(dID, domain.Like, pag.PerPage, pag.PerPage*(pag.Page-1)).
+					WithArgs(dID, domain.Like, pag.PerPage+1, pag.PerPage*(pag.Page-1)).
 					WillReturnError(testingError)
 			},
-			want:    nil,
+			want:    domain.DogPage{},
 			wantErr: true,
 		},
 		{
@@ -343,15 +647,15 @@ func TestDog_Matches(t *testing.T) {
 				pagination: pag,
 			},
 			mocksInit: func() {
-				rows := sqlmock.NewRows([]string{"id", "user_id", "name", "sex", "age", "breed", "image", "created_at", "updated_at"}).
-					AddRow(dog1ID, userID, "dog1", "male", 2, "test_breed_1", "http://dog-images.com/test.jpg", dogsTime, dogsTime).
-					AddRow(dog2ID, userID, "dog2", "female", "wrong-age", "test_breed_1", "http://dog-images.com/test.jpg", dogsTime, dogsTime)
+				rows := sqlmock.NewRows([]string{"id", "user_id", "name", "sex", "age", "breed", "created_at", "updated_at"}).
+					AddRow(dog1ID, userID, "dog1", "male", 2, "test_breed_1", dogsTime, dogsTime).
+					AddRow(dog2ID, userID, "dog2", "female", "wrong-age", "test_breed_1", dogsTime, dogsTime)
 
 				mock.ExpectQuery("select").
-					WithArgs(dID, domain.Like, pag.PerPage, pag.PerPage*(pag.Page-1)).
+					WithArgs(dID, domain.Like, pag.PerPage+1, pag.PerPage*(pag.Page-1)).
 					WillReturnRows(rows)
 			},
-			want:    nil,
+			want:    domain.DogPage{},
 			wantErr: true,
 		},
 		{
@@ -365,15 +669,46 @@ func TestDog_Matches(t *testing.T) {
 				pagination: pag,
 			},
 			mocksInit: func() {
-				rows := sqlmock.NewRows([]string{"id", "user_id", "name", "sex", "age", "breed", "image", "created_at", "updated_at"}).
-					AddRow(dog1ID, userID, "dog1", "male", 2, "test_breed_1", "http://dog-images.com/test.jpg", dogsTime, dogsTime).
-					AddRow(dog2ID, userID, "dog2", "female", 3, "test_breed_1", "http://dog-images.com/test.jpg", dogsTime, dogsTime)
+				rows := sqlmock.NewRows([]string{"id", "user_id", "name", "sex", "age", "breed", "created_at", "updated_at"}).
+					AddRow(dog1ID, userID, "dog1", "male", 2, "test_breed_1", dogsTime, dogsTime).
+					AddRow(dog2ID, userID, "dog2", "female", 3, "test_breed_1", dogsTime, dogsTime)
 
 				mock.ExpectQuery("select").
-					WithArgs(dID, domain.Like, pag.PerPage, pag.PerPage*(pag.Page-1)).
+					WithArgs(dID, domain.Like, pag.PerPage+1, pag.PerPage*(pag.Page-1)).
+					WillReturnRows(rows)
+				mock.ExpectQuery("select \\* from dog_photos").
+					WithArgs(pq.StringArray{dog1ID.String(), dog2ID.String()}).
+					WillReturnRows(sqlmock.NewRows(dogPhotoColumns))
+			},
+			want:    domain.DogPage{Dogs: expectedList},
+			wantErr: false,
+		},
+		{
+			name: "page after cursor",
+			fields: fields{
+				db: sqlx.NewDb(db, "postgres"),
+			},
+			args: args{
+				ctx:        context.TODO(),
+				dogID:      dID,
+				pagination: domain.Pagination{Page: 1, PerPage: 1, After: &after},
+			},
+			mocksInit: func() {
+				rows := sqlmock.NewRows([]string{"id", "user_id", "name", "sex", "age", "breed", "created_at", "updated_at", "matched_at"}).
+					AddRow(dog1ID, userID, "dog1", "male", 2, "test_breed_1", dogsTime, dogsTime, matchedTime).
+					AddRow(dog2ID, userID, "dog2", "female", 3, "test_breed_1", dogsTime, dogsTime, matchedTime)
+
+				mock.ExpectQuery(`and \(r1.created_at, r1.liker_id\) < \(\$4, \$5\)\s+order by r1.created_at DESC, r1.liker_id DESC\s+limit \$3 offset \$6`).
+					WithArgs(dID, domain.Like, 2, after.CreatedAt, after.ID, 0).
 					WillReturnRows(rows)
+				mock.ExpectQuery("select \\* from dog_photos").
+					WithArgs(pq.StringArray{dog1ID.String()}).
+					WillReturnRows(sqlmock.NewRows(dogPhotoColumns))
+			},
+			want: domain.DogPage{
+				Dogs:       expectedList[:1],
+				NextCursor: &domain.Cursor{CreatedAt: matchedTime, ID: dog1ID},
 			},
-			want:    expectedList,
 			wantErr: false,
 		},
 	}
@@ -401,18 +736,24 @@ func TestDog_Create(t *testing.T) {
 	dogID := uuid.New()
 	userID := uuid.New()
 	dogTime := time.Now()
+	images := domain.ExternalDogImages(dogImageURL)
 
 	dogIn := domain.Dog{
-		UserID:    userID,
-		Name:      "dog1",
-		Sex:       "male",
-		Age:       2,
-		Breed:     "test_breed_1",
-		Image:     "http://dog-images.com/test.jpg",
-		CreatedAt: dogTime,
-		UpdatedAt: dogTime,
+		UserID: userID,
+		Name:   "dog1",
+		Sex:    "male",
+		Age:    2,
+		Breed:  "test_breed_1",
+		Photos: []domain.DogPhoto{{Images: images, Primary: true}},
+		City:   "Lviv",
+		Location: &domain.GeoPoint{
+			Latitude:  49.8397,
+			Longitude: 24.0297,
+		},
 	}
+	lat, lng := dogIn.Location.Latitude, dogIn.Location.Longitude
 
+	photoID := uuid.New()
 	dogOut := domain.Dog{
 		ID:        dogID,
 		UserID:    userID,
@@ -420,59 +761,67 @@ func TestDog_Create(t *testing.T) {
 		Sex:       "male",
 		Age:       2,
 		Breed:     "test_breed_1",
-		Image:     "http://dog-images.com/test.jpg",
+		Photos:    []domain.DogPhoto{{ID: photoID, Images: images, Primary: true, CreatedAt: dogTime}},
+		City:      "Lviv",
+		Location:  dogIn.Location,
 		CreatedAt: dogTime,
 		UpdatedAt: dogTime,
 	}
 
-	type fields struct {
-		db *sqlx.DB
-	}
-	type args struct {
-		ctx context.Context
-		dog domain.Dog
-	}
 	tests := []struct {
 		name      string
-		fields    fields
-		args      args
 		mocksInit func()
 		want      domain.Dog
 		wantErr   bool
 	}{
 		{
 			name: "execution insert query error",
-			fields: fields{
-				db: sqlx.NewDb(db, "postgres"),
-			},
-			args: args{
-				ctx: context.TODO(),
-				dog: dogIn,
+			mocksInit: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("insert into dogs").
+					WithArgs(dogIn.UserID, dogIn.Name, dogIn.Sex, dogIn.Age, dogIn.Breed, dogIn.City, lat, lng).
+					WillReturnError(testingError)
+				mock.ExpectRollback()
 			},
+			want:    domain.Dog{},
+			wantErr: true,
+		},
+		{
+			name: "photo insert error rolls back",
 			mocksInit: func() {
-				mock.ExpectQuery("insert").
-					WithArgs(dogIn.UserID, dogIn.Name, dogIn.Sex, dogIn.Age, dogIn.Breed, dogIn.Image).
+				rows := sqlmock.NewRows([]string{"id", "user_id", "name", "sex", "age", "breed", "city", "latitude", "longitude", "created_at", "updated_at"}).
+					AddRow(dogID, userID, "dog1", "male", 2, "test_breed_1", "Lviv", lat, lng, dogTime, dogTime)
+
+				mock.ExpectBegin()
+				mock.ExpectQuery("insert into dogs").
+					WithArgs(dogIn.UserID, dogIn.Name, dogIn.Sex, dogIn.Age, dogIn.Breed, dogIn.City, lat, lng).
+					WillReturnRows(rows)
+				mock.ExpectExec("insert into dog_photos").
+					WithArgs(dogID, 0, images.Full, images.Thumb, images.Card, nil, true).
 					WillReturnError(testingError)
+				mock.ExpectRollback()
 			},
 			want:    domain.Dog{},
 			wantErr: true,
 		},
 		{
 			name: "success",
-			fields: fields{
-				db: sqlx.NewDb(db, "postgres"),
-			},
-			args: args{
-				ctx: context.TODO(),
-				dog: dogIn,
-			},
 			mocksInit: func() {
-				rows := sqlmock.NewRows([]string{"id", "user_id", "name", "sex", "age", "breed", "image", "created_at", "updated_at"}).
-					AddRow(dogOut.ID, userID, "dog1", "male", 2, "test_breed_1", "http://dog-images.com/test.jpg", dogTime, dogTime)
+				rows := sqlmock.NewRows([]string{"id", "user_id", "name", "sex", "age", "breed", "city", "latitude", "longitude", "created_at", "updated_at"}).
+					AddRow(dogID, userID, "dog1", "male", 2, "test_breed_1", "Lviv", lat, lng, dogTime, dogTime)
 
-				mock.ExpectQuery("insert").
-					WithArgs(dogIn.UserID, dogIn.Name, dogIn.Sex, dogIn.Age, dogIn.Breed, dogIn.Image).
+				mock.ExpectBegin()
+				mock.ExpectQuery("insert into dogs").
+					WithArgs(dogIn.UserID, dogIn.Name, dogIn.Sex, dogIn.Age, dogIn.Breed, dogIn.City, lat, lng).
 					WillReturnRows(rows)
+				mock.ExpectExec("insert into dog_photos").
+					WithArgs(dogID, 0, images.Full, images.Thumb, images.Card, nil, true).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery("select \\* from dog_photos").
+					WithArgs(pq.StringArray{dogID.String()}).
+					WillReturnRows(sqlmock.NewRows(dogPhotoColumns).
+						AddRow(photoID, dogID, 0, images.Full, images.Thumb, images.Card, nil, true, dogTime))
+				mock.ExpectCommit()
 			},
 			want:    dogOut,
 			wantErr: false,
@@ -482,10 +831,10 @@ func TestDog_Create(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mocksInit()
 
-			d := NewDog(tt.fields.db)
-			got, err := d.Create(tt.args.ctx, tt.args.dog)
+			got, err := NewDog(sqlx.NewDb(db, "postgres")).Create(context.TODO(), dogIn)
 			assert.Equal(t, tt.wantErr, err != nil)
 			assert.Equal(t, tt.want, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
 		})
 	}
 }
@@ -502,16 +851,17 @@ func TestDog_Update(t *testing.T) {
 	dogID := uuid.New()
 	userID := uuid.New()
 	dogTime := time.Now()
+	primaryID := uuid.New()
+	otherID := uuid.New()
+	primary := domain.ExternalDogImages("http://dog-images.com/primary.jpg")
+	other := domain.ExternalDogImages("http://dog-images.com/other.jpg")
 
 	dogIn := domain.Dog{
-		UserID:    userID,
-		Name:      "dog1",
-		Sex:       "male",
-		Age:       2,
-		Breed:     "test_breed_1",
-		Image:     "http://dog-images.com/test.jpg",
-		CreatedAt: dogTime,
-		UpdatedAt: dogTime,
+		UserID: userID,
+		Name:   "dog1",
+		Sex:    "male",
+		Age:    2,
+		Breed:  "test_breed_1",
 	}
 
 	dogOut := domain.Dog{
@@ -521,64 +871,69 @@ func TestDog_Update(t *testing.T) {
 		Sex:       "male",
 		Age:       2,
 		Breed:     "test_breed_1",
-		Image:     "http://dog-images.com/test.jpg",
 		CreatedAt: dogTime,
 		UpdatedAt: dogTime,
 	}
 
-	type fields struct {
-		db *sqlx.DB
+	withPhotosOut := dogOut
+	withPhotosOut.Photos = []domain.DogPhoto{
+		{ID: primaryID, Images: primary, Primary: true, CreatedAt: dogTime},
+		{ID: otherID, Images: other, CreatedAt: dogTime},
 	}
-	type args struct {
-		ctx context.Context
-		uid uuid.UUID
-		dog domain.Dog
+
+	dogRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "user_id", "name", "sex", "age", "breed", "created_at", "updated_at"}).
+			AddRow(dogID, userID, "dog1", "male", 2, "test_breed_1", dogTime, dogTime)
 	}
+
 	tests := []struct {
 		name      string
-		fields    fields
-		args      args
 		mocksInit func()
 		want      domain.Dog
+		wantCode  ierr.Code
 		wantErr   bool
 	}{
 		{
-			name: "execution update query error",
-			fields: fields{
-				db: sqlx.NewDb(db, "postgres"),
-			},
-			args: args{
-				ctx: context.TODO(),
-				uid: dogID,
-				dog: dogIn,
+			name: "dog not found",
+			mocksInit: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("for update").WithArgs(dogID).WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
 			},
+			want:     domain.Dog{},
+			wantCode: ierr.NotFound,
+			wantErr:  true,
+		},
+		{
+			name: "execution update query error",
 			mocksInit: func() {
-				mock.ExpectQuery("update").
-					WithArgs(dogIn.Name, dogIn.Sex, dogIn.Age, dogIn.Breed, dogIn.Image, dogID).
+				mock.ExpectBegin()
+				mock.ExpectQuery("for update").WithArgs(dogID).WillReturnRows(dogRows())
+				mock.ExpectQuery("update dogs").
+					WithArgs(dogIn.Name, dogIn.Sex, dogIn.Age, dogIn.Breed, dogIn.City, nil, nil, dogID).
 					WillReturnError(testingError)
+				mock.ExpectRollback()
 			},
-			want:    domain.Dog{},
-			wantErr: true,
+			want:     domain.Dog{},
+			wantCode: ierr.Internal,
+			wantErr:  true,
 		},
 		{
-			name: "success",
-			fields: fields{
-				db: sqlx.NewDb(db, "postgres"),
-			},
-			args: args{
-				ctx: context.TODO(),
-				uid: dogID,
-				dog: dogIn,
-			},
+			name: "photos are kept",
 			mocksInit: func() {
-				rows := sqlmock.NewRows([]string{"id", "user_id", "name", "sex", "age", "breed", "image", "created_at", "updated_at"}).
-					AddRow(dogID, userID, "dog1", "male", 2, "test_breed_1", "http://dog-images.com/test.jpg", dogTime, dogTime)
-
-				mock.ExpectQuery("update").
-					WithArgs(dogIn.Name, dogIn.Sex, dogIn.Age, dogIn.Breed, dogIn.Image, dogID).
-					WillReturnRows(rows)
+				mock.ExpectBegin()
+				mock.ExpectQuery("for update").WithArgs(dogID).WillReturnRows(dogRows())
+				mock.ExpectQuery("update dogs").
+					WithArgs(dogIn.Name, dogIn.Sex, dogIn.Age, dogIn.Breed, dogIn.City, nil, nil, dogID).
+					WillReturnRows(dogRows())
+				mock.ExpectQuery("select \\* from dog_photos").
+					WithArgs(pq.StringArray{dogID.String()}).
+					WillReturnRows(sqlmock.NewRows(dogPhotoColumns).
+						AddRow(primaryID, dogID, 0, primary.Full, primary.Thumb, primary.Card, nil, true, dogTime).
+						AddRow(otherID, dogID, 1, other.Full, other.Thumb, other.Card, nil, false, dogTime))
+				mock.ExpectCommit()
 			},
-			want:    dogOut,
+			want:    withPhotosOut,
 			wantErr: false,
 		},
 	}
@@ -586,10 +941,14 @@ func TestDog_Update(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mocksInit()
 
-			d := NewDog(tt.fields.db)
-			got, err := d.Update(tt.args.ctx, tt.args.uid, tt.args.dog)
+			got, err := NewDog(sqlx.NewDb(db, "postgres")).Update(context.TODO(), dogID, dogIn)
 			assert.Equal(t, tt.wantErr, err != nil)
+			if tt.wantErr {
+				assert.Equal(t, tt.wantCode, ierr.GetCode(err))
+			}
+
 			assert.Equal(t, tt.want, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
 		})
 	}
 }
@@ -603,47 +962,42 @@ func TestDog_Delete(t *testing.T) {
 
 	testingError := errors.New("testing-error")
 	dogID := uuid.New()
-
-	type fields struct {
-		db *sqlx.DB
-	}
-	type args struct {
-		ctx context.Context
-		uid uuid.UUID
+	userID := uuid.New()
+	createdAt := time.Now()
+	dogRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "user_id", "name", "sex", "age", "breed", "created_at", "updated_at"}).
+			AddRow(dogID, userID, "Spike", "male", 5, "Bulldog", createdAt, createdAt)
 	}
+
 	tests := []struct {
 		name      string
-		fields    fields
-		args      args
 		mocksInit func()
+		want      []domain.DogImages
 		wantErr   bool
 	}{
 		{
 			name: "execution delete query error",
-			fields: fields{
-				db: sqlx.NewDb(db, "postgres"),
-			},
-			args: args{
-				ctx: context.TODO(),
-				uid: dogID,
-			},
 			mocksInit: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("for update").WithArgs(dogID).WillReturnRows(dogRows())
+				mock.ExpectQuery("select image, image_thumb, image_card from dog_photos").WithArgs(dogID).
+					WillReturnRows(sqlmock.NewRows([]string{"image", "image_thumb", "image_card"}))
 				mock.ExpectExec("delete").WithArgs(dogID).WillReturnError(testingError)
+				mock.ExpectRollback()
 			},
 			wantErr: true,
 		},
 		{
 			name: "success",
-			fields: fields{
-				db: sqlx.NewDb(db, "postgres"),
-			},
-			args: args{
-				ctx: context.TODO(),
-				uid: dogID,
-			},
 			mocksInit: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("for update").WithArgs(dogID).WillReturnRows(dogRows())
+				mock.ExpectQuery("select image, image_thumb, image_card from dog_photos").WithArgs(dogID).
+					WillReturnRows(sqlmock.NewRows([]string{"image", "image_thumb", "image_card"}).AddRow("full", "thumb", "card"))
 				mock.ExpectExec("delete").WithArgs(dogID).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
 			},
+			want:    []domain.DogImages{{Thumb: "thumb", Card: "card", Full: "full"}},
 			wantErr: false,
 		},
 	}
@@ -651,9 +1005,10 @@ func TestDog_Delete(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mocksInit()
 
-			d := NewDog(tt.fields.db)
-			err := d.Delete(tt.args.ctx, tt.args.uid)
+			got, err := NewDog(sqlx.NewDb(db, "postgres")).Delete(context.TODO(), dogID)
 			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.want, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
 		})
 	}
 }
@@ -733,3 +1088,522 @@ func TestDog_AddReaction(t *testing.T) {
 		})
 	}
 }
+
+func TestDog_AddPhoto(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	dogID := uuid.New()
+	userID := uuid.New()
+	photoID := uuid.New()
+	createdAt := time.Now()
+	images := domain.DogImages{
+		Thumb: "http://localhost:8080/media/dogs/thumb.jpg",
+		Card:  "http://localhost:8080/media/dogs/card.jpg",
+		Full:  "http://localhost:8080/media/dogs/full.jpg",
+	}
+	hash := domain.ImageHash(0xF0F0F0F0F0F0F0F0)
+	dogRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "user_id", "name", "sex", "age", "breed", "created_at", "updated_at"}).
+			AddRow(dogID, userID, "Spike", "male", 5, "Bulldog", createdAt, createdAt)
+	}
+
+	tests := []struct {
+		name      string
+		mocksInit func()
+		want      domain.Dog
+		wantCode  ierr.Code
+		wantErr   bool
+	}{
+		{
+			name: "dog not found",
+			mocksInit: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("select \\* from dogs where id=\\$1 for update").WithArgs(dogID).WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
+			},
+			wantCode: ierr.NotFound,
+			wantErr:  true,
+		},
+		{
+			name: "too many photos",
+			mocksInit: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("for update").WithArgs(dogID).WillReturnRows(dogRows())
+				mock.ExpectQuery("select count").WithArgs(dogID).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+				mock.ExpectRollback()
+			},
+			wantCode: ierr.InvalidArgument,
+			wantErr:  true,
+		},
+		{
+			name: "first photo becomes primary",
+			mocksInit: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("for update").WithArgs(dogID).WillReturnRows(dogRows())
+				mock.ExpectQuery("select count").WithArgs(dogID).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectExec("insert into dog_photos").
+					WithArgs(dogID, 0, images.Full, images.Thumb, images.Card, int64(hash), true).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery("select \\* from dog_photos").
+					WithArgs(pq.StringArray{dogID.String()}).
+					WillReturnRows(sqlmock.NewRows(dogPhotoColumns).
+						AddRow(photoID, dogID, 0, images.Full, images.Thumb, images.Card, int64(hash), true, createdAt))
+				mock.ExpectCommit()
+			},
+			want: domain.Dog{
+				ID:        dogID,
+				UserID:    userID,
+				Name:      "Spike",
+				Sex:       "male",
+				Age:       5,
+				Breed:     "Bulldog",
+				Photos:    []domain.DogPhoto{{ID: photoID, Images: images, Hash: &hash, Primary: true, CreatedAt: createdAt}},
+				CreatedAt: createdAt,
+				UpdatedAt: createdAt,
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInit()
+
+			got, err := NewDog(sqlx.NewDb(db, "postgres")).AddPhoto(context.TODO(), dogID, domain.DogPhoto{Images: images, Hash: &hash}, 3)
+			assert.Equal(t, tt.wantErr, err != nil)
+			if tt.wantErr {
+				assert.Equal(t, tt.wantCode, ierr.GetCode(err))
+			}
+
+			assert.Equal(t, tt.want, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestDog_DeletePhoto(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	dogID := uuid.New()
+	userID := uuid.New()
+	photoID := uuid.New()
+	nextID := uuid.New()
+	nextImageURL := "http://dog-images.com/next.jpg"
+	createdAt := time.Now()
+	dogRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "user_id", "name", "sex", "age", "breed", "created_at", "updated_at"}).
+			AddRow(dogID, userID, "Spike", "male", 5, "Bulldog", createdAt, createdAt)
+	}
+
+	tests := []struct {
+		name        string
+		mocksInit   func()
+		want        domain.Dog
+		wantDeleted domain.DogImages
+		wantCode    ierr.Code
+		wantErr     bool
+	}{
+		{
+			name: "photo not found",
+			mocksInit: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("for update").WithArgs(dogID).WillReturnRows(dogRows())
+				mock.ExpectQuery("delete from dog_photos").WithArgs(photoID, dogID).WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
+			},
+			wantCode: ierr.NotFound,
+			wantErr:  true,
+		},
+		{
+			name: "next photo becomes primary",
+			mocksInit: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("for update").WithArgs(dogID).WillReturnRows(dogRows())
+				mock.ExpectQuery("delete from dog_photos").WithArgs(photoID, dogID).
+					WillReturnRows(sqlmock.NewRows(dogPhotoColumns).
+						AddRow(photoID, dogID, 0, dogImageURL, dogImageURL, dogImageURL, nil, true, createdAt))
+				mock.ExpectExec("update dog_photos set position=position-1").WithArgs(dogID, 0).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("update dog_photos set is_primary=true").WithArgs(dogID).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery("select \\* from dog_photos").
+					WithArgs(pq.StringArray{dogID.String()}).
+					WillReturnRows(sqlmock.NewRows(dogPhotoColumns).
+						AddRow(nextID, dogID, 0, nextImageURL, nextImageURL, nextImageURL, nil, true, createdAt))
+				mock.ExpectCommit()
+			},
+			want: domain.Dog{
+				ID:        dogID,
+				UserID:    userID,
+				Name:      "Spike",
+				Sex:       "male",
+				Age:       5,
+				Breed:     "Bulldog",
+				Photos:    []domain.DogPhoto{{ID: nextID, Images: domain.ExternalDogImages(nextImageURL), Primary: true, CreatedAt: createdAt}},
+				CreatedAt: createdAt,
+				UpdatedAt: createdAt,
+			},
+			wantDeleted: domain.ExternalDogImages(dogImageURL),
+			wantErr:     false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInit()
+
+			got, gotDeleted, err := NewDog(sqlx.NewDb(db, "postgres")).DeletePhoto(context.TODO(), dogID, photoID)
+			assert.Equal(t, tt.wantErr, err != nil)
+			if tt.wantErr {
+				assert.Equal(t, tt.wantCode, ierr.GetCode(err))
+			}
+
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantDeleted, gotDeleted)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestDog_ReorderPhotos(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	dogID := uuid.New()
+	userID := uuid.New()
+	photo1ID := uuid.New()
+	photo2ID := uuid.New()
+	createdAt := time.Now()
+	dogRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "user_id", "name", "sex", "age", "breed", "created_at", "updated_at"}).
+			AddRow(dogID, userID, "Spike", "male", 5, "Bulldog", createdAt, createdAt)
+	}
+	currentRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id"}).AddRow(photo1ID).AddRow(photo2ID)
+	}
+
+	tests := []struct {
+		name      string
+		photoIDs  []uuid.UUID
+		mocksInit func()
+		want      domain.Dog
+		wantCode  ierr.Code
+		wantErr   bool
+	}{
+		{
+			name:     "photo listed twice",
+			photoIDs: []uuid.UUID{photo2ID, photo2ID},
+			mocksInit: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("for update").WithArgs(dogID).WillReturnRows(dogRows())
+				mock.ExpectQuery("select id from dog_photos").WithArgs(dogID).WillReturnRows(currentRows())
+				mock.ExpectRollback()
+			},
+			wantCode: ierr.InvalidArgument,
+			wantErr:  true,
+		},
+		{
+			name:     "photo missing",
+			photoIDs: []uuid.UUID{photo2ID},
+			mocksInit: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("for update").WithArgs(dogID).WillReturnRows(dogRows())
+				mock.ExpectQuery("select id from dog_photos").WithArgs(dogID).WillReturnRows(currentRows())
+				mock.ExpectRollback()
+			},
+			wantCode: ierr.InvalidArgument,
+			wantErr:  true,
+		},
+		{
+			name:     "success",
+			photoIDs: []uuid.UUID{photo2ID, photo1ID},
+			mocksInit: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("for update").WithArgs(dogID).WillReturnRows(dogRows())
+				mock.ExpectQuery("select id from dog_photos").WithArgs(dogID).WillReturnRows(currentRows())
+				mock.ExpectExec("update dog_photos set position").WithArgs(0, photo2ID).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("update dog_photos set position").WithArgs(1, photo1ID).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery("select \\* from dog_photos").
+					WithArgs(pq.StringArray{dogID.String()}).
+					WillReturnRows(sqlmock.NewRows(dogPhotoColumns).
+						AddRow(photo2ID, dogID, 0, dogImageURL, dogImageURL, dogImageURL, nil, false, createdAt).
+						AddRow(photo1ID, dogID, 1, dogImageURL, dogImageURL, dogImageURL, nil, true, createdAt))
+				mock.ExpectCommit()
+			},
+			want: domain.Dog{
+				ID:     dogID,
+				UserID: userID,
+				Name:   "Spike",
+				Sex:    "male",
+				Age:    5,
+				Breed:  "Bulldog",
+				Photos: []domain.DogPhoto{
+					{ID: photo2ID, Images: domain.ExternalDogImages(dogImageURL), CreatedAt: createdAt},
+					{ID: photo1ID, Images: domain.ExternalDogImages(dogImageURL), Primary: true, CreatedAt: createdAt},
+				},
+				CreatedAt: createdAt,
+				UpdatedAt: createdAt,
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInit()
+
+			got, err := NewDog(sqlx.NewDb(db, "postgres")).ReorderPhotos(context.TODO(), dogID, tt.photoIDs)
+			assert.Equal(t, tt.wantErr, err != nil)
+			if tt.wantErr {
+				assert.Equal(t, tt.wantCode, ierr.GetCode(err))
+			}
+
+			assert.Equal(t, tt.want, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestDog_SetPrimaryPhoto(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	dogID := uuid.New()
+	userID := uuid.New()
+	photoID := uuid.New()
+	createdAt := time.Now()
+	dogRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "user_id", "name", "sex", "age", "breed", "created_at", "updated_at"}).
+			AddRow(dogID, userID, "Spike", "male", 5, "Bulldog", createdAt, createdAt)
+	}
+
+	tests := []struct {
+		name      string
+		mocksInit func()
+		want      domain.Dog
+		wantCode  ierr.Code
+		wantErr   bool
+	}{
+		{
+			name: "photo not found",
+			mocksInit: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("for update").WithArgs(dogID).WillReturnRows(dogRows())
+				mock.ExpectExec("update dog_photos set is_primary=false").WithArgs(dogID).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("update dog_photos set is_primary=true").WithArgs(photoID, dogID).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectRollback()
+			},
+			wantCode: ierr.NotFound,
+			wantErr:  true,
+		},
+		{
+			name: "success",
+			mocksInit: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("for update").WithArgs(dogID).WillReturnRows(dogRows())
+				mock.ExpectExec("update dog_photos set is_primary=false").WithArgs(dogID).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("update dog_photos set is_primary=true").WithArgs(photoID, dogID).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery("select \\* from dog_photos").
+					WithArgs(pq.StringArray{dogID.String()}).
+					WillReturnRows(sqlmock.NewRows(dogPhotoColumns).
+						AddRow(photoID, dogID, 0, dogImageURL, dogImageURL, dogImageURL, nil, true, createdAt))
+				mock.ExpectCommit()
+			},
+			want: domain.Dog{
+				ID:        dogID,
+				UserID:    userID,
+				Name:      "Spike",
+				Sex:       "male",
+				Age:       5,
+				Breed:     "Bulldog",
+				Photos:    []domain.DogPhoto{{ID: photoID, Images: domain.ExternalDogImages(dogImageURL), Primary: true, CreatedAt: createdAt}},
+				CreatedAt: createdAt,
+				UpdatedAt: createdAt,
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInit()
+
+			got, err := NewDog(sqlx.NewDb(db, "postgres")).SetPrimaryPhoto(context.TODO(), dogID, photoID)
+			assert.Equal(t, tt.wantErr, err != nil)
+			if tt.wantErr {
+				assert.Equal(t, tt.wantCode, ierr.GetCode(err))
+			}
+
+			assert.Equal(t, tt.want, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestDog_SimilarPhoto(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	hash := domain.ImageHash(0xF0F0F0F0F0F0F0F0)
+	userID := uuid.New()
+	photo := domain.SimilarPhoto{PhotoID: uuid.New(), DogID: uuid.New(), UserID: uuid.New(), Distance: 2}
+
+	tests := []struct {
+		name      string
+		mocksInit func()
+		want      domain.SimilarPhoto
+		wantCode  ierr.Code
+		wantErr   bool
+	}{
+		{
+			name: "no similar photo",
+			mocksInit: func() {
+				mock.ExpectQuery("bit_count").WithArgs(int64(hash), userID, 8).WillReturnError(sql.ErrNoRows)
+			},
+			wantCode: ierr.NotFound,
+			wantErr:  true,
+		},
+		{
+			name: "similar photo",
+			mocksInit: func() {
+				mock.ExpectQuery("bit_count").
+					WithArgs(int64(hash), userID, 8).
+					WillReturnRows(sqlmock.NewRows([]string{"photo_id", "dog_id", "user_id", "distance"}).
+						AddRow(photo.PhotoID, photo.DogID, photo.UserID, photo.Distance))
+			},
+			want:    photo,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInit()
+
+			got, err := NewDog(sqlx.NewDb(db, "postgres")).SimilarPhoto(context.TODO(), hash, userID, 8)
+			assert.Equal(t, tt.wantErr, err != nil)
+			if tt.wantErr {
+				assert.Equal(t, tt.wantCode, ierr.GetCode(err))
+			}
+
+			assert.Equal(t, tt.want, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestDog_Preferences(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	d := NewDog(sqlx.NewDb(db, "postgres"))
+	dogID := uuid.New()
+	columns := []string{"dog_id", "sex", "min_age", "max_age", "breeds", "max_distance_km", "updated_at"}
+
+	t.Run("never set", func(t *testing.T) {
+		mock.ExpectQuery("select \\* from dog_preferences where dog_id=\\$1").
+			WithArgs(dogID).
+			WillReturnError(sql.ErrNoRows)
+
+		got, err := d.Preferences(context.TODO(), dogID)
+		assert.NoError(t, err)
+		assert.Equal(t, domain.DogPreferences{}, got)
+	})
+
+	t.Run("set", func(t *testing.T) {
+		mock.ExpectQuery("select \\* from dog_preferences where dog_id=\\$1").
+			WithArgs(dogID).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(dogID, "female", 1, nil, "{bulldog}", 25, time.Now()))
+
+		minAge, maxDistance := uint(1), uint(25)
+		got, err := d.Preferences(context.TODO(), dogID)
+		assert.NoError(t, err)
+		assert.Equal(t, domain.DogPreferences{
+			Sex:           domain.Female,
+			MinAge:        &minAge,
+			Breeds:        []string{"bulldog"},
+			MaxDistanceKm: &maxDistance,
+		}, got)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		maxAge := uint(6)
+		mock.ExpectQuery("insert into dog_preferences").
+			WithArgs(dogID, nil, nil, int64(6), pq.StringArray{"bulldog", "poodle"}, nil).
+			WillReturnRows(sqlmock.NewRows(columns).AddRow(dogID, nil, nil, 6, "{bulldog,poodle}", nil, time.Now()))
+
+		got, err := d.SetPreferences(context.TODO(), dogID, domain.DogPreferences{MaxAge: &maxAge, Breeds: []string{"Bulldog", "poodle"}})
+		assert.NoError(t, err)
+		assert.Equal(t, domain.DogPreferences{MaxAge: &maxAge, Breeds: []string{"bulldog", "poodle"}}, got)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDog_PreferencesOf(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	d := NewDog(sqlx.NewDb(db, "postgres"))
+	withPrefs, withoutPrefs := uuid.New(), uuid.New()
+	columns := []string{"dog_id", "sex", "min_age", "max_age", "breeds", "max_distance_km", "updated_at"}
+
+	mock.ExpectQuery("select \\* from dog_preferences where dog_id = any\\(\\$1::uuid\\[\\]\\)").
+		WithArgs(pq.StringArray{withPrefs.String(), withoutPrefs.String()}).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(withPrefs, "female", nil, nil, "{}", nil, time.Now()))
+
+	got, err := d.PreferencesOf(context.TODO(), []uuid.UUID{withPrefs, withoutPrefs})
+	assert.NoError(t, err)
+	assert.Equal(t, map[uuid.UUID]domain.DogPreferences{withPrefs: {Sex: domain.Female, Breeds: []string{}}}, got)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDog_ReactionStats(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	d := NewDog(sqlx.NewDb(db, "postgres"))
+	dogID, reactorID := uuid.New(), uuid.New()
+	reactorIDs := []uuid.UUID{reactorID}
+
+	t.Run("stats", func(t *testing.T) {
+		mock.ExpectQuery("select r.liker_id,.+from reactions r.+and r.liked_id != \\$1\\s+group by r.liker_id").
+			WithArgs(dogID, pq.StringArray{reactorID.String()}, domain.Like).
+			WillReturnRows(sqlmock.NewRows([]string{"liker_id", "likes", "reactions"}).AddRow(reactorID, 2, 5))
+
+		got, err := d.ReactionStats(context.TODO(), dogID, reactorIDs)
+		assert.NoError(t, err)
+		assert.Equal(t, map[uuid.UUID]domain.ReactionStats{reactorID: {Likes: 2, Reactions: 5}}, got)
+	})
+
+	t.Run("query error", func(t *testing.T) {
+		mock.ExpectQuery("select r.liker_id,.+from reactions r").
+			WillReturnError(errors.New("testing error"))
+
+		_, err := d.ReactionStats(context.TODO(), dogID, reactorIDs)
+		assert.Error(t, err)
+		assert.Equal(t, ierr.Internal, ierr.GetCode(err))
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
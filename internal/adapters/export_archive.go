@@ -0,0 +1,171 @@
+package adapters
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"time"
+
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+)
+
+// ExportArchive builds ZIP archive with JSON file per kind of exported data.
+// Secrets, like password hash and TOTP secret, are never exported.
+type ExportArchive struct{}
+
+func NewExportArchive() *ExportArchive {
+	return &ExportArchive{}
+}
+
+type exportedUser struct {
+	ID                  string     `json:"id"`
+	Email               string     `json:"email"`
+	Role                string     `json:"role"`
+	RegisteredAt        time.Time  `json:"registered_at"`
+	EmailVerifiedAt     *time.Time `json:"email_verified_at"`
+	TwoFactorEnabled    bool       `json:"two_factor_enabled"`
+	DisplayName         string     `json:"display_name"`
+	City                string     `json:"city"`
+	Bio                 string     `json:"bio"`
+	AvatarURL           string     `json:"avatar_url"`
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at"`
+}
+
+type exportedDog struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Sex       string    `json:"sex"`
+	Age       uint      `json:"age"`
+	Breed     string    `json:"breed"`
+	Image     string    `json:"image"`
+	Photos    []string  `json:"photos"`
+	City      string    `json:"city"`
+	Latitude  *float64  `json:"latitude"`
+	Longitude *float64  `json:"longitude"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type exportedReaction struct {
+	LikerDogID string    `json:"liker_dog_id"`
+	LikedDogID string    `json:"liked_dog_id"`
+	Action     string    `json:"action"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type exportedMatch struct {
+	DogID           string `json:"dog_id"`
+	MatchedDogID    string `json:"matched_dog_id"`
+	MatchedDogName  string `json:"matched_dog_name"`
+	MatchedDogBreed string `json:"matched_dog_breed"`
+}
+
+func (e ExportArchive) Build(data domain.AccountData) ([]byte, error) {
+	user := data.User
+	files := []struct {
+		name    string
+		content interface{}
+	}{
+		{
+			name: "user.json",
+			content: exportedUser{
+				ID:                  user.ID.String(),
+				Email:               user.Email,
+				Role:                user.Role.String(),
+				RegisteredAt:        user.RegisteredAt,
+				EmailVerifiedAt:     user.EmailVerifiedAt,
+				TwoFactorEnabled:    user.TOTPEnabledAt != nil,
+				DisplayName:         user.Profile.DisplayName,
+				City:                user.Profile.City,
+				Bio:                 user.Profile.Bio,
+				AvatarURL:           user.Profile.AvatarURL,
+				DeletionScheduledAt: user.DeletionScheduledAt,
+			},
+		},
+		{name: "dogs.json", content: e.dogs(data.Dogs)},
+		{name: "reactions.json", content: e.reactions(data.Reactions)},
+		{name: "matches.json", content: e.matches(data.Matches)},
+	}
+
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+
+	for _, file := range files {
+		f, err := w.Create(file.name)
+		if err != nil {
+			return nil, ierr.WrapCode(ierr.Internal, err, "creating archive file error")
+		}
+
+		encoder := json.NewEncoder(f)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(file.content); err != nil {
+			return nil, ierr.WrapCode(ierr.Internal, err, "encoding archive file error")
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, ierr.WrapCode(ierr.Internal, err, "closing archive error")
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (e ExportArchive) dogs(dogs domain.DogList) []exportedDog {
+	list := make([]exportedDog, 0, len(dogs))
+	for _, dog := range dogs {
+		photos := make([]string, 0, len(dog.Photos))
+		for _, photo := range dog.Photos {
+			photos = append(photos, photo.Images.Full)
+		}
+
+		exported := exportedDog{
+			ID:        dog.ID.String(),
+			Name:      dog.Name,
+			Sex:       dog.Sex.String(),
+			Age:       dog.Age,
+			Breed:     dog.Breed,
+			Image:     dog.PrimaryPhoto().Images.Full,
+			Photos:    photos,
+			City:      dog.City,
+			CreatedAt: dog.CreatedAt,
+			UpdatedAt: dog.UpdatedAt,
+		}
+
+		if dog.Location != nil {
+			exported.Latitude, exported.Longitude = &dog.Location.Latitude, &dog.Location.Longitude
+		}
+
+		list = append(list, exported)
+	}
+
+	return list
+}
+
+func (e ExportArchive) reactions(reactions []domain.Reaction) []exportedReaction {
+	list := make([]exportedReaction, 0, len(reactions))
+	for _, reaction := range reactions {
+		list = append(list, exportedReaction{
+			LikerDogID: reaction.Liker.String(),
+			LikedDogID: reaction.Liked.String(),
+			Action:     string(reaction.Action),
+			CreatedAt:  reaction.CreatedAt,
+		})
+	}
+
+	return list
+}
+
+func (e ExportArchive) matches(matches []domain.Match) []exportedMatch {
+	list := make([]exportedMatch, 0, len(matches))
+	for _, match := range matches {
+		list = append(list, exportedMatch{
+			DogID:           match.DogID.String(),
+			MatchedDogID:    match.MatchedDog.ID.String(),
+			MatchedDogName:  match.MatchedDog.Name,
+			MatchedDogBreed: match.MatchedDog.Breed,
+		})
+	}
+
+	return list
+}
@@ -0,0 +1,83 @@
+package adapters
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportArchive_Build(t *testing.T) {
+	dog := domain.Dog{
+		ID:    uuid.New(),
+		Name:  "Spike",
+		Sex:   "male",
+		Age:   3,
+		Breed: "Bulldog",
+		Photos: []domain.DogPhoto{
+			{Images: domain.ExternalDogImages("http://dog-images.com/1.jpg")},
+			{Images: domain.ExternalDogImages("http://dog-images.com/2.jpg"), Primary: true},
+		},
+	}
+	matched := domain.Dog{ID: uuid.New(), Name: "Tyke", Breed: "Bulldog"}
+
+	archive, err := NewExportArchive().Build(domain.AccountData{
+		User: domain.User{
+			ID:           uuid.New(),
+			Email:        "test@email.com",
+			PasswordHash: "secret-hash",
+			TOTPSecret:   "secret-totp",
+			Role:         domain.RoleUser,
+		},
+		Dogs:      domain.DogList{dog},
+		Reactions: []domain.Reaction{{Liker: dog.ID, Liked: matched.ID, Action: domain.Like}},
+		Matches:   []domain.Match{{DogID: dog.ID, MatchedDog: matched}},
+	})
+	assert.NoError(t, err)
+
+	r, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	assert.NoError(t, err)
+
+	files := make(map[string][]byte)
+	for _, f := range r.File {
+		rc, err := f.Open()
+		assert.NoError(t, err)
+
+		content, err := io.ReadAll(rc)
+		assert.NoError(t, err)
+		rc.Close()
+
+		files[f.Name] = content
+	}
+
+	assert.Len(t, files, 4)
+	assert.NotContains(t, string(files["user.json"]), "secret-hash")
+	assert.NotContains(t, string(files["user.json"]), "secret-totp")
+
+	var dogs []exportedDog
+	assert.NoError(t, json.Unmarshal(files["dogs.json"], &dogs))
+	assert.Equal(t, []exportedDog{{
+		ID:     dog.ID.String(),
+		Name:   "Spike",
+		Sex:    "male",
+		Age:    3,
+		Breed:  "Bulldog",
+		Image:  "http://dog-images.com/2.jpg",
+		Photos: []string{"http://dog-images.com/1.jpg", "http://dog-images.com/2.jpg"},
+	}}, dogs)
+
+	var matches []exportedMatch
+	assert.NoError(t, json.Unmarshal(files["matches.json"], &matches))
+	assert.Equal(t, []exportedMatch{{
+		DogID:           dog.ID.String(),
+		MatchedDogID:    matched.ID.String(),
+		MatchedDogName:  "Tyke",
+		MatchedDogBreed: "Bulldog",
+	}}, matches)
+}
@@ -0,0 +1,203 @@
+package adapters
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/valerii-smirnov/petli-test-task/internal/adapters/models"
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// FlaggedPhoto moderation queue of uploaded photos looking like photos of other users' dogs.
+type FlaggedPhoto struct {
+	db   *sqlx.DB
+	dogs Dog
+}
+
+func NewFlaggedPhoto(db *sqlx.DB) *FlaggedPhoto {
+	return &FlaggedPhoto{
+		db:   db,
+		dogs: Dog{db: db},
+	}
+}
+
+func (f FlaggedPhoto) Create(ctx context.Context, photo domain.FlaggedPhoto) (domain.FlaggedPhoto, error) {
+	query := `insert into flagged_photos
+				(dog_id, image, image_thumb, image_card, phash, matched_photo_id, matched_dog_id, distance) values
+				($1, $2, $3, $4, $5, $6, $7, $8) returning *`
+
+	var mPhoto models.FlaggedPhoto
+	err := f.db.GetContext(
+		ctx,
+		&mPhoto,
+		query,
+		photo.DogID,
+		photo.Images.Full,
+		photo.Images.Thumb,
+		photo.Images.Card,
+		int64(photo.Hash),
+		uuidToNull(photo.MatchedPhotoID),
+		uuidToNull(photo.MatchedDogID),
+		photo.Distance,
+	)
+	if err != nil {
+		return domain.FlaggedPhoto{}, ierr.WrapCode(ierr.Internal, err, "execution insert query error")
+	}
+
+	return f.flaggedPhotoToDomain(mPhoto), nil
+}
+
+// List returns flagged photos matching the filter, the oldest first, so the queue is worked through in order.
+func (f FlaggedPhoto) List(ctx context.Context, filter domain.FlaggedPhotoFilter) ([]domain.FlaggedPhoto, error) {
+	query := `select * from flagged_photos where status=$1 order by created_at limit $2 offset $3`
+
+	var mPhotos []models.FlaggedPhoto
+	err := f.db.SelectContext(
+		ctx,
+		&mPhotos,
+		query,
+		filter.Status.String(),
+		filter.Pagination.PerPage,
+		filter.Pagination.PerPage*(filter.Pagination.Page-1),
+	)
+	if err != nil {
+		return nil, ierr.WrapCode(ierr.Internal, err, "execution select query error")
+	}
+
+	photos := make([]domain.FlaggedPhoto, 0, len(mPhotos))
+	for _, mPhoto := range mPhotos {
+		photos = append(photos, f.flaggedPhotoToDomain(mPhoto))
+	}
+
+	return photos, nil
+}
+
+// Approve adds the flagged photo to its dog and marks it approved in a single transaction.
+func (f FlaggedPhoto) Approve(ctx context.Context, photoID, reviewerID uuid.UUID, maxPhotos int) (domain.FlaggedPhoto, error) {
+	tx, err := f.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return domain.FlaggedPhoto{}, ierr.WrapCode(ierr.Internal, err, "beginning transaction error")
+	}
+	defer tx.Rollback()
+
+	mPhoto, err := f.lockPending(ctx, tx, photoID)
+	if err != nil {
+		return domain.FlaggedPhoto{}, err
+	}
+
+	hash := domain.ImageHash(mPhoto.PHash)
+	photo := domain.DogPhoto{
+		Images: domain.DogImages{
+			Thumb: mPhoto.ImageThumb,
+			Card:  mPhoto.ImageCard,
+			Full:  mPhoto.Image,
+		},
+		Hash: &hash,
+	}
+
+	if _, err := f.dogs.addPhoto(ctx, tx, mPhoto.DogID, photo, maxPhotos); err != nil {
+		return domain.FlaggedPhoto{}, err
+	}
+
+	return f.review(ctx, tx, photoID, reviewerID, domain.ReviewApproved)
+}
+
+// Reject marks the flagged photo rejected, the photo is never added to its dog.
+func (f FlaggedPhoto) Reject(ctx context.Context, photoID, reviewerID uuid.UUID) (domain.FlaggedPhoto, error) {
+	tx, err := f.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return domain.FlaggedPhoto{}, ierr.WrapCode(ierr.Internal, err, "beginning transaction error")
+	}
+	defer tx.Rollback()
+
+	if _, err := f.lockPending(ctx, tx, photoID); err != nil {
+		return domain.FlaggedPhoto{}, err
+	}
+
+	return f.review(ctx, tx, photoID, reviewerID, domain.ReviewRejected)
+}
+
+// lockPending locks the flagged photo until the transaction ends, so it can't be reviewed twice.
+func (f FlaggedPhoto) lockPending(ctx context.Context, tx *sqlx.Tx, photoID uuid.UUID) (models.FlaggedPhoto, error) {
+	var mPhoto models.FlaggedPhoto
+	if err := tx.GetContext(ctx, &mPhoto, "select * from flagged_photos where id=$1 for update", photoID); err != nil {
+		if err == sql.ErrNoRows {
+			return models.FlaggedPhoto{}, ierr.WrapCode(ierr.NotFound, err, "flagged photo not found")
+		}
+
+		return models.FlaggedPhoto{}, ierr.WrapCode(ierr.Internal, err, "getting flagged photo error")
+	}
+
+	if mPhoto.Status != domain.ReviewPending.String() {
+		return models.FlaggedPhoto{}, ierr.New(ierr.AlreadyExists, "photo is already reviewed")
+	}
+
+	return mPhoto, nil
+}
+
+// review records the review of the flagged photo and commits the transaction.
+func (f FlaggedPhoto) review(
+	ctx context.Context,
+	tx *sqlx.Tx,
+	photoID, reviewerID uuid.UUID,
+	status domain.ReviewStatus,
+) (domain.FlaggedPhoto, error) {
+	query := "update flagged_photos set status=$1, reviewed_by=$2, reviewed_at=now() where id=$3 returning *"
+
+	var mPhoto models.FlaggedPhoto
+	if err := tx.GetContext(ctx, &mPhoto, query, status.String(), reviewerID, photoID); err != nil {
+		return domain.FlaggedPhoto{}, ierr.WrapCode(ierr.Internal, err, "reviewing flagged photo error")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return domain.FlaggedPhoto{}, ierr.WrapCode(ierr.Internal, err, "committing transaction error")
+	}
+
+	return f.flaggedPhotoToDomain(mPhoto), nil
+}
+
+func (f FlaggedPhoto) flaggedPhotoToDomain(photo models.FlaggedPhoto) domain.FlaggedPhoto {
+	dPhoto := domain.FlaggedPhoto{
+		ID:    photo.ID,
+		DogID: photo.DogID,
+		Images: domain.DogImages{
+			Thumb: photo.ImageThumb,
+			Card:  photo.ImageCard,
+			Full:  photo.Image,
+		},
+		Hash:      domain.ImageHash(photo.PHash),
+		Distance:  photo.Distance,
+		Status:    domain.ReviewStatus(photo.Status),
+		CreatedAt: photo.CreatedAt,
+	}
+
+	if photo.MatchedPhotoID.Valid {
+		dPhoto.MatchedPhotoID = &photo.MatchedPhotoID.UUID
+	}
+
+	if photo.MatchedDogID.Valid {
+		dPhoto.MatchedDogID = &photo.MatchedDogID.UUID
+	}
+
+	if photo.ReviewedBy.Valid {
+		dPhoto.ReviewedBy = &photo.ReviewedBy.UUID
+	}
+
+	if photo.ReviewedAt.Valid {
+		dPhoto.ReviewedAt = &photo.ReviewedAt.Time
+	}
+
+	return dPhoto
+}
+
+func uuidToNull(id *uuid.UUID) uuid.NullUUID {
+	if id == nil {
+		return uuid.NullUUID{}
+	}
+
+	return uuid.NullUUID{UUID: *id, Valid: true}
+}
@@ -0,0 +1,174 @@
+package adapters
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+var flaggedPhotoColumns = []string{
+	"id", "dog_id", "image", "image_thumb", "image_card", "phash", "matched_photo_id", "matched_dog_id",
+	"distance", "status", "reviewed_by", "reviewed_at", "created_at",
+}
+
+func TestFlaggedPhoto_Create(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	hash := domain.ImageHash(0xF0F0F0F0F0F0F0F0)
+	photoID := uuid.New()
+	dogID := uuid.New()
+	matchedPhotoID := uuid.New()
+	matchedDogID := uuid.New()
+	createdAt := time.Now()
+	images := domain.ExternalDogImages(dogImageURL)
+
+	mock.ExpectQuery("insert into flagged_photos").
+		WithArgs(dogID, images.Full, images.Thumb, images.Card, int64(hash), matchedPhotoID, matchedDogID, 3).
+		WillReturnRows(sqlmock.NewRows(flaggedPhotoColumns).
+			AddRow(photoID, dogID, images.Full, images.Thumb, images.Card, int64(hash), matchedPhotoID, matchedDogID,
+				3, "pending", nil, nil, createdAt))
+
+	got, err := NewFlaggedPhoto(sqlx.NewDb(db, "postgres")).Create(context.TODO(), domain.FlaggedPhoto{
+		DogID:          dogID,
+		Images:         images,
+		Hash:           hash,
+		MatchedPhotoID: &matchedPhotoID,
+		MatchedDogID:   &matchedDogID,
+		Distance:       3,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, domain.FlaggedPhoto{
+		ID:             photoID,
+		DogID:          dogID,
+		Images:         images,
+		Hash:           hash,
+		MatchedPhotoID: &matchedPhotoID,
+		MatchedDogID:   &matchedDogID,
+		Distance:       3,
+		Status:         domain.ReviewPending,
+		CreatedAt:      createdAt,
+	}, got)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFlaggedPhoto_Approve(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	hash := domain.ImageHash(0xF0F0F0F0F0F0F0F0)
+	photoID := uuid.New()
+	dogID := uuid.New()
+	userID := uuid.New()
+	reviewerID := uuid.New()
+	createdAt := time.Now()
+	images := domain.ExternalDogImages(dogImageURL)
+
+	flaggedRows := func(status string, reviewedBy interface{}, reviewedAt interface{}) *sqlmock.Rows {
+		return sqlmock.NewRows(flaggedPhotoColumns).
+			AddRow(photoID, dogID, images.Full, images.Thumb, images.Card, int64(hash), nil, nil,
+				3, status, reviewedBy, reviewedAt, createdAt)
+	}
+	dogRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "user_id", "name", "sex", "age", "breed", "created_at", "updated_at"}).
+			AddRow(dogID, userID, "Spike", "male", 5, "Bulldog", createdAt, createdAt)
+	}
+
+	tests := []struct {
+		name      string
+		mocksInit func()
+		want      domain.FlaggedPhoto
+		wantCode  ierr.Code
+		wantErr   bool
+	}{
+		{
+			name: "flagged photo not found",
+			mocksInit: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("select \\* from flagged_photos where id=\\$1 for update").WithArgs(photoID).WillReturnError(sql.ErrNoRows)
+				mock.ExpectRollback()
+			},
+			wantCode: ierr.NotFound,
+			wantErr:  true,
+		},
+		{
+			name: "photo is already reviewed",
+			mocksInit: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("from flagged_photos").WithArgs(photoID).WillReturnRows(flaggedRows("rejected", reviewerID, createdAt))
+				mock.ExpectRollback()
+			},
+			wantCode: ierr.AlreadyExists,
+			wantErr:  true,
+		},
+		{
+			name: "dog has no room for the photo",
+			mocksInit: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("from flagged_photos").WithArgs(photoID).WillReturnRows(flaggedRows("pending", nil, nil))
+				mock.ExpectQuery("from dogs where id=\\$1 for update").WithArgs(dogID).WillReturnRows(dogRows())
+				mock.ExpectQuery("select count").WithArgs(dogID).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+				mock.ExpectRollback()
+			},
+			wantCode: ierr.InvalidArgument,
+			wantErr:  true,
+		},
+		{
+			name: "success",
+			mocksInit: func() {
+				mock.ExpectBegin()
+				mock.ExpectQuery("from flagged_photos").WithArgs(photoID).WillReturnRows(flaggedRows("pending", nil, nil))
+				mock.ExpectQuery("from dogs where id=\\$1 for update").WithArgs(dogID).WillReturnRows(dogRows())
+				mock.ExpectQuery("select count").WithArgs(dogID).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectExec("insert into dog_photos").
+					WithArgs(dogID, 1, images.Full, images.Thumb, images.Card, int64(hash), false).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery("update flagged_photos set status").
+					WithArgs("approved", reviewerID, photoID).
+					WillReturnRows(flaggedRows("approved", reviewerID, createdAt))
+				mock.ExpectCommit()
+			},
+			want: domain.FlaggedPhoto{
+				ID:         photoID,
+				DogID:      dogID,
+				Images:     images,
+				Hash:       hash,
+				Distance:   3,
+				Status:     domain.ReviewApproved,
+				ReviewedBy: &reviewerID,
+				ReviewedAt: &createdAt,
+				CreatedAt:  createdAt,
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInit()
+
+			got, err := NewFlaggedPhoto(sqlx.NewDb(db, "postgres")).Approve(context.TODO(), photoID, reviewerID, 3)
+			assert.Equal(t, tt.wantErr, err != nil)
+			if tt.wantErr {
+				assert.Equal(t, tt.wantCode, ierr.GetCode(err))
+			}
+
+			assert.Equal(t, tt.want, got)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package adapters
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+)
+
+// citiesCSV bundled dataset of cities: name, ISO 3166 country code, latitude, longitude.
+//
+//go:embed data/cities.csv
+var citiesCSV []byte
+
+// CityGeocoder looks cities up in the bundled dataset, no external service is called.
+// City is given by its name, e.g. "Lviv", or by name and country code, e.g. "Lviv, UA", case-insensitively.
+type CityGeocoder struct {
+	cities map[string][]domain.City
+}
+
+func NewCityGeocoder() (*CityGeocoder, error) {
+	records, err := csv.NewReader(bytes.NewReader(citiesCSV)).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("reading cities dataset error: %w", err)
+	}
+
+	g := &CityGeocoder{cities: make(map[string][]domain.City, len(records))}
+	// the first record is the header.
+	for n, record := range records[1:] {
+		if len(record) != 4 {
+			return nil, fmt.Errorf("cities dataset line %d: expected 4 fields, got %d", n+2, len(record))
+		}
+
+		lat, latErr := strconv.ParseFloat(record[2], 64)
+		lng, lngErr := strconv.ParseFloat(record[3], 64)
+		city := domain.City{Name: record[0], Country: record[1], Point: domain.GeoPoint{Latitude: lat, Longitude: lng}}
+		if latErr != nil || lngErr != nil || !city.Point.Valid() {
+			return nil, fmt.Errorf("cities dataset line %d: wrong coordinates", n+2)
+		}
+
+		key := normalizeCityName(city.Name)
+		g.cities[key] = append(g.cities[key], city)
+	}
+
+	return g, nil
+}
+
+// Geocode returns the city by its name, NotFound if the city isn't in the dataset.
+func (g CityGeocoder) Geocode(name string) (domain.City, error) {
+	name, country, _ := strings.Cut(name, ",")
+	country = strings.TrimSpace(country)
+
+	for _, city := range g.cities[normalizeCityName(name)] {
+		if country == "" || strings.EqualFold(city.Country, country) {
+			return city, nil
+		}
+	}
+
+	return domain.City{}, ierr.New(ierr.NotFound, "city not found")
+}
+
+func normalizeCityName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
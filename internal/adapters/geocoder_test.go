@@ -0,0 +1,49 @@
+package adapters
+
+import (
+	"testing"
+
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCityGeocoder_Geocode(t *testing.T) {
+	g, err := NewCityGeocoder()
+	assert.NoError(t, err)
+
+	lviv := domain.City{Name: "Lviv", Country: "UA", Point: domain.GeoPoint{Latitude: 49.8397, Longitude: 24.0297}}
+
+	tests := []struct {
+		name     string
+		city     string
+		want     domain.City
+		wantCode ierr.Code
+		wantErr  bool
+	}{
+		{name: "name", city: "Lviv", want: lviv},
+		{name: "case and spaces", city: "  lviv ", want: lviv},
+		{name: "name and country", city: "Lviv, ua", want: lviv},
+		{name: "multi-word name", city: "new  york", want: domain.City{
+			Name:    "New York",
+			Country: "US",
+			Point:   domain.GeoPoint{Latitude: 40.7128, Longitude: -74.0060},
+		}},
+		{name: "wrong country", city: "Lviv, PL", wantErr: true, wantCode: ierr.NotFound},
+		{name: "unknown city", city: "Atlantis", wantErr: true, wantCode: ierr.NotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := g.Geocode(tt.city)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Equal(t, tt.wantCode, ierr.GetCode(err))
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,267 @@
+package adapters
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif" // registers GIF decoder
+	"image/jpeg"
+	_ "image/png" // registers PNG decoder
+	"io"
+
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // registers WebP decoder
+)
+
+const (
+	renditionContentType = "image/jpeg"
+	renditionExtension   = ".jpg"
+	renditionQuality     = 85
+	// maxImagePixels protects from small files decoding to huge images.
+	maxImagePixels = 40_000_000
+)
+
+// renditions every uploaded image is processed into, images are scaled down to fit the size, never up.
+var renditions = []struct {
+	rendition domain.ImageRendition
+	size      int
+}{
+	{rendition: domain.RenditionThumb, size: 200},
+	{rendition: domain.RenditionCard, size: 640},
+	{rendition: domain.RenditionFull, size: 1600},
+}
+
+// ImageProcessor decodes uploaded JPEG, PNG, GIF or WebP image and re-encodes it into JPEG renditions.
+// Re-encoding drops EXIF and any other metadata, e.g. GPS location, EXIF orientation is applied to the pixels
+// beforehand, so the image still looks the same. Transparent areas are filled with white.
+// Difference hash (dHash) of the oriented image is computed, so re-encoded or resized copies can be found.
+type ImageProcessor struct{}
+
+func NewImageProcessor() *ImageProcessor {
+	return &ImageProcessor{}
+}
+
+func (p ImageProcessor) Process(content io.Reader) (domain.ProcessedUpload, error) {
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return domain.ProcessedUpload{}, ierr.WrapCode(ierr.Internal, err, "reading image error")
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return domain.ProcessedUpload{}, ierr.WrapCode(ierr.InvalidArgument, err, "image can't be decoded")
+	}
+
+	if config.Width*config.Height > maxImagePixels {
+		return domain.ProcessedUpload{}, ierr.New(
+			ierr.InvalidArgument,
+			fmt.Sprintf("image must not have more than %d pixels", maxImagePixels),
+		)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return domain.ProcessedUpload{}, ierr.WrapCode(ierr.InvalidArgument, err, "image can't be decoded")
+	}
+
+	oriented := orient(flatten(img), jpegOrientation(data))
+
+	processed := domain.ProcessedUpload{Renditions: make([]domain.ProcessedImage, 0, len(renditions))}
+	for _, r := range renditions {
+		scaled := fit(oriented, r.size)
+		// the smallest rendition is hashed, it is the cheapest to shrink further.
+		if r.rendition == domain.RenditionThumb {
+			processed.Hash = dHash(scaled)
+		}
+
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, scaled, &jpeg.Options{Quality: renditionQuality}); err != nil {
+			return domain.ProcessedUpload{}, ierr.WrapCode(ierr.Internal, err, "encoding image error")
+		}
+
+		processed.Renditions = append(processed.Renditions, domain.ProcessedImage{
+			Rendition:   r.rendition,
+			ContentType: renditionContentType,
+			Extension:   renditionExtension,
+			Content:     buf.Bytes(),
+		})
+	}
+
+	return processed, nil
+}
+
+// flatten draws the image over white background, JPEG has no alpha channel.
+func flatten(img image.Image) *image.RGBA {
+	bounds := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), img, bounds.Min, draw.Over)
+
+	return dst
+}
+
+// orient rotates and flips the image, so it looks as EXIF orientation tells it should.
+func orient(img *image.RGBA, orientation int) *image.RGBA {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // mirrored horizontally
+				sx, sy = w-1-x, y
+			case 3: // rotated 180°
+				sx, sy = w-1-x, h-1-y
+			case 4: // mirrored vertically
+				sx, sy = x, h-1-y
+			case 5: // transposed
+				sx, sy = y, x
+			case 6: // rotated 90° counterclockwise, has to be turned clockwise
+				sx, sy = y, h-1-x
+			case 7: // transversed
+				sx, sy = w-1-y, h-1-x
+			case 8: // rotated 90° clockwise, has to be turned counterclockwise
+				sx, sy = w-1-y, x
+			}
+
+			dst.SetRGBA(x, y, img.RGBAAt(sx, sy))
+		}
+	}
+
+	return dst
+}
+
+// fit scales the image down to fit size x size square keeping aspect ratio.
+func fit(img *image.RGBA, size int) image.Image {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	if w <= size && h <= size {
+		return img
+	}
+
+	dw, dh := size, h*size/w
+	if h > w {
+		dw, dh = w*size/h, size
+	}
+
+	// very narrow images must not collapse to nothing.
+	if dw < 1 {
+		dw = 1
+	}
+
+	if dh < 1 {
+		dh = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, img.Bounds(), draw.Src, nil)
+
+	return dst
+}
+
+// dHash difference hash of the image. The image is shrunk to 9x8 grey pixels, every bit of the hash tells
+// if a pixel is brighter than the one on the right of it, so the hash survives re-encoding, resizing and
+// colour adjustments of the image.
+func dHash(img image.Image) domain.ImageHash {
+	const w, h = 9, 8
+
+	small := image.NewGray(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(small, small.Bounds(), img, img.Bounds(), draw.Src, nil)
+
+	var hash domain.ImageHash
+	for y := 0; y < h; y++ {
+		for x := 0; x < w-1; x++ {
+			hash <<= 1
+			if small.GrayAt(x, y).Y > small.GrayAt(x+1, y).Y {
+				hash |= 1
+			}
+		}
+	}
+
+	return hash
+}
+
+// jpegOrientation returns EXIF orientation of JPEG image, 1 (as is) if the image isn't JPEG or has no orientation.
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return 1
+		}
+
+		marker := data[i+1]
+		// EXIF is always before the image data.
+		if marker == 0xDA || marker == 0xD9 {
+			return 1
+		}
+
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		if length < 2 || i+2+length > len(data) {
+			return 1
+		}
+
+		segment := data[i+4 : i+2+length]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return tiffOrientation(segment[6:])
+		}
+
+		i += 2 + length
+	}
+
+	return 1
+}
+
+// tiffOrientation reads orientation tag of the first IFD of EXIF TIFF structure.
+func tiffOrientation(tiff []byte) int {
+	const orientationTag = 0x0112
+
+	if len(tiff) < 8 {
+		return 1
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 1
+	}
+
+	entries := int(order.Uint16(tiff[ifd:]))
+	for n := 0; n < entries; n++ {
+		entry := ifd + 2 + n*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+
+		if order.Uint16(tiff[entry:]) == orientationTag {
+			return int(order.Uint16(tiff[entry+8:]))
+		}
+	}
+
+	return 1
+}
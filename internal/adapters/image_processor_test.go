@@ -0,0 +1,217 @@
+package adapters
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testImage returns w x h image of the background colour with red top left corner.
+func testImage(w, h int, background color.Color) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, background)
+		}
+	}
+
+	for y := 0; y < h/4; y++ {
+		for x := 0; x < w/4; x++ {
+			img.Set(x, y, color.NRGBA{R: 255, A: 255})
+		}
+	}
+
+	return img
+}
+
+// withEXIF inserts APP1 segment with the orientation and GPS latitude reference tags right after SOI marker.
+func withEXIF(t *testing.T, jpegData []byte, orientation uint16) []byte {
+	t.Helper()
+
+	tiff := new(bytes.Buffer)
+	tiff.WriteString("MM")
+	_ = binary.Write(tiff, binary.BigEndian, uint16(42))
+	_ = binary.Write(tiff, binary.BigEndian, uint32(8))
+	_ = binary.Write(tiff, binary.BigEndian, uint16(2))
+	// orientation, SHORT, 1 value.
+	_ = binary.Write(tiff, binary.BigEndian, []uint16{0x0112, 3, 0, 1, orientation, 0})
+	// GPS latitude ref, ASCII, 2 values, stands for any sensitive metadata.
+	_ = binary.Write(tiff, binary.BigEndian, []uint16{0x0001, 2, 0, 2})
+	tiff.WriteString("N\x00\x00\x00")
+	_ = binary.Write(tiff, binary.BigEndian, uint32(0))
+
+	segment := append([]byte("Exif\x00\x00"), tiff.Bytes()...)
+
+	out := []byte{0xFF, 0xD8, 0xFF, 0xE1}
+	out = binary.BigEndian.AppendUint16(out, uint16(len(segment)+2))
+	out = append(out, segment...)
+
+	require.Equal(t, []byte{0xFF, 0xD8}, jpegData[:2])
+
+	return append(out, jpegData[2:]...)
+}
+
+func TestImageProcessor_Process(t *testing.T) {
+	var pngData bytes.Buffer
+	require.NoError(t, png.Encode(&pngData, testImage(2000, 1000, color.NRGBA{})))
+
+	var jpegData bytes.Buffer
+	require.NoError(t, jpeg.Encode(&jpegData, testImage(400, 100, color.NRGBA{B: 255, A: 255}), nil))
+
+	type size struct{ w, h int }
+
+	tests := []struct {
+		name      string
+		content   []byte
+		wantSizes map[domain.ImageRendition]size
+		// wantTopLeft expected colour of the top left corner of the full rendition.
+		wantTopLeft color.RGBA
+		wantCode    ierr.Code
+		wantErr     bool
+	}{
+		{
+			name:     "not an image",
+			content:  []byte("definitely not an image"),
+			wantCode: ierr.InvalidArgument,
+			wantErr:  true,
+		},
+		{
+			name:     "truncated image",
+			content:  pngData.Bytes()[:pngData.Len()/2],
+			wantCode: ierr.InvalidArgument,
+			wantErr:  true,
+		},
+		{
+			name:    "transparent png is scaled down",
+			content: pngData.Bytes(),
+			wantSizes: map[domain.ImageRendition]size{
+				domain.RenditionThumb: {200, 100},
+				domain.RenditionCard:  {640, 320},
+				domain.RenditionFull:  {1600, 800},
+			},
+			wantTopLeft: color.RGBA{R: 255, A: 255},
+		},
+		{
+			name:    "jpeg is rotated by exif orientation and never scaled up",
+			content: withEXIF(t, jpegData.Bytes(), 6),
+			wantSizes: map[domain.ImageRendition]size{
+				domain.RenditionThumb: {50, 200},
+				domain.RenditionCard:  {100, 400},
+				domain.RenditionFull:  {100, 400},
+			},
+			// red corner moved to the top right, bottom left one came to the top left.
+			wantTopLeft: color.RGBA{B: 255, A: 255},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewImageProcessor().Process(bytes.NewReader(tt.content))
+			assert.Equal(t, tt.wantErr, err != nil)
+			if tt.wantErr {
+				assert.Equal(t, tt.wantCode, ierr.GetCode(err))
+				return
+			}
+
+			require.Len(t, got.Renditions, len(tt.wantSizes))
+			for _, processed := range got.Renditions {
+				assert.Equal(t, "image/jpeg", processed.ContentType)
+				assert.Equal(t, ".jpg", processed.Extension)
+				assert.NotContains(t, string(processed.Content), "Exif")
+
+				img, err := jpeg.Decode(bytes.NewReader(processed.Content))
+				require.NoError(t, err)
+				assert.Equal(t, tt.wantSizes[processed.Rendition], size{img.Bounds().Dx(), img.Bounds().Dy()}, processed.Rendition)
+
+				if processed.Rendition == domain.RenditionFull {
+					r, g, b, _ := img.At(0, 0).RGBA()
+					want := tt.wantTopLeft
+					assert.InDelta(t, want.R, r>>8, 16)
+					assert.InDelta(t, want.G, g>>8, 16)
+					assert.InDelta(t, want.B, b>>8, 16)
+				}
+			}
+		})
+	}
+}
+
+// gradientImage returns w x h image getting brighter from left to right, or from right to left if reversed.
+func gradientImage(w, h int, reversed bool) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			v := uint8(x * 255 / (w - 1))
+			if reversed {
+				v = 255 - v
+			}
+
+			img.Set(x, y, color.NRGBA{R: v, G: v, B: v, A: 255})
+		}
+	}
+
+	return img
+}
+
+func TestImageProcessor_Process_hash(t *testing.T) {
+	encodePNG := func(img image.Image) []byte {
+		var buf bytes.Buffer
+		require.NoError(t, png.Encode(&buf, img))
+		return buf.Bytes()
+	}
+
+	encodeJPEG := func(img image.Image) []byte {
+		var buf bytes.Buffer
+		require.NoError(t, jpeg.Encode(&buf, img, &jpeg.Options{Quality: 30}))
+		return buf.Bytes()
+	}
+
+	hash := func(content []byte) domain.ImageHash {
+		got, err := NewImageProcessor().Process(bytes.NewReader(content))
+		require.NoError(t, err)
+		return got.Hash
+	}
+
+	original := hash(encodePNG(gradientImage(800, 600, false)))
+
+	tests := []struct {
+		name         string
+		content      []byte
+		wantDistance func(t *testing.T, distance int)
+	}{
+		{
+			name:    "the same image has the same hash",
+			content: encodePNG(gradientImage(800, 600, false)),
+			wantDistance: func(t *testing.T, distance int) {
+				assert.Equal(t, 0, distance)
+			},
+		},
+		{
+			name:    "resized and re-encoded copy has close hash",
+			content: encodeJPEG(gradientImage(300, 220, false)),
+			wantDistance: func(t *testing.T, distance int) {
+				assert.LessOrEqual(t, distance, 4)
+			},
+		},
+		{
+			name:    "different image has distant hash",
+			content: encodePNG(gradientImage(800, 600, true)),
+			wantDistance: func(t *testing.T, distance int) {
+				assert.GreaterOrEqual(t, distance, 32)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.wantDistance(t, original.Distance(hash(tt.content)))
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type APIKey struct {
+	ID         uuid.UUID      `db:"id"`
+	UserID     uuid.UUID      `db:"user_id"`
+	Name       string         `db:"name"`
+	Prefix     string         `db:"prefix"`
+	KeyHash    string         `db:"key_hash"`
+	Scopes     pq.StringArray `db:"scopes"`
+	LastUsedAt sql.NullTime   `db:"last_used_at"`
+	RevokedAt  sql.NullTime   `db:"revoked_at"`
+	CreatedAt  time.Time      `db:"created_at"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AuditEvent struct {
+	ID        uuid.UUID     `db:"id"`
+	UserID    uuid.NullUUID `db:"user_id"`
+	Email     string        `db:"email"`
+	EventType string        `db:"event_type"`
+	Outcome   string        `db:"outcome"`
+	Reason    string        `db:"reason"`
+	ClientIP  string        `db:"client_ip"`
+	UserAgent string        `db:"user_agent"`
+	CreatedAt time.Time     `db:"created_at"`
+}
@@ -1,14 +1,34 @@
 package models
 
 import (
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 type User struct {
-	ID           uuid.UUID `db:"id"`
-	Email        string    `db:"email"`
-	PasswordHash string    `db:"password_hash"`
-	RegisteredAt time.Time `db:"registered_at"`
+	ID                  uuid.UUID      `db:"id"`
+	Email               string         `db:"email"`
+	PasswordHash        string         `db:"password_hash"`
+	Role                string         `db:"role"`
+	RegisteredAt        time.Time      `db:"registered_at"`
+	TokensValidAfter    sql.NullTime   `db:"tokens_valid_after"`
+	EmailVerifiedAt     sql.NullTime   `db:"email_verified_at"`
+	TOTPSecret          sql.NullString `db:"totp_secret"`
+	TOTPEnabledAt       sql.NullTime   `db:"totp_enabled_at"`
+	TOTPLastStep        int64          `db:"totp_last_step"`
+	DisplayName         string         `db:"display_name"`
+	City                string         `db:"city"`
+	Bio                 string         `db:"bio"`
+	AvatarURL           string         `db:"avatar_url"`
+	DeletionScheduledAt sql.NullTime   `db:"deletion_scheduled_at"`
+}
+
+type UserIdentity struct {
+	ID        uuid.UUID `db:"id"`
+	UserID    uuid.UUID `db:"user_id"`
+	Issuer    string    `db:"issuer"`
+	Subject   string    `db:"subject"`
+	CreatedAt time.Time `db:"created_at"`
 }
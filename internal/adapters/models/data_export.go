@@ -0,0 +1,19 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type DataExport struct {
+	ID          uuid.UUID    `db:"id"`
+	UserID      uuid.UUID    `db:"user_id"`
+	Status      string       `db:"status"`
+	Archive     []byte       `db:"archive"`
+	CreatedAt   time.Time    `db:"created_at"`
+	StartedAt   sql.NullTime `db:"started_at"`
+	CompletedAt sql.NullTime `db:"completed_at"`
+	ExpiresAt   sql.NullTime `db:"expires_at"`
+}
@@ -1,19 +1,73 @@
 package models
 
 import (
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 type Dog struct {
-	ID        uuid.UUID `db:"id"`
-	Name      string    `db:"name"`
-	Sex       string    `db:"sex"`
-	Age       uint      `db:"age"`
-	Breed     string    `db:"breed"`
-	Image     string    `db:"image"`
-	UserID    uuid.UUID `db:"user_id"`
+	ID        uuid.UUID       `db:"id"`
+	Name      string          `db:"name"`
+	Sex       string          `db:"sex"`
+	Age       uint            `db:"age"`
+	Breed     string          `db:"breed"`
+	UserID    uuid.UUID       `db:"user_id"`
+	City      string          `db:"city"`
+	Latitude  sql.NullFloat64 `db:"latitude"`
+	Longitude sql.NullFloat64 `db:"longitude"`
+	// Distance km to the point dogs are searched near, selected by lists only.
+	Distance  sql.NullFloat64 `db:"distance"`
+	CreatedAt time.Time       `db:"created_at"`
+	UpdatedAt time.Time       `db:"updated_at"`
+}
+
+type DogPhoto struct {
+	ID         uuid.UUID     `db:"id"`
+	DogID      uuid.UUID     `db:"dog_id"`
+	Position   int           `db:"position"`
+	Image      string        `db:"image"`
+	ImageThumb string        `db:"image_thumb"`
+	ImageCard  string        `db:"image_card"`
+	PHash      sql.NullInt64 `db:"phash"`
+	IsPrimary  bool          `db:"is_primary"`
+	CreatedAt  time.Time     `db:"created_at"`
+}
+
+// DogImages image columns of dog_photos and flagged_photos.
+type DogImages struct {
+	Image      string `db:"image"`
+	ImageThumb string `db:"image_thumb"`
+	ImageCard  string `db:"image_card"`
+}
+
+type Reaction struct {
+	LikerID   uuid.UUID `db:"liker_id"`
+	LikedID   uuid.UUID `db:"liked_id"`
+	Action    string    `db:"action"`
 	CreatedAt time.Time `db:"created_at"`
-	UpdatedAt time.Time `db:"updated_at"`
+}
+
+type DogPreferences struct {
+	DogID         uuid.UUID      `db:"dog_id"`
+	Sex           sql.NullString `db:"sex"`
+	MinAge        sql.NullInt64  `db:"min_age"`
+	MaxAge        sql.NullInt64  `db:"max_age"`
+	Breeds        pq.StringArray `db:"breeds"`
+	MaxDistanceKm sql.NullInt64  `db:"max_distance_km"`
+	UpdatedAt     time.Time      `db:"updated_at"`
+}
+
+// MatchedDog dog which liked back and when.
+type MatchedDog struct {
+	Dog
+	MatchedAt time.Time `db:"matched_at"`
+}
+
+type ReactionStats struct {
+	LikerID   uuid.UUID `db:"liker_id"`
+	Likes     int       `db:"likes"`
+	Reactions int       `db:"reactions"`
 }
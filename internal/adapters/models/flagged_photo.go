@@ -0,0 +1,31 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type FlaggedPhoto struct {
+	ID             uuid.UUID     `db:"id"`
+	DogID          uuid.UUID     `db:"dog_id"`
+	Image          string        `db:"image"`
+	ImageThumb     string        `db:"image_thumb"`
+	ImageCard      string        `db:"image_card"`
+	PHash          int64         `db:"phash"`
+	MatchedPhotoID uuid.NullUUID `db:"matched_photo_id"`
+	MatchedDogID   uuid.NullUUID `db:"matched_dog_id"`
+	Distance       int           `db:"distance"`
+	Status         string        `db:"status"`
+	ReviewedBy     uuid.NullUUID `db:"reviewed_by"`
+	ReviewedAt     sql.NullTime  `db:"reviewed_at"`
+	CreatedAt      time.Time     `db:"created_at"`
+}
+
+type SimilarPhoto struct {
+	PhotoID  uuid.UUID `db:"photo_id"`
+	DogID    uuid.UUID `db:"dog_id"`
+	UserID   uuid.UUID `db:"user_id"`
+	Distance int       `db:"distance"`
+}
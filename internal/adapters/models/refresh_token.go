@@ -0,0 +1,19 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type RefreshToken struct {
+	ID        uuid.UUID    `db:"id"`
+	FamilyID  uuid.UUID    `db:"family_id"`
+	UserID    uuid.UUID    `db:"user_id"`
+	TokenHash string       `db:"token_hash"`
+	ExpiresAt time.Time    `db:"expires_at"`
+	UsedAt    sql.NullTime `db:"used_at"`
+	RevokedAt sql.NullTime `db:"revoked_at"`
+	CreatedAt time.Time    `db:"created_at"`
+}
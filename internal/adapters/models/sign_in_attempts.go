@@ -0,0 +1,9 @@
+package models
+
+import "time"
+
+type SignInAttempts struct {
+	Key          string    `db:"key"`
+	Failures     int       `db:"failures"`
+	LastFailedAt time.Time `db:"last_failed_at"`
+}
@@ -0,0 +1,36 @@
+package adapters
+
+import (
+	"context"
+
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+	"github.com/valerii-smirnov/petli-test-task/pkg/oidc"
+)
+
+// OIDCProvider external OpenID Connect identity provider.
+type OIDCProvider struct {
+	provider *oidc.Provider
+}
+
+func NewOIDCProvider(provider *oidc.Provider) *OIDCProvider {
+	return &OIDCProvider{provider: provider}
+}
+
+func (o OIDCProvider) AuthCodeURL(state, nonce, codeVerifier string) string {
+	return o.provider.AuthCodeURL(state, nonce, codeVerifier)
+}
+
+func (o OIDCProvider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (domain.ExternalIdentity, error) {
+	identity, err := o.provider.Exchange(ctx, code, codeVerifier, nonce)
+	if err != nil {
+		return domain.ExternalIdentity{}, ierr.WrapCode(ierr.Unauthenticated, err, "exchanging authorization code error")
+	}
+
+	return domain.ExternalIdentity{
+		Issuer:        identity.Issuer,
+		Subject:       identity.Subject,
+		Email:         identity.Email,
+		EmailVerified: identity.EmailVerified,
+	}, nil
+}
@@ -0,0 +1,62 @@
+package adapters
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/pkg/oidc"
+	"github.com/valerii-smirnov/petli-test-task/pkg/oidc/oidctest"
+)
+
+func TestOIDCProvider(t *testing.T) {
+	server := oidctest.NewServer("petly", "petly-secret")
+	defer server.Close()
+
+	server.SignIn(oidctest.Identity{Subject: "248289761001", Email: "test@test.com", EmailVerified: true})
+
+	provider, err := oidc.Discover(context.TODO(), nil, oidc.Config{
+		Issuer:       server.URL,
+		ClientID:     "petly",
+		ClientSecret: "petly-secret",
+		RedirectURL:  "http://localhost:8080/api/auth/oidc/callback",
+	})
+	if err != nil {
+		t.Fatalf("discovering stub provider error: %s", err)
+	}
+
+	o := NewOIDCProvider(provider)
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+
+	client := &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	res, err := client.Get(o.AuthCodeURL("state", "nonce", verifier))
+	if err != nil {
+		t.Fatalf("authorization request error: %s", err)
+	}
+	res.Body.Close()
+
+	location, err := url.Parse(res.Header.Get("Location"))
+	if err != nil {
+		t.Fatalf("parsing redirect location error: %s", err)
+	}
+
+	identity, err := o.Exchange(context.TODO(), location.Query().Get("code"), verifier, "nonce")
+	assert.NoError(t, err)
+	assert.Equal(t, domain.ExternalIdentity{
+		Issuer:        server.URL,
+		Subject:       "248289761001",
+		Email:         "test@test.com",
+		EmailVerified: true,
+	}, identity)
+
+	_, err = o.Exchange(context.TODO(), location.Query().Get("code"), verifier, "nonce")
+	assert.Error(t, err)
+}
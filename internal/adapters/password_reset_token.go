@@ -0,0 +1,86 @@
+package adapters
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/valerii-smirnov/petli-test-task/internal/adapters/models"
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type PasswordResetToken struct {
+	db *sqlx.DB
+}
+
+func NewPasswordResetToken(db *sqlx.DB) *PasswordResetToken {
+	return &PasswordResetToken{db: db}
+}
+
+func (p PasswordResetToken) Create(ctx context.Context, prt domain.PasswordResetToken) error {
+	query := "insert into password_reset_tokens (user_id, token_hash, expires_at) values ($1, $2, $3)"
+
+	if _, err := p.db.ExecContext(ctx, query, prt.UserID, prt.TokenHash, prt.ExpiresAt); err != nil {
+		return ierr.WrapCode(ierr.Internal, err, "execution insert query error")
+	}
+
+	return nil
+}
+
+func (p PasswordResetToken) GetByHash(ctx context.Context, tokenHash string) (domain.PasswordResetToken, error) {
+	query := "select * from password_reset_tokens where token_hash=$1"
+
+	var prt models.PasswordResetToken
+	if err := p.db.GetContext(ctx, &prt, query, tokenHash); err != nil {
+		if err == sql.ErrNoRows {
+			return domain.PasswordResetToken{}, ierr.WrapCode(ierr.NotFound, err, "password reset token not found")
+		}
+
+		return domain.PasswordResetToken{}, ierr.WrapCode(ierr.Internal, err, "execution select query error")
+	}
+
+	dPrt := domain.PasswordResetToken{
+		ID:        prt.ID,
+		UserID:    prt.UserID,
+		TokenHash: prt.TokenHash,
+		ExpiresAt: prt.ExpiresAt,
+		CreatedAt: prt.CreatedAt,
+	}
+
+	if prt.UsedAt.Valid {
+		dPrt.UsedAt = &prt.UsedAt.Time
+	}
+
+	return dPrt, nil
+}
+
+// MarkUsed marks token as used and reports if it was done by this call, so token can't be used twice concurrently.
+func (p PasswordResetToken) MarkUsed(ctx context.Context, id uuid.UUID) (bool, error) {
+	query := "update password_reset_tokens set used_at=now() where id=$1 and used_at is null"
+
+	res, err := p.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return false, ierr.WrapCode(ierr.Internal, err, "execution update query error")
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, ierr.WrapCode(ierr.Internal, err, "getting affected rows error")
+	}
+
+	return affected == 1, nil
+}
+
+// InvalidateForUser marks all not yet used tokens of the user as used.
+func (p PasswordResetToken) InvalidateForUser(ctx context.Context, userID uuid.UUID) error {
+	query := "update password_reset_tokens set used_at=now() where user_id=$1 and used_at is null"
+
+	if _, err := p.db.ExecContext(ctx, query, userID); err != nil {
+		return ierr.WrapCode(ierr.Internal, err, "execution update query error")
+	}
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package adapters
+
+import (
+	"context"
+
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type RecoveryCode struct {
+	db *sqlx.DB
+}
+
+func NewRecoveryCode(db *sqlx.DB) *RecoveryCode {
+	return &RecoveryCode{db: db}
+}
+
+// Replace removes all recovery codes of the user and stores the new ones.
+func (r RecoveryCode) Replace(ctx context.Context, userID uuid.UUID, codeHashes []string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return ierr.WrapCode(ierr.Internal, err, "beginning transaction error")
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "delete from recovery_codes where user_id=$1", userID); err != nil {
+		return ierr.WrapCode(ierr.Internal, err, "execution delete query error")
+	}
+
+	for _, hash := range codeHashes {
+		query := "insert into recovery_codes (user_id, code_hash) values ($1, $2)"
+		if _, err := tx.ExecContext(ctx, query, userID, hash); err != nil {
+			return ierr.WrapCode(ierr.Internal, err, "execution insert query error")
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return ierr.WrapCode(ierr.Internal, err, "committing transaction error")
+	}
+
+	return nil
+}
+
+// Use marks recovery code as used and reports if it was valid and not used before.
+func (r RecoveryCode) Use(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error) {
+	query := "update recovery_codes set used_at=now() where user_id=$1 and code_hash=$2 and used_at is null"
+
+	res, err := r.db.ExecContext(ctx, query, userID, codeHash)
+	if err != nil {
+		return false, ierr.WrapCode(ierr.Internal, err, "execution update query error")
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, ierr.WrapCode(ierr.Internal, err, "getting affected rows error")
+	}
+
+	return affected == 1, nil
+}
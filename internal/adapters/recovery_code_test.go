@@ -0,0 +1,139 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecoveryCode_Replace(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	testingError := errors.New("testing-error")
+	userID := uuid.New()
+	hashes := []string{"hash-1", "hash-2"}
+
+	type fields struct {
+		db *sqlx.DB
+	}
+	tests := []struct {
+		name      string
+		fields    fields
+		mocksInit func()
+		wantErr   bool
+	}{
+		{
+			name: "insert query error rolls back",
+			fields: fields{
+				db: sqlx.NewDb(db, "postgres"),
+			},
+			mocksInit: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec("delete from recovery_codes").WithArgs(userID).WillReturnResult(sqlmock.NewResult(0, 2))
+				mock.ExpectExec("insert into recovery_codes").WithArgs(userID, hashes[0]).WillReturnError(testingError)
+				mock.ExpectRollback()
+			},
+			wantErr: true,
+		},
+		{
+			name: "success",
+			fields: fields{
+				db: sqlx.NewDb(db, "postgres"),
+			},
+			mocksInit: func() {
+				mock.ExpectBegin()
+				mock.ExpectExec("delete from recovery_codes").WithArgs(userID).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec("insert into recovery_codes").WithArgs(userID, hashes[0]).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("insert into recovery_codes").WithArgs(userID, hashes[1]).WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectCommit()
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInit()
+
+			r := NewRecoveryCode(tt.fields.db)
+			err := r.Replace(context.TODO(), userID, hashes)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRecoveryCode_Use(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	testingError := errors.New("testing-error")
+	userID := uuid.New()
+	hash := "hash"
+
+	type fields struct {
+		db *sqlx.DB
+	}
+	tests := []struct {
+		name      string
+		fields    fields
+		mocksInit func()
+		want      bool
+		wantErr   bool
+	}{
+		{
+			name: "update query error",
+			fields: fields{
+				db: sqlx.NewDb(db, "postgres"),
+			},
+			mocksInit: func() {
+				mock.ExpectExec("update recovery_codes").WithArgs(userID, hash).WillReturnError(testingError)
+			},
+			want:    false,
+			wantErr: true,
+		},
+		{
+			name: "unknown or used code",
+			fields: fields{
+				db: sqlx.NewDb(db, "postgres"),
+			},
+			mocksInit: func() {
+				mock.ExpectExec("update recovery_codes").WithArgs(userID, hash).WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			want:    false,
+			wantErr: false,
+		},
+		{
+			name: "success",
+			fields: fields{
+				db: sqlx.NewDb(db, "postgres"),
+			},
+			mocksInit: func() {
+				mock.ExpectExec("update recovery_codes").WithArgs(userID, hash).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			want:    true,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInit()
+
+			r := NewRecoveryCode(tt.fields.db)
+			got, err := r.Use(context.TODO(), userID, hash)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
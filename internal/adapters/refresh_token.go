@@ -0,0 +1,105 @@
+package adapters
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/valerii-smirnov/petli-test-task/internal/adapters/models"
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type RefreshToken struct {
+	db *sqlx.DB
+}
+
+func NewRefreshToken(db *sqlx.DB) *RefreshToken {
+	return &RefreshToken{db: db}
+}
+
+func (r RefreshToken) Create(ctx context.Context, rt domain.RefreshToken) error {
+	query := "insert into refresh_tokens (family_id, user_id, token_hash, expires_at) values ($1, $2, $3, $4)"
+
+	if _, err := r.db.ExecContext(ctx, query, rt.FamilyID, rt.UserID, rt.TokenHash, rt.ExpiresAt); err != nil {
+		return ierr.WrapCode(ierr.Internal, err, "execution insert query error")
+	}
+
+	return nil
+}
+
+func (r RefreshToken) GetByHash(ctx context.Context, tokenHash string) (domain.RefreshToken, error) {
+	query := "select * from refresh_tokens where token_hash=$1"
+
+	var rt models.RefreshToken
+	if err := r.db.GetContext(ctx, &rt, query, tokenHash); err != nil {
+		if err == sql.ErrNoRows {
+			return domain.RefreshToken{}, ierr.WrapCode(ierr.NotFound, err, "refresh token not found")
+		}
+
+		return domain.RefreshToken{}, ierr.WrapCode(ierr.Internal, err, "execution select query error")
+	}
+
+	return r.refreshTokenToDomain(rt), nil
+}
+
+// MarkUsed marks token as used and reports if it was done by this call,
+// so concurrent usage of the same token is detected as reuse.
+func (r RefreshToken) MarkUsed(ctx context.Context, id uuid.UUID) (bool, error) {
+	query := "update refresh_tokens set used_at=now() where id=$1 and used_at is null and revoked_at is null"
+
+	res, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return false, ierr.WrapCode(ierr.Internal, err, "execution update query error")
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, ierr.WrapCode(ierr.Internal, err, "getting affected rows error")
+	}
+
+	return affected == 1, nil
+}
+
+func (r RefreshToken) RevokeFamily(ctx context.Context, familyID uuid.UUID) error {
+	query := "update refresh_tokens set revoked_at=now() where family_id=$1 and revoked_at is null"
+
+	if _, err := r.db.ExecContext(ctx, query, familyID); err != nil {
+		return ierr.WrapCode(ierr.Internal, err, "execution update query error")
+	}
+
+	return nil
+}
+
+func (r RefreshToken) RevokeAllForUser(ctx context.Context, userID uuid.UUID) error {
+	query := "update refresh_tokens set revoked_at=now() where user_id=$1 and revoked_at is null"
+
+	if _, err := r.db.ExecContext(ctx, query, userID); err != nil {
+		return ierr.WrapCode(ierr.Internal, err, "execution update query error")
+	}
+
+	return nil
+}
+
+func (r RefreshToken) refreshTokenToDomain(rt models.RefreshToken) domain.RefreshToken {
+	dRt := domain.RefreshToken{
+		ID:        rt.ID,
+		FamilyID:  rt.FamilyID,
+		UserID:    rt.UserID,
+		TokenHash: rt.TokenHash,
+		ExpiresAt: rt.ExpiresAt,
+		CreatedAt: rt.CreatedAt,
+	}
+
+	if rt.UsedAt.Valid {
+		dRt.UsedAt = &rt.UsedAt.Time
+	}
+
+	if rt.RevokedAt.Valid {
+		dRt.RevokedAt = &rt.RevokedAt.Time
+	}
+
+	return dRt
+}
@@ -0,0 +1,198 @@
+package adapters
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRefreshToken_GetByHash(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	testingError := errors.New("testing-error")
+	tokenHash := "token-hash"
+	now := time.Now()
+
+	expected := domain.RefreshToken{
+		ID:        uuid.New(),
+		FamilyID:  uuid.New(),
+		UserID:    uuid.New(),
+		TokenHash: tokenHash,
+		ExpiresAt: now.Add(time.Hour),
+		UsedAt:    &now,
+		CreatedAt: now,
+	}
+
+	columns := []string{"id", "family_id", "user_id", "token_hash", "expires_at", "used_at", "revoked_at", "created_at"}
+
+	type fields struct {
+		db *sqlx.DB
+	}
+	type args struct {
+		ctx       context.Context
+		tokenHash string
+	}
+	tests := []struct {
+		name      string
+		fields    fields
+		args      args
+		mocksInit func()
+		want      domain.RefreshToken
+		wantErr   bool
+	}{
+		{
+			name: "no rows error",
+			fields: fields{
+				db: sqlx.NewDb(db, "postgres"),
+			},
+			args: args{
+				ctx:       context.TODO(),
+				tokenHash: tokenHash,
+			},
+			mocksInit: func() {
+				mock.ExpectQuery("select").WithArgs(tokenHash).WillReturnError(sql.ErrNoRows)
+			},
+			want:    domain.RefreshToken{},
+			wantErr: true,
+		},
+		{
+			name: "execution select query error",
+			fields: fields{
+				db: sqlx.NewDb(db, "postgres"),
+			},
+			args: args{
+				ctx:       context.TODO(),
+				tokenHash: tokenHash,
+			},
+			mocksInit: func() {
+				mock.ExpectQuery("select").WithArgs(tokenHash).WillReturnError(testingError)
+			},
+			want:    domain.RefreshToken{},
+			wantErr: true,
+		},
+		{
+			name: "success",
+			fields: fields{
+				db: sqlx.NewDb(db, "postgres"),
+			},
+			args: args{
+				ctx:       context.TODO(),
+				tokenHash: tokenHash,
+			},
+			mocksInit: func() {
+				rows := sqlmock.NewRows(columns).
+					AddRow(expected.ID, expected.FamilyID, expected.UserID, tokenHash, expected.ExpiresAt, now, nil, now)
+
+				mock.ExpectQuery("select").WithArgs(tokenHash).WillReturnRows(rows)
+			},
+			want:    expected,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInit()
+
+			r := NewRefreshToken(tt.fields.db)
+			got, err := r.GetByHash(tt.args.ctx, tt.args.tokenHash)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRefreshToken_MarkUsed(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	testingError := errors.New("testing-error")
+	id := uuid.New()
+
+	type fields struct {
+		db *sqlx.DB
+	}
+	type args struct {
+		ctx context.Context
+		id  uuid.UUID
+	}
+	tests := []struct {
+		name      string
+		fields    fields
+		args      args
+		mocksInit func()
+		want      bool
+		wantErr   bool
+	}{
+		{
+			name: "update query error",
+			fields: fields{
+				db: sqlx.NewDb(db, "postgres"),
+			},
+			args: args{
+				ctx: context.TODO(),
+				id:  id,
+			},
+			mocksInit: func() {
+				mock.ExpectExec("update refresh_tokens").WithArgs(id).WillReturnError(testingError)
+			},
+			want:    false,
+			wantErr: true,
+		},
+		{
+			name: "already used",
+			fields: fields{
+				db: sqlx.NewDb(db, "postgres"),
+			},
+			args: args{
+				ctx: context.TODO(),
+				id:  id,
+			},
+			mocksInit: func() {
+				mock.ExpectExec("update refresh_tokens").WithArgs(id).WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			want:    false,
+			wantErr: false,
+		},
+		{
+			name: "success",
+			fields: fields{
+				db: sqlx.NewDb(db, "postgres"),
+			},
+			args: args{
+				ctx: context.TODO(),
+				id:  id,
+			},
+			mocksInit: func() {
+				mock.ExpectExec("update refresh_tokens").WithArgs(id).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			want:    true,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInit()
+
+			r := NewRefreshToken(tt.fields.db)
+			got, err := r.MarkUsed(tt.args.ctx, tt.args.id)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
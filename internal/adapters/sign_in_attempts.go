@@ -0,0 +1,77 @@
+package adapters
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/valerii-smirnov/petli-test-task/internal/adapters/models"
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type SignInAttempts struct {
+	db *sqlx.DB
+}
+
+func NewSignInAttempts(db *sqlx.DB) *SignInAttempts {
+	return &SignInAttempts{db: db}
+}
+
+// Get returns failed attempts of the key. Key without failures has zero attempts.
+func (s SignInAttempts) Get(ctx context.Context, key string) (domain.SignInAttempts, error) {
+	query := "select * from sign_in_attempts where key=$1"
+
+	var attempts models.SignInAttempts
+	if err := s.db.GetContext(ctx, &attempts, query, key); err != nil {
+		if err == sql.ErrNoRows {
+			return domain.SignInAttempts{Key: key}, nil
+		}
+
+		return domain.SignInAttempts{}, ierr.WrapCode(ierr.Internal, err, "execution select query error")
+	}
+
+	return domain.SignInAttempts(attempts), nil
+}
+
+// RegisterFailure atomically increments failures counter of the key. Counter starts over
+// if the previous failure happened before resetBefore.
+func (s SignInAttempts) RegisterFailure(ctx context.Context, key string, failedAt, resetBefore time.Time) (domain.SignInAttempts, error) {
+	query := `insert into sign_in_attempts (key, failures, last_failed_at) values ($1, 1, $2)
+		on conflict (key) do update set
+			failures = case when sign_in_attempts.last_failed_at < $3 then 1 else sign_in_attempts.failures + 1 end,
+			last_failed_at = excluded.last_failed_at
+		returning *`
+
+	var attempts models.SignInAttempts
+	if err := s.db.GetContext(ctx, &attempts, query, key, failedAt, resetBefore); err != nil {
+		return domain.SignInAttempts{}, ierr.WrapCode(ierr.Internal, err, "execution upsert query error")
+	}
+
+	return domain.SignInAttempts(attempts), nil
+}
+
+func (s SignInAttempts) Reset(ctx context.Context, key string) error {
+	if _, err := s.db.ExecContext(ctx, "delete from sign_in_attempts where key=$1", key); err != nil {
+		return ierr.WrapCode(ierr.Internal, err, "execution delete query error")
+	}
+
+	return nil
+}
+
+// DeleteStale removes keys which have not failed since before.
+func (s SignInAttempts) DeleteStale(ctx context.Context, before time.Time) (int64, error) {
+	res, err := s.db.ExecContext(ctx, "delete from sign_in_attempts where last_failed_at < $1", before)
+	if err != nil {
+		return 0, ierr.WrapCode(ierr.Internal, err, "execution delete query error")
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, ierr.WrapCode(ierr.Internal, err, "getting affected rows error")
+	}
+
+	return affected, nil
+}
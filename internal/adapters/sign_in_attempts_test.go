@@ -0,0 +1,141 @@
+package adapters
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignInAttempts_Get(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	testingError := errors.New("testing-error")
+	key := "email:test@email.com"
+	now := time.Now()
+
+	type fields struct {
+		db *sqlx.DB
+	}
+	tests := []struct {
+		name      string
+		fields    fields
+		mocksInit func()
+		want      domain.SignInAttempts
+		wantErr   bool
+	}{
+		{
+			name: "no failures",
+			fields: fields{
+				db: sqlx.NewDb(db, "postgres"),
+			},
+			mocksInit: func() {
+				mock.ExpectQuery("select").WithArgs(key).WillReturnError(sql.ErrNoRows)
+			},
+			want:    domain.SignInAttempts{Key: key},
+			wantErr: false,
+		},
+		{
+			name: "execution select query error",
+			fields: fields{
+				db: sqlx.NewDb(db, "postgres"),
+			},
+			mocksInit: func() {
+				mock.ExpectQuery("select").WithArgs(key).WillReturnError(testingError)
+			},
+			want:    domain.SignInAttempts{},
+			wantErr: true,
+		},
+		{
+			name: "success",
+			fields: fields{
+				db: sqlx.NewDb(db, "postgres"),
+			},
+			mocksInit: func() {
+				rows := sqlmock.NewRows([]string{"key", "failures", "last_failed_at"}).AddRow(key, 3, now)
+				mock.ExpectQuery("select").WithArgs(key).WillReturnRows(rows)
+			},
+			want:    domain.SignInAttempts{Key: key, Failures: 3, LastFailedAt: now},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInit()
+
+			s := NewSignInAttempts(tt.fields.db)
+			got, err := s.Get(context.TODO(), key)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSignInAttempts_RegisterFailure(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	testingError := errors.New("testing-error")
+	key := "ip:127.0.0.1"
+	now := time.Now()
+	resetBefore := now.Add(-time.Hour)
+
+	type fields struct {
+		db *sqlx.DB
+	}
+	tests := []struct {
+		name      string
+		fields    fields
+		mocksInit func()
+		want      domain.SignInAttempts
+		wantErr   bool
+	}{
+		{
+			name: "upsert query error",
+			fields: fields{
+				db: sqlx.NewDb(db, "postgres"),
+			},
+			mocksInit: func() {
+				mock.ExpectQuery("insert into sign_in_attempts").WithArgs(key, now, resetBefore).WillReturnError(testingError)
+			},
+			want:    domain.SignInAttempts{},
+			wantErr: true,
+		},
+		{
+			name: "success",
+			fields: fields{
+				db: sqlx.NewDb(db, "postgres"),
+			},
+			mocksInit: func() {
+				rows := sqlmock.NewRows([]string{"key", "failures", "last_failed_at"}).AddRow(key, 4, now)
+				mock.ExpectQuery("insert into sign_in_attempts").WithArgs(key, now, resetBefore).WillReturnRows(rows)
+			},
+			want:    domain.SignInAttempts{Key: key, Failures: 4, LastFailedAt: now},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInit()
+
+			s := NewSignInAttempts(tt.fields.db)
+			got, err := s.RegisterFailure(context.TODO(), key, now, resetBefore)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package adapters
+
+import (
+	"context"
+	"time"
+
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type TokenRevocation struct {
+	db *sqlx.DB
+}
+
+func NewTokenRevocation(db *sqlx.DB) *TokenRevocation {
+	return &TokenRevocation{db: db}
+}
+
+// Revoke puts token id to denylist until token expiration.
+func (t TokenRevocation) Revoke(ctx context.Context, claims domain.TokenClaims) error {
+	query := "insert into revoked_tokens (id, user_id, expires_at) values ($1, $2, $3) on conflict (id) do nothing"
+
+	if _, err := t.db.ExecContext(ctx, query, claims.ID, claims.UserID, claims.ExpiresAt); err != nil {
+		return ierr.WrapCode(ierr.Internal, err, "execution insert query error")
+	}
+
+	return nil
+}
+
+// RevokeAllIssuedBefore invalidates every token of the user issued before provided time.
+// Token issue time is whole seconds, so the time is truncated to seconds: tokens issued within the same second,
+// e.g. the new session of password change, stay valid.
+func (t TokenRevocation) RevokeAllIssuedBefore(ctx context.Context, userID uuid.UUID, before time.Time) error {
+	query := "update users set tokens_valid_after=$1 where id=$2"
+
+	if _, err := t.db.ExecContext(ctx, query, before.Truncate(time.Second), userID); err != nil {
+		return ierr.WrapCode(ierr.Internal, err, "execution update query error")
+	}
+
+	return nil
+}
+
+// IsRevoked reports if the token is in denylist or was issued before all tokens of the user were revoked.
+func (t TokenRevocation) IsRevoked(ctx context.Context, claims domain.TokenClaims) (bool, error) {
+	// tokens_valid_after written before it was truncated on write may have fractional seconds.
+	query := `select exists(select 1 from revoked_tokens where id = $1)
+				or exists(select 1 from users where id = $2 and date_trunc('second', tokens_valid_after) > $3)`
+
+	var revoked bool
+	if err := t.db.QueryRowContext(ctx, query, claims.ID, claims.UserID, claims.IssuedAt).Scan(&revoked); err != nil {
+		return false, ierr.WrapCode(ierr.Internal, err, "execution select query error")
+	}
+
+	return revoked, nil
+}
+
+// DeleteExpired removes denylist entries of tokens which are expired anyway.
+func (t TokenRevocation) DeleteExpired(ctx context.Context) (int64, error) {
+	res, err := t.db.ExecContext(ctx, "delete from revoked_tokens where expires_at < now()")
+	if err != nil {
+		return 0, ierr.WrapCode(ierr.Internal, err, "execution delete query error")
+	}
+
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return 0, ierr.WrapCode(ierr.Internal, err, "getting affected rows error")
+	}
+
+	return deleted, nil
+}
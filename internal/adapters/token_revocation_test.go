@@ -0,0 +1,194 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenRevocation_IsRevoked(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	testingError := errors.New("testing-error")
+
+	claims := domain.TokenClaims{
+		ID:        uuid.New(),
+		UserID:    uuid.New(),
+		IssuedAt:  time.Now().Add(-time.Minute),
+		ExpiresAt: time.Now().Add(time.Minute),
+	}
+
+	type fields struct {
+		db *sqlx.DB
+	}
+	type args struct {
+		ctx    context.Context
+		claims domain.TokenClaims
+	}
+	tests := []struct {
+		name      string
+		fields    fields
+		args      args
+		mocksInit func()
+		want      bool
+		wantErr   bool
+	}{
+		{
+			name: "query execution error",
+			fields: fields{
+				db: sqlx.NewDb(db, "postgres"),
+			},
+			args: args{
+				ctx:    context.TODO(),
+				claims: claims,
+			},
+			mocksInit: func() {
+				mock.ExpectQuery("select").WithArgs(claims.ID, claims.UserID, claims.IssuedAt).WillReturnError(testingError)
+			},
+			want:    false,
+			wantErr: true,
+		},
+		{
+			name: "token is not revoked",
+			fields: fields{
+				db: sqlx.NewDb(db, "postgres"),
+			},
+			args: args{
+				ctx:    context.TODO(),
+				claims: claims,
+			},
+			mocksInit: func() {
+				rows := sqlmock.NewRows([]string{"revoked"}).AddRow(false)
+				mock.ExpectQuery("select").WithArgs(claims.ID, claims.UserID, claims.IssuedAt).WillReturnRows(rows)
+			},
+			want:    false,
+			wantErr: false,
+		},
+		{
+			name: "token is revoked",
+			fields: fields{
+				db: sqlx.NewDb(db, "postgres"),
+			},
+			args: args{
+				ctx:    context.TODO(),
+				claims: claims,
+			},
+			mocksInit: func() {
+				rows := sqlmock.NewRows([]string{"revoked"}).AddRow(true)
+				mock.ExpectQuery("select").WithArgs(claims.ID, claims.UserID, claims.IssuedAt).WillReturnRows(rows)
+			},
+			want:    true,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInit()
+
+			r := NewTokenRevocation(tt.fields.db)
+			got, err := r.IsRevoked(tt.args.ctx, tt.args.claims)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestTokenRevocation_RevokeAllIssuedBefore(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	r := NewTokenRevocation(sqlx.NewDb(db, "postgres"))
+	userID := uuid.New()
+	revokedAt := time.Date(2023, 2, 1, 10, 0, 5, 700000000, time.UTC)
+
+	t.Run("revocation time is truncated to seconds", func(t *testing.T) {
+		mock.ExpectExec("update users set tokens_valid_after").
+			WithArgs(time.Date(2023, 2, 1, 10, 0, 5, 0, time.UTC), userID).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, r.RevokeAllIssuedBefore(context.TODO(), userID, revokedAt))
+	})
+
+	t.Run("token issued in the same second stays valid", func(t *testing.T) {
+		// iat of the token is whole seconds, it's compared with the truncated revocation time.
+		claims := domain.TokenClaims{ID: uuid.New(), UserID: userID, IssuedAt: time.Date(2023, 2, 1, 10, 0, 5, 0, time.UTC)}
+		mock.ExpectQuery(`date_trunc\('second', tokens_valid_after\) > \$3`).
+			WithArgs(claims.ID, claims.UserID, claims.IssuedAt).
+			WillReturnRows(sqlmock.NewRows([]string{"revoked"}).AddRow(false))
+
+		revoked, err := r.IsRevoked(context.TODO(), claims)
+		assert.NoError(t, err)
+		assert.False(t, revoked)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTokenRevocation_DeleteExpired(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	testingError := errors.New("testing-error")
+
+	type fields struct {
+		db *sqlx.DB
+	}
+	tests := []struct {
+		name      string
+		fields    fields
+		mocksInit func()
+		want      int64
+		wantErr   bool
+	}{
+		{
+			name: "delete query error",
+			fields: fields{
+				db: sqlx.NewDb(db, "postgres"),
+			},
+			mocksInit: func() {
+				mock.ExpectExec("delete from revoked_tokens").WillReturnError(testingError)
+			},
+			want:    0,
+			wantErr: true,
+		},
+		{
+			name: "success",
+			fields: fields{
+				db: sqlx.NewDb(db, "postgres"),
+			},
+			mocksInit: func() {
+				mock.ExpectExec("delete from revoked_tokens").WillReturnResult(sqlmock.NewResult(0, 3))
+			},
+			want:    3,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInit()
+
+			r := NewTokenRevocation(tt.fields.db)
+			got, err := r.DeleteExpired(context.TODO())
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
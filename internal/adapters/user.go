@@ -3,11 +3,15 @@ package adapters
 import (
 	"context"
 	"database/sql"
+	"time"
+
 	"github.com/valerii-smirnov/petli-test-task/internal/adapters/models"
 	"github.com/valerii-smirnov/petli-test-task/internal/domain"
 	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
 
+	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
 type User struct {
@@ -38,12 +42,216 @@ func (u User) Create(ctx context.Context, in domain.SignUp) error {
 	return nil
 }
 
-func (u User) Get(ctx context.Context, in domain.SingIn) (domain.User, error) {
-	query := "select * from users WHERE email=$1 AND password_hash=$2"
+func (u User) Get(ctx context.Context, userID uuid.UUID) (domain.User, error) {
+	return u.getOne(ctx, "select * from users where id=$1", userID)
+}
+
+func (u User) GetByEmail(ctx context.Context, email string) (domain.User, error) {
+	return u.getOne(ctx, "select * from users WHERE email=$1", email)
+}
+
+func (u User) UpdatePasswordHash(ctx context.Context, userID uuid.UUID, passwordHash string) error {
+	query := "update users set password_hash=$1 where id=$2"
+
+	if _, err := u.db.ExecContext(ctx, query, passwordHash, userID); err != nil {
+		return ierr.WrapCode(ierr.Internal, err, "execution update query error")
+	}
+
+	return nil
+}
+
+func (u User) MarkEmailVerified(ctx context.Context, userID uuid.UUID) error {
+	query := "update users set email_verified_at=now() where id=$1 and email_verified_at is null"
+
+	if _, err := u.db.ExecContext(ctx, query, userID); err != nil {
+		return ierr.WrapCode(ierr.Internal, err, "execution update query error")
+	}
+
+	return nil
+}
+
+// SetTOTPSecret starts two-factor enrollment, two-factor authentication stays disabled until it is enabled explicitly.
+func (u User) SetTOTPSecret(ctx context.Context, userID uuid.UUID, secret string) error {
+	query := "update users set totp_secret=$1, totp_enabled_at=null, totp_last_step=0 where id=$2"
+
+	if _, err := u.db.ExecContext(ctx, query, secret, userID); err != nil {
+		return ierr.WrapCode(ierr.Internal, err, "execution update query error")
+	}
+
+	return nil
+}
+
+func (u User) EnableTOTP(ctx context.Context, userID uuid.UUID) error {
+	query := "update users set totp_enabled_at=now() where id=$1 and totp_secret is not null"
+
+	if _, err := u.db.ExecContext(ctx, query, userID); err != nil {
+		return ierr.WrapCode(ierr.Internal, err, "execution update query error")
+	}
+
+	return nil
+}
+
+func (u User) DisableTOTP(ctx context.Context, userID uuid.UUID) error {
+	query := "update users set totp_secret=null, totp_enabled_at=null, totp_last_step=0 where id=$1"
+
+	if _, err := u.db.ExecContext(ctx, query, userID); err != nil {
+		return ierr.WrapCode(ierr.Internal, err, "execution update query error")
+	}
+
+	return nil
+}
+
+// UseTOTPStep records time step of accepted code and reports if it is newer than the previously accepted one,
+// so the same code can't be used twice.
+func (u User) UseTOTPStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error) {
+	query := "update users set totp_last_step=$1 where id=$2 and totp_last_step < $1"
+
+	res, err := u.db.ExecContext(ctx, query, step, userID)
+	if err != nil {
+		return false, ierr.WrapCode(ierr.Internal, err, "execution update query error")
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, ierr.WrapCode(ierr.Internal, err, "getting affected rows error")
+	}
+
+	return affected == 1, nil
+}
+
+func (u User) UpdateRole(ctx context.Context, userID uuid.UUID, role domain.Role) error {
+	query := "update users set role=$1 where id=$2"
+
+	res, err := u.db.ExecContext(ctx, query, role.String(), userID)
+	if err != nil {
+		return ierr.WrapCode(ierr.Internal, err, "execution update query error")
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return ierr.WrapCode(ierr.Internal, err, "getting affected rows error")
+	}
+
+	if affected == 0 {
+		return ierr.New(ierr.NotFound, "user not found")
+	}
+
+	return nil
+}
+
+// UpdateProfile replaces profile fields of the user and returns the updated user.
+func (u User) UpdateProfile(ctx context.Context, userID uuid.UUID, profile domain.Profile) (domain.User, error) {
+	query := "update users set display_name=$1, city=$2, bio=$3, avatar_url=$4 where id=$5 returning *"
+
+	return u.getOne(ctx, query, profile.DisplayName, profile.City, profile.Bio, profile.AvatarURL, userID)
+}
 
+// UpdateEmail sets confirmed email of the user, so the email is also marked as verified.
+func (u User) UpdateEmail(ctx context.Context, userID uuid.UUID, email string) error {
+	query := "update users set email=$1, email_verified_at=now() where id=$2"
+
+	res, err := u.db.ExecContext(ctx, query, email, userID)
+	if err != nil {
+		return ierr.WrapCode(ierr.Internal, err, "execution update query error")
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return ierr.WrapCode(ierr.Internal, err, "getting affected rows error")
+	}
+
+	if affected == 0 {
+		return ierr.New(ierr.NotFound, "user not found")
+	}
+
+	return nil
+}
+
+// ScheduleDeletion marks the account to be purged at the given time.
+func (u User) ScheduleDeletion(ctx context.Context, userID uuid.UUID, at time.Time) error {
+	return u.setDeletionScheduledAt(ctx, "update users set deletion_scheduled_at=$1 where id=$2", at, userID)
+}
+
+// CancelDeletion removes pending deletion mark of the account.
+func (u User) CancelDeletion(ctx context.Context, userID uuid.UUID) error {
+	return u.setDeletionScheduledAt(ctx, "update users set deletion_scheduled_at=null where id=$1", userID)
+}
+
+// DeleteScheduled purges accounts which deletion is due by the given time. Dogs, reactions, tokens
+// and the rest of the user's data are removed by cascade, images of the dogs' photos are returned
+// to be removed from the store.
+func (u User) DeleteScheduled(ctx context.Context, before time.Time) (domain.PurgedAccounts, error) {
+	tx, err := u.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return domain.PurgedAccounts{}, ierr.WrapCode(ierr.Internal, err, "beginning transaction error")
+	}
+	defer tx.Rollback()
+
+	// accounts are locked, so deletion can't be canceled once their images are read.
+	var ids pq.StringArray
+	query := "select id from users where deletion_scheduled_at <= $1 for update"
+	if err := tx.SelectContext(ctx, &ids, query, before); err != nil {
+		return domain.PurgedAccounts{}, ierr.WrapCode(ierr.Internal, err, "getting scheduled users error")
+	}
+
+	if len(ids) == 0 {
+		return domain.PurgedAccounts{}, nil
+	}
+
+	var images []models.DogImages
+	query = `select p.image, p.image_thumb, p.image_card from dog_photos p 
+				join dogs d on d.id = p.dog_id where d.user_id = any($1::uuid[])
+			union
+			select f.image, f.image_thumb, f.image_card from flagged_photos f 
+				join dogs d on d.id = f.dog_id where d.user_id = any($1::uuid[])`
+	if err := tx.SelectContext(ctx, &images, query, ids); err != nil {
+		return domain.PurgedAccounts{}, ierr.WrapCode(ierr.Internal, err, "getting images of scheduled users error")
+	}
+
+	res, err := tx.ExecContext(ctx, "delete from users where id = any($1::uuid[])", ids)
+	if err != nil {
+		return domain.PurgedAccounts{}, ierr.WrapCode(ierr.Internal, err, "execution delete query error")
+	}
+
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return domain.PurgedAccounts{}, ierr.WrapCode(ierr.Internal, err, "getting affected rows error")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return domain.PurgedAccounts{}, ierr.WrapCode(ierr.Internal, err, "committing transaction error")
+	}
+
+	purged := domain.PurgedAccounts{Count: deleted, Images: make([]domain.DogImages, 0, len(images))}
+	for _, image := range images {
+		purged.Images = append(purged.Images, domain.DogImages{Thumb: image.ImageThumb, Card: image.ImageCard, Full: image.Image})
+	}
+
+	return purged, nil
+}
+
+func (u User) setDeletionScheduledAt(ctx context.Context, query string, args ...interface{}) error {
+	res, err := u.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return ierr.WrapCode(ierr.Internal, err, "execution update query error")
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return ierr.WrapCode(ierr.Internal, err, "getting affected rows error")
+	}
+
+	if affected == 0 {
+		return ierr.New(ierr.NotFound, "user not found")
+	}
+
+	return nil
+}
+
+func (u User) getOne(ctx context.Context, query string, args ...interface{}) (domain.User, error) {
 	var user models.User
 
-	if err := u.db.GetContext(ctx, &user, query, in.Email, in.Password); err != nil {
+	if err := u.db.GetContext(ctx, &user, query, args...); err != nil {
 		if err == sql.ErrNoRows {
 			return domain.User{}, ierr.WrapCode(ierr.NotFound, err, "user not found")
 		}
@@ -51,9 +259,39 @@ func (u User) Get(ctx context.Context, in domain.SingIn) (domain.User, error) {
 		return domain.User{}, ierr.WrapCode(ierr.Internal, err, "execution select query error")
 	}
 
-	return domain.User{
+	return u.userToDomain(user), nil
+}
+
+func (u User) userToDomain(user models.User) domain.User {
+	dUser := domain.User{
 		ID:           user.ID,
 		Email:        user.Email,
+		PasswordHash: user.PasswordHash,
+		Role:         domain.Role(user.Role),
 		RegisteredAt: user.RegisteredAt,
-	}, nil
+		Profile: domain.Profile{
+			DisplayName: user.DisplayName,
+			City:        user.City,
+			Bio:         user.Bio,
+			AvatarURL:   user.AvatarURL,
+		},
+	}
+
+	if user.EmailVerifiedAt.Valid {
+		dUser.EmailVerifiedAt = &user.EmailVerifiedAt.Time
+	}
+
+	if user.TOTPSecret.Valid {
+		dUser.TOTPSecret = user.TOTPSecret.String
+	}
+
+	if user.TOTPEnabledAt.Valid {
+		dUser.TOTPEnabledAt = &user.TOTPEnabledAt.Time
+	}
+
+	if user.DeletionScheduledAt.Valid {
+		dUser.DeletionScheduledAt = &user.DeletionScheduledAt.Time
+	}
+
+	return dUser
 }
@@ -0,0 +1,48 @@
+package adapters
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/valerii-smirnov/petli-test-task/internal/adapters/models"
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// UserIdentity links users to their identities at external OpenID Connect providers.
+type UserIdentity struct {
+	db *sqlx.DB
+}
+
+func NewUserIdentity(db *sqlx.DB) *UserIdentity {
+	return &UserIdentity{db: db}
+}
+
+// GetUserID returns id of the user the identity is linked to.
+func (u UserIdentity) GetUserID(ctx context.Context, issuer, subject string) (uuid.UUID, error) {
+	query := "select * from user_identities where issuer=$1 and subject=$2"
+
+	var identity models.UserIdentity
+	if err := u.db.GetContext(ctx, &identity, query, issuer, subject); err != nil {
+		if err == sql.ErrNoRows {
+			return uuid.Nil, ierr.WrapCode(ierr.NotFound, err, "user identity not found")
+		}
+
+		return uuid.Nil, ierr.WrapCode(ierr.Internal, err, "execution select query error")
+	}
+
+	return identity.UserID, nil
+}
+
+func (u UserIdentity) Create(ctx context.Context, userID uuid.UUID, identity domain.ExternalIdentity) error {
+	query := "insert into user_identities (user_id, issuer, subject) values ($1, $2, $3) on conflict (issuer, subject) do nothing"
+
+	if _, err := u.db.ExecContext(ctx, query, userID, identity.Issuer, identity.Subject); err != nil {
+		return ierr.WrapCode(ierr.Internal, err, "execution insert query error")
+	}
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+)
+
+func TestUserIdentity_GetUserID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	testingError := errors.New("testing-error")
+	userID := uuid.New()
+	issuer := "https://accounts.example.com"
+	subject := "248289761001"
+
+	type fields struct {
+		db *sqlx.DB
+	}
+	tests := []struct {
+		name      string
+		fields    fields
+		mocksInit func()
+		want      uuid.UUID
+		wantCode  ierr.Code
+		wantErr   bool
+	}{
+		{
+			name: "select query error",
+			fields: fields{
+				db: sqlx.NewDb(db, "postgres"),
+			},
+			mocksInit: func() {
+				mock.ExpectQuery("select \\* from user_identities").WithArgs(issuer, subject).WillReturnError(testingError)
+			},
+			want:     uuid.Nil,
+			wantCode: ierr.Internal,
+			wantErr:  true,
+		},
+		{
+			name: "not found",
+			fields: fields{
+				db: sqlx.NewDb(db, "postgres"),
+			},
+			mocksInit: func() {
+				mock.ExpectQuery("select \\* from user_identities").WithArgs(issuer, subject).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "issuer", "subject", "created_at"}))
+			},
+			want:     uuid.Nil,
+			wantCode: ierr.NotFound,
+			wantErr:  true,
+		},
+		{
+			name: "success",
+			fields: fields{
+				db: sqlx.NewDb(db, "postgres"),
+			},
+			mocksInit: func() {
+				rows := sqlmock.NewRows([]string{"id", "user_id", "issuer", "subject"}).
+					AddRow(uuid.New(), userID, issuer, subject)
+				mock.ExpectQuery("select \\* from user_identities").WithArgs(issuer, subject).WillReturnRows(rows)
+			},
+			want:    userID,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInit()
+
+			u := NewUserIdentity(tt.fields.db)
+			got, err := u.GetUserID(context.TODO(), issuer, subject)
+			assert.Equal(t, tt.wantErr, err != nil)
+			if tt.wantErr {
+				assert.Equal(t, tt.wantCode, ierr.GetCode(err))
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestUserIdentity_Create(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	userID := uuid.New()
+	identity := domain.ExternalIdentity{
+		Issuer:  "https://accounts.example.com",
+		Subject: "248289761001",
+	}
+
+	mock.ExpectExec("insert into user_identities").WithArgs(userID, identity.Issuer, identity.Subject).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = NewUserIdentity(sqlx.NewDb(db, "postgres")).Create(context.TODO(), userID, identity)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
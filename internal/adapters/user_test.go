@@ -8,10 +8,12 @@ import (
 	"time"
 
 	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -172,7 +174,7 @@ func TestUser_Create(t *testing.T) {
 	}
 }
 
-func TestUser_Get(t *testing.T) {
+func TestUser_GetByEmail(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
 		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
@@ -181,23 +183,24 @@ func TestUser_Get(t *testing.T) {
 
 	testingError := errors.New("testing-error")
 
-	in := domain.SingIn{
-		Email:    "test@email.com",
-		Password: "qwerty hashed",
-	}
+	email := "test@email.com"
+
+	now := time.Now()
 
 	expectedUser := domain.User{
-		ID:           uuid.New(),
-		Email:        in.Email,
-		RegisteredAt: time.Now(),
+		ID:              uuid.New(),
+		Email:           email,
+		PasswordHash:    "qwerty hashed",
+		RegisteredAt:    now,
+		EmailVerifiedAt: &now,
 	}
 
 	type fields struct {
 		db *sqlx.DB
 	}
 	type args struct {
-		ctx context.Context
-		in  domain.SingIn
+		ctx   context.Context
+		email string
 	}
 	tests := []struct {
 		name      string
@@ -213,11 +216,11 @@ func TestUser_Get(t *testing.T) {
 				db: sqlx.NewDb(db, "postgres"),
 			},
 			args: args{
-				ctx: context.TODO(),
-				in:  in,
+				ctx:   context.TODO(),
+				email: email,
 			},
 			mocksInit: func() {
-				mock.ExpectQuery("select").WithArgs(in.Email, in.Password).WillReturnError(sql.ErrNoRows)
+				mock.ExpectQuery("select").WithArgs(email).WillReturnError(sql.ErrNoRows)
 			},
 			want:    domain.User{},
 			wantErr: true,
@@ -228,11 +231,11 @@ func TestUser_Get(t *testing.T) {
 				db: sqlx.NewDb(db, "postgres"),
 			},
 			args: args{
-				ctx: context.TODO(),
-				in:  in,
+				ctx:   context.TODO(),
+				email: email,
 			},
 			mocksInit: func() {
-				mock.ExpectQuery("select").WithArgs(in.Email, in.Password).WillReturnError(testingError)
+				mock.ExpectQuery("select").WithArgs(email).WillReturnError(testingError)
 			},
 			want:    domain.User{},
 			wantErr: true,
@@ -243,15 +246,15 @@ func TestUser_Get(t *testing.T) {
 				db: sqlx.NewDb(db, "postgres"),
 			},
 			args: args{
-				ctx: context.TODO(),
-				in:  in,
+				ctx:   context.TODO(),
+				email: email,
 			},
 			mocksInit: func() {
-				rows := sqlmock.NewRows([]string{"id", "email", "password_hash", "registered_at"}).
-					AddRow(expectedUser.ID, expectedUser.Email, in.Password, expectedUser.RegisteredAt)
+				rows := sqlmock.NewRows([]string{"id", "email", "password_hash", "registered_at", "email_verified_at"}).
+					AddRow(expectedUser.ID, expectedUser.Email, expectedUser.PasswordHash, expectedUser.RegisteredAt, now)
 
 				mock.ExpectQuery("select").
-					WithArgs(in.Email, in.Password).
+					WithArgs(email).
 					WillReturnRows(rows)
 			},
 			want:    expectedUser,
@@ -263,9 +266,549 @@ func TestUser_Get(t *testing.T) {
 			tt.mocksInit()
 
 			u := NewUser(tt.fields.db)
-			got, err := u.Get(tt.args.ctx, tt.args.in)
+			got, err := u.GetByEmail(tt.args.ctx, tt.args.email)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestUser_UpdatePasswordHash(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	testingError := errors.New("testing-error")
+
+	userID := uuid.New()
+	hash := "$argon2id$v=19$m=65536,t=3,p=2$c2FsdA$a2V5"
+
+	type fields struct {
+		db *sqlx.DB
+	}
+	type args struct {
+		ctx          context.Context
+		userID       uuid.UUID
+		passwordHash string
+	}
+	tests := []struct {
+		name      string
+		fields    fields
+		args      args
+		mocksInit func()
+		wantErr   bool
+	}{
+		{
+			name: "update query error",
+			fields: fields{
+				db: sqlx.NewDb(db, "postgres"),
+			},
+			args: args{
+				ctx:          context.TODO(),
+				userID:       userID,
+				passwordHash: hash,
+			},
+			mocksInit: func() {
+				mock.ExpectExec("update users").WithArgs(hash, userID).WillReturnError(testingError)
+			},
+			wantErr: true,
+		},
+		{
+			name: "success",
+			fields: fields{
+				db: sqlx.NewDb(db, "postgres"),
+			},
+			args: args{
+				ctx:          context.TODO(),
+				userID:       userID,
+				passwordHash: hash,
+			},
+			mocksInit: func() {
+				mock.ExpectExec("update users").WithArgs(hash, userID).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInit()
+
+			u := NewUser(tt.fields.db)
+			err := u.UpdatePasswordHash(tt.args.ctx, tt.args.userID, tt.args.passwordHash)
+			assert.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
+
+func TestUser_Get(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	testingError := errors.New("testing-error")
+
+	expectedUser := domain.User{
+		ID:           uuid.New(),
+		Email:        "test@email.com",
+		PasswordHash: "qwerty hashed",
+		RegisteredAt: time.Now(),
+	}
+
+	type fields struct {
+		db *sqlx.DB
+	}
+	type args struct {
+		ctx    context.Context
+		userID uuid.UUID
+	}
+	tests := []struct {
+		name      string
+		fields    fields
+		args      args
+		mocksInit func()
+		want      domain.User
+		wantErr   bool
+	}{
+		{
+			name: "no rows error",
+			fields: fields{
+				db: sqlx.NewDb(db, "postgres"),
+			},
+			args: args{
+				ctx:    context.TODO(),
+				userID: expectedUser.ID,
+			},
+			mocksInit: func() {
+				mock.ExpectQuery("select").WithArgs(expectedUser.ID).WillReturnError(sql.ErrNoRows)
+			},
+			want:    domain.User{},
+			wantErr: true,
+		},
+		{
+			name: "execution select query error",
+			fields: fields{
+				db: sqlx.NewDb(db, "postgres"),
+			},
+			args: args{
+				ctx:    context.TODO(),
+				userID: expectedUser.ID,
+			},
+			mocksInit: func() {
+				mock.ExpectQuery("select").WithArgs(expectedUser.ID).WillReturnError(testingError)
+			},
+			want:    domain.User{},
+			wantErr: true,
+		},
+		{
+			name: "success, email is not verified",
+			fields: fields{
+				db: sqlx.NewDb(db, "postgres"),
+			},
+			args: args{
+				ctx:    context.TODO(),
+				userID: expectedUser.ID,
+			},
+			mocksInit: func() {
+				rows := sqlmock.NewRows([]string{"id", "email", "password_hash", "registered_at", "email_verified_at"}).
+					AddRow(expectedUser.ID, expectedUser.Email, expectedUser.PasswordHash, expectedUser.RegisteredAt, nil)
+
+				mock.ExpectQuery("select").WithArgs(expectedUser.ID).WillReturnRows(rows)
+			},
+			want:    expectedUser,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInit()
+
+			u := NewUser(tt.fields.db)
+			got, err := u.Get(tt.args.ctx, tt.args.userID)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestUser_MarkEmailVerified(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	testingError := errors.New("testing-error")
+	userID := uuid.New()
+
+	type fields struct {
+		db *sqlx.DB
+	}
+	type args struct {
+		ctx    context.Context
+		userID uuid.UUID
+	}
+	tests := []struct {
+		name      string
+		fields    fields
+		args      args
+		mocksInit func()
+		wantErr   bool
+	}{
+		{
+			name: "update query error",
+			fields: fields{
+				db: sqlx.NewDb(db, "postgres"),
+			},
+			args: args{
+				ctx:    context.TODO(),
+				userID: userID,
+			},
+			mocksInit: func() {
+				mock.ExpectExec("update users").WithArgs(userID).WillReturnError(testingError)
+			},
+			wantErr: true,
+		},
+		{
+			name: "success",
+			fields: fields{
+				db: sqlx.NewDb(db, "postgres"),
+			},
+			args: args{
+				ctx:    context.TODO(),
+				userID: userID,
+			},
+			mocksInit: func() {
+				mock.ExpectExec("update users").WithArgs(userID).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInit()
+
+			u := NewUser(tt.fields.db)
+			err := u.MarkEmailVerified(tt.args.ctx, tt.args.userID)
+			assert.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
+
+func TestUser_UseTOTPStep(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	testingError := errors.New("testing-error")
+	userID := uuid.New()
+	step := int64(55555555)
+
+	type fields struct {
+		db *sqlx.DB
+	}
+	tests := []struct {
+		name      string
+		fields    fields
+		mocksInit func()
+		want      bool
+		wantErr   bool
+	}{
+		{
+			name: "update query error",
+			fields: fields{
+				db: sqlx.NewDb(db, "postgres"),
+			},
+			mocksInit: func() {
+				mock.ExpectExec("update users set totp_last_step").WithArgs(step, userID).WillReturnError(testingError)
+			},
+			want:    false,
+			wantErr: true,
+		},
+		{
+			name: "step already used",
+			fields: fields{
+				db: sqlx.NewDb(db, "postgres"),
+			},
+			mocksInit: func() {
+				mock.ExpectExec("update users set totp_last_step").WithArgs(step, userID).WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			want:    false,
+			wantErr: false,
+		},
+		{
+			name: "success",
+			fields: fields{
+				db: sqlx.NewDb(db, "postgres"),
+			},
+			mocksInit: func() {
+				mock.ExpectExec("update users set totp_last_step").WithArgs(step, userID).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			want:    true,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInit()
+
+			u := NewUser(tt.fields.db)
+			got, err := u.UseTOTPStep(context.TODO(), userID, step)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestUser_UpdateRole(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	testingError := errors.New("testing-error")
+	userID := uuid.New()
+
+	type fields struct {
+		db *sqlx.DB
+	}
+	tests := []struct {
+		name      string
+		fields    fields
+		mocksInit func()
+		wantCode  ierr.Code
+		wantErr   bool
+	}{
+		{
+			name: "update query error",
+			fields: fields{
+				db: sqlx.NewDb(db, "postgres"),
+			},
+			mocksInit: func() {
+				mock.ExpectExec("update users set role").WithArgs("moderator", userID).WillReturnError(testingError)
+			},
+			wantCode: ierr.Internal,
+			wantErr:  true,
+		},
+		{
+			name: "user not found",
+			fields: fields{
+				db: sqlx.NewDb(db, "postgres"),
+			},
+			mocksInit: func() {
+				mock.ExpectExec("update users set role").WithArgs("moderator", userID).WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantCode: ierr.NotFound,
+			wantErr:  true,
+		},
+		{
+			name: "success",
+			fields: fields{
+				db: sqlx.NewDb(db, "postgres"),
+			},
+			mocksInit: func() {
+				mock.ExpectExec("update users set role").WithArgs("moderator", userID).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInit()
+
+			u := NewUser(tt.fields.db)
+			err := u.UpdateRole(context.TODO(), userID, domain.RoleModerator)
+			assert.Equal(t, tt.wantErr, err != nil)
+			if tt.wantErr {
+				assert.Equal(t, tt.wantCode, ierr.GetCode(err))
+			}
+		})
+	}
+}
+
+func TestUser_UpdateProfile(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	userID := uuid.New()
+	registeredAt := time.Now()
+	profile := domain.Profile{
+		DisplayName: "Spike's owner",
+		City:        "Kyiv",
+		Bio:         "Bulldogs only",
+		AvatarURL:   "https://example.com/avatar.png",
+	}
+
+	tests := []struct {
+		name      string
+		mocksInit func()
+		want      domain.User
+		wantCode  ierr.Code
+		wantErr   bool
+	}{
+		{
+			name: "user not found",
+			mocksInit: func() {
+				mock.ExpectQuery("update users set display_name").
+					WithArgs(profile.DisplayName, profile.City, profile.Bio, profile.AvatarURL, userID).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}))
+			},
+			want:     domain.User{},
+			wantCode: ierr.NotFound,
+			wantErr:  true,
+		},
+		{
+			name: "success",
+			mocksInit: func() {
+				rows := sqlmock.NewRows([]string{"id", "email", "password_hash", "role", "registered_at", "display_name", "city", "bio", "avatar_url"}).
+					AddRow(userID, "test@email.com", "hash", "user", registeredAt, profile.DisplayName, profile.City, profile.Bio, profile.AvatarURL)
+				mock.ExpectQuery("update users set display_name").
+					WithArgs(profile.DisplayName, profile.City, profile.Bio, profile.AvatarURL, userID).
+					WillReturnRows(rows)
+			},
+			want: domain.User{
+				ID:           userID,
+				Email:        "test@email.com",
+				PasswordHash: "hash",
+				Role:         domain.RoleUser,
+				RegisteredAt: registeredAt,
+				Profile:      profile,
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInit()
+
+			got, err := NewUser(sqlx.NewDb(db, "postgres")).UpdateProfile(context.TODO(), userID, profile)
 			assert.Equal(t, tt.wantErr, err != nil)
+			if tt.wantErr {
+				assert.Equal(t, tt.wantCode, ierr.GetCode(err))
+			}
 			assert.Equal(t, tt.want, got)
 		})
 	}
 }
+
+func TestUser_UpdateEmail(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	userID := uuid.New()
+	email := "new@email.com"
+
+	mock.ExpectExec("update users set email=\\$1, email_verified_at=now\\(\\)").WithArgs(email, userID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = NewUser(sqlx.NewDb(db, "postgres")).UpdateEmail(context.TODO(), userID, email)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUser_ScheduleDeletion(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	userID := uuid.New()
+	deleteAt := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name      string
+		mocksInit func()
+		wantCode  ierr.Code
+		wantErr   bool
+	}{
+		{
+			name: "user not found",
+			mocksInit: func() {
+				mock.ExpectExec("update users set deletion_scheduled_at=\\$1").WithArgs(deleteAt, userID).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			wantCode: ierr.NotFound,
+			wantErr:  true,
+		},
+		{
+			name: "success",
+			mocksInit: func() {
+				mock.ExpectExec("update users set deletion_scheduled_at=\\$1").WithArgs(deleteAt, userID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInit()
+
+			err := NewUser(sqlx.NewDb(db, "postgres")).ScheduleDeletion(context.TODO(), userID, deleteAt)
+			assert.Equal(t, tt.wantErr, err != nil)
+			if tt.wantErr {
+				assert.Equal(t, tt.wantCode, ierr.GetCode(err))
+			}
+		})
+	}
+}
+
+func TestUser_DeleteScheduled(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	before := time.Now()
+	ids := pq.StringArray{uuid.New().String(), uuid.New().String()}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("select id from users where deletion_scheduled_at <= \\$1 for update").WithArgs(before).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(ids[0]).AddRow(ids[1]))
+	mock.ExpectQuery("select p.image, p.image_thumb, p.image_card from dog_photos p").WithArgs(ids).
+		WillReturnRows(sqlmock.NewRows([]string{"image", "image_thumb", "image_card"}).AddRow("full", "thumb", "card"))
+	mock.ExpectExec("delete from users where id = any\\(\\$1::uuid\\[\\]\\)").WithArgs(ids).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	purged, err := NewUser(sqlx.NewDb(db, "postgres")).DeleteScheduled(context.TODO(), before)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.PurgedAccounts{
+		Count:  2,
+		Images: []domain.DogImages{{Thumb: "thumb", Card: "card", Full: "full"}},
+	}, purged)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUser_DeleteScheduled_Nothing(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	before := time.Now()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("select id from users where deletion_scheduled_at <= \\$1 for update").WithArgs(before).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectRollback()
+
+	purged, err := NewUser(sqlx.NewDb(db, "postgres")).DeleteScheduled(context.TODO(), before)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.PurgedAccounts{}, purged)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
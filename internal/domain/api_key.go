@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APIKeyScope limits requests which can be authenticated with API key.
+type APIKeyScope string
+
+const (
+	// APIKeyScopeRead allows read-only requests.
+	APIKeyScopeRead APIKeyScope = "read"
+	// APIKeyScopeWrite allows requests changing data.
+	APIKeyScopeWrite APIKeyScope = "write"
+)
+
+func (s APIKeyScope) String() string {
+	return string(s)
+}
+
+// Valid reports if the scope is known.
+func (s APIKeyScope) Valid() bool {
+	return s == APIKeyScopeRead || s == APIKeyScopeWrite
+}
+
+// APIKey personal key of the user for scripts and service accounts. Only hash of the key is stored,
+// Prefix is kept to let the user recognize the key.
+type APIKey struct {
+	ID         uuid.UUID
+	UserID     uuid.UUID
+	Name       string
+	Prefix     string
+	KeyHash    string
+	Scopes     []APIKeyScope
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+	CreatedAt  time.Time
+}
+
+// HasScope reports if the key is granted the scope.
+func (k APIKey) HasScope(scope APIKeyScope) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+type APIKeyCreate struct {
+	Name   string
+	Scopes []APIKeyScope
+}
+
+// CreatedAPIKey newly created key, the Key itself is returned only once.
+type CreatedAPIKey struct {
+	APIKey APIKey
+	Key    string
+}
+
+type apiKeyContextKey struct{}
+
+// ContextWithAPIKey returns context of request authenticated with API key, such requests never act with elevated roles.
+func ContextWithAPIKey(ctx context.Context) context.Context {
+	return context.WithValue(ctx, apiKeyContextKey{}, true)
+}
+
+// EffectiveRole returns the role of the user the request with the context acts with, RoleUser for API key requests.
+func EffectiveRole(ctx context.Context, role Role) Role {
+	if byAPIKey, _ := ctx.Value(apiKeyContextKey{}).(bool); byAPIKey {
+		return RoleUser
+	}
+
+	return role
+}
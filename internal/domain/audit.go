@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AuditEventType string
+
+const (
+	AuditSignUp             AuditEventType = "sign_up"
+	AuditSignIn             AuditEventType = "sign_in"
+	AuditSignInSecondFactor AuditEventType = "sign_in_second_factor"
+	AuditSignInExternal     AuditEventType = "sign_in_external"
+	AuditTokenRefresh       AuditEventType = "token_refresh"
+	AuditPasswordChange     AuditEventType = "password_change"
+	AuditPasswordReset      AuditEventType = "password_reset"
+)
+
+func (t AuditEventType) String() string {
+	return string(t)
+}
+
+type AuditOutcome string
+
+const (
+	AuditSuccess AuditOutcome = "success"
+	AuditFailure AuditOutcome = "failure"
+	// AuditMFARequired the first sign-in step is passed, the second factor is still required.
+	AuditMFARequired AuditOutcome = "mfa_required"
+)
+
+func (o AuditOutcome) String() string {
+	return string(o)
+}
+
+// AuditEvent security relevant action. UserID is nil if the actor isn't known, e.g. sign-in with unknown email.
+type AuditEvent struct {
+	ID        uuid.UUID
+	UserID    *uuid.UUID
+	Email     string
+	Type      AuditEventType
+	Outcome   AuditOutcome
+	Reason    string
+	ClientIP  string
+	UserAgent string
+	CreatedAt time.Time
+}
+
+// AuditEventFilter empty fields aren't filtered by.
+type AuditEventFilter struct {
+	UserID     *uuid.UUID
+	Email      string
+	Type       AuditEventType
+	Outcome    AuditOutcome
+	ClientIP   string
+	From       time.Time
+	To         time.Time
+	Pagination Pagination
+}
+
+// Client the request is made by.
+type Client struct {
+	IP        string
+	UserAgent string
+}
+
+type clientContextKey struct{}
+
+// ContextWithClient returns context carrying the client, so it is known deep in usecases without passing it explicitly.
+func ContextWithClient(ctx context.Context, client Client) context.Context {
+	return context.WithValue(ctx, clientContextKey{}, client)
+}
+
+// ClientFromContext returns client the context carries, empty one if none.
+func ClientFromContext(ctx context.Context) Client {
+	client, _ := ctx.Value(clientContextKey{}).(Client)
+	return client
+}
@@ -1,5 +1,11 @@
 package domain
 
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
 type SignUp struct {
 	Email    string
 	Password string
@@ -8,6 +14,90 @@ type SignUp struct {
 type SingIn struct {
 	Email    string
 	Password string
+	ClientIP string
 }
 
 type Token string
+
+type Tokens struct {
+	Access  Token
+	Refresh Token
+	// MFA short-lived token issued instead of Access and Refresh when user has to pass the second factor.
+	MFA Token
+}
+
+type RefreshToken struct {
+	ID        uuid.UUID
+	FamilyID  uuid.UUID
+	UserID    uuid.UUID
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	RevokedAt *time.Time
+	CreatedAt time.Time
+}
+
+// TokenClaims identifies issued access token.
+type TokenClaims struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	Role      Role
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+}
+
+// PasswordResetToken single-use token emailed to the user to set a new password.
+type PasswordResetToken struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+type PasswordResetConfirm struct {
+	Token    Token
+	Password string
+}
+
+// SignInAttempts failed sign-in attempts made for the key, e.g. email or client ip.
+type SignInAttempts struct {
+	Key          string
+	Failures     int
+	LastFailedAt time.Time
+}
+
+type TOTPEnrollment struct {
+	Secret          string
+	ProvisioningURI string
+	RecoveryCodes   []string
+}
+
+// SecondFactorSignIn second sign-in step, either TOTP code or recovery code is required.
+type SecondFactorSignIn struct {
+	MFAToken     Token
+	Code         string
+	RecoveryCode string
+	ClientIP     string
+}
+
+// ExternalIdentity user identity asserted by external OpenID Connect provider.
+type ExternalIdentity struct {
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+}
+
+// OIDCLogin start of OpenID Connect login. FlowToken binds the callback to the browser the login was started in.
+type OIDCLogin struct {
+	AuthURL   string
+	FlowToken string
+}
+
+type OIDCCallback struct {
+	Code      string
+	State     string
+	FlowToken string
+}
@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type DataExportStatus string
+
+const (
+	DataExportPending    DataExportStatus = "pending"
+	DataExportProcessing DataExportStatus = "processing"
+	DataExportReady      DataExportStatus = "ready"
+	DataExportFailed     DataExportStatus = "failed"
+)
+
+func (s DataExportStatus) String() string {
+	return string(s)
+}
+
+// DataExport background job building archive of the user's data. The archive is kept until ExpiresAt.
+type DataExport struct {
+	ID          uuid.UUID
+	UserID      uuid.UUID
+	Status      DataExportStatus
+	CreatedAt   time.Time
+	CompletedAt *time.Time
+	ExpiresAt   *time.Time
+}
+
+// AccountData everything stored about the user, exported on the user's request.
+type AccountData struct {
+	User      User
+	Dogs      DogList
+	Reactions []Reaction
+	Matches   []Match
+}
+
+// ExportResult either archive built right away or job building it in background.
+type ExportResult struct {
+	Archive []byte
+	Job     *DataExport
+}
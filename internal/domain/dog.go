@@ -15,10 +15,78 @@ const (
 
 type DogSex string
 
+const (
+	Male   DogSex = "male"
+	Female DogSex = "female"
+)
+
 func (s DogSex) String() string {
 	return string(s)
 }
 
+// Valid reports if the sex is known.
+func (s DogSex) Valid() bool {
+	return s == Male || s == Female
+}
+
+// DogSort order of dogs list, field and direction.
+type DogSort string
+
+const (
+	SortCreatedDesc DogSort = "created-desc"
+	SortCreatedAsc  DogSort = "created-asc"
+	SortAgeAsc      DogSort = "age-asc"
+	SortAgeDesc     DogSort = "age-desc"
+	SortNameAsc     DogSort = "name-asc"
+	SortNameDesc    DogSort = "name-desc"
+	// SortDistanceAsc the nearest first, only for lists searched near a point.
+	SortDistanceAsc DogSort = "distance-asc"
+)
+
+func (s DogSort) String() string {
+	return string(s)
+}
+
+// Valid reports if the sort order is known.
+func (s DogSort) Valid() bool {
+	switch s {
+	case SortCreatedDesc, SortCreatedAsc, SortAgeAsc, SortAgeDesc, SortNameAsc, SortNameDesc, SortDistanceAsc:
+		return true
+	}
+
+	return false
+}
+
+// ByCreation reports if the sort order is by creation time, only such lists are paged through by cursor.
+func (s DogSort) ByCreation() bool {
+	return s == SortCreatedDesc || s == SortCreatedAsc
+}
+
+// DogFilter narrows down dogs list, zero fields don't filter. Breeds match any of them, case-insensitively.
+// Dogs of the list searched Near a point have distance to it, RadiusKm leaves out dogs further than it.
+type DogFilter struct {
+	Sex          DogSex
+	MinAge       *uint
+	MaxAge       *uint
+	Breeds       []string
+	CreatedAfter time.Time
+	Near         *GeoPoint
+	RadiusKm     float64
+	Sort         DogSort
+}
+
+// DogPreferences dogs the dog is looking for, zero fields match any dog. Breeds match any of them,
+// case-insensitively, MaxDistanceKm needs locations of both dogs.
+type DogPreferences struct {
+	Sex           DogSex
+	MinAge        *uint
+	MaxAge        *uint
+	Breeds        []string
+	MaxDistanceKm *uint
+}
+
+// Dog is in the City, Location is geocoded from it unless coordinates are given. Distance from the point
+// the list is searched near is rounded km, it's nil for dogs without location.
 type Dog struct {
 	ID        uuid.UUID
 	UserID    uuid.UUID
@@ -26,20 +94,66 @@ type Dog struct {
 	Sex       DogSex
 	Age       uint
 	Breed     string
-	Image     string
+	Photos    []DogPhoto
+	City      string
+	Location  *GeoPoint
+	Distance  *uint
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
 
+// PrimaryPhoto returns the photo representing the dog, zero photo if the dog has no photos.
+func (d Dog) PrimaryPhoto() DogPhoto {
+	for _, photo := range d.Photos {
+		if photo.Primary {
+			return photo
+		}
+	}
+
+	return DogPhoto{}
+}
+
+// DogPhoto photo of the dog. Photos of the dog are ordered, the only primary one represents the dog in lists.
+type DogPhoto struct {
+	ID     uuid.UUID
+	Images DogImages
+	// Hash perceptual hash of uploaded photo, nil for photos given by URL before uploads, they are never downloaded.
+	Hash      *ImageHash
+	Primary   bool
+	CreatedAt time.Time
+}
+
+// Pagination page by its number or, if After is set, the page following the cursor. Page number is ignored then.
 type Pagination struct {
 	Page    int
 	PerPage int
+	After   *Cursor
+}
+
+// Cursor position in the list ordered by creation time and ID, the next page starts right after it.
+// Unlike page numbers it neither repeats nor skips items created while paging through.
+type Cursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
 }
 
 type DogList []Dog
 
+// DogPage page of dogs, NextCursor is nil on the last page and for lists which aren't ordered by creation time.
+type DogPage struct {
+	Dogs       DogList
+	NextCursor *Cursor
+}
+
 type Reaction struct {
-	Liker  uuid.UUID
-	Liked  uuid.UUID
-	Action Action
+	Liker     uuid.UUID
+	Liked     uuid.UUID
+	Action    Action
+	CreatedAt time.Time
+}
+
+// Match dog of another user which liked the user's dog back.
+type Match struct {
+	DogID      uuid.UUID
+	MatchedDog Dog
 }
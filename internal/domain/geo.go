@@ -0,0 +1,30 @@
+package domain
+
+import "math"
+
+// GeoPoint point on the Earth, latitude and longitude in degrees.
+type GeoPoint struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// Valid reports if the coordinates are within their ranges.
+func (p GeoPoint) Valid() bool {
+	return p.Latitude >= -90 && p.Latitude <= 90 && p.Longitude >= -180 && p.Longitude <= 180
+}
+
+// City known city the location can be given by instead of coordinates.
+type City struct {
+	Name    string
+	Country string
+	Point   GeoPoint
+}
+
+// RoundDistance rounds the distance in km up to whole km, at least 1, so exact location of the dog can't be told.
+func RoundDistance(km float64) uint {
+	if km <= 1 {
+		return 1
+	}
+
+	return uint(math.Ceil(km))
+}
@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"io"
+	"math/bits"
+)
+
+// ImageUpload image uploaded by the user. ContentType is detected from the content, not taken from the client.
+type ImageUpload struct {
+	ContentType string
+	Size        int64
+	Content     io.Reader
+}
+
+type ImageRendition string
+
+const (
+	RenditionThumb ImageRendition = "thumb"
+	RenditionCard  ImageRendition = "card"
+	RenditionFull  ImageRendition = "full"
+)
+
+func (r ImageRendition) String() string {
+	return string(r)
+}
+
+// ProcessedImage rendition of uploaded image, re-encoded without any metadata of the original.
+type ProcessedImage struct {
+	Rendition   ImageRendition
+	ContentType string
+	Extension   string
+	Content     []byte
+}
+
+// ImageHash perceptual hash of the image, visually similar images have hashes differing in few bits.
+type ImageHash uint64
+
+// Distance number of bits the hashes differ in, 0 for the same looking images, 64 at most.
+func (h ImageHash) Distance(other ImageHash) int {
+	return bits.OnesCount64(uint64(h ^ other))
+}
+
+// ProcessedUpload renditions of uploaded image and perceptual hash of it.
+type ProcessedUpload struct {
+	Renditions []ProcessedImage
+	Hash       ImageHash
+}
+
+// DogImages URLs of the dog image renditions.
+type DogImages struct {
+	Thumb string
+	Card  string
+	Full  string
+}
+
+// ExternalDogImages images of the dog hosted elsewhere, there are no renditions of them, so the only URL is used for all.
+func ExternalDogImages(url string) DogImages {
+	return DogImages{Thumb: url, Card: url, Full: url}
+}
+
+// Set sets URL of the rendition.
+func (i *DogImages) Set(rendition ImageRendition, url string) {
+	switch rendition {
+	case RenditionThumb:
+		i.Thumb = url
+	case RenditionCard:
+		i.Card = url
+	case RenditionFull:
+		i.Full = url
+	}
+}
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ReviewStatus string
+
+const (
+	ReviewPending  ReviewStatus = "pending"
+	ReviewApproved ReviewStatus = "approved"
+	ReviewRejected ReviewStatus = "rejected"
+)
+
+func (s ReviewStatus) String() string {
+	return string(s)
+}
+
+// SimilarPhoto photo of the dog which looks like the uploaded one, Distance is the distance of their hashes.
+type SimilarPhoto struct {
+	PhotoID  uuid.UUID
+	DogID    uuid.UUID
+	UserID   uuid.UUID
+	Distance int
+}
+
+// FlaggedPhoto uploaded photo held for moderation, because it looks like a photo of another user's dog.
+// The photo is added to the dog only if a moderator approves it.
+type FlaggedPhoto struct {
+	ID     uuid.UUID
+	DogID  uuid.UUID
+	Images DogImages
+	Hash   ImageHash
+	// MatchedPhotoID and MatchedDogID are nil once the matched photo or its dog is deleted.
+	MatchedPhotoID *uuid.UUID
+	MatchedDogID   *uuid.UUID
+	Distance       int
+	Status         ReviewStatus
+	ReviewedBy     *uuid.UUID
+	ReviewedAt     *time.Time
+	CreatedAt      time.Time
+}
+
+type FlaggedPhotoFilter struct {
+	Status     ReviewStatus
+	Pagination Pagination
+}
+
+// PhotoUpload outcome of photo upload, either the dog the photo is added to or the photo held for moderation.
+type PhotoUpload struct {
+	Dog     Dog
+	Flagged *FlaggedPhoto
+}
@@ -0,0 +1,38 @@
+package domain
+
+// RecommendationWeights weights of score components, the score is the weighted sum of components in [0, 1].
+type RecommendationWeights struct {
+	Preference float64
+	Distance   float64
+	Recency    float64
+	LikeBack   float64
+	Diversity  float64
+}
+
+// ScoreBreakdown components of the recommendation score, each in [0, 1], and their weighted sum.
+type ScoreBreakdown struct {
+	// Preference how well the dogs fit preferences of each other, closer to the middle of the age range and
+	// further within max distance fit better, unset preferences don't count.
+	Preference float64
+	// Distance closer dogs score more, dogs without location score 0.
+	Distance float64
+	// Recency newer dogs score more.
+	Recency float64
+	// LikeBack how likely the dog likes back by the share of likes among its reactions to other dogs.
+	LikeBack float64
+	// Diversity lower for breeds and owners already recommended above.
+	Diversity float64
+	Total     float64
+}
+
+// Recommendation dog recommended to react to and its score.
+type Recommendation struct {
+	Dog   Dog
+	Score ScoreBreakdown
+}
+
+// ReactionStats reactions the dog gave to other dogs than the one the stats are collected for.
+type ReactionStats struct {
+	Likes     int
+	Reactions int
+}
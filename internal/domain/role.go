@@ -0,0 +1,58 @@
+package domain
+
+// Role of the user, it defines what the user is permitted to do besides managing own resources.
+type Role string
+
+const (
+	RoleUser      Role = "user"
+	RoleModerator Role = "moderator"
+	RoleAdmin     Role = "admin"
+)
+
+type Permission string
+
+const (
+	// PermissionManageAnyDog allows to edit and delete dogs of other users.
+	PermissionManageAnyDog Permission = "dogs:manage-any"
+	// PermissionManageUsers allows to change roles of other users.
+	PermissionManageUsers Permission = "users:manage"
+	// PermissionViewAuditLog allows to search security events of all users.
+	PermissionViewAuditLog Permission = "audit:view"
+	// PermissionModeratePhotos allows to approve or reject photos held for moderation.
+	PermissionModeratePhotos Permission = "photos:moderate"
+	// PermissionTuneRecommendations allows to see score breakdown of recommendations, it tells reactions of other dogs.
+	PermissionTuneRecommendations Permission = "recommendations:tune"
+)
+
+var rolePermissions = map[Role][]Permission{
+	RoleUser:      {},
+	RoleModerator: {PermissionManageAnyDog, PermissionModeratePhotos},
+	RoleAdmin: {
+		PermissionManageAnyDog,
+		PermissionManageUsers,
+		PermissionViewAuditLog,
+		PermissionModeratePhotos,
+		PermissionTuneRecommendations,
+	},
+}
+
+func (r Role) String() string {
+	return string(r)
+}
+
+// Valid reports if the role is known.
+func (r Role) Valid() bool {
+	_, ok := rolePermissions[r]
+	return ok
+}
+
+// Can reports if the role is granted the permission.
+func (r Role) Can(permission Permission) bool {
+	for _, p := range rolePermissions[r] {
+		if p == permission {
+			return true
+		}
+	}
+
+	return false
+}
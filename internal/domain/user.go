@@ -9,5 +9,47 @@ import (
 type User struct {
 	ID           uuid.UUID
 	Email        string
+	PasswordHash string
+	Role         Role
 	RegisteredAt time.Time
+	// EmailVerifiedAt is nil until user follows verification link sent on sign-up.
+	EmailVerifiedAt *time.Time
+	// TOTPSecret is set on two-factor enrollment, TOTPEnabledAt once enrollment is confirmed with a valid code.
+	TOTPSecret    string
+	TOTPEnabledAt *time.Time
+	Profile       Profile
+	// DeletionScheduledAt is set while account deletion is pending, the account is purged once it passes.
+	DeletionScheduledAt *time.Time
+}
+
+// PurgedAccounts accounts removed once their deletion was due.
+type PurgedAccounts struct {
+	Count int64
+	// Images of the photos of purged users' dogs, including the ones held for moderation.
+	Images []DogImages
+}
+
+// Profile public information the user tells about themselves.
+type Profile struct {
+	DisplayName string
+	City        string
+	Bio         string
+	AvatarURL   string
+}
+
+// PasswordChange the current password is required, so stolen session isn't enough to take over the account.
+type PasswordChange struct {
+	CurrentPassword string
+	NewPassword     string
+}
+
+// EmailChange new email takes effect once confirmation link sent to it is followed.
+type EmailChange struct {
+	NewEmail        string
+	CurrentPassword string
+}
+
+// AccountDeletion the current password is required, so stolen session isn't enough to delete the account.
+type AccountDeletion struct {
+	CurrentPassword string
 }
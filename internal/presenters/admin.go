@@ -0,0 +1,184 @@
+package presenters
+
+import (
+	"net/http"
+
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/internal/presenters/messages"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+	"github.com/valerii-smirnov/petli-test-task/pkg/utils/gin/resp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Admin presenter of user management and audit log, available only to users permitted to manage users or view audit log.
+type Admin struct {
+	adminUsecase      AdminUsecase
+	identityExtractor IdentityExtractor
+	paginator         Paginator
+
+	middlewares []gin.HandlerFunc
+}
+
+func NewAdmin(
+	adminUsecase AdminUsecase,
+	identityExtractor IdentityExtractor,
+	paginator Paginator,
+	middlewares ...gin.HandlerFunc,
+) *Admin {
+	return &Admin{
+		adminUsecase:      adminUsecase,
+		identityExtractor: identityExtractor,
+		paginator:         paginator,
+		middlewares:       middlewares,
+	}
+}
+
+func (a Admin) Inject(r gin.IRouter) {
+	adminGroup := r.Group("/admin")
+	if len(a.middlewares) > 0 {
+		adminGroup.Use(a.middlewares...)
+	}
+
+	adminGroup.PUT("/users/:id/role", a.AssignRole)
+	adminGroup.GET("/audit-events", a.SearchAuditEvents)
+}
+
+// AssignRole godoc
+// @Summary      User role assignment
+// @Description  Changes role of the user. New role takes effect on the next access token refresh.
+// @ID 			 Assign user role
+// @Tags         admin
+// @Security 	 ApiKeyAuth
+// @Accept       json
+// @Produce      json
+// @Param 		 id path string true "user ID"
+// @Param 		 input body messages.AssignRoleRequestBody true "role"
+// @Success      204
+// @Failure      400  {object}  messages.BadRequestError
+// @Failure      401  {object}  messages.UnauthenticatedError
+// @Failure      403  {object}  messages.ForbiddenError
+// @Failure      404  {object}  messages.NotFoundError
+// @Failure      500  {object}  messages.InternalServerError
+// @Router       /admin/users/{id}/role [put]
+func (a Admin) AssignRole(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		resp.AbortWithError(c, ierr.WrapCode(ierr.InvalidArgument, err, "wrong user id"))
+		return
+	}
+
+	var req messages.AssignRoleRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	actorID, err := a.identityExtractor.ExtractFromContext(c)
+	if err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	if err := a.adminUsecase.AssignRole(c, actorID, userID, domain.Role(req.Role)); err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	c.AbortWithStatus(http.StatusNoContent)
+}
+
+// SearchAuditEvents godoc
+// @Summary      Audit log search
+// @Description  Returns security events of all users matching the filter, the newest first. Events created in [from, to) are returned.
+// @ID 			 Search audit events
+// @Tags         admin
+// @Security 	 ApiKeyAuth
+// @Produce      json
+// @Param 		 user_id query string false "user ID"
+// @Param 		 email query string false "email the event is recorded for"
+// @Param 		 type query string false "event type" Enums(sign_up, sign_in, sign_in_second_factor, sign_in_external, token_refresh, password_change, password_reset)
+// @Param 		 outcome query string false "event outcome" Enums(success, failure, mfa_required)
+// @Param 		 ip query string false "client IP"
+// @Param 		 from query string false "RFC 3339 time, inclusive"
+// @Param 		 to query string false "RFC 3339 time, exclusive"
+// @Param 		 page query string false "pagination page number"
+// @Param 		 per-page query string false "pagination per page items number"
+// @Success      200 {object} messages.AuditEventListResponseBody
+// @Failure      400  {object}  messages.BadRequestError
+// @Failure      401  {object}  messages.UnauthenticatedError
+// @Failure      403  {object}  messages.ForbiddenError
+// @Failure      500  {object}  messages.InternalServerError
+// @Router       /admin/audit-events [get]
+func (a Admin) SearchAuditEvents(c *gin.Context) {
+	var req messages.AuditEventSearchRequestQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		resp.AbortWithError(c, ierr.WrapCode(ierr.InvalidArgument, err, "wrong audit event filter"))
+		return
+	}
+
+	pag, err := a.paginator.GetPagination(c)
+	if err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	actorID, err := a.identityExtractor.ExtractFromContext(c)
+	if err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	filter := domain.AuditEventFilter{
+		Email:      req.Email,
+		Type:       domain.AuditEventType(req.Type),
+		Outcome:    domain.AuditOutcome(req.Outcome),
+		ClientIP:   req.IP,
+		From:       req.From,
+		To:         req.To,
+		Pagination: pag,
+	}
+
+	if req.UserID != "" {
+		userID, err := uuid.Parse(req.UserID)
+		if err != nil {
+			resp.AbortWithError(c, ierr.WrapCode(ierr.InvalidArgument, err, "wrong user id"))
+			return
+		}
+
+		filter.UserID = &userID
+	}
+
+	events, err := a.adminUsecase.SearchAuditEvents(c, actorID, filter)
+	if err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, domainAuditEventsToMessage(events))
+}
+
+func domainAuditEventsToMessage(events []domain.AuditEvent) messages.AuditEventListResponseBody {
+	list := make(messages.AuditEventListResponseBody, 0, len(events))
+	for _, event := range events {
+		msg := messages.AuditEventResponseBody{
+			ID:        event.ID.String(),
+			Email:     event.Email,
+			Type:      event.Type.String(),
+			Outcome:   event.Outcome.String(),
+			Reason:    event.Reason,
+			ClientIP:  event.ClientIP,
+			UserAgent: event.UserAgent,
+			CreatedAt: event.CreatedAt,
+		}
+
+		if event.UserID != nil {
+			msg.UserID = event.UserID.String()
+		}
+
+		list = append(list, msg)
+	}
+
+	return list
+}
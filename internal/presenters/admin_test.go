@@ -0,0 +1,243 @@
+package presenters
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/internal/presenters/messages"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+	"github.com/valerii-smirnov/petli-test-task/pkg/token"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdmin_AssignRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	controller := gomock.NewController(t)
+	mockAdminUsecase := NewMockAdminUsecase(controller)
+	mockIdentityExtractor := NewMockIdentityExtractor(controller)
+
+	actorID := uuid.New()
+	userID := uuid.New()
+
+	getRequestFn := func(url string, body interface{}) *http.Request {
+		b, err := json.Marshal(body)
+		if err != nil {
+			assert.Error(t, err)
+		}
+
+		req, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(b))
+		if err != nil {
+			assert.Error(t, err)
+		}
+
+		return req
+	}
+
+	tests := []struct {
+		name              string
+		mocksInitFn       func()
+		getRequestFn      func() *http.Request
+		resultAssertionFn func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:        "wrong user id",
+			mocksInitFn: func() {},
+			getRequestFn: func() *http.Request {
+				return getRequestFn("/api/admin/users/wrong/role", messages.AssignRoleRequestBody{Role: "moderator"})
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:        "unknown role",
+			mocksInitFn: func() {},
+			getRequestFn: func() *http.Request {
+				return getRequestFn(fmt.Sprintf("/api/admin/users/%s/role", userID), messages.AssignRoleRequestBody{Role: "root"})
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "user not found",
+			mocksInitFn: func() {
+				mockIdentityExtractor.EXPECT().ExtractFromContext(gomock.Any()).Return(actorID, nil)
+				mockAdminUsecase.EXPECT().AssignRole(gomock.Any(), actorID, userID, domain.RoleModerator).
+					Return(ierr.New(ierr.NotFound, "user not found"))
+			},
+			getRequestFn: func() *http.Request {
+				return getRequestFn(fmt.Sprintf("/api/admin/users/%s/role", userID), messages.AssignRoleRequestBody{Role: "moderator"})
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "success",
+			mocksInitFn: func() {
+				mockIdentityExtractor.EXPECT().ExtractFromContext(gomock.Any()).Return(actorID, nil)
+				mockAdminUsecase.EXPECT().AssignRole(gomock.Any(), actorID, userID, domain.RoleModerator).Return(nil)
+			},
+			getRequestFn: func() *http.Request {
+				return getRequestFn(fmt.Sprintf("/api/admin/users/%s/role", userID), messages.AssignRoleRequestBody{Role: "moderator"})
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInitFn()
+
+			recorder := httptest.NewRecorder()
+			_, engine := gin.CreateTestContext(recorder)
+			engine = InitRoutes(engine, NewAdmin(mockAdminUsecase, mockIdentityExtractor, NewUrlPagination()))
+
+			req := tt.getRequestFn()
+			engine.ServeHTTP(recorder, req)
+			tt.resultAssertionFn(recorder)
+		})
+	}
+
+	t.Run("user without permission", func(t *testing.T) {
+		tokenProcessor := token.NewJWT(token.NewHMACKeySet("test-secret"), time.Minute*5)
+		authMiddleware := newTestAuthMiddleware(controller, tokenProcessor)
+
+		recorder := httptest.NewRecorder()
+		_, engine := gin.CreateTestContext(recorder)
+		engine = InitRoutes(engine, NewAdmin(
+			mockAdminUsecase,
+			mockIdentityExtractor,
+			NewUrlPagination(),
+			authMiddleware.Auth,
+			RequirePermission(domain.PermissionManageUsers),
+		))
+
+		st, err := tokenProcessor.Generate(actorID, domain.RoleModerator.String())
+		assert.NoError(t, err)
+
+		req := getRequestFn(fmt.Sprintf("/api/admin/users/%s/role", userID), messages.AssignRoleRequestBody{Role: "moderator"})
+		req.Header.Set(AuthorizationHeaderName, fmt.Sprintf("%s%s", bearerPrefix, st))
+
+		engine.ServeHTTP(recorder, req)
+		assert.Equal(t, http.StatusForbidden, recorder.Code)
+	})
+}
+
+func TestAdmin_SearchAuditEvents(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	controller := gomock.NewController(t)
+	mockAdminUsecase := NewMockAdminUsecase(controller)
+	mockIdentityExtractor := NewMockIdentityExtractor(controller)
+
+	actorID := uuid.New()
+	userID := uuid.New()
+	from := time.Date(2023, 2, 6, 10, 0, 0, 0, time.UTC)
+	event := domain.AuditEvent{
+		ID:        uuid.New(),
+		Type:      domain.AuditSignIn,
+		Outcome:   domain.AuditFailure,
+		Reason:    "user not found",
+		ClientIP:  "10.0.0.1",
+		CreatedAt: from,
+	}
+
+	tests := []struct {
+		name              string
+		mocksInitFn       func()
+		url               string
+		resultAssertionFn func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:        "unknown outcome",
+			mocksInitFn: func() {},
+			url:         "/api/admin/audit-events?outcome=maybe",
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:        "wrong from",
+			mocksInitFn: func() {},
+			url:         "/api/admin/audit-events?from=yesterday",
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "not permitted",
+			mocksInitFn: func() {
+				mockIdentityExtractor.EXPECT().ExtractFromContext(gomock.Any()).Return(actorID, nil)
+				mockAdminUsecase.EXPECT().SearchAuditEvents(gomock.Any(), actorID, gomock.Any()).
+					Return(nil, ierr.New(ierr.PermissionDenied, "you don't have permission to view audit log"))
+			},
+			url: "/api/admin/audit-events",
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "success",
+			mocksInitFn: func() {
+				mockIdentityExtractor.EXPECT().ExtractFromContext(gomock.Any()).Return(actorID, nil)
+				mockAdminUsecase.EXPECT().SearchAuditEvents(gomock.Any(), actorID, domain.AuditEventFilter{
+					UserID:     &userID,
+					Type:       domain.AuditSignIn,
+					Outcome:    domain.AuditFailure,
+					ClientIP:   "10.0.0.1",
+					From:       from,
+					Pagination: domain.Pagination{Page: 2, PerPage: 5},
+				}).Return([]domain.AuditEvent{event}, nil)
+			},
+			url: fmt.Sprintf(
+				"/api/admin/audit-events?user_id=%s&type=sign_in&outcome=failure&ip=10.0.0.1&from=2023-02-06T10:00:00Z&page=2&per-page=5",
+				userID,
+			),
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var body messages.AuditEventListResponseBody
+				assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+				assert.Equal(t, messages.AuditEventListResponseBody{{
+					ID:        event.ID.String(),
+					Type:      "sign_in",
+					Outcome:   "failure",
+					Reason:    "user not found",
+					ClientIP:  "10.0.0.1",
+					CreatedAt: from,
+				}}, body)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInitFn()
+
+			recorder := httptest.NewRecorder()
+			_, engine := gin.CreateTestContext(recorder)
+			engine = InitRoutes(engine, NewAdmin(mockAdminUsecase, mockIdentityExtractor, NewUrlPagination()))
+
+			req, err := http.NewRequest(http.MethodGet, tt.url, nil)
+			assert.NoError(t, err)
+
+			engine.ServeHTTP(recorder, req)
+			tt.resultAssertionFn(recorder)
+		})
+	}
+}
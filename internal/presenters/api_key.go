@@ -0,0 +1,175 @@
+package presenters
+
+import (
+	"net/http"
+
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/internal/presenters/messages"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+	"github.com/valerii-smirnov/petli-test-task/pkg/utils/gin/resp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// APIKey presenter of personal API keys management.
+type APIKey struct {
+	apiKeyUsecase     APIKeyUsecase
+	identityExtractor IdentityExtractor
+
+	middlewares []gin.HandlerFunc
+}
+
+func NewAPIKey(apiKeyUsecase APIKeyUsecase, identityExtractor IdentityExtractor, middlewares ...gin.HandlerFunc) *APIKey {
+	return &APIKey{
+		apiKeyUsecase:     apiKeyUsecase,
+		identityExtractor: identityExtractor,
+		middlewares:       middlewares,
+	}
+}
+
+func (a APIKey) Inject(r gin.IRouter) {
+	apiKeysGroup := r.Group("/api-keys")
+	if len(a.middlewares) > 0 {
+		apiKeysGroup.Use(a.middlewares...)
+	}
+
+	apiKeysGroup.POST("", a.Create)
+	apiKeysGroup.GET("", a.List)
+	apiKeysGroup.DELETE("/:id", a.Revoke)
+}
+
+// Create godoc
+// @Summary      API key creation
+// @Description  Creates personal API key, use it as "ApiKey <key>" in Authorization header. The key is shown only once.
+// @Description  Keys with read scope can make GET requests only, write scope is required for the others.
+// @ID 			 Create API key
+// @Tags         api-keys
+// @Security 	 ApiKeyAuth
+// @Accept       json
+// @Produce      json
+// @Param 		 input body messages.CreateAPIKeyRequestBody true "key name and scopes"
+// @Success      201 {object} messages.CreateAPIKeyResponseBody
+// @Failure      400  {object}  messages.BadRequestError
+// @Failure      401  {object}  messages.UnauthenticatedError
+// @Failure      403  {object}  messages.ForbiddenError
+// @Failure      500  {object}  messages.InternalServerError
+// @Router       /api-keys [post]
+func (a APIKey) Create(c *gin.Context) {
+	var req messages.CreateAPIKeyRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	uid, err := a.identityExtractor.ExtractFromContext(c)
+	if err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	in := domain.APIKeyCreate{
+		Name:   req.Name,
+		Scopes: make([]domain.APIKeyScope, 0, len(req.Scopes)),
+	}
+
+	for _, scope := range req.Scopes {
+		in.Scopes = append(in.Scopes, domain.APIKeyScope(scope))
+	}
+
+	created, err := a.apiKeyUsecase.Create(c, uid, in)
+	if err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, messages.CreateAPIKeyResponseBody{
+		APIKeyResponseBody: a.domainAPIKeyToMessage(created.APIKey),
+		Key:                created.Key,
+	})
+}
+
+// List godoc
+// @Summary      API keys list
+// @Description  Returns not revoked API keys of the user.
+// @ID 			 List API keys
+// @Tags         api-keys
+// @Security 	 ApiKeyAuth
+// @Produce      json
+// @Success      200 {object} messages.APIKeyListResponseBody
+// @Failure      401  {object}  messages.UnauthenticatedError
+// @Failure      403  {object}  messages.ForbiddenError
+// @Failure      500  {object}  messages.InternalServerError
+// @Router       /api-keys [get]
+func (a APIKey) List(c *gin.Context) {
+	uid, err := a.identityExtractor.ExtractFromContext(c)
+	if err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	keys, err := a.apiKeyUsecase.List(c, uid)
+	if err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	list := make(messages.APIKeyListResponseBody, 0, len(keys))
+	for _, key := range keys {
+		list = append(list, a.domainAPIKeyToMessage(key))
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+// Revoke godoc
+// @Summary      API key revocation
+// @Description  Revokes API key, requests made with it are rejected immediately.
+// @ID 			 Revoke API key
+// @Tags         api-keys
+// @Security 	 ApiKeyAuth
+// @Produce      json
+// @Param 		 id path string true "API key ID"
+// @Success      204
+// @Failure      400  {object}  messages.BadRequestError
+// @Failure      401  {object}  messages.UnauthenticatedError
+// @Failure      403  {object}  messages.ForbiddenError
+// @Failure      404  {object}  messages.NotFoundError
+// @Failure      500  {object}  messages.InternalServerError
+// @Router       /api-keys/{id} [delete]
+func (a APIKey) Revoke(c *gin.Context) {
+	keyID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		resp.AbortWithError(c, ierr.WrapCode(ierr.InvalidArgument, err, "wrong api key id"))
+		return
+	}
+
+	uid, err := a.identityExtractor.ExtractFromContext(c)
+	if err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	if err := a.apiKeyUsecase.Revoke(c, uid, keyID); err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	c.AbortWithStatus(http.StatusNoContent)
+}
+
+func (a APIKey) domainAPIKeyToMessage(key domain.APIKey) messages.APIKeyResponseBody {
+	scopes := make([]string, 0, len(key.Scopes))
+	for _, scope := range key.Scopes {
+		scopes = append(scopes, scope.String())
+	}
+
+	return messages.APIKeyResponseBody{
+		ID:         key.ID.String(),
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     scopes,
+		LastUsedAt: key.LastUsedAt,
+		CreatedAt:  key.CreatedAt,
+	}
+}
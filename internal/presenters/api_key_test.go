@@ -0,0 +1,165 @@
+package presenters
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/internal/presenters/messages"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	controller := gomock.NewController(t)
+	mockAPIKeyUsecase := NewMockAPIKeyUsecase(controller)
+	mockIdentityExtractor := NewMockIdentityExtractor(controller)
+
+	userID := uuid.New()
+	keyID := uuid.New()
+	createdAt := time.Now().UTC().Truncate(time.Second)
+
+	apiKey := domain.APIKey{
+		ID:        keyID,
+		UserID:    userID,
+		Name:      "ci",
+		Prefix:    "petly_abcdef",
+		Scopes:    []domain.APIKeyScope{domain.APIKeyScopeRead},
+		CreatedAt: createdAt,
+	}
+
+	getRequestFn := func(method, url string, body interface{}) *http.Request {
+		b, err := json.Marshal(body)
+		if err != nil {
+			assert.Error(t, err)
+		}
+
+		req, err := http.NewRequest(method, url, bytes.NewReader(b))
+		if err != nil {
+			assert.Error(t, err)
+		}
+
+		return req
+	}
+
+	tests := []struct {
+		name              string
+		mocksInitFn       func()
+		getRequestFn      func() *http.Request
+		resultAssertionFn func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:        "create with unknown scope",
+			mocksInitFn: func() {},
+			getRequestFn: func() *http.Request {
+				return getRequestFn(http.MethodPost, "/api/api-keys", messages.CreateAPIKeyRequestBody{Name: "ci", Scopes: []string{"admin"}})
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "create",
+			mocksInitFn: func() {
+				mockIdentityExtractor.EXPECT().ExtractFromContext(gomock.Any()).Return(userID, nil)
+				mockAPIKeyUsecase.EXPECT().Create(gomock.Any(), userID, domain.APIKeyCreate{
+					Name:   "ci",
+					Scopes: []domain.APIKeyScope{domain.APIKeyScopeRead},
+				}).Return(domain.CreatedAPIKey{APIKey: apiKey, Key: "petly_abcdefghijklmnop"}, nil)
+			},
+			getRequestFn: func() *http.Request {
+				return getRequestFn(http.MethodPost, "/api/api-keys", messages.CreateAPIKeyRequestBody{Name: "ci", Scopes: []string{"read"}})
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusCreated, recorder.Code)
+
+				var body messages.CreateAPIKeyResponseBody
+				if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+					assert.Error(t, err)
+				}
+
+				assert.Equal(t, "petly_abcdefghijklmnop", body.Key)
+				assert.Equal(t, keyID.String(), body.ID)
+				assert.Equal(t, []string{"read"}, body.Scopes)
+			},
+		},
+		{
+			name: "list",
+			mocksInitFn: func() {
+				mockIdentityExtractor.EXPECT().ExtractFromContext(gomock.Any()).Return(userID, nil)
+				mockAPIKeyUsecase.EXPECT().List(gomock.Any(), userID).Return([]domain.APIKey{apiKey}, nil)
+			},
+			getRequestFn: func() *http.Request {
+				return getRequestFn(http.MethodGet, "/api/api-keys", nil)
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var body messages.APIKeyListResponseBody
+				if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+					assert.Error(t, err)
+				}
+
+				assert.Equal(t, messages.APIKeyListResponseBody{{
+					ID:        keyID.String(),
+					Name:      "ci",
+					Prefix:    "petly_abcdef",
+					Scopes:    []string{"read"},
+					CreatedAt: createdAt,
+				}}, body)
+				assert.NotContains(t, recorder.Body.String(), "key_hash")
+			},
+		},
+		{
+			name: "revoke not found",
+			mocksInitFn: func() {
+				mockIdentityExtractor.EXPECT().ExtractFromContext(gomock.Any()).Return(userID, nil)
+				mockAPIKeyUsecase.EXPECT().Revoke(gomock.Any(), userID, keyID).Return(ierr.New(ierr.NotFound, "api key not found"))
+			},
+			getRequestFn: func() *http.Request {
+				return getRequestFn(http.MethodDelete, fmt.Sprintf("/api/api-keys/%s", keyID), nil)
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "revoke",
+			mocksInitFn: func() {
+				mockIdentityExtractor.EXPECT().ExtractFromContext(gomock.Any()).Return(userID, nil)
+				mockAPIKeyUsecase.EXPECT().Revoke(gomock.Any(), userID, keyID).Return(nil)
+			},
+			getRequestFn: func() *http.Request {
+				return getRequestFn(http.MethodDelete, fmt.Sprintf("/api/api-keys/%s", keyID), nil)
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInitFn()
+
+			recorder := httptest.NewRecorder()
+			_, engine := gin.CreateTestContext(recorder)
+			engine = InitRoutes(engine, NewAPIKey(mockAPIKeyUsecase, mockIdentityExtractor))
+
+			req := tt.getRequestFn()
+			engine.ServeHTTP(recorder, req)
+			tt.resultAssertionFn(recorder)
+		})
+	}
+}
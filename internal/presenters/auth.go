@@ -1,6 +1,7 @@
 package presenters
 
 import (
+	"io"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -12,13 +13,16 @@ import (
 type Auth struct {
 	authUsecase AuthUsecase
 
-	middlewares []gin.HandlerFunc
+	authMiddleware gin.HandlerFunc
+	middlewares    []gin.HandlerFunc
 }
 
-func NewAuth(authUsecase AuthUsecase, middlewares ...gin.HandlerFunc) *Auth {
+// NewAuth constructor. Auth middleware is applied only to endpoints which require authenticated user.
+func NewAuth(authUsecase AuthUsecase, authMiddleware gin.HandlerFunc, middlewares ...gin.HandlerFunc) *Auth {
 	return &Auth{
-		authUsecase: authUsecase,
-		middlewares: middlewares,
+		authUsecase:    authUsecase,
+		authMiddleware: authMiddleware,
+		middlewares:    middlewares,
 	}
 }
 
@@ -29,7 +33,11 @@ func (a Auth) Inject(r gin.IRouter) {
 	}
 
 	authGroup.POST("sign-in", a.SignIn)
+	authGroup.POST("2fa/verify", a.VerifySecondFactor)
 	authGroup.POST("sign-up", a.SignUp)
+	authGroup.POST("refresh", a.Refresh)
+	authGroup.POST("logout", a.authMiddleware, a.Logout)
+	authGroup.POST("logout-all", a.authMiddleware, a.LogoutAll)
 }
 
 // SignUp godoc
@@ -67,7 +75,8 @@ func (a Auth) SignUp(c *gin.Context) {
 
 // SignIn godoc
 // @Summary      User login
-// @Description  User login endpoint
+// @Description  User login endpoint. Too many failed attempts for the email or from the client ip lock sign-in temporarily.
+// @Description  If user has two-factor authentication enabled, only mfa token is returned, it has to be exchanged for tokens at /auth/2fa/verify.
 // @ID 			 Login user
 // @Tags         auth
 // @Accept       json
@@ -76,6 +85,8 @@ func (a Auth) SignUp(c *gin.Context) {
 // @Success      200 {object} messages.SignInResponseBody
 // @Failure      400  {object}  messages.BadRequestError
 // @Failure      404  {object}  messages.NotFoundError
+// @Failure      429  {object}  messages.TooManyRequestsError
+// @Header       429  {integer} Retry-After "seconds until sign-in is unlocked"
 // @Failure      500  {object}  messages.InternalServerError
 // @Router       /auth/sign-in [post]
 func (a Auth) SignIn(c *gin.Context) {
@@ -88,17 +99,152 @@ func (a Auth) SignIn(c *gin.Context) {
 	domainSignIn := domain.SingIn{
 		Email:    req.Email,
 		Password: req.Password,
+		ClientIP: c.ClientIP(),
 	}
 
-	token, err := a.authUsecase.SignIn(c, domainSignIn)
+	tokens, err := a.authUsecase.SignIn(c, domainSignIn)
 	if err != nil {
 		resp.AbortWithError(c, err)
 		return
 	}
 
-	rb := messages.SignInResponseBody{
-		Token: string(token),
+	c.JSON(http.StatusOK, domainTokensToMessage(tokens))
+}
+
+// VerifySecondFactor godoc
+// @Summary      Two-factor login
+// @Description  Second login step. Exchanges mfa token and either authenticator code or recovery code for a pair of tokens.
+// @ID 			 Login user second factor
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param 		 input body messages.SecondFactorSignInRequestBody true "mfa token and two-factor code"
+// @Success      200 {object} messages.SignInResponseBody
+// @Failure      400  {object}  messages.BadRequestError
+// @Failure      401  {object}  messages.UnauthenticatedError
+// @Failure      429  {object}  messages.TooManyRequestsError
+// @Header       429  {integer} Retry-After "seconds until sign-in is unlocked"
+// @Failure      500  {object}  messages.InternalServerError
+// @Router       /auth/2fa/verify [post]
+func (a Auth) VerifySecondFactor(c *gin.Context) {
+	var req messages.SecondFactorSignInRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	in := domain.SecondFactorSignIn{
+		MFAToken:     domain.Token(req.MFAToken),
+		Code:         req.Code,
+		RecoveryCode: req.RecoveryCode,
+		ClientIP:     c.ClientIP(),
+	}
+
+	tokens, err := a.authUsecase.SignInSecondFactor(c, in)
+	if err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, domainTokensToMessage(tokens))
+}
+
+// Refresh godoc
+// @Summary      Tokens refresh
+// @Description  Exchanges refresh token for a new pair of access and refresh tokens. Every refresh token can be used only once.
+// @ID 			 Refresh tokens
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param 		 input body messages.RefreshRequestBody true "refresh token"
+// @Success      200 {object} messages.SignInResponseBody
+// @Failure      400  {object}  messages.BadRequestError
+// @Failure      401  {object}  messages.UnauthenticatedError
+// @Failure      500  {object}  messages.InternalServerError
+// @Router       /auth/refresh [post]
+func (a Auth) Refresh(c *gin.Context) {
+	var req messages.RefreshRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	tokens, err := a.authUsecase.Refresh(c, domain.Token(req.RefreshToken))
+	if err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, domainTokensToMessage(tokens))
+}
+
+// Logout godoc
+// @Summary      User logout
+// @Description  Revokes access token request is made with. If refresh token is provided, it is revoked as well.
+// @ID 			 Logout user
+// @Tags         auth
+// @Security 	 ApiKeyAuth
+// @Accept       json
+// @Produce      json
+// @Param 		 input body messages.LogoutRequestBody false "refresh token to revoke"
+// @Success      204
+// @Failure      400  {object}  messages.BadRequestError
+// @Failure      401  {object}  messages.UnauthenticatedError
+// @Failure      500  {object}  messages.InternalServerError
+// @Router       /auth/logout [post]
+func (a Auth) Logout(c *gin.Context) {
+	var req messages.LogoutRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	claims, err := tokenClaimsFromContext(c)
+	if err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	if err := a.authUsecase.Logout(c, claims, domain.Token(req.RefreshToken)); err != nil {
+		resp.AbortWithError(c, err)
+		return
 	}
 
-	c.JSON(http.StatusOK, rb)
+	c.AbortWithStatus(http.StatusNoContent)
+}
+
+// LogoutAll godoc
+// @Summary      User logout from all devices
+// @Description  Revokes every access and refresh token issued to the user
+// @ID 			 Logout user everywhere
+// @Tags         auth
+// @Security 	 ApiKeyAuth
+// @Accept       json
+// @Produce      json
+// @Success      204
+// @Failure      401  {object}  messages.UnauthenticatedError
+// @Failure      500  {object}  messages.InternalServerError
+// @Router       /auth/logout-all [post]
+func (a Auth) LogoutAll(c *gin.Context) {
+	claims, err := tokenClaimsFromContext(c)
+	if err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	if err := a.authUsecase.LogoutAll(c, claims.UserID); err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	c.AbortWithStatus(http.StatusNoContent)
+}
+
+func domainTokensToMessage(tokens domain.Tokens) messages.SignInResponseBody {
+	return messages.SignInResponseBody{
+		Token:        string(tokens.Access),
+		RefreshToken: string(tokens.Refresh),
+		MFARequired:  tokens.MFA != "",
+		MFAToken:     string(tokens.MFA),
+	}
 }
@@ -6,14 +6,17 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/valerii-smirnov/petli-test-task/internal/domain"
 	"github.com/valerii-smirnov/petli-test-task/internal/presenters/messages"
 	httpErrors "github.com/valerii-smirnov/petli-test-task/pkg/errors/http"
 	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+	"github.com/valerii-smirnov/petli-test-task/pkg/token"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -22,6 +25,7 @@ func TestAuth_SignUp(t *testing.T) {
 
 	controller := gomock.NewController(t)
 	mockAuthUsecase := NewMockAuthUsecase(controller)
+	authMiddleware := newTestAuthMiddleware(controller, token.NewJWT(token.NewHMACKeySet("test-secret"), time.Minute*5))
 
 	email := "test@email.com"
 	password := "testhashedpassword"
@@ -39,7 +43,7 @@ func TestAuth_SignUp(t *testing.T) {
 		{
 			name: "request body validation error",
 			fields: fields{
-				NewAuth(mockAuthUsecase),
+				NewAuth(mockAuthUsecase, authMiddleware.Auth),
 			},
 			mocksInitFn: func() {},
 			getRequestFn: func() *http.Request {
@@ -67,7 +71,7 @@ func TestAuth_SignUp(t *testing.T) {
 		{
 			name: "usecase error",
 			fields: fields{
-				auth: NewAuth(mockAuthUsecase),
+				auth: NewAuth(mockAuthUsecase, authMiddleware.Auth),
 			},
 			mocksInitFn: func() {
 				up := domain.SignUp{
@@ -112,7 +116,7 @@ func TestAuth_SignUp(t *testing.T) {
 		{
 			name: "success",
 			fields: fields{
-				auth: NewAuth(mockAuthUsecase),
+				auth: NewAuth(mockAuthUsecase, authMiddleware.Auth),
 			},
 			mocksInitFn: func() {
 				up := domain.SignUp{
@@ -166,11 +170,15 @@ func TestAuth_SignIn(t *testing.T) {
 
 	controller := gomock.NewController(t)
 	mockAuthUsecase := NewMockAuthUsecase(controller)
+	authMiddleware := newTestAuthMiddleware(controller, token.NewJWT(token.NewHMACKeySet("test-secret"), time.Minute*5))
 
 	email := "test@email.com"
 	password := "testhashedpassword"
 
-	token := "jwttoken"
+	tokens := domain.Tokens{
+		Access:  "jwttoken",
+		Refresh: "refreshtoken",
+	}
 
 	type fields struct {
 		auth *Auth
@@ -185,7 +193,7 @@ func TestAuth_SignIn(t *testing.T) {
 		{
 			name: "request body validation error",
 			fields: fields{
-				auth: NewAuth(mockAuthUsecase),
+				auth: NewAuth(mockAuthUsecase, authMiddleware.Auth),
 			},
 			mocksInitFn: func() {},
 			getRequestFn: func() *http.Request {
@@ -214,7 +222,7 @@ func TestAuth_SignIn(t *testing.T) {
 		{
 			name: "usecase error",
 			fields: fields{
-				auth: NewAuth(mockAuthUsecase),
+				auth: NewAuth(mockAuthUsecase, authMiddleware.Auth),
 			},
 			mocksInitFn: func() {
 				si := domain.SingIn{
@@ -224,7 +232,7 @@ func TestAuth_SignIn(t *testing.T) {
 
 				err := ierr.New(ierr.Internal, "test-error")
 
-				mockAuthUsecase.EXPECT().SignIn(gomock.Any(), gomock.Eq(si)).Return(domain.Token(""), err)
+				mockAuthUsecase.EXPECT().SignIn(gomock.Any(), gomock.Eq(si)).Return(domain.Tokens{}, err)
 			},
 			getRequestFn: func() *http.Request {
 
@@ -249,10 +257,52 @@ func TestAuth_SignIn(t *testing.T) {
 				assert.Equal(t, http.StatusInternalServerError, recorder.Code)
 			},
 		},
+		{
+			name: "sign-in is locked",
+			fields: fields{
+				auth: NewAuth(mockAuthUsecase, authMiddleware.Auth),
+			},
+			mocksInitFn: func() {
+				si := domain.SingIn{
+					Email:    email,
+					Password: password,
+					ClientIP: "10.0.0.1",
+				}
+
+				err := ierr.New(ierr.ResourceExhausted, "too many failed sign-in attempts").
+					SetProps(ierr.KV{ierr.RetryAfterProp: "120"})
+
+				mockAuthUsecase.EXPECT().SignIn(gomock.Any(), gomock.Eq(si)).Return(domain.Tokens{}, err)
+			},
+			getRequestFn: func() *http.Request {
+				rb := messages.SignInRequestBody{
+					Email:    email,
+					Password: password,
+				}
+
+				b, err := json.Marshal(rb)
+				if err != nil {
+					assert.Error(t, err)
+				}
+
+				req, err := http.NewRequest(http.MethodPost, "/api/auth/sign-in", bytes.NewReader(b))
+				if err != nil {
+					assert.Error(t, err)
+				}
+
+				req.RemoteAddr = "10.0.0.1:54321"
+
+				return req
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+				assert.Equal(t, "120", recorder.Header().Get("Retry-After"))
+			},
+		},
 		{
 			name: "usecase error",
 			fields: fields{
-				auth: NewAuth(mockAuthUsecase),
+				auth: NewAuth(mockAuthUsecase, authMiddleware.Auth),
 			},
 			mocksInitFn: func() {
 				si := domain.SingIn{
@@ -260,7 +310,7 @@ func TestAuth_SignIn(t *testing.T) {
 					Password: password,
 				}
 
-				mockAuthUsecase.EXPECT().SignIn(gomock.Any(), gomock.Eq(si)).Return(domain.Token(token), nil)
+				mockAuthUsecase.EXPECT().SignIn(gomock.Any(), gomock.Eq(si)).Return(tokens, nil)
 			},
 			getRequestFn: func() *http.Request {
 
@@ -289,7 +339,39 @@ func TestAuth_SignIn(t *testing.T) {
 					assert.Error(t, err)
 				}
 
-				assert.Equal(t, token, si.Token)
+				assert.Equal(t, string(tokens.Access), si.Token)
+				assert.Equal(t, string(tokens.Refresh), si.RefreshToken)
+			},
+		},
+		{
+			name: "two-factor authentication is required",
+			fields: fields{
+				auth: NewAuth(mockAuthUsecase, authMiddleware.Auth),
+			},
+			mocksInitFn: func() {
+				si := domain.SingIn{
+					Email:    email,
+					Password: password,
+				}
+
+				mockAuthUsecase.EXPECT().SignIn(gomock.Any(), gomock.Eq(si)).Return(domain.Tokens{MFA: "mfatoken"}, nil)
+			},
+			getRequestFn: func() *http.Request {
+				b, err := json.Marshal(messages.SignInRequestBody{Email: email, Password: password})
+				if err != nil {
+					assert.Error(t, err)
+				}
+
+				req, err := http.NewRequest(http.MethodPost, "/api/auth/sign-in", bytes.NewReader(b))
+				if err != nil {
+					assert.Error(t, err)
+				}
+
+				return req
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.JSONEq(t, `{"mfa_required":true,"mfa_token":"mfatoken"}`, recorder.Body.String())
 			},
 		},
 	}
@@ -308,3 +390,319 @@ func TestAuth_SignIn(t *testing.T) {
 		})
 	}
 }
+
+func TestAuth_Refresh(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	controller := gomock.NewController(t)
+	mockAuthUsecase := NewMockAuthUsecase(controller)
+	authMiddleware := newTestAuthMiddleware(controller, token.NewJWT(token.NewHMACKeySet("test-secret"), time.Minute*5))
+
+	refreshToken := domain.Token("refreshtoken")
+
+	tokens := domain.Tokens{
+		Access:  "jwttoken",
+		Refresh: "newrefreshtoken",
+	}
+
+	getRequestFn := func(rb messages.RefreshRequestBody) *http.Request {
+		b, err := json.Marshal(rb)
+		if err != nil {
+			assert.Error(t, err)
+		}
+
+		req, err := http.NewRequest(http.MethodPost, "/api/auth/refresh", bytes.NewReader(b))
+		if err != nil {
+			assert.Error(t, err)
+		}
+
+		return req
+	}
+
+	type fields struct {
+		auth *Auth
+	}
+	tests := []struct {
+		name              string
+		fields            fields
+		mocksInitFn       func()
+		getRequestFn      func() *http.Request
+		resultAssertionFn func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "request body validation error",
+			fields: fields{
+				auth: NewAuth(mockAuthUsecase, authMiddleware.Auth),
+			},
+			mocksInitFn: func() {},
+			getRequestFn: func() *http.Request {
+				return getRequestFn(messages.RefreshRequestBody{})
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "invalid refresh token",
+			fields: fields{
+				auth: NewAuth(mockAuthUsecase, authMiddleware.Auth),
+			},
+			mocksInitFn: func() {
+				err := ierr.New(ierr.Unauthenticated, "test-error")
+				mockAuthUsecase.EXPECT().Refresh(gomock.Any(), gomock.Eq(refreshToken)).Return(domain.Tokens{}, err)
+			},
+			getRequestFn: func() *http.Request {
+				return getRequestFn(messages.RefreshRequestBody{RefreshToken: string(refreshToken)})
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "success",
+			fields: fields{
+				auth: NewAuth(mockAuthUsecase, authMiddleware.Auth),
+			},
+			mocksInitFn: func() {
+				mockAuthUsecase.EXPECT().Refresh(gomock.Any(), gomock.Eq(refreshToken)).Return(tokens, nil)
+			},
+			getRequestFn: func() *http.Request {
+				return getRequestFn(messages.RefreshRequestBody{RefreshToken: string(refreshToken)})
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var rb messages.SignInResponseBody
+				if err := json.Unmarshal(recorder.Body.Bytes(), &rb); err != nil {
+					assert.Error(t, err)
+				}
+
+				assert.Equal(t, string(tokens.Access), rb.Token)
+				assert.Equal(t, string(tokens.Refresh), rb.RefreshToken)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInitFn()
+
+			recorder := httptest.NewRecorder()
+			_, engine := gin.CreateTestContext(recorder)
+			engine = InitRoutes(engine, tt.fields.auth)
+
+			req := tt.getRequestFn()
+			engine.ServeHTTP(recorder, req)
+			tt.resultAssertionFn(recorder)
+		})
+	}
+}
+
+func TestAuth_Logout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	controller := gomock.NewController(t)
+	mockAuthUsecase := NewMockAuthUsecase(controller)
+	tokenProcessor := token.NewJWT(token.NewHMACKeySet("test-secret"), time.Minute*5)
+	authMiddleware := newTestAuthMiddleware(controller, tokenProcessor)
+
+	userID := uuid.New()
+	refreshToken := "refreshtoken"
+
+	claimsMatcher := tokenClaimsMatcher{userID: userID}
+
+	getRequestFn := func(path string, body []byte, authorized bool) *http.Request {
+		req, err := http.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+		if err != nil {
+			assert.Error(t, err)
+		}
+
+		if authorized {
+			st, err := tokenProcessor.Generate(userID, domain.RoleUser.String())
+			if err != nil {
+				assert.Error(t, err)
+			}
+
+			req.Header.Set(AuthorizationHeaderName, bearerPrefix+st)
+		}
+
+		return req
+	}
+
+	tests := []struct {
+		name              string
+		mocksInitFn       func()
+		getRequestFn      func() *http.Request
+		resultAssertionFn func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:        "logout without token",
+			mocksInitFn: func() {},
+			getRequestFn: func() *http.Request {
+				return getRequestFn("/api/auth/logout", nil, false)
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "logout without refresh token",
+			mocksInitFn: func() {
+				mockAuthUsecase.EXPECT().Logout(gomock.Any(), claimsMatcher, gomock.Eq(domain.Token(""))).Return(nil)
+			},
+			getRequestFn: func() *http.Request {
+				return getRequestFn("/api/auth/logout", nil, true)
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+		{
+			name: "logout usecase error",
+			mocksInitFn: func() {
+				err := ierr.New(ierr.Internal, "test-error")
+				mockAuthUsecase.EXPECT().Logout(gomock.Any(), claimsMatcher, gomock.Eq(domain.Token(refreshToken))).Return(err)
+			},
+			getRequestFn: func() *http.Request {
+				b, err := json.Marshal(messages.LogoutRequestBody{RefreshToken: refreshToken})
+				if err != nil {
+					assert.Error(t, err)
+				}
+
+				return getRequestFn("/api/auth/logout", b, true)
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name: "logout with refresh token",
+			mocksInitFn: func() {
+				mockAuthUsecase.EXPECT().Logout(gomock.Any(), claimsMatcher, gomock.Eq(domain.Token(refreshToken))).Return(nil)
+			},
+			getRequestFn: func() *http.Request {
+				b, err := json.Marshal(messages.LogoutRequestBody{RefreshToken: refreshToken})
+				if err != nil {
+					assert.Error(t, err)
+				}
+
+				return getRequestFn("/api/auth/logout", b, true)
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+		{
+			name: "logout everywhere",
+			mocksInitFn: func() {
+				mockAuthUsecase.EXPECT().LogoutAll(gomock.Any(), gomock.Eq(userID)).Return(nil)
+			},
+			getRequestFn: func() *http.Request {
+				return getRequestFn("/api/auth/logout-all", nil, true)
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInitFn()
+
+			recorder := httptest.NewRecorder()
+			_, engine := gin.CreateTestContext(recorder)
+			engine = InitRoutes(engine, NewAuth(mockAuthUsecase, authMiddleware.Auth))
+
+			req := tt.getRequestFn()
+			engine.ServeHTTP(recorder, req)
+			tt.resultAssertionFn(recorder)
+		})
+	}
+}
+
+func TestAuth_VerifySecondFactor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	controller := gomock.NewController(t)
+	mockAuthUsecase := NewMockAuthUsecase(controller)
+	authMiddleware := newTestAuthMiddleware(controller, token.NewJWT(token.NewHMACKeySet("test-secret"), time.Minute*5))
+
+	in := domain.SecondFactorSignIn{
+		MFAToken: "mfatoken",
+		Code:     "123456",
+	}
+
+	getRequestFn := func(body interface{}) *http.Request {
+		b, err := json.Marshal(body)
+		if err != nil {
+			assert.Error(t, err)
+		}
+
+		req, err := http.NewRequest(http.MethodPost, "/api/auth/2fa/verify", bytes.NewReader(b))
+		if err != nil {
+			assert.Error(t, err)
+		}
+
+		return req
+	}
+
+	tests := []struct {
+		name              string
+		mocksInitFn       func()
+		getRequestFn      func() *http.Request
+		resultAssertionFn func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:        "neither code nor recovery code provided",
+			mocksInitFn: func() {},
+			getRequestFn: func() *http.Request {
+				return getRequestFn(messages.SecondFactorSignInRequestBody{MFAToken: "mfatoken"})
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "invalid code",
+			mocksInitFn: func() {
+				mockAuthUsecase.EXPECT().SignInSecondFactor(gomock.Any(), gomock.Eq(in)).
+					Return(domain.Tokens{}, ierr.New(ierr.Unauthenticated, "invalid two-factor code"))
+			},
+			getRequestFn: func() *http.Request {
+				return getRequestFn(messages.SecondFactorSignInRequestBody{MFAToken: "mfatoken", Code: "123456"})
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "success",
+			mocksInitFn: func() {
+				mockAuthUsecase.EXPECT().SignInSecondFactor(gomock.Any(), gomock.Eq(in)).
+					Return(domain.Tokens{Access: "jwttoken", Refresh: "refreshtoken"}, nil)
+			},
+			getRequestFn: func() *http.Request {
+				return getRequestFn(messages.SecondFactorSignInRequestBody{MFAToken: "mfatoken", Code: "123456"})
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.JSONEq(t, `{"token":"jwttoken","refresh_token":"refreshtoken","mfa_required":false}`, recorder.Body.String())
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInitFn()
+
+			recorder := httptest.NewRecorder()
+			_, engine := gin.CreateTestContext(recorder)
+			engine = InitRoutes(engine, NewAuth(mockAuthUsecase, authMiddleware.Auth))
+
+			req := tt.getRequestFn()
+			engine.ServeHTTP(recorder, req)
+			tt.resultAssertionFn(recorder)
+		})
+	}
+}
@@ -2,9 +2,12 @@ package presenters
 
 import (
 	"context"
+	"time"
+
 	"github.com/gin-gonic/gin"
 
 	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/pkg/token"
 
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/google/uuid"
@@ -14,23 +17,105 @@ import (
 
 type AuthUsecase interface {
 	SignUp(ctx context.Context, su domain.SignUp) error
-	SignIn(ctx context.Context, si domain.SingIn) (domain.Token, error)
+	SignIn(ctx context.Context, si domain.SingIn) (domain.Tokens, error)
+	SignInSecondFactor(ctx context.Context, in domain.SecondFactorSignIn) (domain.Tokens, error)
+	Refresh(ctx context.Context, refreshToken domain.Token) (domain.Tokens, error)
+	Logout(ctx context.Context, claims domain.TokenClaims, refreshToken domain.Token) error
+	LogoutAll(ctx context.Context, userID uuid.UUID) error
+}
+
+type TwoFactorUsecase interface {
+	Enroll(ctx context.Context, userID uuid.UUID) (domain.TOTPEnrollment, error)
+	Confirm(ctx context.Context, userID uuid.UUID, code string) error
+	Disable(ctx context.Context, userID uuid.UUID, code string) error
+}
+
+type OIDCUsecase interface {
+	Begin(ctx context.Context) (domain.OIDCLogin, error)
+	Complete(ctx context.Context, in domain.OIDCCallback) (domain.Tokens, error)
+}
+
+type EmailVerificationUsecase interface {
+	Verify(ctx context.Context, token string) error
+	Resend(ctx context.Context, email string) error
+}
+
+type PasswordResetUsecase interface {
+	Request(ctx context.Context, email string) error
+	Confirm(ctx context.Context, in domain.PasswordResetConfirm) error
+}
+
+type UserUsecase interface {
+	Get(ctx context.Context, userID uuid.UUID) (domain.User, error)
+	UpdateProfile(ctx context.Context, userID uuid.UUID, profile domain.Profile) (domain.User, error)
+	ChangePassword(ctx context.Context, userID uuid.UUID, in domain.PasswordChange) error
+	RequestEmailChange(ctx context.Context, userID uuid.UUID, in domain.EmailChange) error
+	ConfirmEmailChange(ctx context.Context, token string) error
+	ScheduleDeletion(ctx context.Context, userID uuid.UUID, in domain.AccountDeletion) (time.Time, error)
+	CancelDeletion(ctx context.Context, userID uuid.UUID) error
+	SecurityEvents(ctx context.Context, userID uuid.UUID, pagination domain.Pagination) ([]domain.AuditEvent, error)
+}
+
+type DataExportUsecase interface {
+	Export(ctx context.Context, userID uuid.UUID) (domain.ExportResult, error)
+	Get(ctx context.Context, userID, exportID uuid.UUID) (domain.DataExport, error)
+	Archive(ctx context.Context, userID, exportID uuid.UUID) ([]byte, error)
+}
+
+type APIKeyUsecase interface {
+	Create(ctx context.Context, userID uuid.UUID, in domain.APIKeyCreate) (domain.CreatedAPIKey, error)
+	List(ctx context.Context, userID uuid.UUID) ([]domain.APIKey, error)
+	Revoke(ctx context.Context, userID, keyID uuid.UUID) error
+}
+
+type AdminUsecase interface {
+	AssignRole(ctx context.Context, actorID, userID uuid.UUID, role domain.Role) error
+	SearchAuditEvents(ctx context.Context, actorID uuid.UUID, filter domain.AuditEventFilter) ([]domain.AuditEvent, error)
+}
+
+type ModerationUsecase interface {
+	ListFlaggedPhotos(ctx context.Context, actorID uuid.UUID, filter domain.FlaggedPhotoFilter) ([]domain.FlaggedPhoto, error)
+	ApprovePhoto(ctx context.Context, actorID, photoID uuid.UUID) (domain.FlaggedPhoto, error)
+	RejectPhoto(ctx context.Context, actorID, photoID uuid.UUID) (domain.FlaggedPhoto, error)
 }
 
 type DogUsecase interface {
-	List(ctx context.Context, userID uuid.UUID, pagination domain.Pagination) (domain.DogList, error)
+	List(ctx context.Context, userID uuid.UUID, filter domain.DogFilter, pagination domain.Pagination) (domain.DogPage, error)
+	Feed(ctx context.Context, userID, dogID uuid.UUID, filter domain.DogFilter, pagination domain.Pagination) (domain.DogPage, error)
 	Get(ctx context.Context, dogID uuid.UUID) (domain.Dog, error)
-	Matches(ctx context.Context, userID, dogID uuid.UUID, pagination domain.Pagination) (domain.DogList, error)
+	Matches(ctx context.Context, userID, dogID uuid.UUID, pagination domain.Pagination) (domain.DogPage, error)
+	Preferences(ctx context.Context, userID, dogID uuid.UUID) (domain.DogPreferences, error)
+	UpdatePreferences(ctx context.Context, userID, dogID uuid.UUID, prefs domain.DogPreferences) (domain.DogPreferences, error)
 	Create(ctx context.Context, dog domain.Dog) (domain.Dog, error)
 	Update(ctx context.Context, dogID uuid.UUID, dog domain.Dog) (domain.Dog, error)
+	UploadPhoto(ctx context.Context, userID, dogID uuid.UUID, image domain.ImageUpload) (domain.PhotoUpload, error)
+	DeletePhoto(ctx context.Context, userID, dogID, photoID uuid.UUID) (domain.Dog, error)
+	ReorderPhotos(ctx context.Context, userID, dogID uuid.UUID, photoIDs []uuid.UUID) (domain.Dog, error)
+	SetPrimaryPhoto(ctx context.Context, userID, dogID, photoID uuid.UUID) (domain.Dog, error)
 	Delete(ctx context.Context, dogID uuid.UUID, userID uuid.UUID) error
 	AddReaction(ctx context.Context, userID uuid.UUID, reaction domain.Reaction) error
 }
 
+type RecommendationUsecase interface {
+	Recommend(ctx context.Context, userID, dogID uuid.UUID, pagination domain.Pagination) ([]domain.Recommendation, error)
+}
+
 type TokenParser interface {
 	Parse(token string) (*jwt.Token, error)
 }
 
+type KeysPublisher interface {
+	JWKS() []token.JWK
+}
+
+type TokenRevocationChecker interface {
+	IsRevoked(ctx context.Context, claims domain.TokenClaims) (bool, error)
+}
+
+type APIKeyAuthenticator interface {
+	Authenticate(ctx context.Context, key string) (domain.APIKey, error)
+}
+
 type IdentityExtractor interface {
 	ExtractFromContext(c *gin.Context) (uuid.UUID, error)
 }
@@ -38,3 +123,7 @@ type IdentityExtractor interface {
 type Paginator interface {
 	GetPagination(c *gin.Context) (domain.Pagination, error)
 }
+
+type DogFilterParser interface {
+	GetDogFilter(c *gin.Context) (domain.DogFilter, error)
+}
@@ -0,0 +1,167 @@
+package presenters
+
+import (
+	"net/http"
+
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/internal/presenters/messages"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+	"github.com/valerii-smirnov/petli-test-task/pkg/utils/gin/resp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	exportArchiveContentType = "application/zip"
+	exportArchiveDisposition = `attachment; filename="petly-export.zip"`
+)
+
+// DataExport presenter of the signed-in user data export.
+type DataExport struct {
+	dataExportUsecase DataExportUsecase
+	identityExtractor IdentityExtractor
+
+	middlewares []gin.HandlerFunc
+}
+
+func NewDataExport(dataExportUsecase DataExportUsecase, identityExtractor IdentityExtractor, middlewares ...gin.HandlerFunc) *DataExport {
+	return &DataExport{
+		dataExportUsecase: dataExportUsecase,
+		identityExtractor: identityExtractor,
+		middlewares:       middlewares,
+	}
+}
+
+func (d DataExport) Inject(r gin.IRouter) {
+	exportGroup := r.Group("/me/export")
+	if len(d.middlewares) > 0 {
+		exportGroup.Use(d.middlewares...)
+	}
+
+	exportGroup.GET("", d.Export)
+	exportGroup.GET("/:id", d.Get)
+	exportGroup.GET("/:id/archive", d.Archive)
+}
+
+// Export godoc
+// @Summary      Data export
+// @Description  Returns ZIP archive with JSON files of the account, dogs, reactions and matches of the signed-in user.
+// @Description  Large exports are built in background: 202 is returned with the export job, which is polled at Location.
+// @ID 			 Export data
+// @Tags         me
+// @Security 	 ApiKeyAuth
+// @Produce      application/zip,json
+// @Success      200 {file} file
+// @Success      202 {object} messages.DataExportResponseBody
+// @Failure      401  {object}  messages.UnauthenticatedError
+// @Failure      500  {object}  messages.InternalServerError
+// @Router       /me/export [get]
+func (d DataExport) Export(c *gin.Context) {
+	uid, err := d.identityExtractor.ExtractFromContext(c)
+	if err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	result, err := d.dataExportUsecase.Export(c, uid)
+	if err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	if result.Job == nil {
+		d.writeArchive(c, result.Archive)
+		return
+	}
+
+	c.Header("Location", c.FullPath()+"/"+result.Job.ID.String())
+	c.JSON(http.StatusAccepted, domainDataExportToMessage(*result.Job))
+}
+
+// Get godoc
+// @Summary      Data export status
+// @Description  Returns status of the data export job
+// @ID 			 Get data export
+// @Tags         me
+// @Security 	 ApiKeyAuth
+// @Produce      json
+// @Param 		 id path string true "export id"
+// @Success      200 {object} messages.DataExportResponseBody
+// @Failure      400  {object}  messages.BadRequestError
+// @Failure      401  {object}  messages.UnauthenticatedError
+// @Failure      404  {object}  messages.NotFoundError
+// @Failure      500  {object}  messages.InternalServerError
+// @Router       /me/export/{id} [get]
+func (d DataExport) Get(c *gin.Context) {
+	exportID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		resp.AbortWithError(c, ierr.WrapCode(ierr.InvalidArgument, err, "wrong export id"))
+		return
+	}
+
+	uid, err := d.identityExtractor.ExtractFromContext(c)
+	if err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	export, err := d.dataExportUsecase.Get(c, uid, exportID)
+	if err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, domainDataExportToMessage(export))
+}
+
+// Archive godoc
+// @Summary      Data export archive
+// @Description  Downloads archive of the ready data export job
+// @ID 			 Download data export
+// @Tags         me
+// @Security 	 ApiKeyAuth
+// @Produce      application/zip,json
+// @Param 		 id path string true "export id"
+// @Success      200 {file} file
+// @Failure      400  {object}  messages.BadRequestError
+// @Failure      401  {object}  messages.UnauthenticatedError
+// @Failure      404  {object}  messages.NotFoundError
+// @Failure      500  {object}  messages.InternalServerError
+// @Router       /me/export/{id}/archive [get]
+func (d DataExport) Archive(c *gin.Context) {
+	exportID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		resp.AbortWithError(c, ierr.WrapCode(ierr.InvalidArgument, err, "wrong export id"))
+		return
+	}
+
+	uid, err := d.identityExtractor.ExtractFromContext(c)
+	if err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	archive, err := d.dataExportUsecase.Archive(c, uid, exportID)
+	if err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	d.writeArchive(c, archive)
+}
+
+func (d DataExport) writeArchive(c *gin.Context, archive []byte) {
+	c.Header("Content-Disposition", exportArchiveDisposition)
+	c.Data(http.StatusOK, exportArchiveContentType, archive)
+}
+
+func domainDataExportToMessage(export domain.DataExport) messages.DataExportResponseBody {
+	return messages.DataExportResponseBody{
+		ID:          export.ID.String(),
+		Status:      export.Status.String(),
+		CreatedAt:   export.CreatedAt,
+		CompletedAt: export.CompletedAt,
+		ExpiresAt:   export.ExpiresAt,
+	}
+}
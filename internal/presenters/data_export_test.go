@@ -0,0 +1,140 @@
+package presenters
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/internal/presenters/messages"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDataExport(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	controller := gomock.NewController(t)
+	mockDataExportUsecase := NewMockDataExportUsecase(controller)
+	mockIdentityExtractor := NewMockIdentityExtractor(controller)
+
+	userID := uuid.New()
+	job := domain.DataExport{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Status:    domain.DataExportPending,
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+	}
+
+	tests := []struct {
+		name              string
+		mocksInitFn       func()
+		url               string
+		resultAssertionFn func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "archive is returned right away",
+			mocksInitFn: func() {
+				mockIdentityExtractor.EXPECT().ExtractFromContext(gomock.Any()).Return(userID, nil)
+				mockDataExportUsecase.EXPECT().Export(gomock.Any(), userID).Return(domain.ExportResult{Archive: []byte("archive")}, nil)
+			},
+			url: "/api/me/export",
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Equal(t, "application/zip", recorder.Header().Get("Content-Type"))
+				assert.Contains(t, recorder.Header().Get("Content-Disposition"), "attachment")
+				assert.Equal(t, "archive", recorder.Body.String())
+			},
+		},
+		{
+			name: "job is started",
+			mocksInitFn: func() {
+				mockIdentityExtractor.EXPECT().ExtractFromContext(gomock.Any()).Return(userID, nil)
+				mockDataExportUsecase.EXPECT().Export(gomock.Any(), userID).Return(domain.ExportResult{Job: &job}, nil)
+			},
+			url: "/api/me/export",
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusAccepted, recorder.Code)
+				assert.Equal(t, "/api/me/export/"+job.ID.String(), recorder.Header().Get("Location"))
+
+				var body messages.DataExportResponseBody
+				if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+					assert.Error(t, err)
+				}
+
+				assert.Equal(t, messages.DataExportResponseBody{
+					ID:        job.ID.String(),
+					Status:    "pending",
+					CreatedAt: job.CreatedAt,
+				}, body)
+			},
+		},
+		{
+			name:        "wrong export id",
+			mocksInitFn: func() {},
+			url:         "/api/me/export/wrong",
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "job status",
+			mocksInitFn: func() {
+				mockIdentityExtractor.EXPECT().ExtractFromContext(gomock.Any()).Return(userID, nil)
+				mockDataExportUsecase.EXPECT().Get(gomock.Any(), userID, job.ID).Return(job, nil)
+			},
+			url: "/api/me/export/" + job.ID.String(),
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "archive of not ready job",
+			mocksInitFn: func() {
+				mockIdentityExtractor.EXPECT().ExtractFromContext(gomock.Any()).Return(userID, nil)
+				mockDataExportUsecase.EXPECT().Archive(gomock.Any(), userID, job.ID).
+					Return(nil, ierr.New(ierr.InvalidArgument, "data export is pending"))
+			},
+			url: "/api/me/export/" + job.ID.String() + "/archive",
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "archive",
+			mocksInitFn: func() {
+				mockIdentityExtractor.EXPECT().ExtractFromContext(gomock.Any()).Return(userID, nil)
+				mockDataExportUsecase.EXPECT().Archive(gomock.Any(), userID, job.ID).Return([]byte("archive"), nil)
+			},
+			url: "/api/me/export/" + job.ID.String() + "/archive",
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Equal(t, "archive", recorder.Body.String())
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInitFn()
+
+			recorder := httptest.NewRecorder()
+			_, engine := gin.CreateTestContext(recorder)
+			engine = InitRoutes(engine, NewDataExport(mockDataExportUsecase, mockIdentityExtractor))
+
+			req, err := http.NewRequest(http.MethodGet, tt.url, nil)
+			if err != nil {
+				assert.Error(t, err)
+			}
+
+			engine.ServeHTTP(recorder, req)
+			tt.resultAssertionFn(recorder)
+		})
+	}
+}
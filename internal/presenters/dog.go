@@ -1,6 +1,8 @@
 package presenters
 
 import (
+	"context"
+	"io"
 	"net/http"
 
 	"github.com/valerii-smirnov/petli-test-task/internal/domain"
@@ -12,26 +14,42 @@ import (
 	"github.com/google/uuid"
 )
 
+const (
+	imageFormFieldName = "image"
+	// multipartOverhead size of multipart headers and boundaries allowed on top of uploaded image.
+	multipartOverhead = 64 << 10
+	// contentSniffLen bytes content type is detected by.
+	contentSniffLen = 512
+	// imageURLRejected message for requests still setting image by URL, such images can't be checked for duplicates.
+	imageURLRejected = "image urls aren't accepted, upload photos with POST /api/dog/{id}/photos"
+)
+
 // Dog presenter.
 type Dog struct {
 	dogUsecase        DogUsecase
 	identityExtractor IdentityExtractor
 	paginator         Paginator
+	dogFilterParser   DogFilterParser
+	maxImageSize      int64
 
 	middlewares []gin.HandlerFunc
 }
 
-// NewDog constructor.
+// NewDog constructor. Requests uploading images larger than maxImageSize are cut off before being read whole.
 func NewDog(
 	dogUsecase DogUsecase,
 	identityExtractor IdentityExtractor,
 	paginator Paginator,
+	dogFilterParser DogFilterParser,
+	maxImageSize int64,
 	middlewares ...gin.HandlerFunc,
 ) *Dog {
 	return &Dog{
 		dogUsecase:        dogUsecase,
 		identityExtractor: identityExtractor,
 		paginator:         paginator,
+		dogFilterParser:   dogFilterParser,
+		maxImageSize:      maxImageSize,
 		middlewares:       middlewares,
 	}
 }
@@ -45,23 +63,39 @@ func (d Dog) Inject(r gin.IRouter) {
 
 	dogsGroup.GET("", d.List)
 	dogsGroup.GET("/:id", d.Get)
+	dogsGroup.GET("/:id/feed", d.Feed)
 	dogsGroup.GET("/:id/matches", d.Matches)
+	dogsGroup.GET("/:id/preferences", d.Preferences)
+	dogsGroup.PUT("/:id/preferences", d.UpdatePreferences)
 	dogsGroup.POST("", d.Create)
 	dogsGroup.PUT("/:id", d.Update)
+	dogsGroup.POST("/:id/photos", d.UploadPhoto)
+	dogsGroup.PUT("/:id/photos", d.ReorderPhotos)
+	dogsGroup.DELETE("/:id/photos/:photoId", d.DeletePhoto)
+	dogsGroup.PUT("/:id/photos/:photoId/primary", d.SetPrimaryPhoto)
 	dogsGroup.DELETE("/:id", d.Delete)
 	dogsGroup.POST("/reaction", d.Reaction)
 }
 
 // List http handler func to retrieve list of dogs.
 // @Summary      Dogs list
-// @Description  Getting dogs list
+// @Description  Getting dogs list of other users, filtered and sorted, the newest first by default.
 // @Tags         dogs
 // @Security 	 ApiKeyAuth
 // @Accept       json
 // @Produce      json
+// @Param 		 sex query string false "dog sex" Enums(male, female)
+// @Param 		 min-age query int false "min dog age, inclusive" minimum(0)
+// @Param 		 max-age query int false "max dog age, inclusive" minimum(0)
+// @Param 		 breed query []string false "dog breed, case-insensitive, repeat the param to match any of several breeds" collectionFormat(multi)
+// @Param 		 created-after query string false "RFC 3339 time, dogs created after it are returned"
+// @Param 		 near query string false "latitude,longitude dogs are searched near, dogs get rounded distance to it"
+// @Param 		 radius-km query number false "max distance km to the near point"
+// @Param 		 sort query string false "sort order, created-desc by default, distance-asc needs near" Enums(created-desc, created-asc, age-asc, age-desc, name-asc, name-desc, distance-asc)
 // @Param 		 page query string false "pagination page number"
 // @Param 		 per-page query string false "pagination per page items number"
-// @Success      200 {object} messages.DogListResponseBody
+// @Param 		 cursor query string false "next_cursor of the previous page, replaces page number, only for lists sorted by creation time"
+// @Success      200 {object} messages.DogPageResponseBody
 // @Failure      400  {object}  messages.BadRequestError
 // @Failure      500  {object}  messages.InternalServerError
 // @Router       /dog [get]
@@ -78,13 +112,19 @@ func (d Dog) List(c *gin.Context) {
 		return
 	}
 
-	list, err := d.dogUsecase.List(c, uid, pag)
+	filter, err := d.dogFilterParser.GetDogFilter(c)
 	if err != nil {
 		resp.AbortWithError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, d.domainDogListToMessageList(list))
+	page, err := d.dogUsecase.List(c, uid, filter, pag)
+	if err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, domainDogPageToMessage(page))
 }
 
 // Get http handler func to get dog by ID.
@@ -113,7 +153,66 @@ func (d Dog) Get(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, d.domainDogToMessage(dog))
+	c.JSON(http.StatusOK, domainDogToMessage(dog))
+}
+
+// Feed http handler func to get dogs the provided dog can react to.
+// @Summary      Dog feed
+// @Description  Getting dogs of other users the dog hasn't liked or disliked yet, filtered and sorted like dogs list.
+// @Tags         dogs
+// @Security 	 ApiKeyAuth
+// @Accept       json
+// @Produce      json
+// @Param 		 id path string true "dog ID"
+// @Param 		 sex query string false "dog sex" Enums(male, female)
+// @Param 		 min-age query int false "min dog age, inclusive" minimum(0)
+// @Param 		 max-age query int false "max dog age, inclusive" minimum(0)
+// @Param 		 breed query []string false "dog breed, case-insensitive, repeat the param to match any of several breeds" collectionFormat(multi)
+// @Param 		 created-after query string false "RFC 3339 time, dogs created after it are returned"
+// @Param 		 near query string false "latitude,longitude dogs are searched near, dogs get rounded distance to it"
+// @Param 		 radius-km query number false "max distance km to the near point"
+// @Param 		 sort query string false "sort order, created-desc by default, distance-asc needs near" Enums(created-desc, created-asc, age-asc, age-desc, name-asc, name-desc, distance-asc)
+// @Param 		 page query string false "pagination page number"
+// @Param 		 per-page query string false "pagination per page items number"
+// @Param 		 cursor query string false "next_cursor of the previous page, replaces page number, only for lists sorted by creation time"
+// @Success      200 {object} messages.DogPageResponseBody
+// @Failure      400  {object}  messages.BadRequestError
+// @Failure      403  {object}  messages.ForbiddenError
+// @Failure      404  {object}  messages.NotFoundError
+// @Failure      500  {object}  messages.InternalServerError
+// @Router       /dog/{id}/feed [get]
+func (d Dog) Feed(c *gin.Context) {
+	userUid, err := d.identityExtractor.ExtractFromContext(c)
+	if err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	dogUid, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		resp.AbortWithError(c, ierr.WrapCode(ierr.InvalidArgument, err, "wrong dog id param"))
+		return
+	}
+
+	pag, err := d.paginator.GetPagination(c)
+	if err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	filter, err := d.dogFilterParser.GetDogFilter(c)
+	if err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	page, err := d.dogUsecase.Feed(c, userUid, dogUid, filter, pag)
+	if err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, domainDogPageToMessage(page))
 }
 
 // Matches http handler func to get all matches for provided dog.
@@ -126,7 +225,8 @@ func (d Dog) Get(c *gin.Context) {
 // @Param 		 id path string true "dog ID"
 // @Param 		 page query string false "pagination page number"
 // @Param 		 per-page query string false "pagination per page items number"
-// @Success      200 {object} messages.DogListResponseBody
+// @Param 		 cursor query string false "next_cursor of the previous page, replaces page number"
+// @Success      200 {object} messages.DogPageResponseBody
 // @Failure      400  {object}  messages.BadRequestError
 // @Failure      404  {object}  messages.NotFoundError
 // @Failure      500  {object}  messages.InternalServerError
@@ -135,6 +235,7 @@ func (d Dog) Matches(c *gin.Context) {
 	pag, err := d.paginator.GetPagination(c)
 	if err != nil {
 		resp.AbortWithError(c, err)
+		return
 	}
 
 	dogUid, err := uuid.Parse(c.Param("id"))
@@ -149,18 +250,103 @@ func (d Dog) Matches(c *gin.Context) {
 		return
 	}
 
-	list, err := d.dogUsecase.Matches(c, userUid, dogUid, pag)
+	page, err := d.dogUsecase.Matches(c, userUid, dogUid, pag)
 	if err != nil {
 		resp.AbortWithError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, d.domainDogListToMessageList(list))
+	c.JSON(http.StatusOK, domainDogPageToMessage(page))
+}
+
+// Preferences http handler func to get what the dog is looking for.
+// @Summary      Dog preferences
+// @Description  Getting what the dog is looking for, the feed of the dog shows only dogs fitting the preferences and whose preferences the dog fits.
+// @Tags         dogs
+// @Security 	 ApiKeyAuth
+// @Accept       json
+// @Produce      json
+// @Param 		 id path string true "dog ID"
+// @Success      200 {object} messages.DogPreferencesResponseBody
+// @Failure      400  {object}  messages.BadRequestError
+// @Failure      403  {object}  messages.ForbiddenError
+// @Failure      404  {object}  messages.NotFoundError
+// @Failure      500  {object}  messages.InternalServerError
+// @Router       /dog/{id}/preferences [get]
+func (d Dog) Preferences(c *gin.Context) {
+	userUid, err := d.identityExtractor.ExtractFromContext(c)
+	if err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	dogUid, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		resp.AbortWithError(c, ierr.WrapCode(ierr.InvalidArgument, err, "wrong dog id param"))
+		return
+	}
+
+	prefs, err := d.dogUsecase.Preferences(c, userUid, dogUid)
+	if err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, domainPreferencesToMessage(prefs))
+}
+
+// UpdatePreferences http handler func to replace what the dog is looking for.
+// @Summary      Update dog preferences
+// @Description  Replaces what the dog is looking for, omitted fields match any dog. Max distance needs location of the dog.
+// @Tags         dogs
+// @Security 	 ApiKeyAuth
+// @Accept       json
+// @Produce      json
+// @Param 		 id path string true "dog ID"
+// @Param 		 input body messages.DogPreferencesRequestBody true "dog preferences body"
+// @Success      200 {object} messages.DogPreferencesResponseBody
+// @Failure      400  {object}  messages.BadRequestError
+// @Failure      403  {object}  messages.ForbiddenError
+// @Failure      404  {object}  messages.NotFoundError
+// @Failure      500  {object}  messages.InternalServerError
+// @Router       /dog/{id}/preferences [put]
+func (d Dog) UpdatePreferences(c *gin.Context) {
+	dogUid, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		resp.AbortWithError(c, ierr.WrapCode(ierr.InvalidArgument, err, "wrong dog id param"))
+		return
+	}
+
+	var req messages.DogPreferencesRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	userUid, err := d.identityExtractor.ExtractFromContext(c)
+	if err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	prefs, err := d.dogUsecase.UpdatePreferences(c, userUid, dogUid, domain.DogPreferences{
+		Sex:           domain.DogSex(req.Sex),
+		MinAge:        req.MinAge,
+		MaxAge:        req.MaxAge,
+		Breeds:        req.Breeds,
+		MaxDistanceKm: req.MaxDistanceKm,
+	})
+	if err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, domainPreferencesToMessage(prefs))
 }
 
 // Create http handler func to create new dog.
 // @Summary      Create dog
-// @Description  Creates new dog
+// @Description  Creates new dog. Requires verified email. Photos are uploaded with POST /dog/{id}/photos, image URL is rejected.
 // @Tags         dogs
 // @Security 	 ApiKeyAuth
 // @Accept       json
@@ -168,6 +354,7 @@ func (d Dog) Matches(c *gin.Context) {
 // @Param 		 input body messages.CreateOrUpdateDogRequestBody true "dog object body"
 // @Success      200 {object} messages.DogResponseBody
 // @Failure      400  {object}  messages.BadRequestError
+// @Failure      403  {object}  messages.ForbiddenError
 // @Failure      500  {object}  messages.InternalServerError
 // @Router       /dog [post]
 func (d Dog) Create(c *gin.Context) {
@@ -177,6 +364,11 @@ func (d Dog) Create(c *gin.Context) {
 		return
 	}
 
+	if req.Image != "" {
+		resp.AbortWithError(c, ierr.New(ierr.InvalidArgument, imageURLRejected))
+		return
+	}
+
 	uid, err := d.identityExtractor.ExtractFromContext(c)
 	if err != nil {
 		resp.AbortWithError(c, ierr.WrapCode(ierr.Internal, err, "getting user id error"))
@@ -189,7 +381,11 @@ func (d Dog) Create(c *gin.Context) {
 		Sex:    domain.DogSex(req.Sex),
 		Age:    req.Age,
 		Breed:  req.Breed,
-		Image:  req.Image,
+		City:   req.City,
+	}
+
+	if req.Latitude != nil && req.Longitude != nil {
+		newDog.Location = &domain.GeoPoint{Latitude: *req.Latitude, Longitude: *req.Longitude}
 	}
 
 	dog, err := d.dogUsecase.Create(c, newDog)
@@ -198,12 +394,12 @@ func (d Dog) Create(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, d.domainDogToMessage(dog))
+	c.JSON(http.StatusOK, domainDogToMessage(dog))
 }
 
 // Update http handler func to update dog.
 // @Summary      Dog update
-// @Description  Updates existing dog
+// @Description  Updates existing dog, photos are kept as they are. Image URL is rejected.
 // @Tags         dogs
 // @Security 	 ApiKeyAuth
 // @Accept       json
@@ -228,6 +424,11 @@ func (d Dog) Update(c *gin.Context) {
 		return
 	}
 
+	if req.Image != "" {
+		resp.AbortWithError(c, ierr.New(ierr.InvalidArgument, imageURLRejected))
+		return
+	}
+
 	uid, err := d.identityExtractor.ExtractFromContext(c)
 	if err != nil {
 		resp.AbortWithError(c, err)
@@ -240,7 +441,11 @@ func (d Dog) Update(c *gin.Context) {
 		Sex:    domain.DogSex(req.Sex),
 		Age:    req.Age,
 		Breed:  req.Breed,
-		Image:  req.Image,
+		City:   req.City,
+	}
+
+	if req.Latitude != nil && req.Longitude != nil {
+		newDog.Location = &domain.GeoPoint{Latitude: *req.Latitude, Longitude: *req.Longitude}
 	}
 
 	dog, err := d.dogUsecase.Update(c, dogUid, newDog)
@@ -249,7 +454,205 @@ func (d Dog) Update(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, d.domainDogToMessage(dog))
+	c.JSON(http.StatusOK, domainDogToMessage(dog))
+}
+
+// UploadPhoto http handler func to upload photo of the dog.
+// @Summary      Dog photo upload
+// @Description  Processes uploaded image into thumb, card and full JPEG renditions without EXIF and other metadata
+// @Description  and adds them as the last photo of the dog, the first photo becomes primary. Content type is detected from the file itself.
+// @Description  Photo looking like a photo of another user's dog isn't added, it's held for moderation and 202 is returned.
+// @Tags         dogs
+// @Security 	 ApiKeyAuth
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param 		 id path string true "dog ID"
+// @Param 		 image formData file true "jpeg, png, gif or webp image"
+// @Success      200 {object} messages.DogResponseBody
+// @Success      202 {object} messages.HeldPhotoResponseBody
+// @Failure      400  {object}  messages.BadRequestError
+// @Failure      403  {object}  messages.ForbiddenError
+// @Failure      404  {object}  messages.NotFoundError
+// @Failure      500  {object}  messages.InternalServerError
+// @Router       /dog/{id}/photos [post]
+func (d Dog) UploadPhoto(c *gin.Context) {
+	dogUid, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		resp.AbortWithError(c, ierr.WrapCode(ierr.InvalidArgument, err, "wrong dog id"))
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, d.maxImageSize+multipartOverhead)
+
+	header, err := c.FormFile(imageFormFieldName)
+	if err != nil {
+		resp.AbortWithError(c, ierr.WrapCode(ierr.InvalidArgument, err, "reading image error"))
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		resp.AbortWithError(c, ierr.WrapCode(ierr.Internal, err, "opening image error"))
+		return
+	}
+	defer file.Close()
+
+	sniff := make([]byte, contentSniffLen)
+	n, err := io.ReadFull(file, sniff)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		resp.AbortWithError(c, ierr.WrapCode(ierr.Internal, err, "reading image error"))
+		return
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		resp.AbortWithError(c, ierr.WrapCode(ierr.Internal, err, "reading image error"))
+		return
+	}
+
+	uid, err := d.identityExtractor.ExtractFromContext(c)
+	if err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	image := domain.ImageUpload{
+		ContentType: http.DetectContentType(sniff[:n]),
+		Size:        header.Size,
+		Content:     file,
+	}
+
+	upload, err := d.dogUsecase.UploadPhoto(c, uid, dogUid, image)
+	if err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	if upload.Flagged != nil {
+		c.JSON(http.StatusAccepted, messages.HeldPhotoResponseBody{
+			ID:      upload.Flagged.ID.String(),
+			Status:  upload.Flagged.Status.String(),
+			Message: "photo looks like a photo of another user's dog, it's added once a moderator approves it",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, domainDogToMessage(upload.Dog))
+}
+
+// ReorderPhotos http handler func to change order of the dog photos.
+// @Summary      Dog photos reorder
+// @Description  Puts photos of the dog in the given order, every photo of the dog must be listed exactly once.
+// @Tags         dogs
+// @Security 	 ApiKeyAuth
+// @Accept       json
+// @Produce      json
+// @Param 		 id path string true "dog ID"
+// @Param 		 input body messages.ReorderDogPhotosRequestBody true "photos order"
+// @Success      200 {object} messages.DogResponseBody
+// @Failure      400  {object}  messages.BadRequestError
+// @Failure      403  {object}  messages.ForbiddenError
+// @Failure      404  {object}  messages.NotFoundError
+// @Failure      500  {object}  messages.InternalServerError
+// @Router       /dog/{id}/photos [put]
+func (d Dog) ReorderPhotos(c *gin.Context) {
+	dogUid, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		resp.AbortWithError(c, ierr.WrapCode(ierr.InvalidArgument, err, "wrong dog id"))
+		return
+	}
+
+	var req messages.ReorderDogPhotosRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	photoIDs := make([]uuid.UUID, 0, len(req.PhotoIDs))
+	for _, id := range req.PhotoIDs {
+		photoIDs = append(photoIDs, uuid.MustParse(id))
+	}
+
+	uid, err := d.identityExtractor.ExtractFromContext(c)
+	if err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	dog, err := d.dogUsecase.ReorderPhotos(c, uid, dogUid, photoIDs)
+	if err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, domainDogToMessage(dog))
+}
+
+// DeletePhoto http handler func to delete photo of the dog.
+// @Summary      Dog photo deletion
+// @Description  Removes the photo, if it was the primary one the first remaining photo becomes primary.
+// @Tags         dogs
+// @Security 	 ApiKeyAuth
+// @Produce      json
+// @Param 		 id path string true "dog ID"
+// @Param 		 photoId path string true "photo ID"
+// @Success      200 {object} messages.DogResponseBody
+// @Failure      400  {object}  messages.BadRequestError
+// @Failure      403  {object}  messages.ForbiddenError
+// @Failure      404  {object}  messages.NotFoundError
+// @Failure      500  {object}  messages.InternalServerError
+// @Router       /dog/{id}/photos/{photoId} [delete]
+func (d Dog) DeletePhoto(c *gin.Context) {
+	d.changePhoto(c, d.dogUsecase.DeletePhoto)
+}
+
+// SetPrimaryPhoto http handler func to pick the photo representing the dog.
+// @Summary      Dog primary photo
+// @Description  Makes the photo primary, it keeps its position.
+// @Tags         dogs
+// @Security 	 ApiKeyAuth
+// @Produce      json
+// @Param 		 id path string true "dog ID"
+// @Param 		 photoId path string true "photo ID"
+// @Success      200 {object} messages.DogResponseBody
+// @Failure      400  {object}  messages.BadRequestError
+// @Failure      403  {object}  messages.ForbiddenError
+// @Failure      404  {object}  messages.NotFoundError
+// @Failure      500  {object}  messages.InternalServerError
+// @Router       /dog/{id}/photos/{photoId}/primary [put]
+func (d Dog) SetPrimaryPhoto(c *gin.Context) {
+	d.changePhoto(c, d.dogUsecase.SetPrimaryPhoto)
+}
+
+// changePhoto handles request changing the single photo of the dog.
+func (d Dog) changePhoto(
+	c *gin.Context,
+	change func(ctx context.Context, userID, dogID, photoID uuid.UUID) (domain.Dog, error),
+) {
+	dogUid, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		resp.AbortWithError(c, ierr.WrapCode(ierr.InvalidArgument, err, "wrong dog id"))
+		return
+	}
+
+	photoUid, err := uuid.Parse(c.Param("photoId"))
+	if err != nil {
+		resp.AbortWithError(c, ierr.WrapCode(ierr.InvalidArgument, err, "wrong photo id"))
+		return
+	}
+
+	uid, err := d.identityExtractor.ExtractFromContext(c)
+	if err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	dog, err := change(c, uid, dogUid, photoUid)
+	if err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, domainDogToMessage(dog))
 }
 
 // Delete http handler func to delete tog.
@@ -288,7 +691,7 @@ func (d Dog) Delete(c *gin.Context) {
 
 // Reaction http handler func to save reaction of one dog to another.
 // @Summary      Reaction
-// @Description  React to another dog
+// @Description  React to another dog. Requires verified email.
 // @Tags         dogs
 // @Security 	 ApiKeyAuth
 // @Accept       json
@@ -296,6 +699,7 @@ func (d Dog) Delete(c *gin.Context) {
 // @Param 		 input body messages.ReactionRequestBody true "reaction body"
 // @Success      204
 // @Failure      400  {object}  messages.BadRequestError
+// @Failure      403  {object}  messages.ForbiddenError
 // @Failure      404  {object}  messages.NotFoundError
 // @Failure      500  {object}  messages.InternalServerError
 // @Router       /dog/reaction [post]
@@ -326,22 +730,64 @@ func (d Dog) Reaction(c *gin.Context) {
 	c.AbortWithStatus(http.StatusNoContent)
 }
 
-func (d Dog) domainDogToMessage(dog domain.Dog) messages.DogResponseBody {
+func domainDogToMessage(dog domain.Dog) messages.DogResponseBody {
+	photos := make([]messages.DogPhotoResponseBody, 0, len(dog.Photos))
+	for _, photo := range dog.Photos {
+		photos = append(photos, messages.DogPhotoResponseBody{
+			ID:      photo.ID.String(),
+			Primary: photo.Primary,
+			Images:  domainImagesToMessage(photo.Images),
+		})
+	}
+
 	return messages.DogResponseBody{
-		ID:    dog.ID.String(),
-		Name:  dog.Name,
-		Sex:   dog.Sex.String(),
-		Age:   dog.Age,
-		Breed: dog.Breed,
-		Image: dog.Image,
+		ID:         dog.ID.String(),
+		Name:       dog.Name,
+		Sex:        dog.Sex.String(),
+		Age:        dog.Age,
+		Breed:      dog.Breed,
+		Images:     domainImagesToMessage(dog.PrimaryPhoto().Images),
+		Photos:     photos,
+		City:       dog.City,
+		DistanceKm: dog.Distance,
+	}
+}
+
+func domainImagesToMessage(images domain.DogImages) messages.DogImagesResponseBody {
+	return messages.DogImagesResponseBody{
+		Thumb: images.Thumb,
+		Card:  images.Card,
+		Full:  images.Full,
 	}
 }
 
-func (d Dog) domainDogListToMessageList(dogs []domain.Dog) messages.DogListResponseBody {
+func domainDogListToMessageList(dogs []domain.Dog) messages.DogListResponseBody {
 	list := make(messages.DogListResponseBody, 0, len(dogs))
 	for _, dog := range dogs {
-		list = append(list, d.domainDogToMessage(dog))
+		list = append(list, domainDogToMessage(dog))
 	}
 
 	return list
 }
+
+func domainDogPageToMessage(page domain.DogPage) messages.DogPageResponseBody {
+	return messages.DogPageResponseBody{
+		Dogs:       domainDogListToMessageList(page.Dogs),
+		NextCursor: encodeCursor(page.NextCursor),
+	}
+}
+
+func domainPreferencesToMessage(prefs domain.DogPreferences) messages.DogPreferencesResponseBody {
+	breeds := prefs.Breeds
+	if breeds == nil {
+		breeds = []string{}
+	}
+
+	return messages.DogPreferencesResponseBody{
+		Sex:           prefs.Sex.String(),
+		MinAge:        prefs.MinAge,
+		MaxAge:        prefs.MaxAge,
+		Breeds:        breeds,
+		MaxDistanceKm: prefs.MaxDistanceKm,
+	}
+}
@@ -0,0 +1,136 @@
+package presenters
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	sexRequestQueryParamName          = "sex"
+	minAgeRequestQueryParamName       = "min-age"
+	maxAgeRequestQueryParamName       = "max-age"
+	breedRequestQueryParamName        = "breed"
+	createdAfterRequestQueryParamName = "created-after"
+	nearRequestQueryParamName         = "near"
+	radiusKmRequestQueryParamName     = "radius-km"
+	sortRequestQueryParamName         = "sort"
+
+	defaultSort = domain.SortCreatedDesc
+)
+
+type UrlDogFilter struct{}
+
+func NewUrlDogFilter() *UrlDogFilter {
+	return &UrlDogFilter{}
+}
+
+// GetDogFilter helper function parses dogs list filter from request and returns domain.DogFilter object.
+// Breed param may be repeated, dogs of any of the breeds match. Near is "latitude,longitude", radius and sorting
+// by distance need it.
+func (f UrlDogFilter) GetDogFilter(c *gin.Context) (domain.DogFilter, error) {
+	filter := domain.DogFilter{
+		Sex:  domain.DogSex(c.Query(sexRequestQueryParamName)),
+		Sort: domain.DogSort(c.DefaultQuery(sortRequestQueryParamName, defaultSort.String())),
+	}
+
+	if filter.Sex != "" && !filter.Sex.Valid() {
+		return domain.DogFilter{}, ierr.New(ierr.InvalidArgument, "wrong sex param")
+	}
+
+	if !filter.Sort.Valid() {
+		return domain.DogFilter{}, ierr.New(ierr.InvalidArgument, "wrong sort param")
+	}
+
+	var err error
+	if filter.MinAge, err = f.age(c, minAgeRequestQueryParamName); err != nil {
+		return domain.DogFilter{}, err
+	}
+
+	if filter.MaxAge, err = f.age(c, maxAgeRequestQueryParamName); err != nil {
+		return domain.DogFilter{}, err
+	}
+
+	if filter.MinAge != nil && filter.MaxAge != nil && *filter.MinAge > *filter.MaxAge {
+		return domain.DogFilter{}, ierr.New(ierr.InvalidArgument, "min-age must not be greater than max-age")
+	}
+
+	for _, breed := range c.QueryArray(breedRequestQueryParamName) {
+		breed = strings.TrimSpace(breed)
+		if breed == "" {
+			return domain.DogFilter{}, ierr.New(ierr.InvalidArgument, "wrong breed param")
+		}
+
+		filter.Breeds = append(filter.Breeds, breed)
+	}
+
+	if createdAfter := c.Query(createdAfterRequestQueryParamName); createdAfter != "" {
+		filter.CreatedAfter, err = time.Parse(time.RFC3339, createdAfter)
+		if err != nil {
+			return domain.DogFilter{}, ierr.WrapCode(ierr.InvalidArgument, err, "wrong created-after param")
+		}
+	}
+
+	if filter.Near, err = f.near(c); err != nil {
+		return domain.DogFilter{}, err
+	}
+
+	if radius := c.Query(radiusKmRequestQueryParamName); radius != "" {
+		filter.RadiusKm, err = strconv.ParseFloat(radius, 64)
+		// negated comparison rejects NaN too.
+		if err != nil || !(filter.RadiusKm > 0) || math.IsInf(filter.RadiusKm, 0) {
+			return domain.DogFilter{}, ierr.New(ierr.InvalidArgument, "wrong radius-km param")
+		}
+	}
+
+	if filter.Near == nil && (filter.RadiusKm > 0 || filter.Sort == domain.SortDistanceAsc) {
+		return domain.DogFilter{}, ierr.New(ierr.InvalidArgument, "near param is required for radius-km and distance sort")
+	}
+
+	return filter, nil
+}
+
+// near parses optional point param, nil if the param isn't given.
+func (f UrlDogFilter) near(c *gin.Context) (*domain.GeoPoint, error) {
+	value := c.Query(nearRequestQueryParamName)
+	if value == "" {
+		return nil, nil
+	}
+
+	lat, lng, ok := strings.Cut(value, ",")
+	if !ok {
+		return nil, ierr.New(ierr.InvalidArgument, "wrong near param")
+	}
+
+	var point domain.GeoPoint
+	var latErr, lngErr error
+	point.Latitude, latErr = strconv.ParseFloat(strings.TrimSpace(lat), 64)
+	point.Longitude, lngErr = strconv.ParseFloat(strings.TrimSpace(lng), 64)
+	if latErr != nil || lngErr != nil || !point.Valid() {
+		return nil, ierr.New(ierr.InvalidArgument, "wrong near param")
+	}
+
+	return &point, nil
+}
+
+// age parses optional age param, nil if the param isn't given.
+func (f UrlDogFilter) age(c *gin.Context, name string) (*uint, error) {
+	value, ok := c.GetQuery(name)
+	if !ok {
+		return nil, nil
+	}
+
+	age, err := strconv.ParseUint(value, 10, 0)
+	if err != nil {
+		return nil, ierr.WrapCode(ierr.InvalidArgument, err, "wrong "+name+" param")
+	}
+
+	uAge := uint(age)
+	return &uAge, nil
+}
@@ -0,0 +1,126 @@
+package presenters
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUrlDogFilter_GetDogFilter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	minAge, maxAge := uint(1), uint(5)
+	createdAfter := time.Date(2023, 2, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		query   string
+		want    domain.DogFilter
+		wantErr bool
+	}{
+		{
+			name:  "defaults",
+			query: "",
+			want:  domain.DogFilter{Sort: domain.SortCreatedDesc},
+		},
+		{
+			name:  "all params",
+			query: "sex=female&min-age=1&max-age=5&breed=Bulldog&breed=%20poodle%20&created-after=2023-02-01T10:00:00Z&sort=age-asc",
+			want: domain.DogFilter{
+				Sex:          domain.Female,
+				MinAge:       &minAge,
+				MaxAge:       &maxAge,
+				Breeds:       []string{"Bulldog", "poodle"},
+				CreatedAfter: createdAfter,
+				Sort:         domain.SortAgeAsc,
+			},
+		},
+		{
+			name:  "near point",
+			query: "near=49.8397,24.0297&radius-km=2.5&sort=distance-asc",
+			want: domain.DogFilter{
+				Near:     &domain.GeoPoint{Latitude: 49.8397, Longitude: 24.0297},
+				RadiusKm: 2.5,
+				Sort:     domain.SortDistanceAsc,
+			},
+		},
+		{
+			name:    "wrong near",
+			query:   "near=49.8397",
+			wantErr: true,
+		},
+		{
+			name:    "near out of range",
+			query:   "near=91,24.0297",
+			wantErr: true,
+		},
+		{
+			name:    "wrong radius",
+			query:   "near=49.8397,24.0297&radius-km=NaN",
+			wantErr: true,
+		},
+		{
+			name:    "radius without near",
+			query:   "radius-km=5",
+			wantErr: true,
+		},
+		{
+			name:    "distance sort without near",
+			query:   "sort=distance-asc",
+			wantErr: true,
+		},
+		{
+			name:    "wrong sex",
+			query:   "sex=unknown",
+			wantErr: true,
+		},
+		{
+			name:    "wrong sort",
+			query:   "sort=breed-asc",
+			wantErr: true,
+		},
+		{
+			name:    "negative age",
+			query:   "min-age=-1",
+			wantErr: true,
+		},
+		{
+			name:    "min age greater than max age",
+			query:   "min-age=5&max-age=1",
+			wantErr: true,
+		},
+		{
+			name:    "empty breed",
+			query:   "breed=%20",
+			wantErr: true,
+		},
+		{
+			name:    "wrong created-after",
+			query:   "created-after=2023-02-01",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/dog?"+tt.query, nil)
+
+			got, err := NewUrlDogFilter().GetDogFilter(c)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Equal(t, ierr.InvalidArgument, ierr.GetCode(err))
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
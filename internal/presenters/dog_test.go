@@ -4,8 +4,10 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -24,14 +26,16 @@ func TestDog_List(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	controller := gomock.NewController(t)
-	tokenProcessor := token.NewJWT("test-secret", time.Minute*5)
+	tokenProcessor := token.NewJWT(token.NewHMACKeySet("test-secret"), time.Minute*5)
 
 	mockDogUsecase := NewMockDogUsecase(controller)
 	mockIdentityExtractor := NewMockIdentityExtractor(controller)
 	mockPaginator := NewMockPaginator(controller)
-	authMiddleware := NewAuthMiddleware(tokenProcessor)
+	mockDogFilterParser := NewMockDogFilterParser(controller)
+	authMiddleware := newTestAuthMiddleware(controller, tokenProcessor)
 
 	userID := uuid.New()
+	filter := domain.DogFilter{Sex: domain.Female, Sort: domain.SortCreatedDesc}
 
 	dList := domain.DogList{
 		{
@@ -41,7 +45,7 @@ func TestDog_List(t *testing.T) {
 			Sex:       "male",
 			Age:       2,
 			Breed:     "test",
-			Image:     "http://test.com/dog1.jpeg",
+			Photos:    []domain.DogPhoto{{ID: uuid.New(), Images: domain.ExternalDogImages("http://test.com/dog1.jpeg"), Primary: true}},
 			CreatedAt: time.Now(),
 			UpdatedAt: time.Now(),
 		},
@@ -52,28 +56,32 @@ func TestDog_List(t *testing.T) {
 			Sex:       "feamle",
 			Age:       3,
 			Breed:     "test",
-			Image:     "http://test.com/dog2.jpeg",
+			Photos:    []domain.DogPhoto{{ID: uuid.New(), Images: domain.ExternalDogImages("http://test.com/dog2.jpeg"), Primary: true}},
 			CreatedAt: time.Now(),
 			UpdatedAt: time.Now(),
 		},
 	}
 
+	nextCursor := domain.Cursor{CreatedAt: dList[1].CreatedAt.UTC(), ID: dList[1].ID}
+
 	mList := messages.DogListResponseBody{
 		{
-			ID:    dList[0].ID.String(),
-			Name:  dList[0].Name,
-			Sex:   dList[0].Sex.String(),
-			Age:   dList[0].Age,
-			Breed: dList[0].Breed,
-			Image: dList[0].Image,
+			ID:     dList[0].ID.String(),
+			Name:   dList[0].Name,
+			Sex:    dList[0].Sex.String(),
+			Age:    dList[0].Age,
+			Breed:  dList[0].Breed,
+			Images: messages.DogImagesResponseBody(dList[0].Photos[0].Images),
+			Photos: []messages.DogPhotoResponseBody{{ID: dList[0].Photos[0].ID.String(), Primary: true, Images: messages.DogImagesResponseBody(dList[0].Photos[0].Images)}},
 		},
 		{
-			ID:    dList[1].ID.String(),
-			Name:  dList[1].Name,
-			Sex:   dList[1].Sex.String(),
-			Age:   dList[1].Age,
-			Breed: dList[1].Breed,
-			Image: dList[1].Image,
+			ID:     dList[1].ID.String(),
+			Name:   dList[1].Name,
+			Sex:    dList[1].Sex.String(),
+			Age:    dList[1].Age,
+			Breed:  dList[1].Breed,
+			Images: messages.DogImagesResponseBody(dList[1].Photos[0].Images),
+			Photos: []messages.DogPhotoResponseBody{{ID: dList[1].Photos[0].ID.String(), Primary: true, Images: messages.DogImagesResponseBody(dList[1].Photos[0].Images)}},
 		},
 	}
 
@@ -90,7 +98,7 @@ func TestDog_List(t *testing.T) {
 		{
 			name: "getting user ID from context error",
 			fields: fields{
-				dog: NewDog(mockDogUsecase, mockIdentityExtractor, mockPaginator, authMiddleware.Auth),
+				dog: NewDog(mockDogUsecase, mockIdentityExtractor, mockPaginator, mockDogFilterParser, maxImageSize, authMiddleware.Auth),
 			},
 			mocksInitFn: func() {
 				err := ierr.New(ierr.Unauthenticated, "testing-error")
@@ -102,7 +110,7 @@ func TestDog_List(t *testing.T) {
 					assert.Error(t, err)
 				}
 
-				st, err := tokenProcessor.Generate(userID)
+				st, err := tokenProcessor.Generate(userID, domain.RoleUser.String())
 				if err != nil {
 					assert.Error(t, err)
 				}
@@ -118,7 +126,7 @@ func TestDog_List(t *testing.T) {
 		{
 			name: "getting pagination from url error",
 			fields: fields{
-				dog: NewDog(mockDogUsecase, mockIdentityExtractor, mockPaginator, authMiddleware.Auth),
+				dog: NewDog(mockDogUsecase, mockIdentityExtractor, mockPaginator, mockDogFilterParser, maxImageSize, authMiddleware.Auth),
 			},
 			mocksInitFn: func() {
 				err := ierr.New(ierr.InvalidArgument, "testing-error")
@@ -131,7 +139,39 @@ func TestDog_List(t *testing.T) {
 					assert.Error(t, err)
 				}
 
-				st, err := tokenProcessor.Generate(userID)
+				st, err := tokenProcessor.Generate(userID, domain.RoleUser.String())
+				if err != nil {
+					assert.Error(t, err)
+				}
+
+				req.Header.Set(AuthorizationHeaderName, fmt.Sprintf("%s%s", bearerPrefix, st))
+
+				return req
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "getting filter from url error",
+			fields: fields{
+				dog: NewDog(mockDogUsecase, mockIdentityExtractor, mockPaginator, mockDogFilterParser, maxImageSize, authMiddleware.Auth),
+			},
+			mocksInitFn: func() {
+				err := ierr.New(ierr.InvalidArgument, "testing-error")
+				pagination := domain.Pagination{Page: 1, PerPage: 10}
+
+				mockIdentityExtractor.EXPECT().ExtractFromContext(gomock.Any()).Return(userID, nil)
+				mockPaginator.EXPECT().GetPagination(gomock.Any()).Return(pagination, nil)
+				mockDogFilterParser.EXPECT().GetDogFilter(gomock.Any()).Return(domain.DogFilter{}, err)
+			},
+			getRequestFn: func() *http.Request {
+				req, err := http.NewRequest(http.MethodGet, "/api/dog?sex=unknown", nil)
+				if err != nil {
+					assert.Error(t, err)
+				}
+
+				st, err := tokenProcessor.Generate(userID, domain.RoleUser.String())
 				if err != nil {
 					assert.Error(t, err)
 				}
@@ -147,7 +187,7 @@ func TestDog_List(t *testing.T) {
 		{
 			name: "usecase error",
 			fields: fields{
-				dog: NewDog(mockDogUsecase, mockIdentityExtractor, mockPaginator, authMiddleware.Auth),
+				dog: NewDog(mockDogUsecase, mockIdentityExtractor, mockPaginator, mockDogFilterParser, maxImageSize, authMiddleware.Auth),
 			},
 			mocksInitFn: func() {
 				err := ierr.New(ierr.Internal, "testing-error")
@@ -155,7 +195,8 @@ func TestDog_List(t *testing.T) {
 
 				mockIdentityExtractor.EXPECT().ExtractFromContext(gomock.Any()).Return(userID, nil)
 				mockPaginator.EXPECT().GetPagination(gomock.Any()).Return(pagination, nil)
-				mockDogUsecase.EXPECT().List(gomock.Any(), userID, pagination).Return(nil, err)
+				mockDogFilterParser.EXPECT().GetDogFilter(gomock.Any()).Return(filter, nil)
+				mockDogUsecase.EXPECT().List(gomock.Any(), userID, filter, pagination).Return(domain.DogPage{}, err)
 			},
 			getRequestFn: func() *http.Request {
 				req, err := http.NewRequest(http.MethodGet, "/api/dog", nil)
@@ -163,7 +204,7 @@ func TestDog_List(t *testing.T) {
 					assert.Error(t, err)
 				}
 
-				st, err := tokenProcessor.Generate(userID)
+				st, err := tokenProcessor.Generate(userID, domain.RoleUser.String())
 				if err != nil {
 					assert.Error(t, err)
 				}
@@ -179,14 +220,16 @@ func TestDog_List(t *testing.T) {
 		{
 			name: "success",
 			fields: fields{
-				dog: NewDog(mockDogUsecase, mockIdentityExtractor, mockPaginator, authMiddleware.Auth),
+				dog: NewDog(mockDogUsecase, mockIdentityExtractor, mockPaginator, mockDogFilterParser, maxImageSize, authMiddleware.Auth),
 			},
 			mocksInitFn: func() {
 				pagination := domain.Pagination{Page: 1, PerPage: 2}
 
 				mockIdentityExtractor.EXPECT().ExtractFromContext(gomock.Any()).Return(userID, nil)
 				mockPaginator.EXPECT().GetPagination(gomock.Any()).Return(pagination, nil)
-				mockDogUsecase.EXPECT().List(gomock.Any(), userID, pagination).Return(dList, nil)
+				mockDogFilterParser.EXPECT().GetDogFilter(gomock.Any()).Return(filter, nil)
+				mockDogUsecase.EXPECT().List(gomock.Any(), userID, filter, pagination).
+					Return(domain.DogPage{Dogs: dList, NextCursor: &nextCursor}, nil)
 			},
 			getRequestFn: func() *http.Request {
 				req, err := http.NewRequest(http.MethodGet, "/api/dog", nil)
@@ -194,7 +237,7 @@ func TestDog_List(t *testing.T) {
 					assert.Error(t, err)
 				}
 
-				st, err := tokenProcessor.Generate(userID)
+				st, err := tokenProcessor.Generate(userID, domain.RoleUser.String())
 				if err != nil {
 					assert.Error(t, err)
 				}
@@ -206,12 +249,16 @@ func TestDog_List(t *testing.T) {
 			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
 				assert.Equal(t, http.StatusOK, recorder.Code)
 
-				var resp messages.DogListResponseBody
+				var resp messages.DogPageResponseBody
 				if err := json.Unmarshal(recorder.Body.Bytes(), &resp); err != nil {
 					assert.Error(t, err)
 				}
 
-				assert.Equal(t, mList, resp)
+				assert.Equal(t, mList, resp.Dogs)
+
+				cursor, err := decodeCursor(resp.NextCursor)
+				assert.NoError(t, err)
+				assert.Equal(t, nextCursor, cursor)
 			},
 		},
 	}
@@ -235,12 +282,12 @@ func TestDog_Get(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	controller := gomock.NewController(t)
-	tokenProcessor := token.NewJWT("test-secret", time.Minute*5)
+	tokenProcessor := token.NewJWT(token.NewHMACKeySet("test-secret"), time.Minute*5)
 
 	mockDogUsecase := NewMockDogUsecase(controller)
 	mockIdentityExtractor := NewMockIdentityExtractor(controller)
 	mockPaginator := NewMockPaginator(controller)
-	authMiddleware := NewAuthMiddleware(tokenProcessor)
+	authMiddleware := newTestAuthMiddleware(controller, tokenProcessor)
 
 	userID := uuid.New()
 	dogID := uuid.New()
@@ -252,18 +299,19 @@ func TestDog_Get(t *testing.T) {
 		Sex:       "male",
 		Age:       3,
 		Breed:     "test",
-		Image:     "http://test.com/image1.jpeg",
+		Photos:    []domain.DogPhoto{{ID: uuid.New(), Images: domain.ExternalDogImages("http://test.com/image1.jpeg"), Primary: true}},
 		CreatedAt: time.Time{},
 		UpdatedAt: time.Time{},
 	}
 
 	mDog := messages.DogResponseBody{
-		ID:    dDog.ID.String(),
-		Name:  dDog.Name,
-		Sex:   dDog.Sex.String(),
-		Age:   dDog.Age,
-		Breed: dDog.Breed,
-		Image: dDog.Image,
+		ID:     dDog.ID.String(),
+		Name:   dDog.Name,
+		Sex:    dDog.Sex.String(),
+		Age:    dDog.Age,
+		Breed:  dDog.Breed,
+		Images: messages.DogImagesResponseBody(dDog.Photos[0].Images),
+		Photos: []messages.DogPhotoResponseBody{{ID: dDog.Photos[0].ID.String(), Primary: true, Images: messages.DogImagesResponseBody(dDog.Photos[0].Images)}},
 	}
 
 	type fields struct {
@@ -279,7 +327,7 @@ func TestDog_Get(t *testing.T) {
 		{
 			name: "getting dog id param error",
 			fields: fields{
-				dog: NewDog(mockDogUsecase, mockIdentityExtractor, mockPaginator, authMiddleware.Auth),
+				dog: NewDog(mockDogUsecase, mockIdentityExtractor, mockPaginator, nil, maxImageSize, authMiddleware.Auth),
 			},
 			mocksInitFn: func() {
 
@@ -290,7 +338,7 @@ func TestDog_Get(t *testing.T) {
 					assert.Error(t, err)
 				}
 
-				st, err := tokenProcessor.Generate(userID)
+				st, err := tokenProcessor.Generate(userID, domain.RoleUser.String())
 				if err != nil {
 					assert.Error(t, err)
 				}
@@ -306,7 +354,7 @@ func TestDog_Get(t *testing.T) {
 		{
 			name: "usecase error",
 			fields: fields{
-				dog: NewDog(mockDogUsecase, mockIdentityExtractor, mockPaginator, authMiddleware.Auth),
+				dog: NewDog(mockDogUsecase, mockIdentityExtractor, mockPaginator, nil, maxImageSize, authMiddleware.Auth),
 			},
 			mocksInitFn: func() {
 				err := ierr.New(ierr.Internal, "testing-error")
@@ -318,7 +366,7 @@ func TestDog_Get(t *testing.T) {
 					assert.Error(t, err)
 				}
 
-				st, err := tokenProcessor.Generate(userID)
+				st, err := tokenProcessor.Generate(userID, domain.RoleUser.String())
 				if err != nil {
 					assert.Error(t, err)
 				}
@@ -334,7 +382,7 @@ func TestDog_Get(t *testing.T) {
 		{
 			name: "success",
 			fields: fields{
-				dog: NewDog(mockDogUsecase, mockIdentityExtractor, mockPaginator, authMiddleware.Auth),
+				dog: NewDog(mockDogUsecase, mockIdentityExtractor, mockPaginator, nil, maxImageSize, authMiddleware.Auth),
 			},
 			mocksInitFn: func() {
 				mockDogUsecase.EXPECT().Get(gomock.Any(), gomock.Eq(dogID)).Return(dDog, nil)
@@ -345,7 +393,7 @@ func TestDog_Get(t *testing.T) {
 					assert.Error(t, err)
 				}
 
-				st, err := tokenProcessor.Generate(userID)
+				st, err := tokenProcessor.Generate(userID, domain.RoleUser.String())
 				if err != nil {
 					assert.Error(t, err)
 				}
@@ -381,16 +429,106 @@ func TestDog_Get(t *testing.T) {
 	}
 }
 
+func TestDog_Feed(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	controller := gomock.NewController(t)
+	mockDogUsecase := NewMockDogUsecase(controller)
+	mockIdentityExtractor := NewMockIdentityExtractor(controller)
+
+	userID := uuid.New()
+	dogID := uuid.New()
+	candidateID := uuid.New()
+
+	pagination := domain.Pagination{Page: 1, PerPage: 10}
+	filter := domain.DogFilter{Sex: domain.Female, Sort: domain.SortCreatedDesc}
+
+	tests := []struct {
+		name              string
+		mocksInitFn       func()
+		url               string
+		resultAssertionFn func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "wrong dog id",
+			mocksInitFn: func() {
+				mockIdentityExtractor.EXPECT().ExtractFromContext(gomock.Any()).Return(userID, nil)
+			},
+			url: "/api/dog/wrong/feed",
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "wrong filter",
+			mocksInitFn: func() {
+				mockIdentityExtractor.EXPECT().ExtractFromContext(gomock.Any()).Return(userID, nil)
+			},
+			url: fmt.Sprintf("/api/dog/%s/feed?sex=unknown", dogID),
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "feed of not your dog",
+			mocksInitFn: func() {
+				mockIdentityExtractor.EXPECT().ExtractFromContext(gomock.Any()).Return(userID, nil)
+				mockDogUsecase.EXPECT().Feed(gomock.Any(), userID, dogID, filter, pagination).
+					Return(domain.DogPage{}, ierr.New(ierr.PermissionDenied, "cannot get feed of not your dog"))
+			},
+			url: fmt.Sprintf("/api/dog/%s/feed?sex=female", dogID),
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "success",
+			mocksInitFn: func() {
+				mockIdentityExtractor.EXPECT().ExtractFromContext(gomock.Any()).Return(userID, nil)
+				mockDogUsecase.EXPECT().Feed(gomock.Any(), userID, dogID, filter, pagination).
+					Return(domain.DogPage{Dogs: domain.DogList{{ID: candidateID, Name: "dog", Sex: domain.Female}}}, nil)
+			},
+			url: fmt.Sprintf("/api/dog/%s/feed?sex=female", dogID),
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var body messages.DogPageResponseBody
+				assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+				assert.Len(t, body.Dogs, 1)
+				assert.Equal(t, candidateID.String(), body.Dogs[0].ID)
+				assert.Empty(t, body.NextCursor)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInitFn()
+
+			recorder := httptest.NewRecorder()
+			_, engine := gin.CreateTestContext(recorder)
+			dog := NewDog(mockDogUsecase, mockIdentityExtractor, NewUrlPagination(), NewUrlDogFilter(), maxImageSize)
+			engine = InitRoutes(engine, dog)
+
+			req, err := http.NewRequest(http.MethodGet, tt.url, nil)
+			assert.NoError(t, err)
+
+			engine.ServeHTTP(recorder, req)
+			tt.resultAssertionFn(recorder)
+		})
+	}
+}
+
 func TestDog_Matches(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	controller := gomock.NewController(t)
-	tokenProcessor := token.NewJWT("test-secret", time.Minute*5)
+	tokenProcessor := token.NewJWT(token.NewHMACKeySet("test-secret"), time.Minute*5)
 
 	mockDogUsecase := NewMockDogUsecase(controller)
 	mockIdentityExtractor := NewMockIdentityExtractor(controller)
 	mockPaginator := NewMockPaginator(controller)
-	authMiddleware := NewAuthMiddleware(tokenProcessor)
+	authMiddleware := newTestAuthMiddleware(controller, tokenProcessor)
 
 	userID := uuid.New()
 	dogID := uuid.New()
@@ -403,7 +541,7 @@ func TestDog_Matches(t *testing.T) {
 			Sex:       "male",
 			Age:       4,
 			Breed:     "test-breed",
-			Image:     "http://test.com/dog1.jpeg",
+			Photos:    []domain.DogPhoto{{ID: uuid.New(), Images: domain.ExternalDogImages("http://test.com/dog1.jpeg"), Primary: true}},
 			CreatedAt: time.Now(),
 			UpdatedAt: time.Now(),
 		},
@@ -414,7 +552,7 @@ func TestDog_Matches(t *testing.T) {
 			Sex:       "female",
 			Age:       6,
 			Breed:     "test-breed",
-			Image:     "http://test.com/dog3.jpeg",
+			Photos:    []domain.DogPhoto{{ID: uuid.New(), Images: domain.ExternalDogImages("http://test.com/dog3.jpeg"), Primary: true}},
 			CreatedAt: time.Now(),
 			UpdatedAt: time.Now(),
 		},
@@ -422,20 +560,22 @@ func TestDog_Matches(t *testing.T) {
 
 	mList := messages.DogListResponseBody{
 		{
-			ID:    dList[0].ID.String(),
-			Name:  dList[0].Name,
-			Sex:   dList[0].Sex.String(),
-			Age:   dList[0].Age,
-			Breed: dList[0].Breed,
-			Image: dList[0].Image,
+			ID:     dList[0].ID.String(),
+			Name:   dList[0].Name,
+			Sex:    dList[0].Sex.String(),
+			Age:    dList[0].Age,
+			Breed:  dList[0].Breed,
+			Images: messages.DogImagesResponseBody(dList[0].Photos[0].Images),
+			Photos: []messages.DogPhotoResponseBody{{ID: dList[0].Photos[0].ID.String(), Primary: true, Images: messages.DogImagesResponseBody(dList[0].Photos[0].Images)}},
 		},
 		{
-			ID:    dList[1].ID.String(),
-			Name:  dList[1].Name,
-			Sex:   dList[1].Sex.String(),
-			Age:   dList[1].Age,
-			Breed: dList[1].Breed,
-			Image: dList[1].Image,
+			ID:     dList[1].ID.String(),
+			Name:   dList[1].Name,
+			Sex:    dList[1].Sex.String(),
+			Age:    dList[1].Age,
+			Breed:  dList[1].Breed,
+			Images: messages.DogImagesResponseBody(dList[1].Photos[0].Images),
+			Photos: []messages.DogPhotoResponseBody{{ID: dList[1].Photos[0].ID.String(), Primary: true, Images: messages.DogImagesResponseBody(dList[1].Photos[0].Images)}},
 		},
 	}
 
@@ -452,7 +592,7 @@ func TestDog_Matches(t *testing.T) {
 		{
 			name: "getting pagination error",
 			fields: fields{
-				dog: NewDog(mockDogUsecase, mockIdentityExtractor, mockPaginator, authMiddleware.Auth),
+				dog: NewDog(mockDogUsecase, mockIdentityExtractor, mockPaginator, nil, maxImageSize, authMiddleware.Auth),
 			},
 			mocksInitFn: func() {
 				err := ierr.New(ierr.InvalidArgument, "testing-error")
@@ -464,7 +604,7 @@ func TestDog_Matches(t *testing.T) {
 					assert.Error(t, err)
 				}
 
-				st, err := tokenProcessor.Generate(userID)
+				st, err := tokenProcessor.Generate(userID, domain.RoleUser.String())
 				if err != nil {
 					assert.Error(t, err)
 				}
@@ -480,7 +620,7 @@ func TestDog_Matches(t *testing.T) {
 		{
 			name: "getting dog id from params error",
 			fields: fields{
-				dog: NewDog(mockDogUsecase, mockIdentityExtractor, mockPaginator, authMiddleware.Auth),
+				dog: NewDog(mockDogUsecase, mockIdentityExtractor, mockPaginator, nil, maxImageSize, authMiddleware.Auth),
 			},
 			mocksInitFn: func() {
 				mockPaginator.EXPECT().GetPagination(gomock.Any()).Return(domain.Pagination{Page: 1, PerPage: 2}, nil)
@@ -492,7 +632,7 @@ func TestDog_Matches(t *testing.T) {
 					assert.Error(t, err)
 				}
 
-				st, err := tokenProcessor.Generate(userID)
+				st, err := tokenProcessor.Generate(userID, domain.RoleUser.String())
 				if err != nil {
 					assert.Error(t, err)
 				}
@@ -508,7 +648,7 @@ func TestDog_Matches(t *testing.T) {
 		{
 			name: "identity extractor error",
 			fields: fields{
-				dog: NewDog(mockDogUsecase, mockIdentityExtractor, mockPaginator, authMiddleware.Auth),
+				dog: NewDog(mockDogUsecase, mockIdentityExtractor, mockPaginator, nil, maxImageSize, authMiddleware.Auth),
 			},
 			mocksInitFn: func() {
 				err := ierr.New(ierr.Internal, "testing error")
@@ -523,7 +663,7 @@ func TestDog_Matches(t *testing.T) {
 					assert.Error(t, err)
 				}
 
-				st, err := tokenProcessor.Generate(userID)
+				st, err := tokenProcessor.Generate(userID, domain.RoleUser.String())
 				if err != nil {
 					assert.Error(t, err)
 				}
@@ -539,7 +679,7 @@ func TestDog_Matches(t *testing.T) {
 		{
 			name: "usecase error",
 			fields: fields{
-				dog: NewDog(mockDogUsecase, mockIdentityExtractor, mockPaginator, authMiddleware.Auth),
+				dog: NewDog(mockDogUsecase, mockIdentityExtractor, mockPaginator, nil, maxImageSize, authMiddleware.Auth),
 			},
 			mocksInitFn: func() {
 				err := ierr.New(ierr.Internal, "testing error")
@@ -547,7 +687,7 @@ func TestDog_Matches(t *testing.T) {
 
 				mockPaginator.EXPECT().GetPagination(gomock.Any()).Return(pag, nil)
 				mockIdentityExtractor.EXPECT().ExtractFromContext(gomock.Any()).Return(userID, nil)
-				mockDogUsecase.EXPECT().Matches(gomock.Any(), userID, dogID, pag).Return(nil, err)
+				mockDogUsecase.EXPECT().Matches(gomock.Any(), userID, dogID, pag).Return(domain.DogPage{}, err)
 
 			},
 			getRequestFn: func() *http.Request {
@@ -556,7 +696,7 @@ func TestDog_Matches(t *testing.T) {
 					assert.Error(t, err)
 				}
 
-				st, err := tokenProcessor.Generate(userID)
+				st, err := tokenProcessor.Generate(userID, domain.RoleUser.String())
 				if err != nil {
 					assert.Error(t, err)
 				}
@@ -572,14 +712,14 @@ func TestDog_Matches(t *testing.T) {
 		{
 			name: "success",
 			fields: fields{
-				dog: NewDog(mockDogUsecase, mockIdentityExtractor, mockPaginator, authMiddleware.Auth),
+				dog: NewDog(mockDogUsecase, mockIdentityExtractor, mockPaginator, nil, maxImageSize, authMiddleware.Auth),
 			},
 			mocksInitFn: func() {
 				pag := domain.Pagination{Page: 1, PerPage: 2}
 
 				mockPaginator.EXPECT().GetPagination(gomock.Any()).Return(pag, nil)
 				mockIdentityExtractor.EXPECT().ExtractFromContext(gomock.Any()).Return(userID, nil)
-				mockDogUsecase.EXPECT().Matches(gomock.Any(), userID, dogID, pag).Return(dList, nil)
+				mockDogUsecase.EXPECT().Matches(gomock.Any(), userID, dogID, pag).Return(domain.DogPage{Dogs: dList}, nil)
 
 			},
 			getRequestFn: func() *http.Request {
@@ -588,7 +728,7 @@ func TestDog_Matches(t *testing.T) {
 					assert.Error(t, err)
 				}
 
-				st, err := tokenProcessor.Generate(userID)
+				st, err := tokenProcessor.Generate(userID, domain.RoleUser.String())
 				if err != nil {
 					assert.Error(t, err)
 				}
@@ -600,12 +740,12 @@ func TestDog_Matches(t *testing.T) {
 			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
 				assert.Equal(t, http.StatusOK, recorder.Code)
 
-				var resp messages.DogListResponseBody
+				var resp messages.DogPageResponseBody
 				if err := json.Unmarshal(recorder.Body.Bytes(), &resp); err != nil {
 					assert.Error(t, err)
 				}
 
-				assert.Equal(t, mList, resp)
+				assert.Equal(t, messages.DogPageResponseBody{Dogs: mList}, resp)
 			},
 		},
 	}
@@ -628,12 +768,12 @@ func TestDog_Create(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	controller := gomock.NewController(t)
-	tokenProcessor := token.NewJWT("test-secret", time.Minute*5)
+	tokenProcessor := token.NewJWT(token.NewHMACKeySet("test-secret"), time.Minute*5)
 
 	mockDogUsecase := NewMockDogUsecase(controller)
 	mockIdentityExtractor := NewMockIdentityExtractor(controller)
 	mockPaginator := NewMockPaginator(controller)
-	authMiddleware := NewAuthMiddleware(tokenProcessor)
+	authMiddleware := newTestAuthMiddleware(controller, tokenProcessor)
 
 	userID := uuid.New()
 	wrongDogRequestBody := messages.CreateOrUpdateDogRequestBody{
@@ -641,7 +781,6 @@ func TestDog_Create(t *testing.T) {
 		Sex:   "unknown",
 		Age:   40,
 		Breed: "test",
-		Image: "http://test.com/dog1.jpeg",
 	}
 
 	validDogRequestBody := messages.CreateOrUpdateDogRequestBody{
@@ -649,16 +788,17 @@ func TestDog_Create(t *testing.T) {
 		Sex:   "male",
 		Age:   15,
 		Breed: "test",
-		Image: "http://test.com/dog1.jpeg",
 	}
 
+	imageDogRequestBody := validDogRequestBody
+	imageDogRequestBody.Image = "http://test.com/dog1.jpeg"
+
 	domainDogIN := domain.Dog{
 		UserID: userID,
 		Name:   validDogRequestBody.Name,
 		Sex:    domain.DogSex(validDogRequestBody.Sex),
 		Age:    validDogRequestBody.Age,
 		Breed:  validDogRequestBody.Breed,
-		Image:  validDogRequestBody.Image,
 	}
 
 	domainDogOut := domain.Dog{
@@ -667,18 +807,19 @@ func TestDog_Create(t *testing.T) {
 		Name:      domainDogIN.Name,
 		Sex:       domainDogIN.Sex,
 		Age:       domainDogIN.Age,
-		Image:     domainDogIN.Image,
+		Photos:    []domain.DogPhoto{{ID: uuid.New(), Images: domain.ExternalDogImages("http://test.com/dog1.jpeg"), Primary: true}},
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
 
 	responseBody := messages.DogResponseBody{
-		ID:    domainDogOut.ID.String(),
-		Name:  domainDogOut.Name,
-		Sex:   domainDogOut.Sex.String(),
-		Age:   domainDogOut.Age,
-		Breed: domainDogOut.Breed,
-		Image: domainDogOut.Image,
+		ID:     domainDogOut.ID.String(),
+		Name:   domainDogOut.Name,
+		Sex:    domainDogOut.Sex.String(),
+		Age:    domainDogOut.Age,
+		Breed:  domainDogOut.Breed,
+		Images: messages.DogImagesResponseBody(domainDogOut.Photos[0].Images),
+		Photos: []messages.DogPhotoResponseBody{{ID: domainDogOut.Photos[0].ID.String(), Primary: true, Images: messages.DogImagesResponseBody(domainDogOut.Photos[0].Images)}},
 	}
 
 	type fields struct {
@@ -694,7 +835,7 @@ func TestDog_Create(t *testing.T) {
 		{
 			name: "request body validation error",
 			fields: fields{
-				dog: NewDog(mockDogUsecase, mockIdentityExtractor, mockPaginator, authMiddleware.Auth),
+				dog: NewDog(mockDogUsecase, mockIdentityExtractor, mockPaginator, nil, maxImageSize, authMiddleware.Auth),
 			},
 			mocksInitFn: func() {
 
@@ -710,7 +851,39 @@ func TestDog_Create(t *testing.T) {
 					assert.Error(t, err)
 				}
 
-				st, err := tokenProcessor.Generate(userID)
+				st, err := tokenProcessor.Generate(userID, domain.RoleUser.String())
+				if err != nil {
+					assert.Error(t, err)
+				}
+
+				req.Header.Set(AuthorizationHeaderName, fmt.Sprintf("%s%s", bearerPrefix, st))
+
+				return req
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "image url is rejected",
+			fields: fields{
+				dog: NewDog(mockDogUsecase, mockIdentityExtractor, mockPaginator, nil, maxImageSize, authMiddleware.Auth),
+			},
+			mocksInitFn: func() {
+
+			},
+			getRequestFn: func() *http.Request {
+				b, err := json.Marshal(imageDogRequestBody)
+				if err != nil {
+					assert.Error(t, err)
+				}
+
+				req, err := http.NewRequest(http.MethodPost, "/api/dog", bytes.NewReader(b))
+				if err != nil {
+					assert.Error(t, err)
+				}
+
+				st, err := tokenProcessor.Generate(userID, domain.RoleUser.String())
 				if err != nil {
 					assert.Error(t, err)
 				}
@@ -726,7 +899,7 @@ func TestDog_Create(t *testing.T) {
 		{
 			name: "getting pagination error",
 			fields: fields{
-				dog: NewDog(mockDogUsecase, mockIdentityExtractor, mockPaginator, authMiddleware.Auth),
+				dog: NewDog(mockDogUsecase, mockIdentityExtractor, mockPaginator, nil, maxImageSize, authMiddleware.Auth),
 			},
 			mocksInitFn: func() {
 				err := ierr.New(ierr.Internal, "testing-error")
@@ -743,7 +916,7 @@ func TestDog_Create(t *testing.T) {
 					assert.Error(t, err)
 				}
 
-				st, err := tokenProcessor.Generate(userID)
+				st, err := tokenProcessor.Generate(userID, domain.RoleUser.String())
 				if err != nil {
 					assert.Error(t, err)
 				}
@@ -759,7 +932,7 @@ func TestDog_Create(t *testing.T) {
 		{
 			name: "usecase error",
 			fields: fields{
-				dog: NewDog(mockDogUsecase, mockIdentityExtractor, mockPaginator, authMiddleware.Auth),
+				dog: NewDog(mockDogUsecase, mockIdentityExtractor, mockPaginator, nil, maxImageSize, authMiddleware.Auth),
 			},
 			mocksInitFn: func() {
 				err := ierr.New(ierr.Internal, "testing-error")
@@ -777,7 +950,7 @@ func TestDog_Create(t *testing.T) {
 					assert.Error(t, err)
 				}
 
-				st, err := tokenProcessor.Generate(userID)
+				st, err := tokenProcessor.Generate(userID, domain.RoleUser.String())
 				if err != nil {
 					assert.Error(t, err)
 				}
@@ -793,7 +966,7 @@ func TestDog_Create(t *testing.T) {
 		{
 			name: "usecase error",
 			fields: fields{
-				dog: NewDog(mockDogUsecase, mockIdentityExtractor, mockPaginator, authMiddleware.Auth),
+				dog: NewDog(mockDogUsecase, mockIdentityExtractor, mockPaginator, nil, maxImageSize, authMiddleware.Auth),
 			},
 			mocksInitFn: func() {
 				mockIdentityExtractor.EXPECT().ExtractFromContext(gomock.Any()).Return(userID, nil)
@@ -810,7 +983,7 @@ func TestDog_Create(t *testing.T) {
 					assert.Error(t, err)
 				}
 
-				st, err := tokenProcessor.Generate(userID)
+				st, err := tokenProcessor.Generate(userID, domain.RoleUser.String())
 				if err != nil {
 					assert.Error(t, err)
 				}
@@ -845,16 +1018,111 @@ func TestDog_Create(t *testing.T) {
 	}
 }
 
+func TestDog_CreateWithLocation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	controller := gomock.NewController(t)
+	mockDogUsecase := NewMockDogUsecase(controller)
+	mockIdentityExtractor := NewMockIdentityExtractor(controller)
+
+	userID := uuid.New()
+	dogID := uuid.New()
+	distance := uint(3)
+
+	tests := []struct {
+		name              string
+		mocksInitFn       func()
+		body              string
+		resultAssertionFn func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:        "latitude without longitude",
+			mocksInitFn: func() {},
+			body:        `{"name":"dog1","sex":"male","age":2,"breed":"test","latitude":49.8397}`,
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:        "latitude out of range",
+			mocksInitFn: func() {},
+			body:        `{"name":"dog1","sex":"male","age":2,"breed":"test","latitude":91,"longitude":24.0297}`,
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "coordinates",
+			mocksInitFn: func() {
+				mockIdentityExtractor.EXPECT().ExtractFromContext(gomock.Any()).Return(userID, nil)
+				mockDogUsecase.EXPECT().Create(gomock.Any(), domain.Dog{
+					UserID:   userID,
+					Name:     "dog1",
+					Sex:      domain.Male,
+					Age:      2,
+					Breed:    "test",
+					Location: &domain.GeoPoint{Latitude: 49.8397, Longitude: 24.0297},
+				}).Return(domain.Dog{ID: dogID, Location: &domain.GeoPoint{Latitude: 49.8397, Longitude: 24.0297}}, nil)
+			},
+			body: `{"name":"dog1","sex":"male","age":2,"breed":"test","latitude":49.8397,"longitude":24.0297}`,
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.NotContains(t, recorder.Body.String(), "49.8397")
+			},
+		},
+		{
+			name: "city",
+			mocksInitFn: func() {
+				mockIdentityExtractor.EXPECT().ExtractFromContext(gomock.Any()).Return(userID, nil)
+				mockDogUsecase.EXPECT().Create(gomock.Any(), domain.Dog{
+					UserID: userID,
+					Name:   "dog1",
+					Sex:    domain.Male,
+					Age:    2,
+					Breed:  "test",
+					City:   "lviv",
+				}).Return(domain.Dog{ID: dogID, City: "Lviv", Distance: &distance}, nil)
+			},
+			body: `{"name":"dog1","sex":"male","age":2,"breed":"test","city":"lviv"}`,
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var body messages.DogResponseBody
+				assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+				assert.Equal(t, "Lviv", body.City)
+				assert.Equal(t, &distance, body.DistanceKm)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInitFn()
+
+			recorder := httptest.NewRecorder()
+			_, engine := gin.CreateTestContext(recorder)
+			engine = InitRoutes(engine, NewDog(mockDogUsecase, mockIdentityExtractor, nil, nil, maxImageSize))
+
+			req, err := http.NewRequest(http.MethodPost, "/api/dog", strings.NewReader(tt.body))
+			assert.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+
+			engine.ServeHTTP(recorder, req)
+			tt.resultAssertionFn(recorder)
+		})
+	}
+}
+
 func TestDog_Update(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	controller := gomock.NewController(t)
-	tokenProcessor := token.NewJWT("test-secret", time.Minute*5)
+	tokenProcessor := token.NewJWT(token.NewHMACKeySet("test-secret"), time.Minute*5)
 
 	mockDogUsecase := NewMockDogUsecase(controller)
 	mockIdentityExtractor := NewMockIdentityExtractor(controller)
 	mockPaginator := NewMockPaginator(controller)
-	authMiddleware := NewAuthMiddleware(tokenProcessor)
+	authMiddleware := newTestAuthMiddleware(controller, tokenProcessor)
 
 	userID := uuid.New()
 	dogID := uuid.New()
@@ -863,7 +1131,6 @@ func TestDog_Update(t *testing.T) {
 		Sex:   "unknown",
 		Age:   40,
 		Breed: "test",
-		Image: "http://test.com/dog1.jpeg",
 	}
 
 	validDogRequestBody := messages.CreateOrUpdateDogRequestBody{
@@ -871,16 +1138,17 @@ func TestDog_Update(t *testing.T) {
 		Sex:   "male",
 		Age:   15,
 		Breed: "test",
-		Image: "http://test.com/dog1.jpeg",
 	}
 
+	imageDogRequestBody := validDogRequestBody
+	imageDogRequestBody.Image = "http://test.com/dog1.jpeg"
+
 	domainDogIN := domain.Dog{
 		UserID: userID,
 		Name:   validDogRequestBody.Name,
 		Sex:    domain.DogSex(validDogRequestBody.Sex),
 		Age:    validDogRequestBody.Age,
 		Breed:  validDogRequestBody.Breed,
-		Image:  validDogRequestBody.Image,
 	}
 
 	domainDogOut := domain.Dog{
@@ -889,18 +1157,19 @@ func TestDog_Update(t *testing.T) {
 		Name:      domainDogIN.Name,
 		Sex:       domainDogIN.Sex,
 		Age:       domainDogIN.Age,
-		Image:     domainDogIN.Image,
+		Photos:    []domain.DogPhoto{{ID: uuid.New(), Images: domain.ExternalDogImages("http://test.com/dog1.jpeg"), Primary: true}},
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
 
 	responseBody := messages.DogResponseBody{
-		ID:    domainDogOut.ID.String(),
-		Name:  domainDogOut.Name,
-		Sex:   domainDogOut.Sex.String(),
-		Age:   domainDogOut.Age,
-		Breed: domainDogOut.Breed,
-		Image: domainDogOut.Image,
+		ID:     domainDogOut.ID.String(),
+		Name:   domainDogOut.Name,
+		Sex:    domainDogOut.Sex.String(),
+		Age:    domainDogOut.Age,
+		Breed:  domainDogOut.Breed,
+		Images: messages.DogImagesResponseBody(domainDogOut.Photos[0].Images),
+		Photos: []messages.DogPhotoResponseBody{{ID: domainDogOut.Photos[0].ID.String(), Primary: true, Images: messages.DogImagesResponseBody(domainDogOut.Photos[0].Images)}},
 	}
 
 	type fields struct {
@@ -916,7 +1185,7 @@ func TestDog_Update(t *testing.T) {
 		{
 			name: "getting dog id from params error",
 			fields: fields{
-				dog: NewDog(mockDogUsecase, mockIdentityExtractor, mockPaginator, authMiddleware.Auth),
+				dog: NewDog(mockDogUsecase, mockIdentityExtractor, mockPaginator, nil, maxImageSize, authMiddleware.Auth),
 			},
 			mocksInitFn: func() {
 
@@ -932,7 +1201,7 @@ func TestDog_Update(t *testing.T) {
 					assert.Error(t, err)
 				}
 
-				st, err := tokenProcessor.Generate(userID)
+				st, err := tokenProcessor.Generate(userID, domain.RoleUser.String())
 				if err != nil {
 					assert.Error(t, err)
 				}
@@ -948,7 +1217,7 @@ func TestDog_Update(t *testing.T) {
 		{
 			name: "request body validation error",
 			fields: fields{
-				dog: NewDog(mockDogUsecase, mockIdentityExtractor, mockPaginator, authMiddleware.Auth),
+				dog: NewDog(mockDogUsecase, mockIdentityExtractor, mockPaginator, nil, maxImageSize, authMiddleware.Auth),
 			},
 			mocksInitFn: func() {
 
@@ -964,7 +1233,39 @@ func TestDog_Update(t *testing.T) {
 					assert.Error(t, err)
 				}
 
-				st, err := tokenProcessor.Generate(userID)
+				st, err := tokenProcessor.Generate(userID, domain.RoleUser.String())
+				if err != nil {
+					assert.Error(t, err)
+				}
+
+				req.Header.Set(AuthorizationHeaderName, fmt.Sprintf("%s%s", bearerPrefix, st))
+
+				return req
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "image url is rejected",
+			fields: fields{
+				dog: NewDog(mockDogUsecase, mockIdentityExtractor, mockPaginator, nil, maxImageSize, authMiddleware.Auth),
+			},
+			mocksInitFn: func() {
+
+			},
+			getRequestFn: func() *http.Request {
+				b, err := json.Marshal(imageDogRequestBody)
+				if err != nil {
+					assert.Error(t, err)
+				}
+
+				req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("/api/dog/%s", dogID.String()), bytes.NewReader(b))
+				if err != nil {
+					assert.Error(t, err)
+				}
+
+				st, err := tokenProcessor.Generate(userID, domain.RoleUser.String())
 				if err != nil {
 					assert.Error(t, err)
 				}
@@ -980,7 +1281,7 @@ func TestDog_Update(t *testing.T) {
 		{
 			name: "identity extractor error",
 			fields: fields{
-				dog: NewDog(mockDogUsecase, mockIdentityExtractor, mockPaginator, authMiddleware.Auth),
+				dog: NewDog(mockDogUsecase, mockIdentityExtractor, mockPaginator, nil, maxImageSize, authMiddleware.Auth),
 			},
 			mocksInitFn: func() {
 				err := ierr.New(ierr.Internal, "testing-error")
@@ -997,7 +1298,7 @@ func TestDog_Update(t *testing.T) {
 					assert.Error(t, err)
 				}
 
-				st, err := tokenProcessor.Generate(userID)
+				st, err := tokenProcessor.Generate(userID, domain.RoleUser.String())
 				if err != nil {
 					assert.Error(t, err)
 				}
@@ -1013,7 +1314,7 @@ func TestDog_Update(t *testing.T) {
 		{
 			name: "usecase error",
 			fields: fields{
-				dog: NewDog(mockDogUsecase, mockIdentityExtractor, mockPaginator, authMiddleware.Auth),
+				dog: NewDog(mockDogUsecase, mockIdentityExtractor, mockPaginator, nil, maxImageSize, authMiddleware.Auth),
 			},
 			mocksInitFn: func() {
 				err := ierr.New(ierr.Internal, "testing-error")
@@ -1031,7 +1332,7 @@ func TestDog_Update(t *testing.T) {
 					assert.Error(t, err)
 				}
 
-				st, err := tokenProcessor.Generate(userID)
+				st, err := tokenProcessor.Generate(userID, domain.RoleUser.String())
 				if err != nil {
 					assert.Error(t, err)
 				}
@@ -1047,7 +1348,7 @@ func TestDog_Update(t *testing.T) {
 		{
 			name: "usecase error",
 			fields: fields{
-				dog: NewDog(mockDogUsecase, mockIdentityExtractor, mockPaginator, authMiddleware.Auth),
+				dog: NewDog(mockDogUsecase, mockIdentityExtractor, mockPaginator, nil, maxImageSize, authMiddleware.Auth),
 			},
 			mocksInitFn: func() {
 				mockIdentityExtractor.EXPECT().ExtractFromContext(gomock.Any()).Return(userID, nil)
@@ -1064,7 +1365,7 @@ func TestDog_Update(t *testing.T) {
 					assert.Error(t, err)
 				}
 
-				st, err := tokenProcessor.Generate(userID)
+				st, err := tokenProcessor.Generate(userID, domain.RoleUser.String())
 				if err != nil {
 					assert.Error(t, err)
 				}
@@ -1103,12 +1404,12 @@ func TestDog_Delete(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	controller := gomock.NewController(t)
-	tokenProcessor := token.NewJWT("test-secret", time.Minute*5)
+	tokenProcessor := token.NewJWT(token.NewHMACKeySet("test-secret"), time.Minute*5)
 
 	mockDogUsecase := NewMockDogUsecase(controller)
 	mockIdentityExtractor := NewMockIdentityExtractor(controller)
 	mockPaginator := NewMockPaginator(controller)
-	authMiddleware := NewAuthMiddleware(tokenProcessor)
+	authMiddleware := newTestAuthMiddleware(controller, tokenProcessor)
 
 	userID := uuid.New()
 	dogID := uuid.New()
@@ -1126,7 +1427,7 @@ func TestDog_Delete(t *testing.T) {
 		{
 			name: "getting dog id from params error",
 			fields: fields{
-				dog: NewDog(mockDogUsecase, mockIdentityExtractor, mockPaginator, authMiddleware.Auth),
+				dog: NewDog(mockDogUsecase, mockIdentityExtractor, mockPaginator, nil, maxImageSize, authMiddleware.Auth),
 			},
 			mocksInitFn: func() {},
 			getRequestFn: func() *http.Request {
@@ -1135,7 +1436,7 @@ func TestDog_Delete(t *testing.T) {
 					assert.Error(t, err)
 				}
 
-				st, err := tokenProcessor.Generate(userID)
+				st, err := tokenProcessor.Generate(userID, domain.RoleUser.String())
 				if err != nil {
 					assert.Error(t, err)
 				}
@@ -1151,7 +1452,7 @@ func TestDog_Delete(t *testing.T) {
 		{
 			name: "identity extractor error",
 			fields: fields{
-				dog: NewDog(mockDogUsecase, mockIdentityExtractor, mockPaginator, authMiddleware.Auth),
+				dog: NewDog(mockDogUsecase, mockIdentityExtractor, mockPaginator, nil, maxImageSize, authMiddleware.Auth),
 			},
 			mocksInitFn: func() {
 				err := ierr.New(ierr.Internal, "testing-error")
@@ -1163,7 +1464,7 @@ func TestDog_Delete(t *testing.T) {
 					assert.Error(t, err)
 				}
 
-				st, err := tokenProcessor.Generate(userID)
+				st, err := tokenProcessor.Generate(userID, domain.RoleUser.String())
 				if err != nil {
 					assert.Error(t, err)
 				}
@@ -1179,7 +1480,7 @@ func TestDog_Delete(t *testing.T) {
 		{
 			name: "usecase error",
 			fields: fields{
-				dog: NewDog(mockDogUsecase, mockIdentityExtractor, mockPaginator, authMiddleware.Auth),
+				dog: NewDog(mockDogUsecase, mockIdentityExtractor, mockPaginator, nil, maxImageSize, authMiddleware.Auth),
 			},
 			mocksInitFn: func() {
 				err := ierr.New(ierr.Internal, "testing-error")
@@ -1192,7 +1493,7 @@ func TestDog_Delete(t *testing.T) {
 					assert.Error(t, err)
 				}
 
-				st, err := tokenProcessor.Generate(userID)
+				st, err := tokenProcessor.Generate(userID, domain.RoleUser.String())
 				if err != nil {
 					assert.Error(t, err)
 				}
@@ -1208,7 +1509,7 @@ func TestDog_Delete(t *testing.T) {
 		{
 			name: "usecase error",
 			fields: fields{
-				dog: NewDog(mockDogUsecase, mockIdentityExtractor, mockPaginator, authMiddleware.Auth),
+				dog: NewDog(mockDogUsecase, mockIdentityExtractor, mockPaginator, nil, maxImageSize, authMiddleware.Auth),
 			},
 			mocksInitFn: func() {
 				mockIdentityExtractor.EXPECT().ExtractFromContext(gomock.Any()).Return(userID, nil)
@@ -1220,7 +1521,7 @@ func TestDog_Delete(t *testing.T) {
 					assert.Error(t, err)
 				}
 
-				st, err := tokenProcessor.Generate(userID)
+				st, err := tokenProcessor.Generate(userID, domain.RoleUser.String())
 				if err != nil {
 					assert.Error(t, err)
 				}
@@ -1253,12 +1554,12 @@ func TestDog_Reaction(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	controller := gomock.NewController(t)
-	tokenProcessor := token.NewJWT("test-secret", time.Minute*5)
+	tokenProcessor := token.NewJWT(token.NewHMACKeySet("test-secret"), time.Minute*5)
 
 	mockDogUsecase := NewMockDogUsecase(controller)
 	mockIdentityExtractor := NewMockIdentityExtractor(controller)
 	mockPaginator := NewMockPaginator(controller)
-	authMiddleware := NewAuthMiddleware(tokenProcessor)
+	authMiddleware := newTestAuthMiddleware(controller, tokenProcessor)
 
 	userID := uuid.New()
 	//dogID := uuid.New()
@@ -1297,7 +1598,7 @@ func TestDog_Reaction(t *testing.T) {
 		{
 			name: "request body validation error",
 			fields: fields{
-				dog: NewDog(mockDogUsecase, mockIdentityExtractor, mockPaginator, authMiddleware.Auth),
+				dog: NewDog(mockDogUsecase, mockIdentityExtractor, mockPaginator, nil, maxImageSize, authMiddleware.Auth),
 			},
 			mocksInitFn: func() {},
 			getRequestFn: func() *http.Request {
@@ -1311,7 +1612,7 @@ func TestDog_Reaction(t *testing.T) {
 					assert.Error(t, err)
 				}
 
-				st, err := tokenProcessor.Generate(userID)
+				st, err := tokenProcessor.Generate(userID, domain.RoleUser.String())
 				if err != nil {
 					assert.Error(t, err)
 				}
@@ -1327,7 +1628,7 @@ func TestDog_Reaction(t *testing.T) {
 		{
 			name: "identity extractor error",
 			fields: fields{
-				dog: NewDog(mockDogUsecase, mockIdentityExtractor, mockPaginator, authMiddleware.Auth),
+				dog: NewDog(mockDogUsecase, mockIdentityExtractor, mockPaginator, nil, maxImageSize, authMiddleware.Auth),
 			},
 			mocksInitFn: func() {
 				err := ierr.New(ierr.InvalidArgument, "test-error")
@@ -1344,7 +1645,7 @@ func TestDog_Reaction(t *testing.T) {
 					assert.Error(t, err)
 				}
 
-				st, err := tokenProcessor.Generate(userID)
+				st, err := tokenProcessor.Generate(userID, domain.RoleUser.String())
 				if err != nil {
 					assert.Error(t, err)
 				}
@@ -1360,7 +1661,7 @@ func TestDog_Reaction(t *testing.T) {
 		{
 			name: "usecase error",
 			fields: fields{
-				dog: NewDog(mockDogUsecase, mockIdentityExtractor, mockPaginator, authMiddleware.Auth),
+				dog: NewDog(mockDogUsecase, mockIdentityExtractor, mockPaginator, nil, maxImageSize, authMiddleware.Auth),
 			},
 			mocksInitFn: func() {
 				err := ierr.New(ierr.Internal, "testing-error")
@@ -1380,7 +1681,7 @@ func TestDog_Reaction(t *testing.T) {
 					assert.Error(t, err)
 				}
 
-				st, err := tokenProcessor.Generate(userID)
+				st, err := tokenProcessor.Generate(userID, domain.RoleUser.String())
 				if err != nil {
 					assert.Error(t, err)
 				}
@@ -1396,7 +1697,7 @@ func TestDog_Reaction(t *testing.T) {
 		{
 			name: "usecase error",
 			fields: fields{
-				dog: NewDog(mockDogUsecase, mockIdentityExtractor, mockPaginator, authMiddleware.Auth),
+				dog: NewDog(mockDogUsecase, mockIdentityExtractor, mockPaginator, nil, maxImageSize, authMiddleware.Auth),
 			},
 			mocksInitFn: func() {
 				mockIdentityExtractor.EXPECT().ExtractFromContext(gomock.Any()).Return(userID, nil)
@@ -1414,7 +1715,7 @@ func TestDog_Reaction(t *testing.T) {
 					assert.Error(t, err)
 				}
 
-				st, err := tokenProcessor.Generate(userID)
+				st, err := tokenProcessor.Generate(userID, domain.RoleUser.String())
 				if err != nil {
 					assert.Error(t, err)
 				}
@@ -1442,3 +1743,414 @@ func TestDog_Reaction(t *testing.T) {
 		})
 	}
 }
+
+const maxImageSize = 1024
+
+// imageUploadMatcher matches uploaded image by detected content type and size.
+type imageUploadMatcher struct {
+	contentType string
+	size        int64
+}
+
+func (m imageUploadMatcher) Matches(x interface{}) bool {
+	image, ok := x.(domain.ImageUpload)
+	if !ok {
+		return false
+	}
+
+	return image.ContentType == m.contentType && image.Size == m.size
+}
+
+func (m imageUploadMatcher) String() string {
+	return fmt.Sprintf("is %s image of %d bytes", m.contentType, m.size)
+}
+
+func TestDog_UploadPhoto(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	controller := gomock.NewController(t)
+	mockDogUsecase := NewMockDogUsecase(controller)
+	mockIdentityExtractor := NewMockIdentityExtractor(controller)
+
+	userID := uuid.New()
+	dogID := uuid.New()
+	flaggedID := uuid.New()
+	png := append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{0}, 100)...)
+
+	getRequestFn := func(url, field string, content []byte) *http.Request {
+		var body bytes.Buffer
+		w := multipart.NewWriter(&body)
+
+		part, err := w.CreateFormFile(field, "spike.png")
+		assert.NoError(t, err)
+
+		_, err = part.Write(content)
+		assert.NoError(t, err)
+		assert.NoError(t, w.Close())
+
+		req, err := http.NewRequest(http.MethodPost, url, &body)
+		assert.NoError(t, err)
+
+		req.Header.Set("Content-Type", w.FormDataContentType())
+
+		return req
+	}
+
+	tests := []struct {
+		name              string
+		mocksInitFn       func()
+		getRequestFn      func() *http.Request
+		resultAssertionFn func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:        "wrong dog id",
+			mocksInitFn: func() {},
+			getRequestFn: func() *http.Request {
+				return getRequestFn("/api/dog/wrong/photos", "image", png)
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:        "missing image",
+			mocksInitFn: func() {},
+			getRequestFn: func() *http.Request {
+				return getRequestFn(fmt.Sprintf("/api/dog/%s/photos", dogID), "file", png)
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:        "request too large",
+			mocksInitFn: func() {},
+			getRequestFn: func() *http.Request {
+				return getRequestFn(fmt.Sprintf("/api/dog/%s/photos", dogID), "image", bytes.Repeat(png, multipartOverhead))
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "content type is detected",
+			mocksInitFn: func() {
+				mockIdentityExtractor.EXPECT().ExtractFromContext(gomock.Any()).Return(userID, nil)
+				mockDogUsecase.EXPECT().UploadPhoto(gomock.Any(), userID, dogID, imageUploadMatcher{contentType: "text/plain; charset=utf-8", size: 4}).
+					Return(domain.PhotoUpload{}, ierr.New(ierr.InvalidArgument, "image must be jpeg, png, gif or webp"))
+			},
+			getRequestFn: func() *http.Request {
+				return getRequestFn(fmt.Sprintf("/api/dog/%s/photos", dogID), "image", []byte("text"))
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "success",
+			mocksInitFn: func() {
+				mockIdentityExtractor.EXPECT().ExtractFromContext(gomock.Any()).Return(userID, nil)
+				mockDogUsecase.EXPECT().UploadPhoto(gomock.Any(), userID, dogID, imageUploadMatcher{contentType: "image/png", size: int64(len(png))}).
+					Return(domain.PhotoUpload{Dog: domain.Dog{ID: dogID, Name: "Spike", Photos: []domain.DogPhoto{{Images: domain.DogImages{Full: "http://localhost:8080/media/dogs/spike.jpg"}, Primary: true}}}}, nil)
+			},
+			getRequestFn: func() *http.Request {
+				return getRequestFn(fmt.Sprintf("/api/dog/%s/photos", dogID), "image", png)
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var body messages.DogResponseBody
+				assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+				assert.Equal(t, "http://localhost:8080/media/dogs/spike.jpg", body.Images.Full)
+			},
+		},
+		{
+			name: "photo is held for moderation",
+			mocksInitFn: func() {
+				mockIdentityExtractor.EXPECT().ExtractFromContext(gomock.Any()).Return(userID, nil)
+				mockDogUsecase.EXPECT().UploadPhoto(gomock.Any(), userID, dogID, imageUploadMatcher{contentType: "image/png", size: int64(len(png))}).
+					Return(domain.PhotoUpload{Flagged: &domain.FlaggedPhoto{ID: flaggedID, Status: domain.ReviewPending}}, nil)
+			},
+			getRequestFn: func() *http.Request {
+				return getRequestFn(fmt.Sprintf("/api/dog/%s/photos", dogID), "image", png)
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusAccepted, recorder.Code)
+
+				var body messages.HeldPhotoResponseBody
+				assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+				assert.Equal(t, flaggedID.String(), body.ID)
+				assert.Equal(t, "pending", body.Status)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInitFn()
+
+			recorder := httptest.NewRecorder()
+			_, engine := gin.CreateTestContext(recorder)
+			engine = InitRoutes(engine, NewDog(mockDogUsecase, mockIdentityExtractor, nil, nil, maxImageSize))
+
+			engine.ServeHTTP(recorder, tt.getRequestFn())
+			tt.resultAssertionFn(recorder)
+		})
+	}
+}
+
+func TestDog_ReorderPhotos(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	controller := gomock.NewController(t)
+	mockDogUsecase := NewMockDogUsecase(controller)
+	mockIdentityExtractor := NewMockIdentityExtractor(controller)
+
+	userID := uuid.New()
+	dogID := uuid.New()
+	photo1ID := uuid.New()
+	photo2ID := uuid.New()
+	dog := domain.Dog{
+		ID:   dogID,
+		Name: "Spike",
+		Photos: []domain.DogPhoto{
+			{ID: photo2ID, Images: domain.ExternalDogImages("http://test.com/2.jpeg")},
+			{ID: photo1ID, Images: domain.ExternalDogImages("http://test.com/1.jpeg"), Primary: true},
+		},
+	}
+
+	getRequestFn := func(body string) *http.Request {
+		req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("/api/dog/%s/photos", dogID), strings.NewReader(body))
+		assert.NoError(t, err)
+
+		return req
+	}
+
+	tests := []struct {
+		name              string
+		mocksInitFn       func()
+		getRequestFn      func() *http.Request
+		resultAssertionFn func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:        "wrong photo id",
+			mocksInitFn: func() {},
+			getRequestFn: func() *http.Request {
+				return getRequestFn(`{"photo_ids": ["wrong"]}`)
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "not every photo listed",
+			mocksInitFn: func() {
+				mockIdentityExtractor.EXPECT().ExtractFromContext(gomock.Any()).Return(userID, nil)
+				mockDogUsecase.EXPECT().ReorderPhotos(gomock.Any(), userID, dogID, []uuid.UUID{photo2ID}).
+					Return(domain.Dog{}, ierr.New(ierr.InvalidArgument, "every photo of the dog must be listed exactly once"))
+			},
+			getRequestFn: func() *http.Request {
+				return getRequestFn(fmt.Sprintf(`{"photo_ids": [%q]}`, photo2ID))
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "success",
+			mocksInitFn: func() {
+				mockIdentityExtractor.EXPECT().ExtractFromContext(gomock.Any()).Return(userID, nil)
+				mockDogUsecase.EXPECT().ReorderPhotos(gomock.Any(), userID, dogID, []uuid.UUID{photo2ID, photo1ID}).Return(dog, nil)
+			},
+			getRequestFn: func() *http.Request {
+				return getRequestFn(fmt.Sprintf(`{"photo_ids": [%q, %q]}`, photo2ID, photo1ID))
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var body messages.DogResponseBody
+				assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+				assert.Equal(t, "http://test.com/1.jpeg", body.Images.Full)
+				assert.Equal(t, []messages.DogPhotoResponseBody{
+					{ID: photo2ID.String(), Images: messages.DogImagesResponseBody(domain.ExternalDogImages("http://test.com/2.jpeg"))},
+					{ID: photo1ID.String(), Primary: true, Images: messages.DogImagesResponseBody(domain.ExternalDogImages("http://test.com/1.jpeg"))},
+				}, body.Photos)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInitFn()
+
+			recorder := httptest.NewRecorder()
+			_, engine := gin.CreateTestContext(recorder)
+			engine = InitRoutes(engine, NewDog(mockDogUsecase, mockIdentityExtractor, nil, nil, maxImageSize))
+
+			engine.ServeHTTP(recorder, tt.getRequestFn())
+			tt.resultAssertionFn(recorder)
+		})
+	}
+}
+
+func TestDog_SetPrimaryPhoto(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	controller := gomock.NewController(t)
+	mockDogUsecase := NewMockDogUsecase(controller)
+	mockIdentityExtractor := NewMockIdentityExtractor(controller)
+
+	userID := uuid.New()
+	dogID := uuid.New()
+	photoID := uuid.New()
+
+	tests := []struct {
+		name              string
+		mocksInitFn       func()
+		url               string
+		resultAssertionFn func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:        "wrong photo id",
+			mocksInitFn: func() {},
+			url:         fmt.Sprintf("/api/dog/%s/photos/wrong/primary", dogID),
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "photo not found",
+			mocksInitFn: func() {
+				mockIdentityExtractor.EXPECT().ExtractFromContext(gomock.Any()).Return(userID, nil)
+				mockDogUsecase.EXPECT().SetPrimaryPhoto(gomock.Any(), userID, dogID, photoID).
+					Return(domain.Dog{}, ierr.New(ierr.NotFound, "photo not found"))
+			},
+			url: fmt.Sprintf("/api/dog/%s/photos/%s/primary", dogID, photoID),
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "success",
+			mocksInitFn: func() {
+				mockIdentityExtractor.EXPECT().ExtractFromContext(gomock.Any()).Return(userID, nil)
+				mockDogUsecase.EXPECT().SetPrimaryPhoto(gomock.Any(), userID, dogID, photoID).
+					Return(domain.Dog{ID: dogID, Photos: []domain.DogPhoto{{ID: photoID, Primary: true}}}, nil)
+			},
+			url: fmt.Sprintf("/api/dog/%s/photos/%s/primary", dogID, photoID),
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var body messages.DogResponseBody
+				assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+				assert.Equal(t, []messages.DogPhotoResponseBody{{ID: photoID.String(), Primary: true}}, body.Photos)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInitFn()
+
+			recorder := httptest.NewRecorder()
+			_, engine := gin.CreateTestContext(recorder)
+			engine = InitRoutes(engine, NewDog(mockDogUsecase, mockIdentityExtractor, nil, nil, maxImageSize))
+
+			req, err := http.NewRequest(http.MethodPut, tt.url, nil)
+			assert.NoError(t, err)
+
+			engine.ServeHTTP(recorder, req)
+			tt.resultAssertionFn(recorder)
+		})
+	}
+}
+
+func TestDog_Preferences(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	controller := gomock.NewController(t)
+	mockDogUsecase := NewMockDogUsecase(controller)
+	mockIdentityExtractor := NewMockIdentityExtractor(controller)
+
+	userID := uuid.New()
+	dogID := uuid.New()
+	minAge, maxDistance := uint(1), uint(25)
+	prefs := domain.DogPreferences{Sex: domain.Female, MinAge: &minAge, Breeds: []string{"bulldog"}, MaxDistanceKm: &maxDistance}
+
+	tests := []struct {
+		name              string
+		mocksInitFn       func()
+		method            string
+		url               string
+		body              string
+		resultAssertionFn func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "get never set",
+			mocksInitFn: func() {
+				mockIdentityExtractor.EXPECT().ExtractFromContext(gomock.Any()).Return(userID, nil)
+				mockDogUsecase.EXPECT().Preferences(gomock.Any(), userID, dogID).Return(domain.DogPreferences{}, nil)
+			},
+			method: http.MethodGet,
+			url:    fmt.Sprintf("/api/dog/%s/preferences", dogID),
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.JSONEq(t, `{"breeds":[]}`, recorder.Body.String())
+			},
+		},
+		{
+			name: "get not your dog",
+			mocksInitFn: func() {
+				mockIdentityExtractor.EXPECT().ExtractFromContext(gomock.Any()).Return(userID, nil)
+				mockDogUsecase.EXPECT().Preferences(gomock.Any(), userID, dogID).
+					Return(domain.DogPreferences{}, ierr.New(ierr.PermissionDenied, "cannot get preferences of not your dog"))
+			},
+			method: http.MethodGet,
+			url:    fmt.Sprintf("/api/dog/%s/preferences", dogID),
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:        "update validation error",
+			mocksInitFn: func() {},
+			method:      http.MethodPut,
+			url:         fmt.Sprintf("/api/dog/%s/preferences", dogID),
+			body:        `{"sex":"unknown","breeds":[""]}`,
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "update",
+			mocksInitFn: func() {
+				mockIdentityExtractor.EXPECT().ExtractFromContext(gomock.Any()).Return(userID, nil)
+				mockDogUsecase.EXPECT().UpdatePreferences(gomock.Any(), userID, dogID, prefs).Return(prefs, nil)
+			},
+			method: http.MethodPut,
+			url:    fmt.Sprintf("/api/dog/%s/preferences", dogID),
+			body:   `{"sex":"female","min_age":1,"breeds":["bulldog"],"max_distance_km":25}`,
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.JSONEq(t, `{"sex":"female","min_age":1,"breeds":["bulldog"],"max_distance_km":25}`, recorder.Body.String())
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInitFn()
+
+			recorder := httptest.NewRecorder()
+			_, engine := gin.CreateTestContext(recorder)
+			engine = InitRoutes(engine, NewDog(mockDogUsecase, mockIdentityExtractor, nil, nil, maxImageSize))
+
+			req, err := http.NewRequest(tt.method, tt.url, strings.NewReader(tt.body))
+			assert.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+
+			engine.ServeHTTP(recorder, req)
+			tt.resultAssertionFn(recorder)
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package presenters
+
+import (
+	"net/http"
+
+	"github.com/valerii-smirnov/petli-test-task/internal/presenters/messages"
+	"github.com/valerii-smirnov/petli-test-task/pkg/utils/gin/resp"
+
+	"github.com/gin-gonic/gin"
+)
+
+type EmailVerification struct {
+	emailVerificationUsecase EmailVerificationUsecase
+
+	middlewares []gin.HandlerFunc
+}
+
+func NewEmailVerification(emailVerificationUsecase EmailVerificationUsecase, middlewares ...gin.HandlerFunc) *EmailVerification {
+	return &EmailVerification{
+		emailVerificationUsecase: emailVerificationUsecase,
+		middlewares:              middlewares,
+	}
+}
+
+func (v EmailVerification) Inject(r gin.IRouter) {
+	authGroup := r.Group("/auth")
+	if len(v.middlewares) > 0 {
+		authGroup.Use(v.middlewares...)
+	}
+
+	authGroup.GET("verify-email", v.VerifyEmail)
+	authGroup.POST("verify-email/resend", v.ResendVerification)
+}
+
+// VerifyEmail godoc
+// @Summary      Email verification
+// @Description  Consumes verification link sent to the user on sign-up
+// @ID 			 Verify email
+// @Tags         auth
+// @Produce      json
+// @Param 		 token query string true "verification token from the link"
+// @Success      204
+// @Failure      400  {object}  messages.BadRequestError
+// @Failure      500  {object}  messages.InternalServerError
+// @Router       /auth/verify-email [get]
+func (v EmailVerification) VerifyEmail(c *gin.Context) {
+	var req messages.VerifyEmailRequestQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	if err := v.emailVerificationUsecase.Verify(c, req.Token); err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	c.AbortWithStatus(http.StatusNoContent)
+}
+
+// ResendVerification godoc
+// @Summary      Verification email resending
+// @Description  Sends verification link again. Responds the same way whether email is registered or not.
+// @ID 			 Resend verification email
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param 		 input body messages.ResendVerificationRequestBody true "email to verify"
+// @Success      204
+// @Failure      400  {object}  messages.BadRequestError
+// @Failure      500  {object}  messages.InternalServerError
+// @Router       /auth/verify-email/resend [post]
+func (v EmailVerification) ResendVerification(c *gin.Context) {
+	var req messages.ResendVerificationRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	if err := v.emailVerificationUsecase.Resend(c, req.Email); err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	c.AbortWithStatus(http.StatusNoContent)
+}
@@ -0,0 +1,164 @@
+package presenters
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/valerii-smirnov/petli-test-task/internal/presenters/messages"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmailVerification_VerifyEmail(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	controller := gomock.NewController(t)
+	mockEmailVerificationUsecase := NewMockEmailVerificationUsecase(controller)
+
+	verificationToken := "signed-token"
+
+	tests := []struct {
+		name              string
+		mocksInitFn       func()
+		getRequestFn      func() *http.Request
+		resultAssertionFn func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:        "missing token",
+			mocksInitFn: func() {},
+			getRequestFn: func() *http.Request {
+				req, err := http.NewRequest(http.MethodGet, "/api/auth/verify-email", nil)
+				if err != nil {
+					assert.Error(t, err)
+				}
+
+				return req
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "invalid link",
+			mocksInitFn: func() {
+				mockEmailVerificationUsecase.EXPECT().Verify(gomock.Any(), gomock.Eq(verificationToken)).
+					Return(ierr.New(ierr.InvalidArgument, "invalid verification link"))
+			},
+			getRequestFn: func() *http.Request {
+				req, err := http.NewRequest(http.MethodGet, "/api/auth/verify-email?token="+verificationToken, nil)
+				if err != nil {
+					assert.Error(t, err)
+				}
+
+				return req
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "success",
+			mocksInitFn: func() {
+				mockEmailVerificationUsecase.EXPECT().Verify(gomock.Any(), gomock.Eq(verificationToken)).Return(nil)
+			},
+			getRequestFn: func() *http.Request {
+				req, err := http.NewRequest(http.MethodGet, "/api/auth/verify-email?token="+verificationToken, nil)
+				if err != nil {
+					assert.Error(t, err)
+				}
+
+				return req
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInitFn()
+
+			recorder := httptest.NewRecorder()
+			_, engine := gin.CreateTestContext(recorder)
+			engine = InitRoutes(engine, NewEmailVerification(mockEmailVerificationUsecase))
+
+			req := tt.getRequestFn()
+			engine.ServeHTTP(recorder, req)
+			tt.resultAssertionFn(recorder)
+		})
+	}
+}
+
+func TestEmailVerification_ResendVerification(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	controller := gomock.NewController(t)
+	mockEmailVerificationUsecase := NewMockEmailVerificationUsecase(controller)
+
+	email := "test@email.com"
+
+	getRequestFn := func(email string) *http.Request {
+		b, err := json.Marshal(messages.ResendVerificationRequestBody{Email: email})
+		if err != nil {
+			assert.Error(t, err)
+		}
+
+		req, err := http.NewRequest(http.MethodPost, "/api/auth/verify-email/resend", bytes.NewReader(b))
+		if err != nil {
+			assert.Error(t, err)
+		}
+
+		return req
+	}
+
+	tests := []struct {
+		name              string
+		mocksInitFn       func()
+		getRequestFn      func() *http.Request
+		resultAssertionFn func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:        "request body validation error",
+			mocksInitFn: func() {},
+			getRequestFn: func() *http.Request {
+				return getRequestFn("wrong-email")
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "success",
+			mocksInitFn: func() {
+				mockEmailVerificationUsecase.EXPECT().Resend(gomock.Any(), gomock.Eq(email)).Return(nil)
+			},
+			getRequestFn: func() *http.Request {
+				return getRequestFn(email)
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInitFn()
+
+			recorder := httptest.NewRecorder()
+			_, engine := gin.CreateTestContext(recorder)
+			engine = InitRoutes(engine, NewEmailVerification(mockEmailVerificationUsecase))
+
+			req := tt.getRequestFn()
+			engine.ServeHTTP(recorder, req)
+			tt.resultAssertionFn(recorder)
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package presenters
+
+import (
+	"net/http"
+
+	"github.com/valerii-smirnov/petli-test-task/internal/presenters/messages"
+
+	"github.com/gin-gonic/gin"
+)
+
+const jwksCacheControl = "public, max-age=300"
+
+// Keys presenter publishing public keys access tokens can be verified with.
+type Keys struct {
+	keysPublisher KeysPublisher
+}
+
+// NewKeys constructor.
+func NewKeys(keysPublisher KeysPublisher) *Keys {
+	return &Keys{
+		keysPublisher: keysPublisher,
+	}
+}
+
+// Inject Injector implementation. Keys are served outside of /api group, so it has to be injected into the engine.
+func (k Keys) Inject(r gin.IRouter) {
+	r.GET("/.well-known/jwks.json", k.JWKS)
+}
+
+// JWKS http handler func returning JSON Web Key Set of token signing keys.
+func (k Keys) JWKS(c *gin.Context) {
+	keys := k.keysPublisher.JWKS()
+
+	rb := messages.JWKSResponseBody{
+		Keys: make([]messages.JWK, 0, len(keys)),
+	}
+
+	for _, key := range keys {
+		rb.Keys = append(rb.Keys, messages.JWK{
+			KeyType:   key.KeyType,
+			KeyID:     key.KeyID,
+			Use:       key.Use,
+			Algorithm: key.Algorithm,
+			N:         key.N,
+			E:         key.E,
+			Curve:     key.Curve,
+			X:         key.X,
+		})
+	}
+
+	c.Header("Cache-Control", jwksCacheControl)
+	c.JSON(http.StatusOK, rb)
+}
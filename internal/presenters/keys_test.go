@@ -0,0 +1,67 @@
+package presenters
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/valerii-smirnov/petli-test-task/internal/presenters/messages"
+	"github.com/valerii-smirnov/petli-test-task/pkg/token"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeys_JWKS(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	controller := gomock.NewController(t)
+	mockKeysPublisher := NewMockKeysPublisher(controller)
+
+	keys := []token.JWK{
+		{
+			KeyType:   "OKP",
+			KeyID:     "2023-01",
+			Use:       "sig",
+			Algorithm: "EdDSA",
+			Curve:     "Ed25519",
+			X:         "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo",
+		},
+	}
+
+	mockKeysPublisher.EXPECT().JWKS().Return(keys)
+
+	recorder := httptest.NewRecorder()
+	_, engine := gin.CreateTestContext(recorder)
+	NewKeys(mockKeysPublisher).Inject(engine)
+
+	req, err := http.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+	if err != nil {
+		assert.Error(t, err)
+	}
+
+	engine.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, jwksCacheControl, recorder.Header().Get("Cache-Control"))
+
+	var rb messages.JWKSResponseBody
+	if err := json.Unmarshal(recorder.Body.Bytes(), &rb); err != nil {
+		assert.Error(t, err)
+	}
+
+	assert.Equal(t, messages.JWKSResponseBody{
+		Keys: []messages.JWK{
+			{
+				KeyType:   "OKP",
+				KeyID:     "2023-01",
+				Use:       "sig",
+				Algorithm: "EdDSA",
+				Curve:     "Ed25519",
+				X:         "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo",
+			},
+		},
+	}, rb)
+}
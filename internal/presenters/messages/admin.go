@@ -0,0 +1,5 @@
+package messages
+
+type AssignRoleRequestBody struct {
+	Role string `json:"role" binding:"required,oneof=user moderator admin" example:"moderator"`
+}
@@ -0,0 +1,25 @@
+package messages
+
+import "time"
+
+type CreateAPIKeyRequestBody struct {
+	Name   string   `json:"name" binding:"required,max=64" example:"ci"`
+	Scopes []string `json:"scopes" binding:"required,min=1,dive,oneof=read write" example:"read,write"`
+}
+
+type APIKeyResponseBody struct {
+	ID         string     `json:"id" example:"c23bca5a-640a-4f61-bb7b-5f69b1ede69d"`
+	Name       string     `json:"name" example:"ci"`
+	Prefix     string     `json:"prefix" example:"petly_Xk3hPq"`
+	Scopes     []string   `json:"scopes" example:"read,write"`
+	LastUsedAt *time.Time `json:"last_used_at" example:"2023-01-27T10:00:00Z"`
+	CreatedAt  time.Time  `json:"created_at" example:"2023-01-27T10:00:00Z"`
+}
+
+type APIKeyListResponseBody []APIKeyResponseBody
+
+// CreateAPIKeyResponseBody the key is shown only once, it can't be restored later.
+type CreateAPIKeyResponseBody struct {
+	APIKeyResponseBody
+	Key string `json:"key" example:"petly_Xk3hPq0vYc2l9Zr1eTgq8WmJb4sN6uDfA7oKiLpQxHw"`
+}
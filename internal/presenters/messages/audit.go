@@ -0,0 +1,29 @@
+package messages
+
+import "time"
+
+// AuditEventResponseBody user_id is empty if the actor isn't known, e.g. sign-in with unknown email.
+type AuditEventResponseBody struct {
+	ID        string    `json:"id" example:"c23bca5a-640a-4f61-bb7b-5f69b1ede69d"`
+	UserID    string    `json:"user_id,omitempty" example:"5b8f1b2e-3f4a-4c4e-9d7a-1f2e3d4c5b6a"`
+	Email     string    `json:"email,omitempty" example:"your@email.com"`
+	Type      string    `json:"type" example:"sign_in"`
+	Outcome   string    `json:"outcome" example:"failure"`
+	Reason    string    `json:"reason,omitempty" example:"user not found"`
+	ClientIP  string    `json:"client_ip" example:"192.168.0.10"`
+	UserAgent string    `json:"user_agent" example:"Mozilla/5.0"`
+	CreatedAt time.Time `json:"created_at" example:"2023-02-06T10:00:00Z"`
+}
+
+type AuditEventListResponseBody []AuditEventResponseBody
+
+// AuditEventSearchRequestQuery from and to are RFC 3339 timestamps, events created in [from, to) are returned.
+type AuditEventSearchRequestQuery struct {
+	UserID  string    `form:"user_id" binding:"omitempty,uuid"`
+	Email   string    `form:"email" binding:"omitempty,email"`
+	Type    string    `form:"type" binding:"omitempty,oneof=sign_up sign_in sign_in_second_factor sign_in_external token_refresh password_change password_reset"`
+	Outcome string    `form:"outcome" binding:"omitempty,oneof=success failure mfa_required"`
+	IP      string    `form:"ip" binding:"omitempty,ip"`
+	From    time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To      time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+}
@@ -0,0 +1,12 @@
+package messages
+
+import "time"
+
+// DataExportResponseBody archive can be downloaded once status is ready, until expires_at.
+type DataExportResponseBody struct {
+	ID          string     `json:"id" example:"c23bca5a-640a-4f61-bb7b-5f69b1ede69d"`
+	Status      string     `json:"status" example:"pending" enums:"pending,processing,ready,failed"`
+	CreatedAt   time.Time  `json:"created_at" example:"2023-02-03T10:00:00Z"`
+	CompletedAt *time.Time `json:"completed_at" example:"2023-02-03T10:01:00Z"`
+	ExpiresAt   *time.Time `json:"expires_at" example:"2023-02-04T10:01:00Z"`
+}
@@ -6,17 +6,74 @@ type DogResponseBody struct {
 	Sex   string `json:"sex" example:"male|female"`
 	Age   uint   `json:"age" example:"5"`
 	Breed string `json:"breed" example:"Bulldog"`
-	Image string `json:"image" example:"https://cdn.w600.comps.canstockphoto.com/shepherd-cartoon-dog-vector-clipart_csp67503106.jpg"`
+	// Images of the primary photo.
+	Images DogImagesResponseBody  `json:"images"`
+	Photos []DogPhotoResponseBody `json:"photos"`
+	City   string                 `json:"city,omitempty" example:"Lviv"`
+	// DistanceKm to the point dogs are searched near, rounded up to whole km, exact location is never returned.
+	DistanceKm *uint `json:"distance_km,omitempty" example:"3"`
+}
+
+type DogPhotoResponseBody struct {
+	ID      string                `json:"id" example:"6e1a4c7e-4a4b-4b8e-9f0c-1a2b3c4d5e6f"`
+	Primary bool                  `json:"primary" example:"true"`
+	Images  DogImagesResponseBody `json:"images"`
+}
+
+// DogImagesResponseBody URLs of the image renditions. Thumb fits 200x200, card 640x640 and full 1600x1600.
+// Image set by URL before uploads has no renditions, the same URL is returned for all of them.
+type DogImagesResponseBody struct {
+	Thumb string `json:"thumb" example:"http://localhost:8080/media/dogs/c23bca5a-640a-4f61-bb7b-5f69b1ede69d/6e1a4c7e-4a4b-4b8e-9f0c-1a2b3c4d5e6f/thumb.jpg"`
+	Card  string `json:"card" example:"http://localhost:8080/media/dogs/c23bca5a-640a-4f61-bb7b-5f69b1ede69d/6e1a4c7e-4a4b-4b8e-9f0c-1a2b3c4d5e6f/card.jpg"`
+	Full  string `json:"full" example:"http://localhost:8080/media/dogs/c23bca5a-640a-4f61-bb7b-5f69b1ede69d/6e1a4c7e-4a4b-4b8e-9f0c-1a2b3c4d5e6f/full.jpg"`
 }
 
 type DogListResponseBody []DogResponseBody
 
+// DogPageResponseBody page of dogs. NextCursor is passed as cursor param to get the next page,
+// it's missing on the last page and for lists which aren't sorted by creation time.
+type DogPageResponseBody struct {
+	Dogs       DogListResponseBody `json:"dogs"`
+	NextCursor string              `json:"next_cursor,omitempty" example:"MTY3NjYyODgwMDAwMDAwMDAwMCxjMjNiY2E1YS02NDBhLTRmNjEtYmI3Yi01ZjY5YjFlZGU2OWQ"`
+}
+
+// CreateOrUpdateDogRequestBody photos are uploaded with POST /dog/{id}/photos, update keeps them as they are.
+// Image URLs aren't accepted anymore, the field is kept only to reject them instead of silently dropping.
+// Location is optional too: latitude and longitude, or a city, e.g. "Lviv" or "Lviv, UA", to look coordinates up.
+// Update without location removes it.
 type CreateOrUpdateDogRequestBody struct {
-	Name  string `json:"name" binding:"required,min=3,max=30" example:"Spike"`
-	Sex   string `json:"sex" binding:"required,oneof=male female" example:"male|female"`
-	Age   uint   `json:"age" binding:"required,min=0,max=30" example:"5"`
-	Breed string `json:"breed" binding:"required,max=30" example:"Bulldog"`
-	Image string `json:"image" binding:"required,url,max=1024" example:"https://cdn.w600.comps.canstockphoto.com/shepherd-cartoon-dog-vector-clipart_csp67503106.jpg"`
+	Name      string   `json:"name" binding:"required,min=3,max=30" example:"Spike"`
+	Sex       string   `json:"sex" binding:"required,oneof=male female" example:"male|female"`
+	Age       uint     `json:"age" binding:"required,min=0,max=30" example:"5"`
+	Breed     string   `json:"breed" binding:"required,max=30" example:"Bulldog"`
+	Image     string   `json:"image" swaggerignore:"true"`
+	City      string   `json:"city" binding:"omitempty,max=100" example:"Lviv"`
+	Latitude  *float64 `json:"latitude" binding:"required_with=Longitude,omitempty,min=-90,max=90" example:"49.8397"`
+	Longitude *float64 `json:"longitude" binding:"required_with=Latitude,omitempty,min=-180,max=180" example:"24.0297"`
+}
+
+// ReorderDogPhotosRequestBody every photo of the dog in the new order.
+type ReorderDogPhotosRequestBody struct {
+	PhotoIDs []string `json:"photo_ids" binding:"required,min=1,dive,uuid" example:"6e1a4c7e-4a4b-4b8e-9f0c-1a2b3c4d5e6f"`
+}
+
+// DogPreferencesRequestBody what the dog is looking for, omitted fields match any dog.
+// Max distance needs location of the dog.
+type DogPreferencesRequestBody struct {
+	Sex           string   `json:"sex" binding:"omitempty,oneof=male female" example:"female"`
+	MinAge        *uint    `json:"min_age" binding:"omitempty,max=30" example:"1"`
+	MaxAge        *uint    `json:"max_age" binding:"omitempty,max=30" example:"6"`
+	Breeds        []string `json:"breeds" binding:"max=20,dive,required,max=30" example:"Bulldog,Poodle"`
+	MaxDistanceKm *uint    `json:"max_distance_km" binding:"omitempty,min=1,max=20000" example:"25"`
+}
+
+// DogPreferencesResponseBody what the dog is looking for, empty fields match any dog. Breeds are lowercase.
+type DogPreferencesResponseBody struct {
+	Sex           string   `json:"sex,omitempty" example:"female"`
+	MinAge        *uint    `json:"min_age,omitempty" example:"1"`
+	MaxAge        *uint    `json:"max_age,omitempty" example:"6"`
+	Breeds        []string `json:"breeds" example:"bulldog,poodle"`
+	MaxDistanceKm *uint    `json:"max_distance_km,omitempty" example:"25"`
 }
 
 type ReactionRequestBody struct {
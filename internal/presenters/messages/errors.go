@@ -77,3 +77,15 @@ func NewConflictError(message string) *ConflictError {
 		Message: message,
 	}
 }
+
+type TooManyRequestsError struct {
+	Code    int    `json:"code" example:"429"`
+	Message string `json:"message" example:"too many failed sign-in attempts"`
+}
+
+func NewTooManyRequestsError(message string) TooManyRequestsError {
+	return TooManyRequestsError{
+		Code:    http.StatusTooManyRequests,
+		Message: message,
+	}
+}
@@ -0,0 +1,31 @@
+package messages
+
+import "time"
+
+// HeldPhotoResponseBody uploaded photo held for moderation instead of being added to the dog.
+type HeldPhotoResponseBody struct {
+	ID      string `json:"id" example:"c23bca5a-640a-4f61-bb7b-5f69b1ede69d"`
+	Status  string `json:"status" example:"pending"`
+	Message string `json:"message" example:"photo looks like a photo of another user's dog, it's added once a moderator approves it"`
+}
+
+// FlaggedPhotoResponseBody matched_photo_id and matched_dog_id are empty once the matched photo or its dog is deleted.
+// Distance is the number of bits hashes of the photos differ in, out of 64.
+type FlaggedPhotoResponseBody struct {
+	ID             string                `json:"id" example:"c23bca5a-640a-4f61-bb7b-5f69b1ede69d"`
+	DogID          string                `json:"dog_id" example:"5b8f1b2e-3f4a-4c4e-9d7a-1f2e3d4c5b6a"`
+	Images         DogImagesResponseBody `json:"images"`
+	MatchedPhotoID string                `json:"matched_photo_id,omitempty" example:"6e1a4c7e-4a4b-4b8e-9f0c-1a2b3c4d5e6f"`
+	MatchedDogID   string                `json:"matched_dog_id,omitempty" example:"0f4e2d1c-9b8a-4c7d-8e6f-5a4b3c2d1e0f"`
+	Distance       int                   `json:"distance" example:"3"`
+	Status         string                `json:"status" example:"pending"`
+	ReviewedBy     string                `json:"reviewed_by,omitempty" example:"7a6b5c4d-3e2f-4a1b-9c8d-7e6f5a4b3c2d"`
+	ReviewedAt     *time.Time            `json:"reviewed_at,omitempty" example:"2023-02-10T10:00:00Z"`
+	CreatedAt      time.Time             `json:"created_at" example:"2023-02-10T09:00:00Z"`
+}
+
+type FlaggedPhotoListResponseBody []FlaggedPhotoResponseBody
+
+type FlaggedPhotoListRequestQuery struct {
+	Status string `form:"status" binding:"omitempty,oneof=pending approved rejected"`
+}
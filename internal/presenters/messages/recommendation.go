@@ -0,0 +1,19 @@
+package messages
+
+// RecommendationResponseBody recommended dog, score is returned only with explain=true.
+type RecommendationResponseBody struct {
+	DogResponseBody
+	Score *ScoreBreakdownResponseBody `json:"score,omitempty"`
+}
+
+type RecommendationListResponseBody []RecommendationResponseBody
+
+// ScoreBreakdownResponseBody components of the score, each in [0, 1], total is their weighted sum.
+type ScoreBreakdownResponseBody struct {
+	Preference float64 `json:"preference" example:"0.75"`
+	Distance   float64 `json:"distance" example:"0.77"`
+	Recency    float64 `json:"recency" example:"0.9"`
+	LikeBack   float64 `json:"like_back" example:"0.5"`
+	Diversity  float64 `json:"diversity" example:"1"`
+	Total      float64 `json:"total" example:"3.57"`
+}
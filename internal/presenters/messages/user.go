@@ -0,0 +1,47 @@
+package messages
+
+import "time"
+
+// MeResponseBody deletion_scheduled_at is present only while account deletion is pending.
+type MeResponseBody struct {
+	ID                  string     `json:"id" example:"c23bca5a-640a-4f61-bb7b-5f69b1ede69d"`
+	Email               string     `json:"email" example:"your@email.com"`
+	EmailVerified       bool       `json:"email_verified" example:"true"`
+	Role                string     `json:"role" example:"user"`
+	TwoFactorEnabled    bool       `json:"two_factor_enabled" example:"false"`
+	DisplayName         string     `json:"display_name" example:"Spike's owner"`
+	City                string     `json:"city" example:"Kyiv"`
+	Bio                 string     `json:"bio" example:"Bulldogs only"`
+	AvatarURL           string     `json:"avatar_url" example:"https://example.com/avatar.png"`
+	RegisteredAt        time.Time  `json:"registered_at" example:"2023-01-30T10:00:00Z"`
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty" example:"2023-03-02T10:00:00Z"`
+}
+
+type UpdateProfileRequestBody struct {
+	DisplayName string `json:"display_name" binding:"max=64" example:"Spike's owner"`
+	City        string `json:"city" binding:"max=64" example:"Kyiv"`
+	Bio         string `json:"bio" binding:"max=500" example:"Bulldogs only"`
+	AvatarURL   string `json:"avatar_url" binding:"omitempty,url,max=1024" example:"https://example.com/avatar.png"`
+}
+
+type ChangePasswordRequestBody struct {
+	CurrentPassword string `json:"current_password" binding:"required" example:"yousupersecretpassword"`
+	NewPassword     string `json:"new_password" binding:"required" example:"yournewsupersecretpassword"`
+}
+
+type ChangeEmailRequestBody struct {
+	NewEmail        string `json:"new_email" binding:"required,email" example:"your.new@email.com"`
+	CurrentPassword string `json:"current_password" binding:"required" example:"yousupersecretpassword"`
+}
+
+type ConfirmEmailChangeRequestQuery struct {
+	Token string `form:"token" binding:"required"`
+}
+
+type DeleteAccountRequestBody struct {
+	CurrentPassword string `json:"current_password" binding:"required" example:"yousupersecretpassword"`
+}
+
+type DeleteAccountResponseBody struct {
+	DeletionScheduledAt time.Time `json:"deletion_scheduled_at" example:"2023-03-02T10:00:00Z"`
+}
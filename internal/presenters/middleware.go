@@ -1,8 +1,12 @@
 package presenters
 
 import (
+	"fmt"
+	"net/http"
 	"strings"
+	"time"
 
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
 	httpErr "github.com/valerii-smirnov/petli-test-task/pkg/errors/http"
 	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
 	"github.com/valerii-smirnov/petli-test-task/pkg/token"
@@ -16,18 +20,33 @@ import (
 const (
 	AuthorizationHeaderName = "Authorization"
 	bearerPrefix            = "Bearer "
+	apiKeyPrefix            = "ApiKey "
 
-	contextIdentityKey = "user-id"
+	contextIdentityKey    = "user-id"
+	contextRoleKey        = "user-role"
+	contextTokenClaimsKey = "token-claims"
 )
 
 type AuthMiddleware struct {
-	tokenParser TokenParser
+	tokenParser         TokenParser
+	revocationChecker   TokenRevocationChecker
+	apiKeyAuthenticator APIKeyAuthenticator
 }
 
-func NewAuthMiddleware(tokenParser TokenParser) *AuthMiddleware {
-	return &AuthMiddleware{tokenParser: tokenParser}
+func NewAuthMiddleware(
+	tokenParser TokenParser,
+	revocationChecker TokenRevocationChecker,
+	apiKeyAuthenticator APIKeyAuthenticator,
+) *AuthMiddleware {
+	return &AuthMiddleware{
+		tokenParser:         tokenParser,
+		revocationChecker:   revocationChecker,
+		apiKeyAuthenticator: apiKeyAuthenticator,
+	}
 }
 
+// Auth authenticates request either with access token (Authorization: Bearer <token>)
+// or with personal API key (Authorization: ApiKey <key>).
 func (m AuthMiddleware) Auth(c *gin.Context) {
 	bearer := c.GetHeader(AuthorizationHeaderName)
 	if bearer == "" {
@@ -35,6 +54,11 @@ func (m AuthMiddleware) Auth(c *gin.Context) {
 		return
 	}
 
+	if strings.HasPrefix(bearer, apiKeyPrefix) {
+		m.authAPIKey(c, strings.TrimPrefix(bearer, apiKeyPrefix))
+		return
+	}
+
 	t, err := m.tokenParser.Parse(strings.TrimPrefix(bearer, bearerPrefix))
 	if err != nil {
 		resp.AbortWithError(c, ierr.WrapCode(ierr.Unauthenticated, err, "parsing token error"))
@@ -59,7 +83,160 @@ func (m AuthMiddleware) Auth(c *gin.Context) {
 		return
 	}
 
+	tokenClaims, err := m.tokenClaims(uid, claims)
+	if err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	revoked, err := m.revocationChecker.IsRevoked(c, tokenClaims)
+	if err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	if revoked {
+		resp.AbortWithError(c, ierr.New(ierr.Unauthenticated, "token is revoked"))
+		return
+	}
+
 	c.Set(contextIdentityKey, uid)
+	c.Set(contextRoleKey, tokenClaims.Role)
+	c.Set(contextTokenClaimsKey, tokenClaims)
+
+	c.Next()
+}
+
+// authAPIKey authenticates request with API key. Keys never carry elevated roles,
+// and requests changing data require the key to have write scope.
+func (m AuthMiddleware) authAPIKey(c *gin.Context, key string) {
+	apiKey, err := m.apiKeyAuthenticator.Authenticate(c, key)
+	if err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	scope := domain.APIKeyScopeWrite
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		scope = domain.APIKeyScopeRead
+	}
+
+	if !apiKey.HasScope(scope) {
+		resp.AbortWithError(c, ierr.New(ierr.PermissionDenied, fmt.Sprintf("api key has no %s scope", scope)))
+		return
+	}
+
+	c.Set(contextIdentityKey, apiKey.UserID)
+	c.Set(contextRoleKey, domain.RoleUser)
+	// usecases read the role from storage, the mark caps it there as well.
+	c.Request = c.Request.WithContext(domain.ContextWithAPIKey(c.Request.Context()))
+
+	c.Next()
+}
+
+func (m AuthMiddleware) tokenClaims(uid uuid.UUID, claims jwt.MapClaims) (domain.TokenClaims, error) {
+	sjti, ok := claims[token.TokenIDClaimName].(string)
+	if !ok {
+		return domain.TokenClaims{}, ierr.New(ierr.Unauthenticated, "getting token id from token claims error")
+	}
+
+	jti, err := uuid.Parse(sjti)
+	if err != nil {
+		return domain.TokenClaims{}, ierr.WrapCode(ierr.Unauthenticated, err, "parsing token id error")
+	}
+
+	iat, ok := claims[token.IssuedAtClaimName].(float64)
+	if !ok {
+		return domain.TokenClaims{}, ierr.New(ierr.Unauthenticated, "getting issued at from token claims error")
+	}
+
+	exp, ok := claims[token.ExpirationTimeClaimName].(float64)
+	if !ok {
+		return domain.TokenClaims{}, ierr.New(ierr.Unauthenticated, "getting expiration time from token claims error")
+	}
+
+	// tokens issued before roles were introduced have no role claim.
+	role := domain.RoleUser
+	if srole, ok := claims[token.RoleClaimName].(string); ok && srole != "" {
+		role = domain.Role(srole)
+	}
+
+	return domain.TokenClaims{
+		ID:        jti,
+		UserID:    uid,
+		Role:      role,
+		IssuedAt:  time.Unix(int64(iat), 0),
+		ExpiresAt: time.Unix(int64(exp), 0),
+	}, nil
+}
+
+// RequirePermission returns middleware which lets through only users whose role is granted
+// at least one of the permissions. It must be used after AuthMiddleware.Auth.
+func RequirePermission(permissions ...domain.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, err := roleFromContext(c)
+		if err != nil {
+			resp.AbortWithError(c, err)
+			return
+		}
+
+		for _, permission := range permissions {
+			if role.Can(permission) {
+				c.Next()
+				return
+			}
+		}
+
+		resp.AbortWithError(c, ierr.New(ierr.PermissionDenied, "you don't have permission to perform this action"))
+	}
+}
+
+// RequireAccessToken rejects requests authenticated with API key, e.g. so API keys can't be used to manage API keys.
+// It must be used after AuthMiddleware.Auth.
+func RequireAccessToken(c *gin.Context) {
+	if _, ok := c.Get(contextTokenClaimsKey); !ok {
+		resp.AbortWithError(c, ierr.New(ierr.PermissionDenied, "action requires signing in, api key can't be used"))
+		return
+	}
+
+	c.Next()
+}
+
+// roleFromContext returns role of the user request was authenticated for.
+func roleFromContext(c *gin.Context) (domain.Role, error) {
+	v, ok := c.Get(contextRoleKey)
+	if !ok {
+		return "", ierr.New(ierr.Unauthenticated, "request is not authenticated")
+	}
+
+	role, ok := v.(domain.Role)
+	if !ok {
+		return "", ierr.New(ierr.Internal, "casting user role error")
+	}
+
+	return role, nil
+}
+
+// tokenClaimsFromContext returns claims of the access token request was authenticated with.
+func tokenClaimsFromContext(c *gin.Context) (domain.TokenClaims, error) {
+	v, ok := c.Get(contextTokenClaimsKey)
+	if !ok {
+		return domain.TokenClaims{}, ierr.New(ierr.Unauthenticated, "request is not authenticated with access token")
+	}
+
+	claims, ok := v.(domain.TokenClaims)
+	if !ok {
+		return domain.TokenClaims{}, ierr.New(ierr.Internal, "casting token claims error")
+	}
+
+	return claims, nil
+}
+
+// ClientContext puts client of the request to the request context, so usecases know who makes the request, e.g. for auditing.
+func ClientContext(c *gin.Context) {
+	client := domain.Client{IP: c.ClientIP(), UserAgent: c.Request.UserAgent()}
+	c.Request = c.Request.WithContext(domain.ContextWithClient(c.Request.Context(), client))
 
 	c.Next()
 }
@@ -0,0 +1,345 @@
+package presenters
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+	"github.com/valerii-smirnov/petli-test-task/pkg/token"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestAuthMiddleware returns auth middleware which treats every valid token as not revoked.
+func newTestAuthMiddleware(controller *gomock.Controller, tokenParser TokenParser) *AuthMiddleware {
+	revocationChecker := NewMockTokenRevocationChecker(controller)
+	revocationChecker.EXPECT().IsRevoked(gomock.Any(), gomock.Any()).Return(false, nil).AnyTimes()
+
+	return NewAuthMiddleware(tokenParser, revocationChecker, nil)
+}
+
+// tokenClaimsMatcher matches claims of token issued to the user regardless of generated id and timestamps.
+type tokenClaimsMatcher struct {
+	userID uuid.UUID
+}
+
+func (m tokenClaimsMatcher) Matches(x interface{}) bool {
+	claims, ok := x.(domain.TokenClaims)
+	return ok && claims.UserID == m.userID && claims.ID != uuid.Nil && claims.ExpiresAt.After(claims.IssuedAt)
+}
+
+func (m tokenClaimsMatcher) String() string {
+	return "token claims of user " + m.userID.String()
+}
+
+func TestAuthMiddleware_Auth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	controller := gomock.NewController(t)
+	tokenProcessor := token.NewJWT(token.NewHMACKeySet("test-secret"), time.Minute*5)
+	mockRevocationChecker := NewMockTokenRevocationChecker(controller)
+
+	userID := uuid.New()
+
+	claimsMatcher := tokenClaimsMatcher{userID: userID}
+
+	tests := []struct {
+		name              string
+		mocksInitFn       func()
+		getRequestFn      func() *http.Request
+		resultAssertionFn func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:        "missing token",
+			mocksInitFn: func() {},
+			getRequestFn: func() *http.Request {
+				req, err := http.NewRequest(http.MethodGet, "/api/test", nil)
+				if err != nil {
+					assert.Error(t, err)
+				}
+
+				return req
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "revocation check error",
+			mocksInitFn: func() {
+				err := ierr.New(ierr.Internal, "testing-error")
+				mockRevocationChecker.EXPECT().IsRevoked(gomock.Any(), claimsMatcher).Return(false, err)
+			},
+			getRequestFn: func() *http.Request {
+				return newAuthorizedRequest(t, tokenProcessor, userID)
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name: "revoked token",
+			mocksInitFn: func() {
+				mockRevocationChecker.EXPECT().IsRevoked(gomock.Any(), claimsMatcher).Return(true, nil)
+			},
+			getRequestFn: func() *http.Request {
+				return newAuthorizedRequest(t, tokenProcessor, userID)
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "success",
+			mocksInitFn: func() {
+				mockRevocationChecker.EXPECT().IsRevoked(gomock.Any(), claimsMatcher).Return(false, nil)
+			},
+			getRequestFn: func() *http.Request {
+				return newAuthorizedRequest(t, tokenProcessor, userID)
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Equal(t, userID.String(), recorder.Body.String())
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInitFn()
+
+			recorder := httptest.NewRecorder()
+			_, engine := gin.CreateTestContext(recorder)
+
+			authMiddleware := NewAuthMiddleware(tokenProcessor, mockRevocationChecker, nil)
+			engine.GET("/api/test", ErrorHandler, authMiddleware.Auth, func(c *gin.Context) {
+				c.String(http.StatusOK, c.MustGet(contextIdentityKey).(uuid.UUID).String())
+			})
+
+			req := tt.getRequestFn()
+			engine.ServeHTTP(recorder, req)
+			tt.resultAssertionFn(recorder)
+		})
+	}
+}
+
+func newAuthorizedRequest(t *testing.T, tokenProcessor *token.JWT, userID uuid.UUID) *http.Request {
+	req, err := http.NewRequest(http.MethodGet, "/api/test", nil)
+	if err != nil {
+		assert.Error(t, err)
+	}
+
+	st, err := tokenProcessor.Generate(userID, domain.RoleUser.String())
+	if err != nil {
+		assert.Error(t, err)
+	}
+
+	req.Header.Set(AuthorizationHeaderName, fmt.Sprintf("%s%s", bearerPrefix, st))
+
+	return req
+}
+
+func TestRequirePermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	controller := gomock.NewController(t)
+	tokenProcessor := token.NewJWT(token.NewHMACKeySet("test-secret"), time.Minute*5)
+	authMiddleware := newTestAuthMiddleware(controller, tokenProcessor)
+
+	userID := uuid.New()
+
+	tests := []struct {
+		name     string
+		role     string
+		wantCode int
+	}{
+		{
+			name:     "token without role claim",
+			role:     "",
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:     "user",
+			role:     domain.RoleUser.String(),
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:     "moderator",
+			role:     domain.RoleModerator.String(),
+			wantCode: http.StatusOK,
+		},
+		{
+			name:     "admin",
+			role:     domain.RoleAdmin.String(),
+			wantCode: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			_, engine := gin.CreateTestContext(recorder)
+
+			engine.GET("/api/test", ErrorHandler, authMiddleware.Auth, RequirePermission(domain.PermissionManageAnyDog), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			req, err := http.NewRequest(http.MethodGet, "/api/test", nil)
+			assert.NoError(t, err)
+
+			st, err := tokenProcessor.Generate(userID, tt.role)
+			assert.NoError(t, err)
+
+			req.Header.Set(AuthorizationHeaderName, fmt.Sprintf("%s%s", bearerPrefix, st))
+
+			engine.ServeHTTP(recorder, req)
+			assert.Equal(t, tt.wantCode, recorder.Code)
+		})
+	}
+
+	t.Run("not authenticated", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		_, engine := gin.CreateTestContext(recorder)
+
+		engine.GET("/api/test", ErrorHandler, RequirePermission(domain.PermissionManageAnyDog), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+
+		req, err := http.NewRequest(http.MethodGet, "/api/test", nil)
+		assert.NoError(t, err)
+
+		engine.ServeHTTP(recorder, req)
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	})
+}
+
+func TestAuthMiddleware_AuthAPIKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	controller := gomock.NewController(t)
+	tokenProcessor := token.NewJWT(token.NewHMACKeySet("test-secret"), time.Minute*5)
+	mockAPIKeyAuthenticator := NewMockAPIKeyAuthenticator(controller)
+
+	userID := uuid.New()
+	key := "petly_abcdefghijklmnop"
+	readKey := domain.APIKey{ID: uuid.New(), UserID: userID, Scopes: []domain.APIKeyScope{domain.APIKeyScopeRead}}
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		mocksInitFn func()
+		wantCode    int
+		wantBody    string
+	}{
+		{
+			name:   "invalid key",
+			method: http.MethodGet,
+			path:   "/api/test",
+			mocksInitFn: func() {
+				mockAPIKeyAuthenticator.EXPECT().Authenticate(gomock.Any(), key).
+					Return(domain.APIKey{}, ierr.New(ierr.Unauthenticated, "invalid api key"))
+			},
+			wantCode: http.StatusUnauthorized,
+		},
+		{
+			name:   "read scope allows get",
+			method: http.MethodGet,
+			path:   "/api/test",
+			mocksInitFn: func() {
+				mockAPIKeyAuthenticator.EXPECT().Authenticate(gomock.Any(), key).Return(readKey, nil)
+			},
+			wantCode: http.StatusOK,
+			wantBody: userID.String(),
+		},
+		{
+			name:   "elevated role is capped for usecases",
+			method: http.MethodGet,
+			path:   "/api/role",
+			mocksInitFn: func() {
+				mockAPIKeyAuthenticator.EXPECT().Authenticate(gomock.Any(), key).Return(readKey, nil)
+			},
+			wantCode: http.StatusOK,
+			wantBody: domain.RoleUser.String(),
+		},
+		{
+			name:   "read scope denies post",
+			method: http.MethodPost,
+			path:   "/api/test",
+			mocksInitFn: func() {
+				mockAPIKeyAuthenticator.EXPECT().Authenticate(gomock.Any(), key).Return(readKey, nil)
+			},
+			wantCode: http.StatusForbidden,
+		},
+		{
+			name:   "access token is required",
+			method: http.MethodGet,
+			path:   "/api/token-only",
+			mocksInitFn: func() {
+				mockAPIKeyAuthenticator.EXPECT().Authenticate(gomock.Any(), key).Return(readKey, nil)
+			},
+			wantCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInitFn()
+
+			recorder := httptest.NewRecorder()
+			_, engine := gin.CreateTestContext(recorder)
+
+			authMiddleware := NewAuthMiddleware(tokenProcessor, nil, mockAPIKeyAuthenticator)
+			handler := func(c *gin.Context) {
+				c.String(http.StatusOK, c.MustGet(contextIdentityKey).(uuid.UUID).String())
+			}
+			engine.GET("/api/test", ErrorHandler, authMiddleware.Auth, handler)
+			engine.POST("/api/test", ErrorHandler, authMiddleware.Auth, handler)
+			engine.GET("/api/token-only", ErrorHandler, authMiddleware.Auth, RequireAccessToken, handler)
+			engine.GET("/api/role", ErrorHandler, authMiddleware.Auth, func(c *gin.Context) {
+				c.String(http.StatusOK, domain.EffectiveRole(c.Request.Context(), domain.RoleModerator).String())
+			})
+
+			req, err := http.NewRequest(tt.method, tt.path, nil)
+			assert.NoError(t, err)
+			req.Header.Set(AuthorizationHeaderName, apiKeyPrefix+key)
+
+			engine.ServeHTTP(recorder, req)
+			assert.Equal(t, tt.wantCode, recorder.Code)
+			if tt.wantCode == http.StatusOK {
+				assert.Equal(t, tt.wantBody, recorder.Body.String())
+			}
+		})
+	}
+}
+
+func TestClientContext(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	recorder := httptest.NewRecorder()
+	_, engine := gin.CreateTestContext(recorder)
+	engine.ContextWithFallback = true
+
+	var got domain.Client
+	engine.GET("/api/test", ClientContext, func(c *gin.Context) {
+		got = domain.ClientFromContext(c)
+		c.Status(http.StatusOK)
+	})
+
+	req, err := http.NewRequest(http.MethodGet, "/api/test", nil)
+	assert.NoError(t, err)
+
+	req.RemoteAddr = "10.0.0.1:54321"
+	req.Header.Set("User-Agent", "curl/7.87.0")
+
+	engine.ServeHTTP(recorder, req)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, domain.Client{IP: "10.0.0.1", UserAgent: "curl/7.87.0"}, got)
+}
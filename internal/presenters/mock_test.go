@@ -7,12 +7,14 @@ package presenters
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gin "github.com/gin-gonic/gin"
 	jwt "github.com/golang-jwt/jwt/v4"
 	gomock "github.com/golang/mock/gomock"
 	uuid "github.com/google/uuid"
 	domain "github.com/valerii-smirnov/petli-test-task/internal/domain"
+	token "github.com/valerii-smirnov/petli-test-task/pkg/token"
 )
 
 // MockAuthUsecase is a mock of AuthUsecase interface.
@@ -38,33 +40,706 @@ func (m *MockAuthUsecase) EXPECT() *MockAuthUsecaseMockRecorder {
 	return m.recorder
 }
 
+// Logout mocks base method.
+func (m *MockAuthUsecase) Logout(ctx context.Context, claims domain.TokenClaims, refreshToken domain.Token) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Logout", ctx, claims, refreshToken)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Logout indicates an expected call of Logout.
+func (mr *MockAuthUsecaseMockRecorder) Logout(ctx, claims, refreshToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Logout", reflect.TypeOf((*MockAuthUsecase)(nil).Logout), ctx, claims, refreshToken)
+}
+
+// LogoutAll mocks base method.
+func (m *MockAuthUsecase) LogoutAll(ctx context.Context, userID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LogoutAll", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LogoutAll indicates an expected call of LogoutAll.
+func (mr *MockAuthUsecaseMockRecorder) LogoutAll(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LogoutAll", reflect.TypeOf((*MockAuthUsecase)(nil).LogoutAll), ctx, userID)
+}
+
+// Refresh mocks base method.
+func (m *MockAuthUsecase) Refresh(ctx context.Context, refreshToken domain.Token) (domain.Tokens, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Refresh", ctx, refreshToken)
+	ret0, _ := ret[0].(domain.Tokens)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Refresh indicates an expected call of Refresh.
+func (mr *MockAuthUsecaseMockRecorder) Refresh(ctx, refreshToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refresh", reflect.TypeOf((*MockAuthUsecase)(nil).Refresh), ctx, refreshToken)
+}
+
 // SignIn mocks base method.
-func (m *MockAuthUsecase) SignIn(ctx context.Context, si domain.SingIn) (domain.Token, error) {
+func (m *MockAuthUsecase) SignIn(ctx context.Context, si domain.SingIn) (domain.Tokens, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SignIn", ctx, si)
+	ret0, _ := ret[0].(domain.Tokens)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SignIn indicates an expected call of SignIn.
+func (mr *MockAuthUsecaseMockRecorder) SignIn(ctx, si interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignIn", reflect.TypeOf((*MockAuthUsecase)(nil).SignIn), ctx, si)
+}
+
+// SignInSecondFactor mocks base method.
+func (m *MockAuthUsecase) SignInSecondFactor(ctx context.Context, in domain.SecondFactorSignIn) (domain.Tokens, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SignInSecondFactor", ctx, in)
+	ret0, _ := ret[0].(domain.Tokens)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SignInSecondFactor indicates an expected call of SignInSecondFactor.
+func (mr *MockAuthUsecaseMockRecorder) SignInSecondFactor(ctx, in interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignInSecondFactor", reflect.TypeOf((*MockAuthUsecase)(nil).SignInSecondFactor), ctx, in)
+}
+
+// SignUp mocks base method.
+func (m *MockAuthUsecase) SignUp(ctx context.Context, su domain.SignUp) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SignUp", ctx, su)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SignUp indicates an expected call of SignUp.
+func (mr *MockAuthUsecaseMockRecorder) SignUp(ctx, su interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignUp", reflect.TypeOf((*MockAuthUsecase)(nil).SignUp), ctx, su)
+}
+
+// MockTwoFactorUsecase is a mock of TwoFactorUsecase interface.
+type MockTwoFactorUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockTwoFactorUsecaseMockRecorder
+}
+
+// MockTwoFactorUsecaseMockRecorder is the mock recorder for MockTwoFactorUsecase.
+type MockTwoFactorUsecaseMockRecorder struct {
+	mock *MockTwoFactorUsecase
+}
+
+// NewMockTwoFactorUsecase creates a new mock instance.
+func NewMockTwoFactorUsecase(ctrl *gomock.Controller) *MockTwoFactorUsecase {
+	mock := &MockTwoFactorUsecase{ctrl: ctrl}
+	mock.recorder = &MockTwoFactorUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTwoFactorUsecase) EXPECT() *MockTwoFactorUsecaseMockRecorder {
+	return m.recorder
+}
+
+// Confirm mocks base method.
+func (m *MockTwoFactorUsecase) Confirm(ctx context.Context, userID uuid.UUID, code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Confirm", ctx, userID, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Confirm indicates an expected call of Confirm.
+func (mr *MockTwoFactorUsecaseMockRecorder) Confirm(ctx, userID, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Confirm", reflect.TypeOf((*MockTwoFactorUsecase)(nil).Confirm), ctx, userID, code)
+}
+
+// Disable mocks base method.
+func (m *MockTwoFactorUsecase) Disable(ctx context.Context, userID uuid.UUID, code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Disable", ctx, userID, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Disable indicates an expected call of Disable.
+func (mr *MockTwoFactorUsecaseMockRecorder) Disable(ctx, userID, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Disable", reflect.TypeOf((*MockTwoFactorUsecase)(nil).Disable), ctx, userID, code)
+}
+
+// Enroll mocks base method.
+func (m *MockTwoFactorUsecase) Enroll(ctx context.Context, userID uuid.UUID) (domain.TOTPEnrollment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enroll", ctx, userID)
+	ret0, _ := ret[0].(domain.TOTPEnrollment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Enroll indicates an expected call of Enroll.
+func (mr *MockTwoFactorUsecaseMockRecorder) Enroll(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enroll", reflect.TypeOf((*MockTwoFactorUsecase)(nil).Enroll), ctx, userID)
+}
+
+// MockOIDCUsecase is a mock of OIDCUsecase interface.
+type MockOIDCUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockOIDCUsecaseMockRecorder
+}
+
+// MockOIDCUsecaseMockRecorder is the mock recorder for MockOIDCUsecase.
+type MockOIDCUsecaseMockRecorder struct {
+	mock *MockOIDCUsecase
+}
+
+// NewMockOIDCUsecase creates a new mock instance.
+func NewMockOIDCUsecase(ctrl *gomock.Controller) *MockOIDCUsecase {
+	mock := &MockOIDCUsecase{ctrl: ctrl}
+	mock.recorder = &MockOIDCUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOIDCUsecase) EXPECT() *MockOIDCUsecaseMockRecorder {
+	return m.recorder
+}
+
+// Begin mocks base method.
+func (m *MockOIDCUsecase) Begin(ctx context.Context) (domain.OIDCLogin, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Begin", ctx)
+	ret0, _ := ret[0].(domain.OIDCLogin)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Begin indicates an expected call of Begin.
+func (mr *MockOIDCUsecaseMockRecorder) Begin(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Begin", reflect.TypeOf((*MockOIDCUsecase)(nil).Begin), ctx)
+}
+
+// Complete mocks base method.
+func (m *MockOIDCUsecase) Complete(ctx context.Context, in domain.OIDCCallback) (domain.Tokens, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Complete", ctx, in)
+	ret0, _ := ret[0].(domain.Tokens)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Complete indicates an expected call of Complete.
+func (mr *MockOIDCUsecaseMockRecorder) Complete(ctx, in interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Complete", reflect.TypeOf((*MockOIDCUsecase)(nil).Complete), ctx, in)
+}
+
+// MockEmailVerificationUsecase is a mock of EmailVerificationUsecase interface.
+type MockEmailVerificationUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockEmailVerificationUsecaseMockRecorder
+}
+
+// MockEmailVerificationUsecaseMockRecorder is the mock recorder for MockEmailVerificationUsecase.
+type MockEmailVerificationUsecaseMockRecorder struct {
+	mock *MockEmailVerificationUsecase
+}
+
+// NewMockEmailVerificationUsecase creates a new mock instance.
+func NewMockEmailVerificationUsecase(ctrl *gomock.Controller) *MockEmailVerificationUsecase {
+	mock := &MockEmailVerificationUsecase{ctrl: ctrl}
+	mock.recorder = &MockEmailVerificationUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEmailVerificationUsecase) EXPECT() *MockEmailVerificationUsecaseMockRecorder {
+	return m.recorder
+}
+
+// Resend mocks base method.
+func (m *MockEmailVerificationUsecase) Resend(ctx context.Context, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Resend", ctx, email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Resend indicates an expected call of Resend.
+func (mr *MockEmailVerificationUsecaseMockRecorder) Resend(ctx, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Resend", reflect.TypeOf((*MockEmailVerificationUsecase)(nil).Resend), ctx, email)
+}
+
+// Verify mocks base method.
+func (m *MockEmailVerificationUsecase) Verify(ctx context.Context, token string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Verify", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Verify indicates an expected call of Verify.
+func (mr *MockEmailVerificationUsecaseMockRecorder) Verify(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockEmailVerificationUsecase)(nil).Verify), ctx, token)
+}
+
+// MockPasswordResetUsecase is a mock of PasswordResetUsecase interface.
+type MockPasswordResetUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockPasswordResetUsecaseMockRecorder
+}
+
+// MockPasswordResetUsecaseMockRecorder is the mock recorder for MockPasswordResetUsecase.
+type MockPasswordResetUsecaseMockRecorder struct {
+	mock *MockPasswordResetUsecase
+}
+
+// NewMockPasswordResetUsecase creates a new mock instance.
+func NewMockPasswordResetUsecase(ctrl *gomock.Controller) *MockPasswordResetUsecase {
+	mock := &MockPasswordResetUsecase{ctrl: ctrl}
+	mock.recorder = &MockPasswordResetUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPasswordResetUsecase) EXPECT() *MockPasswordResetUsecaseMockRecorder {
+	return m.recorder
+}
+
+// Confirm mocks base method.
+func (m *MockPasswordResetUsecase) Confirm(ctx context.Context, in domain.PasswordResetConfirm) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Confirm", ctx, in)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Confirm indicates an expected call of Confirm.
+func (mr *MockPasswordResetUsecaseMockRecorder) Confirm(ctx, in interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Confirm", reflect.TypeOf((*MockPasswordResetUsecase)(nil).Confirm), ctx, in)
+}
+
+// Request mocks base method.
+func (m *MockPasswordResetUsecase) Request(ctx context.Context, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Request", ctx, email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Request indicates an expected call of Request.
+func (mr *MockPasswordResetUsecaseMockRecorder) Request(ctx, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Request", reflect.TypeOf((*MockPasswordResetUsecase)(nil).Request), ctx, email)
+}
+
+// MockUserUsecase is a mock of UserUsecase interface.
+type MockUserUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockUserUsecaseMockRecorder
+}
+
+// MockUserUsecaseMockRecorder is the mock recorder for MockUserUsecase.
+type MockUserUsecaseMockRecorder struct {
+	mock *MockUserUsecase
+}
+
+// NewMockUserUsecase creates a new mock instance.
+func NewMockUserUsecase(ctrl *gomock.Controller) *MockUserUsecase {
+	mock := &MockUserUsecase{ctrl: ctrl}
+	mock.recorder = &MockUserUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUserUsecase) EXPECT() *MockUserUsecaseMockRecorder {
+	return m.recorder
+}
+
+// CancelDeletion mocks base method.
+func (m *MockUserUsecase) CancelDeletion(ctx context.Context, userID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelDeletion", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelDeletion indicates an expected call of CancelDeletion.
+func (mr *MockUserUsecaseMockRecorder) CancelDeletion(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelDeletion", reflect.TypeOf((*MockUserUsecase)(nil).CancelDeletion), ctx, userID)
+}
+
+// ChangePassword mocks base method.
+func (m *MockUserUsecase) ChangePassword(ctx context.Context, userID uuid.UUID, in domain.PasswordChange) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangePassword", ctx, userID, in)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangePassword indicates an expected call of ChangePassword.
+func (mr *MockUserUsecaseMockRecorder) ChangePassword(ctx, userID, in interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangePassword", reflect.TypeOf((*MockUserUsecase)(nil).ChangePassword), ctx, userID, in)
+}
+
+// ConfirmEmailChange mocks base method.
+func (m *MockUserUsecase) ConfirmEmailChange(ctx context.Context, token string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmEmailChange", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConfirmEmailChange indicates an expected call of ConfirmEmailChange.
+func (mr *MockUserUsecaseMockRecorder) ConfirmEmailChange(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmEmailChange", reflect.TypeOf((*MockUserUsecase)(nil).ConfirmEmailChange), ctx, token)
+}
+
+// Get mocks base method.
+func (m *MockUserUsecase) Get(ctx context.Context, userID uuid.UUID) (domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, userID)
+	ret0, _ := ret[0].(domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockUserUsecaseMockRecorder) Get(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockUserUsecase)(nil).Get), ctx, userID)
+}
+
+// RequestEmailChange mocks base method.
+func (m *MockUserUsecase) RequestEmailChange(ctx context.Context, userID uuid.UUID, in domain.EmailChange) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestEmailChange", ctx, userID, in)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequestEmailChange indicates an expected call of RequestEmailChange.
+func (mr *MockUserUsecaseMockRecorder) RequestEmailChange(ctx, userID, in interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestEmailChange", reflect.TypeOf((*MockUserUsecase)(nil).RequestEmailChange), ctx, userID, in)
+}
+
+// ScheduleDeletion mocks base method.
+func (m *MockUserUsecase) ScheduleDeletion(ctx context.Context, userID uuid.UUID, in domain.AccountDeletion) (time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScheduleDeletion", ctx, userID, in)
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ScheduleDeletion indicates an expected call of ScheduleDeletion.
+func (mr *MockUserUsecaseMockRecorder) ScheduleDeletion(ctx, userID, in interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScheduleDeletion", reflect.TypeOf((*MockUserUsecase)(nil).ScheduleDeletion), ctx, userID, in)
+}
+
+// SecurityEvents mocks base method.
+func (m *MockUserUsecase) SecurityEvents(ctx context.Context, userID uuid.UUID, pagination domain.Pagination) ([]domain.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SecurityEvents", ctx, userID, pagination)
+	ret0, _ := ret[0].([]domain.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SecurityEvents indicates an expected call of SecurityEvents.
+func (mr *MockUserUsecaseMockRecorder) SecurityEvents(ctx, userID, pagination interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SecurityEvents", reflect.TypeOf((*MockUserUsecase)(nil).SecurityEvents), ctx, userID, pagination)
+}
+
+// UpdateProfile mocks base method.
+func (m *MockUserUsecase) UpdateProfile(ctx context.Context, userID uuid.UUID, profile domain.Profile) (domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProfile", ctx, userID, profile)
+	ret0, _ := ret[0].(domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateProfile indicates an expected call of UpdateProfile.
+func (mr *MockUserUsecaseMockRecorder) UpdateProfile(ctx, userID, profile interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProfile", reflect.TypeOf((*MockUserUsecase)(nil).UpdateProfile), ctx, userID, profile)
+}
+
+// MockDataExportUsecase is a mock of DataExportUsecase interface.
+type MockDataExportUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockDataExportUsecaseMockRecorder
+}
+
+// MockDataExportUsecaseMockRecorder is the mock recorder for MockDataExportUsecase.
+type MockDataExportUsecaseMockRecorder struct {
+	mock *MockDataExportUsecase
+}
+
+// NewMockDataExportUsecase creates a new mock instance.
+func NewMockDataExportUsecase(ctrl *gomock.Controller) *MockDataExportUsecase {
+	mock := &MockDataExportUsecase{ctrl: ctrl}
+	mock.recorder = &MockDataExportUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDataExportUsecase) EXPECT() *MockDataExportUsecaseMockRecorder {
+	return m.recorder
+}
+
+// Archive mocks base method.
+func (m *MockDataExportUsecase) Archive(ctx context.Context, userID, exportID uuid.UUID) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Archive", ctx, userID, exportID)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Archive indicates an expected call of Archive.
+func (mr *MockDataExportUsecaseMockRecorder) Archive(ctx, userID, exportID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Archive", reflect.TypeOf((*MockDataExportUsecase)(nil).Archive), ctx, userID, exportID)
+}
+
+// Export mocks base method.
+func (m *MockDataExportUsecase) Export(ctx context.Context, userID uuid.UUID) (domain.ExportResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Export", ctx, userID)
+	ret0, _ := ret[0].(domain.ExportResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Export indicates an expected call of Export.
+func (mr *MockDataExportUsecaseMockRecorder) Export(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Export", reflect.TypeOf((*MockDataExportUsecase)(nil).Export), ctx, userID)
+}
+
+// Get mocks base method.
+func (m *MockDataExportUsecase) Get(ctx context.Context, userID, exportID uuid.UUID) (domain.DataExport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, userID, exportID)
+	ret0, _ := ret[0].(domain.DataExport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockDataExportUsecaseMockRecorder) Get(ctx, userID, exportID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockDataExportUsecase)(nil).Get), ctx, userID, exportID)
+}
+
+// MockAPIKeyUsecase is a mock of APIKeyUsecase interface.
+type MockAPIKeyUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockAPIKeyUsecaseMockRecorder
+}
+
+// MockAPIKeyUsecaseMockRecorder is the mock recorder for MockAPIKeyUsecase.
+type MockAPIKeyUsecaseMockRecorder struct {
+	mock *MockAPIKeyUsecase
+}
+
+// NewMockAPIKeyUsecase creates a new mock instance.
+func NewMockAPIKeyUsecase(ctrl *gomock.Controller) *MockAPIKeyUsecase {
+	mock := &MockAPIKeyUsecase{ctrl: ctrl}
+	mock.recorder = &MockAPIKeyUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAPIKeyUsecase) EXPECT() *MockAPIKeyUsecaseMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockAPIKeyUsecase) Create(ctx context.Context, userID uuid.UUID, in domain.APIKeyCreate) (domain.CreatedAPIKey, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SignIn", ctx, si)
-	ret0, _ := ret[0].(domain.Token)
+	ret := m.ctrl.Call(m, "Create", ctx, userID, in)
+	ret0, _ := ret[0].(domain.CreatedAPIKey)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// SignIn indicates an expected call of SignIn.
-func (mr *MockAuthUsecaseMockRecorder) SignIn(ctx, si interface{}) *gomock.Call {
+// Create indicates an expected call of Create.
+func (mr *MockAPIKeyUsecaseMockRecorder) Create(ctx, userID, in interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignIn", reflect.TypeOf((*MockAuthUsecase)(nil).SignIn), ctx, si)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockAPIKeyUsecase)(nil).Create), ctx, userID, in)
 }
 
-// SignUp mocks base method.
-func (m *MockAuthUsecase) SignUp(ctx context.Context, su domain.SignUp) error {
+// List mocks base method.
+func (m *MockAPIKeyUsecase) List(ctx context.Context, userID uuid.UUID) ([]domain.APIKey, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SignUp", ctx, su)
+	ret := m.ctrl.Call(m, "List", ctx, userID)
+	ret0, _ := ret[0].([]domain.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockAPIKeyUsecaseMockRecorder) List(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockAPIKeyUsecase)(nil).List), ctx, userID)
+}
+
+// Revoke mocks base method.
+func (m *MockAPIKeyUsecase) Revoke(ctx context.Context, userID, keyID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revoke", ctx, userID, keyID)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// SignUp indicates an expected call of SignUp.
-func (mr *MockAuthUsecaseMockRecorder) SignUp(ctx, su interface{}) *gomock.Call {
+// Revoke indicates an expected call of Revoke.
+func (mr *MockAPIKeyUsecaseMockRecorder) Revoke(ctx, userID, keyID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignUp", reflect.TypeOf((*MockAuthUsecase)(nil).SignUp), ctx, su)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockAPIKeyUsecase)(nil).Revoke), ctx, userID, keyID)
+}
+
+// MockAdminUsecase is a mock of AdminUsecase interface.
+type MockAdminUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockAdminUsecaseMockRecorder
+}
+
+// MockAdminUsecaseMockRecorder is the mock recorder for MockAdminUsecase.
+type MockAdminUsecaseMockRecorder struct {
+	mock *MockAdminUsecase
+}
+
+// NewMockAdminUsecase creates a new mock instance.
+func NewMockAdminUsecase(ctrl *gomock.Controller) *MockAdminUsecase {
+	mock := &MockAdminUsecase{ctrl: ctrl}
+	mock.recorder = &MockAdminUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAdminUsecase) EXPECT() *MockAdminUsecaseMockRecorder {
+	return m.recorder
+}
+
+// AssignRole mocks base method.
+func (m *MockAdminUsecase) AssignRole(ctx context.Context, actorID, userID uuid.UUID, role domain.Role) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AssignRole", ctx, actorID, userID, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AssignRole indicates an expected call of AssignRole.
+func (mr *MockAdminUsecaseMockRecorder) AssignRole(ctx, actorID, userID, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AssignRole", reflect.TypeOf((*MockAdminUsecase)(nil).AssignRole), ctx, actorID, userID, role)
+}
+
+// SearchAuditEvents mocks base method.
+func (m *MockAdminUsecase) SearchAuditEvents(ctx context.Context, actorID uuid.UUID, filter domain.AuditEventFilter) ([]domain.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchAuditEvents", ctx, actorID, filter)
+	ret0, _ := ret[0].([]domain.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchAuditEvents indicates an expected call of SearchAuditEvents.
+func (mr *MockAdminUsecaseMockRecorder) SearchAuditEvents(ctx, actorID, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchAuditEvents", reflect.TypeOf((*MockAdminUsecase)(nil).SearchAuditEvents), ctx, actorID, filter)
+}
+
+// MockModerationUsecase is a mock of ModerationUsecase interface.
+type MockModerationUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockModerationUsecaseMockRecorder
+}
+
+// MockModerationUsecaseMockRecorder is the mock recorder for MockModerationUsecase.
+type MockModerationUsecaseMockRecorder struct {
+	mock *MockModerationUsecase
+}
+
+// NewMockModerationUsecase creates a new mock instance.
+func NewMockModerationUsecase(ctrl *gomock.Controller) *MockModerationUsecase {
+	mock := &MockModerationUsecase{ctrl: ctrl}
+	mock.recorder = &MockModerationUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockModerationUsecase) EXPECT() *MockModerationUsecaseMockRecorder {
+	return m.recorder
+}
+
+// ApprovePhoto mocks base method.
+func (m *MockModerationUsecase) ApprovePhoto(ctx context.Context, actorID, photoID uuid.UUID) (domain.FlaggedPhoto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApprovePhoto", ctx, actorID, photoID)
+	ret0, _ := ret[0].(domain.FlaggedPhoto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ApprovePhoto indicates an expected call of ApprovePhoto.
+func (mr *MockModerationUsecaseMockRecorder) ApprovePhoto(ctx, actorID, photoID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApprovePhoto", reflect.TypeOf((*MockModerationUsecase)(nil).ApprovePhoto), ctx, actorID, photoID)
+}
+
+// ListFlaggedPhotos mocks base method.
+func (m *MockModerationUsecase) ListFlaggedPhotos(ctx context.Context, actorID uuid.UUID, filter domain.FlaggedPhotoFilter) ([]domain.FlaggedPhoto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFlaggedPhotos", ctx, actorID, filter)
+	ret0, _ := ret[0].([]domain.FlaggedPhoto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFlaggedPhotos indicates an expected call of ListFlaggedPhotos.
+func (mr *MockModerationUsecaseMockRecorder) ListFlaggedPhotos(ctx, actorID, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFlaggedPhotos", reflect.TypeOf((*MockModerationUsecase)(nil).ListFlaggedPhotos), ctx, actorID, filter)
+}
+
+// RejectPhoto mocks base method.
+func (m *MockModerationUsecase) RejectPhoto(ctx context.Context, actorID, photoID uuid.UUID) (domain.FlaggedPhoto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RejectPhoto", ctx, actorID, photoID)
+	ret0, _ := ret[0].(domain.FlaggedPhoto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RejectPhoto indicates an expected call of RejectPhoto.
+func (mr *MockModerationUsecaseMockRecorder) RejectPhoto(ctx, actorID, photoID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RejectPhoto", reflect.TypeOf((*MockModerationUsecase)(nil).RejectPhoto), ctx, actorID, photoID)
 }
 
 // MockDogUsecase is a mock of DogUsecase interface.
@@ -133,6 +808,36 @@ func (mr *MockDogUsecaseMockRecorder) Delete(ctx, dogID, userID interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockDogUsecase)(nil).Delete), ctx, dogID, userID)
 }
 
+// DeletePhoto mocks base method.
+func (m *MockDogUsecase) DeletePhoto(ctx context.Context, userID, dogID, photoID uuid.UUID) (domain.Dog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePhoto", ctx, userID, dogID, photoID)
+	ret0, _ := ret[0].(domain.Dog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeletePhoto indicates an expected call of DeletePhoto.
+func (mr *MockDogUsecaseMockRecorder) DeletePhoto(ctx, userID, dogID, photoID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePhoto", reflect.TypeOf((*MockDogUsecase)(nil).DeletePhoto), ctx, userID, dogID, photoID)
+}
+
+// Feed mocks base method.
+func (m *MockDogUsecase) Feed(ctx context.Context, userID, dogID uuid.UUID, filter domain.DogFilter, pagination domain.Pagination) (domain.DogPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Feed", ctx, userID, dogID, filter, pagination)
+	ret0, _ := ret[0].(domain.DogPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Feed indicates an expected call of Feed.
+func (mr *MockDogUsecaseMockRecorder) Feed(ctx, userID, dogID, filter, pagination interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Feed", reflect.TypeOf((*MockDogUsecase)(nil).Feed), ctx, userID, dogID, filter, pagination)
+}
+
 // Get mocks base method.
 func (m *MockDogUsecase) Get(ctx context.Context, dogID uuid.UUID) (domain.Dog, error) {
 	m.ctrl.T.Helper()
@@ -149,25 +854,25 @@ func (mr *MockDogUsecaseMockRecorder) Get(ctx, dogID interface{}) *gomock.Call {
 }
 
 // List mocks base method.
-func (m *MockDogUsecase) List(ctx context.Context, userID uuid.UUID, pagination domain.Pagination) (domain.DogList, error) {
+func (m *MockDogUsecase) List(ctx context.Context, userID uuid.UUID, filter domain.DogFilter, pagination domain.Pagination) (domain.DogPage, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "List", ctx, userID, pagination)
-	ret0, _ := ret[0].(domain.DogList)
+	ret := m.ctrl.Call(m, "List", ctx, userID, filter, pagination)
+	ret0, _ := ret[0].(domain.DogPage)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // List indicates an expected call of List.
-func (mr *MockDogUsecaseMockRecorder) List(ctx, userID, pagination interface{}) *gomock.Call {
+func (mr *MockDogUsecaseMockRecorder) List(ctx, userID, filter, pagination interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockDogUsecase)(nil).List), ctx, userID, pagination)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockDogUsecase)(nil).List), ctx, userID, filter, pagination)
 }
 
 // Matches mocks base method.
-func (m *MockDogUsecase) Matches(ctx context.Context, userID, dogID uuid.UUID, pagination domain.Pagination) (domain.DogList, error) {
+func (m *MockDogUsecase) Matches(ctx context.Context, userID, dogID uuid.UUID, pagination domain.Pagination) (domain.DogPage, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Matches", ctx, userID, dogID, pagination)
-	ret0, _ := ret[0].(domain.DogList)
+	ret0, _ := ret[0].(domain.DogPage)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -178,6 +883,51 @@ func (mr *MockDogUsecaseMockRecorder) Matches(ctx, userID, dogID, pagination int
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Matches", reflect.TypeOf((*MockDogUsecase)(nil).Matches), ctx, userID, dogID, pagination)
 }
 
+// Preferences mocks base method.
+func (m *MockDogUsecase) Preferences(ctx context.Context, userID, dogID uuid.UUID) (domain.DogPreferences, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Preferences", ctx, userID, dogID)
+	ret0, _ := ret[0].(domain.DogPreferences)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Preferences indicates an expected call of Preferences.
+func (mr *MockDogUsecaseMockRecorder) Preferences(ctx, userID, dogID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Preferences", reflect.TypeOf((*MockDogUsecase)(nil).Preferences), ctx, userID, dogID)
+}
+
+// ReorderPhotos mocks base method.
+func (m *MockDogUsecase) ReorderPhotos(ctx context.Context, userID, dogID uuid.UUID, photoIDs []uuid.UUID) (domain.Dog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReorderPhotos", ctx, userID, dogID, photoIDs)
+	ret0, _ := ret[0].(domain.Dog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReorderPhotos indicates an expected call of ReorderPhotos.
+func (mr *MockDogUsecaseMockRecorder) ReorderPhotos(ctx, userID, dogID, photoIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReorderPhotos", reflect.TypeOf((*MockDogUsecase)(nil).ReorderPhotos), ctx, userID, dogID, photoIDs)
+}
+
+// SetPrimaryPhoto mocks base method.
+func (m *MockDogUsecase) SetPrimaryPhoto(ctx context.Context, userID, dogID, photoID uuid.UUID) (domain.Dog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPrimaryPhoto", ctx, userID, dogID, photoID)
+	ret0, _ := ret[0].(domain.Dog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetPrimaryPhoto indicates an expected call of SetPrimaryPhoto.
+func (mr *MockDogUsecaseMockRecorder) SetPrimaryPhoto(ctx, userID, dogID, photoID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPrimaryPhoto", reflect.TypeOf((*MockDogUsecase)(nil).SetPrimaryPhoto), ctx, userID, dogID, photoID)
+}
+
 // Update mocks base method.
 func (m *MockDogUsecase) Update(ctx context.Context, dogID uuid.UUID, dog domain.Dog) (domain.Dog, error) {
 	m.ctrl.T.Helper()
@@ -193,6 +943,74 @@ func (mr *MockDogUsecaseMockRecorder) Update(ctx, dogID, dog interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockDogUsecase)(nil).Update), ctx, dogID, dog)
 }
 
+// UpdatePreferences mocks base method.
+func (m *MockDogUsecase) UpdatePreferences(ctx context.Context, userID, dogID uuid.UUID, prefs domain.DogPreferences) (domain.DogPreferences, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePreferences", ctx, userID, dogID, prefs)
+	ret0, _ := ret[0].(domain.DogPreferences)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdatePreferences indicates an expected call of UpdatePreferences.
+func (mr *MockDogUsecaseMockRecorder) UpdatePreferences(ctx, userID, dogID, prefs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePreferences", reflect.TypeOf((*MockDogUsecase)(nil).UpdatePreferences), ctx, userID, dogID, prefs)
+}
+
+// UploadPhoto mocks base method.
+func (m *MockDogUsecase) UploadPhoto(ctx context.Context, userID, dogID uuid.UUID, image domain.ImageUpload) (domain.PhotoUpload, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UploadPhoto", ctx, userID, dogID, image)
+	ret0, _ := ret[0].(domain.PhotoUpload)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UploadPhoto indicates an expected call of UploadPhoto.
+func (mr *MockDogUsecaseMockRecorder) UploadPhoto(ctx, userID, dogID, image interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadPhoto", reflect.TypeOf((*MockDogUsecase)(nil).UploadPhoto), ctx, userID, dogID, image)
+}
+
+// MockRecommendationUsecase is a mock of RecommendationUsecase interface.
+type MockRecommendationUsecase struct {
+	ctrl     *gomock.Controller
+	recorder *MockRecommendationUsecaseMockRecorder
+}
+
+// MockRecommendationUsecaseMockRecorder is the mock recorder for MockRecommendationUsecase.
+type MockRecommendationUsecaseMockRecorder struct {
+	mock *MockRecommendationUsecase
+}
+
+// NewMockRecommendationUsecase creates a new mock instance.
+func NewMockRecommendationUsecase(ctrl *gomock.Controller) *MockRecommendationUsecase {
+	mock := &MockRecommendationUsecase{ctrl: ctrl}
+	mock.recorder = &MockRecommendationUsecaseMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRecommendationUsecase) EXPECT() *MockRecommendationUsecaseMockRecorder {
+	return m.recorder
+}
+
+// Recommend mocks base method.
+func (m *MockRecommendationUsecase) Recommend(ctx context.Context, userID, dogID uuid.UUID, pagination domain.Pagination) ([]domain.Recommendation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Recommend", ctx, userID, dogID, pagination)
+	ret0, _ := ret[0].([]domain.Recommendation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Recommend indicates an expected call of Recommend.
+func (mr *MockRecommendationUsecaseMockRecorder) Recommend(ctx, userID, dogID, pagination interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Recommend", reflect.TypeOf((*MockRecommendationUsecase)(nil).Recommend), ctx, userID, dogID, pagination)
+}
+
 // MockTokenParser is a mock of TokenParser interface.
 type MockTokenParser struct {
 	ctrl     *gomock.Controller
@@ -231,6 +1049,119 @@ func (mr *MockTokenParserMockRecorder) Parse(token interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Parse", reflect.TypeOf((*MockTokenParser)(nil).Parse), token)
 }
 
+// MockKeysPublisher is a mock of KeysPublisher interface.
+type MockKeysPublisher struct {
+	ctrl     *gomock.Controller
+	recorder *MockKeysPublisherMockRecorder
+}
+
+// MockKeysPublisherMockRecorder is the mock recorder for MockKeysPublisher.
+type MockKeysPublisherMockRecorder struct {
+	mock *MockKeysPublisher
+}
+
+// NewMockKeysPublisher creates a new mock instance.
+func NewMockKeysPublisher(ctrl *gomock.Controller) *MockKeysPublisher {
+	mock := &MockKeysPublisher{ctrl: ctrl}
+	mock.recorder = &MockKeysPublisherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockKeysPublisher) EXPECT() *MockKeysPublisherMockRecorder {
+	return m.recorder
+}
+
+// JWKS mocks base method.
+func (m *MockKeysPublisher) JWKS() []token.JWK {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "JWKS")
+	ret0, _ := ret[0].([]token.JWK)
+	return ret0
+}
+
+// JWKS indicates an expected call of JWKS.
+func (mr *MockKeysPublisherMockRecorder) JWKS() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "JWKS", reflect.TypeOf((*MockKeysPublisher)(nil).JWKS))
+}
+
+// MockTokenRevocationChecker is a mock of TokenRevocationChecker interface.
+type MockTokenRevocationChecker struct {
+	ctrl     *gomock.Controller
+	recorder *MockTokenRevocationCheckerMockRecorder
+}
+
+// MockTokenRevocationCheckerMockRecorder is the mock recorder for MockTokenRevocationChecker.
+type MockTokenRevocationCheckerMockRecorder struct {
+	mock *MockTokenRevocationChecker
+}
+
+// NewMockTokenRevocationChecker creates a new mock instance.
+func NewMockTokenRevocationChecker(ctrl *gomock.Controller) *MockTokenRevocationChecker {
+	mock := &MockTokenRevocationChecker{ctrl: ctrl}
+	mock.recorder = &MockTokenRevocationCheckerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTokenRevocationChecker) EXPECT() *MockTokenRevocationCheckerMockRecorder {
+	return m.recorder
+}
+
+// IsRevoked mocks base method.
+func (m *MockTokenRevocationChecker) IsRevoked(ctx context.Context, claims domain.TokenClaims) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsRevoked", ctx, claims)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsRevoked indicates an expected call of IsRevoked.
+func (mr *MockTokenRevocationCheckerMockRecorder) IsRevoked(ctx, claims interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsRevoked", reflect.TypeOf((*MockTokenRevocationChecker)(nil).IsRevoked), ctx, claims)
+}
+
+// MockAPIKeyAuthenticator is a mock of APIKeyAuthenticator interface.
+type MockAPIKeyAuthenticator struct {
+	ctrl     *gomock.Controller
+	recorder *MockAPIKeyAuthenticatorMockRecorder
+}
+
+// MockAPIKeyAuthenticatorMockRecorder is the mock recorder for MockAPIKeyAuthenticator.
+type MockAPIKeyAuthenticatorMockRecorder struct {
+	mock *MockAPIKeyAuthenticator
+}
+
+// NewMockAPIKeyAuthenticator creates a new mock instance.
+func NewMockAPIKeyAuthenticator(ctrl *gomock.Controller) *MockAPIKeyAuthenticator {
+	mock := &MockAPIKeyAuthenticator{ctrl: ctrl}
+	mock.recorder = &MockAPIKeyAuthenticatorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAPIKeyAuthenticator) EXPECT() *MockAPIKeyAuthenticatorMockRecorder {
+	return m.recorder
+}
+
+// Authenticate mocks base method.
+func (m *MockAPIKeyAuthenticator) Authenticate(ctx context.Context, key string) (domain.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Authenticate", ctx, key)
+	ret0, _ := ret[0].(domain.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Authenticate indicates an expected call of Authenticate.
+func (mr *MockAPIKeyAuthenticatorMockRecorder) Authenticate(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authenticate", reflect.TypeOf((*MockAPIKeyAuthenticator)(nil).Authenticate), ctx, key)
+}
+
 // MockIdentityExtractor is a mock of IdentityExtractor interface.
 type MockIdentityExtractor struct {
 	ctrl     *gomock.Controller
@@ -306,3 +1237,41 @@ func (mr *MockPaginatorMockRecorder) GetPagination(c interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPagination", reflect.TypeOf((*MockPaginator)(nil).GetPagination), c)
 }
+
+// MockDogFilterParser is a mock of DogFilterParser interface.
+type MockDogFilterParser struct {
+	ctrl     *gomock.Controller
+	recorder *MockDogFilterParserMockRecorder
+}
+
+// MockDogFilterParserMockRecorder is the mock recorder for MockDogFilterParser.
+type MockDogFilterParserMockRecorder struct {
+	mock *MockDogFilterParser
+}
+
+// NewMockDogFilterParser creates a new mock instance.
+func NewMockDogFilterParser(ctrl *gomock.Controller) *MockDogFilterParser {
+	mock := &MockDogFilterParser{ctrl: ctrl}
+	mock.recorder = &MockDogFilterParserMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDogFilterParser) EXPECT() *MockDogFilterParserMockRecorder {
+	return m.recorder
+}
+
+// GetDogFilter mocks base method.
+func (m *MockDogFilterParser) GetDogFilter(c *gin.Context) (domain.DogFilter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDogFilter", c)
+	ret0, _ := ret[0].(domain.DogFilter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDogFilter indicates an expected call of GetDogFilter.
+func (mr *MockDogFilterParserMockRecorder) GetDogFilter(c interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDogFilter", reflect.TypeOf((*MockDogFilterParser)(nil).GetDogFilter), c)
+}
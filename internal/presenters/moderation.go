@@ -0,0 +1,193 @@
+package presenters
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/internal/presenters/messages"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+	"github.com/valerii-smirnov/petli-test-task/pkg/utils/gin/resp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Moderation presenter of the photo moderation queue, available only to users permitted to moderate photos.
+type Moderation struct {
+	moderationUsecase ModerationUsecase
+	identityExtractor IdentityExtractor
+	paginator         Paginator
+
+	middlewares []gin.HandlerFunc
+}
+
+func NewModeration(
+	moderationUsecase ModerationUsecase,
+	identityExtractor IdentityExtractor,
+	paginator Paginator,
+	middlewares ...gin.HandlerFunc,
+) *Moderation {
+	return &Moderation{
+		moderationUsecase: moderationUsecase,
+		identityExtractor: identityExtractor,
+		paginator:         paginator,
+		middlewares:       middlewares,
+	}
+}
+
+func (m Moderation) Inject(r gin.IRouter) {
+	moderationGroup := r.Group("/moderation")
+	if len(m.middlewares) > 0 {
+		moderationGroup.Use(m.middlewares...)
+	}
+
+	moderationGroup.GET("/flagged-photos", m.ListFlaggedPhotos)
+	moderationGroup.POST("/flagged-photos/:id/approve", m.ApprovePhoto)
+	moderationGroup.POST("/flagged-photos/:id/reject", m.RejectPhoto)
+}
+
+// ListFlaggedPhotos godoc
+// @Summary      Flagged photos list
+// @Description  Returns uploaded photos held for moderation because they look like photos of other users' dogs, the oldest first.
+// @ID 			 List flagged photos
+// @Tags         moderation
+// @Security 	 ApiKeyAuth
+// @Produce      json
+// @Param 		 status query string false "review status, pending if not given" Enums(pending, approved, rejected)
+// @Param 		 page query string false "pagination page number"
+// @Param 		 per-page query string false "pagination per page items number"
+// @Success      200 {object} messages.FlaggedPhotoListResponseBody
+// @Failure      400  {object}  messages.BadRequestError
+// @Failure      401  {object}  messages.UnauthenticatedError
+// @Failure      403  {object}  messages.ForbiddenError
+// @Failure      500  {object}  messages.InternalServerError
+// @Router       /moderation/flagged-photos [get]
+func (m Moderation) ListFlaggedPhotos(c *gin.Context) {
+	var req messages.FlaggedPhotoListRequestQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		resp.AbortWithError(c, ierr.WrapCode(ierr.InvalidArgument, err, "wrong flagged photo filter"))
+		return
+	}
+
+	pag, err := m.paginator.GetPagination(c)
+	if err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	actorID, err := m.identityExtractor.ExtractFromContext(c)
+	if err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	filter := domain.FlaggedPhotoFilter{
+		Status:     domain.ReviewStatus(req.Status),
+		Pagination: pag,
+	}
+
+	photos, err := m.moderationUsecase.ListFlaggedPhotos(c, actorID, filter)
+	if err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	list := make(messages.FlaggedPhotoListResponseBody, 0, len(photos))
+	for _, photo := range photos {
+		list = append(list, domainFlaggedPhotoToMessage(photo))
+	}
+
+	c.JSON(http.StatusOK, list)
+}
+
+// ApprovePhoto godoc
+// @Summary      Flagged photo approval
+// @Description  Adds the flagged photo to its dog as the last photo. The dog must have room for one more photo.
+// @ID 			 Approve flagged photo
+// @Tags         moderation
+// @Security 	 ApiKeyAuth
+// @Produce      json
+// @Param 		 id path string true "flagged photo ID"
+// @Success      200 {object} messages.FlaggedPhotoResponseBody
+// @Failure      400  {object}  messages.BadRequestError
+// @Failure      401  {object}  messages.UnauthenticatedError
+// @Failure      403  {object}  messages.ForbiddenError
+// @Failure      404  {object}  messages.NotFoundError
+// @Failure      409  {object}  messages.ConflictError
+// @Failure      500  {object}  messages.InternalServerError
+// @Router       /moderation/flagged-photos/{id}/approve [post]
+func (m Moderation) ApprovePhoto(c *gin.Context) {
+	m.review(c, m.moderationUsecase.ApprovePhoto)
+}
+
+// RejectPhoto godoc
+// @Summary      Flagged photo rejection
+// @Description  Rejects the flagged photo, it's never added to its dog.
+// @ID 			 Reject flagged photo
+// @Tags         moderation
+// @Security 	 ApiKeyAuth
+// @Produce      json
+// @Param 		 id path string true "flagged photo ID"
+// @Success      200 {object} messages.FlaggedPhotoResponseBody
+// @Failure      400  {object}  messages.BadRequestError
+// @Failure      401  {object}  messages.UnauthenticatedError
+// @Failure      403  {object}  messages.ForbiddenError
+// @Failure      404  {object}  messages.NotFoundError
+// @Failure      409  {object}  messages.ConflictError
+// @Failure      500  {object}  messages.InternalServerError
+// @Router       /moderation/flagged-photos/{id}/reject [post]
+func (m Moderation) RejectPhoto(c *gin.Context) {
+	m.review(c, m.moderationUsecase.RejectPhoto)
+}
+
+func (m Moderation) review(
+	c *gin.Context,
+	review func(ctx context.Context, actorID, photoID uuid.UUID) (domain.FlaggedPhoto, error),
+) {
+	photoID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		resp.AbortWithError(c, ierr.WrapCode(ierr.InvalidArgument, err, "wrong flagged photo id"))
+		return
+	}
+
+	actorID, err := m.identityExtractor.ExtractFromContext(c)
+	if err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	photo, err := review(c, actorID, photoID)
+	if err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, domainFlaggedPhotoToMessage(photo))
+}
+
+func domainFlaggedPhotoToMessage(photo domain.FlaggedPhoto) messages.FlaggedPhotoResponseBody {
+	msg := messages.FlaggedPhotoResponseBody{
+		ID:         photo.ID.String(),
+		DogID:      photo.DogID.String(),
+		Images:     domainImagesToMessage(photo.Images),
+		Distance:   photo.Distance,
+		Status:     photo.Status.String(),
+		ReviewedAt: photo.ReviewedAt,
+		CreatedAt:  photo.CreatedAt,
+	}
+
+	if photo.MatchedPhotoID != nil {
+		msg.MatchedPhotoID = photo.MatchedPhotoID.String()
+	}
+
+	if photo.MatchedDogID != nil {
+		msg.MatchedDogID = photo.MatchedDogID.String()
+	}
+
+	if photo.ReviewedBy != nil {
+		msg.ReviewedBy = photo.ReviewedBy.String()
+	}
+
+	return msg
+}
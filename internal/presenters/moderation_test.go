@@ -0,0 +1,163 @@
+package presenters
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/internal/presenters/messages"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModeration_ListFlaggedPhotos(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	controller := gomock.NewController(t)
+	mockModerationUsecase := NewMockModerationUsecase(controller)
+	mockIdentityExtractor := NewMockIdentityExtractor(controller)
+
+	actorID := uuid.New()
+	matchedDogID := uuid.New()
+	photo := domain.FlaggedPhoto{
+		ID:           uuid.New(),
+		DogID:        uuid.New(),
+		Images:       domain.ExternalDogImages("http://localhost:8080/media/dogs/full.jpg"),
+		MatchedDogID: &matchedDogID,
+		Distance:     3,
+		Status:       domain.ReviewPending,
+	}
+
+	tests := []struct {
+		name              string
+		url               string
+		mocksInitFn       func()
+		resultAssertionFn func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:        "unknown status",
+			url:         "/api/moderation/flagged-photos?status=deleted",
+			mocksInitFn: func() {},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "success",
+			url:  "/api/moderation/flagged-photos?status=pending&page=2&per-page=5",
+			mocksInitFn: func() {
+				mockIdentityExtractor.EXPECT().ExtractFromContext(gomock.Any()).Return(actorID, nil)
+				mockModerationUsecase.EXPECT().ListFlaggedPhotos(gomock.Any(), actorID, domain.FlaggedPhotoFilter{
+					Status:     domain.ReviewPending,
+					Pagination: domain.Pagination{Page: 2, PerPage: 5},
+				}).Return([]domain.FlaggedPhoto{photo}, nil)
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var body messages.FlaggedPhotoListResponseBody
+				assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+				assert.Len(t, body, 1)
+				assert.Equal(t, photo.ID.String(), body[0].ID)
+				assert.Equal(t, matchedDogID.String(), body[0].MatchedDogID)
+				assert.Empty(t, body[0].MatchedPhotoID)
+				assert.Equal(t, "pending", body[0].Status)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInitFn()
+
+			req, err := http.NewRequest(http.MethodGet, tt.url, nil)
+			assert.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			_, engine := gin.CreateTestContext(recorder)
+			engine = InitRoutes(engine, NewModeration(mockModerationUsecase, mockIdentityExtractor, NewUrlPagination()))
+
+			engine.ServeHTTP(recorder, req)
+			tt.resultAssertionFn(recorder)
+		})
+	}
+}
+
+func TestModeration_ApprovePhoto(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	controller := gomock.NewController(t)
+	mockModerationUsecase := NewMockModerationUsecase(controller)
+	mockIdentityExtractor := NewMockIdentityExtractor(controller)
+
+	actorID := uuid.New()
+	photoID := uuid.New()
+
+	tests := []struct {
+		name              string
+		url               string
+		mocksInitFn       func()
+		resultAssertionFn func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:        "wrong photo id",
+			url:         "/api/moderation/flagged-photos/wrong/approve",
+			mocksInitFn: func() {},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "photo is already reviewed",
+			url:  fmt.Sprintf("/api/moderation/flagged-photos/%s/approve", photoID),
+			mocksInitFn: func() {
+				mockIdentityExtractor.EXPECT().ExtractFromContext(gomock.Any()).Return(actorID, nil)
+				mockModerationUsecase.EXPECT().ApprovePhoto(gomock.Any(), actorID, photoID).
+					Return(domain.FlaggedPhoto{}, ierr.New(ierr.AlreadyExists, "photo is already reviewed"))
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "success",
+			url:  fmt.Sprintf("/api/moderation/flagged-photos/%s/approve", photoID),
+			mocksInitFn: func() {
+				mockIdentityExtractor.EXPECT().ExtractFromContext(gomock.Any()).Return(actorID, nil)
+				mockModerationUsecase.EXPECT().ApprovePhoto(gomock.Any(), actorID, photoID).
+					Return(domain.FlaggedPhoto{ID: photoID, Status: domain.ReviewApproved, ReviewedBy: &actorID}, nil)
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var body messages.FlaggedPhotoResponseBody
+				assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+				assert.Equal(t, "approved", body.Status)
+				assert.Equal(t, actorID.String(), body.ReviewedBy)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInitFn()
+
+			req, err := http.NewRequest(http.MethodPost, tt.url, nil)
+			assert.NoError(t, err)
+
+			recorder := httptest.NewRecorder()
+			_, engine := gin.CreateTestContext(recorder)
+			engine = InitRoutes(engine, NewModeration(mockModerationUsecase, mockIdentityExtractor, NewUrlPagination()))
+
+			engine.ServeHTTP(recorder, req)
+			tt.resultAssertionFn(recorder)
+		})
+	}
+}
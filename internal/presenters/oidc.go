@@ -0,0 +1,113 @@
+package presenters
+
+import (
+	"net/http"
+
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/internal/presenters/messages"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+	"github.com/valerii-smirnov/petli-test-task/pkg/utils/gin/resp"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	oidcFlowCookieName = "oidc_flow"
+	oidcCookiePath     = "/api/auth/oidc"
+)
+
+// OIDC presenter of sign-in with external OpenID Connect provider.
+type OIDC struct {
+	oidcUsecase  OIDCUsecase
+	secureCookie bool
+
+	middlewares []gin.HandlerFunc
+}
+
+// NewOIDC constructor. Flow cookie is marked secure if the app is served over https.
+func NewOIDC(oidcUsecase OIDCUsecase, secureCookie bool, middlewares ...gin.HandlerFunc) *OIDC {
+	return &OIDC{
+		oidcUsecase:  oidcUsecase,
+		secureCookie: secureCookie,
+		middlewares:  middlewares,
+	}
+}
+
+func (o OIDC) Inject(r gin.IRouter) {
+	oidcGroup := r.Group("/auth/oidc")
+	if len(o.middlewares) > 0 {
+		oidcGroup.Use(o.middlewares...)
+	}
+
+	oidcGroup.GET("login", o.Login)
+	oidcGroup.GET("callback", o.Callback)
+}
+
+// Login godoc
+// @Summary      OpenID Connect login
+// @Description  Redirects to the identity provider login page. Login state is kept in http-only cookie until callback.
+// @ID 			 Login user with OpenID Connect
+// @Tags         auth
+// @Success      302
+// @Header       302  {string} Location "identity provider login page"
+// @Failure      500  {object}  messages.InternalServerError
+// @Router       /auth/oidc/login [get]
+func (o OIDC) Login(c *gin.Context) {
+	login, err := o.oidcUsecase.Begin(c)
+	if err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	// lax mode is required, so cookie is sent on top-level redirect back from the provider.
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcFlowCookieName, login.FlowToken, 0, oidcCookiePath, "", o.secureCookie, true)
+	c.Redirect(http.StatusFound, login.AuthURL)
+}
+
+// Callback godoc
+// @Summary      OpenID Connect login callback
+// @Description  Identity provider redirects here after login. User is created or linked by email verified by the provider,
+// @Description  existing user is linked only if the email is verified by the user too.
+// @Description  If user has two-factor authentication enabled, only mfa token is returned.
+// @ID 			 Complete OpenID Connect login
+// @Tags         auth
+// @Produce      json
+// @Param 		 code query string true "authorization code"
+// @Param 		 state query string true "login state"
+// @Success      200 {object} messages.SignInResponseBody
+// @Failure      400  {object}  messages.BadRequestError
+// @Failure      401  {object}  messages.UnauthenticatedError
+// @Failure      403  {object}  messages.ForbiddenError
+// @Failure      500  {object}  messages.InternalServerError
+// @Router       /auth/oidc/callback [get]
+func (o OIDC) Callback(c *gin.Context) {
+	var req messages.OIDCCallbackRequestQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	flowToken, err := c.Cookie(oidcFlowCookieName)
+	if err != nil {
+		resp.AbortWithError(c, ierr.WrapCode(ierr.Unauthenticated, err, "oidc login is not started"))
+		return
+	}
+
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(oidcFlowCookieName, "", -1, oidcCookiePath, "", o.secureCookie, true)
+
+	in := domain.OIDCCallback{
+		Code:      req.Code,
+		State:     req.State,
+		FlowToken: flowToken,
+	}
+
+	tokens, err := o.oidcUsecase.Complete(c, in)
+	if err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, domainTokensToMessage(tokens))
+}
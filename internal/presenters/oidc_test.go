@@ -0,0 +1,144 @@
+package presenters
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/internal/presenters/messages"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOIDC(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	controller := gomock.NewController(t)
+	mockOIDCUsecase := NewMockOIDCUsecase(controller)
+
+	login := domain.OIDCLogin{
+		AuthURL:   "https://idp.example.com/authorize?state=state",
+		FlowToken: "flow-token",
+	}
+
+	callback := domain.OIDCCallback{
+		Code:      "code",
+		State:     "state",
+		FlowToken: "flow-token",
+	}
+
+	getRequestFn := func(url string, flowToken string) *http.Request {
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		if err != nil {
+			assert.Error(t, err)
+		}
+
+		if flowToken != "" {
+			req.AddCookie(&http.Cookie{Name: oidcFlowCookieName, Value: flowToken})
+		}
+
+		return req
+	}
+
+	tests := []struct {
+		name              string
+		mocksInitFn       func()
+		getRequestFn      func() *http.Request
+		resultAssertionFn func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "login",
+			mocksInitFn: func() {
+				mockOIDCUsecase.EXPECT().Begin(gomock.Any()).Return(login, nil)
+			},
+			getRequestFn: func() *http.Request {
+				return getRequestFn("/api/auth/oidc/login", "")
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusFound, recorder.Code)
+				assert.Equal(t, login.AuthURL, recorder.Header().Get("Location"))
+
+				cookies := recorder.Result().Cookies()
+				if assert.Len(t, cookies, 1) {
+					assert.Equal(t, oidcFlowCookieName, cookies[0].Name)
+					assert.Equal(t, login.FlowToken, cookies[0].Value)
+					assert.True(t, cookies[0].HttpOnly)
+					assert.Equal(t, http.SameSiteLaxMode, cookies[0].SameSite)
+				}
+			},
+		},
+		{
+			name:        "callback without code",
+			mocksInitFn: func() {},
+			getRequestFn: func() *http.Request {
+				return getRequestFn("/api/auth/oidc/callback?error=access_denied&state=state", "flow-token")
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:        "callback without flow cookie",
+			mocksInitFn: func() {},
+			getRequestFn: func() *http.Request {
+				return getRequestFn("/api/auth/oidc/callback?code=code&state=state", "")
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "callback usecase error",
+			mocksInitFn: func() {
+				mockOIDCUsecase.EXPECT().Complete(gomock.Any(), gomock.Eq(callback)).
+					Return(domain.Tokens{}, ierr.New(ierr.PermissionDenied, "email is not verified by identity provider"))
+			},
+			getRequestFn: func() *http.Request {
+				return getRequestFn("/api/auth/oidc/callback?code=code&state=state", "flow-token")
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "callback",
+			mocksInitFn: func() {
+				mockOIDCUsecase.EXPECT().Complete(gomock.Any(), gomock.Eq(callback)).
+					Return(domain.Tokens{Access: "jwttoken", Refresh: "refreshtoken"}, nil)
+			},
+			getRequestFn: func() *http.Request {
+				return getRequestFn("/api/auth/oidc/callback?code=code&state=state", "flow-token")
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var si messages.SignInResponseBody
+				if err := json.Unmarshal(recorder.Body.Bytes(), &si); err != nil {
+					assert.Error(t, err)
+				}
+
+				assert.Equal(t, "jwttoken", si.Token)
+				assert.Equal(t, "refreshtoken", si.RefreshToken)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInitFn()
+
+			recorder := httptest.NewRecorder()
+			_, engine := gin.CreateTestContext(recorder)
+			engine = InitRoutes(engine, NewOIDC(mockOIDCUsecase, false))
+
+			req := tt.getRequestFn()
+			engine.ServeHTTP(recorder, req)
+			tt.resultAssertionFn(recorder)
+		})
+	}
+}
@@ -1,17 +1,22 @@
 package presenters
 
 import (
+	"encoding/base64"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/valerii-smirnov/petli-test-task/internal/domain"
 	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 const (
 	pageRequestQueryParamName    = "page"
 	perPageRequestQueryParamName = "per-page"
+	cursorRequestQueryParamName  = "cursor"
 
 	defaultPage    = "1"
 	defaultPerPage = "10"
@@ -35,8 +40,58 @@ func (p UrlPagination) GetPagination(c *gin.Context) (domain.Pagination, error)
 		return domain.Pagination{}, ierr.WrapCode(ierr.InvalidArgument, err, "wrong per-page param")
 	}
 
-	return domain.Pagination{
+	pagination := domain.Pagination{
 		Page:    page,
 		PerPage: perPage,
-	}, nil
+	}
+
+	// cursor replaces page number, lists ordered by creation time return the cursor of the next page.
+	if param, ok := c.GetQuery(cursorRequestQueryParamName); ok {
+		if _, ok := c.GetQuery(pageRequestQueryParamName); ok {
+			return domain.Pagination{}, ierr.New(ierr.InvalidArgument, "page param cannot be used with cursor")
+		}
+
+		cursor, err := decodeCursor(param)
+		if err != nil {
+			return domain.Pagination{}, err
+		}
+
+		pagination.After = &cursor
+	}
+
+	return pagination, nil
+}
+
+// encodeCursor makes opaque cursor param of the cursor, empty for nil one.
+func encodeCursor(cursor *domain.Cursor) string {
+	if cursor == nil {
+		return ""
+	}
+
+	raw := strconv.FormatInt(cursor.CreatedAt.UnixNano(), 10) + "," + cursor.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(param string) (domain.Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(param)
+	if err != nil {
+		return domain.Cursor{}, ierr.WrapCode(ierr.InvalidArgument, err, "wrong cursor param")
+	}
+
+	createdAt, id, ok := strings.Cut(string(raw), ",")
+	if !ok {
+		return domain.Cursor{}, ierr.New(ierr.InvalidArgument, "wrong cursor param")
+	}
+
+	nanos, err := strconv.ParseInt(createdAt, 10, 64)
+	if err != nil {
+		return domain.Cursor{}, ierr.WrapCode(ierr.InvalidArgument, err, "wrong cursor param")
+	}
+
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return domain.Cursor{}, ierr.WrapCode(ierr.InvalidArgument, err, "wrong cursor param")
+	}
+
+	return domain.Cursor{CreatedAt: time.Unix(0, nanos).UTC(), ID: uid}, nil
 }
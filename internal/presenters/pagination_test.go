@@ -0,0 +1,82 @@
+package presenters
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUrlPagination_GetPagination(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cursor := domain.Cursor{CreatedAt: time.Date(2023, 2, 17, 10, 0, 0, 123456000, time.UTC), ID: uuid.New()}
+	encoded := encodeCursor(&cursor)
+
+	tests := []struct {
+		name    string
+		query   string
+		want    domain.Pagination
+		wantErr bool
+	}{
+		{
+			name:  "defaults",
+			query: "",
+			want:  domain.Pagination{Page: 1, PerPage: 10},
+		},
+		{
+			name:  "page number",
+			query: "page=3&per-page=20",
+			want:  domain.Pagination{Page: 3, PerPage: 20},
+		},
+		{
+			name:  "cursor",
+			query: "cursor=" + encoded + "&per-page=20",
+			want:  domain.Pagination{Page: 1, PerPage: 20, After: &cursor},
+		},
+		{
+			name:    "cursor with page",
+			query:   "cursor=" + encoded + "&page=2",
+			wantErr: true,
+		},
+		{
+			name:    "wrong cursor encoding",
+			query:   "cursor=not*base64",
+			wantErr: true,
+		},
+		{
+			name:    "wrong cursor content",
+			query:   "cursor=" + base64.RawURLEncoding.EncodeToString([]byte("1676628000,not-uuid")),
+			wantErr: true,
+		},
+		{
+			name:    "wrong page",
+			query:   "page=first",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/dog?"+tt.query, nil)
+
+			got, err := NewUrlPagination().GetPagination(c)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Equal(t, ierr.InvalidArgument, ierr.GetCode(err))
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
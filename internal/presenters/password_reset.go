@@ -0,0 +1,93 @@
+package presenters
+
+import (
+	"net/http"
+
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/internal/presenters/messages"
+	"github.com/valerii-smirnov/petli-test-task/pkg/utils/gin/resp"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PasswordReset struct {
+	passwordResetUsecase PasswordResetUsecase
+
+	middlewares []gin.HandlerFunc
+}
+
+func NewPasswordReset(passwordResetUsecase PasswordResetUsecase, middlewares ...gin.HandlerFunc) *PasswordReset {
+	return &PasswordReset{
+		passwordResetUsecase: passwordResetUsecase,
+		middlewares:          middlewares,
+	}
+}
+
+func (p PasswordReset) Inject(r gin.IRouter) {
+	resetGroup := r.Group("/auth/password-reset")
+	if len(p.middlewares) > 0 {
+		resetGroup.Use(p.middlewares...)
+	}
+
+	resetGroup.POST("request", p.Request)
+	resetGroup.POST("confirm", p.Confirm)
+}
+
+// Request godoc
+// @Summary      Password reset request
+// @Description  Emails password reset link. Responds the same way whether email is registered or not.
+// @ID 			 Request password reset
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param 		 input body messages.PasswordResetRequestBody true "account email"
+// @Success      204
+// @Failure      400  {object}  messages.BadRequestError
+// @Failure      500  {object}  messages.InternalServerError
+// @Router       /auth/password-reset/request [post]
+func (p PasswordReset) Request(c *gin.Context) {
+	var req messages.PasswordResetRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	if err := p.passwordResetUsecase.Request(c, req.Email); err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	c.AbortWithStatus(http.StatusNoContent)
+}
+
+// Confirm godoc
+// @Summary      Password reset confirmation
+// @Description  Sets a new password using token from the reset link. All sessions of the user are invalidated.
+// @ID 			 Confirm password reset
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param 		 input body messages.PasswordResetConfirmRequestBody true "reset token and new password"
+// @Success      204
+// @Failure      400  {object}  messages.BadRequestError
+// @Failure      500  {object}  messages.InternalServerError
+// @Router       /auth/password-reset/confirm [post]
+func (p PasswordReset) Confirm(c *gin.Context) {
+	var req messages.PasswordResetConfirmRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	in := domain.PasswordResetConfirm{
+		Token:    domain.Token(req.Token),
+		Password: req.Password,
+	}
+
+	if err := p.passwordResetUsecase.Confirm(c, in); err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	c.AbortWithStatus(http.StatusNoContent)
+}
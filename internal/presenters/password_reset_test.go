@@ -0,0 +1,129 @@
+package presenters
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/internal/presenters/messages"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPasswordReset(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	controller := gomock.NewController(t)
+	mockPasswordResetUsecase := NewMockPasswordResetUsecase(controller)
+
+	email := "test@email.com"
+	confirm := domain.PasswordResetConfirm{
+		Token:    "reset-token",
+		Password: "new-password",
+	}
+
+	getRequestFn := func(url string, body interface{}) *http.Request {
+		b, err := json.Marshal(body)
+		if err != nil {
+			assert.Error(t, err)
+		}
+
+		req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(b))
+		if err != nil {
+			assert.Error(t, err)
+		}
+
+		return req
+	}
+
+	tests := []struct {
+		name              string
+		mocksInitFn       func()
+		getRequestFn      func() *http.Request
+		resultAssertionFn func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:        "request body validation error",
+			mocksInitFn: func() {},
+			getRequestFn: func() *http.Request {
+				return getRequestFn("/api/auth/password-reset/request", messages.PasswordResetRequestBody{Email: "wrong-email"})
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "request",
+			mocksInitFn: func() {
+				mockPasswordResetUsecase.EXPECT().Request(gomock.Any(), gomock.Eq(email)).Return(nil)
+			},
+			getRequestFn: func() *http.Request {
+				return getRequestFn("/api/auth/password-reset/request", messages.PasswordResetRequestBody{Email: email})
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+		{
+			name:        "confirm body validation error",
+			mocksInitFn: func() {},
+			getRequestFn: func() *http.Request {
+				return getRequestFn("/api/auth/password-reset/confirm", messages.PasswordResetConfirmRequestBody{Token: "reset-token"})
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "confirm with invalid token",
+			mocksInitFn: func() {
+				mockPasswordResetUsecase.EXPECT().Confirm(gomock.Any(), gomock.Eq(confirm)).
+					Return(ierr.New(ierr.InvalidArgument, "invalid password reset token"))
+			},
+			getRequestFn: func() *http.Request {
+				return getRequestFn("/api/auth/password-reset/confirm", messages.PasswordResetConfirmRequestBody{
+					Token:    string(confirm.Token),
+					Password: confirm.Password,
+				})
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "confirm",
+			mocksInitFn: func() {
+				mockPasswordResetUsecase.EXPECT().Confirm(gomock.Any(), gomock.Eq(confirm)).Return(nil)
+			},
+			getRequestFn: func() *http.Request {
+				return getRequestFn("/api/auth/password-reset/confirm", messages.PasswordResetConfirmRequestBody{
+					Token:    string(confirm.Token),
+					Password: confirm.Password,
+				})
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInitFn()
+
+			recorder := httptest.NewRecorder()
+			_, engine := gin.CreateTestContext(recorder)
+			engine = InitRoutes(engine, NewPasswordReset(mockPasswordResetUsecase))
+
+			req := tt.getRequestFn()
+			engine.ServeHTTP(recorder, req)
+			tt.resultAssertionFn(recorder)
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package presenters
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/internal/presenters/messages"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+	"github.com/valerii-smirnov/petli-test-task/pkg/utils/gin/resp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Recommendation presenter.
+type Recommendation struct {
+	recommendationUsecase RecommendationUsecase
+	identityExtractor     IdentityExtractor
+	paginator             Paginator
+
+	middlewares []gin.HandlerFunc
+}
+
+// NewRecommendation constructor.
+func NewRecommendation(
+	recommendationUsecase RecommendationUsecase,
+	identityExtractor IdentityExtractor,
+	paginator Paginator,
+	middlewares ...gin.HandlerFunc,
+) *Recommendation {
+	return &Recommendation{
+		recommendationUsecase: recommendationUsecase,
+		identityExtractor:     identityExtractor,
+		paginator:             paginator,
+		middlewares:           middlewares,
+	}
+}
+
+// Inject Injector implementation.
+func (rc Recommendation) Inject(r gin.IRouter) {
+	dogsGroup := r.Group("/dog")
+	if len(rc.middlewares) > 0 {
+		dogsGroup.Use(rc.middlewares...)
+	}
+
+	dogsGroup.GET("/:id/recommendations", rc.List)
+}
+
+// List http handler func to get dogs recommended for the dog to react to.
+// @Summary      Dog recommendations
+// @Description  Getting dogs of the feed ranked by preference fit, distance, recency, likelihood to like back and diversity of breeds and owners.
+// @Tags         dogs
+// @Security 	 ApiKeyAuth
+// @Accept       json
+// @Produce      json
+// @Param 		 id path string true "dog ID"
+// @Param 		 explain query bool false "return score breakdown of each dog, admins only"
+// @Param 		 page query string false "pagination page number"
+// @Param 		 per-page query string false "pagination per page items number"
+// @Success      200 {object} messages.RecommendationListResponseBody
+// @Failure      400  {object}  messages.BadRequestError
+// @Failure      403  {object}  messages.ForbiddenError
+// @Failure      404  {object}  messages.NotFoundError
+// @Failure      500  {object}  messages.InternalServerError
+// @Router       /dog/{id}/recommendations [get]
+func (rc Recommendation) List(c *gin.Context) {
+	userUid, err := rc.identityExtractor.ExtractFromContext(c)
+	if err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	dogUid, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		resp.AbortWithError(c, ierr.WrapCode(ierr.InvalidArgument, err, "wrong dog id param"))
+		return
+	}
+
+	pag, err := rc.paginator.GetPagination(c)
+	if err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	explain := false
+	if param, ok := c.GetQuery("explain"); ok {
+		if explain, err = strconv.ParseBool(param); err != nil {
+			resp.AbortWithError(c, ierr.WrapCode(ierr.InvalidArgument, err, "wrong explain param"))
+			return
+		}
+	}
+
+	// score breakdown tells how other dogs reacted, it's for tuning weights only.
+	if explain {
+		role, err := roleFromContext(c)
+		if err != nil {
+			resp.AbortWithError(c, err)
+			return
+		}
+
+		if !role.Can(domain.PermissionTuneRecommendations) {
+			resp.AbortWithError(c, ierr.New(ierr.PermissionDenied, "explaining recommendations is allowed to admins only"))
+			return
+		}
+	}
+
+	list, err := rc.recommendationUsecase.Recommend(c, userUid, dogUid, pag)
+	if err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, domainRecommendationsToMessage(list, explain))
+}
+
+func domainRecommendationsToMessage(list []domain.Recommendation, explain bool) messages.RecommendationListResponseBody {
+	body := make(messages.RecommendationListResponseBody, 0, len(list))
+	for _, rec := range list {
+		item := messages.RecommendationResponseBody{DogResponseBody: domainDogToMessage(rec.Dog)}
+		if explain {
+			item.Score = &messages.ScoreBreakdownResponseBody{
+				Preference: rec.Score.Preference,
+				Distance:   rec.Score.Distance,
+				Recency:    rec.Score.Recency,
+				LikeBack:   rec.Score.LikeBack,
+				Diversity:  rec.Score.Diversity,
+				Total:      rec.Score.Total,
+			}
+		}
+
+		body = append(body, item)
+	}
+
+	return body
+}
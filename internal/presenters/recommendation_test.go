@@ -0,0 +1,159 @@
+package presenters
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/internal/presenters/messages"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+	"github.com/valerii-smirnov/petli-test-task/pkg/token"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecommendation_List(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	controller := gomock.NewController(t)
+	mockRecommendationUsecase := NewMockRecommendationUsecase(controller)
+	mockIdentityExtractor := NewMockIdentityExtractor(controller)
+	tokenProcessor := token.NewJWT(token.NewHMACKeySet("test-secret"), time.Minute*5)
+	authMiddleware := newTestAuthMiddleware(controller, tokenProcessor)
+
+	userID := uuid.New()
+	dogID := uuid.New()
+	candidateID := uuid.New()
+
+	pagination := domain.Pagination{Page: 1, PerPage: 10}
+	list := []domain.Recommendation{{
+		Dog:   domain.Dog{ID: candidateID, Name: "dog", Sex: domain.Female},
+		Score: domain.ScoreBreakdown{Preference: 0.75, Distance: 0.5, Recency: 1, LikeBack: 0.5, Diversity: 1, Total: 3.75},
+	}}
+
+	tests := []struct {
+		name              string
+		mocksInitFn       func()
+		url               string
+		role              domain.Role
+		resultAssertionFn func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "wrong dog id",
+			mocksInitFn: func() {
+				mockIdentityExtractor.EXPECT().ExtractFromContext(gomock.Any()).Return(userID, nil)
+			},
+			url: "/api/dog/wrong/recommendations",
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "wrong explain",
+			mocksInitFn: func() {
+				mockIdentityExtractor.EXPECT().ExtractFromContext(gomock.Any()).Return(userID, nil)
+			},
+			url: fmt.Sprintf("/api/dog/%s/recommendations?explain=maybe", dogID),
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "recommendations of not your dog",
+			mocksInitFn: func() {
+				mockIdentityExtractor.EXPECT().ExtractFromContext(gomock.Any()).Return(userID, nil)
+				mockRecommendationUsecase.EXPECT().Recommend(gomock.Any(), userID, dogID, pagination).
+					Return(nil, ierr.New(ierr.PermissionDenied, "cannot get recommendations of not your dog"))
+			},
+			url: fmt.Sprintf("/api/dog/%s/recommendations", dogID),
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "success without score",
+			mocksInitFn: func() {
+				mockIdentityExtractor.EXPECT().ExtractFromContext(gomock.Any()).Return(userID, nil)
+				mockRecommendationUsecase.EXPECT().Recommend(gomock.Any(), userID, dogID, pagination).Return(list, nil)
+			},
+			url: fmt.Sprintf("/api/dog/%s/recommendations", dogID),
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var body messages.RecommendationListResponseBody
+				assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+				assert.Len(t, body, 1)
+				assert.Equal(t, candidateID.String(), body[0].ID)
+				assert.Nil(t, body[0].Score)
+			},
+		},
+		{
+			name: "score is explained to admins only",
+			mocksInitFn: func() {
+				mockIdentityExtractor.EXPECT().ExtractFromContext(gomock.Any()).Return(userID, nil)
+			},
+			url:  fmt.Sprintf("/api/dog/%s/recommendations?explain=true", dogID),
+			role: domain.RoleUser,
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+				assert.NotContains(t, recorder.Body.String(), "score")
+			},
+		},
+		{
+			name: "success with score",
+			mocksInitFn: func() {
+				mockIdentityExtractor.EXPECT().ExtractFromContext(gomock.Any()).Return(userID, nil)
+				mockRecommendationUsecase.EXPECT().Recommend(gomock.Any(), userID, dogID, pagination).Return(list, nil)
+			},
+			url:  fmt.Sprintf("/api/dog/%s/recommendations?explain=true", dogID),
+			role: domain.RoleAdmin,
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var body messages.RecommendationListResponseBody
+				assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+				assert.Len(t, body, 1)
+				assert.Equal(t, &messages.ScoreBreakdownResponseBody{
+					Preference: 0.75,
+					Distance:   0.5,
+					Recency:    1,
+					LikeBack:   0.5,
+					Diversity:  1,
+					Total:      3.75,
+				}, body[0].Score)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInitFn()
+
+			recorder := httptest.NewRecorder()
+			_, engine := gin.CreateTestContext(recorder)
+			engine = InitRoutes(engine, NewRecommendation(mockRecommendationUsecase, mockIdentityExtractor, NewUrlPagination(), authMiddleware.Auth))
+
+			role := tt.role
+			if role == "" {
+				role = domain.RoleUser
+			}
+
+			st, err := tokenProcessor.Generate(userID, role.String())
+			assert.NoError(t, err)
+
+			req, err := http.NewRequest(http.MethodGet, tt.url, nil)
+			assert.NoError(t, err)
+			req.Header.Set(AuthorizationHeaderName, fmt.Sprintf("%s%s", bearerPrefix, st))
+
+			engine.ServeHTTP(recorder, req)
+			tt.resultAssertionFn(recorder)
+		})
+	}
+}
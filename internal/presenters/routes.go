@@ -25,10 +25,13 @@ import (
 // @securityDefinitions.apikey	ApiKeyAuth
 // @in							header
 // @name						Authorization
-// @description					As value you have to use string Bearer + 'received token after sign-in action'
+// @description					As value you have to use string Bearer + 'received token after sign-in action' or ApiKey + 'personal API key'
 
 func InitRoutes(engine *gin.Engine, injectors ...RoutesInjector) *gin.Engine {
-	apiGroup := engine.Group("/api", ErrorHandler)
+	// values put to request context by middlewares, e.g. ClientContext, are visible through gin.Context passed to usecases.
+	engine.ContextWithFallback = true
+
+	apiGroup := engine.Group("/api", ErrorHandler, ClientContext)
 	for _, injector := range injectors {
 		injector.Inject(apiGroup)
 	}
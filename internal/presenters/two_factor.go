@@ -0,0 +1,146 @@
+package presenters
+
+import (
+	"net/http"
+
+	"github.com/valerii-smirnov/petli-test-task/internal/presenters/messages"
+	"github.com/valerii-smirnov/petli-test-task/pkg/utils/gin/resp"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TwoFactor presenter of TOTP two-factor authentication management.
+type TwoFactor struct {
+	twoFactorUsecase  TwoFactorUsecase
+	identityExtractor IdentityExtractor
+
+	middlewares []gin.HandlerFunc
+}
+
+func NewTwoFactor(
+	twoFactorUsecase TwoFactorUsecase,
+	identityExtractor IdentityExtractor,
+	middlewares ...gin.HandlerFunc,
+) *TwoFactor {
+	return &TwoFactor{
+		twoFactorUsecase:  twoFactorUsecase,
+		identityExtractor: identityExtractor,
+		middlewares:       middlewares,
+	}
+}
+
+func (t TwoFactor) Inject(r gin.IRouter) {
+	twoFactorGroup := r.Group("/auth/2fa")
+	if len(t.middlewares) > 0 {
+		twoFactorGroup.Use(t.middlewares...)
+	}
+
+	twoFactorGroup.POST("enroll", t.Enroll)
+	twoFactorGroup.POST("confirm", t.Confirm)
+	twoFactorGroup.POST("disable", t.Disable)
+}
+
+// Enroll godoc
+// @Summary      Two-factor authentication enrollment
+// @Description  Issues TOTP secret, provisioning uri for authenticator apps and one-time recovery codes.
+// @Description  Two-factor authentication is enabled only after the first code is confirmed. Recovery codes are shown only once.
+// @ID 			 Enroll two-factor authentication
+// @Tags         auth
+// @Security 	 ApiKeyAuth
+// @Accept       json
+// @Produce      json
+// @Success      200 {object} messages.TwoFactorEnrollResponseBody
+// @Failure      401  {object}  messages.UnauthenticatedError
+// @Failure      409  {object}  messages.ConflictError
+// @Failure      500  {object}  messages.InternalServerError
+// @Router       /auth/2fa/enroll [post]
+func (t TwoFactor) Enroll(c *gin.Context) {
+	uid, err := t.identityExtractor.ExtractFromContext(c)
+	if err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	enrollment, err := t.twoFactorUsecase.Enroll(c, uid)
+	if err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, messages.TwoFactorEnrollResponseBody{
+		Secret:          enrollment.Secret,
+		ProvisioningURI: enrollment.ProvisioningURI,
+		RecoveryCodes:   enrollment.RecoveryCodes,
+	})
+}
+
+// Confirm godoc
+// @Summary      Two-factor authentication confirmation
+// @Description  Enables two-factor authentication with the first code generated by the enrolled authenticator.
+// @ID 			 Confirm two-factor authentication
+// @Tags         auth
+// @Security 	 ApiKeyAuth
+// @Accept       json
+// @Produce      json
+// @Param 		 input body messages.TwoFactorCodeRequestBody true "authenticator code"
+// @Success      204
+// @Failure      400  {object}  messages.BadRequestError
+// @Failure      401  {object}  messages.UnauthenticatedError
+// @Failure      409  {object}  messages.ConflictError
+// @Failure      500  {object}  messages.InternalServerError
+// @Router       /auth/2fa/confirm [post]
+func (t TwoFactor) Confirm(c *gin.Context) {
+	uid, err := t.identityExtractor.ExtractFromContext(c)
+	if err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	var req messages.TwoFactorCodeRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	if err := t.twoFactorUsecase.Confirm(c, uid, req.Code); err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	c.AbortWithStatus(http.StatusNoContent)
+}
+
+// Disable godoc
+// @Summary      Two-factor authentication disabling
+// @Description  Disables two-factor authentication, either authenticator code or recovery code is required.
+// @ID 			 Disable two-factor authentication
+// @Tags         auth
+// @Security 	 ApiKeyAuth
+// @Accept       json
+// @Produce      json
+// @Param 		 input body messages.TwoFactorCodeRequestBody true "authenticator or recovery code"
+// @Success      204
+// @Failure      400  {object}  messages.BadRequestError
+// @Failure      401  {object}  messages.UnauthenticatedError
+// @Failure      500  {object}  messages.InternalServerError
+// @Router       /auth/2fa/disable [post]
+func (t TwoFactor) Disable(c *gin.Context) {
+	uid, err := t.identityExtractor.ExtractFromContext(c)
+	if err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	var req messages.TwoFactorCodeRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	if err := t.twoFactorUsecase.Disable(c, uid, req.Code); err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	c.AbortWithStatus(http.StatusNoContent)
+}
@@ -0,0 +1,159 @@
+package presenters
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/internal/presenters/messages"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTwoFactor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	controller := gomock.NewController(t)
+	mockTwoFactorUsecase := NewMockTwoFactorUsecase(controller)
+	mockIdentityExtractor := NewMockIdentityExtractor(controller)
+
+	userID := uuid.New()
+	code := "123456"
+
+	enrollment := domain.TOTPEnrollment{
+		Secret:          "JBSWY3DPEHPK3PXP",
+		ProvisioningURI: "otpauth://totp/Petly:test@email.com?secret=JBSWY3DPEHPK3PXP",
+		RecoveryCodes:   []string{"aaaaa-bbbbb", "ccccc-ddddd"},
+	}
+
+	getRequestFn := func(url string, body interface{}) *http.Request {
+		b, err := json.Marshal(body)
+		if err != nil {
+			assert.Error(t, err)
+		}
+
+		req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(b))
+		if err != nil {
+			assert.Error(t, err)
+		}
+
+		return req
+	}
+
+	tests := []struct {
+		name              string
+		mocksInitFn       func()
+		getRequestFn      func() *http.Request
+		resultAssertionFn func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "enroll when already enabled",
+			mocksInitFn: func() {
+				mockIdentityExtractor.EXPECT().ExtractFromContext(gomock.Any()).Return(userID, nil)
+				mockTwoFactorUsecase.EXPECT().Enroll(gomock.Any(), gomock.Eq(userID)).
+					Return(domain.TOTPEnrollment{}, ierr.New(ierr.AlreadyExists, "two-factor authentication is already enabled"))
+			},
+			getRequestFn: func() *http.Request {
+				return getRequestFn("/api/auth/2fa/enroll", nil)
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name: "enroll",
+			mocksInitFn: func() {
+				mockIdentityExtractor.EXPECT().ExtractFromContext(gomock.Any()).Return(userID, nil)
+				mockTwoFactorUsecase.EXPECT().Enroll(gomock.Any(), gomock.Eq(userID)).Return(enrollment, nil)
+			},
+			getRequestFn: func() *http.Request {
+				return getRequestFn("/api/auth/2fa/enroll", nil)
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var body messages.TwoFactorEnrollResponseBody
+				if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+					assert.Error(t, err)
+				}
+
+				assert.Equal(t, enrollment.Secret, body.Secret)
+				assert.Equal(t, enrollment.ProvisioningURI, body.ProvisioningURI)
+				assert.Equal(t, enrollment.RecoveryCodes, body.RecoveryCodes)
+			},
+		},
+		{
+			name: "confirm body validation error",
+			mocksInitFn: func() {
+				mockIdentityExtractor.EXPECT().ExtractFromContext(gomock.Any()).Return(userID, nil)
+			},
+			getRequestFn: func() *http.Request {
+				return getRequestFn("/api/auth/2fa/confirm", messages.TwoFactorCodeRequestBody{})
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "confirm",
+			mocksInitFn: func() {
+				mockIdentityExtractor.EXPECT().ExtractFromContext(gomock.Any()).Return(userID, nil)
+				mockTwoFactorUsecase.EXPECT().Confirm(gomock.Any(), gomock.Eq(userID), gomock.Eq(code)).Return(nil)
+			},
+			getRequestFn: func() *http.Request {
+				return getRequestFn("/api/auth/2fa/confirm", messages.TwoFactorCodeRequestBody{Code: code})
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+		{
+			name: "disable with invalid code",
+			mocksInitFn: func() {
+				mockIdentityExtractor.EXPECT().ExtractFromContext(gomock.Any()).Return(userID, nil)
+				mockTwoFactorUsecase.EXPECT().Disable(gomock.Any(), gomock.Eq(userID), gomock.Eq(code)).
+					Return(ierr.New(ierr.InvalidArgument, "invalid two-factor code"))
+			},
+			getRequestFn: func() *http.Request {
+				return getRequestFn("/api/auth/2fa/disable", messages.TwoFactorCodeRequestBody{Code: code})
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "disable",
+			mocksInitFn: func() {
+				mockIdentityExtractor.EXPECT().ExtractFromContext(gomock.Any()).Return(userID, nil)
+				mockTwoFactorUsecase.EXPECT().Disable(gomock.Any(), gomock.Eq(userID), gomock.Eq(code)).Return(nil)
+			},
+			getRequestFn: func() *http.Request {
+				return getRequestFn("/api/auth/2fa/disable", messages.TwoFactorCodeRequestBody{Code: code})
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInitFn()
+
+			recorder := httptest.NewRecorder()
+			_, engine := gin.CreateTestContext(recorder)
+			engine = InitRoutes(engine, NewTwoFactor(mockTwoFactorUsecase, mockIdentityExtractor))
+
+			req := tt.getRequestFn()
+			engine.ServeHTTP(recorder, req)
+			tt.resultAssertionFn(recorder)
+		})
+	}
+}
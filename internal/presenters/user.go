@@ -0,0 +1,351 @@
+package presenters
+
+import (
+	"net/http"
+
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/internal/presenters/messages"
+	"github.com/valerii-smirnov/petli-test-task/pkg/utils/gin/resp"
+
+	"github.com/gin-gonic/gin"
+)
+
+// User presenter of the signed-in user profile and account.
+type User struct {
+	userUsecase       UserUsecase
+	identityExtractor IdentityExtractor
+	paginator         Paginator
+
+	middlewares []gin.HandlerFunc
+}
+
+func NewUser(
+	userUsecase UserUsecase,
+	identityExtractor IdentityExtractor,
+	paginator Paginator,
+	middlewares ...gin.HandlerFunc,
+) *User {
+	return &User{
+		userUsecase:       userUsecase,
+		identityExtractor: identityExtractor,
+		paginator:         paginator,
+		middlewares:       middlewares,
+	}
+}
+
+func (u User) Inject(r gin.IRouter) {
+	// confirmation link can be opened on another device, the token from the link is enough to authorize it.
+	r.GET("/me/email/confirm", u.ConfirmEmailChange)
+
+	meGroup := r.Group("/me")
+	if len(u.middlewares) > 0 {
+		meGroup.Use(u.middlewares...)
+	}
+
+	meGroup.GET("", u.Get)
+	meGroup.PUT("", u.UpdateProfile)
+	meGroup.PUT("/password", u.ChangePassword)
+	meGroup.POST("/email", u.RequestEmailChange)
+	meGroup.DELETE("", u.ScheduleDeletion)
+	meGroup.DELETE("/deletion", u.CancelDeletion)
+	meGroup.GET("/security-events", u.SecurityEvents)
+}
+
+// Get godoc
+// @Summary      Current user
+// @Description  Returns account and profile of the signed-in user
+// @ID 			 Get current user
+// @Tags         me
+// @Security 	 ApiKeyAuth
+// @Produce      json
+// @Success      200 {object} messages.MeResponseBody
+// @Failure      401  {object}  messages.UnauthenticatedError
+// @Failure      500  {object}  messages.InternalServerError
+// @Router       /me [get]
+func (u User) Get(c *gin.Context) {
+	uid, err := u.identityExtractor.ExtractFromContext(c)
+	if err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	user, err := u.userUsecase.Get(c, uid)
+	if err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, domainUserToMessage(user))
+}
+
+// UpdateProfile godoc
+// @Summary      Profile update
+// @Description  Replaces profile fields of the signed-in user
+// @ID 			 Update profile
+// @Tags         me
+// @Security 	 ApiKeyAuth
+// @Accept       json
+// @Produce      json
+// @Param 		 input body messages.UpdateProfileRequestBody true "profile"
+// @Success      200 {object} messages.MeResponseBody
+// @Failure      400  {object}  messages.BadRequestError
+// @Failure      401  {object}  messages.UnauthenticatedError
+// @Failure      500  {object}  messages.InternalServerError
+// @Router       /me [put]
+func (u User) UpdateProfile(c *gin.Context) {
+	var req messages.UpdateProfileRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	uid, err := u.identityExtractor.ExtractFromContext(c)
+	if err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	profile := domain.Profile{
+		DisplayName: req.DisplayName,
+		City:        req.City,
+		Bio:         req.Bio,
+		AvatarURL:   req.AvatarURL,
+	}
+
+	user, err := u.userUsecase.UpdateProfile(c, uid, profile)
+	if err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, domainUserToMessage(user))
+}
+
+// ChangePassword godoc
+// @Summary      Password change
+// @Description  Sets a new password, the current password is required. All sessions of the user are signed out.
+// @ID 			 Change password
+// @Tags         me
+// @Security 	 ApiKeyAuth
+// @Accept       json
+// @Produce      json
+// @Param 		 input body messages.ChangePasswordRequestBody true "current and new password"
+// @Success      204
+// @Failure      400  {object}  messages.BadRequestError
+// @Failure      401  {object}  messages.UnauthenticatedError
+// @Failure      403  {object}  messages.ForbiddenError
+// @Failure      500  {object}  messages.InternalServerError
+// @Router       /me/password [put]
+func (u User) ChangePassword(c *gin.Context) {
+	var req messages.ChangePasswordRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	uid, err := u.identityExtractor.ExtractFromContext(c)
+	if err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	in := domain.PasswordChange{
+		CurrentPassword: req.CurrentPassword,
+		NewPassword:     req.NewPassword,
+	}
+
+	if err := u.userUsecase.ChangePassword(c, uid, in); err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	c.AbortWithStatus(http.StatusNoContent)
+}
+
+// RequestEmailChange godoc
+// @Summary      Email change
+// @Description  Sends confirmation link to the new email, the email is changed once the link is followed.
+// @ID 			 Change email
+// @Tags         me
+// @Security 	 ApiKeyAuth
+// @Accept       json
+// @Produce      json
+// @Param 		 input body messages.ChangeEmailRequestBody true "new email and current password"
+// @Success      202
+// @Failure      400  {object}  messages.BadRequestError
+// @Failure      401  {object}  messages.UnauthenticatedError
+// @Failure      403  {object}  messages.ForbiddenError
+// @Failure      409  {object}  messages.ConflictError
+// @Failure      500  {object}  messages.InternalServerError
+// @Router       /me/email [post]
+func (u User) RequestEmailChange(c *gin.Context) {
+	var req messages.ChangeEmailRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	uid, err := u.identityExtractor.ExtractFromContext(c)
+	if err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	in := domain.EmailChange{
+		NewEmail:        req.NewEmail,
+		CurrentPassword: req.CurrentPassword,
+	}
+
+	if err := u.userUsecase.RequestEmailChange(c, uid, in); err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	c.AbortWithStatus(http.StatusAccepted)
+}
+
+// ConfirmEmailChange godoc
+// @Summary      Email change confirmation
+// @Description  Consumes confirmation link sent to the new email
+// @ID 			 Confirm email change
+// @Tags         me
+// @Produce      json
+// @Param 		 token query string true "confirmation token from the link"
+// @Success      204
+// @Failure      400  {object}  messages.BadRequestError
+// @Failure      409  {object}  messages.ConflictError
+// @Failure      500  {object}  messages.InternalServerError
+// @Router       /me/email/confirm [get]
+func (u User) ConfirmEmailChange(c *gin.Context) {
+	var req messages.ConfirmEmailChangeRequestQuery
+	if err := c.ShouldBindQuery(&req); err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	if err := u.userUsecase.ConfirmEmailChange(c, req.Token); err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	c.AbortWithStatus(http.StatusNoContent)
+}
+
+// ScheduleDeletion godoc
+// @Summary      Account deletion
+// @Description  Schedules the account with all its dogs to be deleted once the grace period passes, the current password is required.
+// @Description  All sessions and API keys of the user are revoked immediately and the dogs are hidden from other users.
+// @Description  Until the deletion the user can sign in again and cancel it.
+// @ID 			 Delete account
+// @Tags         me
+// @Security 	 ApiKeyAuth
+// @Accept       json
+// @Produce      json
+// @Param 		 input body messages.DeleteAccountRequestBody true "current password"
+// @Success      202 {object} messages.DeleteAccountResponseBody
+// @Failure      400  {object}  messages.BadRequestError
+// @Failure      401  {object}  messages.UnauthenticatedError
+// @Failure      403  {object}  messages.ForbiddenError
+// @Failure      409  {object}  messages.ConflictError
+// @Failure      500  {object}  messages.InternalServerError
+// @Router       /me [delete]
+func (u User) ScheduleDeletion(c *gin.Context) {
+	var req messages.DeleteAccountRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	uid, err := u.identityExtractor.ExtractFromContext(c)
+	if err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	deleteAt, err := u.userUsecase.ScheduleDeletion(c, uid, domain.AccountDeletion{CurrentPassword: req.CurrentPassword})
+	if err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, messages.DeleteAccountResponseBody{DeletionScheduledAt: deleteAt})
+}
+
+// CancelDeletion godoc
+// @Summary      Account deletion cancel
+// @Description  Cancels scheduled deletion of the signed-in user account
+// @ID 			 Cancel account deletion
+// @Tags         me
+// @Security 	 ApiKeyAuth
+// @Produce      json
+// @Success      204
+// @Failure      400  {object}  messages.BadRequestError
+// @Failure      401  {object}  messages.UnauthenticatedError
+// @Failure      500  {object}  messages.InternalServerError
+// @Router       /me/deletion [delete]
+func (u User) CancelDeletion(c *gin.Context) {
+	uid, err := u.identityExtractor.ExtractFromContext(c)
+	if err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	if err := u.userUsecase.CancelDeletion(c, uid); err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	c.AbortWithStatus(http.StatusNoContent)
+}
+
+// SecurityEvents godoc
+// @Summary      Security events
+// @Description  Returns sign-ins, password changes and other security events of the signed-in user, the newest first
+// @ID 			 List security events
+// @Tags         me
+// @Security 	 ApiKeyAuth
+// @Produce      json
+// @Param 		 page query string false "pagination page number"
+// @Param 		 per-page query string false "pagination per page items number"
+// @Success      200 {object} messages.AuditEventListResponseBody
+// @Failure      400  {object}  messages.BadRequestError
+// @Failure      401  {object}  messages.UnauthenticatedError
+// @Failure      500  {object}  messages.InternalServerError
+// @Router       /me/security-events [get]
+func (u User) SecurityEvents(c *gin.Context) {
+	uid, err := u.identityExtractor.ExtractFromContext(c)
+	if err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	pag, err := u.paginator.GetPagination(c)
+	if err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	events, err := u.userUsecase.SecurityEvents(c, uid, pag)
+	if err != nil {
+		resp.AbortWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, domainAuditEventsToMessage(events))
+}
+
+func domainUserToMessage(user domain.User) messages.MeResponseBody {
+	return messages.MeResponseBody{
+		ID:                  user.ID.String(),
+		Email:               user.Email,
+		EmailVerified:       user.EmailVerifiedAt != nil,
+		Role:                user.Role.String(),
+		TwoFactorEnabled:    user.TOTPEnabledAt != nil,
+		DisplayName:         user.Profile.DisplayName,
+		City:                user.Profile.City,
+		Bio:                 user.Profile.Bio,
+		AvatarURL:           user.Profile.AvatarURL,
+		RegisteredAt:        user.RegisteredAt,
+		DeletionScheduledAt: user.DeletionScheduledAt,
+	}
+}
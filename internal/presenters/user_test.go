@@ -0,0 +1,297 @@
+package presenters
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/internal/presenters/messages"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	controller := gomock.NewController(t)
+	mockUserUsecase := NewMockUserUsecase(controller)
+	mockIdentityExtractor := NewMockIdentityExtractor(controller)
+
+	userID := uuid.New()
+	verifiedAt := time.Now()
+	profile := domain.Profile{
+		DisplayName: "Spike's owner",
+		City:        "Kyiv",
+		Bio:         "Bulldogs only",
+		AvatarURL:   "https://example.com/avatar.png",
+	}
+	user := domain.User{
+		ID:              userID,
+		Email:           "test@email.com",
+		Role:            domain.RoleUser,
+		RegisteredAt:    time.Now().UTC().Truncate(time.Second),
+		EmailVerifiedAt: &verifiedAt,
+		Profile:         profile,
+	}
+
+	deleteAt := time.Now().Add(30 * 24 * time.Hour).UTC().Truncate(time.Second)
+	eventID := uuid.New()
+
+	getRequestFn := func(method, url string, body interface{}) *http.Request {
+		b, err := json.Marshal(body)
+		if err != nil {
+			assert.Error(t, err)
+		}
+
+		req, err := http.NewRequest(method, url, bytes.NewReader(b))
+		if err != nil {
+			assert.Error(t, err)
+		}
+
+		return req
+	}
+
+	tests := []struct {
+		name              string
+		mocksInitFn       func()
+		getRequestFn      func() *http.Request
+		resultAssertionFn func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "get",
+			mocksInitFn: func() {
+				mockIdentityExtractor.EXPECT().ExtractFromContext(gomock.Any()).Return(userID, nil)
+				mockUserUsecase.EXPECT().Get(gomock.Any(), userID).Return(user, nil)
+			},
+			getRequestFn: func() *http.Request {
+				return getRequestFn(http.MethodGet, "/api/me", nil)
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var body messages.MeResponseBody
+				if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+					assert.Error(t, err)
+				}
+
+				assert.Equal(t, messages.MeResponseBody{
+					ID:            userID.String(),
+					Email:         "test@email.com",
+					EmailVerified: true,
+					Role:          "user",
+					DisplayName:   profile.DisplayName,
+					City:          profile.City,
+					Bio:           profile.Bio,
+					AvatarURL:     profile.AvatarURL,
+					RegisteredAt:  user.RegisteredAt,
+				}, body)
+			},
+		},
+		{
+			name:        "update profile with invalid avatar url",
+			mocksInitFn: func() {},
+			getRequestFn: func() *http.Request {
+				return getRequestFn(http.MethodPut, "/api/me", messages.UpdateProfileRequestBody{AvatarURL: "not url"})
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "update profile",
+			mocksInitFn: func() {
+				mockIdentityExtractor.EXPECT().ExtractFromContext(gomock.Any()).Return(userID, nil)
+				mockUserUsecase.EXPECT().UpdateProfile(gomock.Any(), userID, profile).Return(user, nil)
+			},
+			getRequestFn: func() *http.Request {
+				return getRequestFn(http.MethodPut, "/api/me", messages.UpdateProfileRequestBody{
+					DisplayName: profile.DisplayName,
+					City:        profile.City,
+					Bio:         profile.Bio,
+					AvatarURL:   profile.AvatarURL,
+				})
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "change password with wrong current password",
+			mocksInitFn: func() {
+				mockIdentityExtractor.EXPECT().ExtractFromContext(gomock.Any()).Return(userID, nil)
+				mockUserUsecase.EXPECT().ChangePassword(gomock.Any(), userID, domain.PasswordChange{CurrentPassword: "wrong", NewPassword: "new"}).
+					Return(ierr.New(ierr.PermissionDenied, "current password is wrong"))
+			},
+			getRequestFn: func() *http.Request {
+				return getRequestFn(http.MethodPut, "/api/me/password", messages.ChangePasswordRequestBody{CurrentPassword: "wrong", NewPassword: "new"})
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "change password",
+			mocksInitFn: func() {
+				mockIdentityExtractor.EXPECT().ExtractFromContext(gomock.Any()).Return(userID, nil)
+				mockUserUsecase.EXPECT().ChangePassword(gomock.Any(), userID, domain.PasswordChange{CurrentPassword: "current", NewPassword: "new"}).Return(nil)
+			},
+			getRequestFn: func() *http.Request {
+				return getRequestFn(http.MethodPut, "/api/me/password", messages.ChangePasswordRequestBody{CurrentPassword: "current", NewPassword: "new"})
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+		{
+			name:        "change email validation error",
+			mocksInitFn: func() {},
+			getRequestFn: func() *http.Request {
+				return getRequestFn(http.MethodPost, "/api/me/email", messages.ChangeEmailRequestBody{NewEmail: "not email", CurrentPassword: "current"})
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "change email",
+			mocksInitFn: func() {
+				mockIdentityExtractor.EXPECT().ExtractFromContext(gomock.Any()).Return(userID, nil)
+				mockUserUsecase.EXPECT().RequestEmailChange(gomock.Any(), userID, domain.EmailChange{NewEmail: "new@email.com", CurrentPassword: "current"}).Return(nil)
+			},
+			getRequestFn: func() *http.Request {
+				return getRequestFn(http.MethodPost, "/api/me/email", messages.ChangeEmailRequestBody{NewEmail: "new@email.com", CurrentPassword: "current"})
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusAccepted, recorder.Code)
+			},
+		},
+		{
+			name: "confirm email change",
+			mocksInitFn: func() {
+				mockUserUsecase.EXPECT().ConfirmEmailChange(gomock.Any(), "token").Return(nil)
+			},
+			getRequestFn: func() *http.Request {
+				return getRequestFn(http.MethodGet, "/api/me/email/confirm?token=token", nil)
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+		{
+			name:        "delete account without password",
+			mocksInitFn: func() {},
+			getRequestFn: func() *http.Request {
+				return getRequestFn(http.MethodDelete, "/api/me", messages.DeleteAccountRequestBody{})
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "delete account",
+			mocksInitFn: func() {
+				mockIdentityExtractor.EXPECT().ExtractFromContext(gomock.Any()).Return(userID, nil)
+				mockUserUsecase.EXPECT().ScheduleDeletion(gomock.Any(), userID, domain.AccountDeletion{CurrentPassword: "current"}).
+					Return(deleteAt, nil)
+			},
+			getRequestFn: func() *http.Request {
+				return getRequestFn(http.MethodDelete, "/api/me", messages.DeleteAccountRequestBody{CurrentPassword: "current"})
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusAccepted, recorder.Code)
+
+				var body messages.DeleteAccountResponseBody
+				if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+					assert.Error(t, err)
+				}
+
+				assert.Equal(t, messages.DeleteAccountResponseBody{DeletionScheduledAt: deleteAt}, body)
+			},
+		},
+		{
+			name: "security events",
+			mocksInitFn: func() {
+				mockIdentityExtractor.EXPECT().ExtractFromContext(gomock.Any()).Return(userID, nil)
+				mockUserUsecase.EXPECT().SecurityEvents(gomock.Any(), userID, domain.Pagination{Page: 1, PerPage: 10}).
+					Return([]domain.AuditEvent{{ID: eventID, UserID: &userID, Type: domain.AuditPasswordChange, Outcome: domain.AuditSuccess}}, nil)
+			},
+			getRequestFn: func() *http.Request {
+				return getRequestFn(http.MethodGet, "/api/me/security-events", nil)
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+
+				var body messages.AuditEventListResponseBody
+				assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+				assert.Equal(t, messages.AuditEventListResponseBody{{
+					ID:      eventID.String(),
+					UserID:  userID.String(),
+					Type:    "password_change",
+					Outcome: "success",
+				}}, body)
+			},
+		},
+		{
+			name: "cancel not scheduled deletion",
+			mocksInitFn: func() {
+				mockIdentityExtractor.EXPECT().ExtractFromContext(gomock.Any()).Return(userID, nil)
+				mockUserUsecase.EXPECT().CancelDeletion(gomock.Any(), userID).
+					Return(ierr.New(ierr.InvalidArgument, "account deletion is not scheduled"))
+			},
+			getRequestFn: func() *http.Request {
+				return getRequestFn(http.MethodDelete, "/api/me/deletion", nil)
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "cancel deletion",
+			mocksInitFn: func() {
+				mockIdentityExtractor.EXPECT().ExtractFromContext(gomock.Any()).Return(userID, nil)
+				mockUserUsecase.EXPECT().CancelDeletion(gomock.Any(), userID).Return(nil)
+			},
+			getRequestFn: func() *http.Request {
+				return getRequestFn(http.MethodDelete, "/api/me/deletion", nil)
+			},
+			resultAssertionFn: func(recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInitFn()
+
+			recorder := httptest.NewRecorder()
+			_, engine := gin.CreateTestContext(recorder)
+			engine = InitRoutes(engine, NewUser(mockUserUsecase, mockIdentityExtractor, NewUrlPagination()))
+
+			req := tt.getRequestFn()
+			engine.ServeHTTP(recorder, req)
+			tt.resultAssertionFn(recorder)
+		})
+	}
+
+	t.Run("confirm email change is not authenticated", func(t *testing.T) {
+		mockUserUsecase.EXPECT().ConfirmEmailChange(gomock.Any(), "token").Return(nil)
+
+		recorder := httptest.NewRecorder()
+		_, engine := gin.CreateTestContext(recorder)
+		engine = InitRoutes(engine, NewUser(mockUserUsecase, mockIdentityExtractor, NewUrlPagination(), func(c *gin.Context) {
+			c.AbortWithStatus(http.StatusUnauthorized)
+		}))
+
+		engine.ServeHTTP(recorder, getRequestFn(http.MethodGet, "/api/me/email/confirm?token=token", nil))
+		assert.Equal(t, http.StatusNoContent, recorder.Code)
+	})
+}
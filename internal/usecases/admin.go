@@ -0,0 +1,74 @@
+package usecases
+
+import (
+	"context"
+
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+
+	"github.com/google/uuid"
+)
+
+// Admin manages users on behalf of administrators.
+type Admin struct {
+	userAdapter       UserAdapter
+	auditEventAdapter AuditEventAdapter
+}
+
+func NewAdmin(userAdapter UserAdapter, auditEventAdapter AuditEventAdapter) *Admin {
+	return &Admin{
+		userAdapter:       userAdapter,
+		auditEventAdapter: auditEventAdapter,
+	}
+}
+
+// AssignRole changes role of the user. Actors can't change their own role, so the last admin can't lock everybody out.
+func (a Admin) AssignRole(ctx context.Context, actorID, userID uuid.UUID, role domain.Role) error {
+	if !role.Valid() {
+		return ierr.New(ierr.InvalidArgument, "unknown role")
+	}
+
+	if actorID == userID {
+		return ierr.New(ierr.InvalidArgument, "cannot change your own role")
+	}
+
+	if err := requirePermission(ctx, a.userAdapter, actorID, domain.PermissionManageUsers, "manage users"); err != nil {
+		return err
+	}
+
+	return a.userAdapter.UpdateRole(ctx, userID, role)
+}
+
+// SearchAuditEvents returns security events of all users matching the filter, the newest first.
+func (a Admin) SearchAuditEvents(ctx context.Context, actorID uuid.UUID, filter domain.AuditEventFilter) ([]domain.AuditEvent, error) {
+	if err := requirePermission(ctx, a.userAdapter, actorID, domain.PermissionViewAuditLog, "view audit log"); err != nil {
+		return nil, err
+	}
+
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return nil, ierr.New(ierr.InvalidArgument, "from must be before to")
+	}
+
+	return a.auditEventAdapter.List(ctx, filter)
+}
+
+// requirePermission checks role of the actor read from storage, so revoked roles take effect before access token expires.
+// API key requests act as regular user.
+func requirePermission(
+	ctx context.Context,
+	userAdapter UserAdapter,
+	actorID uuid.UUID,
+	permission domain.Permission,
+	action string,
+) error {
+	actor, err := userAdapter.Get(ctx, actorID)
+	if err != nil {
+		return err
+	}
+
+	if !domain.EffectiveRole(ctx, actor.Role).Can(permission) {
+		return ierr.New(ierr.PermissionDenied, "you don't have permission to "+action)
+	}
+
+	return nil
+}
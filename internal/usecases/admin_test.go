@@ -0,0 +1,154 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+)
+
+func TestAdmin_AssignRole(t *testing.T) {
+	controller := gomock.NewController(t)
+	userAdapterMock := NewMockUserAdapter(controller)
+
+	testingError := errors.New("testing-error")
+	actorID := uuid.New()
+	userID := uuid.New()
+
+	tests := []struct {
+		name      string
+		actorID   uuid.UUID
+		role      domain.Role
+		mocksInit func()
+		wantCode  ierr.Code
+		wantErr   bool
+	}{
+		{
+			name:      "unknown role",
+			actorID:   actorID,
+			role:      domain.Role("root"),
+			mocksInit: func() {},
+			wantCode:  ierr.InvalidArgument,
+			wantErr:   true,
+		},
+		{
+			name:      "changing own role",
+			actorID:   userID,
+			role:      domain.RoleUser,
+			mocksInit: func() {},
+			wantCode:  ierr.InvalidArgument,
+			wantErr:   true,
+		},
+		{
+			name:    "getting actor error",
+			actorID: actorID,
+			role:    domain.RoleModerator,
+			mocksInit: func() {
+				userAdapterMock.EXPECT().Get(gomock.Any(), actorID).Return(domain.User{}, ierr.WrapCode(ierr.Internal, testingError, "getting user error"))
+			},
+			wantCode: ierr.Internal,
+			wantErr:  true,
+		},
+		{
+			name:    "actor is not permitted",
+			actorID: actorID,
+			role:    domain.RoleModerator,
+			mocksInit: func() {
+				userAdapterMock.EXPECT().Get(gomock.Any(), actorID).Return(domain.User{ID: actorID, Role: domain.RoleModerator}, nil)
+			},
+			wantCode: ierr.PermissionDenied,
+			wantErr:  true,
+		},
+		{
+			name:    "success",
+			actorID: actorID,
+			role:    domain.RoleModerator,
+			mocksInit: func() {
+				userAdapterMock.EXPECT().Get(gomock.Any(), actorID).Return(domain.User{ID: actorID, Role: domain.RoleAdmin}, nil)
+				userAdapterMock.EXPECT().UpdateRole(gomock.Any(), userID, domain.RoleModerator).Return(nil)
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInit()
+
+			a := NewAdmin(userAdapterMock, nil)
+			err := a.AssignRole(context.TODO(), tt.actorID, userID, tt.role)
+			assert.Equal(t, tt.wantErr, err != nil)
+			if tt.wantErr {
+				assert.Equal(t, tt.wantCode, ierr.GetCode(err))
+			}
+		})
+	}
+}
+
+func TestAdmin_SearchAuditEvents(t *testing.T) {
+	controller := gomock.NewController(t)
+	userAdapterMock := NewMockUserAdapter(controller)
+	auditEventAdapterMock := NewMockAuditEventAdapter(controller)
+
+	actorID := uuid.New()
+	now := time.Now()
+	events := []domain.AuditEvent{{ID: uuid.New(), Type: domain.AuditSignIn, Outcome: domain.AuditFailure}}
+
+	tests := []struct {
+		name      string
+		filter    domain.AuditEventFilter
+		mocksInit func()
+		want      []domain.AuditEvent
+		wantCode  ierr.Code
+		wantErr   bool
+	}{
+		{
+			name:   "actor is not permitted",
+			filter: domain.AuditEventFilter{},
+			mocksInit: func() {
+				userAdapterMock.EXPECT().Get(gomock.Any(), actorID).Return(domain.User{ID: actorID, Role: domain.RoleModerator}, nil)
+			},
+			wantCode: ierr.PermissionDenied,
+			wantErr:  true,
+		},
+		{
+			name:   "from is after to",
+			filter: domain.AuditEventFilter{From: now, To: now.Add(-time.Hour)},
+			mocksInit: func() {
+				userAdapterMock.EXPECT().Get(gomock.Any(), actorID).Return(domain.User{ID: actorID, Role: domain.RoleAdmin}, nil)
+			},
+			wantCode: ierr.InvalidArgument,
+			wantErr:  true,
+		},
+		{
+			name:   "success",
+			filter: domain.AuditEventFilter{Type: domain.AuditSignIn, From: now.Add(-time.Hour), To: now},
+			mocksInit: func() {
+				userAdapterMock.EXPECT().Get(gomock.Any(), actorID).Return(domain.User{ID: actorID, Role: domain.RoleAdmin}, nil)
+				auditEventAdapterMock.EXPECT().List(gomock.Any(), domain.AuditEventFilter{Type: domain.AuditSignIn, From: now.Add(-time.Hour), To: now}).
+					Return(events, nil)
+			},
+			want:    events,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInit()
+
+			a := NewAdmin(userAdapterMock, auditEventAdapterMock)
+			got, err := a.SearchAuditEvents(context.TODO(), actorID, tt.filter)
+			assert.Equal(t, tt.wantErr, err != nil)
+			if tt.wantErr {
+				assert.Equal(t, tt.wantCode, ierr.GetCode(err))
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
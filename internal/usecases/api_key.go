@@ -0,0 +1,104 @@
+package usecases
+
+import (
+	"context"
+	"strings"
+
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// apiKeyPrefix makes keys recognizable, e.g. by secret scanners.
+	apiKeyPrefix = "petly_"
+	// apiKeyVisibleLen length of the key beginning which is stored in plain text to let the user recognize the key.
+	apiKeyVisibleLen = len(apiKeyPrefix) + 6
+)
+
+// APIKey manages personal API keys of users and authenticates requests made with them.
+type APIKey struct {
+	apiKeyAdapter APIKeyAdapter
+	keyGenerator  OpaqueTokenGenerator
+}
+
+func NewAPIKey(apiKeyAdapter APIKeyAdapter, keyGenerator OpaqueTokenGenerator) *APIKey {
+	return &APIKey{
+		apiKeyAdapter: apiKeyAdapter,
+		keyGenerator:  keyGenerator,
+	}
+}
+
+// Create generates new key, only its hash is stored so the key is returned only once.
+func (a APIKey) Create(ctx context.Context, userID uuid.UUID, in domain.APIKeyCreate) (domain.CreatedAPIKey, error) {
+	if strings.TrimSpace(in.Name) == "" {
+		return domain.CreatedAPIKey{}, ierr.New(ierr.InvalidArgument, "api key name is required")
+	}
+
+	if len(in.Scopes) == 0 {
+		return domain.CreatedAPIKey{}, ierr.New(ierr.InvalidArgument, "at least one api key scope is required")
+	}
+
+	seen := make(map[domain.APIKeyScope]bool, len(in.Scopes))
+	scopes := make([]domain.APIKeyScope, 0, len(in.Scopes))
+	for _, scope := range in.Scopes {
+		if !scope.Valid() {
+			return domain.CreatedAPIKey{}, ierr.New(ierr.InvalidArgument, "unknown api key scope")
+		}
+
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+
+	token, _, err := a.keyGenerator.Generate()
+	if err != nil {
+		return domain.CreatedAPIKey{}, ierr.WrapCode(ierr.Internal, err, "generating api key error")
+	}
+
+	key := apiKeyPrefix + token
+
+	created, err := a.apiKeyAdapter.Create(ctx, domain.APIKey{
+		UserID:  userID,
+		Name:    strings.TrimSpace(in.Name),
+		Prefix:  key[:apiKeyVisibleLen],
+		KeyHash: a.keyGenerator.Hash(key),
+		Scopes:  scopes,
+	})
+	if err != nil {
+		return domain.CreatedAPIKey{}, err
+	}
+
+	return domain.CreatedAPIKey{
+		APIKey: created,
+		Key:    key,
+	}, nil
+}
+
+func (a APIKey) List(ctx context.Context, userID uuid.UUID) ([]domain.APIKey, error) {
+	return a.apiKeyAdapter.List(ctx, userID)
+}
+
+func (a APIKey) Revoke(ctx context.Context, userID, keyID uuid.UUID) error {
+	return a.apiKeyAdapter.Revoke(ctx, userID, keyID)
+}
+
+// Authenticate returns not revoked key the request was made with.
+func (a APIKey) Authenticate(ctx context.Context, key string) (domain.APIKey, error) {
+	if !strings.HasPrefix(key, apiKeyPrefix) {
+		return domain.APIKey{}, ierr.New(ierr.Unauthenticated, "invalid api key")
+	}
+
+	apiKey, err := a.apiKeyAdapter.Use(ctx, a.keyGenerator.Hash(key))
+	if err != nil {
+		if ierr.GetCode(err) == ierr.NotFound {
+			return domain.APIKey{}, ierr.WrapCode(ierr.Unauthenticated, err, "invalid api key")
+		}
+
+		return domain.APIKey{}, err
+	}
+
+	return apiKey, nil
+}
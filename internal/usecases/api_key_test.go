@@ -0,0 +1,154 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+)
+
+func TestAPIKey_Create(t *testing.T) {
+	controller := gomock.NewController(t)
+	apiKeyAdapterMock := NewMockAPIKeyAdapter(controller)
+	keyGeneratorMock := NewMockOpaqueTokenGenerator(controller)
+
+	testingError := errors.New("testing-error")
+	userID := uuid.New()
+	keyID := uuid.New()
+
+	stored := domain.APIKey{
+		UserID:  userID,
+		Name:    "ci",
+		Prefix:  "petly_abcdef",
+		KeyHash: "keyhash",
+		Scopes:  []domain.APIKeyScope{domain.APIKeyScopeRead},
+	}
+
+	created := stored
+	created.ID = keyID
+
+	tests := []struct {
+		name      string
+		in        domain.APIKeyCreate
+		mocksInit func()
+		want      domain.CreatedAPIKey
+		wantCode  ierr.Code
+		wantErr   bool
+	}{
+		{
+			name:      "empty name",
+			in:        domain.APIKeyCreate{Name: " ", Scopes: []domain.APIKeyScope{domain.APIKeyScopeRead}},
+			mocksInit: func() {},
+			want:      domain.CreatedAPIKey{},
+			wantCode:  ierr.InvalidArgument,
+			wantErr:   true,
+		},
+		{
+			name:      "unknown scope",
+			in:        domain.APIKeyCreate{Name: "ci", Scopes: []domain.APIKeyScope{"admin"}},
+			mocksInit: func() {},
+			want:      domain.CreatedAPIKey{},
+			wantCode:  ierr.InvalidArgument,
+			wantErr:   true,
+		},
+		{
+			name: "key generation error",
+			in:   domain.APIKeyCreate{Name: "ci", Scopes: []domain.APIKeyScope{domain.APIKeyScopeRead}},
+			mocksInit: func() {
+				keyGeneratorMock.EXPECT().Generate().Return("", "", testingError)
+			},
+			want:     domain.CreatedAPIKey{},
+			wantCode: ierr.Internal,
+			wantErr:  true,
+		},
+		{
+			name: "success with duplicated scopes",
+			in:   domain.APIKeyCreate{Name: " ci ", Scopes: []domain.APIKeyScope{domain.APIKeyScopeRead, domain.APIKeyScopeRead}},
+			mocksInit: func() {
+				keyGeneratorMock.EXPECT().Generate().Return("abcdefghijklmnop", "tokenhash", nil)
+				keyGeneratorMock.EXPECT().Hash("petly_abcdefghijklmnop").Return("keyhash")
+				apiKeyAdapterMock.EXPECT().Create(gomock.Any(), stored).Return(created, nil)
+			},
+			want:    domain.CreatedAPIKey{APIKey: created, Key: "petly_abcdefghijklmnop"},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInit()
+
+			a := NewAPIKey(apiKeyAdapterMock, keyGeneratorMock)
+			got, err := a.Create(context.TODO(), userID, tt.in)
+			assert.Equal(t, tt.wantErr, err != nil)
+			if tt.wantErr {
+				assert.Equal(t, tt.wantCode, ierr.GetCode(err))
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestAPIKey_Authenticate(t *testing.T) {
+	controller := gomock.NewController(t)
+	apiKeyAdapterMock := NewMockAPIKeyAdapter(controller)
+	keyGeneratorMock := NewMockOpaqueTokenGenerator(controller)
+
+	key := domain.APIKey{ID: uuid.New(), UserID: uuid.New(), Scopes: []domain.APIKeyScope{domain.APIKeyScopeRead}}
+
+	tests := []struct {
+		name      string
+		key       string
+		mocksInit func()
+		want      domain.APIKey
+		wantCode  ierr.Code
+		wantErr   bool
+	}{
+		{
+			name:      "malformed key",
+			key:       "abcdefghijklmnop",
+			mocksInit: func() {},
+			want:      domain.APIKey{},
+			wantCode:  ierr.Unauthenticated,
+			wantErr:   true,
+		},
+		{
+			name: "unknown or revoked key",
+			key:  "petly_abcdefghijklmnop",
+			mocksInit: func() {
+				keyGeneratorMock.EXPECT().Hash("petly_abcdefghijklmnop").Return("keyhash")
+				apiKeyAdapterMock.EXPECT().Use(gomock.Any(), "keyhash").Return(domain.APIKey{}, ierr.New(ierr.NotFound, "api key not found"))
+			},
+			want:     domain.APIKey{},
+			wantCode: ierr.Unauthenticated,
+			wantErr:  true,
+		},
+		{
+			name: "success",
+			key:  "petly_abcdefghijklmnop",
+			mocksInit: func() {
+				keyGeneratorMock.EXPECT().Hash("petly_abcdefghijklmnop").Return("keyhash")
+				apiKeyAdapterMock.EXPECT().Use(gomock.Any(), "keyhash").Return(key, nil)
+			},
+			want:    key,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInit()
+
+			a := NewAPIKey(apiKeyAdapterMock, keyGeneratorMock)
+			got, err := a.Authenticate(context.TODO(), tt.key)
+			assert.Equal(t, tt.wantErr, err != nil)
+			if tt.wantErr {
+				assert.Equal(t, tt.wantCode, ierr.GetCode(err))
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
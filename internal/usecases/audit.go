@@ -0,0 +1,31 @@
+package usecases
+
+import (
+	"context"
+	"log"
+
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+)
+
+// recordAudit records outcome of the action, the error the action failed with is recorded as the reason.
+// Client of the request is taken from the context. Failure to record is only logged,
+// so auditing never breaks the audited action.
+func recordAudit(ctx context.Context, sink AuditSink, event domain.AuditEvent, actionErr error) {
+	if actionErr != nil {
+		event.Outcome = domain.AuditFailure
+		event.Reason = ierr.GetMessage(actionErr)
+	}
+
+	if event.Outcome == "" {
+		event.Outcome = domain.AuditSuccess
+	}
+
+	client := domain.ClientFromContext(ctx)
+	event.ClientIP = client.IP
+	event.UserAgent = client.UserAgent
+
+	if err := sink.Record(ctx, event); err != nil {
+		log.Printf("recording %s audit event error: %s", event.Type, err)
+	}
+}
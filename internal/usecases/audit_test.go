@@ -0,0 +1,77 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+)
+
+func TestRecordAudit(t *testing.T) {
+	controller := gomock.NewController(t)
+	auditSinkMock := NewMockAuditSink(controller)
+
+	userID := uuid.New()
+	client := domain.Client{IP: "10.0.0.1", UserAgent: "curl"}
+	ctx := domain.ContextWithClient(context.TODO(), client)
+
+	tests := []struct {
+		name      string
+		event     domain.AuditEvent
+		actionErr error
+		mocksInit func()
+	}{
+		{
+			name:      "failure",
+			event:     domain.AuditEvent{Type: domain.AuditSignIn, Email: "test@test.com"},
+			actionErr: ierr.New(ierr.NotFound, "user not found"),
+			mocksInit: func() {
+				auditSinkMock.EXPECT().Record(gomock.Any(), domain.AuditEvent{
+					Type:      domain.AuditSignIn,
+					Email:     "test@test.com",
+					Outcome:   domain.AuditFailure,
+					Reason:    "user not found",
+					ClientIP:  client.IP,
+					UserAgent: client.UserAgent,
+				}).Return(nil)
+			},
+		},
+		{
+			name:  "explicit outcome is kept",
+			event: domain.AuditEvent{Type: domain.AuditSignIn, UserID: &userID, Outcome: domain.AuditMFARequired},
+			mocksInit: func() {
+				auditSinkMock.EXPECT().Record(gomock.Any(), domain.AuditEvent{
+					Type:      domain.AuditSignIn,
+					UserID:    &userID,
+					Outcome:   domain.AuditMFARequired,
+					ClientIP:  client.IP,
+					UserAgent: client.UserAgent,
+				}).Return(nil)
+			},
+		},
+		{
+			name:  "recording error is only logged",
+			event: domain.AuditEvent{Type: domain.AuditPasswordChange, UserID: &userID},
+			mocksInit: func() {
+				auditSinkMock.EXPECT().Record(gomock.Any(), domain.AuditEvent{
+					Type:      domain.AuditPasswordChange,
+					UserID:    &userID,
+					Outcome:   domain.AuditSuccess,
+					ClientIP:  client.IP,
+					UserAgent: client.UserAgent,
+				}).Return(errors.New("testing-error"))
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInit()
+
+			recordAudit(ctx, auditSinkMock, tt.event, tt.actionErr)
+		})
+	}
+}
@@ -2,60 +2,376 @@ package usecases
 
 import (
 	"context"
+	"log"
+	"time"
 
 	"github.com/valerii-smirnov/petli-test-task/internal/domain"
 	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+
+	"github.com/google/uuid"
 )
 
 type Auth struct {
-	passwordHasher PasswordHasher
-	tokenGenerator TokenGenerator
-	userAdapter    UserAdapter
+	passwordHasher        PasswordHasher
+	tokenGenerator        TokenGenerator
+	refreshTokenGenerator OpaqueTokenGenerator
+	userAdapter           UserAdapter
+	refreshTokenAdapter   RefreshTokenAdapter
+	revocationAdapter     TokenRevocationAdapter
+	emailVerifier         EmailVerificationSender
+	signInGuard           SignInGuard
+	secondFactor          SecondFactor
+	auditSink             AuditSink
+	refreshTokenTTL       time.Duration
 }
 
 func NewAuth(
 	passwordHasher PasswordHasher,
 	tokenGenerator TokenGenerator,
+	refreshTokenGenerator OpaqueTokenGenerator,
 	userAdapter UserAdapter,
+	refreshTokenAdapter RefreshTokenAdapter,
+	revocationAdapter TokenRevocationAdapter,
+	emailVerifier EmailVerificationSender,
+	signInGuard SignInGuard,
+	secondFactor SecondFactor,
+	auditSink AuditSink,
+	refreshTokenTTL time.Duration,
 ) *Auth {
 	return &Auth{
-		passwordHasher: passwordHasher,
-		tokenGenerator: tokenGenerator,
-		userAdapter:    userAdapter,
+		passwordHasher:        passwordHasher,
+		tokenGenerator:        tokenGenerator,
+		refreshTokenGenerator: refreshTokenGenerator,
+		userAdapter:           userAdapter,
+		refreshTokenAdapter:   refreshTokenAdapter,
+		revocationAdapter:     revocationAdapter,
+		emailVerifier:         emailVerifier,
+		signInGuard:           signInGuard,
+		secondFactor:          secondFactor,
+		auditSink:             auditSink,
+		refreshTokenTTL:       refreshTokenTTL,
 	}
 }
 
 func (a Auth) SignUp(ctx context.Context, in domain.SignUp) error {
+	user, err := a.signUp(ctx, in)
+	a.auditUser(ctx, domain.AuditSignUp, in.Email, user, err)
+
+	return err
+}
+
+func (a Auth) signUp(ctx context.Context, in domain.SignUp) (domain.User, error) {
 	exists, err := a.userAdapter.Exists(ctx, in.Email)
 	if err != nil {
-		return err
+		return domain.User{}, err
 	}
 
 	if exists {
-		return ierr.New(ierr.AlreadyExists, "user with provided email already exists")
+		return domain.User{}, ierr.New(ierr.AlreadyExists, "user with provided email already exists")
+	}
+
+	hash, err := a.passwordHasher.Hash(in.Password)
+	if err != nil {
+		return domain.User{}, ierr.WrapCode(ierr.Internal, err, "hashing password error")
 	}
 
-	in.Password = a.passwordHasher.StringHash(in.Password)
+	in.Password = hash
 
 	if err := a.userAdapter.Create(ctx, in); err != nil {
-		return ierr.WrapCode(ierr.Internal, err, "creating user error")
+		return domain.User{}, ierr.WrapCode(ierr.Internal, err, "creating user error")
 	}
 
-	return nil
+	// user is already created at this point, so failed delivery doesn't fail sign-up:
+	// verification link can be requested again.
+	if err := a.emailVerifier.SendVerification(ctx, in.Email); err != nil {
+		log.Printf("sending verification email to %s error: %s", in.Email, err)
+	}
+
+	// the user is read back only to be known as the actor of sign-up audit event.
+	user, err := a.userAdapter.GetByEmail(ctx, in.Email)
+	if err != nil {
+		log.Printf("reading signed up user %s error: %s", in.Email, err)
+	}
+
+	return user, nil
+}
+
+// SignIn issues tokens for valid credentials. Failed attempts are tracked per email and client ip,
+// too many of them lock sign-in temporarily. If user has two-factor authentication enabled,
+// only mfa token is returned and sign-in has to be completed with SignInSecondFactor.
+func (a Auth) SignIn(ctx context.Context, in domain.SingIn) (domain.Tokens, error) {
+	user, tokens, err := a.signIn(ctx, in)
+	a.auditSignIn(ctx, domain.AuditSignIn, in.Email, user, tokens, err)
+
+	// unknown email and wrong password are told apart only in the audit log, so sign-in doesn't reveal registered emails.
+	if ierr.GetCode(err) == ierr.NotFound {
+		return tokens, ierr.New(ierr.NotFound, "user not found")
+	}
+
+	return tokens, err
+}
+
+// signIn returns the user once it is known, so failed attempts are audited on behalf of the user.
+func (a Auth) signIn(ctx context.Context, in domain.SingIn) (domain.User, domain.Tokens, error) {
+	if err := a.signInGuard.Check(ctx, in.Email, in.ClientIP); err != nil {
+		return domain.User{}, domain.Tokens{}, err
+	}
+
+	user, err := a.userAdapter.GetByEmail(ctx, in.Email)
+	if err != nil {
+		if ierr.GetCode(err) == ierr.NotFound {
+			return domain.User{}, domain.Tokens{}, a.failSignIn(ctx, in, err)
+		}
+
+		return domain.User{}, domain.Tokens{}, err
+	}
+
+	ok, err := a.passwordHasher.Verify(in.Password, user.PasswordHash)
+	if err != nil {
+		return user, domain.Tokens{}, ierr.WrapCode(ierr.Internal, err, "verifying password error")
+	}
+
+	if !ok {
+		return user, domain.Tokens{}, a.failSignIn(ctx, in, ierr.New(ierr.NotFound, "wrong password"))
+	}
+
+	if a.passwordHasher.NeedsRehash(user.PasswordHash) {
+		if err := a.rehashPassword(ctx, user, in.Password); err != nil {
+			return user, domain.Tokens{}, err
+		}
+	}
+
+	// failed attempts aren't reset until the second factor is passed,
+	// otherwise known password would allow to brute-force two-factor codes.
+	if user.TOTPEnabledAt != nil {
+		return user, domain.Tokens{MFA: a.secondFactor.Challenge(user.ID)}, nil
+	}
+
+	if err := a.signInGuard.RegisterSuccess(ctx, in.Email); err != nil {
+		return user, domain.Tokens{}, err
+	}
+
+	tokens, err := a.issueTokens(ctx, user, uuid.New())
+	return user, tokens, err
+}
+
+// SignInSecondFactor exchanges mfa token issued by SignIn and two-factor code for a pair of tokens.
+func (a Auth) SignInSecondFactor(ctx context.Context, in domain.SecondFactorSignIn) (domain.Tokens, error) {
+	user, err := a.secondFactor.Verify(ctx, in)
+	if err != nil {
+		a.auditSignIn(ctx, domain.AuditSignInSecondFactor, "", domain.User{}, domain.Tokens{}, err)
+		return domain.Tokens{}, err
+	}
+
+	tokens, err := a.issueTokens(ctx, user, uuid.New())
+	a.auditSignIn(ctx, domain.AuditSignInSecondFactor, user.Email, user, tokens, err)
+
+	return tokens, err
+}
+
+// SignInExternal issues tokens for the user authenticated by external identity provider.
+// Two-factor authentication is still required if user has it enabled.
+func (a Auth) SignInExternal(ctx context.Context, user domain.User) (domain.Tokens, error) {
+	var (
+		tokens domain.Tokens
+		err    error
+	)
+
+	if user.TOTPEnabledAt != nil {
+		tokens = domain.Tokens{MFA: a.secondFactor.Challenge(user.ID)}
+	} else {
+		tokens, err = a.issueTokens(ctx, user, uuid.New())
+	}
+
+	a.auditSignIn(ctx, domain.AuditSignInExternal, user.Email, user, tokens, err)
+
+	return tokens, err
+}
+
+// Refresh exchanges refresh token for a new pair of tokens. Every refresh token can be used only once,
+// presenting already used token revokes the whole family of tokens issued since sign-in.
+func (a Auth) Refresh(ctx context.Context, refreshToken domain.Token) (domain.Tokens, error) {
+	user, tokens, err := a.refresh(ctx, refreshToken)
+	a.auditUser(ctx, domain.AuditTokenRefresh, user.Email, user, err)
+
+	return tokens, err
+}
+
+// refresh returns owner of the refresh token once it is known, only its id is set if the token is rejected.
+func (a Auth) refresh(ctx context.Context, refreshToken domain.Token) (domain.User, domain.Tokens, error) {
+	rt, err := a.refreshTokenAdapter.GetByHash(ctx, a.refreshTokenGenerator.Hash(string(refreshToken)))
+	if err != nil {
+		if ierr.GetCode(err) == ierr.NotFound {
+			return domain.User{}, domain.Tokens{}, ierr.WrapCode(ierr.Unauthenticated, err, "invalid refresh token")
+		}
+
+		return domain.User{}, domain.Tokens{}, err
+	}
+
+	owner := domain.User{ID: rt.UserID}
+
+	if rt.RevokedAt != nil {
+		return owner, domain.Tokens{}, ierr.New(ierr.Unauthenticated, "refresh token is revoked")
+	}
+
+	if rt.UsedAt != nil {
+		return owner, domain.Tokens{}, a.revokeReusedFamily(ctx, rt)
+	}
+
+	if time.Now().After(rt.ExpiresAt) {
+		return owner, domain.Tokens{}, ierr.New(ierr.Unauthenticated, "refresh token is expired")
+	}
+
+	marked, err := a.refreshTokenAdapter.MarkUsed(ctx, rt.ID)
+	if err != nil {
+		return owner, domain.Tokens{}, err
+	}
+
+	if !marked {
+		return owner, domain.Tokens{}, a.revokeReusedFamily(ctx, rt)
+	}
+
+	// user is read again, so changes of the role are reflected in refreshed access token.
+	user, err := a.userAdapter.Get(ctx, rt.UserID)
+	if err != nil {
+		return owner, domain.Tokens{}, err
+	}
+
+	tokens, err := a.issueTokens(ctx, user, rt.FamilyID)
+	return user, tokens, err
+}
+
+// Logout revokes access token and, if provided, the family of refresh tokens it was issued with.
+func (a Auth) Logout(ctx context.Context, claims domain.TokenClaims, refreshToken domain.Token) error {
+	if err := a.revocationAdapter.Revoke(ctx, claims); err != nil {
+		return err
+	}
+
+	if refreshToken == "" {
+		return nil
+	}
+
+	rt, err := a.refreshTokenAdapter.GetByHash(ctx, a.refreshTokenGenerator.Hash(string(refreshToken)))
+	if err != nil {
+		if ierr.GetCode(err) == ierr.NotFound {
+			return nil
+		}
+
+		return err
+	}
+
+	if rt.UserID != claims.UserID {
+		return nil
+	}
+
+	return a.refreshTokenAdapter.RevokeFamily(ctx, rt.FamilyID)
+}
+
+// LogoutAll invalidates every access and refresh token issued to the user so far.
+func (a Auth) LogoutAll(ctx context.Context, userID uuid.UUID) error {
+	if err := a.revocationAdapter.RevokeAllIssuedBefore(ctx, userID, time.Now()); err != nil {
+		return err
+	}
+
+	return a.refreshTokenAdapter.RevokeAllForUser(ctx, userID)
+}
+
+func (a Auth) IsRevoked(ctx context.Context, claims domain.TokenClaims) (bool, error) {
+	return a.revocationAdapter.IsRevoked(ctx, claims)
+}
+
+// PruneRevokedTokens removes denylist entries of already expired tokens.
+func (a Auth) PruneRevokedTokens(ctx context.Context) error {
+	_, err := a.revocationAdapter.DeleteExpired(ctx)
+	return err
 }
 
-func (a Auth) SignIn(ctx context.Context, in domain.SingIn) (domain.Token, error) {
-	in.Password = a.passwordHasher.StringHash(in.Password)
+func (a Auth) issueTokens(ctx context.Context, user domain.User, familyID uuid.UUID) (domain.Tokens, error) {
+	access, err := a.tokenGenerator.Generate(user.ID, user.Role.String())
+	if err != nil {
+		return domain.Tokens{}, err
+	}
 
-	user, err := a.userAdapter.Get(ctx, in)
+	refresh, hash, err := a.refreshTokenGenerator.Generate()
 	if err != nil {
-		return "", err
+		return domain.Tokens{}, ierr.WrapCode(ierr.Internal, err, "generating refresh token error")
+	}
+
+	rt := domain.RefreshToken{
+		FamilyID:  familyID,
+		UserID:    user.ID,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(a.refreshTokenTTL),
+	}
+
+	if err := a.refreshTokenAdapter.Create(ctx, rt); err != nil {
+		return domain.Tokens{}, err
+	}
+
+	return domain.Tokens{
+		Access:  domain.Token(access),
+		Refresh: domain.Token(refresh),
+	}, nil
+}
+
+// auditSignIn records sign-in step, passed first step of two-factor sign-in is recorded as mfa required.
+func (a Auth) auditSignIn(
+	ctx context.Context,
+	eventType domain.AuditEventType,
+	email string,
+	user domain.User,
+	tokens domain.Tokens,
+	err error,
+) {
+	event := a.userAuditEvent(eventType, email, user)
+	if err == nil && tokens.MFA != "" {
+		event.Outcome = domain.AuditMFARequired
+	}
+
+	recordAudit(ctx, a.auditSink, event, err)
+}
+
+func (a Auth) auditUser(ctx context.Context, eventType domain.AuditEventType, email string, user domain.User, err error) {
+	recordAudit(ctx, a.auditSink, a.userAuditEvent(eventType, email, user), err)
+}
+
+// userAuditEvent the user is the actor if known.
+func (a Auth) userAuditEvent(eventType domain.AuditEventType, email string, user domain.User) domain.AuditEvent {
+	event := domain.AuditEvent{Type: eventType, Email: email}
+	if user.ID != uuid.Nil {
+		event.UserID = &user.ID
+	}
+
+	return event
+}
+
+// failSignIn registers failed attempt and returns sign-in error.
+func (a Auth) failSignIn(ctx context.Context, in domain.SingIn, signInErr error) error {
+	if err := a.signInGuard.RegisterFailure(ctx, in.Email, in.ClientIP); err != nil {
+		return err
+	}
+
+	return signInErr
+}
+
+func (a Auth) revokeReusedFamily(ctx context.Context, rt domain.RefreshToken) error {
+	if err := a.refreshTokenAdapter.RevokeFamily(ctx, rt.FamilyID); err != nil {
+		return err
 	}
 
-	token, err := a.tokenGenerator.Generate(user.ID)
+	return ierr.New(ierr.Unauthenticated, "refresh token reuse detected")
+}
+
+// rehashPassword upgrades legacy or outdated password hash after successful verification.
+func (a Auth) rehashPassword(ctx context.Context, user domain.User, password string) error {
+	hash, err := a.passwordHasher.Hash(password)
 	if err != nil {
-		return "", err
+		return ierr.WrapCode(ierr.Internal, err, "hashing password error")
+	}
+
+	if err := a.userAdapter.UpdatePasswordHash(ctx, user.ID, hash); err != nil {
+		return ierr.WrapCode(ierr.Internal, err, "updating password hash error")
 	}
 
-	return domain.Token(token), nil
+	return nil
 }
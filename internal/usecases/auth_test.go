@@ -7,13 +7,44 @@ import (
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
 	"testing"
+	"time"
 )
 
+const refreshTokenTTL = time.Hour
+
+// refreshTokenMatcher matches issued refresh token ignoring generated family id and expiration time.
+type refreshTokenMatcher struct {
+	userID    uuid.UUID
+	familyID  uuid.UUID
+	tokenHash string
+}
+
+func (m refreshTokenMatcher) Matches(x interface{}) bool {
+	rt, ok := x.(domain.RefreshToken)
+	if !ok {
+		return false
+	}
+
+	if m.familyID != uuid.Nil && m.familyID != rt.FamilyID {
+		return false
+	}
+
+	return rt.UserID == m.userID && rt.TokenHash == m.tokenHash && rt.FamilyID != uuid.Nil && rt.ExpiresAt.After(time.Now())
+}
+
+func (m refreshTokenMatcher) String() string {
+	return "refresh token of user " + m.userID.String()
+}
+
 func TestAuth_SignUp(t *testing.T) {
 	controller := gomock.NewController(t)
+	auditSinkMock := NewMockAuditSink(controller)
+	auditSinkMock.EXPECT().Record(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 	passwordHasherMock := NewMockPasswordHasher(controller)
 	userAdapterMock := NewMockUserAdapter(controller)
+	emailVerifierMock := NewMockEmailVerificationSender(controller)
 
 	email := "test@test.com"
 	password := "aaaa"
@@ -31,9 +62,15 @@ func TestAuth_SignUp(t *testing.T) {
 	}
 
 	type fields struct {
-		passwordHasher PasswordHasher
-		tokenGenerator TokenGenerator
-		userAdapter    UserAdapter
+		passwordHasher        PasswordHasher
+		tokenGenerator        TokenGenerator
+		refreshTokenGenerator OpaqueTokenGenerator
+		userAdapter           UserAdapter
+		refreshTokenAdapter   RefreshTokenAdapter
+		revocationAdapter     TokenRevocationAdapter
+		emailVerifier         EmailVerificationSender
+		signInGuard           SignInGuard
+		secondFactor          SecondFactor
 	}
 	type args struct {
 		ctx context.Context
@@ -76,6 +113,22 @@ func TestAuth_SignUp(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "hashing password error",
+			fields: fields{
+				passwordHasher: passwordHasherMock,
+				userAdapter:    userAdapterMock,
+			},
+			args: args{
+				ctx: context.TODO(),
+				in:  req,
+			},
+			mocksInit: func() {
+				userAdapterMock.EXPECT().Exists(gomock.Any(), gomock.Eq(email)).Return(false, nil)
+				passwordHasherMock.EXPECT().Hash(gomock.Eq(password)).Return("", testingError)
+			},
+			wantErr: true,
+		},
 		{
 			name: "creation user error",
 			fields: fields{
@@ -88,16 +141,37 @@ func TestAuth_SignUp(t *testing.T) {
 			},
 			mocksInit: func() {
 				userAdapterMock.EXPECT().Exists(gomock.Any(), gomock.Eq(email)).Return(false, nil)
-				passwordHasherMock.EXPECT().StringHash(gomock.Eq(password)).Return(passwordHashed)
+				passwordHasherMock.EXPECT().Hash(gomock.Eq(password)).Return(passwordHashed, nil)
 				userAdapterMock.EXPECT().Create(gomock.Any(), gomock.Eq(su)).Return(testingError)
 			},
 			wantErr: true,
 		},
+		{
+			name: "sending verification email error doesn't fail sign-up",
+			fields: fields{
+				passwordHasher: passwordHasherMock,
+				userAdapter:    userAdapterMock,
+				emailVerifier:  emailVerifierMock,
+			},
+			args: args{
+				ctx: context.TODO(),
+				in:  req,
+			},
+			mocksInit: func() {
+				userAdapterMock.EXPECT().Exists(gomock.Any(), gomock.Eq(email)).Return(false, nil)
+				passwordHasherMock.EXPECT().Hash(gomock.Eq(password)).Return(passwordHashed, nil)
+				userAdapterMock.EXPECT().Create(gomock.Any(), gomock.Eq(su)).Return(nil)
+				emailVerifierMock.EXPECT().SendVerification(gomock.Any(), gomock.Eq(email)).Return(testingError)
+				userAdapterMock.EXPECT().GetByEmail(gomock.Any(), gomock.Eq(email)).Return(domain.User{ID: uuid.New(), Email: email}, nil)
+			},
+			wantErr: false,
+		},
 		{
 			name: "success",
 			fields: fields{
 				passwordHasher: passwordHasherMock,
 				userAdapter:    userAdapterMock,
+				emailVerifier:  emailVerifierMock,
 			},
 			args: args{
 				ctx: context.TODO(),
@@ -105,8 +179,10 @@ func TestAuth_SignUp(t *testing.T) {
 			},
 			mocksInit: func() {
 				userAdapterMock.EXPECT().Exists(gomock.Any(), gomock.Eq(email)).Return(false, nil)
-				passwordHasherMock.EXPECT().StringHash(gomock.Eq(password)).Return(passwordHashed)
+				passwordHasherMock.EXPECT().Hash(gomock.Eq(password)).Return(passwordHashed, nil)
 				userAdapterMock.EXPECT().Create(gomock.Any(), gomock.Eq(su)).Return(nil)
+				emailVerifierMock.EXPECT().SendVerification(gomock.Any(), gomock.Eq(email)).Return(nil)
+				userAdapterMock.EXPECT().GetByEmail(gomock.Any(), gomock.Eq(email)).Return(domain.User{ID: uuid.New(), Email: email}, nil)
 			},
 			wantErr: false,
 		},
@@ -115,7 +191,19 @@ func TestAuth_SignUp(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mocksInit()
 
-			a := NewAuth(tt.fields.passwordHasher, tt.fields.tokenGenerator, tt.fields.userAdapter)
+			a := NewAuth(
+				tt.fields.passwordHasher,
+				tt.fields.tokenGenerator,
+				tt.fields.refreshTokenGenerator,
+				tt.fields.userAdapter,
+				tt.fields.refreshTokenAdapter,
+				tt.fields.revocationAdapter,
+				tt.fields.emailVerifier,
+				tt.fields.signInGuard,
+				tt.fields.secondFactor,
+				auditSinkMock,
+				refreshTokenTTL,
+			)
 			err := a.SignUp(tt.args.ctx, tt.args.in)
 			assert.Equal(t, tt.wantErr, err != nil)
 		})
@@ -124,36 +212,63 @@ func TestAuth_SignUp(t *testing.T) {
 
 func TestAuth_SignIn(t *testing.T) {
 	controller := gomock.NewController(t)
+	auditSinkMock := NewMockAuditSink(controller)
+	auditSinkMock.EXPECT().Record(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 	passwordHasherMock := NewMockPasswordHasher(controller)
 	userAdapterMock := NewMockUserAdapter(controller)
 	tokenGenerator := NewMockTokenGenerator(controller)
+	refreshTokenGeneratorMock := NewMockOpaqueTokenGenerator(controller)
+	refreshTokenAdapterMock := NewMockRefreshTokenAdapter(controller)
+	signInGuardMock := NewMockSignInGuard(controller)
+	secondFactorMock := NewMockSecondFactor(controller)
 
+	email := "test@test.com"
 	password := "aaaa"
 	passwordHashed := "aaaa bbbb"
+	passwordRehashed := "aaaa cccc"
 	testingError := errors.New("testing-error")
 
 	userID := uuid.New()
 
 	foundUser := domain.User{
-		ID: userID,
+		ID:           userID,
+		Email:        email,
+		PasswordHash: passwordHashed,
+		Role:         domain.RoleUser,
 	}
 
+	totpEnabledAt := time.Now()
+	twoFactorUser := foundUser
+	twoFactorUser.TOTPEnabledAt = &totpEnabledAt
+	mfaToken := domain.Token("mfatoken")
+
+	clientIP := "127.0.0.1"
+
 	req := domain.SingIn{
-		Email:    "test@test.com",
+		Email:    email,
 		Password: password,
-	}
-
-	reqHashed := domain.SingIn{
-		Email:    "test@test.com",
-		Password: passwordHashed,
+		ClientIP: clientIP,
 	}
 
 	token := "tokentoken"
+	refreshToken := "refreshtoken"
+	refreshTokenHash := "refreshtokenhash"
+
+	tokens := domain.Tokens{
+		Access:  domain.Token(token),
+		Refresh: domain.Token(refreshToken),
+	}
 
 	type fields struct {
-		passwordHasher PasswordHasher
-		tokenGenerator TokenGenerator
-		userAdapter    UserAdapter
+		passwordHasher        PasswordHasher
+		tokenGenerator        TokenGenerator
+		refreshTokenGenerator OpaqueTokenGenerator
+		userAdapter           UserAdapter
+		refreshTokenAdapter   RefreshTokenAdapter
+		revocationAdapter     TokenRevocationAdapter
+		emailVerifier         EmailVerificationSender
+		signInGuard           SignInGuard
+		secondFactor          SecondFactor
 	}
 	type args struct {
 		ctx context.Context
@@ -164,63 +279,248 @@ func TestAuth_SignIn(t *testing.T) {
 		fields    fields
 		args      args
 		mocksInit func()
-		want      domain.Token
+		want      domain.Tokens
 		wantErr   bool
 	}{
+		{
+			name: "sign-in is locked",
+			fields: fields{
+				passwordHasher:        passwordHasherMock,
+				tokenGenerator:        tokenGenerator,
+				refreshTokenGenerator: refreshTokenGeneratorMock,
+				userAdapter:           userAdapterMock,
+				refreshTokenAdapter:   refreshTokenAdapterMock,
+				signInGuard:           signInGuardMock,
+			},
+			args: args{
+				ctx: context.TODO(),
+				in:  req,
+			},
+			mocksInit: func() {
+				signInGuardMock.EXPECT().Check(gomock.Any(), email, clientIP).
+					Return(ierr.New(ierr.ResourceExhausted, "too many failed sign-in attempts"))
+			},
+			want:    domain.Tokens{},
+			wantErr: true,
+		},
+		{
+			name: "unknown email",
+			fields: fields{
+				passwordHasher:        passwordHasherMock,
+				tokenGenerator:        tokenGenerator,
+				refreshTokenGenerator: refreshTokenGeneratorMock,
+				userAdapter:           userAdapterMock,
+				refreshTokenAdapter:   refreshTokenAdapterMock,
+				signInGuard:           signInGuardMock,
+			},
+			args: args{
+				ctx: context.TODO(),
+				in:  req,
+			},
+			mocksInit: func() {
+				signInGuardMock.EXPECT().Check(gomock.Any(), email, clientIP).Return(nil)
+				userAdapterMock.EXPECT().GetByEmail(gomock.Any(), gomock.Eq(email)).
+					Return(domain.User{}, ierr.New(ierr.NotFound, "user not found"))
+				signInGuardMock.EXPECT().RegisterFailure(gomock.Any(), email, clientIP).Return(nil)
+			},
+			want:    domain.Tokens{},
+			wantErr: true,
+		},
 		{
 			name: "getting user error",
 			fields: fields{
-				passwordHasher: passwordHasherMock,
-				tokenGenerator: tokenGenerator,
-				userAdapter:    userAdapterMock,
+				passwordHasher:        passwordHasherMock,
+				tokenGenerator:        tokenGenerator,
+				refreshTokenGenerator: refreshTokenGeneratorMock,
+				userAdapter:           userAdapterMock,
+				refreshTokenAdapter:   refreshTokenAdapterMock,
+				signInGuard:           signInGuardMock,
 			},
 			args: args{
 				ctx: context.TODO(),
 				in:  req,
 			},
 			mocksInit: func() {
-				passwordHasherMock.EXPECT().StringHash(gomock.Eq(password)).Return(passwordHashed)
-				userAdapterMock.EXPECT().Get(gomock.Any(), gomock.Eq(reqHashed)).Return(domain.User{}, testingError)
+				signInGuardMock.EXPECT().Check(gomock.Any(), email, clientIP).Return(nil)
+				userAdapterMock.EXPECT().GetByEmail(gomock.Any(), gomock.Eq(email)).Return(domain.User{}, testingError)
 			},
-			want:    "",
+			want:    domain.Tokens{},
+			wantErr: true,
+		},
+		{
+			name: "password verification error",
+			fields: fields{
+				passwordHasher:        passwordHasherMock,
+				tokenGenerator:        tokenGenerator,
+				refreshTokenGenerator: refreshTokenGeneratorMock,
+				userAdapter:           userAdapterMock,
+				refreshTokenAdapter:   refreshTokenAdapterMock,
+				signInGuard:           signInGuardMock,
+			},
+			args: args{
+				ctx: context.TODO(),
+				in:  req,
+			},
+			mocksInit: func() {
+				signInGuardMock.EXPECT().Check(gomock.Any(), email, clientIP).Return(nil)
+				userAdapterMock.EXPECT().GetByEmail(gomock.Any(), gomock.Eq(email)).Return(foundUser, nil)
+				passwordHasherMock.EXPECT().Verify(gomock.Eq(password), gomock.Eq(passwordHashed)).Return(false, testingError)
+			},
+			want:    domain.Tokens{},
+			wantErr: true,
+		},
+		{
+			name: "wrong password",
+			fields: fields{
+				passwordHasher:        passwordHasherMock,
+				tokenGenerator:        tokenGenerator,
+				refreshTokenGenerator: refreshTokenGeneratorMock,
+				userAdapter:           userAdapterMock,
+				refreshTokenAdapter:   refreshTokenAdapterMock,
+				signInGuard:           signInGuardMock,
+			},
+			args: args{
+				ctx: context.TODO(),
+				in:  req,
+			},
+			mocksInit: func() {
+				signInGuardMock.EXPECT().Check(gomock.Any(), email, clientIP).Return(nil)
+				userAdapterMock.EXPECT().GetByEmail(gomock.Any(), gomock.Eq(email)).Return(foundUser, nil)
+				passwordHasherMock.EXPECT().Verify(gomock.Eq(password), gomock.Eq(passwordHashed)).Return(false, nil)
+				signInGuardMock.EXPECT().RegisterFailure(gomock.Any(), email, clientIP).Return(nil)
+			},
+			want:    domain.Tokens{},
+			wantErr: true,
+		},
+		{
+			name: "rehash update error",
+			fields: fields{
+				passwordHasher:        passwordHasherMock,
+				tokenGenerator:        tokenGenerator,
+				refreshTokenGenerator: refreshTokenGeneratorMock,
+				userAdapter:           userAdapterMock,
+				refreshTokenAdapter:   refreshTokenAdapterMock,
+				signInGuard:           signInGuardMock,
+			},
+			args: args{
+				ctx: context.TODO(),
+				in:  req,
+			},
+			mocksInit: func() {
+				signInGuardMock.EXPECT().Check(gomock.Any(), email, clientIP).Return(nil)
+				userAdapterMock.EXPECT().GetByEmail(gomock.Any(), gomock.Eq(email)).Return(foundUser, nil)
+				passwordHasherMock.EXPECT().Verify(gomock.Eq(password), gomock.Eq(passwordHashed)).Return(true, nil)
+				passwordHasherMock.EXPECT().NeedsRehash(gomock.Eq(passwordHashed)).Return(true)
+				passwordHasherMock.EXPECT().Hash(gomock.Eq(password)).Return(passwordRehashed, nil)
+				userAdapterMock.EXPECT().UpdatePasswordHash(gomock.Any(), gomock.Eq(userID), gomock.Eq(passwordRehashed)).Return(testingError)
+			},
+			want:    domain.Tokens{},
 			wantErr: true,
 		},
 		{
 			name: "token generation error",
 			fields: fields{
-				passwordHasher: passwordHasherMock,
-				tokenGenerator: tokenGenerator,
-				userAdapter:    userAdapterMock,
+				passwordHasher:        passwordHasherMock,
+				tokenGenerator:        tokenGenerator,
+				refreshTokenGenerator: refreshTokenGeneratorMock,
+				userAdapter:           userAdapterMock,
+				refreshTokenAdapter:   refreshTokenAdapterMock,
+				signInGuard:           signInGuardMock,
 			},
 			args: args{
 				ctx: context.TODO(),
 				in:  req,
 			},
 			mocksInit: func() {
-				passwordHasherMock.EXPECT().StringHash(gomock.Eq(password)).Return(passwordHashed)
-				userAdapterMock.EXPECT().Get(gomock.Any(), gomock.Eq(reqHashed)).Return(foundUser, nil)
-				tokenGenerator.EXPECT().Generate(gomock.Eq(userID)).Return("", testingError)
+				signInGuardMock.EXPECT().Check(gomock.Any(), email, clientIP).Return(nil)
+				userAdapterMock.EXPECT().GetByEmail(gomock.Any(), gomock.Eq(email)).Return(foundUser, nil)
+				passwordHasherMock.EXPECT().Verify(gomock.Eq(password), gomock.Eq(passwordHashed)).Return(true, nil)
+				signInGuardMock.EXPECT().RegisterSuccess(gomock.Any(), email).Return(nil)
+				passwordHasherMock.EXPECT().NeedsRehash(gomock.Eq(passwordHashed)).Return(false)
+				tokenGenerator.EXPECT().Generate(gomock.Eq(userID), domain.RoleUser.String()).Return("", testingError)
 			},
-			want:    "",
+			want:    domain.Tokens{},
 			wantErr: true,
 		},
+		{
+			name: "success with legacy hash upgrade",
+			fields: fields{
+				passwordHasher:        passwordHasherMock,
+				tokenGenerator:        tokenGenerator,
+				refreshTokenGenerator: refreshTokenGeneratorMock,
+				userAdapter:           userAdapterMock,
+				refreshTokenAdapter:   refreshTokenAdapterMock,
+				signInGuard:           signInGuardMock,
+			},
+			args: args{
+				ctx: context.TODO(),
+				in:  req,
+			},
+			mocksInit: func() {
+				signInGuardMock.EXPECT().Check(gomock.Any(), email, clientIP).Return(nil)
+				userAdapterMock.EXPECT().GetByEmail(gomock.Any(), gomock.Eq(email)).Return(foundUser, nil)
+				passwordHasherMock.EXPECT().Verify(gomock.Eq(password), gomock.Eq(passwordHashed)).Return(true, nil)
+				signInGuardMock.EXPECT().RegisterSuccess(gomock.Any(), email).Return(nil)
+				passwordHasherMock.EXPECT().NeedsRehash(gomock.Eq(passwordHashed)).Return(true)
+				passwordHasherMock.EXPECT().Hash(gomock.Eq(password)).Return(passwordRehashed, nil)
+				userAdapterMock.EXPECT().UpdatePasswordHash(gomock.Any(), gomock.Eq(userID), gomock.Eq(passwordRehashed)).Return(nil)
+				tokenGenerator.EXPECT().Generate(gomock.Eq(userID), domain.RoleUser.String()).Return(token, nil)
+				refreshTokenGeneratorMock.EXPECT().Generate().Return(refreshToken, refreshTokenHash, nil)
+				refreshTokenAdapterMock.EXPECT().Create(gomock.Any(), refreshTokenMatcher{userID: userID, tokenHash: refreshTokenHash}).Return(nil)
+			},
+			want:    tokens,
+			wantErr: false,
+		},
+		{
+			name: "two-factor authentication is required",
+			fields: fields{
+				passwordHasher:        passwordHasherMock,
+				tokenGenerator:        tokenGenerator,
+				refreshTokenGenerator: refreshTokenGeneratorMock,
+				userAdapter:           userAdapterMock,
+				refreshTokenAdapter:   refreshTokenAdapterMock,
+				signInGuard:           signInGuardMock,
+				secondFactor:          secondFactorMock,
+			},
+			args: args{
+				ctx: context.TODO(),
+				in:  req,
+			},
+			mocksInit: func() {
+				signInGuardMock.EXPECT().Check(gomock.Any(), email, clientIP).Return(nil)
+				userAdapterMock.EXPECT().GetByEmail(gomock.Any(), gomock.Eq(email)).Return(twoFactorUser, nil)
+				passwordHasherMock.EXPECT().Verify(gomock.Eq(password), gomock.Eq(passwordHashed)).Return(true, nil)
+				passwordHasherMock.EXPECT().NeedsRehash(gomock.Eq(passwordHashed)).Return(false)
+				secondFactorMock.EXPECT().Challenge(gomock.Eq(userID)).Return(mfaToken)
+			},
+			want:    domain.Tokens{MFA: mfaToken},
+			wantErr: false,
+		},
 		{
 			name: "success",
 			fields: fields{
-				passwordHasher: passwordHasherMock,
-				tokenGenerator: tokenGenerator,
-				userAdapter:    userAdapterMock,
+				passwordHasher:        passwordHasherMock,
+				tokenGenerator:        tokenGenerator,
+				refreshTokenGenerator: refreshTokenGeneratorMock,
+				userAdapter:           userAdapterMock,
+				refreshTokenAdapter:   refreshTokenAdapterMock,
+				signInGuard:           signInGuardMock,
 			},
 			args: args{
 				ctx: context.TODO(),
 				in:  req,
 			},
 			mocksInit: func() {
-				passwordHasherMock.EXPECT().StringHash(gomock.Eq(password)).Return(passwordHashed)
-				userAdapterMock.EXPECT().Get(gomock.Any(), gomock.Eq(reqHashed)).Return(foundUser, nil)
-				tokenGenerator.EXPECT().Generate(gomock.Eq(userID)).Return(token, nil)
+				signInGuardMock.EXPECT().Check(gomock.Any(), email, clientIP).Return(nil)
+				userAdapterMock.EXPECT().GetByEmail(gomock.Any(), gomock.Eq(email)).Return(foundUser, nil)
+				passwordHasherMock.EXPECT().Verify(gomock.Eq(password), gomock.Eq(passwordHashed)).Return(true, nil)
+				signInGuardMock.EXPECT().RegisterSuccess(gomock.Any(), email).Return(nil)
+				passwordHasherMock.EXPECT().NeedsRehash(gomock.Eq(passwordHashed)).Return(false)
+				tokenGenerator.EXPECT().Generate(gomock.Eq(userID), domain.RoleUser.String()).Return(token, nil)
+				refreshTokenGeneratorMock.EXPECT().Generate().Return(refreshToken, refreshTokenHash, nil)
+				refreshTokenAdapterMock.EXPECT().Create(gomock.Any(), refreshTokenMatcher{userID: userID, tokenHash: refreshTokenHash}).Return(nil)
 			},
-			want:    domain.Token(token),
+			want:    tokens,
 			wantErr: false,
 		},
 	}
@@ -228,10 +528,481 @@ func TestAuth_SignIn(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mocksInit()
 
-			a := NewAuth(tt.fields.passwordHasher, tt.fields.tokenGenerator, tt.fields.userAdapter)
+			a := NewAuth(
+				tt.fields.passwordHasher,
+				tt.fields.tokenGenerator,
+				tt.fields.refreshTokenGenerator,
+				tt.fields.userAdapter,
+				tt.fields.refreshTokenAdapter,
+				tt.fields.revocationAdapter,
+				tt.fields.emailVerifier,
+				tt.fields.signInGuard,
+				tt.fields.secondFactor,
+				auditSinkMock,
+				refreshTokenTTL,
+			)
 			got, err := a.SignIn(tt.args.ctx, tt.args.in)
 			assert.Equal(t, tt.wantErr, err != nil)
 			assert.Equal(t, tt.want, got)
 		})
 	}
 }
+
+func TestAuth_SignIn_FailureReason(t *testing.T) {
+	controller := gomock.NewController(t)
+	auditSinkMock := NewMockAuditSink(controller)
+	userAdapterMock := NewMockUserAdapter(controller)
+	passwordHasherMock := NewMockPasswordHasher(controller)
+	signInGuardMock := NewMockSignInGuard(controller)
+
+	in := domain.SingIn{Email: "test@test.com", Password: "aaaa", ClientIP: "127.0.0.1"}
+	user := domain.User{ID: uuid.New(), Email: in.Email, PasswordHash: "aaaa bbbb"}
+
+	tests := []struct {
+		name       string
+		mocksInit  func()
+		wantReason string
+	}{
+		{
+			name: "unknown email",
+			mocksInit: func() {
+				userAdapterMock.EXPECT().GetByEmail(gomock.Any(), in.Email).
+					Return(domain.User{}, ierr.New(ierr.NotFound, "user not found"))
+			},
+			wantReason: "user not found",
+		},
+		{
+			name: "wrong password",
+			mocksInit: func() {
+				userAdapterMock.EXPECT().GetByEmail(gomock.Any(), in.Email).Return(user, nil)
+				passwordHasherMock.EXPECT().Verify(in.Password, user.PasswordHash).Return(false, nil)
+			},
+			wantReason: "wrong password",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signInGuardMock.EXPECT().Check(gomock.Any(), in.Email, in.ClientIP).Return(nil)
+			signInGuardMock.EXPECT().RegisterFailure(gomock.Any(), in.Email, in.ClientIP).Return(nil)
+			tt.mocksInit()
+
+			var event domain.AuditEvent
+			auditSinkMock.EXPECT().Record(gomock.Any(), gomock.Any()).
+				Do(func(_ context.Context, e domain.AuditEvent) { event = e }).Return(nil)
+
+			a := NewAuth(passwordHasherMock, nil, nil, userAdapterMock, nil, nil, nil, signInGuardMock, nil, auditSinkMock, refreshTokenTTL)
+			_, err := a.SignIn(context.TODO(), in)
+			assert.Equal(t, ierr.NotFound, ierr.GetCode(err))
+			assert.Equal(t, "user not found", ierr.GetMessage(err))
+			assert.Equal(t, domain.AuditFailure, event.Outcome)
+			assert.Equal(t, tt.wantReason, event.Reason)
+		})
+	}
+}
+
+func TestAuth_SignInSecondFactor(t *testing.T) {
+	controller := gomock.NewController(t)
+	auditSinkMock := NewMockAuditSink(controller)
+	auditSinkMock.EXPECT().Record(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	tokenGenerator := NewMockTokenGenerator(controller)
+	refreshTokenGeneratorMock := NewMockOpaqueTokenGenerator(controller)
+	refreshTokenAdapterMock := NewMockRefreshTokenAdapter(controller)
+	secondFactorMock := NewMockSecondFactor(controller)
+
+	testingError := errors.New("testing-error")
+	userID := uuid.New()
+
+	user := domain.User{ID: userID, Role: domain.RoleUser}
+
+	in := domain.SecondFactorSignIn{
+		MFAToken: "mfatoken",
+		Code:     "123456",
+		ClientIP: "127.0.0.1",
+	}
+
+	tests := []struct {
+		name      string
+		mocksInit func()
+		want      domain.Tokens
+		wantErr   bool
+	}{
+		{
+			name: "second factor verification error",
+			mocksInit: func() {
+				secondFactorMock.EXPECT().Verify(gomock.Any(), gomock.Eq(in)).
+					Return(domain.User{}, ierr.New(ierr.Unauthenticated, "invalid two-factor code"))
+			},
+			want:    domain.Tokens{},
+			wantErr: true,
+		},
+		{
+			name: "token generation error",
+			mocksInit: func() {
+				secondFactorMock.EXPECT().Verify(gomock.Any(), gomock.Eq(in)).Return(user, nil)
+				tokenGenerator.EXPECT().Generate(gomock.Eq(userID), domain.RoleUser.String()).Return("", testingError)
+			},
+			want:    domain.Tokens{},
+			wantErr: true,
+		},
+		{
+			name: "success",
+			mocksInit: func() {
+				secondFactorMock.EXPECT().Verify(gomock.Any(), gomock.Eq(in)).Return(user, nil)
+				tokenGenerator.EXPECT().Generate(gomock.Eq(userID), domain.RoleUser.String()).Return("access", nil)
+				refreshTokenGeneratorMock.EXPECT().Generate().Return("refresh", "refreshhash", nil)
+				refreshTokenAdapterMock.EXPECT().Create(gomock.Any(), refreshTokenMatcher{userID: userID, tokenHash: "refreshhash"}).Return(nil)
+			},
+			want:    domain.Tokens{Access: "access", Refresh: "refresh"},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInit()
+
+			a := NewAuth(nil, tokenGenerator, refreshTokenGeneratorMock, nil, refreshTokenAdapterMock, nil, nil, nil, secondFactorMock, auditSinkMock, refreshTokenTTL)
+			got, err := a.SignInSecondFactor(context.TODO(), in)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestAuth_SignInExternal(t *testing.T) {
+	controller := gomock.NewController(t)
+	auditSinkMock := NewMockAuditSink(controller)
+	auditSinkMock.EXPECT().Record(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	tokenGenerator := NewMockTokenGenerator(controller)
+	refreshTokenGeneratorMock := NewMockOpaqueTokenGenerator(controller)
+	refreshTokenAdapterMock := NewMockRefreshTokenAdapter(controller)
+	secondFactorMock := NewMockSecondFactor(controller)
+
+	userID := uuid.New()
+	totpEnabledAt := time.Now()
+
+	tests := []struct {
+		name      string
+		user      domain.User
+		mocksInit func()
+		want      domain.Tokens
+	}{
+		{
+			name: "two-factor authentication is required",
+			user: domain.User{ID: userID, Role: domain.RoleUser, TOTPEnabledAt: &totpEnabledAt},
+			mocksInit: func() {
+				secondFactorMock.EXPECT().Challenge(gomock.Eq(userID)).Return(domain.Token("mfatoken"))
+			},
+			want: domain.Tokens{MFA: "mfatoken"},
+		},
+		{
+			name: "success",
+			user: domain.User{ID: userID, Role: domain.RoleUser},
+			mocksInit: func() {
+				tokenGenerator.EXPECT().Generate(gomock.Eq(userID), domain.RoleUser.String()).Return("access", nil)
+				refreshTokenGeneratorMock.EXPECT().Generate().Return("refresh", "refreshhash", nil)
+				refreshTokenAdapterMock.EXPECT().Create(gomock.Any(), refreshTokenMatcher{userID: userID, tokenHash: "refreshhash"}).Return(nil)
+			},
+			want: domain.Tokens{Access: "access", Refresh: "refresh"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInit()
+
+			a := NewAuth(nil, tokenGenerator, refreshTokenGeneratorMock, nil, refreshTokenAdapterMock, nil, nil, nil, secondFactorMock, auditSinkMock, refreshTokenTTL)
+			got, err := a.SignInExternal(context.TODO(), tt.user)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestAuth_Refresh(t *testing.T) {
+	controller := gomock.NewController(t)
+	auditSinkMock := NewMockAuditSink(controller)
+	auditSinkMock.EXPECT().Record(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	userAdapterMock := NewMockUserAdapter(controller)
+	tokenGenerator := NewMockTokenGenerator(controller)
+	refreshTokenGeneratorMock := NewMockOpaqueTokenGenerator(controller)
+	refreshTokenAdapterMock := NewMockRefreshTokenAdapter(controller)
+
+	testingError := errors.New("testing-error")
+
+	userID := uuid.New()
+	familyID := uuid.New()
+
+	refreshToken := domain.Token("refreshtoken")
+	refreshTokenHash := "refreshtokenhash"
+	newRefreshToken := "newrefreshtoken"
+	newRefreshTokenHash := "newrefreshtokenhash"
+	token := "tokentoken"
+
+	now := time.Now()
+
+	stored := domain.RefreshToken{
+		ID:        uuid.New(),
+		FamilyID:  familyID,
+		UserID:    userID,
+		TokenHash: refreshTokenHash,
+		ExpiresAt: now.Add(time.Hour),
+	}
+
+	used := stored
+	used.UsedAt = &now
+
+	revoked := stored
+	revoked.RevokedAt = &now
+
+	expired := stored
+	expired.ExpiresAt = now.Add(-time.Minute)
+
+	tests := []struct {
+		name      string
+		mocksInit func()
+		want      domain.Tokens
+		wantErr   bool
+	}{
+		{
+			name: "token not found",
+			mocksInit: func() {
+				refreshTokenGeneratorMock.EXPECT().Hash(gomock.Eq(string(refreshToken))).Return(refreshTokenHash)
+				refreshTokenAdapterMock.EXPECT().GetByHash(gomock.Any(), gomock.Eq(refreshTokenHash)).
+					Return(domain.RefreshToken{}, ierr.New(ierr.NotFound, "testing-error"))
+			},
+			want:    domain.Tokens{},
+			wantErr: true,
+		},
+		{
+			name: "revoked token",
+			mocksInit: func() {
+				refreshTokenGeneratorMock.EXPECT().Hash(gomock.Eq(string(refreshToken))).Return(refreshTokenHash)
+				refreshTokenAdapterMock.EXPECT().GetByHash(gomock.Any(), gomock.Eq(refreshTokenHash)).Return(revoked, nil)
+			},
+			want:    domain.Tokens{},
+			wantErr: true,
+		},
+		{
+			name: "reused token revokes family",
+			mocksInit: func() {
+				refreshTokenGeneratorMock.EXPECT().Hash(gomock.Eq(string(refreshToken))).Return(refreshTokenHash)
+				refreshTokenAdapterMock.EXPECT().GetByHash(gomock.Any(), gomock.Eq(refreshTokenHash)).Return(used, nil)
+				refreshTokenAdapterMock.EXPECT().RevokeFamily(gomock.Any(), gomock.Eq(familyID)).Return(nil)
+			},
+			want:    domain.Tokens{},
+			wantErr: true,
+		},
+		{
+			name: "expired token",
+			mocksInit: func() {
+				refreshTokenGeneratorMock.EXPECT().Hash(gomock.Eq(string(refreshToken))).Return(refreshTokenHash)
+				refreshTokenAdapterMock.EXPECT().GetByHash(gomock.Any(), gomock.Eq(refreshTokenHash)).Return(expired, nil)
+			},
+			want:    domain.Tokens{},
+			wantErr: true,
+		},
+		{
+			name: "concurrently used token revokes family",
+			mocksInit: func() {
+				refreshTokenGeneratorMock.EXPECT().Hash(gomock.Eq(string(refreshToken))).Return(refreshTokenHash)
+				refreshTokenAdapterMock.EXPECT().GetByHash(gomock.Any(), gomock.Eq(refreshTokenHash)).Return(stored, nil)
+				refreshTokenAdapterMock.EXPECT().MarkUsed(gomock.Any(), gomock.Eq(stored.ID)).Return(false, nil)
+				refreshTokenAdapterMock.EXPECT().RevokeFamily(gomock.Any(), gomock.Eq(familyID)).Return(nil)
+			},
+			want:    domain.Tokens{},
+			wantErr: true,
+		},
+		{
+			name: "marking token used error",
+			mocksInit: func() {
+				refreshTokenGeneratorMock.EXPECT().Hash(gomock.Eq(string(refreshToken))).Return(refreshTokenHash)
+				refreshTokenAdapterMock.EXPECT().GetByHash(gomock.Any(), gomock.Eq(refreshTokenHash)).Return(stored, nil)
+				refreshTokenAdapterMock.EXPECT().MarkUsed(gomock.Any(), gomock.Eq(stored.ID)).Return(false, testingError)
+			},
+			want:    domain.Tokens{},
+			wantErr: true,
+		},
+		{
+			name: "getting user error",
+			mocksInit: func() {
+				refreshTokenGeneratorMock.EXPECT().Hash(gomock.Eq(string(refreshToken))).Return(refreshTokenHash)
+				refreshTokenAdapterMock.EXPECT().GetByHash(gomock.Any(), gomock.Eq(refreshTokenHash)).Return(stored, nil)
+				refreshTokenAdapterMock.EXPECT().MarkUsed(gomock.Any(), gomock.Eq(stored.ID)).Return(true, nil)
+				userAdapterMock.EXPECT().Get(gomock.Any(), gomock.Eq(userID)).Return(domain.User{}, testingError)
+			},
+			want:    domain.Tokens{},
+			wantErr: true,
+		},
+		{
+			name: "success",
+			mocksInit: func() {
+				refreshTokenGeneratorMock.EXPECT().Hash(gomock.Eq(string(refreshToken))).Return(refreshTokenHash)
+				refreshTokenAdapterMock.EXPECT().GetByHash(gomock.Any(), gomock.Eq(refreshTokenHash)).Return(stored, nil)
+				refreshTokenAdapterMock.EXPECT().MarkUsed(gomock.Any(), gomock.Eq(stored.ID)).Return(true, nil)
+				userAdapterMock.EXPECT().Get(gomock.Any(), gomock.Eq(userID)).Return(domain.User{ID: userID, Role: domain.RoleUser}, nil)
+				tokenGenerator.EXPECT().Generate(gomock.Eq(userID), domain.RoleUser.String()).Return(token, nil)
+				refreshTokenGeneratorMock.EXPECT().Generate().Return(newRefreshToken, newRefreshTokenHash, nil)
+				refreshTokenAdapterMock.EXPECT().Create(
+					gomock.Any(),
+					refreshTokenMatcher{userID: userID, familyID: familyID, tokenHash: newRefreshTokenHash},
+				).Return(nil)
+			},
+			want: domain.Tokens{
+				Access:  domain.Token(token),
+				Refresh: domain.Token(newRefreshToken),
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInit()
+
+			a := NewAuth(nil, tokenGenerator, refreshTokenGeneratorMock, userAdapterMock, refreshTokenAdapterMock, nil, nil, nil, nil, auditSinkMock, refreshTokenTTL)
+			got, err := a.Refresh(context.TODO(), refreshToken)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestAuth_Logout(t *testing.T) {
+	controller := gomock.NewController(t)
+	refreshTokenGeneratorMock := NewMockOpaqueTokenGenerator(controller)
+	refreshTokenAdapterMock := NewMockRefreshTokenAdapter(controller)
+	revocationAdapterMock := NewMockTokenRevocationAdapter(controller)
+
+	testingError := errors.New("testing-error")
+
+	claims := domain.TokenClaims{
+		ID:        uuid.New(),
+		UserID:    uuid.New(),
+		IssuedAt:  time.Now().Add(-time.Minute),
+		ExpiresAt: time.Now().Add(time.Minute),
+	}
+
+	refreshToken := domain.Token("refreshtoken")
+	refreshTokenHash := "refreshtokenhash"
+
+	stored := domain.RefreshToken{
+		ID:        uuid.New(),
+		FamilyID:  uuid.New(),
+		UserID:    claims.UserID,
+		TokenHash: refreshTokenHash,
+	}
+
+	foreign := stored
+	foreign.UserID = uuid.New()
+
+	tests := []struct {
+		name         string
+		refreshToken domain.Token
+		mocksInit    func()
+		wantErr      bool
+	}{
+		{
+			name:         "revoking access token error",
+			refreshToken: refreshToken,
+			mocksInit: func() {
+				revocationAdapterMock.EXPECT().Revoke(gomock.Any(), gomock.Eq(claims)).Return(testingError)
+			},
+			wantErr: true,
+		},
+		{
+			name:         "without refresh token",
+			refreshToken: "",
+			mocksInit: func() {
+				revocationAdapterMock.EXPECT().Revoke(gomock.Any(), gomock.Eq(claims)).Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name:         "unknown refresh token",
+			refreshToken: refreshToken,
+			mocksInit: func() {
+				revocationAdapterMock.EXPECT().Revoke(gomock.Any(), gomock.Eq(claims)).Return(nil)
+				refreshTokenGeneratorMock.EXPECT().Hash(gomock.Eq(string(refreshToken))).Return(refreshTokenHash)
+				refreshTokenAdapterMock.EXPECT().GetByHash(gomock.Any(), gomock.Eq(refreshTokenHash)).
+					Return(domain.RefreshToken{}, ierr.New(ierr.NotFound, "testing-error"))
+			},
+			wantErr: false,
+		},
+		{
+			name:         "refresh token of another user is ignored",
+			refreshToken: refreshToken,
+			mocksInit: func() {
+				revocationAdapterMock.EXPECT().Revoke(gomock.Any(), gomock.Eq(claims)).Return(nil)
+				refreshTokenGeneratorMock.EXPECT().Hash(gomock.Eq(string(refreshToken))).Return(refreshTokenHash)
+				refreshTokenAdapterMock.EXPECT().GetByHash(gomock.Any(), gomock.Eq(refreshTokenHash)).Return(foreign, nil)
+			},
+			wantErr: false,
+		},
+		{
+			name:         "success",
+			refreshToken: refreshToken,
+			mocksInit: func() {
+				revocationAdapterMock.EXPECT().Revoke(gomock.Any(), gomock.Eq(claims)).Return(nil)
+				refreshTokenGeneratorMock.EXPECT().Hash(gomock.Eq(string(refreshToken))).Return(refreshTokenHash)
+				refreshTokenAdapterMock.EXPECT().GetByHash(gomock.Any(), gomock.Eq(refreshTokenHash)).Return(stored, nil)
+				refreshTokenAdapterMock.EXPECT().RevokeFamily(gomock.Any(), gomock.Eq(stored.FamilyID)).Return(nil)
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInit()
+
+			a := NewAuth(nil, nil, refreshTokenGeneratorMock, nil, refreshTokenAdapterMock, revocationAdapterMock, nil, nil, nil, nil, refreshTokenTTL)
+			err := a.Logout(context.TODO(), claims, tt.refreshToken)
+			assert.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
+
+func TestAuth_LogoutAll(t *testing.T) {
+	controller := gomock.NewController(t)
+	refreshTokenAdapterMock := NewMockRefreshTokenAdapter(controller)
+	revocationAdapterMock := NewMockTokenRevocationAdapter(controller)
+
+	testingError := errors.New("testing-error")
+	userID := uuid.New()
+
+	tests := []struct {
+		name      string
+		mocksInit func()
+		wantErr   bool
+	}{
+		{
+			name: "revoking access tokens error",
+			mocksInit: func() {
+				revocationAdapterMock.EXPECT().RevokeAllIssuedBefore(gomock.Any(), gomock.Eq(userID), gomock.Any()).Return(testingError)
+			},
+			wantErr: true,
+		},
+		{
+			name: "revoking refresh tokens error",
+			mocksInit: func() {
+				revocationAdapterMock.EXPECT().RevokeAllIssuedBefore(gomock.Any(), gomock.Eq(userID), gomock.Any()).Return(nil)
+				refreshTokenAdapterMock.EXPECT().RevokeAllForUser(gomock.Any(), gomock.Eq(userID)).Return(testingError)
+			},
+			wantErr: true,
+		},
+		{
+			name: "success",
+			mocksInit: func() {
+				revocationAdapterMock.EXPECT().RevokeAllIssuedBefore(gomock.Any(), gomock.Eq(userID), gomock.Any()).Return(nil)
+				refreshTokenAdapterMock.EXPECT().RevokeAllForUser(gomock.Any(), gomock.Eq(userID)).Return(nil)
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInit()
+
+			a := NewAuth(nil, nil, nil, nil, refreshTokenAdapterMock, revocationAdapterMock, nil, nil, nil, nil, refreshTokenTTL)
+			err := a.LogoutAll(context.TODO(), userID)
+			assert.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
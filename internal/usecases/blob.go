@@ -0,0 +1,31 @@
+package usecases
+
+import (
+	"context"
+	"log"
+
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+)
+
+// deleteStoredImages removes renditions of the images from the store, images given by URL aren't stored and are skipped.
+// Errors are only logged, the images are no longer referenced by then.
+func deleteStoredImages(ctx context.Context, store BlobStore, images []domain.DogImages) {
+	deleted := make(map[string]bool)
+	for _, image := range images {
+		for _, url := range []string{image.Thumb, image.Card, image.Full} {
+			if url == "" {
+				continue
+			}
+
+			key, ok := store.Key(url)
+			if !ok || deleted[key] {
+				continue
+			}
+
+			deleted[key] = true
+			if err := store.Delete(ctx, key); err != nil {
+				log.Printf("deleting image %s error: %s", key, err)
+			}
+		}
+	}
+}
@@ -2,6 +2,8 @@ package usecases
 
 import (
 	"context"
+	"io"
+	"time"
 
 	"github.com/valerii-smirnov/petli-test-task/internal/domain"
 
@@ -11,25 +13,197 @@ import (
 //go:generate mockgen -destination=./mock_test.go -package=usecases -source=./contracts.go
 
 type DogAdapter interface {
-	List(ctx context.Context, userID uuid.UUID, pagination domain.Pagination) (domain.DogList, error)
+	List(ctx context.Context, userID uuid.UUID, filter domain.DogFilter, pagination domain.Pagination) (domain.DogPage, error)
+	Feed(ctx context.Context, dog domain.Dog, filter domain.DogFilter, pagination domain.Pagination) (domain.DogPage, error)
 	Get(ctx context.Context, dogID uuid.UUID) (domain.Dog, error)
-	Matches(ctx context.Context, dogID uuid.UUID, pagination domain.Pagination) (domain.DogList, error)
+	Matches(ctx context.Context, dogID uuid.UUID, pagination domain.Pagination) (domain.DogPage, error)
 	Create(ctx context.Context, dog domain.Dog) (domain.Dog, error)
 	Update(ctx context.Context, dogID uuid.UUID, dog domain.Dog) (domain.Dog, error)
-	Delete(ctx context.Context, dogID uuid.UUID) error
+	AddPhoto(ctx context.Context, dogID uuid.UUID, photo domain.DogPhoto, maxPhotos int) (domain.Dog, error)
+	DeletePhoto(ctx context.Context, dogID, photoID uuid.UUID) (domain.Dog, domain.DogImages, error)
+	ReorderPhotos(ctx context.Context, dogID uuid.UUID, photoIDs []uuid.UUID) (domain.Dog, error)
+	SetPrimaryPhoto(ctx context.Context, dogID, photoID uuid.UUID) (domain.Dog, error)
+	SimilarPhoto(ctx context.Context, hash domain.ImageHash, exceptUserID uuid.UUID, maxDistance int) (domain.SimilarPhoto, error)
+	Preferences(ctx context.Context, dogID uuid.UUID) (domain.DogPreferences, error)
+	SetPreferences(ctx context.Context, dogID uuid.UUID, prefs domain.DogPreferences) (domain.DogPreferences, error)
+	PreferencesOf(ctx context.Context, dogIDs []uuid.UUID) (map[uuid.UUID]domain.DogPreferences, error)
+	ReactionStats(ctx context.Context, dogID uuid.UUID, reactorIDs []uuid.UUID) (map[uuid.UUID]domain.ReactionStats, error)
+	Delete(ctx context.Context, dogID uuid.UUID) ([]domain.DogImages, error)
 	AddReaction(ctx context.Context, reaction domain.Reaction) error
+	ListByUser(ctx context.Context, userID uuid.UUID) (domain.DogList, error)
+	UserReactions(ctx context.Context, userID uuid.UUID) ([]domain.Reaction, error)
+}
+
+type FlaggedPhotoAdapter interface {
+	Create(ctx context.Context, photo domain.FlaggedPhoto) (domain.FlaggedPhoto, error)
+	List(ctx context.Context, filter domain.FlaggedPhotoFilter) ([]domain.FlaggedPhoto, error)
+	Approve(ctx context.Context, photoID, reviewerID uuid.UUID, maxPhotos int) (domain.FlaggedPhoto, error)
+	Reject(ctx context.Context, photoID, reviewerID uuid.UUID) (domain.FlaggedPhoto, error)
 }
 
 type UserAdapter interface {
 	Create(ctx context.Context, su domain.SignUp) error
-	Get(ctx context.Context, si domain.SingIn) (domain.User, error)
+	Get(ctx context.Context, userID uuid.UUID) (domain.User, error)
+	GetByEmail(ctx context.Context, email string) (domain.User, error)
 	Exists(ctx context.Context, email string) (bool, error)
+	UpdatePasswordHash(ctx context.Context, userID uuid.UUID, passwordHash string) error
+	MarkEmailVerified(ctx context.Context, userID uuid.UUID) error
+	SetTOTPSecret(ctx context.Context, userID uuid.UUID, secret string) error
+	EnableTOTP(ctx context.Context, userID uuid.UUID) error
+	DisableTOTP(ctx context.Context, userID uuid.UUID) error
+	UseTOTPStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error)
+	UpdateRole(ctx context.Context, userID uuid.UUID, role domain.Role) error
+	UpdateProfile(ctx context.Context, userID uuid.UUID, profile domain.Profile) (domain.User, error)
+	UpdateEmail(ctx context.Context, userID uuid.UUID, email string) error
+	ScheduleDeletion(ctx context.Context, userID uuid.UUID, at time.Time) error
+	CancelDeletion(ctx context.Context, userID uuid.UUID) error
+	DeleteScheduled(ctx context.Context, before time.Time) (domain.PurgedAccounts, error)
+}
+
+type RecoveryCodeAdapter interface {
+	Replace(ctx context.Context, userID uuid.UUID, codeHashes []string) error
+	Use(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error)
+}
+
+type UserIdentityAdapter interface {
+	GetUserID(ctx context.Context, issuer, subject string) (uuid.UUID, error)
+	Create(ctx context.Context, userID uuid.UUID, identity domain.ExternalIdentity) error
+}
+
+type APIKeyAdapter interface {
+	Create(ctx context.Context, key domain.APIKey) (domain.APIKey, error)
+	List(ctx context.Context, userID uuid.UUID) ([]domain.APIKey, error)
+	Use(ctx context.Context, keyHash string) (domain.APIKey, error)
+	Revoke(ctx context.Context, userID, keyID uuid.UUID) error
+	RevokeAll(ctx context.Context, userID uuid.UUID) error
+}
+
+type DataExportAdapter interface {
+	Create(ctx context.Context, userID uuid.UUID) (domain.DataExport, error)
+	Get(ctx context.Context, userID, exportID uuid.UUID) (domain.DataExport, error)
+	GetUnfinished(ctx context.Context, userID uuid.UUID) (domain.DataExport, error)
+	GetArchive(ctx context.Context, userID, exportID uuid.UUID) ([]byte, error)
+	Claim(ctx context.Context, staleBefore time.Time) (domain.DataExport, error)
+	Complete(ctx context.Context, exportID uuid.UUID, archive []byte, expiresAt time.Time) error
+	Fail(ctx context.Context, exportID uuid.UUID) error
+	DeleteExpired(ctx context.Context) (int64, error)
+}
+
+type AuditEventAdapter interface {
+	List(ctx context.Context, filter domain.AuditEventFilter) ([]domain.AuditEvent, error)
+}
+
+type RefreshTokenAdapter interface {
+	Create(ctx context.Context, rt domain.RefreshToken) error
+	GetByHash(ctx context.Context, tokenHash string) (domain.RefreshToken, error)
+	MarkUsed(ctx context.Context, id uuid.UUID) (bool, error)
+	RevokeFamily(ctx context.Context, familyID uuid.UUID) error
+	RevokeAllForUser(ctx context.Context, userID uuid.UUID) error
+}
+
+type PasswordResetTokenAdapter interface {
+	Create(ctx context.Context, prt domain.PasswordResetToken) error
+	GetByHash(ctx context.Context, tokenHash string) (domain.PasswordResetToken, error)
+	MarkUsed(ctx context.Context, id uuid.UUID) (bool, error)
+	InvalidateForUser(ctx context.Context, userID uuid.UUID) error
+}
+
+type SignInAttemptsAdapter interface {
+	Get(ctx context.Context, key string) (domain.SignInAttempts, error)
+	RegisterFailure(ctx context.Context, key string, failedAt, resetBefore time.Time) (domain.SignInAttempts, error)
+	Reset(ctx context.Context, key string) error
+	DeleteStale(ctx context.Context, before time.Time) (int64, error)
+}
+
+type TokenRevocationAdapter interface {
+	Revoke(ctx context.Context, claims domain.TokenClaims) error
+	RevokeAllIssuedBefore(ctx context.Context, userID uuid.UUID, before time.Time) error
+	IsRevoked(ctx context.Context, claims domain.TokenClaims) (bool, error)
+	DeleteExpired(ctx context.Context) (int64, error)
 }
 
 type PasswordHasher interface {
-	StringHash(in string) string
+	Hash(password string) (string, error)
+	Verify(password, hash string) (bool, error)
+	NeedsRehash(hash string) bool
 }
 
 type TokenGenerator interface {
-	Generate(userID uuid.UUID) (string, error)
+	Generate(userID uuid.UUID, role string) (string, error)
+}
+
+type OpaqueTokenGenerator interface {
+	Generate() (token string, hash string, err error)
+	Hash(token string) string
+}
+
+type LinkSigner interface {
+	Sign(purpose, subject string, ttl time.Duration) string
+	Verify(purpose, token string) (subject string, err error)
+}
+
+type OTPAuthenticator interface {
+	GenerateSecret() (string, error)
+	ProvisioningURI(secret, account string) string
+	Validate(secret, code string, at time.Time) (step int64, ok bool)
+	GenerateRecoveryCodes(n int) ([]string, error)
+}
+
+type OIDCProvider interface {
+	AuthCodeURL(state, nonce, codeVerifier string) string
+	Exchange(ctx context.Context, code, codeVerifier, nonce string) (domain.ExternalIdentity, error)
+}
+
+type AuditSink interface {
+	Record(ctx context.Context, event domain.AuditEvent) error
+}
+
+type ExportArchiver interface {
+	Build(data domain.AccountData) ([]byte, error)
+}
+
+type Mailer interface {
+	Send(ctx context.Context, to, subject, body string) error
+}
+
+// BlobStore stores uploaded files under the key and returns URL the file is served at.
+type BlobStore interface {
+	Put(ctx context.Context, key, contentType string, content io.Reader) (string, error)
+	Delete(ctx context.Context, key string) error
+	// Key returns key of the file served at the URL, false for URLs the store doesn't serve, e.g. images given by URL.
+	Key(url string) (string, bool)
+}
+
+// ImageProcessor turns uploaded image into renditions safe to publish and hashes it.
+// Undecodable image is ierr.InvalidArgument.
+type ImageProcessor interface {
+	Process(content io.Reader) (domain.ProcessedUpload, error)
+}
+
+// Geocoder finds coordinates of the city by its name, unknown city is ierr.NotFound.
+type Geocoder interface {
+	Geocode(name string) (domain.City, error)
+}
+
+type SignInGuard interface {
+	Check(ctx context.Context, email, clientIP string) error
+	RegisterFailure(ctx context.Context, email, clientIP string) error
+	RegisterSuccess(ctx context.Context, email string) error
+}
+
+type EmailVerificationSender interface {
+	SendVerification(ctx context.Context, email string) error
+}
+
+type SessionRevoker interface {
+	LogoutAll(ctx context.Context, userID uuid.UUID) error
+}
+
+type SecondFactor interface {
+	Challenge(userID uuid.UUID) domain.Token
+	Verify(ctx context.Context, in domain.SecondFactorSignIn) (domain.User, error)
+}
+
+type SessionIssuer interface {
+	SignInExternal(ctx context.Context, user domain.User) (domain.Tokens, error)
 }
@@ -0,0 +1,200 @@
+package usecases
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// exportProcessingTimeout export processed longer is considered abandoned and is processed again.
+	exportProcessingTimeout = 10 * time.Minute
+	exportMatchesPageSize   = 100
+)
+
+// DataExport exports everything stored about the user. Accounts with few dogs are exported right away,
+// larger ones by background job the user polls.
+type DataExport struct {
+	exportAdapter DataExportAdapter
+	userAdapter   UserAdapter
+	dogAdapter    DogAdapter
+	archiver      ExportArchiver
+	syncMaxDogs   int
+	archiveTTL    time.Duration
+}
+
+func NewDataExport(
+	exportAdapter DataExportAdapter,
+	userAdapter UserAdapter,
+	dogAdapter DogAdapter,
+	archiver ExportArchiver,
+	syncMaxDogs int,
+	archiveTTL time.Duration,
+) *DataExport {
+	return &DataExport{
+		exportAdapter: exportAdapter,
+		userAdapter:   userAdapter,
+		dogAdapter:    dogAdapter,
+		archiver:      archiver,
+		syncMaxDogs:   syncMaxDogs,
+		archiveTTL:    archiveTTL,
+	}
+}
+
+// Export returns archive of the user's data if the user has no more than syncMaxDogs dogs,
+// otherwise starts export job. Unfinished job is returned instead of starting another one.
+func (e DataExport) Export(ctx context.Context, userID uuid.UUID) (domain.ExportResult, error) {
+	user, err := e.userAdapter.Get(ctx, userID)
+	if err != nil {
+		return domain.ExportResult{}, err
+	}
+
+	dogs, err := e.dogAdapter.ListByUser(ctx, userID)
+	if err != nil {
+		return domain.ExportResult{}, err
+	}
+
+	if len(dogs) <= e.syncMaxDogs {
+		archive, err := e.build(ctx, user, dogs)
+		if err != nil {
+			return domain.ExportResult{}, err
+		}
+
+		return domain.ExportResult{Archive: archive}, nil
+	}
+
+	job, err := e.exportAdapter.GetUnfinished(ctx, userID)
+	if err == nil {
+		return domain.ExportResult{Job: &job}, nil
+	}
+
+	if ierr.GetCode(err) != ierr.NotFound {
+		return domain.ExportResult{}, err
+	}
+
+	job, err = e.exportAdapter.Create(ctx, userID)
+	if err != nil {
+		return domain.ExportResult{}, err
+	}
+
+	return domain.ExportResult{Job: &job}, nil
+}
+
+func (e DataExport) Get(ctx context.Context, userID, exportID uuid.UUID) (domain.DataExport, error) {
+	return e.exportAdapter.Get(ctx, userID, exportID)
+}
+
+// Archive returns archive of the finished export job.
+func (e DataExport) Archive(ctx context.Context, userID, exportID uuid.UUID) ([]byte, error) {
+	job, err := e.exportAdapter.Get(ctx, userID, exportID)
+	if err != nil {
+		return nil, err
+	}
+
+	if job.Status != domain.DataExportReady {
+		return nil, ierr.New(ierr.InvalidArgument, "data export is "+job.Status.String())
+	}
+
+	return e.exportAdapter.GetArchive(ctx, userID, exportID)
+}
+
+// ProcessPending builds archives of all pending export jobs.
+// Failed job is marked as failed, so the user can start another one.
+func (e DataExport) ProcessPending(ctx context.Context) error {
+	for {
+		job, err := e.exportAdapter.Claim(ctx, time.Now().Add(-exportProcessingTimeout))
+		if err != nil {
+			if ierr.GetCode(err) == ierr.NotFound {
+				return nil
+			}
+
+			return err
+		}
+
+		archive, err := e.buildForUser(ctx, job.UserID)
+		if err != nil {
+			log.Printf("data export %s error: %s", job.ID, err)
+
+			if err := e.exportAdapter.Fail(ctx, job.ID); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if err := e.exportAdapter.Complete(ctx, job.ID, archive, time.Now().Add(e.archiveTTL)); err != nil {
+			return err
+		}
+	}
+}
+
+// PruneExpired removes expired archives.
+func (e DataExport) PruneExpired(ctx context.Context) error {
+	_, err := e.exportAdapter.DeleteExpired(ctx)
+	return err
+}
+
+func (e DataExport) buildForUser(ctx context.Context, userID uuid.UUID) ([]byte, error) {
+	user, err := e.userAdapter.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	dogs, err := e.dogAdapter.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return e.build(ctx, user, dogs)
+}
+
+func (e DataExport) build(ctx context.Context, user domain.User, dogs domain.DogList) ([]byte, error) {
+	reactions, err := e.dogAdapter.UserReactions(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make([]domain.Match, 0)
+	for _, dog := range dogs {
+		dogMatches, err := e.matches(ctx, dog.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		matches = append(matches, dogMatches...)
+	}
+
+	return e.archiver.Build(domain.AccountData{
+		User:      user,
+		Dogs:      dogs,
+		Reactions: reactions,
+		Matches:   matches,
+	})
+}
+
+// matches pages through all matches of the dog.
+func (e DataExport) matches(ctx context.Context, dogID uuid.UUID) ([]domain.Match, error) {
+	matches := make([]domain.Match, 0)
+	pagination := domain.Pagination{Page: 1, PerPage: exportMatchesPageSize}
+	for {
+		page, err := e.dogAdapter.Matches(ctx, dogID, pagination)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, matched := range page.Dogs {
+			matches = append(matches, domain.Match{DogID: dogID, MatchedDog: matched})
+		}
+
+		if page.NextCursor == nil {
+			return matches, nil
+		}
+
+		pagination.After = page.NextCursor
+	}
+}
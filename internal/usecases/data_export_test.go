@@ -0,0 +1,187 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+)
+
+const (
+	exportSyncMaxDogs = 1
+	exportArchiveTTL  = 24 * time.Hour
+)
+
+func TestDataExport_Export(t *testing.T) {
+	controller := gomock.NewController(t)
+	exportAdapterMock := NewMockDataExportAdapter(controller)
+	userAdapterMock := NewMockUserAdapter(controller)
+	dogAdapterMock := NewMockDogAdapter(controller)
+	archiverMock := NewMockExportArchiver(controller)
+
+	userID := uuid.New()
+	user := domain.User{ID: userID, Email: "test@test.com"}
+	dog := domain.Dog{ID: uuid.New(), UserID: userID, Name: "Spike"}
+	matched := domain.Dog{ID: uuid.New(), Name: "Tyke"}
+	matchesCursor := domain.Cursor{CreatedAt: time.Now(), ID: matched.ID}
+	reactions := []domain.Reaction{{Liker: dog.ID, Liked: matched.ID, Action: domain.Like}}
+	job := domain.DataExport{ID: uuid.New(), UserID: userID, Status: domain.DataExportPending}
+
+	tests := []struct {
+		name      string
+		mocksInit func()
+		want      domain.ExportResult
+		wantCode  ierr.Code
+		wantErr   bool
+	}{
+		{
+			name: "user not found",
+			mocksInit: func() {
+				userAdapterMock.EXPECT().Get(gomock.Any(), userID).Return(domain.User{}, ierr.New(ierr.NotFound, "user not found"))
+			},
+			wantCode: ierr.NotFound,
+			wantErr:  true,
+		},
+		{
+			name: "small export is built right away",
+			mocksInit: func() {
+				userAdapterMock.EXPECT().Get(gomock.Any(), userID).Return(user, nil)
+				dogAdapterMock.EXPECT().ListByUser(gomock.Any(), userID).Return(domain.DogList{dog}, nil)
+				dogAdapterMock.EXPECT().UserReactions(gomock.Any(), userID).Return(reactions, nil)
+				dogAdapterMock.EXPECT().Matches(gomock.Any(), dog.ID, domain.Pagination{Page: 1, PerPage: exportMatchesPageSize}).
+					Return(domain.DogPage{Dogs: domain.DogList{matched}, NextCursor: &matchesCursor}, nil)
+				dogAdapterMock.EXPECT().Matches(gomock.Any(), dog.ID, domain.Pagination{Page: 1, PerPage: exportMatchesPageSize, After: &matchesCursor}).
+					Return(domain.DogPage{Dogs: domain.DogList{}}, nil)
+				archiverMock.EXPECT().Build(domain.AccountData{
+					User:      user,
+					Dogs:      domain.DogList{dog},
+					Reactions: reactions,
+					Matches:   []domain.Match{{DogID: dog.ID, MatchedDog: matched}},
+				}).Return([]byte("archive"), nil)
+			},
+			want:    domain.ExportResult{Archive: []byte("archive")},
+			wantErr: false,
+		},
+		{
+			name: "large export is started as job",
+			mocksInit: func() {
+				userAdapterMock.EXPECT().Get(gomock.Any(), userID).Return(user, nil)
+				dogAdapterMock.EXPECT().ListByUser(gomock.Any(), userID).Return(domain.DogList{dog, dog}, nil)
+				exportAdapterMock.EXPECT().GetUnfinished(gomock.Any(), userID).Return(domain.DataExport{}, ierr.New(ierr.NotFound, "data export not found"))
+				exportAdapterMock.EXPECT().Create(gomock.Any(), userID).Return(job, nil)
+			},
+			want:    domain.ExportResult{Job: &job},
+			wantErr: false,
+		},
+		{
+			name: "unfinished job is reused",
+			mocksInit: func() {
+				userAdapterMock.EXPECT().Get(gomock.Any(), userID).Return(user, nil)
+				dogAdapterMock.EXPECT().ListByUser(gomock.Any(), userID).Return(domain.DogList{dog, dog}, nil)
+				exportAdapterMock.EXPECT().GetUnfinished(gomock.Any(), userID).Return(job, nil)
+			},
+			want:    domain.ExportResult{Job: &job},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInit()
+
+			e := NewDataExport(exportAdapterMock, userAdapterMock, dogAdapterMock, archiverMock, exportSyncMaxDogs, exportArchiveTTL)
+			got, err := e.Export(context.TODO(), userID)
+			assert.Equal(t, tt.wantErr, err != nil)
+			if tt.wantErr {
+				assert.Equal(t, tt.wantCode, ierr.GetCode(err))
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDataExport_Archive(t *testing.T) {
+	controller := gomock.NewController(t)
+	exportAdapterMock := NewMockDataExportAdapter(controller)
+
+	userID := uuid.New()
+	exportID := uuid.New()
+
+	tests := []struct {
+		name      string
+		mocksInit func()
+		want      []byte
+		wantCode  ierr.Code
+		wantErr   bool
+	}{
+		{
+			name: "not ready",
+			mocksInit: func() {
+				exportAdapterMock.EXPECT().Get(gomock.Any(), userID, exportID).
+					Return(domain.DataExport{ID: exportID, Status: domain.DataExportProcessing}, nil)
+			},
+			wantCode: ierr.InvalidArgument,
+			wantErr:  true,
+		},
+		{
+			name: "success",
+			mocksInit: func() {
+				exportAdapterMock.EXPECT().Get(gomock.Any(), userID, exportID).
+					Return(domain.DataExport{ID: exportID, Status: domain.DataExportReady}, nil)
+				exportAdapterMock.EXPECT().GetArchive(gomock.Any(), userID, exportID).Return([]byte("archive"), nil)
+			},
+			want:    []byte("archive"),
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInit()
+
+			e := NewDataExport(exportAdapterMock, nil, nil, nil, exportSyncMaxDogs, exportArchiveTTL)
+			got, err := e.Archive(context.TODO(), userID, exportID)
+			assert.Equal(t, tt.wantErr, err != nil)
+			if tt.wantErr {
+				assert.Equal(t, tt.wantCode, ierr.GetCode(err))
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDataExport_ProcessPending(t *testing.T) {
+	controller := gomock.NewController(t)
+	exportAdapterMock := NewMockDataExportAdapter(controller)
+	userAdapterMock := NewMockUserAdapter(controller)
+	dogAdapterMock := NewMockDogAdapter(controller)
+	archiverMock := NewMockExportArchiver(controller)
+
+	user := domain.User{ID: uuid.New()}
+	failing := domain.DataExport{ID: uuid.New(), UserID: uuid.New(), Status: domain.DataExportProcessing}
+	succeeding := domain.DataExport{ID: uuid.New(), UserID: user.ID, Status: domain.DataExportProcessing}
+
+	gomock.InOrder(
+		exportAdapterMock.EXPECT().Claim(gomock.Any(), gomock.Any()).Return(failing, nil),
+		exportAdapterMock.EXPECT().Claim(gomock.Any(), gomock.Any()).Return(succeeding, nil),
+		exportAdapterMock.EXPECT().Claim(gomock.Any(), gomock.Any()).Return(domain.DataExport{}, ierr.New(ierr.NotFound, "data export not found")),
+	)
+
+	userAdapterMock.EXPECT().Get(gomock.Any(), failing.UserID).Return(domain.User{}, ierr.WrapCode(ierr.Internal, errors.New("testing-error"), "select error"))
+	exportAdapterMock.EXPECT().Fail(gomock.Any(), failing.ID).Return(nil)
+
+	userAdapterMock.EXPECT().Get(gomock.Any(), user.ID).Return(user, nil)
+	dogAdapterMock.EXPECT().ListByUser(gomock.Any(), user.ID).Return(domain.DogList{}, nil)
+	dogAdapterMock.EXPECT().UserReactions(gomock.Any(), user.ID).Return([]domain.Reaction{}, nil)
+	archiverMock.EXPECT().Build(gomock.Any()).Return([]byte("archive"), nil)
+	exportAdapterMock.EXPECT().Complete(gomock.Any(), succeeding.ID, []byte("archive"), gomock.Any()).Return(nil)
+
+	e := NewDataExport(exportAdapterMock, userAdapterMock, dogAdapterMock, archiverMock, exportSyncMaxDogs, exportArchiveTTL)
+	assert.NoError(t, e.ProcessPending(context.TODO()))
+}
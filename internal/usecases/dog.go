@@ -1,7 +1,10 @@
 package usecases
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"log"
 
 	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
 
@@ -10,23 +13,127 @@ import (
 	"github.com/google/uuid"
 )
 
+// imageContentTypes accepted content types of uploaded images.
+var imageContentTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
 type Dog struct {
-	dogAdapter DogAdapter
+	dogAdapter          DogAdapter
+	userAdapter         UserAdapter
+	flaggedPhotoAdapter FlaggedPhotoAdapter
+	blobStore           BlobStore
+	imageProcessor      ImageProcessor
+	geocoder            Geocoder
+	maxImageSize        int64
+	maxPhotos           int
+	// maxDuplicateDistance max distance of photo hashes the photos are considered the same at.
+	maxDuplicateDistance int
 }
 
-func NewDog(dogAdapter DogAdapter) *Dog {
+func NewDog(
+	dogAdapter DogAdapter,
+	userAdapter UserAdapter,
+	flaggedPhotoAdapter FlaggedPhotoAdapter,
+	blobStore BlobStore,
+	imageProcessor ImageProcessor,
+	geocoder Geocoder,
+	maxImageSize int64,
+	maxPhotos int,
+	maxDuplicateDistance int,
+) *Dog {
 	return &Dog{
-		dogAdapter: dogAdapter,
+		dogAdapter:           dogAdapter,
+		userAdapter:          userAdapter,
+		flaggedPhotoAdapter:  flaggedPhotoAdapter,
+		blobStore:            blobStore,
+		imageProcessor:       imageProcessor,
+		geocoder:             geocoder,
+		maxImageSize:         maxImageSize,
+		maxPhotos:            maxPhotos,
+		maxDuplicateDistance: maxDuplicateDistance,
+	}
+}
+
+func (d Dog) List(
+	ctx context.Context,
+	userID uuid.UUID,
+	filter domain.DogFilter,
+	pagination domain.Pagination,
+) (domain.DogPage, error) {
+	if err := cursorFits(filter, pagination); err != nil {
+		return domain.DogPage{}, err
 	}
+
+	page, err := d.dogAdapter.List(ctx, userID, filter, pagination)
+	if err != nil {
+		return domain.DogPage{}, ierr.WrapCode(ierr.Internal, err, "getting dogs list error")
+	}
+
+	return page, nil
 }
 
-func (d Dog) List(ctx context.Context, dogID uuid.UUID, pagination domain.Pagination) (domain.DogList, error) {
-	list, err := d.dogAdapter.List(ctx, dogID, pagination)
+// Feed returns candidates for the user's dog to react to, dogs it already liked or disliked are left out.
+// Preferences apply both ways: candidates fit preferences of the dog and the dog fits preferences of candidates.
+func (d Dog) Feed(
+	ctx context.Context,
+	userID, dogID uuid.UUID,
+	filter domain.DogFilter,
+	pagination domain.Pagination,
+) (domain.DogPage, error) {
+	if err := cursorFits(filter, pagination); err != nil {
+		return domain.DogPage{}, err
+	}
+
+	dog, err := d.ownDog(ctx, userID, dogID, "cannot get feed of not your dog")
 	if err != nil {
-		return nil, ierr.WrapCode(ierr.Internal, err, "getting dogs list error")
+		return domain.DogPage{}, err
+	}
+
+	page, err := d.dogAdapter.Feed(ctx, dog, filter, pagination)
+	if err != nil {
+		return domain.DogPage{}, ierr.WrapCode(ierr.Internal, err, "getting dog feed error")
+	}
+
+	return page, nil
+}
+
+// Preferences returns what the user's dog is looking for.
+func (d Dog) Preferences(ctx context.Context, userID, dogID uuid.UUID) (domain.DogPreferences, error) {
+	if _, err := d.ownDog(ctx, userID, dogID, "cannot get preferences of not your dog"); err != nil {
+		return domain.DogPreferences{}, err
 	}
 
-	return list, nil
+	return d.dogAdapter.Preferences(ctx, dogID)
+}
+
+// UpdatePreferences replaces what the user's dog is looking for, max distance needs location of the dog.
+func (d Dog) UpdatePreferences(
+	ctx context.Context,
+	userID, dogID uuid.UUID,
+	prefs domain.DogPreferences,
+) (domain.DogPreferences, error) {
+	dog, err := d.ownDog(ctx, userID, dogID, "cannot update preferences of not your dog")
+	if err != nil {
+		return domain.DogPreferences{}, err
+	}
+
+	if prefs.Sex != "" && !prefs.Sex.Valid() {
+		return domain.DogPreferences{}, ierr.New(ierr.InvalidArgument, "wrong preferred sex")
+	}
+
+	if prefs.MinAge != nil && prefs.MaxAge != nil && *prefs.MinAge > *prefs.MaxAge {
+		return domain.DogPreferences{}, ierr.New(ierr.InvalidArgument, "min age must not be greater than max age")
+	}
+
+	if prefs.MaxDistanceKm != nil && dog.Location == nil {
+		return domain.DogPreferences{}, ierr.New(ierr.InvalidArgument, "max distance needs the dog location")
+	}
+
+	return d.dogAdapter.SetPreferences(ctx, dogID, prefs)
 }
 
 func (d Dog) Get(ctx context.Context, uid uuid.UUID) (domain.Dog, error) {
@@ -38,21 +145,30 @@ func (d Dog) Get(ctx context.Context, uid uuid.UUID) (domain.Dog, error) {
 	return dog, nil
 }
 
-func (d Dog) Matches(ctx context.Context, userID, dogID uuid.UUID, pagination domain.Pagination) (domain.DogList, error) {
+func (d Dog) Matches(ctx context.Context, userID, dogID uuid.UUID, pagination domain.Pagination) (domain.DogPage, error) {
 	dog, err := d.dogAdapter.Get(ctx, dogID)
 	if err != nil {
-		return nil, err
+		return domain.DogPage{}, err
 	}
 
 	if dog.UserID != userID {
-		return nil, ierr.New(ierr.PermissionDenied, "cannot get matches of not your dog")
+		return domain.DogPage{}, ierr.New(ierr.PermissionDenied, "cannot get matches of not your dog")
 	}
 
 	return d.dogAdapter.Matches(ctx, dogID, pagination)
 }
 
 func (d Dog) Create(ctx context.Context, dog domain.Dog) (domain.Dog, error) {
-	dog, err := d.dogAdapter.Create(ctx, dog)
+	if err := d.requireVerifiedEmail(ctx, dog.UserID); err != nil {
+		return domain.Dog{}, err
+	}
+
+	dog, err := d.locate(dog)
+	if err != nil {
+		return domain.Dog{}, err
+	}
+
+	dog, err = d.dogAdapter.Create(ctx, dog)
 	if err != nil {
 		return domain.Dog{}, ierr.WrapCode(ierr.Internal, err, "creation dog error")
 	}
@@ -67,9 +183,22 @@ func (d Dog) Update(ctx context.Context, uid uuid.UUID, dog domain.Dog) (domain.
 	}
 
 	if dDog.UserID != dog.UserID {
-		return domain.Dog{}, ierr.WrapCode(ierr.PermissionDenied, err, "cannot edit a dog that isn't yours")
+		permitted, err := d.isPermitted(ctx, dog.UserID, domain.PermissionManageAnyDog)
+		if err != nil {
+			return domain.Dog{}, err
+		}
+
+		if !permitted {
+			return domain.Dog{}, ierr.New(ierr.PermissionDenied, "cannot edit a dog that isn't yours")
+		}
 	}
 
+	dog, err = d.locate(dog)
+	if err != nil {
+		return domain.Dog{}, err
+	}
+
+	// photos are managed separately.
 	uDog, err := d.dogAdapter.Update(ctx, uid, dog)
 	if err != nil {
 		return domain.Dog{}, ierr.WrapCode(ierr.Internal, err, "updating dog error")
@@ -78,6 +207,133 @@ func (d Dog) Update(ctx context.Context, uid uuid.UUID, dog domain.Dog) (domain.
 	return uDog, nil
 }
 
+// UploadPhoto processes the image into renditions, stores them and adds them as a photo of the dog.
+// The original upload is never stored, so its metadata can't leak. The photo looking like a photo of
+// another user's dog isn't added, it's held for moderation instead.
+func (d Dog) UploadPhoto(ctx context.Context, userID, dogID uuid.UUID, image domain.ImageUpload) (domain.PhotoUpload, error) {
+	if !imageContentTypes[image.ContentType] {
+		return domain.PhotoUpload{}, ierr.New(ierr.InvalidArgument, "image must be jpeg, png, gif or webp")
+	}
+
+	if image.Size > d.maxImageSize {
+		return domain.PhotoUpload{}, ierr.New(
+			ierr.InvalidArgument,
+			fmt.Sprintf("image must not be larger than %d bytes", d.maxImageSize),
+		)
+	}
+
+	dog, err := d.editableDog(ctx, userID, dogID)
+	if err != nil {
+		return domain.PhotoUpload{}, err
+	}
+
+	// checked before processing to save the work, the adapter checks it again atomically.
+	if len(dog.Photos) >= d.maxPhotos {
+		return domain.PhotoUpload{}, ierr.New(
+			ierr.InvalidArgument,
+			fmt.Sprintf("dog can't have more than %d photos", d.maxPhotos),
+		)
+	}
+
+	processed, err := d.imageProcessor.Process(image.Content)
+	if err != nil {
+		return domain.PhotoUpload{}, err
+	}
+
+	// photos of the same owner's dogs may look alike, only photos of other owners are compared.
+	similar, err := d.dogAdapter.SimilarPhoto(ctx, processed.Hash, dog.UserID, d.maxDuplicateDistance)
+	duplicate := err == nil
+	if err != nil && ierr.GetCode(err) != ierr.NotFound {
+		return domain.PhotoUpload{}, err
+	}
+
+	// every upload gets new keys, so cached old image is never served instead of the new one.
+	uploadID := uuid.New()
+
+	var images domain.DogImages
+	keys := make([]string, 0, len(processed.Renditions))
+	for _, rendition := range processed.Renditions {
+		key := fmt.Sprintf("dogs/%s/%s/%s%s", dogID, uploadID, rendition.Rendition, rendition.Extension)
+
+		url, err := d.blobStore.Put(ctx, key, rendition.ContentType, bytes.NewReader(rendition.Content))
+		if err != nil {
+			d.deleteImages(ctx, keys)
+			return domain.PhotoUpload{}, ierr.WrapCode(ierr.Internal, err, "storing image error")
+		}
+
+		keys = append(keys, key)
+		images.Set(rendition.Rendition, url)
+	}
+
+	if duplicate {
+		flagged, err := d.flaggedPhotoAdapter.Create(ctx, domain.FlaggedPhoto{
+			DogID:          dogID,
+			Images:         images,
+			Hash:           processed.Hash,
+			MatchedPhotoID: &similar.PhotoID,
+			MatchedDogID:   &similar.DogID,
+			Distance:       similar.Distance,
+		})
+		if err != nil {
+			d.deleteImages(ctx, keys)
+			return domain.PhotoUpload{}, err
+		}
+
+		return domain.PhotoUpload{Flagged: &flagged}, nil
+	}
+
+	uDog, err := d.dogAdapter.AddPhoto(ctx, dogID, domain.DogPhoto{Images: images, Hash: &processed.Hash}, d.maxPhotos)
+	if err != nil {
+		d.deleteImages(ctx, keys)
+		return domain.PhotoUpload{}, err
+	}
+
+	return domain.PhotoUpload{Dog: uDog}, nil
+}
+
+// DeletePhoto removes the photo from the dog together with its stored images.
+func (d Dog) DeletePhoto(ctx context.Context, userID, dogID, photoID uuid.UUID) (domain.Dog, error) {
+	if _, err := d.editableDog(ctx, userID, dogID); err != nil {
+		return domain.Dog{}, err
+	}
+
+	dog, deleted, err := d.dogAdapter.DeletePhoto(ctx, dogID, photoID)
+	if err != nil {
+		return domain.Dog{}, err
+	}
+
+	deleteStoredImages(ctx, d.blobStore, []domain.DogImages{deleted})
+
+	return dog, nil
+}
+
+// ReorderPhotos puts photos of the dog in the given order, every photo of the dog must be listed exactly once.
+func (d Dog) ReorderPhotos(ctx context.Context, userID, dogID uuid.UUID, photoIDs []uuid.UUID) (domain.Dog, error) {
+	if _, err := d.editableDog(ctx, userID, dogID); err != nil {
+		return domain.Dog{}, err
+	}
+
+	return d.dogAdapter.ReorderPhotos(ctx, dogID, photoIDs)
+}
+
+// SetPrimaryPhoto makes the photo the one representing the dog.
+func (d Dog) SetPrimaryPhoto(ctx context.Context, userID, dogID, photoID uuid.UUID) (domain.Dog, error) {
+	if _, err := d.editableDog(ctx, userID, dogID); err != nil {
+		return domain.Dog{}, err
+	}
+
+	return d.dogAdapter.SetPrimaryPhoto(ctx, dogID, photoID)
+}
+
+// deleteImages removes stored renditions of the upload which failed, errors are only logged.
+func (d Dog) deleteImages(ctx context.Context, keys []string) {
+	for _, key := range keys {
+		if err := d.blobStore.Delete(ctx, key); err != nil {
+			log.Printf("deleting orphaned image %s error: %s", key, err)
+		}
+	}
+}
+
 func (d Dog) Delete(ctx context.Context, dogUid, userUid uuid.UUID) error {
 	dDog, err := d.dogAdapter.Get(ctx, dogUid)
 	if err != nil {
@@ -85,13 +341,23 @@ func (d Dog) Delete(ctx context.Context, dogUid, userUid uuid.UUID) error {
 	}
 
 	if dDog.UserID != userUid {
-		return ierr.WrapCode(ierr.PermissionDenied, err, "cannot delete a dog that isn't yours")
+		permitted, err := d.isPermitted(ctx, userUid, domain.PermissionManageAnyDog)
+		if err != nil {
+			return err
+		}
+
+		if !permitted {
+			return ierr.New(ierr.PermissionDenied, "cannot delete a dog that isn't yours")
+		}
 	}
 
-	if err := d.dogAdapter.Delete(ctx, dogUid); err != nil {
+	deleted, err := d.dogAdapter.Delete(ctx, dogUid)
+	if err != nil {
 		return ierr.WrapCode(ierr.Internal, err, "deletion dog error")
 	}
 
+	deleteStoredImages(ctx, d.blobStore, deleted)
+
 	return nil
 }
 
@@ -109,9 +375,111 @@ func (d Dog) AddReaction(ctx context.Context, uid uuid.UUID, reaction domain.Rea
 		return ierr.New(ierr.InvalidArgument, "you're not an owner of liker dog")
 	}
 
+	if err := d.requireVerifiedEmail(ctx, uid); err != nil {
+		return err
+	}
+
 	if err := d.dogAdapter.AddReaction(ctx, reaction); err != nil {
 		return ierr.WrapCode(ierr.Internal, err, "adding reaction error")
 	}
 
 	return nil
 }
+
+// editableDog returns the dog if the user owns it or may manage any dog.
+func (d Dog) editableDog(ctx context.Context, userID, dogID uuid.UUID) (domain.Dog, error) {
+	dog, err := d.dogAdapter.Get(ctx, dogID)
+	if err != nil {
+		return domain.Dog{}, err
+	}
+
+	if dog.UserID != userID {
+		permitted, err := d.isPermitted(ctx, userID, domain.PermissionManageAnyDog)
+		if err != nil {
+			return domain.Dog{}, err
+		}
+
+		if !permitted {
+			return domain.Dog{}, ierr.New(ierr.PermissionDenied, "cannot change photos of a dog that isn't yours")
+		}
+	}
+
+	return dog, nil
+}
+
+// ownDog returns the dog of the user, PermissionDenied with the message if the dog is someone else's.
+func (d Dog) ownDog(ctx context.Context, userID, dogID uuid.UUID, message string) (domain.Dog, error) {
+	dog, err := d.dogAdapter.Get(ctx, dogID)
+	if err != nil {
+		return domain.Dog{}, err
+	}
+
+	if dog.UserID != userID {
+		return domain.Dog{}, ierr.New(ierr.PermissionDenied, message)
+	}
+
+	return dog, nil
+}
+
+// locate geocodes the city of the dog given without coordinates, the city is renamed as it's in the dataset.
+func (d Dog) locate(dog domain.Dog) (domain.Dog, error) {
+	if dog.Location != nil {
+		if !dog.Location.Valid() {
+			return domain.Dog{}, ierr.New(ierr.InvalidArgument, "wrong dog location")
+		}
+
+		return dog, nil
+	}
+
+	if dog.City == "" {
+		return dog, nil
+	}
+
+	city, err := d.geocoder.Geocode(dog.City)
+	if err != nil {
+		if ierr.GetCode(err) == ierr.NotFound {
+			return domain.Dog{}, ierr.WrapCode(ierr.InvalidArgument, err, "unknown city, give dog location by coordinates")
+		}
+
+		return domain.Dog{}, ierr.WrapCode(ierr.Internal, err, "geocoding city error")
+	}
+
+	dog.City = city.Name
+	dog.Location = &city.Point
+
+	return dog, nil
+}
+
+func (d Dog) requireVerifiedEmail(ctx context.Context, userID uuid.UUID) error {
+	user, err := d.userAdapter.Get(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if user.EmailVerifiedAt == nil {
+		return ierr.New(ierr.PermissionDenied, "email is not verified")
+	}
+
+	return nil
+}
+
+// isPermitted reports if role of the user is granted the permission, API key requests act as regular user.
+// The role is read from storage, so revoked roles take effect before access token expires.
+func (d Dog) isPermitted(ctx context.Context, userID uuid.UUID, permission domain.Permission) (bool, error) {
+	user, err := d.userAdapter.Get(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+
+	return domain.EffectiveRole(ctx, user.Role).Can(permission), nil
+}
+
+// cursorFits checks the list is sorted by creation time if it's paged through by cursor,
+// the cursor doesn't point anywhere in other orders.
+func cursorFits(filter domain.DogFilter, pagination domain.Pagination) error {
+	if pagination.After != nil && !filter.Sort.ByCreation() {
+		return ierr.New(ierr.InvalidArgument, "cursor pages only dogs sorted by creation time")
+	}
+
+	return nil
+}
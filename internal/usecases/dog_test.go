@@ -7,6 +7,8 @@ import (
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+	"strings"
 	"testing"
 	"time"
 )
@@ -24,7 +26,7 @@ func TestDog_List(t *testing.T) {
 		Sex:       "test sex",
 		Age:       3,
 		Breed:     "test breed",
-		Image:     "http://test-image/image.jpg",
+		Photos:    []domain.DogPhoto{{ID: uuid.New(), Images: domain.ExternalDogImages("http://test-image/image.jpg"), Primary: true}},
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
@@ -37,13 +39,16 @@ func TestDog_List(t *testing.T) {
 	}
 
 	userID := uuid.New()
+	filter := domain.DogFilter{Sex: domain.Male, Sort: domain.SortNameAsc}
 
 	type fields struct {
-		dogAdapter DogAdapter
+		dogAdapter  DogAdapter
+		userAdapter UserAdapter
 	}
 	type args struct {
 		ctx        context.Context
 		userID     uuid.UUID
+		filter     domain.DogFilter
 		pagination domain.Pagination
 	}
 
@@ -52,7 +57,7 @@ func TestDog_List(t *testing.T) {
 		fields    fields
 		args      args
 		mocksInit func()
-		want      domain.DogList
+		want      domain.DogPage
 		wantErr   bool
 	}{
 		{
@@ -63,14 +68,30 @@ func TestDog_List(t *testing.T) {
 			args: args{
 				ctx:        context.TODO(),
 				userID:     userID,
+				filter:     filter,
 				pagination: pag,
 			},
 			mocksInit: func() {
-				dogAdapterMock.EXPECT().List(gomock.Any(), gomock.Eq(userID), gomock.Eq(pag)).Return(nil, testErr)
+				dogAdapterMock.EXPECT().List(gomock.Any(), gomock.Eq(userID), gomock.Eq(filter), gomock.Eq(pag)).Return(domain.DogPage{}, testErr)
 			},
-			want:    nil,
+			want:    domain.DogPage{},
 			wantErr: true,
 		},
+		{
+			name: "cursor with not creation time order",
+			fields: fields{
+				dogAdapter: dogAdapterMock,
+			},
+			args: args{
+				ctx:        context.TODO(),
+				userID:     userID,
+				filter:     filter,
+				pagination: domain.Pagination{Page: 1, PerPage: 2, After: &domain.Cursor{CreatedAt: time.Now(), ID: uuid.New()}},
+			},
+			mocksInit: func() {},
+			want:      domain.DogPage{},
+			wantErr:   true,
+		},
 		{
 			name: "success",
 			fields: fields{
@@ -79,12 +100,13 @@ func TestDog_List(t *testing.T) {
 			args: args{
 				ctx:        context.TODO(),
 				userID:     userID,
+				filter:     filter,
 				pagination: pag,
 			},
 			mocksInit: func() {
-				dogAdapterMock.EXPECT().List(gomock.Any(), gomock.Eq(userID), gomock.Eq(pag)).Return(listDog, nil)
+				dogAdapterMock.EXPECT().List(gomock.Any(), gomock.Eq(userID), gomock.Eq(filter), gomock.Eq(pag)).Return(domain.DogPage{Dogs: listDog}, nil)
 			},
-			want:    listDog,
+			want:    domain.DogPage{Dogs: listDog},
 			wantErr: false,
 		},
 	}
@@ -92,8 +114,8 @@ func TestDog_List(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mocksInit()
 
-			d := NewDog(tt.fields.dogAdapter)
-			got, err := d.List(tt.args.ctx, tt.args.userID, tt.args.pagination)
+			d := NewDog(tt.fields.dogAdapter, tt.fields.userAdapter, nil, nil, nil, nil, dogImageMaxSize, dogMaxPhotos, dogMaxDuplicateDistance)
+			got, err := d.List(tt.args.ctx, tt.args.userID, tt.args.filter, tt.args.pagination)
 			assert.Equal(t, tt.wantErr, err != nil)
 			assert.Equal(t, tt.want, got)
 		})
@@ -102,7 +124,8 @@ func TestDog_List(t *testing.T) {
 
 func TestDog_Get(t *testing.T) {
 	type fields struct {
-		dogAdapter DogAdapter
+		dogAdapter  DogAdapter
+		userAdapter UserAdapter
 	}
 	type args struct {
 		ctx context.Context
@@ -123,7 +146,7 @@ func TestDog_Get(t *testing.T) {
 		Sex:       "test sex",
 		Age:       3,
 		Breed:     "test breed",
-		Image:     "http://test-image/image.jpg",
+		Photos:    []domain.DogPhoto{{ID: uuid.New(), Images: domain.ExternalDogImages("http://test-image/image.jpg"), Primary: true}},
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
@@ -171,7 +194,7 @@ func TestDog_Get(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mocksInit()
 
-			d := NewDog(tt.fields.dogAdapter)
+			d := NewDog(tt.fields.dogAdapter, tt.fields.userAdapter, nil, nil, nil, nil, dogImageMaxSize, dogMaxPhotos, dogMaxDuplicateDistance)
 			got, err := d.Get(tt.args.ctx, tt.args.uid)
 			assert.Equal(t, tt.wantErr, err != nil)
 			assert.Equal(t, tt.want, got)
@@ -179,6 +202,111 @@ func TestDog_Get(t *testing.T) {
 	}
 }
 
+func TestDog_Feed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	dogAdapterMock := NewMockDogAdapter(ctrl)
+
+	dogID := uuid.New()
+	userID := uuid.New()
+
+	testErr := errors.New("testing error")
+
+	dog := domain.Dog{ID: dogID, UserID: userID}
+	otherDog := domain.Dog{ID: dogID, UserID: uuid.New()}
+	candidates := domain.DogPage{Dogs: domain.DogList{{ID: uuid.New(), UserID: uuid.New()}}}
+
+	filter := domain.DogFilter{Sex: domain.Female, Sort: domain.SortCreatedDesc}
+	pag := domain.Pagination{Page: 1, PerPage: 5}
+
+	cursorPag := domain.Pagination{Page: 1, PerPage: 5, After: &domain.Cursor{CreatedAt: time.Now(), ID: uuid.New()}}
+
+	tests := []struct {
+		name       string
+		mocksInit  func()
+		filter     domain.DogFilter
+		pagination domain.Pagination
+		want       domain.DogPage
+		wantErr    bool
+		wantCode   ierr.Code
+	}{
+		{
+			name: "getting dog error",
+			mocksInit: func() {
+				dogAdapterMock.EXPECT().Get(gomock.Any(), gomock.Eq(dogID)).Return(domain.Dog{}, ierr.New(ierr.NotFound, "dog not found"))
+			},
+			filter:     filter,
+			pagination: pag,
+			wantErr:    true,
+			wantCode:   ierr.NotFound,
+		},
+		{
+			name: "feed of not your dog",
+			mocksInit: func() {
+				dogAdapterMock.EXPECT().Get(gomock.Any(), gomock.Eq(dogID)).Return(otherDog, nil)
+			},
+			filter:     filter,
+			pagination: pag,
+			wantErr:    true,
+			wantCode:   ierr.PermissionDenied,
+		},
+		{
+			name: "getting feed error",
+			mocksInit: func() {
+				dogAdapterMock.EXPECT().Get(gomock.Any(), gomock.Eq(dogID)).Return(dog, nil)
+				dogAdapterMock.EXPECT().Feed(gomock.Any(), gomock.Eq(dog), gomock.Eq(filter), gomock.Eq(pag)).Return(domain.DogPage{}, testErr)
+			},
+			filter:     filter,
+			pagination: pag,
+			wantErr:    true,
+			wantCode:   ierr.Internal,
+		},
+		{
+			name: "success",
+			mocksInit: func() {
+				dogAdapterMock.EXPECT().Get(gomock.Any(), gomock.Eq(dogID)).Return(dog, nil)
+				dogAdapterMock.EXPECT().Feed(gomock.Any(), gomock.Eq(dog), gomock.Eq(filter), gomock.Eq(pag)).Return(candidates, nil)
+			},
+			filter:     filter,
+			pagination: pag,
+			want:       candidates,
+		},
+		{
+			name: "page after cursor",
+			mocksInit: func() {
+				dogAdapterMock.EXPECT().Get(gomock.Any(), gomock.Eq(dogID)).Return(dog, nil)
+				dogAdapterMock.EXPECT().Feed(gomock.Any(), gomock.Eq(dog), gomock.Eq(filter), gomock.Eq(cursorPag)).Return(candidates, nil)
+			},
+			filter:     filter,
+			pagination: cursorPag,
+			want:       candidates,
+		},
+		{
+			name:       "cursor with not creation time order",
+			mocksInit:  func() {},
+			filter:     domain.DogFilter{Sort: domain.SortAgeAsc},
+			pagination: cursorPag,
+			wantErr:    true,
+			wantCode:   ierr.InvalidArgument,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInit()
+
+			d := NewDog(dogAdapterMock, nil, nil, nil, nil, nil, dogImageMaxSize, dogMaxPhotos, dogMaxDuplicateDistance)
+			got, err := d.Feed(context.TODO(), userID, dogID, tt.filter, tt.pagination)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Equal(t, tt.wantCode, ierr.GetCode(err))
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
 func TestDog_Matches(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	dogAdapterMock := NewMockDogAdapter(ctrl)
@@ -208,7 +336,8 @@ func TestDog_Matches(t *testing.T) {
 	//listDog := domain.DogList{dog, dog}
 
 	type fields struct {
-		dogAdapter DogAdapter
+		dogAdapter  DogAdapter
+		userAdapter UserAdapter
 	}
 	type args struct {
 		ctx        context.Context
@@ -221,7 +350,7 @@ func TestDog_Matches(t *testing.T) {
 		fields    fields
 		args      args
 		mocksInit func()
-		want      domain.DogList
+		want      domain.DogPage
 		wantErr   bool
 	}{
 		{
@@ -238,7 +367,7 @@ func TestDog_Matches(t *testing.T) {
 			mocksInit: func() {
 				dogAdapterMock.EXPECT().Get(gomock.Any(), gomock.Eq(dogID)).Return(domain.Dog{}, testErr)
 			},
-			want:    nil,
+			want:    domain.DogPage{},
 			wantErr: true,
 		},
 		{
@@ -255,7 +384,7 @@ func TestDog_Matches(t *testing.T) {
 			mocksInit: func() {
 				dogAdapterMock.EXPECT().Get(gomock.Any(), gomock.Eq(dogID)).Return(wrongDog, nil)
 			},
-			want:    nil,
+			want:    domain.DogPage{},
 			wantErr: true,
 		},
 		{
@@ -271,9 +400,9 @@ func TestDog_Matches(t *testing.T) {
 			},
 			mocksInit: func() {
 				dogAdapterMock.EXPECT().Get(gomock.Any(), gomock.Eq(dogID)).Return(goodDog, nil)
-				dogAdapterMock.EXPECT().Matches(gomock.Any(), gomock.Eq(dogID), gomock.Eq(pag)).Return(goodDogs, nil)
+				dogAdapterMock.EXPECT().Matches(gomock.Any(), gomock.Eq(dogID), gomock.Eq(pag)).Return(domain.DogPage{Dogs: goodDogs}, nil)
 			},
-			want:    goodDogs,
+			want:    domain.DogPage{Dogs: goodDogs},
 			wantErr: false,
 		},
 	}
@@ -281,7 +410,7 @@ func TestDog_Matches(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mocksInit()
 
-			d := NewDog(tt.fields.dogAdapter)
+			d := NewDog(tt.fields.dogAdapter, tt.fields.userAdapter, nil, nil, nil, nil, dogImageMaxSize, dogMaxPhotos, dogMaxDuplicateDistance)
 			got, err := d.Matches(tt.args.ctx, tt.args.userID, tt.args.dogID, tt.args.pagination)
 			assert.Equal(t, tt.wantErr, err != nil)
 			assert.Equal(t, tt.want, got)
@@ -292,6 +421,8 @@ func TestDog_Matches(t *testing.T) {
 func TestDog_Create(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	dogAdapterMock := NewMockDogAdapter(ctrl)
+	userAdapterMock := NewMockUserAdapter(ctrl)
+	geocoderMock := NewMockGeocoder(ctrl)
 
 	testError := errors.New("testing-error")
 	dogID := uuid.New()
@@ -309,8 +440,19 @@ func TestDog_Create(t *testing.T) {
 		Name:   dogName,
 	}
 
+	lviv := domain.City{Name: "Lviv", Country: "UA", Point: domain.GeoPoint{Latitude: 49.8397, Longitude: 24.0297}}
+	cityDog := domain.Dog{UserID: userID, Name: dogName, City: "lviv"}
+	locatedDog := domain.Dog{UserID: userID, Name: dogName, City: lviv.Name, Location: &lviv.Point}
+	wrongLocationDog := domain.Dog{UserID: userID, Name: dogName, Location: &domain.GeoPoint{Latitude: 91}}
+
+	verifiedAt := time.Now()
+	verifiedUser := domain.User{ID: userID, EmailVerifiedAt: &verifiedAt}
+	unverifiedUser := domain.User{ID: userID}
+
 	type fields struct {
-		dogAdapter DogAdapter
+		dogAdapter  DogAdapter
+		userAdapter UserAdapter
+		geocoder    Geocoder
 	}
 	type args struct {
 		ctx context.Context
@@ -324,15 +466,47 @@ func TestDog_Create(t *testing.T) {
 		want      domain.Dog
 		wantErr   bool
 	}{
+		{
+			name: "getting user error",
+			fields: fields{
+				dogAdapter:  dogAdapterMock,
+				userAdapter: userAdapterMock,
+			},
+			args: args{
+				dog: dog,
+			},
+			mocksInit: func() {
+				userAdapterMock.EXPECT().Get(gomock.Any(), gomock.Eq(userID)).Return(domain.User{}, testError)
+			},
+			want:    domain.Dog{},
+			wantErr: true,
+		},
+		{
+			name: "email is not verified",
+			fields: fields{
+				dogAdapter:  dogAdapterMock,
+				userAdapter: userAdapterMock,
+			},
+			args: args{
+				dog: dog,
+			},
+			mocksInit: func() {
+				userAdapterMock.EXPECT().Get(gomock.Any(), gomock.Eq(userID)).Return(unverifiedUser, nil)
+			},
+			want:    domain.Dog{},
+			wantErr: true,
+		},
 		{
 			name: "creation dog error",
 			fields: fields{
-				dogAdapter: dogAdapterMock,
+				dogAdapter:  dogAdapterMock,
+				userAdapter: userAdapterMock,
 			},
 			args: args{
 				dog: dog,
 			},
 			mocksInit: func() {
+				userAdapterMock.EXPECT().Get(gomock.Any(), gomock.Eq(userID)).Return(verifiedUser, nil)
 				dogAdapterMock.EXPECT().Create(gomock.Any(), gomock.Eq(dog)).Return(domain.Dog{}, testError)
 			},
 			want:    domain.Dog{},
@@ -341,23 +515,75 @@ func TestDog_Create(t *testing.T) {
 		{
 			name: "success",
 			fields: fields{
-				dogAdapter: dogAdapterMock,
+				dogAdapter:  dogAdapterMock,
+				userAdapter: userAdapterMock,
 			},
 			args: args{
 				dog: dog,
 			},
 			mocksInit: func() {
+				userAdapterMock.EXPECT().Get(gomock.Any(), gomock.Eq(userID)).Return(verifiedUser, nil)
 				dogAdapterMock.EXPECT().Create(gomock.Any(), gomock.Eq(dog)).Return(createdDog, nil)
 			},
 			want:    createdDog,
 			wantErr: false,
 		},
+		{
+			name: "wrong location",
+			fields: fields{
+				dogAdapter:  dogAdapterMock,
+				userAdapter: userAdapterMock,
+			},
+			args: args{
+				dog: wrongLocationDog,
+			},
+			mocksInit: func() {
+				userAdapterMock.EXPECT().Get(gomock.Any(), gomock.Eq(userID)).Return(verifiedUser, nil)
+			},
+			want:    domain.Dog{},
+			wantErr: true,
+		},
+		{
+			name: "unknown city",
+			fields: fields{
+				dogAdapter:  dogAdapterMock,
+				userAdapter: userAdapterMock,
+				geocoder:    geocoderMock,
+			},
+			args: args{
+				dog: cityDog,
+			},
+			mocksInit: func() {
+				userAdapterMock.EXPECT().Get(gomock.Any(), gomock.Eq(userID)).Return(verifiedUser, nil)
+				geocoderMock.EXPECT().Geocode(gomock.Eq("lviv")).Return(domain.City{}, ierr.New(ierr.NotFound, "city not found"))
+			},
+			want:    domain.Dog{},
+			wantErr: true,
+		},
+		{
+			name: "success with geocoded city",
+			fields: fields{
+				dogAdapter:  dogAdapterMock,
+				userAdapter: userAdapterMock,
+				geocoder:    geocoderMock,
+			},
+			args: args{
+				dog: cityDog,
+			},
+			mocksInit: func() {
+				userAdapterMock.EXPECT().Get(gomock.Any(), gomock.Eq(userID)).Return(verifiedUser, nil)
+				geocoderMock.EXPECT().Geocode(gomock.Eq("lviv")).Return(lviv, nil)
+				dogAdapterMock.EXPECT().Create(gomock.Any(), gomock.Eq(locatedDog)).Return(createdDog, nil)
+			},
+			want:    createdDog,
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mocksInit()
 
-			d := NewDog(tt.fields.dogAdapter)
+			d := NewDog(tt.fields.dogAdapter, tt.fields.userAdapter, nil, nil, nil, tt.fields.geocoder, dogImageMaxSize, dogMaxPhotos, dogMaxDuplicateDistance)
 			got, err := d.Create(tt.args.ctx, tt.args.dog)
 			assert.Equal(t, tt.wantErr, err != nil)
 			assert.Equal(t, tt.want, got)
@@ -368,6 +594,7 @@ func TestDog_Create(t *testing.T) {
 func TestDog_Update(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	dogAdapterMock := NewMockDogAdapter(ctrl)
+	userAdapterMock := NewMockUserAdapter(ctrl)
 
 	testError := errors.New("testing-error")
 	userID := uuid.New()
@@ -391,7 +618,8 @@ func TestDog_Update(t *testing.T) {
 	}
 
 	type fields struct {
-		dogAdapter DogAdapter
+		dogAdapter  DogAdapter
+		userAdapter UserAdapter
 	}
 	type args struct {
 		ctx context.Context
@@ -425,7 +653,26 @@ func TestDog_Update(t *testing.T) {
 		{
 			name: "updating not your dog error",
 			fields: fields{
-				dogAdapter: dogAdapterMock,
+				dogAdapter:  dogAdapterMock,
+				userAdapter: userAdapterMock,
+			},
+			args: args{
+				ctx: context.TODO(),
+				uid: dogID,
+				dog: dogIn,
+			},
+			mocksInit: func() {
+				dogAdapterMock.EXPECT().Get(gomock.Any(), gomock.Eq(dogID)).Return(wrongFoundDog, nil)
+				userAdapterMock.EXPECT().Get(gomock.Any(), gomock.Eq(userID)).Return(domain.User{ID: userID, Role: domain.RoleUser}, nil)
+			},
+			want:    domain.Dog{},
+			wantErr: true,
+		},
+		{
+			name: "getting user error",
+			fields: fields{
+				dogAdapter:  dogAdapterMock,
+				userAdapter: userAdapterMock,
 			},
 			args: args{
 				ctx: context.TODO(),
@@ -434,10 +681,30 @@ func TestDog_Update(t *testing.T) {
 			},
 			mocksInit: func() {
 				dogAdapterMock.EXPECT().Get(gomock.Any(), gomock.Eq(dogID)).Return(wrongFoundDog, nil)
+				userAdapterMock.EXPECT().Get(gomock.Any(), gomock.Eq(userID)).Return(domain.User{}, testError)
 			},
 			want:    domain.Dog{},
 			wantErr: true,
 		},
+		{
+			name: "moderator updates not own dog",
+			fields: fields{
+				dogAdapter:  dogAdapterMock,
+				userAdapter: userAdapterMock,
+			},
+			args: args{
+				ctx: context.TODO(),
+				uid: dogID,
+				dog: dogIn,
+			},
+			mocksInit: func() {
+				dogAdapterMock.EXPECT().Get(gomock.Any(), gomock.Eq(dogID)).Return(wrongFoundDog, nil)
+				userAdapterMock.EXPECT().Get(gomock.Any(), gomock.Eq(userID)).Return(domain.User{ID: userID, Role: domain.RoleModerator}, nil)
+				dogAdapterMock.EXPECT().Update(gomock.Any(), gomock.Eq(dogID), dogIn).Return(dogOut, nil)
+			},
+			want:    dogOut,
+			wantErr: false,
+		},
 		{
 			name: "updating dog error",
 			fields: fields{
@@ -477,7 +744,7 @@ func TestDog_Update(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mocksInit()
 
-			d := NewDog(tt.fields.dogAdapter)
+			d := NewDog(tt.fields.dogAdapter, tt.fields.userAdapter, nil, nil, nil, nil, dogImageMaxSize, dogMaxPhotos, dogMaxDuplicateDistance)
 			got, err := d.Update(tt.args.ctx, tt.args.uid, tt.args.dog)
 			assert.Equal(t, tt.wantErr, err != nil)
 			assert.Equal(t, tt.want, got)
@@ -488,6 +755,7 @@ func TestDog_Update(t *testing.T) {
 func TestDog_Delete(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	dogAdapterMock := NewMockDogAdapter(ctrl)
+	userAdapterMock := NewMockUserAdapter(ctrl)
 
 	testError := errors.New("testing-error")
 	dogID := uuid.New()
@@ -504,7 +772,8 @@ func TestDog_Delete(t *testing.T) {
 	}
 
 	type fields struct {
-		dogAdapter DogAdapter
+		dogAdapter  DogAdapter
+		userAdapter UserAdapter
 	}
 	type args struct {
 		ctx     context.Context
@@ -536,7 +805,8 @@ func TestDog_Delete(t *testing.T) {
 		{
 			name: "deleting not your dog",
 			fields: fields{
-				dogAdapter: dogAdapterMock,
+				dogAdapter:  dogAdapterMock,
+				userAdapter: userAdapterMock,
 			},
 			args: args{
 				ctx:     context.TODO(),
@@ -545,6 +815,42 @@ func TestDog_Delete(t *testing.T) {
 			},
 			mocksInit: func() {
 				dogAdapterMock.EXPECT().Get(gomock.Any(), gomock.Eq(dogID)).Return(wrongDogOut, nil)
+				userAdapterMock.EXPECT().Get(gomock.Any(), gomock.Eq(userID)).Return(domain.User{ID: userID, Role: domain.RoleUser}, nil)
+			},
+			wantErr: true,
+		},
+		{
+			name: "moderator deletes not own dog",
+			fields: fields{
+				dogAdapter:  dogAdapterMock,
+				userAdapter: userAdapterMock,
+			},
+			args: args{
+				ctx:     context.TODO(),
+				dogUid:  dogID,
+				userUid: userID,
+			},
+			mocksInit: func() {
+				dogAdapterMock.EXPECT().Get(gomock.Any(), gomock.Eq(dogID)).Return(wrongDogOut, nil)
+				userAdapterMock.EXPECT().Get(gomock.Any(), gomock.Eq(userID)).Return(domain.User{ID: userID, Role: domain.RoleModerator}, nil)
+				dogAdapterMock.EXPECT().Delete(gomock.Any(), gomock.Eq(dogID)).Return(nil, nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "moderator's api key can't delete not own dog",
+			fields: fields{
+				dogAdapter:  dogAdapterMock,
+				userAdapter: userAdapterMock,
+			},
+			args: args{
+				ctx:     domain.ContextWithAPIKey(context.TODO()),
+				dogUid:  dogID,
+				userUid: userID,
+			},
+			mocksInit: func() {
+				dogAdapterMock.EXPECT().Get(gomock.Any(), gomock.Eq(dogID)).Return(wrongDogOut, nil)
+				userAdapterMock.EXPECT().Get(gomock.Any(), gomock.Eq(userID)).Return(domain.User{ID: userID, Role: domain.RoleModerator}, nil)
 			},
 			wantErr: true,
 		},
@@ -560,7 +866,7 @@ func TestDog_Delete(t *testing.T) {
 			},
 			mocksInit: func() {
 				dogAdapterMock.EXPECT().Get(gomock.Any(), gomock.Eq(dogID)).Return(dogOut, nil)
-				dogAdapterMock.EXPECT().Delete(gomock.Any(), gomock.Eq(dogID)).Return(testError)
+				dogAdapterMock.EXPECT().Delete(gomock.Any(), gomock.Eq(dogID)).Return(nil, testError)
 			},
 			wantErr: true,
 		},
@@ -576,7 +882,7 @@ func TestDog_Delete(t *testing.T) {
 			},
 			mocksInit: func() {
 				dogAdapterMock.EXPECT().Get(gomock.Any(), gomock.Eq(dogID)).Return(dogOut, nil)
-				dogAdapterMock.EXPECT().Delete(gomock.Any(), gomock.Eq(dogID)).Return(nil)
+				dogAdapterMock.EXPECT().Delete(gomock.Any(), gomock.Eq(dogID)).Return(nil, nil)
 			},
 			wantErr: false,
 		},
@@ -585,7 +891,7 @@ func TestDog_Delete(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mocksInit()
 
-			d := NewDog(tt.fields.dogAdapter)
+			d := NewDog(tt.fields.dogAdapter, tt.fields.userAdapter, nil, nil, nil, nil, dogImageMaxSize, dogMaxPhotos, dogMaxDuplicateDistance)
 			err := d.Delete(tt.args.ctx, tt.args.dogUid, tt.args.userUid)
 			assert.Equal(t, tt.wantErr, err != nil)
 		})
@@ -595,6 +901,7 @@ func TestDog_Delete(t *testing.T) {
 func TestDog_AddReaction(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	dogAdapterMock := NewMockDogAdapter(ctrl)
+	userAdapterMock := NewMockUserAdapter(ctrl)
 
 	testError := errors.New("testing-error")
 
@@ -631,8 +938,13 @@ func TestDog_AddReaction(t *testing.T) {
 		UserID: userID,
 	}
 
+	verifiedAt := time.Now()
+	verifiedUser := domain.User{ID: userID, EmailVerifiedAt: &verifiedAt}
+	unverifiedUser := domain.User{ID: userID}
+
 	type fields struct {
-		dogAdapter DogAdapter
+		dogAdapter  DogAdapter
+		userAdapter UserAdapter
 	}
 	type args struct {
 		ctx      context.Context
@@ -649,7 +961,8 @@ func TestDog_AddReaction(t *testing.T) {
 		{
 			name: "like to itself",
 			fields: fields{
-				dogAdapter: dogAdapterMock,
+				dogAdapter:  dogAdapterMock,
+				userAdapter: userAdapterMock,
 			},
 			args: args{
 				ctx:      context.TODO(),
@@ -662,7 +975,8 @@ func TestDog_AddReaction(t *testing.T) {
 		{
 			name: "getting dog error",
 			fields: fields{
-				dogAdapter: dogAdapterMock,
+				dogAdapter:  dogAdapterMock,
+				userAdapter: userAdapterMock,
 			},
 			args: args{
 				ctx:      context.TODO(),
@@ -677,7 +991,8 @@ func TestDog_AddReaction(t *testing.T) {
 		{
 			name: "owner error",
 			fields: fields{
-				dogAdapter: dogAdapterMock,
+				dogAdapter:  dogAdapterMock,
+				userAdapter: userAdapterMock,
 			},
 			args: args{
 				ctx:      context.TODO(),
@@ -689,10 +1004,28 @@ func TestDog_AddReaction(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "email is not verified",
+			fields: fields{
+				dogAdapter:  dogAdapterMock,
+				userAdapter: userAdapterMock,
+			},
+			args: args{
+				ctx:      context.TODO(),
+				uid:      userID,
+				reaction: correctReaction,
+			},
+			mocksInit: func() {
+				dogAdapterMock.EXPECT().Get(gomock.Any(), gomock.Eq(correctReaction.Liker)).Return(correctDog, nil)
+				userAdapterMock.EXPECT().Get(gomock.Any(), gomock.Eq(userID)).Return(unverifiedUser, nil)
+			},
+			wantErr: true,
+		},
 		{
 			name: "error adding reaction",
 			fields: fields{
-				dogAdapter: dogAdapterMock,
+				dogAdapter:  dogAdapterMock,
+				userAdapter: userAdapterMock,
 			},
 			args: args{
 				ctx:      context.TODO(),
@@ -701,6 +1034,7 @@ func TestDog_AddReaction(t *testing.T) {
 			},
 			mocksInit: func() {
 				dogAdapterMock.EXPECT().Get(gomock.Any(), gomock.Eq(correctReaction.Liker)).Return(correctDog, nil)
+				userAdapterMock.EXPECT().Get(gomock.Any(), gomock.Eq(userID)).Return(verifiedUser, nil)
 				dogAdapterMock.EXPECT().AddReaction(gomock.Any(), gomock.Eq(correctReaction)).Return(testError)
 			},
 			wantErr: true,
@@ -708,7 +1042,8 @@ func TestDog_AddReaction(t *testing.T) {
 		{
 			name: "success",
 			fields: fields{
-				dogAdapter: dogAdapterMock,
+				dogAdapter:  dogAdapterMock,
+				userAdapter: userAdapterMock,
 			},
 			args: args{
 				ctx:      context.TODO(),
@@ -717,6 +1052,7 @@ func TestDog_AddReaction(t *testing.T) {
 			},
 			mocksInit: func() {
 				dogAdapterMock.EXPECT().Get(gomock.Any(), gomock.Eq(correctReaction.Liker)).Return(correctDog, nil)
+				userAdapterMock.EXPECT().Get(gomock.Any(), gomock.Eq(userID)).Return(verifiedUser, nil)
 				dogAdapterMock.EXPECT().AddReaction(gomock.Any(), gomock.Eq(correctReaction)).Return(nil)
 			},
 			wantErr: false,
@@ -726,9 +1062,394 @@ func TestDog_AddReaction(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.mocksInit()
 
-			d := NewDog(tt.fields.dogAdapter)
+			d := NewDog(tt.fields.dogAdapter, tt.fields.userAdapter, nil, nil, nil, nil, dogImageMaxSize, dogMaxPhotos, dogMaxDuplicateDistance)
 			err := d.AddReaction(tt.args.ctx, tt.args.uid, tt.args.reaction)
 			assert.Equal(t, tt.wantErr, err != nil)
 		})
 	}
 }
+
+const (
+	dogImageMaxSize         = 1024
+	dogMaxPhotos            = 2
+	dogMaxDuplicateDistance = 10
+)
+
+type imageKeyMatcher struct {
+	dogID     uuid.UUID
+	rendition domain.ImageRendition
+}
+
+func (m imageKeyMatcher) Matches(x interface{}) bool {
+	key, ok := x.(string)
+	if !ok {
+		return false
+	}
+
+	return strings.HasPrefix(key, "dogs/"+m.dogID.String()+"/") && strings.HasSuffix(key, "/"+m.rendition.String()+".jpg")
+}
+
+func (m imageKeyMatcher) String() string {
+	return "is " + m.rendition.String() + " key of dog " + m.dogID.String()
+}
+
+func TestDog_UploadPhoto(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	dogAdapterMock := NewMockDogAdapter(ctrl)
+	userAdapterMock := NewMockUserAdapter(ctrl)
+	flaggedPhotoAdapterMock := NewMockFlaggedPhotoAdapter(ctrl)
+	blobStoreMock := NewMockBlobStore(ctrl)
+	imageProcessorMock := NewMockImageProcessor(ctrl)
+
+	userID := uuid.New()
+	moderatorID := uuid.New()
+	dog := domain.Dog{ID: uuid.New(), UserID: userID, Name: "Spike"}
+	fullDog := domain.Dog{
+		ID:     dog.ID,
+		UserID: userID,
+		Name:   "Spike",
+		Photos: []domain.DogPhoto{{ID: uuid.New(), Primary: true}, {ID: uuid.New()}},
+	}
+	image := domain.ImageUpload{ContentType: "image/png", Size: 4, Content: strings.NewReader("png!")}
+	images := domain.DogImages{
+		Thumb: "http://localhost:8080/media/dogs/thumb.jpg",
+		Full:  "http://localhost:8080/media/dogs/full.jpg",
+	}
+	hash := domain.ImageHash(0xF0F0F0F0F0F0F0F0)
+	processed := domain.ProcessedUpload{
+		Renditions: []domain.ProcessedImage{
+			{Rendition: domain.RenditionThumb, ContentType: "image/jpeg", Extension: ".jpg", Content: []byte("thumb")},
+			{Rendition: domain.RenditionFull, ContentType: "image/jpeg", Extension: ".jpg", Content: []byte("full")},
+		},
+		Hash: hash,
+	}
+	notSimilar := ierr.New(ierr.NotFound, "similar photo not found")
+	similar := domain.SimilarPhoto{PhotoID: uuid.New(), DogID: uuid.New(), UserID: uuid.New(), Distance: 3}
+	photo := domain.DogPhoto{Images: images, Hash: &hash}
+	flaggedID := uuid.New()
+	thumbKey := imageKeyMatcher{dogID: dog.ID, rendition: domain.RenditionThumb}
+	fullKey := imageKeyMatcher{dogID: dog.ID, rendition: domain.RenditionFull}
+
+	tests := []struct {
+		name      string
+		userID    uuid.UUID
+		image     domain.ImageUpload
+		mocksInit func()
+		want      domain.PhotoUpload
+		wantCode  ierr.Code
+		wantErr   bool
+	}{
+		{
+			name:      "not an image",
+			userID:    userID,
+			image:     domain.ImageUpload{ContentType: "application/pdf", Size: 4, Content: strings.NewReader("pdf!")},
+			mocksInit: func() {},
+			wantCode:  ierr.InvalidArgument,
+			wantErr:   true,
+		},
+		{
+			name:      "too large",
+			userID:    userID,
+			image:     domain.ImageUpload{ContentType: "image/png", Size: dogImageMaxSize + 1, Content: strings.NewReader("png!")},
+			mocksInit: func() {},
+			wantCode:  ierr.InvalidArgument,
+			wantErr:   true,
+		},
+		{
+			name:   "not your dog",
+			userID: uuid.New(),
+			image:  image,
+			mocksInit: func() {
+				dogAdapterMock.EXPECT().Get(gomock.Any(), dog.ID).Return(dog, nil)
+				userAdapterMock.EXPECT().Get(gomock.Any(), gomock.Any()).Return(domain.User{Role: domain.RoleUser}, nil)
+			},
+			wantCode: ierr.PermissionDenied,
+			wantErr:  true,
+		},
+		{
+			name:   "too many photos",
+			userID: userID,
+			image:  image,
+			mocksInit: func() {
+				dogAdapterMock.EXPECT().Get(gomock.Any(), dog.ID).Return(fullDog, nil)
+			},
+			wantCode: ierr.InvalidArgument,
+			wantErr:  true,
+		},
+		{
+			name:   "undecodable image",
+			userID: userID,
+			image:  image,
+			mocksInit: func() {
+				dogAdapterMock.EXPECT().Get(gomock.Any(), dog.ID).Return(dog, nil)
+				imageProcessorMock.EXPECT().Process(image.Content).
+					Return(domain.ProcessedUpload{}, ierr.WrapCode(ierr.InvalidArgument, errors.New("testing error"), "image can't be decoded"))
+			},
+			wantCode: ierr.InvalidArgument,
+			wantErr:  true,
+		},
+		{
+			name:   "storing rendition error removes stored ones",
+			userID: userID,
+			image:  image,
+			mocksInit: func() {
+				dogAdapterMock.EXPECT().Get(gomock.Any(), dog.ID).Return(dog, nil)
+				imageProcessorMock.EXPECT().Process(image.Content).Return(processed, nil)
+				dogAdapterMock.EXPECT().SimilarPhoto(gomock.Any(), hash, userID, dogMaxDuplicateDistance).Return(domain.SimilarPhoto{}, notSimilar)
+				blobStoreMock.EXPECT().Put(gomock.Any(), thumbKey, "image/jpeg", gomock.Any()).Return(images.Thumb, nil)
+				blobStoreMock.EXPECT().Put(gomock.Any(), fullKey, "image/jpeg", gomock.Any()).Return("", errors.New("testing error"))
+				blobStoreMock.EXPECT().Delete(gomock.Any(), thumbKey).Return(nil)
+			},
+			wantCode: ierr.Internal,
+			wantErr:  true,
+		},
+		{
+			name:   "adding photo error removes stored renditions",
+			userID: userID,
+			image:  image,
+			mocksInit: func() {
+				dogAdapterMock.EXPECT().Get(gomock.Any(), dog.ID).Return(dog, nil)
+				imageProcessorMock.EXPECT().Process(image.Content).Return(processed, nil)
+				dogAdapterMock.EXPECT().SimilarPhoto(gomock.Any(), hash, userID, dogMaxDuplicateDistance).Return(domain.SimilarPhoto{}, notSimilar)
+				blobStoreMock.EXPECT().Put(gomock.Any(), thumbKey, "image/jpeg", gomock.Any()).Return(images.Thumb, nil)
+				blobStoreMock.EXPECT().Put(gomock.Any(), fullKey, "image/jpeg", gomock.Any()).Return(images.Full, nil)
+				dogAdapterMock.EXPECT().AddPhoto(gomock.Any(), dog.ID, photo, dogMaxPhotos).
+					Return(domain.Dog{}, ierr.WrapCode(ierr.Internal, errors.New("testing error"), "adding photo error"))
+				blobStoreMock.EXPECT().Delete(gomock.Any(), thumbKey).Return(nil)
+				blobStoreMock.EXPECT().Delete(gomock.Any(), fullKey).Return(nil)
+			},
+			wantCode: ierr.Internal,
+			wantErr:  true,
+		},
+		{
+			name:   "success",
+			userID: userID,
+			image:  image,
+			mocksInit: func() {
+				dogAdapterMock.EXPECT().Get(gomock.Any(), dog.ID).Return(dog, nil)
+				imageProcessorMock.EXPECT().Process(image.Content).Return(processed, nil)
+				dogAdapterMock.EXPECT().SimilarPhoto(gomock.Any(), hash, userID, dogMaxDuplicateDistance).Return(domain.SimilarPhoto{}, notSimilar)
+				blobStoreMock.EXPECT().Put(gomock.Any(), thumbKey, "image/jpeg", gomock.Any()).Return(images.Thumb, nil)
+				blobStoreMock.EXPECT().Put(gomock.Any(), fullKey, "image/jpeg", gomock.Any()).Return(images.Full, nil)
+				dogAdapterMock.EXPECT().AddPhoto(gomock.Any(), dog.ID, photo, dogMaxPhotos).Return(domain.Dog{ID: dog.ID, Photos: []domain.DogPhoto{{Images: images, Primary: true}}}, nil)
+			},
+			want:    domain.PhotoUpload{Dog: domain.Dog{ID: dog.ID, Photos: []domain.DogPhoto{{Images: images, Primary: true}}}},
+			wantErr: false,
+		},
+		{
+			name:   "searching similar photo error",
+			userID: userID,
+			image:  image,
+			mocksInit: func() {
+				dogAdapterMock.EXPECT().Get(gomock.Any(), dog.ID).Return(dog, nil)
+				imageProcessorMock.EXPECT().Process(image.Content).Return(processed, nil)
+				dogAdapterMock.EXPECT().SimilarPhoto(gomock.Any(), hash, userID, dogMaxDuplicateDistance).
+					Return(domain.SimilarPhoto{}, ierr.WrapCode(ierr.Internal, errors.New("testing error"), "searching similar photo error"))
+			},
+			wantCode: ierr.Internal,
+			wantErr:  true,
+		},
+		{
+			name:   "photo like another user's one is held for moderation",
+			userID: userID,
+			image:  image,
+			mocksInit: func() {
+				dogAdapterMock.EXPECT().Get(gomock.Any(), dog.ID).Return(dog, nil)
+				imageProcessorMock.EXPECT().Process(image.Content).Return(processed, nil)
+				dogAdapterMock.EXPECT().SimilarPhoto(gomock.Any(), hash, userID, dogMaxDuplicateDistance).Return(similar, nil)
+				blobStoreMock.EXPECT().Put(gomock.Any(), thumbKey, "image/jpeg", gomock.Any()).Return(images.Thumb, nil)
+				blobStoreMock.EXPECT().Put(gomock.Any(), fullKey, "image/jpeg", gomock.Any()).Return(images.Full, nil)
+				flaggedPhotoAdapterMock.EXPECT().Create(gomock.Any(), domain.FlaggedPhoto{
+					DogID:          dog.ID,
+					Images:         images,
+					Hash:           hash,
+					MatchedPhotoID: &similar.PhotoID,
+					MatchedDogID:   &similar.DogID,
+					Distance:       similar.Distance,
+				}).Return(domain.FlaggedPhoto{ID: flaggedID, Status: domain.ReviewPending}, nil)
+			},
+			want:    domain.PhotoUpload{Flagged: &domain.FlaggedPhoto{ID: flaggedID, Status: domain.ReviewPending}},
+			wantErr: false,
+		},
+		{
+			name:   "moderator uploading to user's dog is compared with photos of other users",
+			userID: moderatorID,
+			image:  image,
+			mocksInit: func() {
+				dogAdapterMock.EXPECT().Get(gomock.Any(), dog.ID).Return(dog, nil)
+				userAdapterMock.EXPECT().Get(gomock.Any(), moderatorID).Return(domain.User{Role: domain.RoleModerator}, nil)
+				imageProcessorMock.EXPECT().Process(image.Content).Return(processed, nil)
+				dogAdapterMock.EXPECT().SimilarPhoto(gomock.Any(), hash, userID, dogMaxDuplicateDistance).Return(domain.SimilarPhoto{}, notSimilar)
+				blobStoreMock.EXPECT().Put(gomock.Any(), thumbKey, "image/jpeg", gomock.Any()).Return(images.Thumb, nil)
+				blobStoreMock.EXPECT().Put(gomock.Any(), fullKey, "image/jpeg", gomock.Any()).Return(images.Full, nil)
+				dogAdapterMock.EXPECT().AddPhoto(gomock.Any(), dog.ID, photo, dogMaxPhotos).Return(domain.Dog{ID: dog.ID}, nil)
+			},
+			want:    domain.PhotoUpload{Dog: domain.Dog{ID: dog.ID}},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInit()
+
+			d := NewDog(
+				dogAdapterMock,
+				userAdapterMock,
+				flaggedPhotoAdapterMock,
+				blobStoreMock,
+				imageProcessorMock,
+				nil,
+				dogImageMaxSize,
+				dogMaxPhotos,
+				dogMaxDuplicateDistance,
+			)
+			got, err := d.UploadPhoto(context.TODO(), tt.userID, dog.ID, tt.image)
+			assert.Equal(t, tt.wantErr, err != nil)
+			if tt.wantErr {
+				assert.Equal(t, tt.wantCode, ierr.GetCode(err))
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDog_SetPrimaryPhoto(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	dogAdapterMock := NewMockDogAdapter(ctrl)
+	userAdapterMock := NewMockUserAdapter(ctrl)
+
+	userID := uuid.New()
+	photoID := uuid.New()
+	dog := domain.Dog{ID: uuid.New(), UserID: userID, Name: "Spike", Photos: []domain.DogPhoto{{ID: photoID}}}
+	primary := domain.Dog{ID: dog.ID, UserID: userID, Name: "Spike", Photos: []domain.DogPhoto{{ID: photoID, Primary: true}}}
+
+	tests := []struct {
+		name      string
+		userID    uuid.UUID
+		mocksInit func()
+		want      domain.Dog
+		wantCode  ierr.Code
+		wantErr   bool
+	}{
+		{
+			name:   "not your dog",
+			userID: uuid.New(),
+			mocksInit: func() {
+				dogAdapterMock.EXPECT().Get(gomock.Any(), dog.ID).Return(dog, nil)
+				userAdapterMock.EXPECT().Get(gomock.Any(), gomock.Any()).Return(domain.User{Role: domain.RoleUser}, nil)
+			},
+			wantCode: ierr.PermissionDenied,
+			wantErr:  true,
+		},
+		{
+			name:   "photo not found",
+			userID: userID,
+			mocksInit: func() {
+				dogAdapterMock.EXPECT().Get(gomock.Any(), dog.ID).Return(dog, nil)
+				dogAdapterMock.EXPECT().SetPrimaryPhoto(gomock.Any(), dog.ID, photoID).Return(domain.Dog{}, ierr.New(ierr.NotFound, "photo not found"))
+			},
+			wantCode: ierr.NotFound,
+			wantErr:  true,
+		},
+		{
+			name:   "success",
+			userID: userID,
+			mocksInit: func() {
+				dogAdapterMock.EXPECT().Get(gomock.Any(), dog.ID).Return(dog, nil)
+				dogAdapterMock.EXPECT().SetPrimaryPhoto(gomock.Any(), dog.ID, photoID).Return(primary, nil)
+			},
+			want:    primary,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInit()
+
+			d := NewDog(dogAdapterMock, userAdapterMock, nil, nil, nil, nil, dogImageMaxSize, dogMaxPhotos, dogMaxDuplicateDistance)
+			got, err := d.SetPrimaryPhoto(context.TODO(), tt.userID, dog.ID, photoID)
+			assert.Equal(t, tt.wantErr, err != nil)
+			if tt.wantErr {
+				assert.Equal(t, tt.wantCode, ierr.GetCode(err))
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDog_UpdatePreferences(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	dogAdapterMock := NewMockDogAdapter(ctrl)
+
+	userID := uuid.New()
+	dogID := uuid.New()
+	dog := domain.Dog{ID: dogID, UserID: userID, Location: &domain.GeoPoint{Latitude: 49.8397, Longitude: 24.0297}}
+	dogWithoutLocation := domain.Dog{ID: dogID, UserID: userID}
+
+	one, five, ten := uint(1), uint(5), uint(10)
+	prefs := domain.DogPreferences{Sex: domain.Female, MinAge: &one, MaxAge: &five, MaxDistanceKm: &ten}
+
+	tests := []struct {
+		name      string
+		prefs     domain.DogPreferences
+		mocksInit func()
+		want      domain.DogPreferences
+		wantErr   bool
+		wantCode  ierr.Code
+	}{
+		{
+			name:  "not your dog",
+			prefs: prefs,
+			mocksInit: func() {
+				dogAdapterMock.EXPECT().Get(gomock.Any(), gomock.Eq(dogID)).Return(domain.Dog{ID: dogID, UserID: uuid.New()}, nil)
+			},
+			wantErr:  true,
+			wantCode: ierr.PermissionDenied,
+		},
+		{
+			name:  "min age greater than max age",
+			prefs: domain.DogPreferences{MinAge: &five, MaxAge: &one},
+			mocksInit: func() {
+				dogAdapterMock.EXPECT().Get(gomock.Any(), gomock.Eq(dogID)).Return(dog, nil)
+			},
+			wantErr:  true,
+			wantCode: ierr.InvalidArgument,
+		},
+		{
+			name:  "max distance of dog without location",
+			prefs: prefs,
+			mocksInit: func() {
+				dogAdapterMock.EXPECT().Get(gomock.Any(), gomock.Eq(dogID)).Return(dogWithoutLocation, nil)
+			},
+			wantErr:  true,
+			wantCode: ierr.InvalidArgument,
+		},
+		{
+			name:  "success",
+			prefs: prefs,
+			mocksInit: func() {
+				dogAdapterMock.EXPECT().Get(gomock.Any(), gomock.Eq(dogID)).Return(dog, nil)
+				dogAdapterMock.EXPECT().SetPreferences(gomock.Any(), gomock.Eq(dogID), gomock.Eq(prefs)).Return(prefs, nil)
+			},
+			want: prefs,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInit()
+
+			d := NewDog(dogAdapterMock, nil, nil, nil, nil, nil, dogImageMaxSize, dogMaxPhotos, dogMaxDuplicateDistance)
+			got, err := d.UpdatePreferences(context.TODO(), userID, dogID, tt.prefs)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Equal(t, tt.wantCode, ierr.GetCode(err))
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+)
+
+const emailVerificationPurpose = "email-verification"
+
+type EmailVerification struct {
+	userAdapter     UserAdapter
+	linkSigner      LinkSigner
+	mailer          Mailer
+	verificationURL string
+	linkTTL         time.Duration
+}
+
+// NewEmailVerification constructor. Verification token is appended to verificationURL as token query parameter.
+func NewEmailVerification(
+	userAdapter UserAdapter,
+	linkSigner LinkSigner,
+	mailer Mailer,
+	verificationURL string,
+	linkTTL time.Duration,
+) *EmailVerification {
+	return &EmailVerification{
+		userAdapter:     userAdapter,
+		linkSigner:      linkSigner,
+		mailer:          mailer,
+		verificationURL: verificationURL,
+		linkTTL:         linkTTL,
+	}
+}
+
+// SendVerification sends signed expiring verification link to the email.
+func (v EmailVerification) SendVerification(ctx context.Context, email string) error {
+	link, err := linkWithToken(v.verificationURL, v.linkSigner.Sign(emailVerificationPurpose, email, v.linkTTL))
+	if err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf(
+		"Please confirm your email address by following the link:\n\n%s\n\nThe link expires in %s.",
+		link, v.linkTTL,
+	)
+
+	if err := v.mailer.Send(ctx, email, "Confirm your email address", body); err != nil {
+		return ierr.WrapCode(ierr.Internal, err, "sending verification email error")
+	}
+
+	return nil
+}
+
+// Resend sends verification link again. Unknown and already verified emails are silently ignored,
+// so the endpoint can't be used to find out which emails are registered.
+func (v EmailVerification) Resend(ctx context.Context, email string) error {
+	user, err := v.userAdapter.GetByEmail(ctx, email)
+	if err != nil {
+		if ierr.GetCode(err) == ierr.NotFound {
+			return nil
+		}
+
+		return err
+	}
+
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	return v.SendVerification(ctx, user.Email)
+}
+
+// Verify marks email from the verification link token as verified.
+func (v EmailVerification) Verify(ctx context.Context, token string) error {
+	email, err := v.linkSigner.Verify(emailVerificationPurpose, token)
+	if err != nil {
+		return ierr.WrapCode(ierr.InvalidArgument, err, "invalid verification link")
+	}
+
+	user, err := v.userAdapter.GetByEmail(ctx, email)
+	if err != nil {
+		if ierr.GetCode(err) == ierr.NotFound {
+			return ierr.WrapCode(ierr.InvalidArgument, err, "invalid verification link")
+		}
+
+		return err
+	}
+
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+
+	return v.userAdapter.MarkEmailVerified(ctx, user.ID)
+}
@@ -0,0 +1,219 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+)
+
+const (
+	verificationURL     = "http://localhost:8080/api/auth/verify-email"
+	verificationLinkTTL = time.Hour
+)
+
+// verificationLinkMatcher matches email body containing verification link with the token.
+type verificationLinkMatcher struct {
+	token string
+}
+
+func (m verificationLinkMatcher) Matches(x interface{}) bool {
+	body, ok := x.(string)
+	if !ok {
+		return false
+	}
+
+	return strings.Contains(body, verificationURL+"?token="+m.token)
+}
+
+func (m verificationLinkMatcher) String() string {
+	return "contains verification link with token " + m.token
+}
+
+func TestEmailVerification_SendVerification(t *testing.T) {
+	controller := gomock.NewController(t)
+	linkSignerMock := NewMockLinkSigner(controller)
+	mailerMock := NewMockMailer(controller)
+
+	email := "test@test.com"
+	token := "signed-token"
+	testingError := errors.New("testing-error")
+
+	tests := []struct {
+		name      string
+		mocksInit func()
+		wantErr   bool
+	}{
+		{
+			name: "sending email error",
+			mocksInit: func() {
+				linkSignerMock.EXPECT().Sign(emailVerificationPurpose, email, verificationLinkTTL).Return(token)
+				mailerMock.EXPECT().Send(gomock.Any(), email, gomock.Any(), verificationLinkMatcher{token: token}).Return(testingError)
+			},
+			wantErr: true,
+		},
+		{
+			name: "success",
+			mocksInit: func() {
+				linkSignerMock.EXPECT().Sign(emailVerificationPurpose, email, verificationLinkTTL).Return(token)
+				mailerMock.EXPECT().Send(gomock.Any(), email, gomock.Any(), verificationLinkMatcher{token: token}).Return(nil)
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInit()
+
+			v := NewEmailVerification(nil, linkSignerMock, mailerMock, verificationURL, verificationLinkTTL)
+			err := v.SendVerification(context.TODO(), email)
+			assert.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
+
+func TestEmailVerification_Resend(t *testing.T) {
+	controller := gomock.NewController(t)
+	userAdapterMock := NewMockUserAdapter(controller)
+	linkSignerMock := NewMockLinkSigner(controller)
+	mailerMock := NewMockMailer(controller)
+
+	email := "test@test.com"
+	token := "signed-token"
+	testingError := errors.New("testing-error")
+	verifiedAt := time.Now()
+
+	tests := []struct {
+		name      string
+		mocksInit func()
+		wantErr   bool
+	}{
+		{
+			name: "getting user error",
+			mocksInit: func() {
+				userAdapterMock.EXPECT().GetByEmail(gomock.Any(), email).Return(domain.User{}, testingError)
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown email is ignored",
+			mocksInit: func() {
+				userAdapterMock.EXPECT().GetByEmail(gomock.Any(), email).
+					Return(domain.User{}, ierr.New(ierr.NotFound, "user not found"))
+			},
+			wantErr: false,
+		},
+		{
+			name: "already verified email is ignored",
+			mocksInit: func() {
+				userAdapterMock.EXPECT().GetByEmail(gomock.Any(), email).
+					Return(domain.User{Email: email, EmailVerifiedAt: &verifiedAt}, nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "success",
+			mocksInit: func() {
+				userAdapterMock.EXPECT().GetByEmail(gomock.Any(), email).Return(domain.User{Email: email}, nil)
+				linkSignerMock.EXPECT().Sign(emailVerificationPurpose, email, verificationLinkTTL).Return(token)
+				mailerMock.EXPECT().Send(gomock.Any(), email, gomock.Any(), verificationLinkMatcher{token: token}).Return(nil)
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInit()
+
+			v := NewEmailVerification(userAdapterMock, linkSignerMock, mailerMock, verificationURL, verificationLinkTTL)
+			err := v.Resend(context.TODO(), email)
+			assert.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
+
+func TestEmailVerification_Verify(t *testing.T) {
+	controller := gomock.NewController(t)
+	userAdapterMock := NewMockUserAdapter(controller)
+	linkSignerMock := NewMockLinkSigner(controller)
+
+	email := "test@test.com"
+	token := "signed-token"
+	userID := uuid.New()
+	testingError := errors.New("testing-error")
+	verifiedAt := time.Now()
+
+	tests := []struct {
+		name      string
+		mocksInit func()
+		wantCode  ierr.Code
+		wantErr   bool
+	}{
+		{
+			name: "invalid token",
+			mocksInit: func() {
+				linkSignerMock.EXPECT().Verify(emailVerificationPurpose, token).Return("", testingError)
+			},
+			wantCode: ierr.InvalidArgument,
+			wantErr:  true,
+		},
+		{
+			name: "user not found",
+			mocksInit: func() {
+				linkSignerMock.EXPECT().Verify(emailVerificationPurpose, token).Return(email, nil)
+				userAdapterMock.EXPECT().GetByEmail(gomock.Any(), email).
+					Return(domain.User{}, ierr.New(ierr.NotFound, "user not found"))
+			},
+			wantCode: ierr.InvalidArgument,
+			wantErr:  true,
+		},
+		{
+			name: "already verified",
+			mocksInit: func() {
+				linkSignerMock.EXPECT().Verify(emailVerificationPurpose, token).Return(email, nil)
+				userAdapterMock.EXPECT().GetByEmail(gomock.Any(), email).
+					Return(domain.User{ID: userID, EmailVerifiedAt: &verifiedAt}, nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "marking email verified error",
+			mocksInit: func() {
+				linkSignerMock.EXPECT().Verify(emailVerificationPurpose, token).Return(email, nil)
+				userAdapterMock.EXPECT().GetByEmail(gomock.Any(), email).Return(domain.User{ID: userID}, nil)
+				userAdapterMock.EXPECT().MarkEmailVerified(gomock.Any(), userID).
+					Return(ierr.WrapCode(ierr.Internal, testingError, "execution update query error"))
+			},
+			wantCode: ierr.Internal,
+			wantErr:  true,
+		},
+		{
+			name: "success",
+			mocksInit: func() {
+				linkSignerMock.EXPECT().Verify(emailVerificationPurpose, token).Return(email, nil)
+				userAdapterMock.EXPECT().GetByEmail(gomock.Any(), email).Return(domain.User{ID: userID}, nil)
+				userAdapterMock.EXPECT().MarkEmailVerified(gomock.Any(), userID).Return(nil)
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInit()
+
+			v := NewEmailVerification(userAdapterMock, linkSignerMock, nil, verificationURL, verificationLinkTTL)
+			err := v.Verify(context.TODO(), token)
+			assert.Equal(t, tt.wantErr, err != nil)
+			if tt.wantErr {
+				assert.Equal(t, tt.wantCode, ierr.GetCode(err))
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+package usecases
+
+import (
+	"net/url"
+
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+)
+
+// linkWithToken appends token to the base url of the links sent to users as token query parameter.
+func linkWithToken(base, token string) (string, error) {
+	link, err := url.Parse(base)
+	if err != nil {
+		return "", ierr.WrapCode(ierr.Internal, err, "parsing link url error")
+	}
+
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+
+	return link.String(), nil
+}
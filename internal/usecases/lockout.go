@@ -0,0 +1,136 @@
+package usecases
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+)
+
+const (
+	emailAttemptsKeyPrefix = "email:"
+	ipAttemptsKeyPrefix    = "ip:"
+)
+
+// LockoutPolicy defines how many failed sign-in attempts are allowed before the key gets locked and for how long.
+type LockoutPolicy struct {
+	// MaxFailures number of failures allowed without lockout.
+	MaxFailures int
+	// BaseDelay lockout after the first failure over MaxFailures, it is doubled by every next failure up to MaxDelay.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// ResetAfter period without failures after which counter starts over.
+	ResetAfter time.Duration
+}
+
+// lockedUntil returns time the key is locked until, zero time if it isn't locked.
+func (p LockoutPolicy) lockedUntil(attempts domain.SignInAttempts) time.Time {
+	exceeded := attempts.Failures - p.MaxFailures
+	if exceeded <= 0 {
+		return time.Time{}
+	}
+
+	delay := p.BaseDelay
+	for i := 1; i < exceeded && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+
+	if delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+
+	return attempts.LastFailedAt.Add(delay)
+}
+
+// Lockout tracks failed sign-in attempts per email and per client ip with exponential backoff.
+// Counters are stored in the database, so lockout is shared by all instances of the app.
+type Lockout struct {
+	attemptsAdapter SignInAttemptsAdapter
+	emailPolicy     LockoutPolicy
+	ipPolicy        LockoutPolicy
+}
+
+func NewLockout(attemptsAdapter SignInAttemptsAdapter, emailPolicy, ipPolicy LockoutPolicy) *Lockout {
+	return &Lockout{
+		attemptsAdapter: attemptsAdapter,
+		emailPolicy:     emailPolicy,
+		ipPolicy:        ipPolicy,
+	}
+}
+
+// Check returns ResourceExhausted error if either email or client ip is locked.
+func (l Lockout) Check(ctx context.Context, email, clientIP string) error {
+	var lockedUntil time.Time
+	for _, key := range l.keys(email, clientIP) {
+		attempts, err := l.attemptsAdapter.Get(ctx, key.key)
+		if err != nil {
+			return err
+		}
+
+		if until := key.policy.lockedUntil(attempts); until.After(lockedUntil) {
+			lockedUntil = until
+		}
+	}
+
+	retryAfter := time.Until(lockedUntil)
+	if retryAfter <= 0 {
+		return nil
+	}
+
+	return ierr.New(ierr.ResourceExhausted, "too many failed sign-in attempts").SetProps(ierr.KV{
+		ierr.RetryAfterProp: strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))),
+	})
+}
+
+func (l Lockout) RegisterFailure(ctx context.Context, email, clientIP string) error {
+	now := time.Now()
+	for _, key := range l.keys(email, clientIP) {
+		if _, err := l.attemptsAdapter.RegisterFailure(ctx, key.key, now, now.Add(-key.policy.ResetAfter)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RegisterSuccess resets failures of the email. Client ip counter isn't reset, otherwise
+// attacker could reset it by signing in to own account from time to time.
+func (l Lockout) RegisterSuccess(ctx context.Context, email string) error {
+	return l.attemptsAdapter.Reset(ctx, emailAttemptsKey(email))
+}
+
+// PruneStale removes counters which would start over on the next failure anyway.
+func (l Lockout) PruneStale(ctx context.Context) error {
+	resetAfter := l.emailPolicy.ResetAfter
+	if l.ipPolicy.ResetAfter > resetAfter {
+		resetAfter = l.ipPolicy.ResetAfter
+	}
+
+	_, err := l.attemptsAdapter.DeleteStale(ctx, time.Now().Add(-resetAfter))
+	return err
+}
+
+type lockoutKey struct {
+	key    string
+	policy LockoutPolicy
+}
+
+func (l Lockout) keys(email, clientIP string) []lockoutKey {
+	keys := []lockoutKey{
+		{key: emailAttemptsKey(email), policy: l.emailPolicy},
+	}
+
+	if clientIP != "" {
+		keys = append(keys, lockoutKey{key: ipAttemptsKeyPrefix + clientIP, policy: l.ipPolicy})
+	}
+
+	return keys
+}
+
+func emailAttemptsKey(email string) string {
+	return emailAttemptsKeyPrefix + strings.ToLower(strings.TrimSpace(email))
+}
@@ -0,0 +1,176 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+)
+
+var (
+	testEmailLockoutPolicy = LockoutPolicy{
+		MaxFailures: 5,
+		BaseDelay:   time.Minute,
+		MaxDelay:    time.Hour,
+		ResetAfter:  24 * time.Hour,
+	}
+	testIPLockoutPolicy = LockoutPolicy{
+		MaxFailures: 20,
+		BaseDelay:   time.Minute,
+		MaxDelay:    time.Hour,
+		ResetAfter:  24 * time.Hour,
+	}
+)
+
+func TestLockoutPolicy_lockedUntil(t *testing.T) {
+	lastFailedAt := time.Now()
+
+	tests := []struct {
+		name     string
+		failures int
+		want     time.Time
+	}{
+		{
+			name:     "failures within limit",
+			failures: 5,
+			want:     time.Time{},
+		},
+		{
+			name:     "first failure over limit",
+			failures: 6,
+			want:     lastFailedAt.Add(time.Minute),
+		},
+		{
+			name:     "delay is doubled",
+			failures: 8,
+			want:     lastFailedAt.Add(4 * time.Minute),
+		},
+		{
+			name:     "delay is capped",
+			failures: 1000,
+			want:     lastFailedAt.Add(time.Hour),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := testEmailLockoutPolicy.lockedUntil(domain.SignInAttempts{Failures: tt.failures, LastFailedAt: lastFailedAt})
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestLockout_Check(t *testing.T) {
+	controller := gomock.NewController(t)
+	attemptsAdapterMock := NewMockSignInAttemptsAdapter(controller)
+
+	email := "Test@Test.com"
+	emailKey := "email:test@test.com"
+	clientIP := "127.0.0.1"
+	ipKey := "ip:127.0.0.1"
+	testingError := errors.New("testing-error")
+
+	tests := []struct {
+		name           string
+		mocksInit      func()
+		wantCode       ierr.Code
+		wantRetryAfter int
+		wantErr        bool
+	}{
+		{
+			name: "getting attempts error",
+			mocksInit: func() {
+				attemptsAdapterMock.EXPECT().Get(gomock.Any(), emailKey).
+					Return(domain.SignInAttempts{}, ierr.WrapCode(ierr.Internal, testingError, "execution select query error"))
+			},
+			wantCode: ierr.Internal,
+			wantErr:  true,
+		},
+		{
+			name: "not locked",
+			mocksInit: func() {
+				attemptsAdapterMock.EXPECT().Get(gomock.Any(), emailKey).
+					Return(domain.SignInAttempts{Key: emailKey, Failures: 5, LastFailedAt: time.Now()}, nil)
+				attemptsAdapterMock.EXPECT().Get(gomock.Any(), ipKey).
+					Return(domain.SignInAttempts{Key: ipKey, Failures: 5, LastFailedAt: time.Now()}, nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "lockout is expired",
+			mocksInit: func() {
+				attemptsAdapterMock.EXPECT().Get(gomock.Any(), emailKey).
+					Return(domain.SignInAttempts{Key: emailKey, Failures: 6, LastFailedAt: time.Now().Add(-2 * time.Minute)}, nil)
+				attemptsAdapterMock.EXPECT().Get(gomock.Any(), ipKey).Return(domain.SignInAttempts{Key: ipKey}, nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "email is locked",
+			mocksInit: func() {
+				attemptsAdapterMock.EXPECT().Get(gomock.Any(), emailKey).
+					Return(domain.SignInAttempts{Key: emailKey, Failures: 7, LastFailedAt: time.Now()}, nil)
+				attemptsAdapterMock.EXPECT().Get(gomock.Any(), ipKey).Return(domain.SignInAttempts{Key: ipKey}, nil)
+			},
+			wantCode:       ierr.ResourceExhausted,
+			wantRetryAfter: 120,
+			wantErr:        true,
+		},
+		{
+			name: "ip is locked",
+			mocksInit: func() {
+				attemptsAdapterMock.EXPECT().Get(gomock.Any(), emailKey).Return(domain.SignInAttempts{Key: emailKey}, nil)
+				attemptsAdapterMock.EXPECT().Get(gomock.Any(), ipKey).
+					Return(domain.SignInAttempts{Key: ipKey, Failures: 21, LastFailedAt: time.Now()}, nil)
+			},
+			wantCode:       ierr.ResourceExhausted,
+			wantRetryAfter: 60,
+			wantErr:        true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInit()
+
+			l := NewLockout(attemptsAdapterMock, testEmailLockoutPolicy, testIPLockoutPolicy)
+			err := l.Check(context.TODO(), email, clientIP)
+			assert.Equal(t, tt.wantErr, err != nil)
+			if !tt.wantErr {
+				return
+			}
+
+			assert.Equal(t, tt.wantCode, ierr.GetCode(err))
+			if tt.wantRetryAfter > 0 {
+				assert.Equal(t, strconv.Itoa(tt.wantRetryAfter), ierr.GetProps(err)[ierr.RetryAfterProp])
+			}
+		})
+	}
+}
+
+func TestLockout_RegisterFailure(t *testing.T) {
+	controller := gomock.NewController(t)
+	attemptsAdapterMock := NewMockSignInAttemptsAdapter(controller)
+
+	attemptsAdapterMock.EXPECT().RegisterFailure(gomock.Any(), "email:test@test.com", gomock.Any(), gomock.Any()).
+		Return(domain.SignInAttempts{}, nil)
+	attemptsAdapterMock.EXPECT().RegisterFailure(gomock.Any(), "ip:127.0.0.1", gomock.Any(), gomock.Any()).
+		Return(domain.SignInAttempts{}, nil)
+
+	l := NewLockout(attemptsAdapterMock, testEmailLockoutPolicy, testIPLockoutPolicy)
+	assert.NoError(t, l.RegisterFailure(context.TODO(), "test@test.com", "127.0.0.1"))
+}
+
+func TestLockout_RegisterSuccess(t *testing.T) {
+	controller := gomock.NewController(t)
+	attemptsAdapterMock := NewMockSignInAttemptsAdapter(controller)
+
+	attemptsAdapterMock.EXPECT().Reset(gomock.Any(), "email:test@test.com").Return(nil)
+
+	l := NewLockout(attemptsAdapterMock, testEmailLockoutPolicy, testIPLockoutPolicy)
+	assert.NoError(t, l.RegisterSuccess(context.TODO(), "test@test.com"))
+}
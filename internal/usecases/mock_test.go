@@ -6,7 +6,9 @@ package usecases
 
 import (
 	context "context"
+	io "io"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	uuid "github.com/google/uuid"
@@ -36,6 +38,21 @@ func (m *MockDogAdapter) EXPECT() *MockDogAdapterMockRecorder {
 	return m.recorder
 }
 
+// AddPhoto mocks base method.
+func (m *MockDogAdapter) AddPhoto(ctx context.Context, dogID uuid.UUID, photo domain.DogPhoto, maxPhotos int) (domain.Dog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddPhoto", ctx, dogID, photo, maxPhotos)
+	ret0, _ := ret[0].(domain.Dog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddPhoto indicates an expected call of AddPhoto.
+func (mr *MockDogAdapterMockRecorder) AddPhoto(ctx, dogID, photo, maxPhotos interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPhoto", reflect.TypeOf((*MockDogAdapter)(nil).AddPhoto), ctx, dogID, photo, maxPhotos)
+}
+
 // AddReaction mocks base method.
 func (m *MockDogAdapter) AddReaction(ctx context.Context, reaction domain.Reaction) error {
 	m.ctrl.T.Helper()
@@ -66,11 +83,12 @@ func (mr *MockDogAdapterMockRecorder) Create(ctx, dog interface{}) *gomock.Call
 }
 
 // Delete mocks base method.
-func (m *MockDogAdapter) Delete(ctx context.Context, dogID uuid.UUID) error {
+func (m *MockDogAdapter) Delete(ctx context.Context, dogID uuid.UUID) ([]domain.DogImages, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Delete", ctx, dogID)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].([]domain.DogImages)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // Delete indicates an expected call of Delete.
@@ -79,6 +97,37 @@ func (mr *MockDogAdapterMockRecorder) Delete(ctx, dogID interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockDogAdapter)(nil).Delete), ctx, dogID)
 }
 
+// DeletePhoto mocks base method.
+func (m *MockDogAdapter) DeletePhoto(ctx context.Context, dogID, photoID uuid.UUID) (domain.Dog, domain.DogImages, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePhoto", ctx, dogID, photoID)
+	ret0, _ := ret[0].(domain.Dog)
+	ret1, _ := ret[1].(domain.DogImages)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// DeletePhoto indicates an expected call of DeletePhoto.
+func (mr *MockDogAdapterMockRecorder) DeletePhoto(ctx, dogID, photoID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePhoto", reflect.TypeOf((*MockDogAdapter)(nil).DeletePhoto), ctx, dogID, photoID)
+}
+
+// Feed mocks base method.
+func (m *MockDogAdapter) Feed(ctx context.Context, dog domain.Dog, filter domain.DogFilter, pagination domain.Pagination) (domain.DogPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Feed", ctx, dog, filter, pagination)
+	ret0, _ := ret[0].(domain.DogPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Feed indicates an expected call of Feed.
+func (mr *MockDogAdapterMockRecorder) Feed(ctx, dog, filter, pagination interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Feed", reflect.TypeOf((*MockDogAdapter)(nil).Feed), ctx, dog, filter, pagination)
+}
+
 // Get mocks base method.
 func (m *MockDogAdapter) Get(ctx context.Context, dogID uuid.UUID) (domain.Dog, error) {
 	m.ctrl.T.Helper()
@@ -95,25 +144,40 @@ func (mr *MockDogAdapterMockRecorder) Get(ctx, dogID interface{}) *gomock.Call {
 }
 
 // List mocks base method.
-func (m *MockDogAdapter) List(ctx context.Context, userID uuid.UUID, pagination domain.Pagination) (domain.DogList, error) {
+func (m *MockDogAdapter) List(ctx context.Context, userID uuid.UUID, filter domain.DogFilter, pagination domain.Pagination) (domain.DogPage, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "List", ctx, userID, pagination)
-	ret0, _ := ret[0].(domain.DogList)
+	ret := m.ctrl.Call(m, "List", ctx, userID, filter, pagination)
+	ret0, _ := ret[0].(domain.DogPage)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // List indicates an expected call of List.
-func (mr *MockDogAdapterMockRecorder) List(ctx, userID, pagination interface{}) *gomock.Call {
+func (mr *MockDogAdapterMockRecorder) List(ctx, userID, filter, pagination interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockDogAdapter)(nil).List), ctx, userID, filter, pagination)
+}
+
+// ListByUser mocks base method.
+func (m *MockDogAdapter) ListByUser(ctx context.Context, userID uuid.UUID) (domain.DogList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByUser", ctx, userID)
+	ret0, _ := ret[0].(domain.DogList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByUser indicates an expected call of ListByUser.
+func (mr *MockDogAdapterMockRecorder) ListByUser(ctx, userID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockDogAdapter)(nil).List), ctx, userID, pagination)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByUser", reflect.TypeOf((*MockDogAdapter)(nil).ListByUser), ctx, userID)
 }
 
 // Matches mocks base method.
-func (m *MockDogAdapter) Matches(ctx context.Context, dogID uuid.UUID, pagination domain.Pagination) (domain.DogList, error) {
+func (m *MockDogAdapter) Matches(ctx context.Context, dogID uuid.UUID, pagination domain.Pagination) (domain.DogPage, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Matches", ctx, dogID, pagination)
-	ret0, _ := ret[0].(domain.DogList)
+	ret0, _ := ret[0].(domain.DogPage)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -124,6 +188,111 @@ func (mr *MockDogAdapterMockRecorder) Matches(ctx, dogID, pagination interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Matches", reflect.TypeOf((*MockDogAdapter)(nil).Matches), ctx, dogID, pagination)
 }
 
+// Preferences mocks base method.
+func (m *MockDogAdapter) Preferences(ctx context.Context, dogID uuid.UUID) (domain.DogPreferences, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Preferences", ctx, dogID)
+	ret0, _ := ret[0].(domain.DogPreferences)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Preferences indicates an expected call of Preferences.
+func (mr *MockDogAdapterMockRecorder) Preferences(ctx, dogID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Preferences", reflect.TypeOf((*MockDogAdapter)(nil).Preferences), ctx, dogID)
+}
+
+// PreferencesOf mocks base method.
+func (m *MockDogAdapter) PreferencesOf(ctx context.Context, dogIDs []uuid.UUID) (map[uuid.UUID]domain.DogPreferences, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PreferencesOf", ctx, dogIDs)
+	ret0, _ := ret[0].(map[uuid.UUID]domain.DogPreferences)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PreferencesOf indicates an expected call of PreferencesOf.
+func (mr *MockDogAdapterMockRecorder) PreferencesOf(ctx, dogIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PreferencesOf", reflect.TypeOf((*MockDogAdapter)(nil).PreferencesOf), ctx, dogIDs)
+}
+
+// ReactionStats mocks base method.
+func (m *MockDogAdapter) ReactionStats(ctx context.Context, dogID uuid.UUID, reactorIDs []uuid.UUID) (map[uuid.UUID]domain.ReactionStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReactionStats", ctx, dogID, reactorIDs)
+	ret0, _ := ret[0].(map[uuid.UUID]domain.ReactionStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReactionStats indicates an expected call of ReactionStats.
+func (mr *MockDogAdapterMockRecorder) ReactionStats(ctx, dogID, reactorIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReactionStats", reflect.TypeOf((*MockDogAdapter)(nil).ReactionStats), ctx, dogID, reactorIDs)
+}
+
+// ReorderPhotos mocks base method.
+func (m *MockDogAdapter) ReorderPhotos(ctx context.Context, dogID uuid.UUID, photoIDs []uuid.UUID) (domain.Dog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReorderPhotos", ctx, dogID, photoIDs)
+	ret0, _ := ret[0].(domain.Dog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReorderPhotos indicates an expected call of ReorderPhotos.
+func (mr *MockDogAdapterMockRecorder) ReorderPhotos(ctx, dogID, photoIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReorderPhotos", reflect.TypeOf((*MockDogAdapter)(nil).ReorderPhotos), ctx, dogID, photoIDs)
+}
+
+// SetPreferences mocks base method.
+func (m *MockDogAdapter) SetPreferences(ctx context.Context, dogID uuid.UUID, prefs domain.DogPreferences) (domain.DogPreferences, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPreferences", ctx, dogID, prefs)
+	ret0, _ := ret[0].(domain.DogPreferences)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetPreferences indicates an expected call of SetPreferences.
+func (mr *MockDogAdapterMockRecorder) SetPreferences(ctx, dogID, prefs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPreferences", reflect.TypeOf((*MockDogAdapter)(nil).SetPreferences), ctx, dogID, prefs)
+}
+
+// SetPrimaryPhoto mocks base method.
+func (m *MockDogAdapter) SetPrimaryPhoto(ctx context.Context, dogID, photoID uuid.UUID) (domain.Dog, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPrimaryPhoto", ctx, dogID, photoID)
+	ret0, _ := ret[0].(domain.Dog)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetPrimaryPhoto indicates an expected call of SetPrimaryPhoto.
+func (mr *MockDogAdapterMockRecorder) SetPrimaryPhoto(ctx, dogID, photoID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPrimaryPhoto", reflect.TypeOf((*MockDogAdapter)(nil).SetPrimaryPhoto), ctx, dogID, photoID)
+}
+
+// SimilarPhoto mocks base method.
+func (m *MockDogAdapter) SimilarPhoto(ctx context.Context, hash domain.ImageHash, exceptUserID uuid.UUID, maxDistance int) (domain.SimilarPhoto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SimilarPhoto", ctx, hash, exceptUserID, maxDistance)
+	ret0, _ := ret[0].(domain.SimilarPhoto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SimilarPhoto indicates an expected call of SimilarPhoto.
+func (mr *MockDogAdapterMockRecorder) SimilarPhoto(ctx, hash, exceptUserID, maxDistance interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SimilarPhoto", reflect.TypeOf((*MockDogAdapter)(nil).SimilarPhoto), ctx, hash, exceptUserID, maxDistance)
+}
+
 // Update mocks base method.
 func (m *MockDogAdapter) Update(ctx context.Context, dogID uuid.UUID, dog domain.Dog) (domain.Dog, error) {
 	m.ctrl.T.Helper()
@@ -139,6 +308,104 @@ func (mr *MockDogAdapterMockRecorder) Update(ctx, dogID, dog interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockDogAdapter)(nil).Update), ctx, dogID, dog)
 }
 
+// UserReactions mocks base method.
+func (m *MockDogAdapter) UserReactions(ctx context.Context, userID uuid.UUID) ([]domain.Reaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserReactions", ctx, userID)
+	ret0, _ := ret[0].([]domain.Reaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserReactions indicates an expected call of UserReactions.
+func (mr *MockDogAdapterMockRecorder) UserReactions(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserReactions", reflect.TypeOf((*MockDogAdapter)(nil).UserReactions), ctx, userID)
+}
+
+// MockFlaggedPhotoAdapter is a mock of FlaggedPhotoAdapter interface.
+type MockFlaggedPhotoAdapter struct {
+	ctrl     *gomock.Controller
+	recorder *MockFlaggedPhotoAdapterMockRecorder
+}
+
+// MockFlaggedPhotoAdapterMockRecorder is the mock recorder for MockFlaggedPhotoAdapter.
+type MockFlaggedPhotoAdapterMockRecorder struct {
+	mock *MockFlaggedPhotoAdapter
+}
+
+// NewMockFlaggedPhotoAdapter creates a new mock instance.
+func NewMockFlaggedPhotoAdapter(ctrl *gomock.Controller) *MockFlaggedPhotoAdapter {
+	mock := &MockFlaggedPhotoAdapter{ctrl: ctrl}
+	mock.recorder = &MockFlaggedPhotoAdapterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFlaggedPhotoAdapter) EXPECT() *MockFlaggedPhotoAdapterMockRecorder {
+	return m.recorder
+}
+
+// Approve mocks base method.
+func (m *MockFlaggedPhotoAdapter) Approve(ctx context.Context, photoID, reviewerID uuid.UUID, maxPhotos int) (domain.FlaggedPhoto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Approve", ctx, photoID, reviewerID, maxPhotos)
+	ret0, _ := ret[0].(domain.FlaggedPhoto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Approve indicates an expected call of Approve.
+func (mr *MockFlaggedPhotoAdapterMockRecorder) Approve(ctx, photoID, reviewerID, maxPhotos interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Approve", reflect.TypeOf((*MockFlaggedPhotoAdapter)(nil).Approve), ctx, photoID, reviewerID, maxPhotos)
+}
+
+// Create mocks base method.
+func (m *MockFlaggedPhotoAdapter) Create(ctx context.Context, photo domain.FlaggedPhoto) (domain.FlaggedPhoto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, photo)
+	ret0, _ := ret[0].(domain.FlaggedPhoto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockFlaggedPhotoAdapterMockRecorder) Create(ctx, photo interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockFlaggedPhotoAdapter)(nil).Create), ctx, photo)
+}
+
+// List mocks base method.
+func (m *MockFlaggedPhotoAdapter) List(ctx context.Context, filter domain.FlaggedPhotoFilter) ([]domain.FlaggedPhoto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, filter)
+	ret0, _ := ret[0].([]domain.FlaggedPhoto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockFlaggedPhotoAdapterMockRecorder) List(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockFlaggedPhotoAdapter)(nil).List), ctx, filter)
+}
+
+// Reject mocks base method.
+func (m *MockFlaggedPhotoAdapter) Reject(ctx context.Context, photoID, reviewerID uuid.UUID) (domain.FlaggedPhoto, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reject", ctx, photoID, reviewerID)
+	ret0, _ := ret[0].(domain.FlaggedPhoto)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Reject indicates an expected call of Reject.
+func (mr *MockFlaggedPhotoAdapterMockRecorder) Reject(ctx, photoID, reviewerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reject", reflect.TypeOf((*MockFlaggedPhotoAdapter)(nil).Reject), ctx, photoID, reviewerID)
+}
+
 // MockUserAdapter is a mock of UserAdapter interface.
 type MockUserAdapter struct {
 	ctrl     *gomock.Controller
@@ -162,6 +429,20 @@ func (m *MockUserAdapter) EXPECT() *MockUserAdapterMockRecorder {
 	return m.recorder
 }
 
+// CancelDeletion mocks base method.
+func (m *MockUserAdapter) CancelDeletion(ctx context.Context, userID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelDeletion", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelDeletion indicates an expected call of CancelDeletion.
+func (mr *MockUserAdapterMockRecorder) CancelDeletion(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelDeletion", reflect.TypeOf((*MockUserAdapter)(nil).CancelDeletion), ctx, userID)
+}
+
 // Create mocks base method.
 func (m *MockUserAdapter) Create(ctx context.Context, su domain.SignUp) error {
 	m.ctrl.T.Helper()
@@ -176,6 +457,49 @@ func (mr *MockUserAdapterMockRecorder) Create(ctx, su interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockUserAdapter)(nil).Create), ctx, su)
 }
 
+// DeleteScheduled mocks base method.
+func (m *MockUserAdapter) DeleteScheduled(ctx context.Context, before time.Time) (domain.PurgedAccounts, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteScheduled", ctx, before)
+	ret0, _ := ret[0].(domain.PurgedAccounts)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteScheduled indicates an expected call of DeleteScheduled.
+func (mr *MockUserAdapterMockRecorder) DeleteScheduled(ctx, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteScheduled", reflect.TypeOf((*MockUserAdapter)(nil).DeleteScheduled), ctx, before)
+}
+
+// DisableTOTP mocks base method.
+func (m *MockUserAdapter) DisableTOTP(ctx context.Context, userID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableTOTP", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DisableTOTP indicates an expected call of DisableTOTP.
+func (mr *MockUserAdapterMockRecorder) DisableTOTP(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableTOTP", reflect.TypeOf((*MockUserAdapter)(nil).DisableTOTP), ctx, userID)
+}
+
+// EnableTOTP mocks base method.
+func (m *MockUserAdapter) EnableTOTP(ctx context.Context, userID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableTOTP", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnableTOTP indicates an expected call of EnableTOTP.
+func (mr *MockUserAdapterMockRecorder) EnableTOTP(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableTOTP", reflect.TypeOf((*MockUserAdapter)(nil).EnableTOTP), ctx, userID)
+}
+
 // Exists mocks base method.
 func (m *MockUserAdapter) Exists(ctx context.Context, email string) (bool, error) {
 	m.ctrl.T.Helper()
@@ -192,91 +516,1691 @@ func (mr *MockUserAdapterMockRecorder) Exists(ctx, email interface{}) *gomock.Ca
 }
 
 // Get mocks base method.
-func (m *MockUserAdapter) Get(ctx context.Context, si domain.SingIn) (domain.User, error) {
+func (m *MockUserAdapter) Get(ctx context.Context, userID uuid.UUID) (domain.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Get", ctx, si)
+	ret := m.ctrl.Call(m, "Get", ctx, userID)
 	ret0, _ := ret[0].(domain.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Get indicates an expected call of Get.
-func (mr *MockUserAdapterMockRecorder) Get(ctx, si interface{}) *gomock.Call {
+func (mr *MockUserAdapterMockRecorder) Get(ctx, userID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockUserAdapter)(nil).Get), ctx, si)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockUserAdapter)(nil).Get), ctx, userID)
 }
 
-// MockPasswordHasher is a mock of PasswordHasher interface.
-type MockPasswordHasher struct {
-	ctrl     *gomock.Controller
-	recorder *MockPasswordHasherMockRecorder
+// GetByEmail mocks base method.
+func (m *MockUserAdapter) GetByEmail(ctx context.Context, email string) (domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByEmail", ctx, email)
+	ret0, _ := ret[0].(domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// MockPasswordHasherMockRecorder is the mock recorder for MockPasswordHasher.
-type MockPasswordHasherMockRecorder struct {
-	mock *MockPasswordHasher
+// GetByEmail indicates an expected call of GetByEmail.
+func (mr *MockUserAdapterMockRecorder) GetByEmail(ctx, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByEmail", reflect.TypeOf((*MockUserAdapter)(nil).GetByEmail), ctx, email)
 }
 
-// NewMockPasswordHasher creates a new mock instance.
-func NewMockPasswordHasher(ctrl *gomock.Controller) *MockPasswordHasher {
-	mock := &MockPasswordHasher{ctrl: ctrl}
-	mock.recorder = &MockPasswordHasherMockRecorder{mock}
-	return mock
+// MarkEmailVerified mocks base method.
+func (m *MockUserAdapter) MarkEmailVerified(ctx context.Context, userID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkEmailVerified", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockPasswordHasher) EXPECT() *MockPasswordHasherMockRecorder {
-	return m.recorder
+// MarkEmailVerified indicates an expected call of MarkEmailVerified.
+func (mr *MockUserAdapterMockRecorder) MarkEmailVerified(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkEmailVerified", reflect.TypeOf((*MockUserAdapter)(nil).MarkEmailVerified), ctx, userID)
 }
 
-// StringHash mocks base method.
-func (m *MockPasswordHasher) StringHash(in string) string {
+// ScheduleDeletion mocks base method.
+func (m *MockUserAdapter) ScheduleDeletion(ctx context.Context, userID uuid.UUID, at time.Time) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "StringHash", in)
-	ret0, _ := ret[0].(string)
+	ret := m.ctrl.Call(m, "ScheduleDeletion", ctx, userID, at)
+	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// StringHash indicates an expected call of StringHash.
-func (mr *MockPasswordHasherMockRecorder) StringHash(in interface{}) *gomock.Call {
+// ScheduleDeletion indicates an expected call of ScheduleDeletion.
+func (mr *MockUserAdapterMockRecorder) ScheduleDeletion(ctx, userID, at interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StringHash", reflect.TypeOf((*MockPasswordHasher)(nil).StringHash), in)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScheduleDeletion", reflect.TypeOf((*MockUserAdapter)(nil).ScheduleDeletion), ctx, userID, at)
 }
 
-// MockTokenGenerator is a mock of TokenGenerator interface.
-type MockTokenGenerator struct {
-	ctrl     *gomock.Controller
-	recorder *MockTokenGeneratorMockRecorder
+// SetTOTPSecret mocks base method.
+func (m *MockUserAdapter) SetTOTPSecret(ctx context.Context, userID uuid.UUID, secret string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTOTPSecret", ctx, userID, secret)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// MockTokenGeneratorMockRecorder is the mock recorder for MockTokenGenerator.
-type MockTokenGeneratorMockRecorder struct {
-	mock *MockTokenGenerator
+// SetTOTPSecret indicates an expected call of SetTOTPSecret.
+func (mr *MockUserAdapterMockRecorder) SetTOTPSecret(ctx, userID, secret interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTOTPSecret", reflect.TypeOf((*MockUserAdapter)(nil).SetTOTPSecret), ctx, userID, secret)
 }
 
-// NewMockTokenGenerator creates a new mock instance.
-func NewMockTokenGenerator(ctrl *gomock.Controller) *MockTokenGenerator {
-	mock := &MockTokenGenerator{ctrl: ctrl}
-	mock.recorder = &MockTokenGeneratorMockRecorder{mock}
-	return mock
+// UpdateEmail mocks base method.
+func (m *MockUserAdapter) UpdateEmail(ctx context.Context, userID uuid.UUID, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateEmail", ctx, userID, email)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockTokenGenerator) EXPECT() *MockTokenGeneratorMockRecorder {
-	return m.recorder
+// UpdateEmail indicates an expected call of UpdateEmail.
+func (mr *MockUserAdapterMockRecorder) UpdateEmail(ctx, userID, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateEmail", reflect.TypeOf((*MockUserAdapter)(nil).UpdateEmail), ctx, userID, email)
 }
 
-// Generate mocks base method.
-func (m *MockTokenGenerator) Generate(userID uuid.UUID) (string, error) {
+// UpdatePasswordHash mocks base method.
+func (m *MockUserAdapter) UpdatePasswordHash(ctx context.Context, userID uuid.UUID, passwordHash string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Generate", userID)
-	ret0, _ := ret[0].(string)
+	ret := m.ctrl.Call(m, "UpdatePasswordHash", ctx, userID, passwordHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePasswordHash indicates an expected call of UpdatePasswordHash.
+func (mr *MockUserAdapterMockRecorder) UpdatePasswordHash(ctx, userID, passwordHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePasswordHash", reflect.TypeOf((*MockUserAdapter)(nil).UpdatePasswordHash), ctx, userID, passwordHash)
+}
+
+// UpdateProfile mocks base method.
+func (m *MockUserAdapter) UpdateProfile(ctx context.Context, userID uuid.UUID, profile domain.Profile) (domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProfile", ctx, userID, profile)
+	ret0, _ := ret[0].(domain.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// Generate indicates an expected call of Generate.
-func (mr *MockTokenGeneratorMockRecorder) Generate(userID interface{}) *gomock.Call {
+// UpdateProfile indicates an expected call of UpdateProfile.
+func (mr *MockUserAdapterMockRecorder) UpdateProfile(ctx, userID, profile interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProfile", reflect.TypeOf((*MockUserAdapter)(nil).UpdateProfile), ctx, userID, profile)
+}
+
+// UpdateRole mocks base method.
+func (m *MockUserAdapter) UpdateRole(ctx context.Context, userID uuid.UUID, role domain.Role) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRole", ctx, userID, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateRole indicates an expected call of UpdateRole.
+func (mr *MockUserAdapterMockRecorder) UpdateRole(ctx, userID, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRole", reflect.TypeOf((*MockUserAdapter)(nil).UpdateRole), ctx, userID, role)
+}
+
+// UseTOTPStep mocks base method.
+func (m *MockUserAdapter) UseTOTPStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseTOTPStep", ctx, userID, step)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseTOTPStep indicates an expected call of UseTOTPStep.
+func (mr *MockUserAdapterMockRecorder) UseTOTPStep(ctx, userID, step interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseTOTPStep", reflect.TypeOf((*MockUserAdapter)(nil).UseTOTPStep), ctx, userID, step)
+}
+
+// MockRecoveryCodeAdapter is a mock of RecoveryCodeAdapter interface.
+type MockRecoveryCodeAdapter struct {
+	ctrl     *gomock.Controller
+	recorder *MockRecoveryCodeAdapterMockRecorder
+}
+
+// MockRecoveryCodeAdapterMockRecorder is the mock recorder for MockRecoveryCodeAdapter.
+type MockRecoveryCodeAdapterMockRecorder struct {
+	mock *MockRecoveryCodeAdapter
+}
+
+// NewMockRecoveryCodeAdapter creates a new mock instance.
+func NewMockRecoveryCodeAdapter(ctrl *gomock.Controller) *MockRecoveryCodeAdapter {
+	mock := &MockRecoveryCodeAdapter{ctrl: ctrl}
+	mock.recorder = &MockRecoveryCodeAdapterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRecoveryCodeAdapter) EXPECT() *MockRecoveryCodeAdapterMockRecorder {
+	return m.recorder
+}
+
+// Replace mocks base method.
+func (m *MockRecoveryCodeAdapter) Replace(ctx context.Context, userID uuid.UUID, codeHashes []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Replace", ctx, userID, codeHashes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Replace indicates an expected call of Replace.
+func (mr *MockRecoveryCodeAdapterMockRecorder) Replace(ctx, userID, codeHashes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Replace", reflect.TypeOf((*MockRecoveryCodeAdapter)(nil).Replace), ctx, userID, codeHashes)
+}
+
+// Use mocks base method.
+func (m *MockRecoveryCodeAdapter) Use(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Use", ctx, userID, codeHash)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Use indicates an expected call of Use.
+func (mr *MockRecoveryCodeAdapterMockRecorder) Use(ctx, userID, codeHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Use", reflect.TypeOf((*MockRecoveryCodeAdapter)(nil).Use), ctx, userID, codeHash)
+}
+
+// MockUserIdentityAdapter is a mock of UserIdentityAdapter interface.
+type MockUserIdentityAdapter struct {
+	ctrl     *gomock.Controller
+	recorder *MockUserIdentityAdapterMockRecorder
+}
+
+// MockUserIdentityAdapterMockRecorder is the mock recorder for MockUserIdentityAdapter.
+type MockUserIdentityAdapterMockRecorder struct {
+	mock *MockUserIdentityAdapter
+}
+
+// NewMockUserIdentityAdapter creates a new mock instance.
+func NewMockUserIdentityAdapter(ctrl *gomock.Controller) *MockUserIdentityAdapter {
+	mock := &MockUserIdentityAdapter{ctrl: ctrl}
+	mock.recorder = &MockUserIdentityAdapterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUserIdentityAdapter) EXPECT() *MockUserIdentityAdapterMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockUserIdentityAdapter) Create(ctx context.Context, userID uuid.UUID, identity domain.ExternalIdentity) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, userID, identity)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockUserIdentityAdapterMockRecorder) Create(ctx, userID, identity interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockUserIdentityAdapter)(nil).Create), ctx, userID, identity)
+}
+
+// GetUserID mocks base method.
+func (m *MockUserIdentityAdapter) GetUserID(ctx context.Context, issuer, subject string) (uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserID", ctx, issuer, subject)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserID indicates an expected call of GetUserID.
+func (mr *MockUserIdentityAdapterMockRecorder) GetUserID(ctx, issuer, subject interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserID", reflect.TypeOf((*MockUserIdentityAdapter)(nil).GetUserID), ctx, issuer, subject)
+}
+
+// MockAPIKeyAdapter is a mock of APIKeyAdapter interface.
+type MockAPIKeyAdapter struct {
+	ctrl     *gomock.Controller
+	recorder *MockAPIKeyAdapterMockRecorder
+}
+
+// MockAPIKeyAdapterMockRecorder is the mock recorder for MockAPIKeyAdapter.
+type MockAPIKeyAdapterMockRecorder struct {
+	mock *MockAPIKeyAdapter
+}
+
+// NewMockAPIKeyAdapter creates a new mock instance.
+func NewMockAPIKeyAdapter(ctrl *gomock.Controller) *MockAPIKeyAdapter {
+	mock := &MockAPIKeyAdapter{ctrl: ctrl}
+	mock.recorder = &MockAPIKeyAdapterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAPIKeyAdapter) EXPECT() *MockAPIKeyAdapterMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockAPIKeyAdapter) Create(ctx context.Context, key domain.APIKey) (domain.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, key)
+	ret0, _ := ret[0].(domain.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockAPIKeyAdapterMockRecorder) Create(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockAPIKeyAdapter)(nil).Create), ctx, key)
+}
+
+// List mocks base method.
+func (m *MockAPIKeyAdapter) List(ctx context.Context, userID uuid.UUID) ([]domain.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, userID)
+	ret0, _ := ret[0].([]domain.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockAPIKeyAdapterMockRecorder) List(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockAPIKeyAdapter)(nil).List), ctx, userID)
+}
+
+// Revoke mocks base method.
+func (m *MockAPIKeyAdapter) Revoke(ctx context.Context, userID, keyID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revoke", ctx, userID, keyID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Revoke indicates an expected call of Revoke.
+func (mr *MockAPIKeyAdapterMockRecorder) Revoke(ctx, userID, keyID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockAPIKeyAdapter)(nil).Revoke), ctx, userID, keyID)
+}
+
+// RevokeAll mocks base method.
+func (m *MockAPIKeyAdapter) RevokeAll(ctx context.Context, userID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAll", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAll indicates an expected call of RevokeAll.
+func (mr *MockAPIKeyAdapterMockRecorder) RevokeAll(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAll", reflect.TypeOf((*MockAPIKeyAdapter)(nil).RevokeAll), ctx, userID)
+}
+
+// Use mocks base method.
+func (m *MockAPIKeyAdapter) Use(ctx context.Context, keyHash string) (domain.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Use", ctx, keyHash)
+	ret0, _ := ret[0].(domain.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Use indicates an expected call of Use.
+func (mr *MockAPIKeyAdapterMockRecorder) Use(ctx, keyHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Use", reflect.TypeOf((*MockAPIKeyAdapter)(nil).Use), ctx, keyHash)
+}
+
+// MockDataExportAdapter is a mock of DataExportAdapter interface.
+type MockDataExportAdapter struct {
+	ctrl     *gomock.Controller
+	recorder *MockDataExportAdapterMockRecorder
+}
+
+// MockDataExportAdapterMockRecorder is the mock recorder for MockDataExportAdapter.
+type MockDataExportAdapterMockRecorder struct {
+	mock *MockDataExportAdapter
+}
+
+// NewMockDataExportAdapter creates a new mock instance.
+func NewMockDataExportAdapter(ctrl *gomock.Controller) *MockDataExportAdapter {
+	mock := &MockDataExportAdapter{ctrl: ctrl}
+	mock.recorder = &MockDataExportAdapterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDataExportAdapter) EXPECT() *MockDataExportAdapterMockRecorder {
+	return m.recorder
+}
+
+// Claim mocks base method.
+func (m *MockDataExportAdapter) Claim(ctx context.Context, staleBefore time.Time) (domain.DataExport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Claim", ctx, staleBefore)
+	ret0, _ := ret[0].(domain.DataExport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Claim indicates an expected call of Claim.
+func (mr *MockDataExportAdapterMockRecorder) Claim(ctx, staleBefore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Claim", reflect.TypeOf((*MockDataExportAdapter)(nil).Claim), ctx, staleBefore)
+}
+
+// Complete mocks base method.
+func (m *MockDataExportAdapter) Complete(ctx context.Context, exportID uuid.UUID, archive []byte, expiresAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Complete", ctx, exportID, archive, expiresAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Complete indicates an expected call of Complete.
+func (mr *MockDataExportAdapterMockRecorder) Complete(ctx, exportID, archive, expiresAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Complete", reflect.TypeOf((*MockDataExportAdapter)(nil).Complete), ctx, exportID, archive, expiresAt)
+}
+
+// Create mocks base method.
+func (m *MockDataExportAdapter) Create(ctx context.Context, userID uuid.UUID) (domain.DataExport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, userID)
+	ret0, _ := ret[0].(domain.DataExport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockDataExportAdapterMockRecorder) Create(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockDataExportAdapter)(nil).Create), ctx, userID)
+}
+
+// DeleteExpired mocks base method.
+func (m *MockDataExportAdapter) DeleteExpired(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpired", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpired indicates an expected call of DeleteExpired.
+func (mr *MockDataExportAdapterMockRecorder) DeleteExpired(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpired", reflect.TypeOf((*MockDataExportAdapter)(nil).DeleteExpired), ctx)
+}
+
+// Fail mocks base method.
+func (m *MockDataExportAdapter) Fail(ctx context.Context, exportID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Fail", ctx, exportID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Fail indicates an expected call of Fail.
+func (mr *MockDataExportAdapterMockRecorder) Fail(ctx, exportID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Fail", reflect.TypeOf((*MockDataExportAdapter)(nil).Fail), ctx, exportID)
+}
+
+// Get mocks base method.
+func (m *MockDataExportAdapter) Get(ctx context.Context, userID, exportID uuid.UUID) (domain.DataExport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, userID, exportID)
+	ret0, _ := ret[0].(domain.DataExport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockDataExportAdapterMockRecorder) Get(ctx, userID, exportID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockDataExportAdapter)(nil).Get), ctx, userID, exportID)
+}
+
+// GetArchive mocks base method.
+func (m *MockDataExportAdapter) GetArchive(ctx context.Context, userID, exportID uuid.UUID) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetArchive", ctx, userID, exportID)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetArchive indicates an expected call of GetArchive.
+func (mr *MockDataExportAdapterMockRecorder) GetArchive(ctx, userID, exportID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetArchive", reflect.TypeOf((*MockDataExportAdapter)(nil).GetArchive), ctx, userID, exportID)
+}
+
+// GetUnfinished mocks base method.
+func (m *MockDataExportAdapter) GetUnfinished(ctx context.Context, userID uuid.UUID) (domain.DataExport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUnfinished", ctx, userID)
+	ret0, _ := ret[0].(domain.DataExport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUnfinished indicates an expected call of GetUnfinished.
+func (mr *MockDataExportAdapterMockRecorder) GetUnfinished(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUnfinished", reflect.TypeOf((*MockDataExportAdapter)(nil).GetUnfinished), ctx, userID)
+}
+
+// MockAuditEventAdapter is a mock of AuditEventAdapter interface.
+type MockAuditEventAdapter struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditEventAdapterMockRecorder
+}
+
+// MockAuditEventAdapterMockRecorder is the mock recorder for MockAuditEventAdapter.
+type MockAuditEventAdapterMockRecorder struct {
+	mock *MockAuditEventAdapter
+}
+
+// NewMockAuditEventAdapter creates a new mock instance.
+func NewMockAuditEventAdapter(ctrl *gomock.Controller) *MockAuditEventAdapter {
+	mock := &MockAuditEventAdapter{ctrl: ctrl}
+	mock.recorder = &MockAuditEventAdapterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditEventAdapter) EXPECT() *MockAuditEventAdapterMockRecorder {
+	return m.recorder
+}
+
+// List mocks base method.
+func (m *MockAuditEventAdapter) List(ctx context.Context, filter domain.AuditEventFilter) ([]domain.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, filter)
+	ret0, _ := ret[0].([]domain.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockAuditEventAdapterMockRecorder) List(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockAuditEventAdapter)(nil).List), ctx, filter)
+}
+
+// MockRefreshTokenAdapter is a mock of RefreshTokenAdapter interface.
+type MockRefreshTokenAdapter struct {
+	ctrl     *gomock.Controller
+	recorder *MockRefreshTokenAdapterMockRecorder
+}
+
+// MockRefreshTokenAdapterMockRecorder is the mock recorder for MockRefreshTokenAdapter.
+type MockRefreshTokenAdapterMockRecorder struct {
+	mock *MockRefreshTokenAdapter
+}
+
+// NewMockRefreshTokenAdapter creates a new mock instance.
+func NewMockRefreshTokenAdapter(ctrl *gomock.Controller) *MockRefreshTokenAdapter {
+	mock := &MockRefreshTokenAdapter{ctrl: ctrl}
+	mock.recorder = &MockRefreshTokenAdapterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRefreshTokenAdapter) EXPECT() *MockRefreshTokenAdapterMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockRefreshTokenAdapter) Create(ctx context.Context, rt domain.RefreshToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, rt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockRefreshTokenAdapterMockRecorder) Create(ctx, rt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockRefreshTokenAdapter)(nil).Create), ctx, rt)
+}
+
+// GetByHash mocks base method.
+func (m *MockRefreshTokenAdapter) GetByHash(ctx context.Context, tokenHash string) (domain.RefreshToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByHash", ctx, tokenHash)
+	ret0, _ := ret[0].(domain.RefreshToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByHash indicates an expected call of GetByHash.
+func (mr *MockRefreshTokenAdapterMockRecorder) GetByHash(ctx, tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByHash", reflect.TypeOf((*MockRefreshTokenAdapter)(nil).GetByHash), ctx, tokenHash)
+}
+
+// MarkUsed mocks base method.
+func (m *MockRefreshTokenAdapter) MarkUsed(ctx context.Context, id uuid.UUID) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkUsed", ctx, id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkUsed indicates an expected call of MarkUsed.
+func (mr *MockRefreshTokenAdapterMockRecorder) MarkUsed(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkUsed", reflect.TypeOf((*MockRefreshTokenAdapter)(nil).MarkUsed), ctx, id)
+}
+
+// RevokeAllForUser mocks base method.
+func (m *MockRefreshTokenAdapter) RevokeAllForUser(ctx context.Context, userID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAllForUser", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAllForUser indicates an expected call of RevokeAllForUser.
+func (mr *MockRefreshTokenAdapterMockRecorder) RevokeAllForUser(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAllForUser", reflect.TypeOf((*MockRefreshTokenAdapter)(nil).RevokeAllForUser), ctx, userID)
+}
+
+// RevokeFamily mocks base method.
+func (m *MockRefreshTokenAdapter) RevokeFamily(ctx context.Context, familyID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeFamily", ctx, familyID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeFamily indicates an expected call of RevokeFamily.
+func (mr *MockRefreshTokenAdapterMockRecorder) RevokeFamily(ctx, familyID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeFamily", reflect.TypeOf((*MockRefreshTokenAdapter)(nil).RevokeFamily), ctx, familyID)
+}
+
+// MockPasswordResetTokenAdapter is a mock of PasswordResetTokenAdapter interface.
+type MockPasswordResetTokenAdapter struct {
+	ctrl     *gomock.Controller
+	recorder *MockPasswordResetTokenAdapterMockRecorder
+}
+
+// MockPasswordResetTokenAdapterMockRecorder is the mock recorder for MockPasswordResetTokenAdapter.
+type MockPasswordResetTokenAdapterMockRecorder struct {
+	mock *MockPasswordResetTokenAdapter
+}
+
+// NewMockPasswordResetTokenAdapter creates a new mock instance.
+func NewMockPasswordResetTokenAdapter(ctrl *gomock.Controller) *MockPasswordResetTokenAdapter {
+	mock := &MockPasswordResetTokenAdapter{ctrl: ctrl}
+	mock.recorder = &MockPasswordResetTokenAdapterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPasswordResetTokenAdapter) EXPECT() *MockPasswordResetTokenAdapterMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockPasswordResetTokenAdapter) Create(ctx context.Context, prt domain.PasswordResetToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, prt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockPasswordResetTokenAdapterMockRecorder) Create(ctx, prt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockPasswordResetTokenAdapter)(nil).Create), ctx, prt)
+}
+
+// GetByHash mocks base method.
+func (m *MockPasswordResetTokenAdapter) GetByHash(ctx context.Context, tokenHash string) (domain.PasswordResetToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByHash", ctx, tokenHash)
+	ret0, _ := ret[0].(domain.PasswordResetToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByHash indicates an expected call of GetByHash.
+func (mr *MockPasswordResetTokenAdapterMockRecorder) GetByHash(ctx, tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByHash", reflect.TypeOf((*MockPasswordResetTokenAdapter)(nil).GetByHash), ctx, tokenHash)
+}
+
+// InvalidateForUser mocks base method.
+func (m *MockPasswordResetTokenAdapter) InvalidateForUser(ctx context.Context, userID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InvalidateForUser", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InvalidateForUser indicates an expected call of InvalidateForUser.
+func (mr *MockPasswordResetTokenAdapterMockRecorder) InvalidateForUser(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InvalidateForUser", reflect.TypeOf((*MockPasswordResetTokenAdapter)(nil).InvalidateForUser), ctx, userID)
+}
+
+// MarkUsed mocks base method.
+func (m *MockPasswordResetTokenAdapter) MarkUsed(ctx context.Context, id uuid.UUID) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkUsed", ctx, id)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkUsed indicates an expected call of MarkUsed.
+func (mr *MockPasswordResetTokenAdapterMockRecorder) MarkUsed(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkUsed", reflect.TypeOf((*MockPasswordResetTokenAdapter)(nil).MarkUsed), ctx, id)
+}
+
+// MockSignInAttemptsAdapter is a mock of SignInAttemptsAdapter interface.
+type MockSignInAttemptsAdapter struct {
+	ctrl     *gomock.Controller
+	recorder *MockSignInAttemptsAdapterMockRecorder
+}
+
+// MockSignInAttemptsAdapterMockRecorder is the mock recorder for MockSignInAttemptsAdapter.
+type MockSignInAttemptsAdapterMockRecorder struct {
+	mock *MockSignInAttemptsAdapter
+}
+
+// NewMockSignInAttemptsAdapter creates a new mock instance.
+func NewMockSignInAttemptsAdapter(ctrl *gomock.Controller) *MockSignInAttemptsAdapter {
+	mock := &MockSignInAttemptsAdapter{ctrl: ctrl}
+	mock.recorder = &MockSignInAttemptsAdapterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSignInAttemptsAdapter) EXPECT() *MockSignInAttemptsAdapterMockRecorder {
+	return m.recorder
+}
+
+// DeleteStale mocks base method.
+func (m *MockSignInAttemptsAdapter) DeleteStale(ctx context.Context, before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteStale", ctx, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteStale indicates an expected call of DeleteStale.
+func (mr *MockSignInAttemptsAdapterMockRecorder) DeleteStale(ctx, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteStale", reflect.TypeOf((*MockSignInAttemptsAdapter)(nil).DeleteStale), ctx, before)
+}
+
+// Get mocks base method.
+func (m *MockSignInAttemptsAdapter) Get(ctx context.Context, key string) (domain.SignInAttempts, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, key)
+	ret0, _ := ret[0].(domain.SignInAttempts)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockSignInAttemptsAdapterMockRecorder) Get(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockSignInAttemptsAdapter)(nil).Get), ctx, key)
+}
+
+// RegisterFailure mocks base method.
+func (m *MockSignInAttemptsAdapter) RegisterFailure(ctx context.Context, key string, failedAt, resetBefore time.Time) (domain.SignInAttempts, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RegisterFailure", ctx, key, failedAt, resetBefore)
+	ret0, _ := ret[0].(domain.SignInAttempts)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RegisterFailure indicates an expected call of RegisterFailure.
+func (mr *MockSignInAttemptsAdapterMockRecorder) RegisterFailure(ctx, key, failedAt, resetBefore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterFailure", reflect.TypeOf((*MockSignInAttemptsAdapter)(nil).RegisterFailure), ctx, key, failedAt, resetBefore)
+}
+
+// Reset mocks base method.
+func (m *MockSignInAttemptsAdapter) Reset(ctx context.Context, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reset", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Reset indicates an expected call of Reset.
+func (mr *MockSignInAttemptsAdapterMockRecorder) Reset(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reset", reflect.TypeOf((*MockSignInAttemptsAdapter)(nil).Reset), ctx, key)
+}
+
+// MockTokenRevocationAdapter is a mock of TokenRevocationAdapter interface.
+type MockTokenRevocationAdapter struct {
+	ctrl     *gomock.Controller
+	recorder *MockTokenRevocationAdapterMockRecorder
+}
+
+// MockTokenRevocationAdapterMockRecorder is the mock recorder for MockTokenRevocationAdapter.
+type MockTokenRevocationAdapterMockRecorder struct {
+	mock *MockTokenRevocationAdapter
+}
+
+// NewMockTokenRevocationAdapter creates a new mock instance.
+func NewMockTokenRevocationAdapter(ctrl *gomock.Controller) *MockTokenRevocationAdapter {
+	mock := &MockTokenRevocationAdapter{ctrl: ctrl}
+	mock.recorder = &MockTokenRevocationAdapterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTokenRevocationAdapter) EXPECT() *MockTokenRevocationAdapterMockRecorder {
+	return m.recorder
+}
+
+// DeleteExpired mocks base method.
+func (m *MockTokenRevocationAdapter) DeleteExpired(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpired", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpired indicates an expected call of DeleteExpired.
+func (mr *MockTokenRevocationAdapterMockRecorder) DeleteExpired(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpired", reflect.TypeOf((*MockTokenRevocationAdapter)(nil).DeleteExpired), ctx)
+}
+
+// IsRevoked mocks base method.
+func (m *MockTokenRevocationAdapter) IsRevoked(ctx context.Context, claims domain.TokenClaims) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsRevoked", ctx, claims)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsRevoked indicates an expected call of IsRevoked.
+func (mr *MockTokenRevocationAdapterMockRecorder) IsRevoked(ctx, claims interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsRevoked", reflect.TypeOf((*MockTokenRevocationAdapter)(nil).IsRevoked), ctx, claims)
+}
+
+// Revoke mocks base method.
+func (m *MockTokenRevocationAdapter) Revoke(ctx context.Context, claims domain.TokenClaims) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revoke", ctx, claims)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Revoke indicates an expected call of Revoke.
+func (mr *MockTokenRevocationAdapterMockRecorder) Revoke(ctx, claims interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockTokenRevocationAdapter)(nil).Revoke), ctx, claims)
+}
+
+// RevokeAllIssuedBefore mocks base method.
+func (m *MockTokenRevocationAdapter) RevokeAllIssuedBefore(ctx context.Context, userID uuid.UUID, before time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAllIssuedBefore", ctx, userID, before)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAllIssuedBefore indicates an expected call of RevokeAllIssuedBefore.
+func (mr *MockTokenRevocationAdapterMockRecorder) RevokeAllIssuedBefore(ctx, userID, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAllIssuedBefore", reflect.TypeOf((*MockTokenRevocationAdapter)(nil).RevokeAllIssuedBefore), ctx, userID, before)
+}
+
+// MockPasswordHasher is a mock of PasswordHasher interface.
+type MockPasswordHasher struct {
+	ctrl     *gomock.Controller
+	recorder *MockPasswordHasherMockRecorder
+}
+
+// MockPasswordHasherMockRecorder is the mock recorder for MockPasswordHasher.
+type MockPasswordHasherMockRecorder struct {
+	mock *MockPasswordHasher
+}
+
+// NewMockPasswordHasher creates a new mock instance.
+func NewMockPasswordHasher(ctrl *gomock.Controller) *MockPasswordHasher {
+	mock := &MockPasswordHasher{ctrl: ctrl}
+	mock.recorder = &MockPasswordHasherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPasswordHasher) EXPECT() *MockPasswordHasherMockRecorder {
+	return m.recorder
+}
+
+// Hash mocks base method.
+func (m *MockPasswordHasher) Hash(password string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Hash", password)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Hash indicates an expected call of Hash.
+func (mr *MockPasswordHasherMockRecorder) Hash(password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Hash", reflect.TypeOf((*MockPasswordHasher)(nil).Hash), password)
+}
+
+// NeedsRehash mocks base method.
+func (m *MockPasswordHasher) NeedsRehash(hash string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NeedsRehash", hash)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// NeedsRehash indicates an expected call of NeedsRehash.
+func (mr *MockPasswordHasherMockRecorder) NeedsRehash(hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NeedsRehash", reflect.TypeOf((*MockPasswordHasher)(nil).NeedsRehash), hash)
+}
+
+// Verify mocks base method.
+func (m *MockPasswordHasher) Verify(password, hash string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Verify", password, hash)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Verify indicates an expected call of Verify.
+func (mr *MockPasswordHasherMockRecorder) Verify(password, hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockPasswordHasher)(nil).Verify), password, hash)
+}
+
+// MockTokenGenerator is a mock of TokenGenerator interface.
+type MockTokenGenerator struct {
+	ctrl     *gomock.Controller
+	recorder *MockTokenGeneratorMockRecorder
+}
+
+// MockTokenGeneratorMockRecorder is the mock recorder for MockTokenGenerator.
+type MockTokenGeneratorMockRecorder struct {
+	mock *MockTokenGenerator
+}
+
+// NewMockTokenGenerator creates a new mock instance.
+func NewMockTokenGenerator(ctrl *gomock.Controller) *MockTokenGenerator {
+	mock := &MockTokenGenerator{ctrl: ctrl}
+	mock.recorder = &MockTokenGeneratorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTokenGenerator) EXPECT() *MockTokenGeneratorMockRecorder {
+	return m.recorder
+}
+
+// Generate mocks base method.
+func (m *MockTokenGenerator) Generate(userID uuid.UUID, role string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Generate", userID, role)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Generate indicates an expected call of Generate.
+func (mr *MockTokenGeneratorMockRecorder) Generate(userID, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Generate", reflect.TypeOf((*MockTokenGenerator)(nil).Generate), userID, role)
+}
+
+// MockOpaqueTokenGenerator is a mock of OpaqueTokenGenerator interface.
+type MockOpaqueTokenGenerator struct {
+	ctrl     *gomock.Controller
+	recorder *MockOpaqueTokenGeneratorMockRecorder
+}
+
+// MockOpaqueTokenGeneratorMockRecorder is the mock recorder for MockOpaqueTokenGenerator.
+type MockOpaqueTokenGeneratorMockRecorder struct {
+	mock *MockOpaqueTokenGenerator
+}
+
+// NewMockOpaqueTokenGenerator creates a new mock instance.
+func NewMockOpaqueTokenGenerator(ctrl *gomock.Controller) *MockOpaqueTokenGenerator {
+	mock := &MockOpaqueTokenGenerator{ctrl: ctrl}
+	mock.recorder = &MockOpaqueTokenGeneratorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOpaqueTokenGenerator) EXPECT() *MockOpaqueTokenGeneratorMockRecorder {
+	return m.recorder
+}
+
+// Generate mocks base method.
+func (m *MockOpaqueTokenGenerator) Generate() (string, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Generate")
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Generate indicates an expected call of Generate.
+func (mr *MockOpaqueTokenGeneratorMockRecorder) Generate() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Generate", reflect.TypeOf((*MockOpaqueTokenGenerator)(nil).Generate))
+}
+
+// Hash mocks base method.
+func (m *MockOpaqueTokenGenerator) Hash(token string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Hash", token)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Hash indicates an expected call of Hash.
+func (mr *MockOpaqueTokenGeneratorMockRecorder) Hash(token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Hash", reflect.TypeOf((*MockOpaqueTokenGenerator)(nil).Hash), token)
+}
+
+// MockLinkSigner is a mock of LinkSigner interface.
+type MockLinkSigner struct {
+	ctrl     *gomock.Controller
+	recorder *MockLinkSignerMockRecorder
+}
+
+// MockLinkSignerMockRecorder is the mock recorder for MockLinkSigner.
+type MockLinkSignerMockRecorder struct {
+	mock *MockLinkSigner
+}
+
+// NewMockLinkSigner creates a new mock instance.
+func NewMockLinkSigner(ctrl *gomock.Controller) *MockLinkSigner {
+	mock := &MockLinkSigner{ctrl: ctrl}
+	mock.recorder = &MockLinkSignerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLinkSigner) EXPECT() *MockLinkSignerMockRecorder {
+	return m.recorder
+}
+
+// Sign mocks base method.
+func (m *MockLinkSigner) Sign(purpose, subject string, ttl time.Duration) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Sign", purpose, subject, ttl)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Sign indicates an expected call of Sign.
+func (mr *MockLinkSignerMockRecorder) Sign(purpose, subject, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Sign", reflect.TypeOf((*MockLinkSigner)(nil).Sign), purpose, subject, ttl)
+}
+
+// Verify mocks base method.
+func (m *MockLinkSigner) Verify(purpose, token string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Verify", purpose, token)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Verify indicates an expected call of Verify.
+func (mr *MockLinkSignerMockRecorder) Verify(purpose, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockLinkSigner)(nil).Verify), purpose, token)
+}
+
+// MockOTPAuthenticator is a mock of OTPAuthenticator interface.
+type MockOTPAuthenticator struct {
+	ctrl     *gomock.Controller
+	recorder *MockOTPAuthenticatorMockRecorder
+}
+
+// MockOTPAuthenticatorMockRecorder is the mock recorder for MockOTPAuthenticator.
+type MockOTPAuthenticatorMockRecorder struct {
+	mock *MockOTPAuthenticator
+}
+
+// NewMockOTPAuthenticator creates a new mock instance.
+func NewMockOTPAuthenticator(ctrl *gomock.Controller) *MockOTPAuthenticator {
+	mock := &MockOTPAuthenticator{ctrl: ctrl}
+	mock.recorder = &MockOTPAuthenticatorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOTPAuthenticator) EXPECT() *MockOTPAuthenticatorMockRecorder {
+	return m.recorder
+}
+
+// GenerateRecoveryCodes mocks base method.
+func (m *MockOTPAuthenticator) GenerateRecoveryCodes(n int) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GenerateRecoveryCodes", n)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GenerateRecoveryCodes indicates an expected call of GenerateRecoveryCodes.
+func (mr *MockOTPAuthenticatorMockRecorder) GenerateRecoveryCodes(n interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateRecoveryCodes", reflect.TypeOf((*MockOTPAuthenticator)(nil).GenerateRecoveryCodes), n)
+}
+
+// GenerateSecret mocks base method.
+func (m *MockOTPAuthenticator) GenerateSecret() (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GenerateSecret")
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GenerateSecret indicates an expected call of GenerateSecret.
+func (mr *MockOTPAuthenticatorMockRecorder) GenerateSecret() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateSecret", reflect.TypeOf((*MockOTPAuthenticator)(nil).GenerateSecret))
+}
+
+// ProvisioningURI mocks base method.
+func (m *MockOTPAuthenticator) ProvisioningURI(secret, account string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProvisioningURI", secret, account)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// ProvisioningURI indicates an expected call of ProvisioningURI.
+func (mr *MockOTPAuthenticatorMockRecorder) ProvisioningURI(secret, account interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProvisioningURI", reflect.TypeOf((*MockOTPAuthenticator)(nil).ProvisioningURI), secret, account)
+}
+
+// Validate mocks base method.
+func (m *MockOTPAuthenticator) Validate(secret, code string, at time.Time) (int64, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Validate", secret, code, at)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// Validate indicates an expected call of Validate.
+func (mr *MockOTPAuthenticatorMockRecorder) Validate(secret, code, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Validate", reflect.TypeOf((*MockOTPAuthenticator)(nil).Validate), secret, code, at)
+}
+
+// MockOIDCProvider is a mock of OIDCProvider interface.
+type MockOIDCProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockOIDCProviderMockRecorder
+}
+
+// MockOIDCProviderMockRecorder is the mock recorder for MockOIDCProvider.
+type MockOIDCProviderMockRecorder struct {
+	mock *MockOIDCProvider
+}
+
+// NewMockOIDCProvider creates a new mock instance.
+func NewMockOIDCProvider(ctrl *gomock.Controller) *MockOIDCProvider {
+	mock := &MockOIDCProvider{ctrl: ctrl}
+	mock.recorder = &MockOIDCProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOIDCProvider) EXPECT() *MockOIDCProviderMockRecorder {
+	return m.recorder
+}
+
+// AuthCodeURL mocks base method.
+func (m *MockOIDCProvider) AuthCodeURL(state, nonce, codeVerifier string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthCodeURL", state, nonce, codeVerifier)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// AuthCodeURL indicates an expected call of AuthCodeURL.
+func (mr *MockOIDCProviderMockRecorder) AuthCodeURL(state, nonce, codeVerifier interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthCodeURL", reflect.TypeOf((*MockOIDCProvider)(nil).AuthCodeURL), state, nonce, codeVerifier)
+}
+
+// Exchange mocks base method.
+func (m *MockOIDCProvider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (domain.ExternalIdentity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Exchange", ctx, code, codeVerifier, nonce)
+	ret0, _ := ret[0].(domain.ExternalIdentity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Exchange indicates an expected call of Exchange.
+func (mr *MockOIDCProviderMockRecorder) Exchange(ctx, code, codeVerifier, nonce interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Exchange", reflect.TypeOf((*MockOIDCProvider)(nil).Exchange), ctx, code, codeVerifier, nonce)
+}
+
+// MockAuditSink is a mock of AuditSink interface.
+type MockAuditSink struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditSinkMockRecorder
+}
+
+// MockAuditSinkMockRecorder is the mock recorder for MockAuditSink.
+type MockAuditSinkMockRecorder struct {
+	mock *MockAuditSink
+}
+
+// NewMockAuditSink creates a new mock instance.
+func NewMockAuditSink(ctrl *gomock.Controller) *MockAuditSink {
+	mock := &MockAuditSink{ctrl: ctrl}
+	mock.recorder = &MockAuditSinkMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditSink) EXPECT() *MockAuditSinkMockRecorder {
+	return m.recorder
+}
+
+// Record mocks base method.
+func (m *MockAuditSink) Record(ctx context.Context, event domain.AuditEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Record", ctx, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Record indicates an expected call of Record.
+func (mr *MockAuditSinkMockRecorder) Record(ctx, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Record", reflect.TypeOf((*MockAuditSink)(nil).Record), ctx, event)
+}
+
+// MockExportArchiver is a mock of ExportArchiver interface.
+type MockExportArchiver struct {
+	ctrl     *gomock.Controller
+	recorder *MockExportArchiverMockRecorder
+}
+
+// MockExportArchiverMockRecorder is the mock recorder for MockExportArchiver.
+type MockExportArchiverMockRecorder struct {
+	mock *MockExportArchiver
+}
+
+// NewMockExportArchiver creates a new mock instance.
+func NewMockExportArchiver(ctrl *gomock.Controller) *MockExportArchiver {
+	mock := &MockExportArchiver{ctrl: ctrl}
+	mock.recorder = &MockExportArchiverMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockExportArchiver) EXPECT() *MockExportArchiverMockRecorder {
+	return m.recorder
+}
+
+// Build mocks base method.
+func (m *MockExportArchiver) Build(data domain.AccountData) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Build", data)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Build indicates an expected call of Build.
+func (mr *MockExportArchiverMockRecorder) Build(data interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Build", reflect.TypeOf((*MockExportArchiver)(nil).Build), data)
+}
+
+// MockMailer is a mock of Mailer interface.
+type MockMailer struct {
+	ctrl     *gomock.Controller
+	recorder *MockMailerMockRecorder
+}
+
+// MockMailerMockRecorder is the mock recorder for MockMailer.
+type MockMailerMockRecorder struct {
+	mock *MockMailer
+}
+
+// NewMockMailer creates a new mock instance.
+func NewMockMailer(ctrl *gomock.Controller) *MockMailer {
+	mock := &MockMailer{ctrl: ctrl}
+	mock.recorder = &MockMailerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMailer) EXPECT() *MockMailerMockRecorder {
+	return m.recorder
+}
+
+// Send mocks base method.
+func (m *MockMailer) Send(ctx context.Context, to, subject, body string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", ctx, to, subject, body)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockMailerMockRecorder) Send(ctx, to, subject, body interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockMailer)(nil).Send), ctx, to, subject, body)
+}
+
+// MockBlobStore is a mock of BlobStore interface.
+type MockBlobStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockBlobStoreMockRecorder
+}
+
+// MockBlobStoreMockRecorder is the mock recorder for MockBlobStore.
+type MockBlobStoreMockRecorder struct {
+	mock *MockBlobStore
+}
+
+// NewMockBlobStore creates a new mock instance.
+func NewMockBlobStore(ctrl *gomock.Controller) *MockBlobStore {
+	mock := &MockBlobStore{ctrl: ctrl}
+	mock.recorder = &MockBlobStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBlobStore) EXPECT() *MockBlobStoreMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockBlobStore) Delete(ctx context.Context, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockBlobStoreMockRecorder) Delete(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockBlobStore)(nil).Delete), ctx, key)
+}
+
+// Key mocks base method.
+func (m *MockBlobStore) Key(url string) (string, bool) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Key", url)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(bool)
+	return ret0, ret1
+}
+
+// Key indicates an expected call of Key.
+func (mr *MockBlobStoreMockRecorder) Key(url interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Key", reflect.TypeOf((*MockBlobStore)(nil).Key), url)
+}
+
+// Put mocks base method.
+func (m *MockBlobStore) Put(ctx context.Context, key, contentType string, content io.Reader) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Put", ctx, key, contentType, content)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Put indicates an expected call of Put.
+func (mr *MockBlobStoreMockRecorder) Put(ctx, key, contentType, content interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Put", reflect.TypeOf((*MockBlobStore)(nil).Put), ctx, key, contentType, content)
+}
+
+// MockImageProcessor is a mock of ImageProcessor interface.
+type MockImageProcessor struct {
+	ctrl     *gomock.Controller
+	recorder *MockImageProcessorMockRecorder
+}
+
+// MockImageProcessorMockRecorder is the mock recorder for MockImageProcessor.
+type MockImageProcessorMockRecorder struct {
+	mock *MockImageProcessor
+}
+
+// NewMockImageProcessor creates a new mock instance.
+func NewMockImageProcessor(ctrl *gomock.Controller) *MockImageProcessor {
+	mock := &MockImageProcessor{ctrl: ctrl}
+	mock.recorder = &MockImageProcessorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockImageProcessor) EXPECT() *MockImageProcessorMockRecorder {
+	return m.recorder
+}
+
+// Process mocks base method.
+func (m *MockImageProcessor) Process(content io.Reader) (domain.ProcessedUpload, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Process", content)
+	ret0, _ := ret[0].(domain.ProcessedUpload)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Process indicates an expected call of Process.
+func (mr *MockImageProcessorMockRecorder) Process(content interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Process", reflect.TypeOf((*MockImageProcessor)(nil).Process), content)
+}
+
+// MockGeocoder is a mock of Geocoder interface.
+type MockGeocoder struct {
+	ctrl     *gomock.Controller
+	recorder *MockGeocoderMockRecorder
+}
+
+// MockGeocoderMockRecorder is the mock recorder for MockGeocoder.
+type MockGeocoderMockRecorder struct {
+	mock *MockGeocoder
+}
+
+// NewMockGeocoder creates a new mock instance.
+func NewMockGeocoder(ctrl *gomock.Controller) *MockGeocoder {
+	mock := &MockGeocoder{ctrl: ctrl}
+	mock.recorder = &MockGeocoderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockGeocoder) EXPECT() *MockGeocoderMockRecorder {
+	return m.recorder
+}
+
+// Geocode mocks base method.
+func (m *MockGeocoder) Geocode(name string) (domain.City, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Geocode", name)
+	ret0, _ := ret[0].(domain.City)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Geocode indicates an expected call of Geocode.
+func (mr *MockGeocoderMockRecorder) Geocode(name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Geocode", reflect.TypeOf((*MockGeocoder)(nil).Geocode), name)
+}
+
+// MockSignInGuard is a mock of SignInGuard interface.
+type MockSignInGuard struct {
+	ctrl     *gomock.Controller
+	recorder *MockSignInGuardMockRecorder
+}
+
+// MockSignInGuardMockRecorder is the mock recorder for MockSignInGuard.
+type MockSignInGuardMockRecorder struct {
+	mock *MockSignInGuard
+}
+
+// NewMockSignInGuard creates a new mock instance.
+func NewMockSignInGuard(ctrl *gomock.Controller) *MockSignInGuard {
+	mock := &MockSignInGuard{ctrl: ctrl}
+	mock.recorder = &MockSignInGuardMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSignInGuard) EXPECT() *MockSignInGuardMockRecorder {
+	return m.recorder
+}
+
+// Check mocks base method.
+func (m *MockSignInGuard) Check(ctx context.Context, email, clientIP string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Check", ctx, email, clientIP)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Check indicates an expected call of Check.
+func (mr *MockSignInGuardMockRecorder) Check(ctx, email, clientIP interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Check", reflect.TypeOf((*MockSignInGuard)(nil).Check), ctx, email, clientIP)
+}
+
+// RegisterFailure mocks base method.
+func (m *MockSignInGuard) RegisterFailure(ctx context.Context, email, clientIP string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RegisterFailure", ctx, email, clientIP)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RegisterFailure indicates an expected call of RegisterFailure.
+func (mr *MockSignInGuardMockRecorder) RegisterFailure(ctx, email, clientIP interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterFailure", reflect.TypeOf((*MockSignInGuard)(nil).RegisterFailure), ctx, email, clientIP)
+}
+
+// RegisterSuccess mocks base method.
+func (m *MockSignInGuard) RegisterSuccess(ctx context.Context, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RegisterSuccess", ctx, email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RegisterSuccess indicates an expected call of RegisterSuccess.
+func (mr *MockSignInGuardMockRecorder) RegisterSuccess(ctx, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterSuccess", reflect.TypeOf((*MockSignInGuard)(nil).RegisterSuccess), ctx, email)
+}
+
+// MockEmailVerificationSender is a mock of EmailVerificationSender interface.
+type MockEmailVerificationSender struct {
+	ctrl     *gomock.Controller
+	recorder *MockEmailVerificationSenderMockRecorder
+}
+
+// MockEmailVerificationSenderMockRecorder is the mock recorder for MockEmailVerificationSender.
+type MockEmailVerificationSenderMockRecorder struct {
+	mock *MockEmailVerificationSender
+}
+
+// NewMockEmailVerificationSender creates a new mock instance.
+func NewMockEmailVerificationSender(ctrl *gomock.Controller) *MockEmailVerificationSender {
+	mock := &MockEmailVerificationSender{ctrl: ctrl}
+	mock.recorder = &MockEmailVerificationSenderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEmailVerificationSender) EXPECT() *MockEmailVerificationSenderMockRecorder {
+	return m.recorder
+}
+
+// SendVerification mocks base method.
+func (m *MockEmailVerificationSender) SendVerification(ctx context.Context, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendVerification", ctx, email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendVerification indicates an expected call of SendVerification.
+func (mr *MockEmailVerificationSenderMockRecorder) SendVerification(ctx, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendVerification", reflect.TypeOf((*MockEmailVerificationSender)(nil).SendVerification), ctx, email)
+}
+
+// MockSessionRevoker is a mock of SessionRevoker interface.
+type MockSessionRevoker struct {
+	ctrl     *gomock.Controller
+	recorder *MockSessionRevokerMockRecorder
+}
+
+// MockSessionRevokerMockRecorder is the mock recorder for MockSessionRevoker.
+type MockSessionRevokerMockRecorder struct {
+	mock *MockSessionRevoker
+}
+
+// NewMockSessionRevoker creates a new mock instance.
+func NewMockSessionRevoker(ctrl *gomock.Controller) *MockSessionRevoker {
+	mock := &MockSessionRevoker{ctrl: ctrl}
+	mock.recorder = &MockSessionRevokerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSessionRevoker) EXPECT() *MockSessionRevokerMockRecorder {
+	return m.recorder
+}
+
+// LogoutAll mocks base method.
+func (m *MockSessionRevoker) LogoutAll(ctx context.Context, userID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LogoutAll", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LogoutAll indicates an expected call of LogoutAll.
+func (mr *MockSessionRevokerMockRecorder) LogoutAll(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LogoutAll", reflect.TypeOf((*MockSessionRevoker)(nil).LogoutAll), ctx, userID)
+}
+
+// MockSecondFactor is a mock of SecondFactor interface.
+type MockSecondFactor struct {
+	ctrl     *gomock.Controller
+	recorder *MockSecondFactorMockRecorder
+}
+
+// MockSecondFactorMockRecorder is the mock recorder for MockSecondFactor.
+type MockSecondFactorMockRecorder struct {
+	mock *MockSecondFactor
+}
+
+// NewMockSecondFactor creates a new mock instance.
+func NewMockSecondFactor(ctrl *gomock.Controller) *MockSecondFactor {
+	mock := &MockSecondFactor{ctrl: ctrl}
+	mock.recorder = &MockSecondFactorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSecondFactor) EXPECT() *MockSecondFactorMockRecorder {
+	return m.recorder
+}
+
+// Challenge mocks base method.
+func (m *MockSecondFactor) Challenge(userID uuid.UUID) domain.Token {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Challenge", userID)
+	ret0, _ := ret[0].(domain.Token)
+	return ret0
+}
+
+// Challenge indicates an expected call of Challenge.
+func (mr *MockSecondFactorMockRecorder) Challenge(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Challenge", reflect.TypeOf((*MockSecondFactor)(nil).Challenge), userID)
+}
+
+// Verify mocks base method.
+func (m *MockSecondFactor) Verify(ctx context.Context, in domain.SecondFactorSignIn) (domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Verify", ctx, in)
+	ret0, _ := ret[0].(domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Verify indicates an expected call of Verify.
+func (mr *MockSecondFactorMockRecorder) Verify(ctx, in interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockSecondFactor)(nil).Verify), ctx, in)
+}
+
+// MockSessionIssuer is a mock of SessionIssuer interface.
+type MockSessionIssuer struct {
+	ctrl     *gomock.Controller
+	recorder *MockSessionIssuerMockRecorder
+}
+
+// MockSessionIssuerMockRecorder is the mock recorder for MockSessionIssuer.
+type MockSessionIssuerMockRecorder struct {
+	mock *MockSessionIssuer
+}
+
+// NewMockSessionIssuer creates a new mock instance.
+func NewMockSessionIssuer(ctrl *gomock.Controller) *MockSessionIssuer {
+	mock := &MockSessionIssuer{ctrl: ctrl}
+	mock.recorder = &MockSessionIssuerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSessionIssuer) EXPECT() *MockSessionIssuerMockRecorder {
+	return m.recorder
+}
+
+// SignInExternal mocks base method.
+func (m *MockSessionIssuer) SignInExternal(ctx context.Context, user domain.User) (domain.Tokens, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SignInExternal", ctx, user)
+	ret0, _ := ret[0].(domain.Tokens)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SignInExternal indicates an expected call of SignInExternal.
+func (mr *MockSessionIssuerMockRecorder) SignInExternal(ctx, user interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Generate", reflect.TypeOf((*MockTokenGenerator)(nil).Generate), userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignInExternal", reflect.TypeOf((*MockSessionIssuer)(nil).SignInExternal), ctx, user)
 }
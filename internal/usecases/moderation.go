@@ -0,0 +1,59 @@
+package usecases
+
+import (
+	"context"
+
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// Moderation reviews uploaded photos held for moderation on behalf of moderators.
+type Moderation struct {
+	userAdapter         UserAdapter
+	flaggedPhotoAdapter FlaggedPhotoAdapter
+	maxPhotos           int
+}
+
+func NewModeration(userAdapter UserAdapter, flaggedPhotoAdapter FlaggedPhotoAdapter, maxPhotos int) *Moderation {
+	return &Moderation{
+		userAdapter:         userAdapter,
+		flaggedPhotoAdapter: flaggedPhotoAdapter,
+		maxPhotos:           maxPhotos,
+	}
+}
+
+// ListFlaggedPhotos returns flagged photos in the status, pending ones if the status isn't given, the oldest first.
+func (m Moderation) ListFlaggedPhotos(
+	ctx context.Context,
+	actorID uuid.UUID,
+	filter domain.FlaggedPhotoFilter,
+) ([]domain.FlaggedPhoto, error) {
+	if err := requirePermission(ctx, m.userAdapter, actorID, domain.PermissionModeratePhotos, "moderate photos"); err != nil {
+		return nil, err
+	}
+
+	if filter.Status == "" {
+		filter.Status = domain.ReviewPending
+	}
+
+	return m.flaggedPhotoAdapter.List(ctx, filter)
+}
+
+// ApprovePhoto adds the flagged photo to its dog, the dog must still have room for it.
+func (m Moderation) ApprovePhoto(ctx context.Context, actorID, photoID uuid.UUID) (domain.FlaggedPhoto, error) {
+	if err := requirePermission(ctx, m.userAdapter, actorID, domain.PermissionModeratePhotos, "moderate photos"); err != nil {
+		return domain.FlaggedPhoto{}, err
+	}
+
+	return m.flaggedPhotoAdapter.Approve(ctx, photoID, actorID, m.maxPhotos)
+}
+
+// RejectPhoto rejects the flagged photo, it's never added to its dog.
+func (m Moderation) RejectPhoto(ctx context.Context, actorID, photoID uuid.UUID) (domain.FlaggedPhoto, error) {
+	if err := requirePermission(ctx, m.userAdapter, actorID, domain.PermissionModeratePhotos, "moderate photos"); err != nil {
+		return domain.FlaggedPhoto{}, err
+	}
+
+	return m.flaggedPhotoAdapter.Reject(ctx, photoID, actorID)
+}
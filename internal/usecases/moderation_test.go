@@ -0,0 +1,137 @@
+package usecases
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+)
+
+func TestModeration_ListFlaggedPhotos(t *testing.T) {
+	controller := gomock.NewController(t)
+	userAdapterMock := NewMockUserAdapter(controller)
+	flaggedPhotoAdapterMock := NewMockFlaggedPhotoAdapter(controller)
+
+	actorID := uuid.New()
+	pagination := domain.Pagination{Page: 1, PerPage: 10}
+	photos := []domain.FlaggedPhoto{{ID: uuid.New(), Status: domain.ReviewPending}}
+
+	tests := []struct {
+		name      string
+		filter    domain.FlaggedPhotoFilter
+		mocksInit func()
+		want      []domain.FlaggedPhoto
+		wantCode  ierr.Code
+		wantErr   bool
+	}{
+		{
+			name:   "actor is not permitted",
+			filter: domain.FlaggedPhotoFilter{Pagination: pagination},
+			mocksInit: func() {
+				userAdapterMock.EXPECT().Get(gomock.Any(), actorID).Return(domain.User{ID: actorID, Role: domain.RoleUser}, nil)
+			},
+			wantCode: ierr.PermissionDenied,
+			wantErr:  true,
+		},
+		{
+			name:   "pending photos by default",
+			filter: domain.FlaggedPhotoFilter{Pagination: pagination},
+			mocksInit: func() {
+				userAdapterMock.EXPECT().Get(gomock.Any(), actorID).Return(domain.User{ID: actorID, Role: domain.RoleModerator}, nil)
+				flaggedPhotoAdapterMock.EXPECT().List(gomock.Any(), domain.FlaggedPhotoFilter{Status: domain.ReviewPending, Pagination: pagination}).
+					Return(photos, nil)
+			},
+			want:    photos,
+			wantErr: false,
+		},
+		{
+			name:   "photos in the status",
+			filter: domain.FlaggedPhotoFilter{Status: domain.ReviewRejected, Pagination: pagination},
+			mocksInit: func() {
+				userAdapterMock.EXPECT().Get(gomock.Any(), actorID).Return(domain.User{ID: actorID, Role: domain.RoleAdmin}, nil)
+				flaggedPhotoAdapterMock.EXPECT().List(gomock.Any(), domain.FlaggedPhotoFilter{Status: domain.ReviewRejected, Pagination: pagination}).
+					Return(photos, nil)
+			},
+			want:    photos,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInit()
+
+			m := NewModeration(userAdapterMock, flaggedPhotoAdapterMock, dogMaxPhotos)
+			got, err := m.ListFlaggedPhotos(context.TODO(), actorID, tt.filter)
+			assert.Equal(t, tt.wantErr, err != nil)
+			if tt.wantErr {
+				assert.Equal(t, tt.wantCode, ierr.GetCode(err))
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestModeration_ApprovePhoto(t *testing.T) {
+	controller := gomock.NewController(t)
+	userAdapterMock := NewMockUserAdapter(controller)
+	flaggedPhotoAdapterMock := NewMockFlaggedPhotoAdapter(controller)
+
+	actorID := uuid.New()
+	photoID := uuid.New()
+	approved := domain.FlaggedPhoto{ID: photoID, Status: domain.ReviewApproved, ReviewedBy: &actorID}
+
+	tests := []struct {
+		name      string
+		mocksInit func()
+		want      domain.FlaggedPhoto
+		wantCode  ierr.Code
+		wantErr   bool
+	}{
+		{
+			name: "actor is not permitted",
+			mocksInit: func() {
+				userAdapterMock.EXPECT().Get(gomock.Any(), actorID).Return(domain.User{ID: actorID, Role: domain.RoleUser}, nil)
+			},
+			wantCode: ierr.PermissionDenied,
+			wantErr:  true,
+		},
+		{
+			name: "photo is already reviewed",
+			mocksInit: func() {
+				userAdapterMock.EXPECT().Get(gomock.Any(), actorID).Return(domain.User{ID: actorID, Role: domain.RoleModerator}, nil)
+				flaggedPhotoAdapterMock.EXPECT().Approve(gomock.Any(), photoID, actorID, dogMaxPhotos).
+					Return(domain.FlaggedPhoto{}, ierr.New(ierr.AlreadyExists, "photo is already reviewed"))
+			},
+			wantCode: ierr.AlreadyExists,
+			wantErr:  true,
+		},
+		{
+			name: "success",
+			mocksInit: func() {
+				userAdapterMock.EXPECT().Get(gomock.Any(), actorID).Return(domain.User{ID: actorID, Role: domain.RoleModerator}, nil)
+				flaggedPhotoAdapterMock.EXPECT().Approve(gomock.Any(), photoID, actorID, dogMaxPhotos).Return(approved, nil)
+			},
+			want:    approved,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInit()
+
+			m := NewModeration(userAdapterMock, flaggedPhotoAdapterMock, dogMaxPhotos)
+			got, err := m.ApprovePhoto(context.TODO(), actorID, photoID)
+			assert.Equal(t, tt.wantErr, err != nil)
+			if tt.wantErr {
+				assert.Equal(t, tt.wantCode, ierr.GetCode(err))
+			}
+
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
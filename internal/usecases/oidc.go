@@ -0,0 +1,139 @@
+package usecases
+
+import (
+	"context"
+	"crypto/subtle"
+	"strings"
+	"time"
+
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+)
+
+const (
+	oidcFlowPurpose   = "oidc-flow"
+	oidcFlowSeparator = "."
+)
+
+// OIDC sign-in with external OpenID Connect provider using authorization code flow with PKCE.
+// State, nonce and code verifier are kept in signed flow token instead of server side storage.
+type OIDC struct {
+	provider        OIDCProvider
+	userAdapter     UserAdapter
+	identityAdapter UserIdentityAdapter
+	randomGenerator OpaqueTokenGenerator
+	flowSigner      LinkSigner
+	sessionIssuer   SessionIssuer
+	flowTTL         time.Duration
+}
+
+func NewOIDC(
+	provider OIDCProvider,
+	userAdapter UserAdapter,
+	identityAdapter UserIdentityAdapter,
+	randomGenerator OpaqueTokenGenerator,
+	flowSigner LinkSigner,
+	sessionIssuer SessionIssuer,
+	flowTTL time.Duration,
+) *OIDC {
+	return &OIDC{
+		provider:        provider,
+		userAdapter:     userAdapter,
+		identityAdapter: identityAdapter,
+		randomGenerator: randomGenerator,
+		flowSigner:      flowSigner,
+		sessionIssuer:   sessionIssuer,
+		flowTTL:         flowTTL,
+	}
+}
+
+// Begin returns provider login url and flow token which has to be presented on callback.
+func (o OIDC) Begin(_ context.Context) (domain.OIDCLogin, error) {
+	values := make([]string, 0, 3)
+	for i := 0; i < 3; i++ {
+		value, _, err := o.randomGenerator.Generate()
+		if err != nil {
+			return domain.OIDCLogin{}, ierr.WrapCode(ierr.Internal, err, "generating oidc flow values error")
+		}
+
+		values = append(values, value)
+	}
+
+	state, nonce, verifier := values[0], values[1], values[2]
+
+	return domain.OIDCLogin{
+		AuthURL:   o.provider.AuthCodeURL(state, nonce, verifier),
+		FlowToken: o.flowSigner.Sign(oidcFlowPurpose, strings.Join(values, oidcFlowSeparator), o.flowTTL),
+	}, nil
+}
+
+// Complete exchanges authorization code for identity, creates or links user by verified email and signs the user in.
+func (o OIDC) Complete(ctx context.Context, in domain.OIDCCallback) (domain.Tokens, error) {
+	flow, err := o.flowSigner.Verify(oidcFlowPurpose, in.FlowToken)
+	if err != nil {
+		return domain.Tokens{}, ierr.WrapCode(ierr.Unauthenticated, err, "oidc login is expired or was started in another browser")
+	}
+
+	values := strings.Split(flow, oidcFlowSeparator)
+	if len(values) != 3 || subtle.ConstantTimeCompare([]byte(values[0]), []byte(in.State)) != 1 {
+		return domain.Tokens{}, ierr.New(ierr.Unauthenticated, "oidc state mismatch")
+	}
+
+	identity, err := o.provider.Exchange(ctx, in.Code, values[2], values[1])
+	if err != nil {
+		return domain.Tokens{}, ierr.WrapCode(ierr.Unauthenticated, err, "oidc authentication failed")
+	}
+
+	user, err := o.resolveUser(ctx, identity)
+	if err != nil {
+		return domain.Tokens{}, err
+	}
+
+	return o.sessionIssuer.SignInExternal(ctx, user)
+}
+
+// resolveUser returns user the identity is linked to. Not yet linked identity is linked to the user
+// with the same verified email, the user is created if there is none. Only emails verified by provider are trusted.
+func (o OIDC) resolveUser(ctx context.Context, identity domain.ExternalIdentity) (domain.User, error) {
+	userID, err := o.identityAdapter.GetUserID(ctx, identity.Issuer, identity.Subject)
+	if err == nil {
+		return o.userAdapter.Get(ctx, userID)
+	}
+
+	if ierr.GetCode(err) != ierr.NotFound {
+		return domain.User{}, err
+	}
+
+	if identity.Email == "" || !identity.EmailVerified {
+		return domain.User{}, ierr.New(ierr.PermissionDenied, "email is not verified by identity provider")
+	}
+
+	user, err := o.userAdapter.GetByEmail(ctx, identity.Email)
+	switch {
+	case err == nil && user.EmailVerifiedAt == nil:
+		// whoever signed up with the email never proved owning it and may know the password of the account.
+		return domain.User{}, ierr.New(ierr.PermissionDenied, "account with this email exists but its email is not verified")
+	case err == nil:
+	case ierr.GetCode(err) != ierr.NotFound:
+		return domain.User{}, err
+	default:
+		// users signed up with provider have no password, it can be set with password reset.
+		if err := o.userAdapter.Create(ctx, domain.SignUp{Email: identity.Email}); err != nil {
+			return domain.User{}, err
+		}
+
+		if user, err = o.userAdapter.GetByEmail(ctx, identity.Email); err != nil {
+			return domain.User{}, err
+		}
+
+		if err := o.userAdapter.MarkEmailVerified(ctx, user.ID); err != nil {
+			return domain.User{}, err
+		}
+	}
+
+	if err := o.identityAdapter.Create(ctx, user.ID, identity); err != nil {
+		return domain.User{}, err
+	}
+
+	return user, nil
+}
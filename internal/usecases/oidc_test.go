@@ -0,0 +1,197 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+)
+
+const oidcFlowTTL = 10 * time.Minute
+
+func TestOIDC_Begin(t *testing.T) {
+	controller := gomock.NewController(t)
+	providerMock := NewMockOIDCProvider(controller)
+	randomGeneratorMock := NewMockOpaqueTokenGenerator(controller)
+	flowSignerMock := NewMockLinkSigner(controller)
+
+	gomock.InOrder(
+		randomGeneratorMock.EXPECT().Generate().Return("state", "", nil),
+		randomGeneratorMock.EXPECT().Generate().Return("nonce", "", nil),
+		randomGeneratorMock.EXPECT().Generate().Return("verifier", "", nil),
+	)
+	providerMock.EXPECT().AuthCodeURL("state", "nonce", "verifier").Return("https://idp.example.com/authorize?state=state")
+	flowSignerMock.EXPECT().Sign(oidcFlowPurpose, "state.nonce.verifier", oidcFlowTTL).Return("flow-token")
+
+	o := NewOIDC(providerMock, nil, nil, randomGeneratorMock, flowSignerMock, nil, oidcFlowTTL)
+	got, err := o.Begin(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, domain.OIDCLogin{
+		AuthURL:   "https://idp.example.com/authorize?state=state",
+		FlowToken: "flow-token",
+	}, got)
+}
+
+func TestOIDC_Complete(t *testing.T) {
+	controller := gomock.NewController(t)
+	providerMock := NewMockOIDCProvider(controller)
+	userAdapterMock := NewMockUserAdapter(controller)
+	identityAdapterMock := NewMockUserIdentityAdapter(controller)
+	flowSignerMock := NewMockLinkSigner(controller)
+	sessionIssuerMock := NewMockSessionIssuer(controller)
+
+	testingError := errors.New("testing-error")
+	userID := uuid.New()
+	email := "test@test.com"
+	verifiedAt := time.Now()
+
+	in := domain.OIDCCallback{
+		Code:      "code",
+		State:     "state",
+		FlowToken: "flow-token",
+	}
+
+	identity := domain.ExternalIdentity{
+		Issuer:        "https://idp.example.com",
+		Subject:       "248289761001",
+		Email:         email,
+		EmailVerified: true,
+	}
+
+	unverifiedIdentity := identity
+	unverifiedIdentity.EmailVerified = false
+
+	linkedUser := domain.User{ID: userID, Email: email, EmailVerifiedAt: &verifiedAt}
+	unverifiedUser := domain.User{ID: userID, Email: email}
+	tokens := domain.Tokens{Access: "access", Refresh: "refresh"}
+
+	validFlow := func() {
+		flowSignerMock.EXPECT().Verify(oidcFlowPurpose, "flow-token").Return("state.nonce.verifier", nil)
+	}
+
+	tests := []struct {
+		name      string
+		mocksInit func()
+		want      domain.Tokens
+		wantCode  ierr.Code
+		wantErr   bool
+	}{
+		{
+			name: "invalid flow token",
+			mocksInit: func() {
+				flowSignerMock.EXPECT().Verify(oidcFlowPurpose, "flow-token").Return("", testingError)
+			},
+			want:     domain.Tokens{},
+			wantCode: ierr.Unauthenticated,
+			wantErr:  true,
+		},
+		{
+			name: "state mismatch",
+			mocksInit: func() {
+				flowSignerMock.EXPECT().Verify(oidcFlowPurpose, "flow-token").Return("another.nonce.verifier", nil)
+			},
+			want:     domain.Tokens{},
+			wantCode: ierr.Unauthenticated,
+			wantErr:  true,
+		},
+		{
+			name: "code exchange error",
+			mocksInit: func() {
+				validFlow()
+				providerMock.EXPECT().Exchange(gomock.Any(), "code", "verifier", "nonce").Return(domain.ExternalIdentity{}, testingError)
+			},
+			want:     domain.Tokens{},
+			wantCode: ierr.Unauthenticated,
+			wantErr:  true,
+		},
+		{
+			name: "already linked identity",
+			mocksInit: func() {
+				validFlow()
+				providerMock.EXPECT().Exchange(gomock.Any(), "code", "verifier", "nonce").Return(identity, nil)
+				identityAdapterMock.EXPECT().GetUserID(gomock.Any(), identity.Issuer, identity.Subject).Return(userID, nil)
+				userAdapterMock.EXPECT().Get(gomock.Any(), userID).Return(linkedUser, nil)
+				sessionIssuerMock.EXPECT().SignInExternal(gomock.Any(), linkedUser).Return(tokens, nil)
+			},
+			want:    tokens,
+			wantErr: false,
+		},
+		{
+			name: "email is not verified by provider",
+			mocksInit: func() {
+				validFlow()
+				providerMock.EXPECT().Exchange(gomock.Any(), "code", "verifier", "nonce").Return(unverifiedIdentity, nil)
+				identityAdapterMock.EXPECT().GetUserID(gomock.Any(), identity.Issuer, identity.Subject).
+					Return(uuid.Nil, ierr.New(ierr.NotFound, "user identity not found"))
+			},
+			want:     domain.Tokens{},
+			wantCode: ierr.PermissionDenied,
+			wantErr:  true,
+		},
+		{
+			name: "identity is linked to existing user by email",
+			mocksInit: func() {
+				validFlow()
+				providerMock.EXPECT().Exchange(gomock.Any(), "code", "verifier", "nonce").Return(identity, nil)
+				identityAdapterMock.EXPECT().GetUserID(gomock.Any(), identity.Issuer, identity.Subject).
+					Return(uuid.Nil, ierr.New(ierr.NotFound, "user identity not found"))
+				userAdapterMock.EXPECT().GetByEmail(gomock.Any(), email).Return(linkedUser, nil)
+				identityAdapterMock.EXPECT().Create(gomock.Any(), userID, identity).Return(nil)
+				sessionIssuerMock.EXPECT().SignInExternal(gomock.Any(), linkedUser).Return(tokens, nil)
+			},
+			want:    tokens,
+			wantErr: false,
+		},
+		{
+			name: "identity isn't linked to existing user with not verified email",
+			mocksInit: func() {
+				validFlow()
+				providerMock.EXPECT().Exchange(gomock.Any(), "code", "verifier", "nonce").Return(identity, nil)
+				identityAdapterMock.EXPECT().GetUserID(gomock.Any(), identity.Issuer, identity.Subject).
+					Return(uuid.Nil, ierr.New(ierr.NotFound, "user identity not found"))
+				userAdapterMock.EXPECT().GetByEmail(gomock.Any(), email).Return(unverifiedUser, nil)
+			},
+			want:     domain.Tokens{},
+			wantCode: ierr.PermissionDenied,
+			wantErr:  true,
+		},
+		{
+			name: "user is created",
+			mocksInit: func() {
+				validFlow()
+				providerMock.EXPECT().Exchange(gomock.Any(), "code", "verifier", "nonce").Return(identity, nil)
+				identityAdapterMock.EXPECT().GetUserID(gomock.Any(), identity.Issuer, identity.Subject).
+					Return(uuid.Nil, ierr.New(ierr.NotFound, "user identity not found"))
+				gomock.InOrder(
+					userAdapterMock.EXPECT().GetByEmail(gomock.Any(), email).Return(domain.User{}, ierr.New(ierr.NotFound, "user not found")),
+					userAdapterMock.EXPECT().Create(gomock.Any(), domain.SignUp{Email: email}).Return(nil),
+					userAdapterMock.EXPECT().GetByEmail(gomock.Any(), email).Return(unverifiedUser, nil),
+				)
+				userAdapterMock.EXPECT().MarkEmailVerified(gomock.Any(), userID).Return(nil)
+				identityAdapterMock.EXPECT().Create(gomock.Any(), userID, identity).Return(nil)
+				sessionIssuerMock.EXPECT().SignInExternal(gomock.Any(), unverifiedUser).Return(tokens, nil)
+			},
+			want:    tokens,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInit()
+
+			o := NewOIDC(providerMock, userAdapterMock, identityAdapterMock, nil, flowSignerMock, sessionIssuerMock, oidcFlowTTL)
+			got, err := o.Complete(context.TODO(), in)
+			assert.Equal(t, tt.wantErr, err != nil)
+			if tt.wantErr {
+				assert.Equal(t, tt.wantCode, ierr.GetCode(err))
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
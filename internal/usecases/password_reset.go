@@ -0,0 +1,159 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+)
+
+// passwordResetDeliveryTimeout time issuing and sending of the reset link may take.
+const passwordResetDeliveryTimeout = 30 * time.Second
+
+type PasswordReset struct {
+	userAdapter       UserAdapter
+	resetTokenAdapter PasswordResetTokenAdapter
+	tokenGenerator    OpaqueTokenGenerator
+	passwordHasher    PasswordHasher
+	mailer            Mailer
+	sessionRevoker    SessionRevoker
+	auditSink         AuditSink
+	resetURL          string
+	tokenTTL          time.Duration
+}
+
+// NewPasswordReset constructor. Reset token is appended to resetURL as token query parameter.
+func NewPasswordReset(
+	userAdapter UserAdapter,
+	resetTokenAdapter PasswordResetTokenAdapter,
+	tokenGenerator OpaqueTokenGenerator,
+	passwordHasher PasswordHasher,
+	mailer Mailer,
+	sessionRevoker SessionRevoker,
+	auditSink AuditSink,
+	resetURL string,
+	tokenTTL time.Duration,
+) *PasswordReset {
+	return &PasswordReset{
+		userAdapter:       userAdapter,
+		resetTokenAdapter: resetTokenAdapter,
+		tokenGenerator:    tokenGenerator,
+		passwordHasher:    passwordHasher,
+		mailer:            mailer,
+		sessionRevoker:    sessionRevoker,
+		auditSink:         auditSink,
+		resetURL:          resetURL,
+		tokenTTL:          tokenTTL,
+	}
+}
+
+// Request emails password reset link to the user. Previously requested links stop working.
+// Unknown emails and delivery failures are not reported, so the result doesn't reveal which emails are registered.
+// The link is issued and sent in background, so the response time doesn't reveal it either.
+func (p PasswordReset) Request(ctx context.Context, email string) error {
+	user, err := p.userAdapter.GetByEmail(ctx, email)
+	if err != nil {
+		if ierr.GetCode(err) == ierr.NotFound {
+			return nil
+		}
+
+		return err
+	}
+
+	go func() {
+		// the request context is canceled as soon as the response is written.
+		ctx, cancel := context.WithTimeout(context.Background(), passwordResetDeliveryTimeout)
+		defer cancel()
+
+		if err := p.deliver(ctx, user); err != nil {
+			log.Printf("sending password reset email to %s error: %s", user.Email, err)
+		}
+	}()
+
+	return nil
+}
+
+// deliver issues a new reset token of the user and emails the link with it.
+func (p PasswordReset) deliver(ctx context.Context, user domain.User) error {
+	token, hash, err := p.tokenGenerator.Generate()
+	if err != nil {
+		return ierr.WrapCode(ierr.Internal, err, "generating password reset token error")
+	}
+
+	if err := p.resetTokenAdapter.InvalidateForUser(ctx, user.ID); err != nil {
+		return err
+	}
+
+	prt := domain.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: hash,
+		ExpiresAt: time.Now().Add(p.tokenTTL),
+	}
+
+	if err := p.resetTokenAdapter.Create(ctx, prt); err != nil {
+		return err
+	}
+
+	link, err := linkWithToken(p.resetURL, token)
+	if err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf(
+		"To set a new password follow the link:\n\n%s\n\nThe link expires in %s. If you didn't request password reset, ignore this email.",
+		link, p.tokenTTL,
+	)
+
+	return p.mailer.Send(ctx, user.Email, "Password reset", body)
+}
+
+// Confirm sets a new password and invalidates all sessions of the user.
+func (p PasswordReset) Confirm(ctx context.Context, in domain.PasswordResetConfirm) error {
+	prt, err := p.resetTokenAdapter.GetByHash(ctx, p.tokenGenerator.Hash(string(in.Token)))
+	if err != nil {
+		if ierr.GetCode(err) == ierr.NotFound {
+			return ierr.WrapCode(ierr.InvalidArgument, err, "invalid password reset token")
+		}
+
+		return err
+	}
+
+	err = p.confirm(ctx, prt, in.Password)
+	recordAudit(ctx, p.auditSink, domain.AuditEvent{Type: domain.AuditPasswordReset, UserID: &prt.UserID}, err)
+
+	return err
+}
+
+func (p PasswordReset) confirm(ctx context.Context, prt domain.PasswordResetToken, password string) error {
+	if prt.UsedAt != nil || time.Now().After(prt.ExpiresAt) {
+		return ierr.New(ierr.InvalidArgument, "password reset token is used or expired")
+	}
+
+	marked, err := p.resetTokenAdapter.MarkUsed(ctx, prt.ID)
+	if err != nil {
+		return err
+	}
+
+	if !marked {
+		return ierr.New(ierr.InvalidArgument, "password reset token is used or expired")
+	}
+
+	hash, err := p.passwordHasher.Hash(password)
+	if err != nil {
+		return ierr.WrapCode(ierr.Internal, err, "hashing password error")
+	}
+
+	if err := p.userAdapter.UpdatePasswordHash(ctx, prt.UserID, hash); err != nil {
+		return err
+	}
+
+	// reset link was received by email, so it proves the email belongs to the user.
+	if err := p.userAdapter.MarkEmailVerified(ctx, prt.UserID); err != nil {
+		return err
+	}
+
+	return p.sessionRevoker.LogoutAll(ctx, prt.UserID)
+}
@@ -0,0 +1,291 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+)
+
+const (
+	passwordResetURL      = "http://localhost:8080/password-reset"
+	passwordResetTokenTTL = time.Hour
+)
+
+// passwordResetTokenMatcher matches created reset token ignoring expiration time.
+type passwordResetTokenMatcher struct {
+	userID    uuid.UUID
+	tokenHash string
+}
+
+func (m passwordResetTokenMatcher) Matches(x interface{}) bool {
+	prt, ok := x.(domain.PasswordResetToken)
+	if !ok {
+		return false
+	}
+
+	return prt.UserID == m.userID && prt.TokenHash == m.tokenHash && prt.ExpiresAt.After(time.Now())
+}
+
+func (m passwordResetTokenMatcher) String() string {
+	return "password reset token of user " + m.userID.String()
+}
+
+// resetLinkMatcher matches email body containing reset link with the token.
+type resetLinkMatcher struct {
+	token string
+}
+
+func (m resetLinkMatcher) Matches(x interface{}) bool {
+	body, ok := x.(string)
+	return ok && strings.Contains(body, passwordResetURL+"?token="+m.token)
+}
+
+func (m resetLinkMatcher) String() string {
+	return "contains password reset link with token " + m.token
+}
+
+func TestPasswordReset_Request(t *testing.T) {
+	controller := gomock.NewController(t)
+	userAdapterMock := NewMockUserAdapter(controller)
+	resetTokenAdapterMock := NewMockPasswordResetTokenAdapter(controller)
+	tokenGeneratorMock := NewMockOpaqueTokenGenerator(controller)
+	mailerMock := NewMockMailer(controller)
+
+	email := "test@test.com"
+	user := domain.User{ID: uuid.New(), Email: email}
+	token := "reset-token"
+	tokenHash := "reset-token-hash"
+	testingError := errors.New("testing-error")
+
+	// done is closed by the last call of the background delivery.
+	var done chan struct{}
+	tokenStored := func(context.Context, domain.PasswordResetToken) { close(done) }
+	mailSent := func(context.Context, string, string, string) { close(done) }
+
+	tests := []struct {
+		name      string
+		mocksInit func()
+		wantErr   bool
+		async     bool
+	}{
+		{
+			name: "unknown email is not reported",
+			mocksInit: func() {
+				userAdapterMock.EXPECT().GetByEmail(gomock.Any(), email).
+					Return(domain.User{}, ierr.New(ierr.NotFound, "user not found"))
+			},
+			wantErr: false,
+		},
+		{
+			name: "getting user error",
+			mocksInit: func() {
+				userAdapterMock.EXPECT().GetByEmail(gomock.Any(), email).Return(domain.User{}, testingError)
+			},
+			wantErr: true,
+		},
+		{
+			name: "storing token error is not reported",
+			mocksInit: func() {
+				userAdapterMock.EXPECT().GetByEmail(gomock.Any(), email).Return(user, nil)
+				tokenGeneratorMock.EXPECT().Generate().Return(token, tokenHash, nil)
+				resetTokenAdapterMock.EXPECT().InvalidateForUser(gomock.Any(), user.ID).Return(nil)
+				resetTokenAdapterMock.EXPECT().Create(gomock.Any(), passwordResetTokenMatcher{userID: user.ID, tokenHash: tokenHash}).
+					Do(tokenStored).Return(testingError)
+			},
+			wantErr: false,
+			async:   true,
+		},
+		{
+			name: "delivery error is not reported",
+			mocksInit: func() {
+				userAdapterMock.EXPECT().GetByEmail(gomock.Any(), email).Return(user, nil)
+				tokenGeneratorMock.EXPECT().Generate().Return(token, tokenHash, nil)
+				resetTokenAdapterMock.EXPECT().InvalidateForUser(gomock.Any(), user.ID).Return(nil)
+				resetTokenAdapterMock.EXPECT().Create(gomock.Any(), passwordResetTokenMatcher{userID: user.ID, tokenHash: tokenHash}).
+					Return(nil)
+				mailerMock.EXPECT().Send(gomock.Any(), email, gomock.Any(), resetLinkMatcher{token: token}).Do(mailSent).Return(testingError)
+			},
+			wantErr: false,
+			async:   true,
+		},
+		{
+			name: "success",
+			mocksInit: func() {
+				userAdapterMock.EXPECT().GetByEmail(gomock.Any(), email).Return(user, nil)
+				tokenGeneratorMock.EXPECT().Generate().Return(token, tokenHash, nil)
+				resetTokenAdapterMock.EXPECT().InvalidateForUser(gomock.Any(), user.ID).Return(nil)
+				resetTokenAdapterMock.EXPECT().Create(gomock.Any(), passwordResetTokenMatcher{userID: user.ID, tokenHash: tokenHash}).
+					Return(nil)
+				mailerMock.EXPECT().Send(gomock.Any(), email, gomock.Any(), resetLinkMatcher{token: token}).Do(mailSent).Return(nil)
+			},
+			wantErr: false,
+			async:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			done = make(chan struct{})
+			tt.mocksInit()
+
+			p := NewPasswordReset(
+				userAdapterMock,
+				resetTokenAdapterMock,
+				tokenGeneratorMock,
+				nil,
+				mailerMock,
+				nil,
+				nil,
+				passwordResetURL,
+				passwordResetTokenTTL,
+			)
+			err := p.Request(context.TODO(), email)
+			assert.Equal(t, tt.wantErr, err != nil)
+
+			if tt.async {
+				select {
+				case <-done:
+				case <-time.After(time.Second):
+					t.Fatal("password reset email is not delivered")
+				}
+			}
+		})
+	}
+}
+
+func TestPasswordReset_Confirm(t *testing.T) {
+	controller := gomock.NewController(t)
+	auditSinkMock := NewMockAuditSink(controller)
+	auditSinkMock.EXPECT().Record(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	userAdapterMock := NewMockUserAdapter(controller)
+	resetTokenAdapterMock := NewMockPasswordResetTokenAdapter(controller)
+	tokenGeneratorMock := NewMockOpaqueTokenGenerator(controller)
+	passwordHasherMock := NewMockPasswordHasher(controller)
+	sessionRevokerMock := NewMockSessionRevoker(controller)
+
+	userID := uuid.New()
+	tokenHash := "reset-token-hash"
+	password := "new-password"
+	passwordHashed := "new-password-hashed"
+	testingError := errors.New("testing-error")
+	usedAt := time.Now()
+
+	in := domain.PasswordResetConfirm{
+		Token:    "reset-token",
+		Password: password,
+	}
+
+	validToken := domain.PasswordResetToken{
+		ID:        uuid.New(),
+		UserID:    userID,
+		TokenHash: tokenHash,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	usedToken := validToken
+	usedToken.UsedAt = &usedAt
+
+	expiredToken := validToken
+	expiredToken.ExpiresAt = time.Now().Add(-time.Minute)
+
+	tests := []struct {
+		name      string
+		mocksInit func()
+		wantCode  ierr.Code
+		wantErr   bool
+	}{
+		{
+			name: "unknown token",
+			mocksInit: func() {
+				tokenGeneratorMock.EXPECT().Hash(string(in.Token)).Return(tokenHash)
+				resetTokenAdapterMock.EXPECT().GetByHash(gomock.Any(), tokenHash).
+					Return(domain.PasswordResetToken{}, ierr.New(ierr.NotFound, "password reset token not found"))
+			},
+			wantCode: ierr.InvalidArgument,
+			wantErr:  true,
+		},
+		{
+			name: "used token",
+			mocksInit: func() {
+				tokenGeneratorMock.EXPECT().Hash(string(in.Token)).Return(tokenHash)
+				resetTokenAdapterMock.EXPECT().GetByHash(gomock.Any(), tokenHash).Return(usedToken, nil)
+			},
+			wantCode: ierr.InvalidArgument,
+			wantErr:  true,
+		},
+		{
+			name: "expired token",
+			mocksInit: func() {
+				tokenGeneratorMock.EXPECT().Hash(string(in.Token)).Return(tokenHash)
+				resetTokenAdapterMock.EXPECT().GetByHash(gomock.Any(), tokenHash).Return(expiredToken, nil)
+			},
+			wantCode: ierr.InvalidArgument,
+			wantErr:  true,
+		},
+		{
+			name: "token used concurrently",
+			mocksInit: func() {
+				tokenGeneratorMock.EXPECT().Hash(string(in.Token)).Return(tokenHash)
+				resetTokenAdapterMock.EXPECT().GetByHash(gomock.Any(), tokenHash).Return(validToken, nil)
+				resetTokenAdapterMock.EXPECT().MarkUsed(gomock.Any(), validToken.ID).Return(false, nil)
+			},
+			wantCode: ierr.InvalidArgument,
+			wantErr:  true,
+		},
+		{
+			name: "updating password error",
+			mocksInit: func() {
+				tokenGeneratorMock.EXPECT().Hash(string(in.Token)).Return(tokenHash)
+				resetTokenAdapterMock.EXPECT().GetByHash(gomock.Any(), tokenHash).Return(validToken, nil)
+				resetTokenAdapterMock.EXPECT().MarkUsed(gomock.Any(), validToken.ID).Return(true, nil)
+				passwordHasherMock.EXPECT().Hash(password).Return(passwordHashed, nil)
+				userAdapterMock.EXPECT().UpdatePasswordHash(gomock.Any(), userID, passwordHashed).
+					Return(ierr.WrapCode(ierr.Internal, testingError, "execution update query error"))
+			},
+			wantCode: ierr.Internal,
+			wantErr:  true,
+		},
+		{
+			name: "success",
+			mocksInit: func() {
+				tokenGeneratorMock.EXPECT().Hash(string(in.Token)).Return(tokenHash)
+				resetTokenAdapterMock.EXPECT().GetByHash(gomock.Any(), tokenHash).Return(validToken, nil)
+				resetTokenAdapterMock.EXPECT().MarkUsed(gomock.Any(), validToken.ID).Return(true, nil)
+				passwordHasherMock.EXPECT().Hash(password).Return(passwordHashed, nil)
+				userAdapterMock.EXPECT().UpdatePasswordHash(gomock.Any(), userID, passwordHashed).Return(nil)
+				userAdapterMock.EXPECT().MarkEmailVerified(gomock.Any(), userID).Return(nil)
+				sessionRevokerMock.EXPECT().LogoutAll(gomock.Any(), userID).Return(nil)
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInit()
+
+			p := NewPasswordReset(
+				userAdapterMock,
+				resetTokenAdapterMock,
+				tokenGeneratorMock,
+				passwordHasherMock,
+				nil,
+				sessionRevokerMock,
+				auditSinkMock,
+				passwordResetURL,
+				passwordResetTokenTTL,
+			)
+			err := p.Confirm(context.TODO(), in)
+			assert.Equal(t, tt.wantErr, err != nil)
+			if tt.wantErr {
+				assert.Equal(t, tt.wantCode, ierr.GetCode(err))
+			}
+		})
+	}
+}
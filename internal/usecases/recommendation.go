@@ -0,0 +1,242 @@
+package usecases
+
+import (
+	"context"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// distanceScaleKm distance the distance score halves at.
+	distanceScaleKm = 10.0
+	// recencyHalfLife age of the dog the recency score halves at.
+	recencyHalfLife = 14 * 24 * time.Hour
+)
+
+// Recommendation ranks the feed of the dog. Newest candidates of the feed, up to the pool size, are scored
+// by weighted components of domain.ScoreBreakdown and picked best first, diversity of each pick depends on
+// the picks above it.
+type Recommendation struct {
+	dogAdapter DogAdapter
+	weights    domain.RecommendationWeights
+	poolSize   int
+}
+
+func NewRecommendation(dogAdapter DogAdapter, weights domain.RecommendationWeights, poolSize int) *Recommendation {
+	return &Recommendation{
+		dogAdapter: dogAdapter,
+		weights:    weights,
+		poolSize:   poolSize,
+	}
+}
+
+// Recommend returns the page of ranked candidates for the user's dog to react to.
+func (r Recommendation) Recommend(
+	ctx context.Context,
+	userID, dogID uuid.UUID,
+	pagination domain.Pagination,
+) ([]domain.Recommendation, error) {
+	if pagination.After != nil {
+		return nil, ierr.New(ierr.InvalidArgument, "recommendations are paged by page number only, they aren't ordered by creation time")
+	}
+
+	dog, err := r.dogAdapter.Get(ctx, dogID)
+	if err != nil {
+		return nil, err
+	}
+
+	if dog.UserID != userID {
+		return nil, ierr.New(ierr.PermissionDenied, "cannot get recommendations of not your dog")
+	}
+
+	prefs, err := r.dogAdapter.Preferences(ctx, dogID)
+	if err != nil {
+		return nil, err
+	}
+
+	// candidates get distance to the dog.
+	filter := domain.DogFilter{Near: dog.Location, Sort: domain.SortCreatedDesc}
+	feed, err := r.dogAdapter.Feed(ctx, dog, filter, domain.Pagination{Page: 1, PerPage: r.poolSize})
+	if err != nil {
+		return nil, ierr.WrapCode(ierr.Internal, err, "getting dog feed error")
+	}
+
+	pool := feed.Dogs
+
+	ids := make([]uuid.UUID, 0, len(pool))
+	for _, candidate := range pool {
+		ids = append(ids, candidate.ID)
+	}
+
+	candidatePrefs, err := r.dogAdapter.PreferencesOf(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := r.dogAdapter.ReactionStats(ctx, dogID, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	scores := make([]domain.ScoreBreakdown, 0, len(pool))
+	for _, candidate := range pool {
+		// both ways the distance is the one between the dogs, the dog itself has none.
+		fit := preferenceFit(prefs, candidate, candidate.Distance) + preferenceFit(candidatePrefs[candidate.ID], dog, candidate.Distance)
+
+		scores = append(scores, domain.ScoreBreakdown{
+			Preference: fit / 2,
+			Distance:   distanceScore(candidate.Distance),
+			Recency:    math.Exp2(-float64(now.Sub(candidate.CreatedAt)) / float64(recencyHalfLife)),
+			LikeBack:   likeBackScore(stats[candidate.ID]),
+		})
+	}
+
+	ranked := r.rank(pool, scores)
+
+	from := pagination.PerPage * (pagination.Page - 1)
+	if from >= len(ranked) {
+		return []domain.Recommendation{}, nil
+	}
+
+	to := from + pagination.PerPage
+	if to > len(ranked) {
+		to = len(ranked)
+	}
+
+	return ranked[from:to], nil
+}
+
+// rank picks the best scored candidate one by one, diversity and total scores are set on the pick.
+func (r Recommendation) rank(pool domain.DogList, scores []domain.ScoreBreakdown) []domain.Recommendation {
+	base := make([]float64, len(scores))
+	for i, s := range scores {
+		base[i] = r.weights.Preference*s.Preference +
+			r.weights.Distance*s.Distance +
+			r.weights.Recency*s.Recency +
+			r.weights.LikeBack*s.LikeBack
+	}
+
+	breeds := make(map[string]int)
+	owners := make(map[uuid.UUID]int)
+	picked := make([]bool, len(pool))
+	ranked := make([]domain.Recommendation, 0, len(pool))
+	for len(ranked) < len(pool) {
+		best, bestDiversity := -1, 0.0
+		for i, candidate := range pool {
+			if picked[i] {
+				continue
+			}
+
+			diversity := 1 / float64(1+breeds[strings.ToLower(candidate.Breed)]+owners[candidate.UserID])
+			if best == -1 || base[i]+r.weights.Diversity*diversity > base[best]+r.weights.Diversity*bestDiversity {
+				best, bestDiversity = i, diversity
+			}
+		}
+
+		picked[best] = true
+		breeds[strings.ToLower(pool[best].Breed)]++
+		owners[pool[best].UserID]++
+
+		score := scores[best]
+		score.Diversity = bestDiversity
+		score.Total = base[best] + r.weights.Diversity*bestDiversity
+		ranked = append(ranked, domain.Recommendation{Dog: pool[best], Score: score})
+	}
+
+	return ranked
+}
+
+// preferenceFit mean fit of the dog distance km away to each set preference, 1 if none is set. Feed dogs fit
+// the set preferences already, so the fit is graded by how well they do. Unset preferences don't count,
+// dogs fitting equally well score the same however many preferences are set.
+func preferenceFit(prefs domain.DogPreferences, dog domain.Dog, distance *uint) float64 {
+	fits := make([]float64, 0, 4)
+	if prefs.Sex != "" {
+		fits = append(fits, criterionFit(dog.Sex == prefs.Sex))
+	}
+
+	if prefs.MinAge != nil || prefs.MaxAge != nil {
+		fits = append(fits, ageFit(prefs.MinAge, prefs.MaxAge, dog.Age))
+	}
+
+	if len(prefs.Breeds) > 0 {
+		breedFits := false
+		for _, breed := range prefs.Breeds {
+			breedFits = breedFits || strings.EqualFold(breed, dog.Breed)
+		}
+
+		fits = append(fits, criterionFit(breedFits))
+	}
+
+	if prefs.MaxDistanceKm != nil {
+		fits = append(fits, distanceFit(*prefs.MaxDistanceKm, distance))
+	}
+
+	if len(fits) == 0 {
+		return 1
+	}
+
+	sum := 0.0
+	for _, fit := range fits {
+		sum += fit
+	}
+
+	return sum / float64(len(fits))
+}
+
+func criterionFit(fits bool) float64 {
+	if fits {
+		return 1
+	}
+
+	return 0
+}
+
+// ageFit 1 in the middle of the age range falling to 0.5 at its bounds, 1 within the range open on one side
+// and 0 outside of the range.
+func ageFit(minAge, maxAge *uint, age uint) float64 {
+	if (minAge != nil && age < *minAge) || (maxAge != nil && age > *maxAge) {
+		return 0
+	}
+
+	if minAge == nil || maxAge == nil || *minAge == *maxAge {
+		return 1
+	}
+
+	half := float64(*maxAge-*minAge) / 2
+	return 1 - 0.5*math.Abs(float64(age-*minAge)-half)/half
+}
+
+// distanceFit 1 next to the dog falling to 0.5 at max distance, 0 further or if the distance is unknown.
+func distanceFit(maxKm uint, km *uint) float64 {
+	if km == nil || *km > maxKm {
+		return 0
+	}
+
+	if maxKm == 0 {
+		return 1
+	}
+
+	return 1 - 0.5*float64(*km)/float64(maxKm)
+}
+
+func distanceScore(km *uint) float64 {
+	if km == nil {
+		return 0
+	}
+
+	return distanceScaleKm / (distanceScaleKm + float64(*km))
+}
+
+// likeBackScore share of likes among reactions the dog gave, smoothed towards 0.5 for dogs which gave few reactions.
+// Whether the dog already liked isn't scored, the order would tell it before the dogs match.
+func likeBackScore(stats domain.ReactionStats) float64 {
+	return float64(stats.Likes+1) / float64(stats.Reactions+2)
+}
@@ -0,0 +1,304 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+)
+
+func TestRecommendation_Recommend(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	dogAdapterMock := NewMockDogAdapter(ctrl)
+
+	dogID := uuid.New()
+	userID := uuid.New()
+	ownerID := uuid.New()
+
+	testErr := errors.New("testing error")
+
+	now := time.Now()
+	distance := uint(1)
+	farDistance := uint(100)
+	location := &domain.GeoPoint{Latitude: 49.8397, Longitude: 24.0297}
+
+	dog := domain.Dog{ID: dogID, UserID: userID, Sex: domain.Male, Age: 3, Breed: "Bulldog", Location: location}
+	otherDog := domain.Dog{ID: dogID, UserID: uuid.New()}
+	prefs := domain.DogPreferences{Sex: domain.Female}
+
+	// likes most dogs it reacts to, the best one.
+	likesOften := domain.Dog{ID: uuid.New(), UserID: ownerID, Sex: domain.Female, Breed: "Poodle", Distance: &distance, CreatedAt: now}
+	// far away and a month old, the worst one.
+	farAway := domain.Dog{ID: uuid.New(), UserID: uuid.New(), Sex: domain.Female, Breed: "Husky", Distance: &farDistance, CreatedAt: now.Add(-30 * 24 * time.Hour)}
+	// same breed and owner as the best one.
+	sameBreed := domain.Dog{ID: uuid.New(), UserID: ownerID, Sex: domain.Female, Breed: "poodle", Distance: &distance, CreatedAt: now}
+	otherBreed := domain.Dog{ID: uuid.New(), UserID: uuid.New(), Sex: domain.Female, Breed: "Beagle", Distance: &distance, CreatedAt: now}
+
+	pool := domain.DogList{farAway, sameBreed, otherBreed, likesOften}
+	ids := []uuid.UUID{farAway.ID, sameBreed.ID, otherBreed.ID, likesOften.ID}
+	stats := map[uuid.UUID]domain.ReactionStats{likesOften.ID: {Likes: 9, Reactions: 10}}
+
+	filter := domain.DogFilter{Near: location, Sort: domain.SortCreatedDesc}
+	poolPag := domain.Pagination{Page: 1, PerPage: 50}
+
+	weights := domain.RecommendationWeights{Preference: 1, Distance: 1, Recency: 1, LikeBack: 1, Diversity: 1}
+
+	successMocks := func() {
+		dogAdapterMock.EXPECT().Get(gomock.Any(), gomock.Eq(dogID)).Return(dog, nil)
+		dogAdapterMock.EXPECT().Preferences(gomock.Any(), gomock.Eq(dogID)).Return(prefs, nil)
+		dogAdapterMock.EXPECT().Feed(gomock.Any(), gomock.Eq(dog), gomock.Eq(filter), gomock.Eq(poolPag)).Return(domain.DogPage{Dogs: pool}, nil)
+		dogAdapterMock.EXPECT().PreferencesOf(gomock.Any(), gomock.Eq(ids)).Return(map[uuid.UUID]domain.DogPreferences{}, nil)
+		dogAdapterMock.EXPECT().ReactionStats(gomock.Any(), gomock.Eq(dogID), gomock.Eq(ids)).Return(stats, nil)
+	}
+
+	tests := []struct {
+		name       string
+		mocksInit  func()
+		pagination domain.Pagination
+		want       []uuid.UUID
+		wantErr    bool
+		wantCode   ierr.Code
+	}{
+		{
+			name:       "paged by cursor",
+			mocksInit:  func() {},
+			pagination: domain.Pagination{Page: 1, PerPage: 10, After: &domain.Cursor{CreatedAt: now, ID: uuid.New()}},
+			wantErr:    true,
+			wantCode:   ierr.InvalidArgument,
+		},
+		{
+			name: "getting dog error",
+			mocksInit: func() {
+				dogAdapterMock.EXPECT().Get(gomock.Any(), gomock.Eq(dogID)).Return(domain.Dog{}, ierr.New(ierr.NotFound, "dog not found"))
+			},
+			pagination: domain.Pagination{Page: 1, PerPage: 10},
+			wantErr:    true,
+			wantCode:   ierr.NotFound,
+		},
+		{
+			name: "recommendations of not your dog",
+			mocksInit: func() {
+				dogAdapterMock.EXPECT().Get(gomock.Any(), gomock.Eq(dogID)).Return(otherDog, nil)
+			},
+			pagination: domain.Pagination{Page: 1, PerPage: 10},
+			wantErr:    true,
+			wantCode:   ierr.PermissionDenied,
+		},
+		{
+			name: "getting feed error",
+			mocksInit: func() {
+				dogAdapterMock.EXPECT().Get(gomock.Any(), gomock.Eq(dogID)).Return(dog, nil)
+				dogAdapterMock.EXPECT().Preferences(gomock.Any(), gomock.Eq(dogID)).Return(prefs, nil)
+				dogAdapterMock.EXPECT().Feed(gomock.Any(), gomock.Eq(dog), gomock.Eq(filter), gomock.Eq(poolPag)).Return(domain.DogPage{}, testErr)
+			},
+			pagination: domain.Pagination{Page: 1, PerPage: 10},
+			wantErr:    true,
+			wantCode:   ierr.Internal,
+		},
+		{
+			name: "getting reaction stats error",
+			mocksInit: func() {
+				dogAdapterMock.EXPECT().Get(gomock.Any(), gomock.Eq(dogID)).Return(dog, nil)
+				dogAdapterMock.EXPECT().Preferences(gomock.Any(), gomock.Eq(dogID)).Return(prefs, nil)
+				dogAdapterMock.EXPECT().Feed(gomock.Any(), gomock.Eq(dog), gomock.Eq(filter), gomock.Eq(poolPag)).Return(domain.DogPage{Dogs: pool}, nil)
+				dogAdapterMock.EXPECT().PreferencesOf(gomock.Any(), gomock.Eq(ids)).Return(map[uuid.UUID]domain.DogPreferences{}, nil)
+				dogAdapterMock.EXPECT().ReactionStats(gomock.Any(), gomock.Eq(dogID), gomock.Eq(ids)).
+					Return(nil, ierr.WrapCode(ierr.Internal, testErr, "getting reaction stats error"))
+			},
+			pagination: domain.Pagination{Page: 1, PerPage: 10},
+			wantErr:    true,
+			wantCode:   ierr.Internal,
+		},
+		{
+			name:       "ranked",
+			mocksInit:  successMocks,
+			pagination: domain.Pagination{Page: 1, PerPage: 10},
+			want:       []uuid.UUID{likesOften.ID, otherBreed.ID, sameBreed.ID, farAway.ID},
+		},
+		{
+			name:       "second page",
+			mocksInit:  successMocks,
+			pagination: domain.Pagination{Page: 2, PerPage: 3},
+			want:       []uuid.UUID{farAway.ID},
+		},
+		{
+			name:       "page after the pool",
+			mocksInit:  successMocks,
+			pagination: domain.Pagination{Page: 3, PerPage: 3},
+			want:       []uuid.UUID{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInit()
+
+			r := NewRecommendation(dogAdapterMock, weights, 50)
+			got, err := r.Recommend(context.TODO(), userID, dogID, tt.pagination)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Equal(t, tt.wantCode, ierr.GetCode(err))
+				return
+			}
+
+			assert.NoError(t, err)
+
+			gotIDs := make([]uuid.UUID, 0, len(got))
+			for _, rec := range got {
+				gotIDs = append(gotIDs, rec.Dog.ID)
+				assert.InDelta(t, rec.Score.Preference+rec.Score.Distance+rec.Score.Recency+rec.Score.LikeBack+rec.Score.Diversity, rec.Score.Total, 1e-9)
+			}
+			assert.Equal(t, tt.want, gotIDs)
+		})
+	}
+}
+
+func TestRecommendation_ScoreBreakdown(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	dogAdapterMock := NewMockDogAdapter(ctrl)
+
+	dogID := uuid.New()
+	userID := uuid.New()
+	minAge, maxAge := uint(1), uint(5)
+	distance := uint(10)
+	maxDistance := uint(20)
+
+	dog := domain.Dog{ID: dogID, UserID: userID, Sex: domain.Male, Age: 3, Breed: "Bulldog"}
+	candidate := domain.Dog{
+		ID:        uuid.New(),
+		UserID:    uuid.New(),
+		Sex:       domain.Female,
+		Age:       2,
+		Breed:     "Poodle",
+		Distance:  &distance,
+		CreatedAt: time.Now().Add(-14 * 24 * time.Hour),
+	}
+
+	dogAdapterMock.EXPECT().Get(gomock.Any(), gomock.Eq(dogID)).Return(dog, nil)
+	// sex fits, age is off the middle of the range.
+	dogAdapterMock.EXPECT().Preferences(gomock.Any(), gomock.Eq(dogID)).
+		Return(domain.DogPreferences{Sex: domain.Female, MinAge: &minAge, MaxAge: &maxAge}, nil)
+	dogAdapterMock.EXPECT().Feed(gomock.Any(), gomock.Eq(dog), gomock.Any(), gomock.Any()).Return(domain.DogPage{Dogs: domain.DogList{candidate}}, nil)
+	// breed fits, the distance is halfway to the max one.
+	dogAdapterMock.EXPECT().PreferencesOf(gomock.Any(), gomock.Any()).
+		Return(map[uuid.UUID]domain.DogPreferences{candidate.ID: {Breeds: []string{"bulldog"}, MaxDistanceKm: &maxDistance}}, nil)
+	dogAdapterMock.EXPECT().ReactionStats(gomock.Any(), gomock.Eq(dogID), gomock.Any()).
+		Return(map[uuid.UUID]domain.ReactionStats{candidate.ID: {Likes: 2, Reactions: 6}}, nil)
+
+	weights := domain.RecommendationWeights{Preference: 2, Distance: 1, Recency: 0.5, LikeBack: 1.5, Diversity: 0.5}
+	got, err := NewRecommendation(dogAdapterMock, weights, 10).Recommend(context.TODO(), userID, dogID, domain.Pagination{Page: 1, PerPage: 10})
+	assert.NoError(t, err)
+	assert.Len(t, got, 1)
+
+	score := got[0].Score
+	assert.InDelta(t, ((1+0.75)/2+(1+0.75)/2)/2, score.Preference, 1e-9)
+	assert.InDelta(t, 0.5, score.Distance, 1e-9)
+	assert.InDelta(t, 0.5, score.Recency, 1e-3)
+	assert.InDelta(t, 3.0/8, score.LikeBack, 1e-9)
+	assert.InDelta(t, 1, score.Diversity, 1e-9)
+	assert.InDelta(t, 2*score.Preference+score.Distance+0.5*score.Recency+1.5*score.LikeBack+0.5*score.Diversity, score.Total, 1e-9)
+}
+
+func TestRecommendation_UnsetPreferencesDontCount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	dogAdapterMock := NewMockDogAdapter(ctrl)
+
+	dogID := uuid.New()
+	userID := uuid.New()
+	dog := domain.Dog{ID: dogID, UserID: userID, Sex: domain.Male, Age: 3, Breed: "Bulldog"}
+
+	// both fit the dog's preferences and the dog fits theirs, one of them just has more of them set.
+	fewPrefs := domain.Dog{ID: uuid.New(), UserID: uuid.New(), Sex: domain.Female, Breed: "Poodle"}
+	manyPrefs := domain.Dog{ID: uuid.New(), UserID: uuid.New(), Sex: domain.Female, Breed: "Beagle"}
+
+	dogAdapterMock.EXPECT().Get(gomock.Any(), gomock.Eq(dogID)).Return(dog, nil)
+	dogAdapterMock.EXPECT().Preferences(gomock.Any(), gomock.Eq(dogID)).Return(domain.DogPreferences{Sex: domain.Female}, nil)
+	dogAdapterMock.EXPECT().Feed(gomock.Any(), gomock.Eq(dog), gomock.Any(), gomock.Any()).
+		Return(domain.DogPage{Dogs: domain.DogList{fewPrefs, manyPrefs}}, nil)
+	dogAdapterMock.EXPECT().PreferencesOf(gomock.Any(), gomock.Any()).
+		Return(map[uuid.UUID]domain.DogPreferences{manyPrefs.ID: {Sex: domain.Male, Breeds: []string{"bulldog"}}}, nil)
+	dogAdapterMock.EXPECT().ReactionStats(gomock.Any(), gomock.Eq(dogID), gomock.Any()).Return(nil, nil)
+
+	weights := domain.RecommendationWeights{Preference: 1}
+	got, err := NewRecommendation(dogAdapterMock, weights, 10).Recommend(context.TODO(), userID, dogID, domain.Pagination{Page: 1, PerPage: 10})
+	assert.NoError(t, err)
+	assert.Len(t, got, 2)
+	assert.InDelta(t, 1, got[0].Score.Preference, 1e-9)
+	assert.InDelta(t, got[0].Score.Preference, got[1].Score.Preference, 1e-9)
+}
+
+func TestPreferenceFit(t *testing.T) {
+	minAge, maxAge := uint(2), uint(6)
+	maxDistance := uint(20)
+	near, far := uint(2), uint(20)
+
+	dog := domain.Dog{Sex: domain.Female, Age: 4, Breed: "Poodle"}
+
+	tests := []struct {
+		name     string
+		prefs    domain.DogPreferences
+		dog      domain.Dog
+		distance *uint
+		want     float64
+	}{
+		{
+			name: "no preferences",
+			dog:  dog,
+			want: 1,
+		},
+		{
+			name:  "fitting sex",
+			prefs: domain.DogPreferences{Sex: domain.Female},
+			dog:   dog,
+			want:  1,
+		},
+		{
+			name:  "fitting sex, breed and middle of age range",
+			prefs: domain.DogPreferences{Sex: domain.Female, Breeds: []string{"poodle"}, MinAge: &minAge, MaxAge: &maxAge},
+			dog:   dog,
+			want:  1,
+		},
+		{
+			name:  "age at the range bound",
+			prefs: domain.DogPreferences{MinAge: &minAge, MaxAge: &maxAge},
+			dog:   domain.Dog{Age: 6},
+			want:  0.5,
+		},
+		{
+			name:  "age within range open on one side",
+			prefs: domain.DogPreferences{MinAge: &minAge},
+			dog:   domain.Dog{Age: 10},
+			want:  1,
+		},
+		{
+			name:     "near dog",
+			prefs:    domain.DogPreferences{MaxDistanceKm: &maxDistance},
+			dog:      dog,
+			distance: &near,
+			want:     0.95,
+		},
+		{
+			name:     "dog at max distance",
+			prefs:    domain.DogPreferences{Sex: domain.Female, MaxDistanceKm: &maxDistance},
+			dog:      dog,
+			distance: &far,
+			want:     (1 + 0.5) / 2,
+		},
+		{
+			name:  "not fitting sex",
+			prefs: domain.DogPreferences{Sex: domain.Male, Breeds: []string{"poodle"}},
+			dog:   dog,
+			want:  0.5,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.want, preferenceFit(tt.prefs, tt.dog, tt.distance), 1e-9)
+		})
+	}
+}
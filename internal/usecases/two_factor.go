@@ -0,0 +1,229 @@
+package usecases
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+
+	"github.com/google/uuid"
+)
+
+const (
+	mfaPendingPurpose = "mfa-pending"
+	recoveryCodesNum  = 10
+)
+
+// TwoFactor manages TOTP (RFC 6238) second factor and verifies the second sign-in step.
+type TwoFactor struct {
+	userAdapter         UserAdapter
+	recoveryCodeAdapter RecoveryCodeAdapter
+	authenticator       OTPAuthenticator
+	codeHasher          OpaqueTokenGenerator
+	mfaTokenSigner      LinkSigner
+	signInGuard         SignInGuard
+	mfaTokenTTL         time.Duration
+}
+
+func NewTwoFactor(
+	userAdapter UserAdapter,
+	recoveryCodeAdapter RecoveryCodeAdapter,
+	authenticator OTPAuthenticator,
+	codeHasher OpaqueTokenGenerator,
+	mfaTokenSigner LinkSigner,
+	signInGuard SignInGuard,
+	mfaTokenTTL time.Duration,
+) *TwoFactor {
+	return &TwoFactor{
+		userAdapter:         userAdapter,
+		recoveryCodeAdapter: recoveryCodeAdapter,
+		authenticator:       authenticator,
+		codeHasher:          codeHasher,
+		mfaTokenSigner:      mfaTokenSigner,
+		signInGuard:         signInGuard,
+		mfaTokenTTL:         mfaTokenTTL,
+	}
+}
+
+// Enroll generates new TOTP secret and recovery codes. Two-factor authentication isn't enabled
+// until the first code generated by the authenticator is confirmed.
+func (t TwoFactor) Enroll(ctx context.Context, userID uuid.UUID) (domain.TOTPEnrollment, error) {
+	user, err := t.userAdapter.Get(ctx, userID)
+	if err != nil {
+		return domain.TOTPEnrollment{}, err
+	}
+
+	if user.TOTPEnabledAt != nil {
+		return domain.TOTPEnrollment{}, ierr.New(ierr.AlreadyExists, "two-factor authentication is already enabled")
+	}
+
+	secret, err := t.authenticator.GenerateSecret()
+	if err != nil {
+		return domain.TOTPEnrollment{}, ierr.WrapCode(ierr.Internal, err, "generating totp secret error")
+	}
+
+	codes, err := t.authenticator.GenerateRecoveryCodes(recoveryCodesNum)
+	if err != nil {
+		return domain.TOTPEnrollment{}, ierr.WrapCode(ierr.Internal, err, "generating recovery codes error")
+	}
+
+	if err := t.userAdapter.SetTOTPSecret(ctx, userID, secret); err != nil {
+		return domain.TOTPEnrollment{}, err
+	}
+
+	hashes := make([]string, 0, len(codes))
+	for _, code := range codes {
+		hashes = append(hashes, t.hashRecoveryCode(code))
+	}
+
+	if err := t.recoveryCodeAdapter.Replace(ctx, userID, hashes); err != nil {
+		return domain.TOTPEnrollment{}, err
+	}
+
+	return domain.TOTPEnrollment{
+		Secret:          secret,
+		ProvisioningURI: t.authenticator.ProvisioningURI(secret, user.Email),
+		RecoveryCodes:   codes,
+	}, nil
+}
+
+// Confirm enables two-factor authentication once the code from the enrolled authenticator is valid.
+func (t TwoFactor) Confirm(ctx context.Context, userID uuid.UUID, code string) error {
+	user, err := t.userAdapter.Get(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if user.TOTPEnabledAt != nil {
+		return ierr.New(ierr.AlreadyExists, "two-factor authentication is already enabled")
+	}
+
+	if user.TOTPSecret == "" {
+		return ierr.New(ierr.InvalidArgument, "two-factor authentication enrollment is not started")
+	}
+
+	ok, err := t.checkCode(ctx, user, code)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return ierr.New(ierr.InvalidArgument, "invalid two-factor code")
+	}
+
+	return t.userAdapter.EnableTOTP(ctx, userID)
+}
+
+// Disable turns two-factor authentication off, either valid TOTP code or unused recovery code is required.
+func (t TwoFactor) Disable(ctx context.Context, userID uuid.UUID, code string) error {
+	user, err := t.userAdapter.Get(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if user.TOTPEnabledAt == nil {
+		return ierr.New(ierr.InvalidArgument, "two-factor authentication is not enabled")
+	}
+
+	ok, err := t.checkCode(ctx, user, code)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		if ok, err = t.recoveryCodeAdapter.Use(ctx, userID, t.hashRecoveryCode(code)); err != nil {
+			return err
+		}
+	}
+
+	if !ok {
+		return ierr.New(ierr.InvalidArgument, "invalid two-factor code")
+	}
+
+	if err := t.userAdapter.DisableTOTP(ctx, userID); err != nil {
+		return err
+	}
+
+	return t.recoveryCodeAdapter.Replace(ctx, userID, nil)
+}
+
+// Challenge returns short-lived token proving the user has passed the first sign-in step.
+func (t TwoFactor) Challenge(userID uuid.UUID) domain.Token {
+	return domain.Token(t.mfaTokenSigner.Sign(mfaPendingPurpose, userID.String(), t.mfaTokenTTL))
+}
+
+// Verify checks the second sign-in step and returns the signed-in user.
+// Wrong codes count as failed sign-in attempts, so codes can't be brute-forced.
+func (t TwoFactor) Verify(ctx context.Context, in domain.SecondFactorSignIn) (domain.User, error) {
+	subject, err := t.mfaTokenSigner.Verify(mfaPendingPurpose, string(in.MFAToken))
+	if err != nil {
+		return domain.User{}, ierr.WrapCode(ierr.Unauthenticated, err, "invalid mfa token")
+	}
+
+	userID, err := uuid.Parse(subject)
+	if err != nil {
+		return domain.User{}, ierr.WrapCode(ierr.Unauthenticated, err, "invalid mfa token")
+	}
+
+	user, err := t.userAdapter.Get(ctx, userID)
+	if err != nil {
+		if ierr.GetCode(err) == ierr.NotFound {
+			return domain.User{}, ierr.WrapCode(ierr.Unauthenticated, err, "invalid mfa token")
+		}
+
+		return domain.User{}, err
+	}
+
+	if user.TOTPEnabledAt == nil {
+		return domain.User{}, ierr.New(ierr.Unauthenticated, "invalid mfa token")
+	}
+
+	if err := t.signInGuard.Check(ctx, user.Email, in.ClientIP); err != nil {
+		return domain.User{}, err
+	}
+
+	var ok bool
+	switch {
+	case in.Code != "":
+		ok, err = t.checkCode(ctx, user, in.Code)
+	case in.RecoveryCode != "":
+		ok, err = t.recoveryCodeAdapter.Use(ctx, user.ID, t.hashRecoveryCode(in.RecoveryCode))
+	default:
+		return domain.User{}, ierr.New(ierr.InvalidArgument, "either code or recovery code is required")
+	}
+
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	if !ok {
+		if err := t.signInGuard.RegisterFailure(ctx, user.Email, in.ClientIP); err != nil {
+			return domain.User{}, err
+		}
+
+		return domain.User{}, ierr.New(ierr.Unauthenticated, "invalid two-factor code")
+	}
+
+	if err := t.signInGuard.RegisterSuccess(ctx, user.Email); err != nil {
+		return domain.User{}, err
+	}
+
+	return user, nil
+}
+
+// checkCode validates TOTP code and records its time step, so every code is accepted only once.
+func (t TwoFactor) checkCode(ctx context.Context, user domain.User, code string) (bool, error) {
+	step, ok := t.authenticator.Validate(user.TOTPSecret, code, time.Now())
+	if !ok {
+		return false, nil
+	}
+
+	return t.userAdapter.UseTOTPStep(ctx, user.ID, step)
+}
+
+// hashRecoveryCode hashes normalized recovery code, so codes are accepted regardless of case and surrounding spaces.
+func (t TwoFactor) hashRecoveryCode(code string) string {
+	return t.codeHasher.Hash(strings.ToLower(strings.TrimSpace(code)))
+}
@@ -0,0 +1,356 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+)
+
+const mfaTokenTTL = 5 * time.Minute
+
+func TestTwoFactor_Enroll(t *testing.T) {
+	controller := gomock.NewController(t)
+	userAdapterMock := NewMockUserAdapter(controller)
+	recoveryCodeAdapterMock := NewMockRecoveryCodeAdapter(controller)
+	authenticatorMock := NewMockOTPAuthenticator(controller)
+	codeHasherMock := NewMockOpaqueTokenGenerator(controller)
+
+	testingError := errors.New("testing-error")
+	userID := uuid.New()
+	email := "test@test.com"
+	secret := "JBSWY3DPEHPK3PXP"
+	uri := "otpauth://totp/Petly:test@test.com?secret=JBSWY3DPEHPK3PXP"
+	codes := []string{"aaaaa-bbbbb", "ccccc-ddddd"}
+	enabledAt := time.Now()
+
+	tests := []struct {
+		name      string
+		mocksInit func()
+		want      domain.TOTPEnrollment
+		wantCode  ierr.Code
+		wantErr   bool
+	}{
+		{
+			name: "already enabled",
+			mocksInit: func() {
+				userAdapterMock.EXPECT().Get(gomock.Any(), userID).
+					Return(domain.User{ID: userID, Email: email, TOTPEnabledAt: &enabledAt}, nil)
+			},
+			want:     domain.TOTPEnrollment{},
+			wantCode: ierr.AlreadyExists,
+			wantErr:  true,
+		},
+		{
+			name: "storing recovery codes error",
+			mocksInit: func() {
+				userAdapterMock.EXPECT().Get(gomock.Any(), userID).Return(domain.User{ID: userID, Email: email}, nil)
+				authenticatorMock.EXPECT().GenerateSecret().Return(secret, nil)
+				authenticatorMock.EXPECT().GenerateRecoveryCodes(recoveryCodesNum).Return(codes, nil)
+				userAdapterMock.EXPECT().SetTOTPSecret(gomock.Any(), userID, secret).Return(nil)
+				codeHasherMock.EXPECT().Hash(codes[0]).Return("hash-0")
+				codeHasherMock.EXPECT().Hash(codes[1]).Return("hash-1")
+				recoveryCodeAdapterMock.EXPECT().Replace(gomock.Any(), userID, []string{"hash-0", "hash-1"}).
+					Return(ierr.WrapCode(ierr.Internal, testingError, "execution insert query error"))
+			},
+			want:     domain.TOTPEnrollment{},
+			wantCode: ierr.Internal,
+			wantErr:  true,
+		},
+		{
+			name: "success",
+			mocksInit: func() {
+				userAdapterMock.EXPECT().Get(gomock.Any(), userID).Return(domain.User{ID: userID, Email: email}, nil)
+				authenticatorMock.EXPECT().GenerateSecret().Return(secret, nil)
+				authenticatorMock.EXPECT().GenerateRecoveryCodes(recoveryCodesNum).Return(codes, nil)
+				userAdapterMock.EXPECT().SetTOTPSecret(gomock.Any(), userID, secret).Return(nil)
+				codeHasherMock.EXPECT().Hash(codes[0]).Return("hash-0")
+				codeHasherMock.EXPECT().Hash(codes[1]).Return("hash-1")
+				recoveryCodeAdapterMock.EXPECT().Replace(gomock.Any(), userID, []string{"hash-0", "hash-1"}).Return(nil)
+				authenticatorMock.EXPECT().ProvisioningURI(secret, email).Return(uri)
+			},
+			want: domain.TOTPEnrollment{
+				Secret:          secret,
+				ProvisioningURI: uri,
+				RecoveryCodes:   codes,
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInit()
+
+			tf := NewTwoFactor(userAdapterMock, recoveryCodeAdapterMock, authenticatorMock, codeHasherMock, nil, nil, mfaTokenTTL)
+			got, err := tf.Enroll(context.TODO(), userID)
+			assert.Equal(t, tt.wantErr, err != nil)
+			if tt.wantErr {
+				assert.Equal(t, tt.wantCode, ierr.GetCode(err))
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestTwoFactor_Confirm(t *testing.T) {
+	controller := gomock.NewController(t)
+	userAdapterMock := NewMockUserAdapter(controller)
+	authenticatorMock := NewMockOTPAuthenticator(controller)
+
+	userID := uuid.New()
+	secret := "JBSWY3DPEHPK3PXP"
+	code := "123456"
+	step := int64(55555555)
+	user := domain.User{ID: userID, TOTPSecret: secret}
+
+	tests := []struct {
+		name      string
+		mocksInit func()
+		wantCode  ierr.Code
+		wantErr   bool
+	}{
+		{
+			name: "enrollment is not started",
+			mocksInit: func() {
+				userAdapterMock.EXPECT().Get(gomock.Any(), userID).Return(domain.User{ID: userID}, nil)
+			},
+			wantCode: ierr.InvalidArgument,
+			wantErr:  true,
+		},
+		{
+			name: "invalid code",
+			mocksInit: func() {
+				userAdapterMock.EXPECT().Get(gomock.Any(), userID).Return(user, nil)
+				authenticatorMock.EXPECT().Validate(secret, code, gomock.Any()).Return(int64(0), false)
+			},
+			wantCode: ierr.InvalidArgument,
+			wantErr:  true,
+		},
+		{
+			name: "code is already used",
+			mocksInit: func() {
+				userAdapterMock.EXPECT().Get(gomock.Any(), userID).Return(user, nil)
+				authenticatorMock.EXPECT().Validate(secret, code, gomock.Any()).Return(step, true)
+				userAdapterMock.EXPECT().UseTOTPStep(gomock.Any(), userID, step).Return(false, nil)
+			},
+			wantCode: ierr.InvalidArgument,
+			wantErr:  true,
+		},
+		{
+			name: "success",
+			mocksInit: func() {
+				userAdapterMock.EXPECT().Get(gomock.Any(), userID).Return(user, nil)
+				authenticatorMock.EXPECT().Validate(secret, code, gomock.Any()).Return(step, true)
+				userAdapterMock.EXPECT().UseTOTPStep(gomock.Any(), userID, step).Return(true, nil)
+				userAdapterMock.EXPECT().EnableTOTP(gomock.Any(), userID).Return(nil)
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInit()
+
+			tf := NewTwoFactor(userAdapterMock, nil, authenticatorMock, nil, nil, nil, mfaTokenTTL)
+			err := tf.Confirm(context.TODO(), userID, code)
+			assert.Equal(t, tt.wantErr, err != nil)
+			if tt.wantErr {
+				assert.Equal(t, tt.wantCode, ierr.GetCode(err))
+			}
+		})
+	}
+}
+
+func TestTwoFactor_Disable(t *testing.T) {
+	controller := gomock.NewController(t)
+	userAdapterMock := NewMockUserAdapter(controller)
+	recoveryCodeAdapterMock := NewMockRecoveryCodeAdapter(controller)
+	authenticatorMock := NewMockOTPAuthenticator(controller)
+	codeHasherMock := NewMockOpaqueTokenGenerator(controller)
+
+	userID := uuid.New()
+	secret := "JBSWY3DPEHPK3PXP"
+	recoveryCode := "aaaaa-bbbbb"
+	enabledAt := time.Now()
+	user := domain.User{ID: userID, TOTPSecret: secret, TOTPEnabledAt: &enabledAt}
+
+	tests := []struct {
+		name      string
+		mocksInit func()
+		wantErr   bool
+	}{
+		{
+			name: "not enabled",
+			mocksInit: func() {
+				userAdapterMock.EXPECT().Get(gomock.Any(), userID).Return(domain.User{ID: userID}, nil)
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid code",
+			mocksInit: func() {
+				userAdapterMock.EXPECT().Get(gomock.Any(), userID).Return(user, nil)
+				authenticatorMock.EXPECT().Validate(secret, recoveryCode, gomock.Any()).Return(int64(0), false)
+				codeHasherMock.EXPECT().Hash(recoveryCode).Return("hash")
+				recoveryCodeAdapterMock.EXPECT().Use(gomock.Any(), userID, "hash").Return(false, nil)
+			},
+			wantErr: true,
+		},
+		{
+			name: "success with recovery code",
+			mocksInit: func() {
+				userAdapterMock.EXPECT().Get(gomock.Any(), userID).Return(user, nil)
+				authenticatorMock.EXPECT().Validate(secret, recoveryCode, gomock.Any()).Return(int64(0), false)
+				codeHasherMock.EXPECT().Hash(recoveryCode).Return("hash")
+				recoveryCodeAdapterMock.EXPECT().Use(gomock.Any(), userID, "hash").Return(true, nil)
+				userAdapterMock.EXPECT().DisableTOTP(gomock.Any(), userID).Return(nil)
+				recoveryCodeAdapterMock.EXPECT().Replace(gomock.Any(), userID, nil).Return(nil)
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInit()
+
+			tf := NewTwoFactor(userAdapterMock, recoveryCodeAdapterMock, authenticatorMock, codeHasherMock, nil, nil, mfaTokenTTL)
+			err := tf.Disable(context.TODO(), userID, recoveryCode)
+			assert.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
+
+func TestTwoFactor_Verify(t *testing.T) {
+	controller := gomock.NewController(t)
+	userAdapterMock := NewMockUserAdapter(controller)
+	recoveryCodeAdapterMock := NewMockRecoveryCodeAdapter(controller)
+	authenticatorMock := NewMockOTPAuthenticator(controller)
+	codeHasherMock := NewMockOpaqueTokenGenerator(controller)
+	mfaTokenSignerMock := NewMockLinkSigner(controller)
+	signInGuardMock := NewMockSignInGuard(controller)
+
+	testingError := errors.New("testing-error")
+	userID := uuid.New()
+	email := "test@test.com"
+	secret := "JBSWY3DPEHPK3PXP"
+	clientIP := "127.0.0.1"
+	step := int64(55555555)
+	enabledAt := time.Now()
+	user := domain.User{ID: userID, Email: email, TOTPSecret: secret, TOTPEnabledAt: &enabledAt}
+
+	withCode := domain.SecondFactorSignIn{MFAToken: "mfatoken", Code: "123456", ClientIP: clientIP}
+	withRecoveryCode := domain.SecondFactorSignIn{MFAToken: "mfatoken", RecoveryCode: " AAAAA-bbbbb ", ClientIP: clientIP}
+
+	tests := []struct {
+		name      string
+		in        domain.SecondFactorSignIn
+		mocksInit func()
+		want      domain.User
+		wantCode  ierr.Code
+		wantErr   bool
+	}{
+		{
+			name: "invalid mfa token",
+			in:   withCode,
+			mocksInit: func() {
+				mfaTokenSignerMock.EXPECT().Verify(mfaPendingPurpose, "mfatoken").Return("", testingError)
+			},
+			want:     domain.User{},
+			wantCode: ierr.Unauthenticated,
+			wantErr:  true,
+		},
+		{
+			name: "two-factor authentication is disabled since token was issued",
+			in:   withCode,
+			mocksInit: func() {
+				mfaTokenSignerMock.EXPECT().Verify(mfaPendingPurpose, "mfatoken").Return(userID.String(), nil)
+				userAdapterMock.EXPECT().Get(gomock.Any(), userID).Return(domain.User{ID: userID, Email: email}, nil)
+			},
+			want:     domain.User{},
+			wantCode: ierr.Unauthenticated,
+			wantErr:  true,
+		},
+		{
+			name: "sign-in is locked",
+			in:   withCode,
+			mocksInit: func() {
+				mfaTokenSignerMock.EXPECT().Verify(mfaPendingPurpose, "mfatoken").Return(userID.String(), nil)
+				userAdapterMock.EXPECT().Get(gomock.Any(), userID).Return(user, nil)
+				signInGuardMock.EXPECT().Check(gomock.Any(), email, clientIP).
+					Return(ierr.New(ierr.ResourceExhausted, "too many failed sign-in attempts"))
+			},
+			want:     domain.User{},
+			wantCode: ierr.ResourceExhausted,
+			wantErr:  true,
+		},
+		{
+			name: "invalid code",
+			in:   withCode,
+			mocksInit: func() {
+				mfaTokenSignerMock.EXPECT().Verify(mfaPendingPurpose, "mfatoken").Return(userID.String(), nil)
+				userAdapterMock.EXPECT().Get(gomock.Any(), userID).Return(user, nil)
+				signInGuardMock.EXPECT().Check(gomock.Any(), email, clientIP).Return(nil)
+				authenticatorMock.EXPECT().Validate(secret, "123456", gomock.Any()).Return(int64(0), false)
+				signInGuardMock.EXPECT().RegisterFailure(gomock.Any(), email, clientIP).Return(nil)
+			},
+			want:     domain.User{},
+			wantCode: ierr.Unauthenticated,
+			wantErr:  true,
+		},
+		{
+			name: "success with code",
+			in:   withCode,
+			mocksInit: func() {
+				mfaTokenSignerMock.EXPECT().Verify(mfaPendingPurpose, "mfatoken").Return(userID.String(), nil)
+				userAdapterMock.EXPECT().Get(gomock.Any(), userID).Return(user, nil)
+				signInGuardMock.EXPECT().Check(gomock.Any(), email, clientIP).Return(nil)
+				authenticatorMock.EXPECT().Validate(secret, "123456", gomock.Any()).Return(step, true)
+				userAdapterMock.EXPECT().UseTOTPStep(gomock.Any(), userID, step).Return(true, nil)
+				signInGuardMock.EXPECT().RegisterSuccess(gomock.Any(), email).Return(nil)
+			},
+			want:    user,
+			wantErr: false,
+		},
+		{
+			name: "success with recovery code",
+			in:   withRecoveryCode,
+			mocksInit: func() {
+				mfaTokenSignerMock.EXPECT().Verify(mfaPendingPurpose, "mfatoken").Return(userID.String(), nil)
+				userAdapterMock.EXPECT().Get(gomock.Any(), userID).Return(user, nil)
+				signInGuardMock.EXPECT().Check(gomock.Any(), email, clientIP).Return(nil)
+				codeHasherMock.EXPECT().Hash("aaaaa-bbbbb").Return("hash")
+				recoveryCodeAdapterMock.EXPECT().Use(gomock.Any(), userID, "hash").Return(true, nil)
+				signInGuardMock.EXPECT().RegisterSuccess(gomock.Any(), email).Return(nil)
+			},
+			want:    user,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInit()
+
+			tf := NewTwoFactor(
+				userAdapterMock,
+				recoveryCodeAdapterMock,
+				authenticatorMock,
+				codeHasherMock,
+				mfaTokenSignerMock,
+				signInGuardMock,
+				mfaTokenTTL,
+			)
+			got, err := tf.Verify(context.TODO(), tt.in)
+			assert.Equal(t, tt.wantErr, err != nil)
+			if tt.wantErr {
+				assert.Equal(t, tt.wantCode, ierr.GetCode(err))
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,305 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+
+	"github.com/google/uuid"
+)
+
+const (
+	emailChangePurpose   = "email-change"
+	emailChangeSeparator = " "
+)
+
+// User manages profile and account of the signed-in user.
+type User struct {
+	userAdapter         UserAdapter
+	apiKeyAdapter       APIKeyAdapter
+	auditEventAdapter   AuditEventAdapter
+	passwordHasher      PasswordHasher
+	linkSigner          LinkSigner
+	mailer              Mailer
+	sessionRevoker      SessionRevoker
+	auditSink           AuditSink
+	blobStore           BlobStore
+	emailChangeURL      string
+	linkTTL             time.Duration
+	deletionGracePeriod time.Duration
+}
+
+// NewUser constructor. Email change token is appended to emailChangeURL as token query parameter.
+func NewUser(
+	userAdapter UserAdapter,
+	apiKeyAdapter APIKeyAdapter,
+	auditEventAdapter AuditEventAdapter,
+	passwordHasher PasswordHasher,
+	linkSigner LinkSigner,
+	mailer Mailer,
+	sessionRevoker SessionRevoker,
+	auditSink AuditSink,
+	blobStore BlobStore,
+	emailChangeURL string,
+	linkTTL time.Duration,
+	deletionGracePeriod time.Duration,
+) *User {
+	return &User{
+		userAdapter:         userAdapter,
+		apiKeyAdapter:       apiKeyAdapter,
+		auditEventAdapter:   auditEventAdapter,
+		passwordHasher:      passwordHasher,
+		linkSigner:          linkSigner,
+		mailer:              mailer,
+		sessionRevoker:      sessionRevoker,
+		auditSink:           auditSink,
+		blobStore:           blobStore,
+		emailChangeURL:      emailChangeURL,
+		linkTTL:             linkTTL,
+		deletionGracePeriod: deletionGracePeriod,
+	}
+}
+
+func (u User) Get(ctx context.Context, userID uuid.UUID) (domain.User, error) {
+	return u.userAdapter.Get(ctx, userID)
+}
+
+func (u User) UpdateProfile(ctx context.Context, userID uuid.UUID, profile domain.Profile) (domain.User, error) {
+	return u.userAdapter.UpdateProfile(ctx, userID, profile)
+}
+
+// SecurityEvents returns sign-ins and other security events of the user, the newest first.
+func (u User) SecurityEvents(ctx context.Context, userID uuid.UUID, pagination domain.Pagination) ([]domain.AuditEvent, error) {
+	return u.auditEventAdapter.List(ctx, domain.AuditEventFilter{UserID: &userID, Pagination: pagination})
+}
+
+// ChangePassword sets a new password and invalidates all sessions of the user.
+func (u User) ChangePassword(ctx context.Context, userID uuid.UUID, in domain.PasswordChange) error {
+	user, err := u.userAdapter.Get(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	err = u.changePassword(ctx, user, in)
+	recordAudit(ctx, u.auditSink, domain.AuditEvent{Type: domain.AuditPasswordChange, UserID: &user.ID, Email: user.Email}, err)
+
+	return err
+}
+
+func (u User) changePassword(ctx context.Context, user domain.User, in domain.PasswordChange) error {
+	if err := u.checkPassword(user, in.CurrentPassword); err != nil {
+		return err
+	}
+
+	hash, err := u.passwordHasher.Hash(in.NewPassword)
+	if err != nil {
+		return ierr.WrapCode(ierr.Internal, err, "hashing password error")
+	}
+
+	if err := u.userAdapter.UpdatePasswordHash(ctx, user.ID, hash); err != nil {
+		return err
+	}
+
+	return u.sessionRevoker.LogoutAll(ctx, user.ID)
+}
+
+// RequestEmailChange emails confirmation link to the new email, the email is changed once the link is followed.
+func (u User) RequestEmailChange(ctx context.Context, userID uuid.UUID, in domain.EmailChange) error {
+	user, err := u.userAdapter.Get(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := u.checkPassword(user, in.CurrentPassword); err != nil {
+		return err
+	}
+
+	if strings.EqualFold(user.Email, in.NewEmail) {
+		return ierr.New(ierr.InvalidArgument, "new email is the same as the current one")
+	}
+
+	exists, err := u.userAdapter.Exists(ctx, in.NewEmail)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		return ierr.New(ierr.AlreadyExists, "user with provided email already exists")
+	}
+
+	// the link is bound to the current email, so it stops working once the email is changed again.
+	subject := strings.Join([]string{user.ID.String(), user.Email, in.NewEmail}, emailChangeSeparator)
+
+	link, err := linkWithToken(u.emailChangeURL, u.linkSigner.Sign(emailChangePurpose, subject, u.linkTTL))
+	if err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf(
+		"To confirm the new email address of your Petly account follow the link:\n\n%s\n\nThe link expires in %s.",
+		link, u.linkTTL,
+	)
+
+	if err := u.mailer.Send(ctx, in.NewEmail, "Confirm your new email address", body); err != nil {
+		return ierr.WrapCode(ierr.Internal, err, "sending email change confirmation error")
+	}
+
+	return nil
+}
+
+// ConfirmEmailChange changes email of the user to the one from the confirmation link and notifies the previous email.
+func (u User) ConfirmEmailChange(ctx context.Context, token string) error {
+	subject, err := u.linkSigner.Verify(emailChangePurpose, token)
+	if err != nil {
+		return ierr.WrapCode(ierr.InvalidArgument, err, "invalid email change link")
+	}
+
+	values := strings.SplitN(subject, emailChangeSeparator, 3)
+	if len(values) != 3 {
+		return ierr.New(ierr.InvalidArgument, "invalid email change link")
+	}
+
+	userID, err := uuid.Parse(values[0])
+	if err != nil {
+		return ierr.WrapCode(ierr.InvalidArgument, err, "invalid email change link")
+	}
+
+	currentEmail, newEmail := values[1], values[2]
+
+	user, err := u.userAdapter.Get(ctx, userID)
+	if err != nil {
+		if ierr.GetCode(err) == ierr.NotFound {
+			return ierr.WrapCode(ierr.InvalidArgument, err, "invalid email change link")
+		}
+
+		return err
+	}
+
+	if strings.EqualFold(user.Email, newEmail) {
+		return nil
+	}
+
+	if !strings.EqualFold(user.Email, currentEmail) {
+		return ierr.New(ierr.InvalidArgument, "email change link is outdated, the email was changed after it was sent")
+	}
+
+	// the email could be taken by somebody else while the link was on its way.
+	exists, err := u.userAdapter.Exists(ctx, newEmail)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		return ierr.New(ierr.AlreadyExists, "user with provided email already exists")
+	}
+
+	if err := u.userAdapter.UpdateEmail(ctx, userID, newEmail); err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf(
+		"The email address of your Petly account was changed to %s. If you didn't do it, contact support immediately.",
+		newEmail,
+	)
+
+	if err := u.mailer.Send(ctx, user.Email, "Your email address was changed", body); err != nil {
+		log.Printf("sending email change notice to %s error: %s", user.Email, err)
+	}
+
+	return nil
+}
+
+// ScheduleDeletion schedules the account to be purged once the grace period passes and signs the user out
+// everywhere, API keys are revoked as well. Until then the user can sign in again and cancel the deletion.
+func (u User) ScheduleDeletion(ctx context.Context, userID uuid.UUID, in domain.AccountDeletion) (time.Time, error) {
+	user, err := u.userAdapter.Get(ctx, userID)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if user.DeletionScheduledAt != nil {
+		return time.Time{}, ierr.New(ierr.AlreadyExists, "account deletion is already scheduled")
+	}
+
+	if err := u.checkPassword(user, in.CurrentPassword); err != nil {
+		return time.Time{}, err
+	}
+
+	deleteAt := time.Now().Add(u.deletionGracePeriod)
+	if err := u.userAdapter.ScheduleDeletion(ctx, userID, deleteAt); err != nil {
+		return time.Time{}, err
+	}
+
+	if err := u.sessionRevoker.LogoutAll(ctx, userID); err != nil {
+		return time.Time{}, err
+	}
+
+	if err := u.apiKeyAdapter.RevokeAll(ctx, userID); err != nil {
+		return time.Time{}, err
+	}
+
+	body := fmt.Sprintf(
+		"Your Petly account will be deleted on %s. To keep the account sign in and cancel the deletion before then.",
+		deleteAt.UTC().Format(time.RFC1123),
+	)
+
+	if err := u.mailer.Send(ctx, user.Email, "Your account is scheduled for deletion", body); err != nil {
+		log.Printf("sending account deletion notice to %s error: %s", user.Email, err)
+	}
+
+	return deleteAt, nil
+}
+
+// CancelDeletion keeps the account pending deletion. Sessions and API keys revoked on scheduling stay revoked.
+func (u User) CancelDeletion(ctx context.Context, userID uuid.UUID) error {
+	user, err := u.userAdapter.Get(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if user.DeletionScheduledAt == nil {
+		return ierr.New(ierr.InvalidArgument, "account deletion is not scheduled")
+	}
+
+	return u.userAdapter.CancelDeletion(ctx, userID)
+}
+
+// PurgeDeleted removes accounts which grace period is over together with all their data, stored images included.
+func (u User) PurgeDeleted(ctx context.Context) error {
+	purged, err := u.userAdapter.DeleteScheduled(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+
+	deleteStoredImages(ctx, u.blobStore, purged.Images)
+
+	if purged.Count > 0 {
+		log.Printf("%d scheduled for deletion accounts purged", purged.Count)
+	}
+
+	return nil
+}
+
+// checkPassword verifies the current password. Users signed up with identity provider have no password
+// until they set one with password reset.
+func (u User) checkPassword(user domain.User, password string) error {
+	if user.PasswordHash == "" {
+		return ierr.New(ierr.InvalidArgument, "password is not set, use password reset to set it")
+	}
+
+	ok, err := u.passwordHasher.Verify(password, user.PasswordHash)
+	if err != nil {
+		return ierr.WrapCode(ierr.Internal, err, "verifying password error")
+	}
+
+	if !ok {
+		return ierr.New(ierr.PermissionDenied, "current password is wrong")
+	}
+
+	return nil
+}
@@ -0,0 +1,455 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/valerii-smirnov/petli-test-task/internal/domain"
+	"github.com/valerii-smirnov/petli-test-task/pkg/errors/ierr"
+)
+
+const (
+	emailChangeURL      = "http://localhost:8080/api/me/email/confirm"
+	emailChangeTTL      = time.Hour
+	deletionGracePeriod = 30 * 24 * time.Hour
+)
+
+// mailBodyMatcher matches email body containing the substring.
+type mailBodyMatcher struct {
+	substr string
+}
+
+func (m mailBodyMatcher) Matches(x interface{}) bool {
+	body, ok := x.(string)
+	return ok && strings.Contains(body, m.substr)
+}
+
+func (m mailBodyMatcher) String() string {
+	return "contains " + m.substr
+}
+
+func TestUser_ChangePassword(t *testing.T) {
+	controller := gomock.NewController(t)
+	userAdapterMock := NewMockUserAdapter(controller)
+	passwordHasherMock := NewMockPasswordHasher(controller)
+	sessionRevokerMock := NewMockSessionRevoker(controller)
+	auditSinkMock := NewMockAuditSink(controller)
+	auditSinkMock.EXPECT().Record(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	userID := uuid.New()
+	user := domain.User{ID: userID, Email: "test@test.com", PasswordHash: "currenthash"}
+	in := domain.PasswordChange{CurrentPassword: "current", NewPassword: "new"}
+
+	tests := []struct {
+		name      string
+		mocksInit func()
+		wantCode  ierr.Code
+		wantErr   bool
+	}{
+		{
+			name: "password is not set",
+			mocksInit: func() {
+				userAdapterMock.EXPECT().Get(gomock.Any(), userID).Return(domain.User{ID: userID}, nil)
+			},
+			wantCode: ierr.InvalidArgument,
+			wantErr:  true,
+		},
+		{
+			name: "wrong current password",
+			mocksInit: func() {
+				userAdapterMock.EXPECT().Get(gomock.Any(), userID).Return(user, nil)
+				passwordHasherMock.EXPECT().Verify(in.CurrentPassword, user.PasswordHash).Return(false, nil)
+			},
+			wantCode: ierr.PermissionDenied,
+			wantErr:  true,
+		},
+		{
+			name: "success",
+			mocksInit: func() {
+				userAdapterMock.EXPECT().Get(gomock.Any(), userID).Return(user, nil)
+				passwordHasherMock.EXPECT().Verify(in.CurrentPassword, user.PasswordHash).Return(true, nil)
+				passwordHasherMock.EXPECT().Hash(in.NewPassword).Return("newhash", nil)
+				userAdapterMock.EXPECT().UpdatePasswordHash(gomock.Any(), userID, "newhash").Return(nil)
+				sessionRevokerMock.EXPECT().LogoutAll(gomock.Any(), userID).Return(nil)
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInit()
+
+			u := NewUser(userAdapterMock, nil, nil, passwordHasherMock, nil, nil, sessionRevokerMock, auditSinkMock, nil, emailChangeURL, emailChangeTTL, deletionGracePeriod)
+			err := u.ChangePassword(context.TODO(), userID, in)
+			assert.Equal(t, tt.wantErr, err != nil)
+			if tt.wantErr {
+				assert.Equal(t, tt.wantCode, ierr.GetCode(err))
+			}
+		})
+	}
+}
+
+func TestUser_RequestEmailChange(t *testing.T) {
+	controller := gomock.NewController(t)
+	userAdapterMock := NewMockUserAdapter(controller)
+	passwordHasherMock := NewMockPasswordHasher(controller)
+	linkSignerMock := NewMockLinkSigner(controller)
+	mailerMock := NewMockMailer(controller)
+
+	testingError := errors.New("testing-error")
+	userID := uuid.New()
+	user := domain.User{ID: userID, Email: "test@test.com", PasswordHash: "currenthash"}
+	in := domain.EmailChange{NewEmail: "new@test.com", CurrentPassword: "current"}
+
+	validPassword := func() {
+		userAdapterMock.EXPECT().Get(gomock.Any(), userID).Return(user, nil)
+		passwordHasherMock.EXPECT().Verify(in.CurrentPassword, user.PasswordHash).Return(true, nil)
+	}
+
+	tests := []struct {
+		name      string
+		in        domain.EmailChange
+		mocksInit func()
+		wantCode  ierr.Code
+		wantErr   bool
+	}{
+		{
+			name: "same email",
+			in:   domain.EmailChange{NewEmail: "TEST@test.com", CurrentPassword: "current"},
+			mocksInit: func() {
+				validPassword()
+			},
+			wantCode: ierr.InvalidArgument,
+			wantErr:  true,
+		},
+		{
+			name: "email is taken",
+			in:   in,
+			mocksInit: func() {
+				validPassword()
+				userAdapterMock.EXPECT().Exists(gomock.Any(), in.NewEmail).Return(true, nil)
+			},
+			wantCode: ierr.AlreadyExists,
+			wantErr:  true,
+		},
+		{
+			name: "sending email error",
+			in:   in,
+			mocksInit: func() {
+				validPassword()
+				userAdapterMock.EXPECT().Exists(gomock.Any(), in.NewEmail).Return(false, nil)
+				linkSignerMock.EXPECT().Sign(emailChangePurpose, userID.String()+" "+user.Email+" "+in.NewEmail, emailChangeTTL).Return("token")
+				mailerMock.EXPECT().Send(gomock.Any(), in.NewEmail, gomock.Any(), gomock.Any()).Return(testingError)
+			},
+			wantCode: ierr.Internal,
+			wantErr:  true,
+		},
+		{
+			name: "success",
+			in:   in,
+			mocksInit: func() {
+				validPassword()
+				userAdapterMock.EXPECT().Exists(gomock.Any(), in.NewEmail).Return(false, nil)
+				linkSignerMock.EXPECT().Sign(emailChangePurpose, userID.String()+" "+user.Email+" "+in.NewEmail, emailChangeTTL).Return("token")
+				mailerMock.EXPECT().Send(gomock.Any(), in.NewEmail, gomock.Any(), mailBodyMatcher{substr: emailChangeURL + "?token=token"}).Return(nil)
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInit()
+
+			u := NewUser(userAdapterMock, nil, nil, passwordHasherMock, linkSignerMock, mailerMock, nil, nil, nil, emailChangeURL, emailChangeTTL, deletionGracePeriod)
+			err := u.RequestEmailChange(context.TODO(), userID, tt.in)
+			assert.Equal(t, tt.wantErr, err != nil)
+			if tt.wantErr {
+				assert.Equal(t, tt.wantCode, ierr.GetCode(err))
+			}
+		})
+	}
+}
+
+func TestUser_ConfirmEmailChange(t *testing.T) {
+	controller := gomock.NewController(t)
+	userAdapterMock := NewMockUserAdapter(controller)
+	linkSignerMock := NewMockLinkSigner(controller)
+	mailerMock := NewMockMailer(controller)
+
+	testingError := errors.New("testing-error")
+	userID := uuid.New()
+	user := domain.User{ID: userID, Email: "test@test.com"}
+	newEmail := "new@test.com"
+	subject := userID.String() + " " + user.Email + " " + newEmail
+
+	tests := []struct {
+		name      string
+		mocksInit func()
+		wantCode  ierr.Code
+		wantErr   bool
+	}{
+		{
+			name: "invalid link",
+			mocksInit: func() {
+				linkSignerMock.EXPECT().Verify(emailChangePurpose, "token").Return("", testingError)
+			},
+			wantCode: ierr.InvalidArgument,
+			wantErr:  true,
+		},
+		{
+			name: "email was taken meanwhile",
+			mocksInit: func() {
+				linkSignerMock.EXPECT().Verify(emailChangePurpose, "token").Return(subject, nil)
+				userAdapterMock.EXPECT().Get(gomock.Any(), userID).Return(user, nil)
+				userAdapterMock.EXPECT().Exists(gomock.Any(), newEmail).Return(true, nil)
+			},
+			wantCode: ierr.AlreadyExists,
+			wantErr:  true,
+		},
+		{
+			name: "link of previous email",
+			mocksInit: func() {
+				linkSignerMock.EXPECT().Verify(emailChangePurpose, "token").Return(subject, nil)
+				userAdapterMock.EXPECT().Get(gomock.Any(), userID).Return(domain.User{ID: userID, Email: "another@test.com"}, nil)
+			},
+			wantCode: ierr.InvalidArgument,
+			wantErr:  true,
+		},
+		{
+			name: "already changed",
+			mocksInit: func() {
+				linkSignerMock.EXPECT().Verify(emailChangePurpose, "token").Return(subject, nil)
+				userAdapterMock.EXPECT().Get(gomock.Any(), userID).Return(domain.User{ID: userID, Email: "NEW@test.com"}, nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "success notifies previous email",
+			mocksInit: func() {
+				linkSignerMock.EXPECT().Verify(emailChangePurpose, "token").Return(subject, nil)
+				userAdapterMock.EXPECT().Get(gomock.Any(), userID).Return(user, nil)
+				userAdapterMock.EXPECT().Exists(gomock.Any(), newEmail).Return(false, nil)
+				userAdapterMock.EXPECT().UpdateEmail(gomock.Any(), userID, newEmail).Return(nil)
+				mailerMock.EXPECT().Send(gomock.Any(), user.Email, gomock.Any(), mailBodyMatcher{substr: newEmail}).Return(testingError)
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInit()
+
+			u := NewUser(userAdapterMock, nil, nil, nil, linkSignerMock, mailerMock, nil, nil, nil, emailChangeURL, emailChangeTTL, deletionGracePeriod)
+			err := u.ConfirmEmailChange(context.TODO(), "token")
+			assert.Equal(t, tt.wantErr, err != nil)
+			if tt.wantErr {
+				assert.Equal(t, tt.wantCode, ierr.GetCode(err))
+			}
+		})
+	}
+}
+
+func TestUser_ScheduleDeletion(t *testing.T) {
+	controller := gomock.NewController(t)
+	userAdapterMock := NewMockUserAdapter(controller)
+	apiKeyAdapterMock := NewMockAPIKeyAdapter(controller)
+	passwordHasherMock := NewMockPasswordHasher(controller)
+	mailerMock := NewMockMailer(controller)
+	sessionRevokerMock := NewMockSessionRevoker(controller)
+
+	userID := uuid.New()
+	scheduledAt := time.Now().Add(time.Hour)
+	user := domain.User{ID: userID, Email: "test@test.com", PasswordHash: "currenthash"}
+	in := domain.AccountDeletion{CurrentPassword: "current"}
+
+	tests := []struct {
+		name      string
+		mocksInit func()
+		wantCode  ierr.Code
+		wantErr   bool
+	}{
+		{
+			name: "already scheduled",
+			mocksInit: func() {
+				userAdapterMock.EXPECT().Get(gomock.Any(), userID).
+					Return(domain.User{ID: userID, PasswordHash: "currenthash", DeletionScheduledAt: &scheduledAt}, nil)
+			},
+			wantCode: ierr.AlreadyExists,
+			wantErr:  true,
+		},
+		{
+			name: "wrong current password",
+			mocksInit: func() {
+				userAdapterMock.EXPECT().Get(gomock.Any(), userID).Return(user, nil)
+				passwordHasherMock.EXPECT().Verify(in.CurrentPassword, user.PasswordHash).Return(false, nil)
+			},
+			wantCode: ierr.PermissionDenied,
+			wantErr:  true,
+		},
+		{
+			name: "revoking sessions error",
+			mocksInit: func() {
+				userAdapterMock.EXPECT().Get(gomock.Any(), userID).Return(user, nil)
+				passwordHasherMock.EXPECT().Verify(in.CurrentPassword, user.PasswordHash).Return(true, nil)
+				userAdapterMock.EXPECT().ScheduleDeletion(gomock.Any(), userID, gomock.Any()).Return(nil)
+				sessionRevokerMock.EXPECT().LogoutAll(gomock.Any(), userID).Return(ierr.New(ierr.Internal, "testing error"))
+			},
+			wantCode: ierr.Internal,
+			wantErr:  true,
+		},
+		{
+			name: "notice is not sent",
+			mocksInit: func() {
+				userAdapterMock.EXPECT().Get(gomock.Any(), userID).Return(user, nil)
+				passwordHasherMock.EXPECT().Verify(in.CurrentPassword, user.PasswordHash).Return(true, nil)
+				userAdapterMock.EXPECT().ScheduleDeletion(gomock.Any(), userID, gomock.Any()).Return(nil)
+				sessionRevokerMock.EXPECT().LogoutAll(gomock.Any(), userID).Return(nil)
+				apiKeyAdapterMock.EXPECT().RevokeAll(gomock.Any(), userID).Return(nil)
+				mailerMock.EXPECT().Send(gomock.Any(), user.Email, gomock.Any(), gomock.Any()).Return(errors.New("smtp error"))
+			},
+			wantErr: false,
+		},
+		{
+			name: "success",
+			mocksInit: func() {
+				userAdapterMock.EXPECT().Get(gomock.Any(), userID).Return(user, nil)
+				passwordHasherMock.EXPECT().Verify(in.CurrentPassword, user.PasswordHash).Return(true, nil)
+				userAdapterMock.EXPECT().ScheduleDeletion(gomock.Any(), userID, gomock.Any()).Return(nil)
+				sessionRevokerMock.EXPECT().LogoutAll(gomock.Any(), userID).Return(nil)
+				apiKeyAdapterMock.EXPECT().RevokeAll(gomock.Any(), userID).Return(nil)
+				mailerMock.EXPECT().Send(gomock.Any(), user.Email, gomock.Any(), mailBodyMatcher{substr: "cancel the deletion"}).Return(nil)
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInit()
+
+			u := NewUser(
+				userAdapterMock,
+				apiKeyAdapterMock,
+				nil,
+				passwordHasherMock,
+				nil,
+				mailerMock,
+				sessionRevokerMock,
+				nil,
+				nil,
+				emailChangeURL,
+				emailChangeTTL,
+				deletionGracePeriod,
+			)
+
+			deleteAt, err := u.ScheduleDeletion(context.TODO(), userID, in)
+			assert.Equal(t, tt.wantErr, err != nil)
+			if tt.wantErr {
+				assert.Equal(t, tt.wantCode, ierr.GetCode(err))
+				return
+			}
+
+			assert.WithinDuration(t, time.Now().Add(deletionGracePeriod), deleteAt, time.Minute)
+		})
+	}
+}
+
+func TestUser_CancelDeletion(t *testing.T) {
+	controller := gomock.NewController(t)
+	userAdapterMock := NewMockUserAdapter(controller)
+
+	userID := uuid.New()
+	scheduledAt := time.Now().Add(time.Hour)
+
+	tests := []struct {
+		name      string
+		mocksInit func()
+		wantCode  ierr.Code
+		wantErr   bool
+	}{
+		{
+			name: "not scheduled",
+			mocksInit: func() {
+				userAdapterMock.EXPECT().Get(gomock.Any(), userID).Return(domain.User{ID: userID}, nil)
+			},
+			wantCode: ierr.InvalidArgument,
+			wantErr:  true,
+		},
+		{
+			name: "success",
+			mocksInit: func() {
+				userAdapterMock.EXPECT().Get(gomock.Any(), userID).
+					Return(domain.User{ID: userID, DeletionScheduledAt: &scheduledAt}, nil)
+				userAdapterMock.EXPECT().CancelDeletion(gomock.Any(), userID).Return(nil)
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInit()
+
+			u := NewUser(userAdapterMock, nil, nil, nil, nil, nil, nil, nil, nil, emailChangeURL, emailChangeTTL, deletionGracePeriod)
+			err := u.CancelDeletion(context.TODO(), userID)
+			assert.Equal(t, tt.wantErr, err != nil)
+			if tt.wantErr {
+				assert.Equal(t, tt.wantCode, ierr.GetCode(err))
+			}
+		})
+	}
+}
+
+func TestUser_PurgeDeleted(t *testing.T) {
+	controller := gomock.NewController(t)
+	userAdapterMock := NewMockUserAdapter(controller)
+	blobStoreMock := NewMockBlobStore(controller)
+
+	stored := domain.DogImages{
+		Thumb: "http://localhost:8080/media/dogs/1/thumb.webp",
+		Card:  "http://localhost:8080/media/dogs/1/card.webp",
+		Full:  "http://localhost:8080/media/dogs/1/full.webp",
+	}
+	external := domain.ExternalDogImages("https://example.com/spike.jpg")
+
+	tests := []struct {
+		name      string
+		mocksInit func()
+		wantErr   bool
+	}{
+		{
+			name: "purging error",
+			mocksInit: func() {
+				userAdapterMock.EXPECT().DeleteScheduled(gomock.Any(), gomock.Any()).
+					Return(domain.PurgedAccounts{}, ierr.New(ierr.Internal, "testing error"))
+			},
+			wantErr: true,
+		},
+		{
+			name: "stored images are deleted",
+			mocksInit: func() {
+				userAdapterMock.EXPECT().DeleteScheduled(gomock.Any(), gomock.Any()).
+					Return(domain.PurgedAccounts{Count: 1, Images: []domain.DogImages{stored, external, stored}}, nil)
+				blobStoreMock.EXPECT().Key(external.Full).Return("", false).Times(3)
+				for _, key := range []string{"dogs/1/thumb.webp", "dogs/1/card.webp", "dogs/1/full.webp"} {
+					blobStoreMock.EXPECT().Key("http://localhost:8080/media/"+key).Return(key, true).Times(2)
+					blobStoreMock.EXPECT().Delete(gomock.Any(), key).Return(nil)
+				}
+			},
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mocksInit()
+
+			u := NewUser(userAdapterMock, nil, nil, nil, nil, nil, nil, nil, blobStoreMock, emailChangeURL, emailChangeTTL, deletionGracePeriod)
+			err := u.PurgeDeleted(context.TODO())
+			assert.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
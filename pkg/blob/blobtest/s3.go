@@ -0,0 +1,100 @@
+// Package blobtest provides local stub S3 compatible storage for tests, a stand-in for MinIO.
+package blobtest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+)
+
+// Object stored in the stub storage.
+type Object struct {
+	Content     []byte
+	ContentType string
+}
+
+// S3 stub storage with a single bucket. Only object PUT, GET and DELETE with path-style addressing are supported.
+// Requests must be signed with the access key and carry hash of the payload, the signature itself isn't verified.
+type S3 struct {
+	*httptest.Server
+
+	Bucket    string
+	AccessKey string
+
+	mu      sync.Mutex
+	objects map[string]Object
+}
+
+func NewS3(bucket, accessKey string) *S3 {
+	s := &S3{
+		Bucket:    bucket,
+		AccessKey: accessKey,
+		objects:   make(map[string]Object),
+	}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+
+	return s
+}
+
+// Object returns stored object by the key.
+func (s *S3) Object(key string) (Object, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	object, ok := s.objects[key]
+
+	return object, ok
+}
+
+func (s *S3) handle(w http.ResponseWriter, r *http.Request) {
+	key := strings.TrimPrefix(r.URL.Path, "/"+s.Bucket+"/")
+	if key == r.URL.Path || key == "" {
+		http.Error(w, "NoSuchBucket", http.StatusNotFound)
+		return
+	}
+
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential="+s.AccessKey+"/") {
+		http.Error(w, "InvalidAccessKeyId", http.StatusForbidden)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	sum := sha256.Sum256(body)
+	if r.Header.Get("X-Amz-Content-Sha256") != hex.EncodeToString(sum[:]) {
+		http.Error(w, "XAmzContentSHA256Mismatch", http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodPut:
+		s.objects[key] = Object{Content: body, ContentType: r.Header.Get("Content-Type")}
+		w.WriteHeader(http.StatusOK)
+	case http.MethodGet:
+		object, ok := s.objects[key]
+		if !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", object.ContentType)
+		_, _ = w.Write(object.Content)
+	case http.MethodDelete:
+		delete(s.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "MethodNotAllowed", http.StatusMethodNotAllowed)
+	}
+}
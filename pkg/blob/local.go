@@ -0,0 +1,100 @@
+package blob
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Local stores files in the directory on local filesystem, the app serves them at baseURL.
+// Suitable for local development and single-instance deployments.
+type Local struct {
+	dir     string
+	baseURL string
+}
+
+func NewLocal(dir, baseURL string) *Local {
+	return &Local{
+		dir:     dir,
+		baseURL: strings.TrimRight(baseURL, "/"),
+	}
+}
+
+// Put writes the file under the key, the file is replaced atomically, so it is never served half-written.
+func (s Local) Put(_ context.Context, key, _ string, content io.Reader) (string, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", fmt.Errorf("creating directory of %s error: %w", key, err)
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return "", fmt.Errorf("creating temporary file error: %w", err)
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := io.Copy(f, content); err != nil {
+		f.Close()
+		return "", fmt.Errorf("writing %s error: %w", key, err)
+	}
+
+	if err := f.Close(); err != nil {
+		return "", fmt.Errorf("writing %s error: %w", key, err)
+	}
+
+	if err := os.Chmod(f.Name(), 0o644); err != nil {
+		return "", fmt.Errorf("changing mode of %s error: %w", key, err)
+	}
+
+	if err := os.Rename(f.Name(), path); err != nil {
+		return "", fmt.Errorf("moving %s in place error: %w", key, err)
+	}
+
+	return s.baseURL + "/" + key, nil
+}
+
+// Delete removes the file, missing file isn't an error.
+func (s Local) Delete(_ context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("deleting %s error: %w", key, err)
+	}
+
+	return nil
+}
+
+// Key returns key of the file served at the URL.
+func (s Local) Key(url string) (string, bool) {
+	return keyOf(s.baseURL, url)
+}
+
+// path returns path of the file, keys pointing outside the directory are rejected.
+func (s Local) path(key string) (string, error) {
+	if !fs.ValidPath(key) || key == "." {
+		return "", fmt.Errorf("invalid key %q", key)
+	}
+
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
+
+// keyOf returns key of the file served at the URL under baseURL.
+func keyOf(baseURL, url string) (string, bool) {
+	key := strings.TrimPrefix(url, baseURL+"/")
+	if key == url || key == "" {
+		return "", false
+	}
+
+	return key, true
+}
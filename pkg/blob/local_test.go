@@ -0,0 +1,43 @@
+package blob
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocal_PutDelete(t *testing.T) {
+	dir := t.TempDir()
+	s := NewLocal(dir, "http://localhost:8080/media/")
+
+	url, err := s.Put(context.TODO(), "dogs/spike.jpg", "image/jpeg", bytes.NewReader([]byte("jpeg")))
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost:8080/media/dogs/spike.jpg", url)
+
+	key, ok := s.Key(url)
+	assert.True(t, ok)
+	assert.Equal(t, "dogs/spike.jpg", key)
+
+	_, ok = s.Key("https://example.com/media/dogs/spike.jpg")
+	assert.False(t, ok)
+
+	content, err := os.ReadFile(filepath.Join(dir, "dogs", "spike.jpg"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("jpeg"), content)
+
+	require.NoError(t, s.Delete(context.TODO(), "dogs/spike.jpg"))
+	_, err = os.Stat(filepath.Join(dir, "dogs", "spike.jpg"))
+	assert.True(t, os.IsNotExist(err))
+
+	assert.NoError(t, s.Delete(context.TODO(), "dogs/spike.jpg"))
+
+	for _, key := range []string{"../spike.jpg", "/etc/passwd", "dogs/../../spike.jpg", ""} {
+		_, err := s.Put(context.TODO(), key, "image/jpeg", bytes.NewReader([]byte("jpeg")))
+		assert.Error(t, err, key)
+	}
+}
@@ -0,0 +1,180 @@
+package blob
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	s3Algorithm   = "AWS4-HMAC-SHA256"
+	s3DateFormat  = "20060102T150405Z"
+	s3ErrorMaxLen = 512
+)
+
+// S3Config of S3 compatible storage, e.g. AWS S3 or MinIO.
+type S3Config struct {
+	// Endpoint e.g. https://s3.eu-central-1.amazonaws.com or http://localhost:9000 for MinIO.
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	// PublicURL files are served at, e.g. CDN in front of the bucket. Endpoint/bucket if empty.
+	PublicURL string
+}
+
+// S3 stores files in the bucket of S3 compatible storage. Requests are signed with AWS Signature Version 4
+// and use path-style addressing, so any S3 compatible storage works without DNS setup.
+type S3 struct {
+	config S3Config
+	client *http.Client
+	now    func() time.Time
+}
+
+func NewS3(config S3Config) *S3 {
+	config.Endpoint = strings.TrimRight(config.Endpoint, "/")
+	if config.PublicURL == "" {
+		config.PublicURL = config.Endpoint + "/" + config.Bucket
+	}
+
+	config.PublicURL = strings.TrimRight(config.PublicURL, "/")
+
+	return &S3{
+		config: config,
+		client: &http.Client{Timeout: time.Minute},
+		now:    time.Now,
+	}
+}
+
+func (s S3) Put(ctx context.Context, key, contentType string, content io.Reader) (string, error) {
+	// payload has to be hashed before sending, uploaded files are small enough to be buffered.
+	body, err := io.ReadAll(content)
+	if err != nil {
+		return "", fmt.Errorf("reading %s error: %w", key, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectURL(key), bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("creating request error: %w", err)
+	}
+
+	req.Header.Set("Content-Type", contentType)
+
+	if err := s.do(req, body); err != nil {
+		return "", fmt.Errorf("uploading %s error: %w", key, err)
+	}
+
+	return s.config.PublicURL + "/" + key, nil
+}
+
+// Delete removes the object, S3 doesn't report missing objects as an error.
+func (s S3) Delete(ctx context.Context, key string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, s.objectURL(key), nil)
+	if err != nil {
+		return fmt.Errorf("creating request error: %w", err)
+	}
+
+	if err := s.do(req, nil); err != nil {
+		return fmt.Errorf("deleting %s error: %w", key, err)
+	}
+
+	return nil
+}
+
+// Key returns key of the object served at the URL.
+func (s S3) Key(url string) (string, bool) {
+	return keyOf(s.config.PublicURL, url)
+}
+
+func (s S3) do(req *http.Request, body []byte) error {
+	s.sign(req, sha256Hex(body), s.now())
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, s3ErrorMaxLen))
+		return fmt.Errorf("unexpected status %d: %s", res.StatusCode, msg)
+	}
+
+	return nil
+}
+
+func (s S3) objectURL(key string) string {
+	return s.config.Endpoint + "/" + s.config.Bucket + "/" + (&url.URL{Path: key}).EscapedPath()
+}
+
+// sign adds AWS Signature Version 4 to the request. Every header already set on the request is signed.
+func (s S3) sign(req *http.Request, payloadHash string, now time.Time) {
+	amzDate := now.UTC().Format(s3DateFormat)
+	date := amzDate[:8]
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		strings.ReplaceAll(req.URL.Query().Encode(), "+", "%20"),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.config.Region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{s3Algorithm, amzDate, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.config.SecretKey), date)
+	key = hmacSHA256(key, s.config.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"%s Credential=%s/%s,SignedHeaders=%s,Signature=%s",
+		s3Algorithm, s.config.AccessKey, scope, signedHeaders, hex.EncodeToString(hmacSHA256(key, stringToSign)),
+	))
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+
+	return h.Sum(nil)
+}
@@ -17,6 +17,8 @@ const (
 	BadRequestErrorDefaultText      = "validation error"
 )
 
+const retryAfterHeader = "Retry-After"
+
 func ToIErr(err error) *ierr.Error {
 	switch e := err.(type) {
 	case *ierr.Error:
@@ -44,6 +46,12 @@ func IErrToHttpBody(c *gin.Context, err *ierr.Error) {
 		c.JSON(http.StatusUnauthorized, messages.NewUnauthenticatedError(UnauthenticatedErrorDefaultText))
 	case ierr.AlreadyExists:
 		c.JSON(http.StatusConflict, messages.NewConflictError(err.Message()))
+	case ierr.ResourceExhausted:
+		if retryAfter, ok := err.Props()[ierr.RetryAfterProp]; ok {
+			c.Header(retryAfterHeader, retryAfter)
+		}
+
+		c.JSON(http.StatusTooManyRequests, messages.NewTooManyRequestsError(err.Message()))
 	default:
 		c.JSON(http.StatusInternalServerError, messages.NewInternalServerError(InternalServerErrorDefaultText, err))
 	}
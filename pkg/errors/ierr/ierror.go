@@ -8,6 +8,9 @@ import (
 // KV is an alias for building generic key/value
 type KV map[string]string
 
+// RetryAfterProp - property of ResourceExhausted error holding number of seconds after which operation can be retried.
+const RetryAfterProp = "retry-after"
+
 // Code is an unsigned 32-bit error code as defined for internal errors.
 type Code uint32
 
@@ -0,0 +1,129 @@
+package hasher
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+const argon2IDPrefix = "$argon2id$"
+
+var ErrMalformedHash = errors.New("malformed password hash")
+
+// Argon2IDParams tunable cost parameters of argon2id algorithm.
+type Argon2IDParams struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// DefaultArgon2IDParams parameters recommended by RFC 9106 for memory constrained environments.
+var DefaultArgon2IDParams = Argon2IDParams{
+	Memory:      64 * 1024,
+	Iterations:  3,
+	Parallelism: 2,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+// Argon2ID password hasher producing per-password salted hashes encoded in PHC string format.
+type Argon2ID struct {
+	params Argon2IDParams
+}
+
+func NewArgon2ID(params Argon2IDParams) *Argon2ID {
+	return &Argon2ID{
+		params: params,
+	}
+}
+
+// Hash returns encoded hash in format $argon2id$v=19$m=65536,t=3,p=2$<salt>$<key>.
+func (h Argon2ID) Hash(password string) (string, error) {
+	salt := make([]byte, h.params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, h.params.Iterations, h.params.Memory, h.params.Parallelism, h.params.KeyLength)
+
+	return fmt.Sprintf(
+		"%sv=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2IDPrefix,
+		argon2.Version,
+		h.params.Memory,
+		h.params.Iterations,
+		h.params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// Verify compares password with encoded hash in constant time.
+func (h Argon2ID) Verify(password, encoded string) (bool, error) {
+	params, salt, key, err := h.decode(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	other := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+
+	return subtle.ConstantTimeCompare(key, other) == 1, nil
+}
+
+// Supports reports if encoded hash was produced by argon2id algorithm.
+func (h Argon2ID) Supports(encoded string) bool {
+	return strings.HasPrefix(encoded, argon2IDPrefix)
+}
+
+// NeedsRehash reports if encoded hash was produced with parameters different from the current ones.
+func (h Argon2ID) NeedsRehash(encoded string) bool {
+	params, _, _, err := h.decode(encoded)
+	if err != nil {
+		return true
+	}
+
+	return params != h.params
+}
+
+func (h Argon2ID) decode(encoded string) (Argon2IDParams, []byte, []byte, error) {
+	if !h.Supports(encoded) {
+		return Argon2IDParams{}, nil, nil, ErrMalformedHash
+	}
+
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 {
+		return Argon2IDParams{}, nil, nil, ErrMalformedHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return Argon2IDParams{}, nil, nil, ErrMalformedHash
+	}
+
+	var params Argon2IDParams
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return Argon2IDParams{}, nil, nil, ErrMalformedHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return Argon2IDParams{}, nil, nil, ErrMalformedHash
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return Argon2IDParams{}, nil, nil, ErrMalformedHash
+	}
+
+	params.SaltLength = uint32(len(salt))
+	params.KeyLength = uint32(len(key))
+
+	return params, salt, key, nil
+}
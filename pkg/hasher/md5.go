@@ -2,9 +2,12 @@ package hasher
 
 import (
 	"crypto/md5"
+	"crypto/subtle"
 	"encoding/hex"
 )
 
+// MD5 legacy password hasher with single global salt.
+// Kept only for verification of hashes created before argon2id was introduced.
 type MD5 struct {
 	salt string
 }
@@ -19,3 +22,8 @@ func (h MD5) StringHash(inp string) string {
 	hash := md5.Sum([]byte(inp + h.salt))
 	return hex.EncodeToString(hash[:])
 }
+
+// Verify compares password with legacy hash in constant time.
+func (h MD5) Verify(password, encoded string) bool {
+	return subtle.ConstantTimeCompare([]byte(h.StringHash(password)), []byte(encoded)) == 1
+}
@@ -0,0 +1,32 @@
+package hasher
+
+// Password hashes new passwords with argon2id and still verifies legacy md5 hashes,
+// so they can be transparently upgraded after successful sign-in.
+type Password struct {
+	primary *Argon2ID
+	legacy  *MD5
+}
+
+func NewPassword(primary *Argon2ID, legacy *MD5) *Password {
+	return &Password{
+		primary: primary,
+		legacy:  legacy,
+	}
+}
+
+func (p Password) Hash(password string) (string, error) {
+	return p.primary.Hash(password)
+}
+
+func (p Password) Verify(password, encoded string) (bool, error) {
+	if p.primary.Supports(encoded) {
+		return p.primary.Verify(password, encoded)
+	}
+
+	return p.legacy.Verify(password, encoded), nil
+}
+
+// NeedsRehash reports if hash is legacy or was produced with outdated argon2id parameters.
+func (p Password) NeedsRehash(encoded string) bool {
+	return p.primary.NeedsRehash(encoded)
+}
@@ -0,0 +1,100 @@
+package hasher
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPassword_Verify(t *testing.T) {
+	params := Argon2IDParams{
+		Memory:      1024,
+		Iterations:  1,
+		Parallelism: 1,
+		SaltLength:  16,
+		KeyLength:   32,
+	}
+
+	legacy := NewMD5("test-salt")
+	h := NewPassword(NewArgon2ID(params), legacy)
+
+	password := "super-secret"
+
+	hash, err := h.Hash(password)
+	if err != nil {
+		t.Fatalf("hashing error: %s", err)
+	}
+
+	anotherHash, err := h.Hash(password)
+	if err != nil {
+		t.Fatalf("hashing error: %s", err)
+	}
+
+	outdatedHash, err := NewArgon2ID(DefaultArgon2IDParams).Hash(password)
+	if err != nil {
+		t.Fatalf("hashing error: %s", err)
+	}
+
+	assert.NotEqual(t, hash, anotherHash, "hashes of the same password must be salted")
+
+	tests := []struct {
+		name            string
+		password        string
+		hash            string
+		want            bool
+		wantErr         bool
+		wantNeedsRehash bool
+	}{
+		{
+			name:            "argon2id valid password",
+			password:        password,
+			hash:            hash,
+			want:            true,
+			wantNeedsRehash: false,
+		},
+		{
+			name:            "argon2id wrong password",
+			password:        "wrong",
+			hash:            hash,
+			want:            false,
+			wantNeedsRehash: false,
+		},
+		{
+			name:            "argon2id outdated params",
+			password:        password,
+			hash:            outdatedHash,
+			want:            true,
+			wantNeedsRehash: true,
+		},
+		{
+			name:            "argon2id malformed hash",
+			password:        password,
+			hash:            "$argon2id$v=19$m=1024,t=1$aaaa",
+			want:            false,
+			wantErr:         true,
+			wantNeedsRehash: true,
+		},
+		{
+			name:            "legacy md5 valid password",
+			password:        password,
+			hash:            legacy.StringHash(password),
+			want:            true,
+			wantNeedsRehash: true,
+		},
+		{
+			name:            "legacy md5 wrong password",
+			password:        "wrong",
+			hash:            legacy.StringHash(password),
+			want:            false,
+			wantNeedsRehash: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := h.Verify(tt.password, tt.hash)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantNeedsRehash, h.NeedsRehash(tt.hash))
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+)
+
+// SMTP mailer sending plain text messages through SMTP server.
+type SMTP struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+// NewSMTP constructor. Authentication is used only if user is provided.
+func NewSMTP(host string, port uint, user, password, from string) *SMTP {
+	var auth smtp.Auth
+	if user != "" {
+		auth = smtp.PlainAuth("", user, password, host)
+	}
+
+	return &SMTP{
+		addr: net.JoinHostPort(host, strconv.Itoa(int(port))),
+		from: from,
+		auth: auth,
+	}
+}
+
+func (m SMTP) Send(_ context.Context, to, subject, body string) error {
+	if err := smtp.SendMail(m.addr, m.auth, m.from, []string{to}, message(m.from, to, subject, body)); err != nil {
+		return fmt.Errorf("sending mail to %s error: %w", to, err)
+	}
+
+	return nil
+}
+
+func message(from, to, subject, body string) []byte {
+	return []byte(fmt.Sprintf(
+		"From: %s\r\nTo: %s\r\nSubject: %s\r\nMIME-Version: 1.0\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s\r\n",
+		from, to, subject, body,
+	))
+}
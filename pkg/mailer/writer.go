@@ -0,0 +1,30 @@
+package mailer
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// Writer mailer for local development. Instead of sending, messages are written to the writer, e.g. log file or stdout.
+type Writer struct {
+	mu   sync.Mutex
+	w    io.Writer
+	from string
+}
+
+func NewWriter(w io.Writer, from string) *Writer {
+	return &Writer{
+		w:    w,
+		from: from,
+	}
+}
+
+func (m *Writer) Send(_ context.Context, to, subject, body string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, err := m.w.Write(append(message(m.from, to, subject, body), '\n'))
+
+	return err
+}
@@ -0,0 +1,329 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	discoveryPath = "/.well-known/openid-configuration"
+	scopes        = "openid email profile"
+	// responseLimit protects from unexpectedly large provider responses.
+	responseLimit = 1 << 20
+	// keysRefetchInterval limits refetching of provider keys, unknown key ids come with any forged id token.
+	keysRefetchInterval = time.Minute
+)
+
+var ErrNonceMismatch = errors.New("id token nonce mismatch")
+
+// Config of the OpenID Connect client registered at the provider.
+type Config struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+}
+
+// Identity claims of verified id token.
+type Identity struct {
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+}
+
+// Provider OpenID Connect relying party of the authorization code flow with PKCE (RFC 7636).
+// Only RS256 signed id tokens are accepted.
+type Provider struct {
+	config Config
+	client *http.Client
+
+	authorizationEndpoint string
+	tokenEndpoint         string
+	jwksURI               string
+
+	mu   sync.RWMutex
+	keys map[string]*rsa.PublicKey
+
+	// fetchMu serializes keys refetching, fetchedAt is guarded by it.
+	fetchMu   sync.Mutex
+	fetchedAt time.Time
+}
+
+type discoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Discover reads provider endpoints from its discovery document.
+func Discover(ctx context.Context, client *http.Client, config Config) (*Provider, error) {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	var doc discoveryDocument
+	if err := getJSON(ctx, client, strings.TrimSuffix(config.Issuer, "/")+discoveryPath, &doc); err != nil {
+		return nil, fmt.Errorf("fetching discovery document error: %w", err)
+	}
+
+	if doc.Issuer != config.Issuer {
+		return nil, fmt.Errorf("discovered issuer %q doesn't match configured %q", doc.Issuer, config.Issuer)
+	}
+
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" || doc.JWKSURI == "" {
+		return nil, errors.New("discovery document misses required endpoints")
+	}
+
+	return &Provider{
+		config:                config,
+		client:                client,
+		authorizationEndpoint: doc.AuthorizationEndpoint,
+		tokenEndpoint:         doc.TokenEndpoint,
+		jwksURI:               doc.JWKSURI,
+		keys:                  make(map[string]*rsa.PublicKey),
+	}, nil
+}
+
+func (p *Provider) Issuer() string {
+	return p.config.Issuer
+}
+
+// AuthCodeURL returns url of the provider login page. Only S256 challenge of the code verifier is sent.
+func (p *Provider) AuthCodeURL(state, nonce, codeVerifier string) string {
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.config.ClientID},
+		"redirect_uri":          {p.config.RedirectURL},
+		"scope":                 {scopes},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {CodeChallenge(codeVerifier)},
+		"code_challenge_method": {"S256"},
+	}
+
+	separator := "?"
+	if strings.Contains(p.authorizationEndpoint, "?") {
+		separator = "&"
+	}
+
+	return p.authorizationEndpoint + separator + query.Encode()
+}
+
+// Exchange redeems authorization code and returns identity from the verified id token.
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (Identity, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.config.RedirectURL},
+		"code_verifier": {codeVerifier},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.tokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return Identity{}, err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(p.config.ClientSecret))
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return Identity{}, fmt.Errorf("token request error: %w", err)
+	}
+	defer res.Body.Close()
+
+	var body struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+
+	if err := json.NewDecoder(io.LimitReader(res.Body, responseLimit)).Decode(&body); err != nil {
+		return Identity{}, fmt.Errorf("decoding token response error: %w", err)
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return Identity{}, fmt.Errorf("token request failed with status %d: %s %s", res.StatusCode, body.Error, body.ErrorDescription)
+	}
+
+	if body.IDToken == "" {
+		return Identity{}, errors.New("token response has no id token")
+	}
+
+	return p.verify(ctx, body.IDToken, nonce)
+}
+
+type idTokenClaims struct {
+	jwt.RegisteredClaims
+	Nonce         string `json:"nonce"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+}
+
+func (p *Provider) verify(ctx context.Context, idToken, nonce string) (Identity, error) {
+	var claims idTokenClaims
+
+	keyFunc := func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return p.key(ctx, kid)
+	}
+
+	if _, err := jwt.ParseWithClaims(idToken, &claims, keyFunc, jwt.WithValidMethods([]string{"RS256"})); err != nil {
+		return Identity{}, fmt.Errorf("invalid id token: %w", err)
+	}
+
+	if !claims.VerifyIssuer(p.config.Issuer, true) {
+		return Identity{}, fmt.Errorf("unexpected id token issuer %q", claims.Issuer)
+	}
+
+	if !claims.VerifyAudience(p.config.ClientID, true) {
+		return Identity{}, errors.New("id token is issued for another client")
+	}
+
+	if claims.ExpiresAt == nil {
+		return Identity{}, errors.New("id token has no expiration time")
+	}
+
+	if claims.Nonce != nonce {
+		return Identity{}, ErrNonceMismatch
+	}
+
+	return Identity{
+		Issuer:        claims.Issuer,
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified,
+	}, nil
+}
+
+// key returns verification key by id. Keys are refetched for unknown id, so provider key rotation is picked up,
+// but not more often than keysRefetchInterval.
+func (p *Provider) key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	if key, ok := p.cachedKey(kid); ok {
+		return key, nil
+	}
+
+	p.fetchMu.Lock()
+	defer p.fetchMu.Unlock()
+
+	// keys could be refetched while waiting for the lock.
+	if key, ok := p.cachedKey(kid); ok {
+		return key, nil
+	}
+
+	if time.Since(p.fetchedAt) < keysRefetchInterval {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	// failed fetches are limited too.
+	p.fetchedAt = time.Now()
+
+	keys, err := p.fetchKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	p.keys = keys
+	p.mu.Unlock()
+
+	key, ok := keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	return key, nil
+}
+
+func (p *Provider) cachedKey(kid string) (*rsa.PublicKey, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	key, ok := p.keys[kid]
+
+	return key, ok
+}
+
+func (p *Provider) fetchKeys(ctx context.Context) (map[string]*rsa.PublicKey, error) {
+	var set struct {
+		Keys []struct {
+			KeyType string `json:"kty"`
+			KeyID   string `json:"kid"`
+			Use     string `json:"use"`
+			N       string `json:"n"`
+			E       string `json:"e"`
+		} `json:"keys"`
+	}
+
+	if err := getJSON(ctx, p.client, p.jwksURI, &set); err != nil {
+		return nil, fmt.Errorf("fetching provider keys error: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.KeyType != "RSA" || (k.Use != "" && k.Use != "sig") {
+			continue
+		}
+
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("decoding modulus of key %q error: %w", k.KeyID, err)
+		}
+
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("decoding exponent of key %q error: %w", k.KeyID, err)
+		}
+
+		keys[k.KeyID] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+
+	return keys, nil
+}
+
+// CodeChallenge returns S256 PKCE challenge of the code verifier.
+func CodeChallenge(codeVerifier string) string {
+	hash := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(hash[:])
+}
+
+func getJSON(ctx context.Context, client *http.Client, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d", res.StatusCode)
+	}
+
+	return json.NewDecoder(io.LimitReader(res.Body, responseLimit)).Decode(v)
+}
@@ -0,0 +1,134 @@
+package oidc_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/valerii-smirnov/petli-test-task/pkg/oidc"
+	"github.com/valerii-smirnov/petli-test-task/pkg/oidc/oidctest"
+)
+
+const (
+	clientID     = "petly"
+	clientSecret = "petly-secret"
+	redirectURL  = "http://localhost:8080/api/auth/oidc/callback"
+	codeVerifier = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	nonce        = "n-0S6_WzA2Mj"
+	state        = "af0ifjsldkj"
+)
+
+// authorize follows provider login page and returns authorization code from the redirect to the client.
+func authorize(t *testing.T, provider *oidc.Provider, verifier string) string {
+	client := &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	res, err := client.Get(provider.AuthCodeURL(state, nonce, verifier))
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusFound, res.StatusCode)
+
+	location, err := url.Parse(res.Header.Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, state, location.Query().Get("state"))
+
+	return location.Query().Get("code")
+}
+
+func TestProvider(t *testing.T) {
+	server := oidctest.NewServer(clientID, clientSecret)
+	defer server.Close()
+
+	server.SignIn(oidctest.Identity{
+		Subject:       "248289761001",
+		Email:         "test@email.com",
+		EmailVerified: true,
+	})
+
+	config := oidc.Config{
+		Issuer:       server.URL,
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		RedirectURL:  redirectURL,
+	}
+
+	provider, err := oidc.Discover(context.TODO(), nil, config)
+	require.NoError(t, err)
+
+	t.Run("success", func(t *testing.T) {
+		code := authorize(t, provider, codeVerifier)
+
+		identity, err := provider.Exchange(context.TODO(), code, codeVerifier, nonce)
+		require.NoError(t, err)
+		assert.Equal(t, oidc.Identity{
+			Issuer:        server.URL,
+			Subject:       "248289761001",
+			Email:         "test@email.com",
+			EmailVerified: true,
+		}, identity)
+
+		_, err = provider.Exchange(context.TODO(), code, codeVerifier, nonce)
+		assert.Error(t, err, "authorization code must be single-use")
+	})
+
+	t.Run("wrong code verifier", func(t *testing.T) {
+		code := authorize(t, provider, codeVerifier)
+
+		_, err := provider.Exchange(context.TODO(), code, "another-verifier-another-verifier-another-ver", nonce)
+		assert.Error(t, err)
+	})
+
+	t.Run("nonce mismatch", func(t *testing.T) {
+		code := authorize(t, provider, codeVerifier)
+
+		_, err := provider.Exchange(context.TODO(), code, codeVerifier, "another-nonce")
+		assert.ErrorIs(t, err, oidc.ErrNonceMismatch)
+	})
+
+	t.Run("keys refetch is limited", func(t *testing.T) {
+		fetched := server.JWKSRequests()
+		server.RotateKey()
+
+		for i := 0; i < 3; i++ {
+			code := authorize(t, provider, codeVerifier)
+
+			_, err := provider.Exchange(context.TODO(), code, codeVerifier, nonce)
+			assert.Error(t, err)
+		}
+
+		assert.Equal(t, fetched, server.JWKSRequests())
+	})
+
+	t.Run("wrong client secret", func(t *testing.T) {
+		wrongConfig := config
+		wrongConfig.ClientSecret = "wrong"
+
+		wrongProvider, err := oidc.Discover(context.TODO(), nil, wrongConfig)
+		require.NoError(t, err)
+
+		code := authorize(t, wrongProvider, codeVerifier)
+
+		_, err = wrongProvider.Exchange(context.TODO(), code, codeVerifier, nonce)
+		assert.Error(t, err)
+	})
+}
+
+func TestDiscover_IssuerMismatch(t *testing.T) {
+	server := oidctest.NewServer(clientID, clientSecret)
+	defer server.Close()
+
+	_, err := oidc.Discover(context.TODO(), nil, oidc.Config{
+		Issuer:      server.URL + "/",
+		ClientID:    clientID,
+		RedirectURL: redirectURL,
+	})
+	assert.Error(t, err)
+}
@@ -9,34 +9,40 @@ import (
 
 const (
 	UserIDClaimName         = "user-id"
-	expirationTimeClaimName = "exp"
-	issuedAaClimeName       = "iat"
-	tokenIDClimeName        = "jti"
+	RoleClaimName           = "role"
+	ExpirationTimeClaimName = "exp"
+	IssuedAtClaimName       = "iat"
+	TokenIDClaimName        = "jti"
 )
 
 type JWT struct {
-	secret []byte
-	ttl    time.Duration
+	keys *KeySet
+	ttl  time.Duration
 }
 
-func NewJWT(secret string, ttl time.Duration) *JWT {
+func NewJWT(keys *KeySet, ttl time.Duration) *JWT {
 	return &JWT{
-		secret: []byte(secret),
-		ttl:    ttl,
+		keys: keys,
+		ttl:  ttl,
 	}
 }
 
-func (j JWT) Generate(uid uuid.UUID) (string, error) {
-	token := jwt.New(jwt.SigningMethodHS256)
+func (j JWT) Generate(uid uuid.UUID, role string) (string, error) {
+	key := j.keys.Active()
+	token := jwt.New(key.Method)
+	if key.ID != "" {
+		token.Header[keyIDHeaderName] = key.ID
+	}
 
 	token.Claims = jwt.MapClaims{
 		UserIDClaimName:         uid.String(),
-		expirationTimeClaimName: jwt.NewNumericDate(time.Now().Add(j.ttl)),
-		issuedAaClimeName:       jwt.NewNumericDate(time.Now()),
-		tokenIDClimeName:        uuid.New().String(),
+		RoleClaimName:           role,
+		ExpirationTimeClaimName: jwt.NewNumericDate(time.Now().Add(j.ttl)),
+		IssuedAtClaimName:       jwt.NewNumericDate(time.Now()),
+		TokenIDClaimName:        uuid.New().String(),
 	}
 
-	return token.SignedString(j.secret)
+	return token.SignedString(key.signingKey)
 }
 
 func (j JWT) Valid(token string) (bool, error) {
@@ -48,14 +54,17 @@ func (j JWT) Valid(token string) (bool, error) {
 	return t.Valid, nil
 }
 
+// Parse validates token signature with the key referenced by kid header. Only algorithms of the key set are accepted.
 func (j JWT) Parse(token string) (*jwt.Token, error) {
-	t, err := jwt.Parse(token, func(token *jwt.Token) (interface{}, error) {
-		return j.secret, nil
-	})
-
+	t, err := jwt.Parse(token, j.keys.VerificationKey, jwt.WithValidMethods(j.keys.Methods()))
 	if err != nil {
 		return nil, err
 	}
 
 	return t, nil
 }
+
+// JWKS returns public keys tokens can be verified with.
+func (j JWT) JWKS() []JWK {
+	return j.keys.JWKS()
+}
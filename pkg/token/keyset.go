@@ -0,0 +1,220 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const keyIDHeaderName = "kid"
+
+// Key signing key with its verification counterpart.
+type Key struct {
+	ID     string
+	Method jwt.SigningMethod
+
+	signingKey      interface{}
+	verificationKey interface{}
+}
+
+// KeySet keys used to sign and validate tokens. Tokens are signed with the active key only,
+// but validated with any key of the set, so keys can be rotated without invalidating issued tokens.
+type KeySet struct {
+	active *Key
+	keys   map[string]*Key
+}
+
+// NewHMACKeySet returns key set with single HS256 shared secret. Such key set has no public keys to publish.
+func NewHMACKeySet(secret string) *KeySet {
+	key := &Key{
+		Method:          jwt.SigningMethodHS256,
+		signingKey:      []byte(secret),
+		verificationKey: []byte(secret),
+	}
+
+	return &KeySet{
+		active: key,
+		keys:   map[string]*Key{key.ID: key},
+	}
+}
+
+// LoadKeySet reads RSA (RS256) and Ed25519 (EdDSA) private keys from PEM files.
+// File name without extension is used as key ID. Retired keys are skipped, so they neither sign nor validate tokens.
+// Active key may be omitted if only one key is loaded.
+func LoadKeySet(paths []string, activeID string, retiredIDs []string) (*KeySet, error) {
+	retired := make(map[string]struct{}, len(retiredIDs))
+	for _, id := range retiredIDs {
+		retired[id] = struct{}{}
+	}
+
+	ks := &KeySet{
+		keys: make(map[string]*Key, len(paths)),
+	}
+
+	for _, path := range paths {
+		id := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		if _, ok := retired[id]; ok {
+			continue
+		}
+
+		if _, ok := ks.keys[id]; ok {
+			return nil, fmt.Errorf("duplicated key id %q", id)
+		}
+
+		key, err := loadKey(id, path)
+		if err != nil {
+			return nil, err
+		}
+
+		ks.keys[id] = key
+	}
+
+	if len(ks.keys) == 0 {
+		return nil, fmt.Errorf("no signing keys loaded")
+	}
+
+	if activeID == "" && len(ks.keys) == 1 {
+		for _, key := range ks.keys {
+			ks.active = key
+		}
+
+		return ks, nil
+	}
+
+	active, ok := ks.keys[activeID]
+	if !ok {
+		return nil, fmt.Errorf("active key %q is not loaded or retired", activeID)
+	}
+
+	ks.active = active
+
+	return ks, nil
+}
+
+// Active returns key new tokens are signed with.
+func (ks KeySet) Active() *Key {
+	return ks.active
+}
+
+// Methods returns names of algorithms allowed for token validation.
+func (ks KeySet) Methods() []string {
+	seen := make(map[string]struct{}, len(ks.keys))
+	methods := make([]string, 0, len(ks.keys))
+	for _, key := range ks.keys {
+		if _, ok := seen[key.Method.Alg()]; ok {
+			continue
+		}
+
+		seen[key.Method.Alg()] = struct{}{}
+		methods = append(methods, key.Method.Alg())
+	}
+
+	return methods
+}
+
+// VerificationKey jwt.Keyfunc implementation. It looks key up by kid header
+// and requires token algorithm to be exactly the one of the key.
+func (ks KeySet) VerificationKey(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header[keyIDHeaderName].(string)
+
+	key, ok := ks.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method %q for key %q", token.Method.Alg(), kid)
+	}
+
+	return key.verificationKey, nil
+}
+
+// JWKS returns public keys of the set in JSON Web Key format. Symmetric keys are never published.
+func (ks KeySet) JWKS() []JWK {
+	jwks := make([]JWK, 0, len(ks.keys))
+	for _, key := range ks.keys {
+		switch pub := key.verificationKey.(type) {
+		case *rsa.PublicKey:
+			jwks = append(jwks, JWK{
+				KeyType:   "RSA",
+				KeyID:     key.ID,
+				Use:       "sig",
+				Algorithm: key.Method.Alg(),
+				N:         base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+				E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			jwks = append(jwks, JWK{
+				KeyType:   "OKP",
+				KeyID:     key.ID,
+				Use:       "sig",
+				Algorithm: key.Method.Alg(),
+				Curve:     "Ed25519",
+				X:         base64.RawURLEncoding.EncodeToString(pub),
+			})
+		}
+	}
+
+	sort.Slice(jwks, func(i, j int) bool {
+		return jwks[i].KeyID < jwks[j].KeyID
+	})
+
+	return jwks
+}
+
+// JWK public key in JSON Web Key (RFC 7517) format.
+type JWK struct {
+	KeyType   string
+	KeyID     string
+	Use       string
+	Algorithm string
+	N         string
+	E         string
+	Curve     string
+	X         string
+}
+
+func loadKey(id, path string) (*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading key %q error: %w", path, err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("key %q is not PEM encoded", path)
+	}
+
+	var private interface{}
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		private, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		private, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block type %q of key %q", block.Type, path)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("parsing key %q error: %w", path, err)
+	}
+
+	switch k := private.(type) {
+	case *rsa.PrivateKey:
+		return &Key{ID: id, Method: jwt.SigningMethodRS256, signingKey: k, verificationKey: k.Public()}, nil
+	case ed25519.PrivateKey:
+		return &Key{ID: id, Method: jwt.SigningMethodEdDSA, signingKey: k, verificationKey: k.Public()}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %T of key %q", private, path)
+	}
+}
@@ -0,0 +1,142 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJWT_KeyRotation(t *testing.T) {
+	dir := t.TempDir()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generating rsa key error: %s", err)
+	}
+
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generating ed25519 key error: %s", err)
+	}
+
+	oldKeyPath := writePEM(t, dir, "2023-01", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey))
+
+	edKeyBytes, err := x509.MarshalPKCS8PrivateKey(edKey)
+	if err != nil {
+		t.Fatalf("marshaling ed25519 key error: %s", err)
+	}
+
+	newKeyPath := writePEM(t, dir, "2023-02", "PRIVATE KEY", edKeyBytes)
+
+	oldKeys, err := LoadKeySet([]string{oldKeyPath}, "", nil)
+	if err != nil {
+		t.Fatalf("loading key set error: %s", err)
+	}
+
+	rotatedKeys, err := LoadKeySet([]string{oldKeyPath, newKeyPath}, "2023-02", nil)
+	if err != nil {
+		t.Fatalf("loading key set error: %s", err)
+	}
+
+	retiredKeys, err := LoadKeySet([]string{oldKeyPath, newKeyPath}, "2023-02", []string{"2023-01"})
+	if err != nil {
+		t.Fatalf("loading key set error: %s", err)
+	}
+
+	_, err = LoadKeySet([]string{oldKeyPath, newKeyPath}, "", nil)
+	assert.Error(t, err, "active key must be chosen when several keys are loaded")
+
+	uid := uuid.New()
+
+	oldToken, err := NewJWT(oldKeys, time.Minute).Generate(uid, "user")
+	if err != nil {
+		t.Fatalf("generating token error: %s", err)
+	}
+
+	newToken, err := NewJWT(rotatedKeys, time.Minute).Generate(uid, "user")
+	if err != nil {
+		t.Fatalf("generating token error: %s", err)
+	}
+
+	// token signed with HMAC using RSA public key as a secret must not be accepted.
+	confused := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{UserIDClaimName: uid.String()})
+	confused.Header[keyIDHeaderName] = "2023-01"
+	confusedToken, err := confused.SignedString(x509.MarshalPKCS1PublicKey(&rsaKey.PublicKey))
+	if err != nil {
+		t.Fatalf("generating token error: %s", err)
+	}
+
+	tests := []struct {
+		name    string
+		keys    *KeySet
+		token   string
+		wantErr bool
+	}{
+		{
+			name:  "token of old key is valid after rotation",
+			keys:  rotatedKeys,
+			token: oldToken,
+		},
+		{
+			name:  "token of active key is valid",
+			keys:  rotatedKeys,
+			token: newToken,
+		},
+		{
+			name:    "token of retired key is rejected",
+			keys:    retiredKeys,
+			token:   oldToken,
+			wantErr: true,
+		},
+		{
+			name:    "token of unknown key is rejected",
+			keys:    oldKeys,
+			token:   newToken,
+			wantErr: true,
+		},
+		{
+			name:    "algorithm confusion is rejected",
+			keys:    rotatedKeys,
+			token:   confusedToken,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			valid, err := NewJWT(tt.keys, time.Minute).Valid(tt.token)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, !tt.wantErr, valid)
+		})
+	}
+
+	jwks := rotatedKeys.JWKS()
+	if assert.Len(t, jwks, 2) {
+		assert.Equal(t, "2023-01", jwks[0].KeyID)
+		assert.Equal(t, "RS256", jwks[0].Algorithm)
+		assert.Equal(t, "AQAB", jwks[0].E)
+		assert.Equal(t, "2023-02", jwks[1].KeyID)
+		assert.Equal(t, "EdDSA", jwks[1].Algorithm)
+		assert.Equal(t, "Ed25519", jwks[1].Curve)
+	}
+
+	assert.Empty(t, NewHMACKeySet("secret").JWKS())
+}
+
+func writePEM(t *testing.T, dir, name, blockType string, der []byte) string {
+	path := filepath.Join(dir, name+".pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatalf("writing key error: %s", err)
+	}
+
+	return path
+}
@@ -0,0 +1,39 @@
+package token
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+const defaultOpaqueTokenSize = 32
+
+// Opaque generates random tokens which carry no data and must be looked up in storage by their hash.
+type Opaque struct {
+	size int
+}
+
+func NewOpaque() *Opaque {
+	return &Opaque{
+		size: defaultOpaqueTokenSize,
+	}
+}
+
+// Generate returns url safe random token and its hash suitable for storing.
+func (o Opaque) Generate() (string, string, error) {
+	b := make([]byte, o.size)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	return token, o.Hash(token), nil
+}
+
+// Hash returns hex encoded sha256 hash of the token.
+func (o Opaque) Hash(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,71 @@
+package token
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidSignedToken = errors.New("invalid signed token")
+	ErrSignedTokenExpired = errors.New("signed token is expired")
+)
+
+// Signed stateless tokens for links sent to users. Token binds subject to purpose and expiration time
+// with HMAC-SHA256 signature, so token issued for one purpose can't be used for another one.
+type Signed struct {
+	secret []byte
+}
+
+func NewSigned(secret string) *Signed {
+	return &Signed{
+		secret: []byte(secret),
+	}
+}
+
+// Sign returns url safe token in format <subject>.<expiration unix time>.<signature>.
+func (s Signed) Sign(purpose, subject string, ttl time.Duration) string {
+	encodedSubject := base64.RawURLEncoding.EncodeToString([]byte(subject))
+	exp := strconv.FormatInt(time.Now().Add(ttl).Unix(), 10)
+
+	return encodedSubject + "." + exp + "." + s.signature(purpose, encodedSubject, exp)
+}
+
+// Verify checks token signature and expiration and returns its subject.
+func (s Signed) Verify(purpose, token string) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", ErrInvalidSignedToken
+	}
+
+	if !hmac.Equal([]byte(parts[2]), []byte(s.signature(purpose, parts[0], parts[1]))) {
+		return "", ErrInvalidSignedToken
+	}
+
+	exp, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return "", ErrInvalidSignedToken
+	}
+
+	if time.Now().After(time.Unix(exp, 0)) {
+		return "", ErrSignedTokenExpired
+	}
+
+	subject, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return "", ErrInvalidSignedToken
+	}
+
+	return string(subject), nil
+}
+
+func (s Signed) signature(purpose, encodedSubject, exp string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(purpose + "." + encodedSubject + "." + exp))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
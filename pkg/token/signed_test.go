@@ -0,0 +1,65 @@
+package token
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSigned_Verify(t *testing.T) {
+	signer := NewSigned("test-secret")
+
+	purpose := "email-verification"
+	subject := "test@email.com"
+
+	valid := signer.Sign(purpose, subject, time.Hour)
+	expired := signer.Sign(purpose, subject, -time.Minute)
+	foreign := NewSigned("another-secret").Sign(purpose, subject, time.Hour)
+
+	tests := []struct {
+		name    string
+		purpose string
+		token   string
+		want    string
+		wantErr error
+	}{
+		{
+			name:    "valid token",
+			purpose: purpose,
+			token:   valid,
+			want:    subject,
+		},
+		{
+			name:    "expired token",
+			purpose: purpose,
+			token:   expired,
+			wantErr: ErrSignedTokenExpired,
+		},
+		{
+			name:    "token of another purpose",
+			purpose: "password-reset",
+			token:   valid,
+			wantErr: ErrInvalidSignedToken,
+		},
+		{
+			name:    "token signed with another secret",
+			purpose: purpose,
+			token:   foreign,
+			wantErr: ErrInvalidSignedToken,
+		},
+		{
+			name:    "malformed token",
+			purpose: purpose,
+			token:   "malformed",
+			wantErr: ErrInvalidSignedToken,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := signer.Verify(tt.purpose, tt.token)
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	secretSize         = 20
+	recoveryCodeSize   = 10
+	defaultDigits      = 6
+	defaultPeriod      = 30 * time.Second
+	defaultSkewPeriods = 1
+)
+
+var (
+	encoding         = base32.StdEncoding.WithPadding(base32.NoPadding)
+	recoveryEncoding = base32.NewEncoding("abcdefghijkmnpqrstuvwxyz23456789").WithPadding(base32.NoPadding)
+)
+
+// TOTP RFC 6238 time-based one-time passwords with HMAC-SHA1, compatible with common authenticator apps.
+type TOTP struct {
+	issuer string
+	digits int
+	period time.Duration
+	// skew number of periods before and after the current one codes are accepted for, to tolerate clock drift.
+	skew int64
+}
+
+func New(issuer string) *TOTP {
+	return &TOTP{
+		issuer: issuer,
+		digits: defaultDigits,
+		period: defaultPeriod,
+		skew:   defaultSkewPeriods,
+	}
+}
+
+// GenerateSecret returns random base32 encoded secret.
+func (t TOTP) GenerateSecret() (string, error) {
+	b := make([]byte, secretSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("reading random bytes error: %w", err)
+	}
+
+	return encoding.EncodeToString(b), nil
+}
+
+// ProvisioningURI returns otpauth:// URI authenticator apps are enrolled with, usually shown as QR code.
+func (t TOTP) ProvisioningURI(secret, account string) string {
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", t.issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(t.digits))
+	query.Set("period", fmt.Sprint(int(t.period.Seconds())))
+
+	u := url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + t.issuer + ":" + account,
+		RawQuery: query.Encode(),
+	}
+
+	return u.String()
+}
+
+// Validate checks code against the secret at the time and returns time step it matched,
+// so caller can reject reuse of the same code.
+func (t TOTP) Validate(secret, code string, at time.Time) (int64, bool) {
+	current := at.Unix() / int64(t.period.Seconds())
+	for step := current - t.skew; step <= current+t.skew; step++ {
+		expected, err := t.Code(secret, step)
+		if err != nil {
+			return 0, false
+		}
+
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+
+	return 0, false
+}
+
+// Code returns code for the time step.
+func (t TOTP) Code(secret string, step int64) (string, error) {
+	key, err := encoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", fmt.Errorf("decoding secret error: %w", err)
+	}
+
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, uint64(step))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < t.digits; i++ {
+		mod *= 10
+	}
+
+	return fmt.Sprintf("%0*d", t.digits, value%mod), nil
+}
+
+// GenerateRecoveryCodes returns n random single-use codes in xxxxx-xxxxx format.
+// Alphabet has no similar looking characters, so codes are easy to type.
+func (t TOTP) GenerateRecoveryCodes(n int) ([]string, error) {
+	codes := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		b := make([]byte, recoveryCodeSize*5/8)
+		if _, err := rand.Read(b); err != nil {
+			return nil, fmt.Errorf("reading random bytes error: %w", err)
+		}
+
+		code := recoveryEncoding.EncodeToString(b)
+		codes = append(codes, code[:recoveryCodeSize/2]+"-"+code[recoveryCodeSize/2:])
+	}
+
+	return codes, nil
+}
@@ -0,0 +1,85 @@
+package totp
+
+import (
+	"encoding/base32"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// rfc6238Secret SHA1 secret of RFC 6238 test vectors.
+var rfc6238Secret = base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestTOTP_Code(t *testing.T) {
+	totp := TOTP{issuer: "Petly", digits: 8, period: defaultPeriod, skew: defaultSkewPeriods}
+
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "94287082"},
+		{unix: 1111111109, want: "07081804"},
+		{unix: 1111111111, want: "14050471"},
+		{unix: 1234567890, want: "89005924"},
+		{unix: 2000000000, want: "69279037"},
+		{unix: 20000000000, want: "65353130"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			got, err := totp.Code(rfc6238Secret, tt.unix/30)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestTOTP_Validate(t *testing.T) {
+	totp := New("Petly")
+	at := time.Unix(1234567890, 0)
+	step := at.Unix() / 30
+
+	current, err := totp.Code(rfc6238Secret, step)
+	assert.NoError(t, err)
+
+	previous, err := totp.Code(rfc6238Secret, step-1)
+	assert.NoError(t, err)
+
+	outdated, err := totp.Code(rfc6238Secret, step-2)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		code     string
+		wantStep int64
+		wantOk   bool
+	}{
+		{name: "current code", code: current, wantStep: step, wantOk: true},
+		{name: "previous code within skew", code: previous, wantStep: step - 1, wantOk: true},
+		{name: "outdated code", code: outdated, wantStep: 0, wantOk: false},
+		{name: "wrong code", code: "000000", wantStep: 0, wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotStep, gotOk := totp.Validate(rfc6238Secret, tt.code, at)
+			assert.Equal(t, tt.wantOk, gotOk)
+			assert.Equal(t, tt.wantStep, gotStep)
+		})
+	}
+}
+
+func TestTOTP_ProvisioningURI(t *testing.T) {
+	uri := New("Petly").ProvisioningURI("JBSWY3DPEHPK3PXP", "test@email.com")
+	assert.Equal(t, "otpauth://totp/Petly:test@email.com?algorithm=SHA1&digits=6&issuer=Petly&period=30&secret=JBSWY3DPEHPK3PXP", uri)
+}
+
+func TestTOTP_GenerateRecoveryCodes(t *testing.T) {
+	codes, err := New("Petly").GenerateRecoveryCodes(10)
+	assert.NoError(t, err)
+	assert.Len(t, codes, 10)
+
+	for _, code := range codes {
+		assert.Regexp(t, regexp.MustCompile(`^[a-z2-9]{5}-[a-z2-9]{5}$`), code)
+	}
+}
@@ -0,0 +1,43 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Job unit of background work.
+type Job func(ctx context.Context) error
+
+// Periodic runs job with fixed interval until context is done.
+type Periodic struct {
+	name     string
+	interval time.Duration
+	job      Job
+}
+
+func NewPeriodic(name string, interval time.Duration, job Job) *Periodic {
+	return &Periodic{
+		name:     name,
+		interval: interval,
+		job:      job,
+	}
+}
+
+// Run blocks until context is done, so it is meant to be started in separate goroutine.
+// Job errors are logged and don't stop next runs.
+func (p Periodic) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := p.job(ctx); err != nil {
+				log.Printf("%s job error: %s", p.name, err)
+			}
+		}
+	}
+}